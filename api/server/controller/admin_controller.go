@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"net/http"
+	"sort"
+
+	"cvs/internal/models"
+	"cvs/internal/service/exchange"
+	"cvs/internal/service/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// adminController handles the operations available only to the users with the admin role.
+type adminController struct {
+	allExchangesStorage exchange.AllExchanges // Storage for all exchanges
+	logger              logger.Logger
+}
+
+// NewAdminController creates a new instance of adminController.
+//
+// Parameters:
+//   - allExchangesStorage: The storage for all exchanges, allowing access to exchange-related operations.
+//
+// Returns:
+//   - *adminController: A pointer to the initialized adminController instance.
+func NewAdminController(
+	allExchangesStorage exchange.AllExchanges,
+	logger logger.Logger,
+) *adminController {
+	return &adminController{
+		allExchangesStorage: allExchangesStorage,
+		logger:              logger,
+	}
+}
+
+// GetExchangesStats retrieves the scanning load and the last fetch status of every exchange.
+//
+// The stats are sorted by the exchange name, so the operator can compare the responses over time.
+//
+// @Summary Exchanges stats
+// @Description Get per exchange the number of subscribed and listed pairs, the status of the last fetch and the minimum volume floor. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Success 200 {array} models.ExchangeStats "Stats of the exchanges"
+// @Failure 401 {object} models.Response "Unauthorized"
+// @Failure 403 {object} models.Response "Admin role is required"
+// @Router /api/admin/exchanges/stats [get]
+func (ac *adminController) GetExchangesStats(c *fiber.Ctx) error {
+	stats := []models.ExchangeStats{}
+
+	for _, exchange := range ac.allExchangesStorage.All() {
+		status := exchange.Status()
+
+		stats = append(stats, models.ExchangeStats{
+			Exchange:            exchange.ExchangeName(),
+			SubscribedPairs:     exchange.SubscribedPairsCount(),
+			ListedPairs:         exchange.AllPairsCount(),
+			LastSuccessfulFetch: status.LastSuccessfulFetch,
+			LastError:           status.LastError,
+			MinVolumeFloor:      exchange.MinVolumeFloor(),
+			CircuitBreaker:      status.CircuitBreaker,
+			BlacklistedPairs:    exchange.BlacklistedPairs(),
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Exchange < stats[j].Exchange
+	})
+
+	return c.JSON(stats) // Return the stats of the exchanges in JSON format
+}
+
+// RefreshExchangePairs fetches the pairs listed on the exchange on demand.
+//
+// The pairs are also refreshed periodically if the interval is configured, the endpoint lets the operator
+// pick up a new listing right away. The function performs the following steps:
+// 1. Retrieves the exchange by the name from the path.
+// 2. Returns 404 if the exchange is not supported.
+// 3. Fetches the pairs of the exchange, adding the new listings and removing the delisted pairs.
+// 4. Returns 502 if the exchange couldn't be requested or its response couldn't be parsed, the stored pairs are kept then.
+// 5. Returns a JSON response containing the number of pairs listed after the refresh.
+//
+// @Summary Refresh pairs of an exchange
+// @Description Fetch the pairs listed on the exchange now instead of waiting for the periodic refresh. Requires the admin role.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param name path string true "Exchange name" example(binance_spot)
+// @Success 200 {object} models.ExchangePairsRefresh "Pairs of the exchange are refreshed"
+// @Failure 401 {object} models.Response "Unauthorized"
+// @Failure 403 {object} models.Response "Admin role is required"
+// @Failure 404 {object} models.Response "Exchange not found"
+// @Failure 502 {object} models.Response "Exchange request failed"
+// @Router /api/admin/exchanges/{name}/refresh-pairs [post]
+func (ac *adminController) RefreshExchangePairs(c *fiber.Ctx) error {
+	exchange, ok := ac.allExchangesStorage.Get(c.Params("name"))
+	if !ok {
+		c.Status(http.StatusNotFound)
+
+		return c.JSON(models.Response{
+			Result: "exchange not found", // Return error message in JSON format
+			Code:   models.CodeNotFound,
+		})
+	}
+
+	if err := exchange.GetAllPairsOfExchange(c.Context()); err != nil {
+		c.Status(http.StatusBadGateway)
+
+		return c.JSON(models.Response{
+			Result: "refreshing the pairs failed: " + err.Error(), // The stored pairs are kept, the request can be repeated later
+			Code:   models.CodeUnavailable,
+		})
+	}
+
+	return c.JSON(models.ExchangePairsRefresh{
+		Exchange:    exchange.ExchangeName(),
+		ListedPairs: exchange.AllPairsCount(),
+	})
+}
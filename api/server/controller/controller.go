@@ -10,11 +10,14 @@ Key Features:
   - **Account Deletion**: Facilitates the deletion of user accounts along with associated data from exchanges.
   - **Trading Pair Management**: Supports adding, updating, and deleting trading pairs for authenticated users.
   - **Found Volume Retrieval**: Enables retrieval of all found volumes associated with a user's trading pairs.
-  - **Error Handling**: Implements robust error handling to provide meaningful feedback to users in case of issues during operations.
+  - **Error Handling**: Implements robust error handling to provide meaningful feedback to users in case of issues during operations. Error responses carry a stable machine-readable code, and the details of internal errors are only logged.
 
 Main Components:
   - `userController`: The primary controller that handles requests related to user authentication and trading pairs. It provides methods for signing up users, logging them in, updating passwords, refreshing tokens, managing their trading pairs, and retrieving found volumes.
   - `userPairsController`: Handles requests related to user trading pairs. It provides methods for adding pairs, updating their values, retrieving all user pairs, and deleting specific pairs.
+  - `userSettingsController`: Handles requests reading and updating the notification preferences of the user.
+  - `exchangesController`: Handles read-only requests listing the supported exchanges and the pairs available on them.
+  - `healthController`: Handles the liveness and readiness probes reporting the connectivity of the exchanges.
 
 Service Dependencies: The controller relies on several services for its functionality:
   - `UserService`: Manages user-related data and operations.
@@ -25,6 +28,7 @@ Service Dependencies: The controller relies on several services for its function
 Endpoints:
   - **POST /api/user/auth/signup**: Sign up a new user.
   - **POST /api/user/auth/login**: Authenticate a user and issue tokens if successful.
+  - **GET /api/user/auth/verify**: Verify the user's email using the token sent on signup.
   - **GET /api/user/auth/tokens**: Retrieve new access and refresh tokens for the authenticated user.
   - **PUT /api/user/auth/password**: Update a user's password.
   - **DELETE /api/user**: Delete the authenticated user's account.
@@ -32,6 +36,12 @@ Endpoints:
   - **POST /api/user/pair**: Add a new trading pair for the authenticated user.
   - **GET /api/user/pair/all-pairs**: Retrieve all pairs for the authenticated user.
   - **GET /api/user/found-volumes**: Retrieve all found volumes associated with the authenticated user's trading pairs.
+  - **GET /api/user/settings**: Retrieve the notification preferences of the authenticated user.
+  - **PUT /api/user/settings**: Update the notification preferences of the authenticated user.
+  - **GET /api/exchanges**: Retrieve the names of all supported exchanges.
+  - **GET /api/exchanges/:name/pairs**: Retrieve all pairs available on the exchange, optionally filtered by the search query.
+  - **GET /health**: Check that the process is alive.
+  - **GET /ready**: Report the connectivity of every exchange, 503 if the data of any of them is stale.
 */
 package controller
 
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"cvs/internal/models"
+	"cvs/internal/repository"
+	"cvs/internal/service"
+	"cvs/internal/service/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	errInvalidInputData = "invalid input data"                           // Error of a request body which can't be parsed
+	errInternal         = "internal server error"                        // Error of a failure whose details are only logged
+	errEmailRegistered  = "a user with this email is already registered" // Error of an email another user is registered with
+)
+
+// clientError is the description of an error the client is allowed to see.
+type clientError struct {
+	status  int    // HTTP status of the response
+	code    string // Machine-readable code of the error
+	message string // Message of the error, the message of the error itself when empty
+}
+
+// knownErrors are the errors of the services reported to the client, by the errors they wrap.
+var knownErrors = []struct {
+	err         error
+	clientError clientError
+}{
+	{repository.ErrEmailAlreadyExists, clientError{http.StatusConflict, models.CodeConflict, errEmailRegistered}},
+	{repository.ErrPendingEmailNotFound, clientError{http.StatusBadRequest, models.CodeInvalidToken, repository.ErrPendingEmailNotFound.Error()}},
+	{repository.ErrSessionNotFound, clientError{http.StatusNotFound, models.CodeNotFound, repository.ErrSessionNotFound.Error()}},
+	{repository.ErrUserPairNotFound, clientError{http.StatusNotFound, models.CodeNotFound, repository.ErrUserPairNotFound.Error()}},
+	{service.ErrMaxPairsPerUserReached, clientError{http.StatusBadRequest, models.CodePairsLimitReached, ""}}, // The message carries the limit
+	{models.ErrInvalidPair, clientError{http.StatusBadRequest, models.CodeValidationFailed, models.ErrInvalidPair.Error()}},
+}
+
+// describeError maps the error returned by a service to the description the client is allowed to see.
+//
+// Validation errors and the known failures keep their messages. Any other error may carry the operation
+// paths of the wrapped errors or the details of the database, so it is described as an internal error.
+//
+// Parameters:
+//   - err: The error returned by the service.
+//
+// Returns:
+//   - clientError: The status, the code and the message of the response.
+//   - bool: Whether the error is known, false if it is an internal error.
+func describeError(err error) (clientError, bool) {
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		return clientError{http.StatusBadRequest, models.CodeValidationFailed, validationErr.Error()}, true
+	}
+
+	for _, known := range knownErrors {
+		if !errors.Is(err, known.err) {
+			continue
+		}
+
+		description := known.clientError
+		if description.message == "" {
+			description.message = err.Error()
+		}
+
+		return description, true
+	}
+
+	return clientError{http.StatusInternalServerError, models.CodeInternal, errInternal}, false
+}
+
+// errorResponse sets the status of the error returned by a service and returns the response describing it.
+//
+// The details of an internal error are logged along with the fields, the client gets only its code.
+//
+// Parameters:
+//   - c: The context of the request whose status is set.
+//   - logger: The logger the internal errors are written to.
+//   - err: The error returned by the service.
+//   - fields: The fields logged along with an internal error, e.g. the user ID.
+//
+// Returns:
+//   - models.Response: The response with the client-facing message and code of the error.
+func errorResponse(c *fiber.Ctx, logger logger.Logger, err error, fields ...interface{}) models.Response {
+	description, known := describeError(err)
+	if !known {
+		logger.Error(append([]interface{}{err}, fields...)...)
+	}
+
+	c.Status(description.status)
+
+	return models.Response{
+		Result: description.message,
+		Code:   description.code,
+	}
+}
@@ -0,0 +1,462 @@
+package controller
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cvs/internal/models"
+	"cvs/internal/service/exchange"
+	"cvs/internal/service/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// exchangesController handles read-only operations on the supported exchanges.
+type exchangesController struct {
+	allExchangesStorage exchange.AllExchanges // Storage for all exchanges
+	logger              logger.Logger
+}
+
+// NewExchangesController creates a new instance of exchangesController.
+//
+// Parameters:
+//   - allExchangesStorage: The storage for all exchanges, allowing access to exchange-related operations.
+//
+// Returns:
+//   - *exchangesController: A pointer to the initialized exchangesController instance.
+func NewExchangesController(
+	allExchangesStorage exchange.AllExchanges,
+	logger logger.Logger,
+) *exchangesController {
+	return &exchangesController{
+		allExchangesStorage: allExchangesStorage,
+		logger:              logger,
+	}
+}
+
+// GetExchanges retrieves the names of all supported exchanges sorted alphabetically.
+//
+// @Summary List exchanges
+// @Description Get the names of all supported exchanges, which are used as the exchange of user pairs
+// @Tags exchanges
+// @Produce json
+// @Success 200 {array} string "List of exchange names"
+// @Router /api/exchanges [get]
+func (ec *exchangesController) GetExchanges(c *fiber.Ctx) error {
+	exchangeNames := []string{}
+	for _, exchange := range ec.allExchangesStorage.All() {
+		exchangeNames = append(exchangeNames, exchange.ExchangeName())
+	}
+
+	sort.Strings(exchangeNames)
+
+	return c.JSON(exchangeNames) // Return list of exchange names in JSON format
+}
+
+// GetExchangePairs retrieves all pairs available on the exchange.
+//
+// The function performs the following steps:
+// 1. Retrieves the exchange by the name from the path.
+// 2. Returns 404 if the exchange is not supported.
+// 3. Filters the pairs of the exchange by the optional case-insensitive search substring.
+// 4. Returns a JSON response containing the list of pairs sorted by the pair name.
+//
+// @Summary List pairs of an exchange
+// @Description Get all pairs available on the exchange, which can be added as user pairs
+// @Tags exchanges
+// @Produce json
+// @Param name path string true "Exchange name" example(binance_spot)
+// @Param search query string false "Case-insensitive substring of the pair name" example(BTC)
+// @Success 200 {array} models.ExchangePairs "List of exchange pairs"
+// @Failure 404 {object} models.Response "Exchange not found"
+// @Router /api/exchanges/{name}/pairs [get]
+func (ec *exchangesController) GetExchangePairs(c *fiber.Ctx) error {
+	exchange, ok := ec.allExchangesStorage.Get(c.Params("name"))
+	if !ok {
+		c.Status(http.StatusNotFound)
+
+		return c.JSON(models.Response{
+			Result: "exchange not found", // Return error message in JSON format
+		})
+	}
+
+	search := strings.ToUpper(c.Query("search"))
+
+	pairs := []models.ExchangePairs{}
+	for _, pairData := range exchange.AllPairs() {
+		if strings.Contains(strings.ToUpper(pairData.Pair), search) {
+			pairs = append(pairs, pairData)
+		}
+	}
+
+	return c.JSON(pairs) // Return list of exchange pairs in JSON format
+}
+
+// SearchPairs searches the pairs of all exchanges by their base or quote asset.
+//
+// The function performs the following steps:
+// 1. Returns 400 if the search query is missing.
+// 2. Searches the pairs of every exchange whose base or quote asset contains the case-insensitive query.
+// 3. Returns a JSON response containing the found pairs grouped by the exchange, sorted by the exchange name.
+// The exchanges without found pairs are omitted.
+//
+// @Summary Search pairs across exchanges
+// @Description Get the pairs of all exchanges whose base or quote asset contains the query, grouped by the exchange
+// @Tags pairs
+// @Produce json
+// @Param q query string true "Case-insensitive substring of the base or quote asset" example(BTC)
+// @Success 200 {array} models.ExchangePairsGroup "Found pairs grouped by the exchange"
+// @Failure 400 {object} models.Response "Query is required"
+// @Router /api/pairs/search [get]
+func (ec *exchangesController) SearchPairs(c *fiber.Ctx) error {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.Status(http.StatusBadRequest)
+
+		return c.JSON(models.Response{
+			Result: "query is required", // Return error message in JSON format
+		})
+	}
+
+	groups := []models.ExchangePairsGroup{}
+	for _, exchange := range ec.allExchangesStorage.All() {
+		pairs := exchange.SearchPairs(query)
+		if len(pairs) == 0 {
+			continue // Omit the exchanges which don't list the asset
+		}
+
+		groups = append(groups, models.ExchangePairsGroup{
+			Exchange: exchange.ExchangeName(),
+			Pairs:    pairs,
+		})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Exchange < groups[j].Exchange
+	})
+
+	return c.JSON(groups) // Return found pairs grouped by the exchange in JSON format
+}
+
+// GetExchangeSpread retrieves the best prices, the spread and the mid price of a pair on the exchange.
+//
+// The function performs the following steps:
+// 1. Retrieves the exchange by the name from the path.
+// 2. Returns 404 if the exchange is not supported.
+// 3. Normalizes the pair from the query and returns 400 if it is missing.
+// 4. Returns 404 if there is no order book data for the pair, which is kept only for the subscribed pairs.
+// 5. Returns a JSON response containing the price snapshot of the pair.
+//
+// @Summary Get the spread of a pair
+// @Description Get the best bid, best ask, spread and mid price of a pair subscribed by any user. For a one-sided order book the spread and the mid price are zero
+// @Tags exchanges
+// @Produce json
+// @Param name path string true "Exchange name" example(binance_spot)
+// @Param pair query string true "Pair name" example(BTC/USDT)
+// @Success 200 {object} models.PriceSnapshot "Price snapshot of the pair"
+// @Failure 400 {object} models.Response "Pair is required"
+// @Failure 404 {object} models.Response "Exchange or order book not found"
+// @Router /api/exchanges/{name}/spread [get]
+func (ec *exchangesController) GetExchangeSpread(c *fiber.Ctx) error {
+	exchange, ok := ec.allExchangesStorage.Get(c.Params("name"))
+	if !ok {
+		c.Status(http.StatusNotFound)
+
+		return c.JSON(models.Response{
+			Result: "exchange not found", // Return error message in JSON format
+		})
+	}
+
+	pair := strings.ToUpper(strings.TrimSpace(c.Query("pair")))
+	if pair == "" {
+		c.Status(http.StatusBadRequest)
+
+		return c.JSON(models.Response{
+			Result: "pair is required", // Return error message in JSON format
+		})
+	}
+
+	snapshot, ok := exchange.BestPrices(pair)
+	if !ok {
+		c.Status(http.StatusNotFound)
+
+		return c.JSON(models.Response{
+			Result: "no orderbook data for the pair", // Return error message in JSON format
+		})
+	}
+
+	return c.JSON(snapshot) // Return price snapshot in JSON format
+}
+
+// GetExchangeOrderbook retrieves the price levels of the order book of a pair on the exchange.
+//
+// The function performs the following steps:
+// 1. Retrieves the exchange by the name from the path.
+// 2. Returns 404 if the exchange is not supported.
+// 3. Normalizes the pair from the query and returns 400 if it is missing.
+// 4. Returns 400 if the depth is not a non-negative integer, the depth of zero or no depth returns all levels.
+// 5. Returns 404 if there is no order book data for the pair, which is kept only for the subscribed pairs.
+// 6. Returns a JSON response containing the asks and the bids of the pair, both starting with the best price.
+//
+// @Summary Get the order book of a pair
+// @Description Get the asks sorted by price ascending and the bids sorted by price descending of a pair subscribed by any user
+// @Tags exchanges
+// @Produce json
+// @Param name path string true "Exchange name" example(binance_spot)
+// @Param pair query string true "Pair name" example(BTC/USDT)
+// @Param depth query int false "Maximum number of levels of each side, all levels if omitted or zero" example(20)
+// @Success 200 {object} models.OrderbookSnapshot "Order book of the pair"
+// @Failure 400 {object} models.Response "Pair is required or depth is invalid"
+// @Failure 404 {object} models.Response "Exchange or order book not found"
+// @Router /api/exchanges/{name}/orderbook [get]
+func (ec *exchangesController) GetExchangeOrderbook(c *fiber.Ctx) error {
+	exchange, ok := ec.allExchangesStorage.Get(c.Params("name"))
+	if !ok {
+		c.Status(http.StatusNotFound)
+
+		return c.JSON(models.Response{
+			Result: "exchange not found", // Return error message in JSON format
+		})
+	}
+
+	pair := strings.ToUpper(strings.TrimSpace(c.Query("pair")))
+	if pair == "" {
+		c.Status(http.StatusBadRequest)
+
+		return c.JSON(models.Response{
+			Result: "pair is required", // Return error message in JSON format
+		})
+	}
+
+	depth := 0 // All levels are returned by default
+	if depthParam := c.Query("depth"); depthParam != "" {
+		parsedDepth, err := strconv.Atoi(depthParam)
+		if err != nil || parsedDepth < 0 {
+			c.Status(http.StatusBadRequest)
+
+			return c.JSON(models.Response{
+				Result: "depth must be a non-negative integer", // Return error message in JSON format
+			})
+		}
+		depth = parsedDepth
+	}
+
+	snapshot, ok := exchange.OrderbookSnapshot(pair, depth)
+	if !ok {
+		c.Status(http.StatusNotFound)
+
+		return c.JSON(models.Response{
+			Result: "no orderbook data for the pair", // Return error message in JSON format
+		})
+	}
+
+	return c.JSON(snapshot) // Return order book snapshot in JSON format
+}
+
+// PreviewVolumes previews the volumes an exact value would find in the current order book of a pair on the exchange.
+//
+// The function performs the following steps:
+// 1. Retrieves the exchange by the name from the path.
+// 2. Returns 404 if the exchange is not supported.
+// 3. Normalizes the pair from the query and returns 400 if it is missing or invalid.
+// 4. Returns 400 if the value is not a positive number or the side is neither "asks" nor "bids".
+// 5. Returns 404 if the exchange doesn't list the pair or there is no order book data for it,
+// which is kept only for the subscribed pairs.
+// 6. Returns a JSON response containing the found volumes of the requested side, or of both sides if it is omitted.
+// Nothing is stored, so the preview doesn't trigger any notification.
+//
+// @Summary Preview the volumes of a value
+// @Description Search the current order book of a pair subscribed by any user for the volumes the exact value would find, without storing them
+// @Tags exchanges
+// @Produce json
+// @Param name path string true "Exchange name" example(binance_spot)
+// @Param pair query string true "Pair name" example(BTC/USDT)
+// @Param value query number true "Exact value the volumes must reach" example(10)
+// @Param side query string false "Side of the order book, both sides if omitted" Enums(asks, bids)
+// @Success 200 {array} models.FoundVolume "Volumes the value would find"
+// @Failure 400 {object} models.Response "Pair, value or side is invalid"
+// @Failure 404 {object} models.Response "Exchange, pair or order book not found"
+// @Router /api/exchanges/{name}/search-volume [get]
+func (ec *exchangesController) PreviewVolumes(c *fiber.Ctx) error {
+	exchange, ok := ec.allExchangesStorage.Get(c.Params("name"))
+	if !ok {
+		c.Status(http.StatusNotFound)
+
+		return c.JSON(models.Response{
+			Result: "exchange not found", // Return error message in JSON format
+			Code:   models.CodeNotFound,
+		})
+	}
+
+	pair, value, side, err := parseVolumeSearchQuery(c)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+
+		return c.JSON(models.Response{
+			Result: err.Error(), // Return error message in JSON format
+			Code:   models.CodeValidationFailed,
+		})
+	}
+
+	if !exchange.HasPair(pair) {
+		c.Status(http.StatusNotFound)
+
+		return c.JSON(models.Response{
+			Result: "pair not found", // Return error message in JSON format
+			Code:   models.CodeNotFound,
+		})
+	}
+
+	foundVolumes, ok := exchange.PreviewVolumes(pair, value)
+	if !ok {
+		c.Status(http.StatusNotFound)
+
+		return c.JSON(models.Response{
+			Result: "no orderbook data for the pair", // Return error message in JSON format
+			Code:   models.CodeNotFound,
+		})
+	}
+
+	return c.JSON(filterVolumesBySide(foundVolumes, side)) // Return the found volumes in JSON format
+}
+
+// AggregateVolumes previews the volumes an exact value would find in the current order books of a pair
+// across all exchanges listing it, so the biggest walls of the pair can be compared between the exchanges.
+//
+// The function performs the following steps:
+// 1. Normalizes the pair from the query and returns 400 if it is missing or invalid.
+// 2. Returns 400 if the value is not a positive number or the side is neither "asks" nor "bids".
+// 3. Previews the volumes on every exchange listing the pair, see PreviewVolumes. The exchanges without
+// the order book data of the pair, which is kept only for the subscribed pairs, or without found volumes are omitted.
+// 4. Returns 404 if no exchange lists the pair.
+// 5. Returns a JSON response containing the found volumes grouped by the exchange. The exchanges are sorted
+// by their total volume descending and the volumes of every exchange by the volume descending.
+// Nothing is stored, so the preview doesn't trigger any notification.
+//
+// @Summary Aggregate the volumes of a pair across exchanges
+// @Description Search the current order books of a pair on every exchange listing it for the volumes the exact value would find, grouped by the exchange and sorted by the volume
+// @Tags pairs
+// @Produce json
+// @Param pair query string true "Pair name" example(BTC/USDT)
+// @Param value query number true "Exact value the volumes must reach" example(10)
+// @Param side query string false "Side of the order book, both sides if omitted" Enums(asks, bids)
+// @Success 200 {object} models.AggregatedVolumes "Volumes the value would find grouped by the exchange"
+// @Failure 400 {object} models.Response "Pair, value or side is invalid"
+// @Failure 404 {object} models.Response "No exchange lists the pair"
+// @Router /api/pairs/aggregate-volume [get]
+func (ec *exchangesController) AggregateVolumes(c *fiber.Ctx) error {
+	pair, value, side, err := parseVolumeSearchQuery(c)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+
+		return c.JSON(models.Response{
+			Result: err.Error(), // Return error message in JSON format
+			Code:   models.CodeValidationFailed,
+		})
+	}
+
+	aggregated := models.AggregatedVolumes{
+		Pair:      pair,
+		Exchanges: []models.ExchangeVolumes{},
+	}
+	listed := false // Whether any exchange lists the pair
+
+	for _, exchange := range ec.allExchangesStorage.All() {
+		if !exchange.HasPair(pair) {
+			continue
+		}
+
+		listed = true
+
+		foundVolumes, ok := exchange.PreviewVolumes(pair, value)
+		if !ok {
+			continue // Nobody watches the pair on the exchange, so there is no order book to search
+		}
+
+		volumes := filterVolumesBySide(foundVolumes, side)
+		if len(volumes) == 0 {
+			continue // Omit the exchanges where the value finds nothing
+		}
+
+		sort.SliceStable(volumes, func(i, j int) bool {
+			return volumes[i].Volume > volumes[j].Volume
+		})
+
+		exchangeVolumes := models.ExchangeVolumes{
+			Exchange: exchange.ExchangeName(),
+			Volumes:  volumes,
+		}
+		for _, volume := range volumes {
+			exchangeVolumes.TotalVolume += volume.Volume
+		}
+
+		aggregated.TotalVolume += exchangeVolumes.TotalVolume
+		aggregated.Exchanges = append(aggregated.Exchanges, exchangeVolumes)
+	}
+
+	if !listed {
+		c.Status(http.StatusNotFound)
+
+		return c.JSON(models.Response{
+			Result: "pair not found", // Return error message in JSON format
+			Code:   models.CodeNotFound,
+		})
+	}
+
+	sort.Slice(aggregated.Exchanges, func(i, j int) bool {
+		if aggregated.Exchanges[i].TotalVolume != aggregated.Exchanges[j].TotalVolume {
+			return aggregated.Exchanges[i].TotalVolume > aggregated.Exchanges[j].TotalVolume
+		}
+
+		return aggregated.Exchanges[i].Exchange < aggregated.Exchanges[j].Exchange // Keep the order of equal volumes stable
+	})
+
+	return c.JSON(aggregated) // Return the found volumes grouped by the exchange in JSON format
+}
+
+// parseVolumeSearchQuery parses the pair, the exact value and the side of a volume search from the query.
+//
+// Returns:
+//   - The normalized pair, e.g. "BTC/USDT".
+//   - The positive value the volumes must reach.
+//   - The side of the order book, "asks" or "bids", or an empty string for both sides.
+//   - An error describing the invalid parameter, which is returned to the client.
+func parseVolumeSearchQuery(c *fiber.Ctx) (string, float64, string, error) {
+	if strings.TrimSpace(c.Query("pair")) == "" {
+		return "", 0, "", errors.New("pair is required")
+	}
+
+	pair, err := models.NormalizePair(c.Query("pair"))
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	value, err := strconv.ParseFloat(c.Query("value"), 64)
+	if err != nil || value <= 0 || math.IsInf(value, 0) {
+		return "", 0, "", errors.New("value must be a positive number")
+	}
+
+	side := strings.ToLower(strings.TrimSpace(c.Query("side")))
+	if side != "" && side != "asks" && side != "bids" {
+		return "", 0, "", errors.New("side must be asks or bids")
+	}
+
+	return pair, value, side, nil
+}
+
+// filterVolumesBySide returns the found volumes of the side, or all of them if the side is empty.
+// It returns an empty list rather than nil, so the response is an empty JSON array.
+func filterVolumesBySide(foundVolumes []models.FoundVolume, side string) []models.FoundVolume {
+	filtered := []models.FoundVolume{}
+	for _, volume := range foundVolumes {
+		if side == "" || volume.Side == side {
+			filtered = append(filtered, volume)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"cvs/internal/models"
+	"cvs/internal/service/exchange"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultReadinessStaleness = time.Minute // Staleness threshold used when none is configured
+
+// healthController handles the liveness and readiness probes of the service.
+type healthController struct {
+	allExchangesStorage exchange.AllExchanges // Storage for all exchanges
+	readinessStaleness  time.Duration         // Maximum time since the last successful fetch of a ready exchange
+}
+
+// NewHealthController creates a new instance of healthController.
+//
+// Parameters:
+//   - allExchangesStorage: The storage for all exchanges, allowing access to exchange-related operations.
+//   - readinessStaleness: The maximum time since the last successful fetch of an exchange with subscribed pairs
+//     before the exchange is reported as not ready. If it isn't positive, 1 minute is used.
+//
+// Returns:
+//   - *healthController: A pointer to the initialized healthController instance.
+func NewHealthController(
+	allExchangesStorage exchange.AllExchanges,
+	readinessStaleness time.Duration,
+) *healthController {
+	if readinessStaleness <= 0 {
+		readinessStaleness = defaultReadinessStaleness
+	}
+
+	return &healthController{
+		allExchangesStorage: allExchangesStorage,
+		readinessStaleness:  readinessStaleness,
+	}
+}
+
+// Health reports that the process is alive.
+//
+// @Summary Liveness probe
+// @Description Check that the process is alive
+// @Tags health
+// @Produce json
+// @Success 200 {object} models.Response "Process is alive"
+// @Router /health [get]
+func (hc *healthController) Health(c *fiber.Ctx) error {
+	return c.JSON(models.Response{
+		Result: "ok",
+	})
+}
+
+// Ready reports whether the data of all exchanges is fresh.
+//
+// An exchange is ready if its subscribed pairs have been loaded from the database and at least one fetch
+// from it has succeeded. An exchange with subscribed pairs must additionally have succeeded within
+// the staleness threshold, since its order book is expected to be updated continuously.
+// Exchanges without subscribers are only polled for their pairs on start.
+//
+// @Summary Readiness probe
+// @Description Report per exchange whether the last fetch succeeded and how stale the data is
+// @Tags health
+// @Produce json
+// @Success 200 {object} models.Readiness "All exchanges are ready"
+// @Failure 503 {object} models.Readiness "At least one exchange is not ready"
+// @Router /ready [get]
+func (hc *healthController) Ready(c *fiber.Ctx) error {
+	now := time.Now()
+	readiness := models.Readiness{
+		Ready:     true,
+		Exchanges: []models.ExchangeReadiness{},
+	}
+
+	for _, exchange := range hc.allExchangesStorage.All() {
+		status := exchange.Status()
+		exchangeReadiness := models.ExchangeReadiness{
+			ExchangeStatus: status,
+		}
+
+		if !status.LastSuccessfulFetch.IsZero() {
+			staleness := now.Sub(status.LastSuccessfulFetch)
+
+			exchangeReadiness.Staleness = staleness.Round(time.Millisecond).String()
+			exchangeReadiness.Ready = status.SubscriptionsLoaded && (status.SubscribedPairs == 0 || staleness <= hc.readinessStaleness)
+		}
+
+		readiness.Ready = readiness.Ready && exchangeReadiness.Ready
+		readiness.Exchanges = append(readiness.Exchanges, exchangeReadiness)
+	}
+
+	sort.Slice(readiness.Exchanges, func(i, j int) bool {
+		return readiness.Exchanges[i].Exchange < readiness.Exchanges[j].Exchange
+	})
+
+	if !readiness.Ready {
+		c.Status(http.StatusServiceUnavailable)
+	}
+
+	return c.JSON(readiness)
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"net/http"
+
+	"cvs/internal/models"
+	"cvs/internal/service"
+	"cvs/internal/service/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// sessionController handles operations related to the sessions of users.
+type sessionController struct {
+	sessionService service.SessionService // Service for managing the sessions of users
+	logger         logger.Logger
+}
+
+// NewSessionController creates a new instance of sessionController.
+//
+// Parameters:
+//   - sessionService: The service for managing the sessions of users.
+//   - logger: The logger of the failed requests.
+//
+// Returns:
+//   - *sessionController: A pointer to the initialized sessionController instance.
+func NewSessionController(sessionService service.SessionService, logger logger.Logger) *sessionController {
+	return &sessionController{
+		sessionService: sessionService,
+		logger:         logger,
+	}
+}
+
+// List retrieves the active sessions of the authenticated user, the most recently seen first.
+// The session which made the request is marked as the current one.
+//
+// @Summary List the sessions of the authenticated user
+// @Description Get the devices the authenticated user is logged in on. Every login starts a new session, which stays active until it is revoked or the user logs out of it.
+// @Tags user-sessions
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Success 200 {array} models.Session "Sessions of the user"
+// @Failure 500 {object} models.Response "Internal server error"
+// @Router /api/user/sessions [get]
+func (sc *sessionController) List(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User)          // Retrieve the authenticated user from context locals
+	current := c.Locals("session").(models.Session) // Retrieve the session which made the request
+
+	sessions, err := sc.sessionService.GetUserSessions(c.Context(), user.ID)
+	if err != nil {
+		return c.JSON(errorResponse(c, sc.logger, err, zap.Int("user_id", user.ID))) // Return error message in JSON format
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == current.ID
+	}
+
+	return c.JSON(sessions) // Return the sessions in JSON format
+}
+
+// Revoke revokes a session of the authenticated user, so the access and refresh tokens issued for it
+// are rejected from now on. Revoking the current session logs the user out like the logout does.
+//
+// The function performs the following steps:
+// 1. Parses the ID of the session from the path, returning 400 if it isn't a positive number.
+// 2. Deletes the session of the user, returning 404 if the user has no session with the ID.
+// 3. Returns a JSON response indicating success or failure.
+//
+// @Summary Revoke a session of the authenticated user
+// @Description Log the authenticated user out of a device. The access and refresh tokens of the session stop being accepted.
+// @Tags user-sessions
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param id path int true "Session ID" example(1234)
+// @Success 200 {object} models.Response "Session revoked"
+// @Failure 400 {object} models.Response "Invalid session ID"
+// @Failure 404 {object} models.Response "Session not found"
+// @Failure 500 {object} models.Response "Internal server error"
+// @Router /api/user/sessions/{id} [delete]
+func (sc *sessionController) Revoke(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User) // Retrieve the authenticated user from context locals
+
+	sessionId, err := c.ParamsInt("id")
+	if err != nil || sessionId < 1 {
+		c.Status(http.StatusBadRequest)
+
+		return c.JSON(models.Response{
+			Result: "invalid session id", // Return error message in JSON format
+			Code:   models.CodeInvalidInput,
+		})
+	}
+
+	if err := sc.sessionService.DeleteSession(c.Context(), user.ID, sessionId); err != nil {
+		return c.JSON(errorResponse(c, sc.logger, err, zap.Int("user_id", user.ID))) // Return error message in JSON format
+	}
+
+	return c.JSON(models.Response{
+		Result: "session revoked successfully", // Return success message in JSON format
+	})
+}
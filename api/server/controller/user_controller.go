@@ -2,10 +2,15 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
 	"math/rand"
 	"net/http"
 
-	"cvs/internal/models"  // Importing the models package for user data structures
+	"cvs/internal/config"
+	"cvs/internal/models" // Importing the models package for user data structures
+	"cvs/internal/repository"
 	"cvs/internal/service" // Importing the service package for user and JWT services
 	"cvs/internal/service/exchange"
 	"cvs/internal/service/logger"
@@ -14,33 +19,52 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	maxRandomId        = math.MaxInt32 // Upper bound of the randomly generated session IDs and password versions
+	maxUserAgentLength = 512           // Maximum length of the user agent stored with a session
+)
+
+var errRefreshTokenReused = errors.New("refresh token reuse detected, log in again")
+
 // userController handles user-related operations.
 type userController struct {
-	userService         service.UserService   // Service for managing user data
-	allExchangesStorage exchange.AllExchanges // Storage for all exchanges
-	jwtService          service.JwtService    // Service for managing JWT tokens
+	userService         service.UserService         // Service for managing user data
+	sessionService      service.SessionService      // Service for managing the sessions of users
+	allExchangesStorage exchange.AllExchanges       // Storage for all exchanges
+	jwtService          service.JwtService          // Service for managing JWT tokens
+	emailService        service.EmailService        // Service for sending emails to users
+	passwordPolicy      config.PasswordPolicyConfig // Rules the new passwords of users must satisfy
 	logger              logger.Logger
 }
 
 // NewUserController creates a new instance of userController.
-// It takes a UserService and a JwtService as dependencies.
+// It takes a UserService, a SessionService and a JwtService as dependencies.
 //
 // Parameters:
 //   - userService: A service for managing user data.
+//   - sessionService: A service for managing the sessions of users.
 //   - jwtService: A service for managing JWT tokens.
+//   - emailService: A service for sending emails to users.
+//   - passwordPolicy: The rules the passwords set on signup, update and reset must satisfy.
 //
 // Returns:
 //   - A pointer to a new userController instance.
 func NewUserController(
 	userService service.UserService,
+	sessionService service.SessionService,
 	jwtService service.JwtService,
+	emailService service.EmailService,
 	allExchangesStorage exchange.AllExchanges,
+	passwordPolicy config.PasswordPolicyConfig,
 	logger logger.Logger,
 ) *userController {
 	return &userController{
 		userService:         userService,
+		sessionService:      sessionService,
 		allExchangesStorage: allExchangesStorage,
 		jwtService:          jwtService,
+		emailService:        emailService,
+		passwordPolicy:      passwordPolicy,
 		logger:              logger,
 	}
 }
@@ -48,20 +72,24 @@ func NewUserController(
 // Signup handles the user registration process by parsing the incoming request,
 // validating the user data, and inserting the new user into the database.
 // It also generates access and refresh tokens for the newly registered user.
+// The user is created unverified, so the tokens are accepted only after the email is verified
+// by the link sent to it.
 //
 // The function performs the following steps:
 // 1. Initializes a struct to hold new user data.
 // 2. Parses the request body into the `newUserData` struct.
 // 3. Creates a `User` object from the parsed email.
 // 4. Sets the user's password and handles any errors that may occur.
-// 5. Validates the user data (e.g., email format).
-// 6. Attempts to insert the new user into the database and retrieves the user ID.
-// 7. Generates access and refresh tokens for the newly created user.
-// 8. Sets the refresh token for the user object and updates it in the database.
-// 9. Returns a JSON response containing tokens data if successful, or an error message if any step fails.
+// 5. Validates the user data (e.g., email format) and the strength of the password.
+// 6. Inserts the new user into the database along with the first session in a single transaction,
+// returns 409 if a user with the email already exists.
+// 7. Generates access and refresh tokens for the newly created user inside the transaction.
+// 8. Stores the refresh token hash with the session, so no user is stored without it.
+// 9. Sends the email verification link to the user's email in the background.
+// 10. Returns a JSON response containing tokens data if successful, or an error message if any step fails.
 //
 // @Summary Sign up a new user
-// @Description Create a new user account with email and password.
+// @Description Create a new user account with email and password. The account is active once the email is verified by the link sent to it.
 // @Description Returns the access token, the refresh token, and the time when the access token ceases to be valid. After the access token has ceased to be valid, you need to send a request along the path "/api/user/auth/token" to get a new pair of tokens.
 // @Tags users
 // @Accept json
@@ -69,7 +97,9 @@ func NewUserController(
 // @Param user body models.UserAuth true "User registration data"
 // @Success 200 {object} models.Tokens "Successful response with tokens data"
 // @Failure 400 {object} models.Response "Invalid input data"
+// @Failure 409 {object} models.Response "Email already registered"
 // @Failure 500 {object} models.Response "Internal server error"
+// @Failure 429 {object} models.Response "Too many requests"
 // @Router /api/user/auth/signup [post]
 func (uc *userController) Signup(c *fiber.Ctx) error {
 	newUserData := models.UserAuth{} // Initialize a struct to hold new user data
@@ -81,109 +111,226 @@ func (uc *userController) Signup(c *fiber.Ctx) error {
 		uc.logger.Error(err)
 
 		return c.JSON(models.Response{
-			Result: err.Error(), // Return error message in JSON format if parsing fails
+			Result: errInvalidInputData, // Return error message in JSON format if parsing fails
+			Code:   models.CodeInvalidInput,
 		})
 	}
 
 	// Create a User object from the parsed email
 	user := models.User{
 		Email: newUserData.Email,
+		Role:  models.RoleUser, // Every user signs up with the user role
 	}
 
 	// Set the user's password using the provided password and handle any errors
 	if err := user.SetPassword(newUserData.Password); err != nil {
-		uc.logger.Error(err)
-
-		return c.JSON(models.Response{
-			Result: err.Error(), // Return error message in JSON format if setting password fails
-		})
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error message in JSON format if hashing the password fails
 	}
 
 	// Validate the user data (e.g., email format, etc.)
 	if err := service.CheckUserData(user); err != nil {
-		uc.logger.Error(err)
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return the validation error in JSON format
+	}
 
-		return c.JSON(models.Response{
-			Result: err.Error(), // Return error message in JSON format if validation fails
-		})
+	// Check the password satisfies the password policy
+	if err := service.CheckPasswordStrength(newUserData.Password, uc.passwordPolicy); err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return the failed rule in JSON format
 	}
 
 	c.Status(http.StatusInternalServerError) // Set response status to Internal Server Error for potential database issues
 
-	user.SessionID = 1 // Set the user's session ID to an intermediate value
+	user.PasswordVersion = newRandomId() // Set the version the password reset tokens are bound to
 
-	// Insert the new user into the database and retrieve the user ID
-	userId, err := uc.userService.InsertUser(c.Context(), user)
-	if err != nil {
-		uc.logger.Error(err)
+	var tokensData models.Tokens // Tokens issued for the newly created user
+
+	// Insert the new user into the database along with the first session and retrieve the user ID.
+	// The tokens are issued for the user ID, so they are generated once the user is inserted,
+	// and the user isn't stored if they can't be.
+	userId, err := uc.userService.InsertUserWithSession(c.Context(), user, func(newUser *models.User) (models.Session, error) {
+		session, tokens, err := uc.newSession(c, *newUser)
+		tokensData = tokens
+
+		return session, err
+	})
+	if errors.Is(err, repository.ErrEmailAlreadyExists) {
+		c.Status(http.StatusConflict)
 
 		return c.JSON(models.Response{
-			Result: err.Error(), // Return error message in JSON format if insertion fails
+			Result: errEmailRegistered, // Return conflict message in JSON format
+			Code:   models.CodeConflict,
 		})
 	}
+	if err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error message in JSON format if insertion fails
+	}
 
 	user.ID = userId
 
-	tokensData, err := uc.updateTokens(user)
+	uc.sendVerifyEmail(user)
+
+	return c.Status(http.StatusOK).JSON(tokensData) // Return tokens data in JSON format with a 200 OK status
+}
+
+// VerifyEmail handles the request to verify the user's email using the email verification token.
+//
+// This method performs the following steps:
+// 1. Validates the verification token from the query and retrieves the user it was issued for.
+// 2. Returns 409 if the email of the user is already verified.
+// 3. Marks the email of the user as verified, so the account becomes active.
+//
+// @Summary Verify user email
+// @Description Verify the email of the user using the token sent by "/api/user/auth/signup". The token is valid for 24 hours.
+// @Tags users
+// @Produce json
+// @Param token query string true "Email verification token"
+// @Success 200 {object} models.Response "Email verified"
+// @Failure 400 {object} models.Response "Invalid or expired verification token"
+// @Failure 409 {object} models.Response "Email already verified"
+// @Failure 500 {object} models.Response "Internal server error"
+// @Router /api/user/auth/verify [get]
+func (uc *userController) VerifyEmail(c *fiber.Ctx) error {
+	c.Status(http.StatusBadRequest) // Set response status to Bad Request initially
+
+	// Parse the verification token to extract the user ID
+	userId, err := uc.jwtService.ParseVerifyEmailToken(c.Query("token"))
 	if err != nil {
 		uc.logger.Error(err)
 
 		return c.JSON(models.Response{
-			Result: err.Error(), // Return error message in JSON format if updating refresh token fails
+			Result: "invalid or expired verification token", // Return error message in JSON format
+			Code:   models.CodeInvalidToken,
 		})
 	}
 
-	return c.Status(http.StatusOK).JSON(tokensData) // Return tokens data in JSON format with a 200 OK status
+	// Retrieve the user the token was issued for
+	user, err := uc.userService.GetUserById(c.Context(), userId)
+	if err != nil || user.ID != userId {
+		uc.logger.Error(
+			err,
+			zap.Int("user_id", userId),
+		)
+
+		return c.JSON(models.Response{
+			Result: "user not found", // Return error message in JSON format
+			Code:   models.CodeNotFound,
+		})
+	}
+
+	if user.Verified {
+		c.Status(http.StatusConflict)
+
+		return c.JSON(models.Response{
+			Result: "email is already verified", // Return error message in JSON format
+			Code:   models.CodeConflict,
+		})
+	}
+
+	if err := uc.userService.VerifyUser(c.Context(), user.ID); err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err, zap.Int("user_id", user.ID))) // Return error message in JSON format if verifying fails
+	}
+
+	return c.Status(http.StatusOK).JSON(models.Response{
+		Result: "email verified successfully",
+	})
 }
 
-// Tokens handles the refresh token operation for an authenticated user.
+// Tokens handles the refresh token operation.
 // It retrieves the refresh token from the request header and validates it.
-// If valid, it generates new access and refresh tokens for the user.
+// If valid, it rotates the refresh token and generates new access and refresh tokens for the user.
+//
+// Every refresh token can be used only once. A refresh token which doesn't match the one stored with
+// its session means that an already rotated token was reused, so the session is revoked to log out
+// whoever holds the newest tokens as well.
 //
 // This method performs the following steps:
-// 1. Retrieves the user object from the context, which was set during authentication.
-// 2. Extracts the refresh token from the Authorization header of the request.
-// 3. Validates the provided refresh token against the stored token for the user.
-// 4. If validation is successful, generates new access and refresh tokens for the user.
+// 1. Extracts the refresh token from the Authorization header of the request.
+// 2. Parses the refresh token and retrieves the user and the session it was issued for.
+// 3. Validates the refresh token against the one stored with the session,
+// revoking the session if they don't match.
+// 4. If validation is successful, rotates the refresh token of the session and generates new access and refresh tokens.
 // 5. Returns the newly generated tokens in JSON format upon successful operation.
 //
 // @Summary Get new tokens
-// @Description Retrieve new access and refresh tokens for the authenticated user
+// @Description Retrieve new access and refresh tokens for the authenticated user.
+// @Description Every refresh token can be used only once. Reusing a refresh token invalidates the session, so the user has to log in again.
 // @Tags users
 // @Param Authorization header string true "Refresh token"
 // @Success 200 {object} models.Tokens "Successful response with new tokens"
-// @Failure 401 {object} models.Response "Invalid refresh token"
+// @Failure 401 {object} models.Response "Invalid, revoked or reused refresh token"
 // @Failure 500 {object} models.Response "Internal server error"
 // @Router /api/user/auth/tokens [get]
 func (uc *userController) Tokens(c *fiber.Ctx) error {
-	// Retrieve the user object from the context, which was set during authentication.
-	user := c.Locals("user").(models.User)
-
 	// Get the refresh token from the request header (Authorization header).
 	refreshToken := c.Get("Authorization")
 
-	// Compare the provided refresh token with the one stored for the user.
-	err := user.CompareRefreshToken(refreshToken)
-	if err != nil {
+	c.Status(http.StatusUnauthorized) // Set response status to Unauthorized (401) initially
+
+	// Parse the refresh token to extract the user ID and session ID.
+	userId, sessionId, err := uc.jwtService.ParseRefreshToken(refreshToken)
+	if err != nil || userId < 1 {
 		uc.logger.Error(err)
 
-		c.Status(http.StatusUnauthorized) // Set response status to Unauthorized (401)
+		return c.JSON(models.Response{
+			Result: "invalid refresh token", // Return error message in JSON format
+			Code:   models.CodeInvalidToken,
+		})
+	}
+
+	// Retrieve the user the refresh token was issued for.
+	user, err := uc.userService.GetUserById(c.Context(), userId)
+	if err != nil || user.ID != userId {
+		uc.logger.Error(
+			err,
+			zap.Int("user_id", userId),
+		)
 
 		return c.JSON(models.Response{
-			Result: err.Error(), // Return error message in JSON format
+			Result: "user not found", // Return error message in JSON format
+			Code:   models.CodeNotFound,
 		})
 	}
 
-	newTokens, err := uc.updateTokens(user)
+	// Retrieve the session the refresh token was issued for, it doesn't exist once revoked.
+	session, err := uc.sessionService.GetSession(c.Context(), user.ID, sessionId)
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		return c.JSON(models.Response{
+			Result: "session was revoked, log in again", // Return error message in JSON format
+			Code:   models.CodeInvalidToken,
+		})
+	}
 	if err != nil {
-		c.Status(http.StatusInternalServerError)
+		return c.JSON(errorResponse(c, uc.logger, err, zap.Int("user_id", user.ID))) // Return error message in JSON format if retrieving the session fails
+	}
+
+	// A valid refresh token which doesn't match the stored one was already rotated, so it is reused.
+	if session.CompareRefreshToken(refreshToken) != nil {
+		uc.revokeSession(session)
 
 		return c.JSON(models.Response{
-			Result: err.Error(), // Return error message in JSON format if updating refresh token fails
+			Result: errRefreshTokenReused.Error(), // Return error message in JSON format
+			Code:   models.CodeInvalidToken,
 		})
 	}
 
-	return c.JSON(newTokens) // Return new tokens in JSON format with a 200 OK status
+	newTokens, err := uc.rotateTokens(user, session)
+	if errors.Is(err, errRefreshTokenReused) {
+		uc.logger.Error(
+			err,
+			zap.Int("user_id", user.ID),
+		)
+
+		uc.revokeSession(session) // The token was rotated by a concurrent request with the same refresh token
+
+		return c.JSON(models.Response{
+			Result: errRefreshTokenReused.Error(), // Return error message in JSON format
+			Code:   models.CodeInvalidToken,
+		})
+	}
+	if err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err, zap.Int("user_id", user.ID))) // Return error message in JSON format if generating tokens fails
+	}
+
+	return c.Status(http.StatusOK).JSON(newTokens) // Return new tokens in JSON format with a 200 OK status
 }
 
 // Login handles user authentication by processing the login request.
@@ -193,7 +340,8 @@ func (uc *userController) Tokens(c *fiber.Ctx) error {
 // 1. Parses the incoming request body to extract user credentials (email and password).
 // 2. Retrieves the user from the database using the provided email address.
 // 3. Validates the user's existence and checks if the provided password matches the stored password.
-// 4. If authentication is successful, it generates new access and refresh tokens for the user.
+// 4. If authentication is successful, it starts a new session with new access and refresh tokens,
+// the other sessions of the user stay active.
 // 5. Returns the newly generated tokens in JSON format.
 //
 // @Summary Log in a user
@@ -205,6 +353,7 @@ func (uc *userController) Tokens(c *fiber.Ctx) error {
 // @Success 200 {object} models.Tokens "New tokens data"
 // @Failure 400 {object} models.Response "Invalid input data"
 // @Failure 500 {object} models.Response "Internal server error"
+// @Failure 429 {object} models.Response "Too many requests"
 // @Router /api/user/auth/login [post]
 func (uc *userController) Login(c *fiber.Ctx) error {
 	userDataRequest := models.UserAuth{} // Initialize a struct to hold user credentials
@@ -216,7 +365,8 @@ func (uc *userController) Login(c *fiber.Ctx) error {
 		uc.logger.Error(err)
 
 		return c.JSON(models.Response{
-			Result: err.Error(), // Return error message in JSON format if parsing fails
+			Result: errInvalidInputData, // Return error message in JSON format if parsing fails
+			Code:   models.CodeInvalidInput,
 		})
 	}
 
@@ -227,6 +377,7 @@ func (uc *userController) Login(c *fiber.Ctx) error {
 
 		return c.JSON(models.Response{
 			Result: "The user was not found", // Return error message in JSON format if user not found or email mismatch
+			Code:   models.CodeInvalidCredentials,
 		})
 	}
 
@@ -236,18 +387,13 @@ func (uc *userController) Login(c *fiber.Ctx) error {
 
 		return c.JSON(models.Response{
 			Result: "invalid password", // Return error message in JSON format if password is invalid
+			Code:   models.CodeInvalidCredentials,
 		})
 	}
 
-	newTokens, err := uc.updateTokens(userFromDB)
+	newTokens, err := uc.startSession(c, userFromDB)
 	if err != nil {
-		uc.logger.Error(err)
-
-		c.Status(http.StatusInternalServerError)
-
-		return c.JSON(models.Response{
-			Result: err.Error(), // Return error message in JSON format if updating refresh token fails
-		})
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error message in JSON format if starting the session fails
 	}
 
 	return c.Status(http.StatusOK).JSON(newTokens) // Return new tokens in JSON format with a 200 OK status
@@ -260,12 +406,13 @@ func (uc *userController) Login(c *fiber.Ctx) error {
 // 1. Parses the incoming request body to extract the old and new passwords.
 // 2. Retrieves the authenticated user object from the context.
 // 3. Validates the provided old password against the stored password.
-// 4. If validation is successful, it sets the new password and refresh token for the user.
-// 5. Updates the user's password in the database and generates new access and refresh tokens.
+// 4. If validation is successful, it checks the new password satisfies the password policy and sets it for the user.
+// 5. Updates the user's password in the database, revokes all sessions of the user
+// and starts a new one with new access and refresh tokens.
 // 6. Returns the newly generated tokens in JSON format upon successful update.
 //
 // @Summary Update user password
-// @Description Update the password for the authenticated user.
+// @Description Update the password for the authenticated user. All sessions of the user are revoked, so the other devices have to log in again.
 // @Description Returns the access token, the refresh token, and the time when the access token ceases to be valid. After the access token has ceased to be valid, you need to send a request along the path "/api/user/auth/token" to get a new pair of tokens.
 // @Tags users
 // @Accept json
@@ -273,7 +420,7 @@ func (uc *userController) Login(c *fiber.Ctx) error {
 // @Param Authorization header string true "Access token"
 // @Param passwords body models.PasswordUpdate true "Passwords data"
 // @Success 200 {object} models.Tokens "New tokens data"
-// @Failure 400 {object} models.Response "Invalid password"
+// @Failure 400 {object} models.Response "Invalid old password or weak new password"
 // @Failure 500 {object} models.Response "Internal server error"
 // @Router /api/user/update-password [put]
 func (uc *userController) UpdatePassword(c *fiber.Ctx) error {
@@ -286,7 +433,8 @@ func (uc *userController) UpdatePassword(c *fiber.Ctx) error {
 		uc.logger.Error(err)
 
 		return c.JSON(models.Response{
-			Result: err.Error(), // Return error message in JSON format if parsing fails
+			Result: errInvalidInputData, // Return error message in JSON format if parsing fails
+			Code:   models.CodeInvalidInput,
 		})
 	}
 
@@ -299,13 +447,41 @@ func (uc *userController) UpdatePassword(c *fiber.Ctx) error {
 
 		return c.JSON(models.Response{
 			Result: "invalid old password", // Return error message in JSON format if old password is invalid
+			Code:   models.CodeInvalidCredentials,
 		})
 	}
 
+	// Check the new password satisfies the password policy
+	if err := service.CheckPasswordStrength(passwordData.NewPassword, uc.passwordPolicy); err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return the failed rule in JSON format
+	}
+
 	c.Status(http.StatusInternalServerError) // Set response status to Internal Server Error (500)
 
-	// Generate new access and refresh tokens for the user after updating their password
-	newTokens, sessionId, err := uc.generateTokens(user.ID)
+	// Set the new password in the user object
+	if err := user.SetPassword(passwordData.NewPassword); err != nil {
+		uc.logger.Error(err)
+
+		return c.JSON(models.Response{
+			Result: "user update failed", // Return error message in JSON format if hashing the password fails
+			Code:   models.CodeInternal,
+		})
+	}
+	user.PasswordVersion = newRandomId() // Invalidate the password reset tokens issued before the update
+
+	// Update the user's password in the database
+	err := uc.userService.UpdatePassword(c.Context(), user)
+	if err != nil {
+		uc.logger.Error(err)
+
+		return c.JSON(models.Response{
+			Result: "user update failed", // Return error message in JSON format if updating password fails
+			Code:   models.CodeInternal,
+		})
+	}
+
+	// Log the user out of every device and start a new session for the current one
+	newTokens, err := uc.restartSessions(c, user)
 	if err != nil {
 		uc.logger.Error(
 			err,
@@ -313,23 +489,329 @@ func (uc *userController) UpdatePassword(c *fiber.Ctx) error {
 		)
 
 		return c.JSON(models.Response{
-			Result: "user update failed", // Return error message in JSON format if token generation fails
+			Result: "user update failed", // Return error message in JSON format if restarting the sessions fails
+			Code:   models.CodeInternal,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(newTokens) // Return new tokens in JSON format with a 200 OK status
+}
+
+// ChangeEmail handles the request to change the email of the authenticated user.
+// The email isn't changed until it is confirmed by the link sent to the new email,
+// so the user can still log in with the current email until then.
+//
+// This method performs the following steps:
+// 1. Parses the incoming request body to extract the new email and validates its format.
+// 2. Returns 400 if the new email is the current email of the user.
+// 3. Stores the new email as the pending email of the user, returning 409 if another user is registered with it.
+// 4. Sends the confirmation link to the new email in the background.
+//
+// @Summary Change user email
+// @Description Request a change of the email of the authenticated user. The email is changed once it is confirmed by the link sent to the new email, the link is valid for 24 hours.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param email body models.EmailChange true "New email"
+// @Success 200 {object} models.Response "Confirmation link sent"
+// @Failure 400 {object} models.Response "Invalid email"
+// @Failure 409 {object} models.Response "Email already registered"
+// @Failure 500 {object} models.Response "Internal server error"
+// @Router /api/user/auth/email [put]
+func (uc *userController) ChangeEmail(c *fiber.Ctx) error {
+	emailData := models.EmailChange{} // Initialize a struct to hold the new email
+
+	c.Status(http.StatusBadRequest) // Set response status to Bad Request initially
+
+	// Parse the request body into the emailData struct
+	if err := c.BodyParser(&emailData); err != nil {
+		uc.logger.Error(err)
+
+		return c.JSON(models.Response{
+			Result: errInvalidInputData, // Return error message in JSON format if parsing fails
+			Code:   models.CodeInvalidInput,
+		})
+	}
+
+	if err := service.CheckEmail(emailData.Email); err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return validation error message in JSON format
+	}
+
+	// Retrieve the user object from the context locals, which was set during authentication
+	user := c.Locals("user").(models.User)
+
+	if emailData.Email == user.Email {
+		return c.JSON(models.Response{
+			Result: "the new email is the current one", // Return error message in JSON format as there is nothing to change
+			Code:   models.CodeValidationFailed,
+		})
+	}
+
+	err := uc.userService.SetPendingEmail(c.Context(), user.ID, emailData.Email)
+	if errors.Is(err, repository.ErrEmailAlreadyExists) {
+		c.Status(http.StatusConflict)
+
+		return c.JSON(models.Response{
+			Result: errEmailRegistered, // Return conflict message in JSON format
+			Code:   models.CodeConflict,
+		})
+	}
+	if err != nil {
+		uc.logger.Error(
+			err,
+			zap.Int("user_id", user.ID),
+		)
+
+		c.Status(http.StatusInternalServerError)
+
+		return c.JSON(models.Response{
+			Result: "email change failed", // Return error message in JSON format if storing the email fails
+			Code:   models.CodeInternal,
+		})
+	}
+
+	uc.sendChangeEmail(user.ID, emailData.Email)
+
+	return c.Status(http.StatusOK).JSON(models.Response{
+		Result: "confirmation link sent to the new email",
+	})
+}
+
+// ConfirmEmail handles the request to confirm the new email of the user using the email change token.
+//
+// This method performs the following steps:
+// 1. Validates the email change token from the query and retrieves the user ID and the new email it was issued for.
+// 2. Replaces the email of the user with the new one if it is still the pending email of the user.
+// 3. Returns 409 if another user has registered with the new email since the change was requested.
+//
+// @Summary Confirm user email change
+// @Description Confirm the new email of the user using the token sent by "/api/user/auth/email". The token is valid for 24 hours.
+// @Tags users
+// @Produce json
+// @Param token query string true "Email change token"
+// @Success 200 {object} models.Response "Email changed"
+// @Failure 400 {object} models.Response "Invalid, expired or superseded email change token"
+// @Failure 409 {object} models.Response "Email already registered"
+// @Failure 500 {object} models.Response "Internal server error"
+// @Router /api/user/auth/email/confirm [get]
+func (uc *userController) ConfirmEmail(c *fiber.Ctx) error {
+	c.Status(http.StatusBadRequest) // Set response status to Bad Request initially
+
+	// Parse the email change token to extract the user ID and the new email
+	userId, email, err := uc.jwtService.ParseChangeEmailToken(c.Query("token"))
+	if err != nil {
+		uc.logger.Error(err)
+
+		return c.JSON(models.Response{
+			Result: "invalid or expired email change token", // Return error message in JSON format
+			Code:   models.CodeInvalidToken,
+		})
+	}
+
+	err = uc.userService.ConfirmEmail(c.Context(), userId, email)
+	if errors.Is(err, repository.ErrPendingEmailNotFound) {
+		return c.JSON(errorResponse(c, uc.logger, err)) // The change was already confirmed or another email was requested after it
+	}
+	if errors.Is(err, repository.ErrEmailAlreadyExists) {
+		c.Status(http.StatusConflict)
+
+		return c.JSON(models.Response{
+			Result: errEmailRegistered, // Return conflict message in JSON format
+			Code:   models.CodeConflict,
+		})
+	}
+	if err != nil {
+		uc.logger.Error(
+			err,
+			zap.Int("user_id", userId),
+		)
+
+		c.Status(http.StatusInternalServerError)
+
+		return c.JSON(models.Response{
+			Result: "email change failed", // Return error message in JSON format if replacing the email fails
+			Code:   models.CodeInternal,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(models.Response{
+		Result: "email changed successfully",
+	})
+}
+
+// ForgotPassword handles the request to send a password reset token to the user's email.
+// The response doesn't reveal whether the email is registered.
+//
+// This method performs the following steps:
+// 1. Parses the incoming request body to extract the user's email.
+// 2. Retrieves the user from the database using the provided email address.
+// 3. If the user exists, creates a password reset token bound to the user's current password version.
+// 4. Sends the token to the user's email in the background.
+// 5. Returns the same successful response for registered and unknown emails.
+//
+// @Summary Request a password reset
+// @Description Send a password reset token to the user's email. The token is valid for 15 minutes and is invalidated once the password is changed or reset.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param email body models.PasswordForgot true "User email"
+// @Success 200 {object} models.Response "Successful response"
+// @Failure 400 {object} models.Response "Invalid input data"
+// @Failure 429 {object} models.Response "Too many requests"
+// @Router /api/user/auth/forgot-password [post]
+func (uc *userController) ForgotPassword(c *fiber.Ctx) error {
+	forgotData := models.PasswordForgot{} // Initialize a struct to hold the user's email
+
+	// Parse the request body into the forgotData struct
+	if err := c.BodyParser(&forgotData); err != nil {
+		uc.logger.Error(err)
+
+		c.Status(http.StatusBadRequest) // Set response status to Bad Request
+
+		return c.JSON(models.Response{
+			Result: errInvalidInputData, // Return error message in JSON format if parsing fails
+			Code:   models.CodeInvalidInput,
+		})
+	}
+
+	response := models.Response{
+		Result: "if the email is registered, a password reset link has been sent",
+	}
+
+	// Retrieve the user from the database using their email
+	user, err := uc.userService.GetUserByEmail(c.Context(), forgotData.Email)
+	if err != nil || user.Email != forgotData.Email {
+		return c.JSON(response) // Respond the same way to not reveal which emails are registered
+	}
+
+	// Create a reset token bound to the password version, so changing the password invalidates it
+	token, err := uc.jwtService.CreateResetPasswordToken(user.ID, user.PasswordVersion)
+	if err != nil {
+		uc.logger.Error(
+			err,
+			zap.Int("user_id", user.ID),
+		)
+
+		return c.JSON(response)
+	}
+
+	// Send the email in the background to not slow down the response for registered emails
+	go func() {
+		if err := uc.emailService.SendResetPasswordToken(user.Email, token); err != nil {
+			uc.logger.Error(
+				err,
+				zap.Int("user_id", user.ID),
+			)
+		}
+	}()
+
+	return c.JSON(response)
+}
+
+// ResetPassword handles the request to set a new password using a password reset token.
+//
+// This method performs the following steps:
+// 1. Parses the incoming request body to extract the reset token and the new password,
+// and checks the new password satisfies the password policy.
+// 2. Validates the reset token and retrieves the user it was issued for.
+// 3. Checks that the token belongs to the user's current session, so it can be used only once.
+// 4. Sets the new password and generates new access and refresh tokens for the user.
+// 5. Returns the newly generated tokens in JSON format upon successful update.
+//
+// @Summary Reset user password
+// @Description Set a new password using the token sent by "/api/user/auth/forgot-password".
+// @Description Returns the access token, the refresh token, and the time when the access token ceases to be valid.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param passwords body models.PasswordReset true "Reset token and new password"
+// @Success 200 {object} models.Tokens "New tokens data"
+// @Failure 400 {object} models.Response "Invalid reset token or password"
+// @Failure 500 {object} models.Response "Internal server error"
+// @Router /api/user/auth/reset-password [post]
+func (uc *userController) ResetPassword(c *fiber.Ctx) error {
+	resetData := models.PasswordReset{} // Initialize a struct to hold password reset data
+
+	c.Status(http.StatusBadRequest) // Set response status to Bad Request initially
+
+	// Parse the request body into the resetData struct
+	if err := c.BodyParser(&resetData); err != nil {
+		uc.logger.Error(err)
+
+		return c.JSON(models.Response{
+			Result: errInvalidInputData, // Return error message in JSON format if parsing fails
+			Code:   models.CodeInvalidInput,
+		})
+	}
+
+	if resetData.NewPassword != resetData.NewPasswordRepeat {
+		return c.JSON(models.Response{
+			Result: "passwords do not match", // Return error message in JSON format if the new passwords differ
+			Code:   models.CodeValidationFailed,
+		})
+	}
+
+	// Check the new password satisfies the password policy
+	if err := service.CheckPasswordStrength(resetData.NewPassword, uc.passwordPolicy); err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return the failed rule in JSON format
+	}
+
+	// Validate the reset token and extract the user it was issued for
+	userId, passwordVersion, err := uc.jwtService.ParseResetPasswordToken(resetData.Token)
+	if err != nil {
+		uc.logger.Error(err)
+
+		return c.JSON(models.Response{
+			Result: "invalid reset token", // Return error message in JSON format if the token is invalid
+			Code:   models.CodeInvalidToken,
+		})
+	}
+
+	// Retrieve the user and check the token wasn't used or outdated by a password change
+	user, err := uc.userService.GetUserById(c.Context(), userId)
+	if err != nil || user.ID != userId || user.PasswordVersion != passwordVersion {
+		uc.logger.Error(
+			err,
+			zap.Int("user_id", userId),
+		)
+
+		return c.JSON(models.Response{
+			Result: "invalid reset token", // Return error message in JSON format if the token is outdated
+			Code:   models.CodeInvalidToken,
 		})
 	}
 
-	// Set the new refresh token in the user object
-	user.SetRefreshToken(newTokens.Refresh)
 	// Set the new password in the user object
-	user.SetPassword(passwordData.NewPassword)
-	user.SessionID = sessionId
+	if err := user.SetPassword(resetData.NewPassword); err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error message in JSON format if hashing the password fails
+	}
+
+	c.Status(http.StatusInternalServerError) // Set response status to Internal Server Error (500)
+
+	user.PasswordVersion = newRandomId() // Invalidate the reset token, so it can be used only once
 
 	// Update the user's password in the database
-	err = uc.userService.UpdatePassword(c.Context(), user)
-	if err != nil {
+	if err := uc.userService.UpdatePassword(c.Context(), user); err != nil {
 		uc.logger.Error(err)
 
 		return c.JSON(models.Response{
 			Result: "user update failed", // Return error message in JSON format if updating password fails
+			Code:   models.CodeInternal,
+		})
+	}
+
+	// Log the user out of every device, whoever knew the old password included, and start a new session
+	newTokens, err := uc.restartSessions(c, user)
+	if err != nil {
+		uc.logger.Error(
+			err,
+			zap.Int("user_id", user.ID),
+		)
+
+		return c.JSON(models.Response{
+			Result: "user update failed", // Return error message in JSON format if restarting the sessions fails
+			Code:   models.CodeInternal,
 		})
 	}
 
@@ -364,6 +846,7 @@ func (uc *userController) DeleteUser(c *fiber.Ctx) error {
 
 		return c.JSON(models.Response{
 			Result: "user deletion failed", // Return error message in JSON format
+			Code:   models.CodeInternal,
 		})
 	}
 
@@ -379,38 +862,64 @@ func (uc *userController) DeleteUser(c *fiber.Ctx) error {
 	})
 }
 
-// generateTokens generates new access and refresh tokens for a user.
+// Logout handles the request to sign the user out of the current session.
 //
-// This method creates a random session ID for each token generation process,
-// then generates an access token and a refresh token using the provided user ID.
+// This method performs the following steps:
+// 1. Retrieves the authenticated user object from the context.
+// 2. Revokes the current session of the user, so the access and refresh tokens issued for it are rejected from now on.
+// 3. Returns a success message, or an error message if the session can't be revoked.
+//
+// @Summary Log out
+// @Description Sign the authenticated user out of the current session. The access and refresh tokens of the session stop being accepted, the other sessions stay active.
+// @Tags users
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Success 200 {object} models.Response "Successful response"
+// @Failure 401 {object} models.Response "Invalid token"
+// @Failure 500 {object} models.Response "Internal server error"
+// @Router /api/user/auth/logout [post]
+func (uc *userController) Logout(c *fiber.Ctx) error {
+	session := c.Locals("session").(models.Session) // Retrieve the current session from the context locals, which was set during authentication
+
+	if err := uc.revokeSession(session); err != nil {
+		c.Status(http.StatusInternalServerError) // Set response status to Internal Server Error
+
+		return c.JSON(models.Response{
+			Result: "logout failed", // Return error message in JSON format
+			Code:   models.CodeInternal,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(models.Response{
+		Result: "logged out successfully", // Return success message in JSON format
+	})
+}
+
+// generateTokens generates new access and refresh tokens for a session of the user.
+//
+// Both tokens carry the ID of the session, so they are accepted only as long as the session exists.
+// The access token carries the role of the user as well.
 //
 // Parameters:
-//   - userId: An integer representing the user's unique identifier.
+//   - user: A models.User structure containing the ID and the role of the user.
+//   - sessionId: The ID of the session the tokens are issued for.
 //
 // Returns:
 //   - models.Tokens: A structure containing the newly generated access token,
 //     refresh token, and expiration time of the access token.
-//   - int: A randomly generated session ID associated with this token generation.
 //   - error: An error if there was an issue creating either of the tokens;
 //     if successful, it returns nil.
-//
-// Possible Errors:
-//   - An error may occur during the creation of either the access or refresh tokens,
-//     in which case it will be returned alongside an empty Tokens structure.
-func (uc *userController) generateTokens(userId int) (models.Tokens, int, error) {
-	// Generate a random session ID for this token generation process.
-	sessionId := rand.Intn(9999)
-
-	// Create an access token using the user ID and session ID.
-	accessToken, expiresAt, err := uc.jwtService.CreateAccessToken(userId, sessionId)
+func (uc *userController) generateTokens(user models.User, sessionId int) (models.Tokens, error) {
+	// Create an access token using the user ID, session ID and role.
+	accessToken, expiresAt, err := uc.jwtService.CreateAccessToken(user.ID, sessionId, user.Role)
 	if err != nil {
-		return models.Tokens{}, 0, err // Return an empty Tokens struct and error if token creation fails.
+		return models.Tokens{}, err // Return an empty Tokens struct and error if token creation fails.
 	}
 
 	// Create a refresh token using the user ID and session ID.
-	refreshToken, err := uc.jwtService.CreateRefreshToken(userId, sessionId)
+	refreshToken, err := uc.jwtService.CreateRefreshToken(user.ID, sessionId)
 	if err != nil {
-		return models.Tokens{}, 0, err // Return an empty Tokens struct and error if token creation fails.
+		return models.Tokens{}, err // Return an empty Tokens struct and error if token creation fails.
 	}
 
 	// Return a Tokens struct containing the generated access token,
@@ -419,43 +928,195 @@ func (uc *userController) generateTokens(userId int) (models.Tokens, int, error)
 		Access:    accessToken,
 		Refresh:   refreshToken,
 		ExpiresAt: expiresAt,
-	}, sessionId, nil // Return session id and nil indicating no error occurred.
+	}, nil // Return nil indicating no error occurred.
+}
+
+// newSession creates a new session of the user with a random ID and issues the tokens bound to it.
+// The session isn't stored, the caller stores it along with the hash of the refresh token.
+//
+// Parameters:
+//   - c: The context of the request logging the user in, its user agent is stored with the session.
+//   - user: A models.User structure containing the ID and the role of the user.
+//
+// Returns:
+//   - models.Session: The new session containing the hash of the refresh token.
+//   - models.Tokens: A structure containing the access and refresh tokens of the session.
+//   - error: An error if the tokens can't be generated or the refresh token can't be hashed.
+func (uc *userController) newSession(c *fiber.Ctx, user models.User) (models.Session, models.Tokens, error) {
+	userAgent := c.Get(fiber.HeaderUserAgent)
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength] // Keep the stored user agent within the column size
+	}
+
+	session := models.Session{
+		ID:        newRandomId(),
+		UserID:    user.ID,
+		UserAgent: userAgent,
+	}
+
+	tokens, err := uc.generateTokens(user, session.ID)
+	if err != nil {
+		return models.Session{}, models.Tokens{}, err // Return empty values and error if token generation fails
+	}
+
+	// Store the hash of the refresh token with the session
+	if err := session.SetRefreshToken(tokens.Refresh); err != nil {
+		return models.Session{}, models.Tokens{}, err // Return empty values and error if hashing the refresh token fails
+	}
+
+	return session, tokens, nil
+}
+
+// startSession starts a new session of the user and stores it in the database.
+// The other sessions of the user stay active, so the user can be logged in on several devices.
+//
+// Parameters:
+//   - c: The context of the request logging the user in.
+//   - user: A models.User structure representing the user who logs in.
+//
+// Returns:
+//   - models.Tokens: A structure containing the access and refresh tokens of the new session.
+//   - error: An error if there was an issue generating tokens or storing the session.
+func (uc *userController) startSession(c *fiber.Ctx, user models.User) (models.Tokens, error) {
+	session, newTokens, err := uc.newSession(c, user)
+	if err != nil {
+		return models.Tokens{}, err // Return an empty Tokens struct and error if the session can't be created
+	}
+
+	if err := uc.sessionService.InsertSession(c.Context(), session); err != nil {
+		return models.Tokens{}, err // Return an empty Tokens struct and error if storing the session fails
+	}
+
+	return newTokens, nil
+}
+
+// restartSessions revokes all sessions of the user and starts a new one for the current request,
+// so the user stays logged in only on the device which made it.
+//
+// Parameters:
+//   - c: The context of the request the new session is started for.
+//   - user: A models.User structure representing the user whose sessions are restarted.
+//
+// Returns:
+//   - models.Tokens: A structure containing the access and refresh tokens of the new session.
+//   - error: An error if the sessions can't be revoked or the new session can't be started.
+func (uc *userController) restartSessions(c *fiber.Ctx, user models.User) (models.Tokens, error) {
+	if err := uc.sessionService.DeleteUserSessions(c.Context(), user.ID); err != nil {
+		return models.Tokens{}, err // Return an empty Tokens struct and error if revoking the sessions fails
+	}
+
+	return uc.startSession(c, user)
 }
 
-// updateRefreshToken updates the access and refresh tokens for the specified user.
+// rotateTokens replaces the refresh token of the session with a newly generated one.
 //
-// This function generates new access and refresh tokens for the authenticated user,
-// sets the new refresh token in the user object, and updates it in the database.
+// The stored refresh token is replaced only if it is still the one the session was retrieved with,
+// so the same refresh token can't be rotated twice. The session keeps its ID.
 //
 // Parameters:
-//   - user: A models.User structure representing the user for whom the tokens need to be updated.
-//     The object must contain a valid user ID.
+//   - user: A models.User structure containing the ID and the role of the user.
+//   - session: The session containing the current refresh token hash.
 //
 // Returns:
 //   - models.Tokens: A structure containing the new access and refresh tokens.
-//   - error: An error if there was an issue generating tokens or updating the token in the database.
-//     If the function completes successfully, the returned error value will be nil.
-//
-// Possible Errors:
-//   - An error may occur during token generation if the user does not exist or if there is
-//     an issue creating the tokens.
-//   - An error may occur when setting the refresh token in the user object.
-//   - An error may occur when attempting to update the token in the database.
-func (uc *userController) updateTokens(user models.User) (models.Tokens, error) {
-	// Generate new access and refresh tokens for the authenticated user
-	newTokens, sessionId, err := uc.generateTokens(user.ID)
+//   - error: An error if there was an issue generating tokens, or errRefreshTokenReused
+//     if the refresh token was already rotated.
+func (uc *userController) rotateTokens(user models.User, session models.Session) (models.Tokens, error) {
+	previousRefreshToken := session.RefreshToken // Keep the hash of the rotated refresh token
+
+	// Generate new access and refresh tokens for the session
+	newTokens, err := uc.generateTokens(user, session.ID)
 	if err != nil {
 		return models.Tokens{}, err // Return an empty Tokens struct and error if token generation fails
 	}
 
-	// Set the refresh token for the user object
-	if err := user.SetRefreshToken(newTokens.Refresh); err != nil {
+	// Set the refresh token for the session object
+	if err := session.SetRefreshToken(newTokens.Refresh); err != nil {
 		return models.Tokens{}, err // Return an empty Tokens struct and error if setting the refresh token fails
 	}
-	user.SessionID = sessionId // Assign the new session ID to the user
 
-	// Update the user's refresh token in the database
-	err = uc.userService.UpdateRefreshToken(context.Background(), user)
+	// Replace the session's refresh token in the database if it wasn't rotated yet
+	if err := uc.sessionService.RotateRefreshToken(context.Background(), session, previousRefreshToken); err != nil {
+		return models.Tokens{}, fmt.Errorf("%w: %v", errRefreshTokenReused, err)
+	}
+
+	return newTokens, nil
+}
+
+// revokeSession deletes the session, so the access and refresh tokens issued for it are rejected from now on.
+// Errors are logged, callers revoking the session of an already rejected request may ignore them.
+//
+// Parameters:
+//   - session: The revoked session.
+//
+// Returns:
+//   - error: An error if the session can't be deleted.
+func (uc *userController) revokeSession(session models.Session) error {
+	err := uc.sessionService.DeleteSession(context.Background(), session.UserID, session.ID)
+	if err != nil {
+		uc.logger.Error(
+			err,
+			zap.Int("user_id", session.UserID),
+		)
+	}
+
+	return err
+}
+
+// sendVerifyEmail sends the email verification link to the user in the background,
+// so the response isn't slowed down by the SMTP server. Errors are only logged.
+//
+// Parameters:
+//   - user: A models.User structure containing the ID and the email of the user.
+func (uc *userController) sendVerifyEmail(user models.User) {
+	token, err := uc.jwtService.CreateVerifyEmailToken(user.ID)
+	if err != nil {
+		uc.logger.Error(
+			err,
+			zap.Int("user_id", user.ID),
+		)
+
+		return
+	}
+
+	go func() {
+		if err := uc.emailService.SendVerifyEmailToken(user.Email, token); err != nil {
+			uc.logger.Error(
+				err,
+				zap.Int("user_id", user.ID),
+			)
+		}
+	}()
+}
+
+// sendChangeEmail sends the email change confirmation link to the new email in the background,
+// so the response isn't slowed down by the SMTP server. Errors are only logged.
+//
+// Parameters:
+//   - userId: The ID of the user changing the email.
+//   - email: The new email of the user.
+func (uc *userController) sendChangeEmail(userId int, email string) {
+	token, err := uc.jwtService.CreateChangeEmailToken(userId, email)
+	if err != nil {
+		uc.logger.Error(
+			err,
+			zap.Int("user_id", userId),
+		)
+
+		return
+	}
+
+	go func() {
+		if err := uc.emailService.SendChangeEmailToken(email, token); err != nil {
+			uc.logger.Error(
+				err,
+				zap.Int("user_id", userId),
+			)
+		}
+	}()
+}
 
-	return newTokens, err // Return the new tokens and any error from updating the database
+// newRandomId generates a random positive ID of a session or of a password version.
+func newRandomId() int {
+	return rand.Intn(maxRandomId) + 1
 }
@@ -1,13 +1,21 @@
 package controller
 
 import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"cvs/internal/models"
 	"cvs/internal/service"
 	"cvs/internal/service/exchange"
 	"cvs/internal/service/logger"
 
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -58,8 +66,12 @@ func NewUserPairsController(
 // 1. Initializes a `UserPairs` struct to hold the new pair data.
 // 2. Retrieves the authenticated user's ID from context locals.
 // 3. Parses the request body into the `pairData` struct.
-// 4. Calls the service to add the new pair to the database.
-// 5. Returns a JSON response indicating success or failure.
+// 4. Normalizes the pair to the BASE/QUOTE form, e.g. BTCUSDT or btc-usdt to BTC/USDT, and the exchange name
+// to lower case, returning 400 if the assets of the pair can't be told apart.
+// 5. Returns 400 if the exchange isn't supported or doesn't list the pair, before anything is stored.
+// 6. Returns 503 with the Retry-After header if the pairs of the exchange haven't been loaded yet, so the pair can't be checked.
+// 7. Calls the service to add the new pair to the database, returning 400 if the user has reached the limit of pairs.
+// 8. Subscribes the exchange to the pair and returns a JSON response indicating success or failure.
 //
 // @Summary Add a new user pair
 // @Description Create a new pair for the authenticated user
@@ -69,8 +81,9 @@ func NewUserPairsController(
 // @Param Authorization header string true "Access token"
 // @Param pair body models.UserPairs true "User pair data"
 // @Success 200 {object} models.Response "Successful response indicating the pair was added"
-// @Failure 400 {object} models.Response "Invalid input data"
+// @Failure 400 {object} models.Response "Invalid input data or pair format, the pair isn't listed on the exchange or the maximum number of pairs per user reached"
 // @Failure 500 {object} models.Response "Internal server error"
+// @Failure 503 {object} models.Response "The pairs of the exchange haven't been loaded yet"
 // @Router /api/user/pair/add [post]
 func (uc *userPairsController) Add(c *fiber.Ctx) error {
 	var pairData models.UserPairs                       // Initialize a UserPairs struct to hold the new pair data
@@ -83,24 +96,55 @@ func (uc *userPairsController) Add(c *fiber.Ctx) error {
 		c.Status(http.StatusBadRequest)
 
 		return c.JSON(models.Response{
-			Result: "invalid input data", // Return error if parsing fails
+			Result: errInvalidInputData, // Return error if parsing fails
+			Code:   models.CodeInvalidInput,
 		})
 	}
 
-	// Call the service to add the new pair to the database
-	if err := uc.userPairsService.Add(c.Context(), pairData); err != nil {
-		uc.logger.Error(err)
+	pair, err := models.NormalizePair(pairData.Pair) // Pairs of the exchanges are in the BASE/QUOTE form
+	if err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error if the assets of the pair can't be told apart
+	}
+
+	pairData.Pair = pair
+	pairData.Exchange = strings.ToLower(strings.TrimSpace(pairData.Exchange)) // Exchange names are in lower case
 
-		c.Status(http.StatusInternalServerError)
+	exchange, ok := uc.allExchangesStorage.Get(pairData.Exchange)
+	if !ok {
+		c.Status(http.StatusBadRequest)
 
 		return c.JSON(models.Response{
-			Result: err.Error(), // Return error message in JSON format
+			Result: "exchange not found", // Return error if the exchange isn't supported
+			Code:   models.CodeNotFound,
 		})
 	}
 
+	if !exchange.PairsLoaded() {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(pairsNotLoadedRetryAfter))
+		c.Status(http.StatusServiceUnavailable)
+
+		return c.JSON(models.Response{
+			Result: errPairsNotLoaded, // The exchange has just started, the request can be repeated later
+			Code:   models.CodeUnavailable,
+		})
+	}
+
+	if !exchange.HasPair(pairData.Pair) {
+		c.Status(http.StatusBadRequest)
+
+		return c.JSON(models.Response{
+			Result: errPairNotListed, // Return error if the exchange doesn't offer the pair
+			Code:   models.CodePairNotListed,
+		})
+	}
+
+	// Call the service to add the new pair to the database
+	if err := uc.userPairsService.Add(c.Context(), pairData); err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return the limit in JSON format if the user has reached it
+	}
+
 	uc.userService.SetUserIdIntoMemory(pairData.UserID)
 
-	exchange := uc.allExchangesStorage.Get(pairData.Exchange)
 	exchange.AddPairToSubscribedPairs(pairData.Pair)
 
 	return c.JSON(models.Response{
@@ -108,6 +152,356 @@ func (uc *userPairsController) Add(c *fiber.Ctx) error {
 	}) // Return success message in JSON format
 }
 
+const (
+	errPairNotListed  = "pair is not listed on the exchange"                           // Error of a pair the exchange doesn't offer
+	errPairsNotLoaded = "pairs of the exchange are not loaded yet, please retry later" // Error of a pair added before the exchange has loaded its pairs
+)
+
+// pairsNotLoadedRetryAfter is the number of seconds a client waits before adding a pair
+// of an exchange which hasn't loaded its pairs yet.
+const pairsNotLoadedRetryAfter = 5
+
+// maxBulkPairs is the maximum number of pairs added by a single bulk request.
+const maxBulkPairs = 100
+
+// BulkAdd creates several user pairs in the database at once.
+//
+// The function performs the following steps:
+// 1. Parses the request body into a slice of `UserPairs` and returns 400 if it is empty or too large.
+// 2. Adds the pairs as addPairs describes.
+// 3. Returns a JSON response with the outcome of every pair in the order of the request.
+//
+// @Summary Add several user pairs
+// @Description Create several pairs for the authenticated user at once. A pair which can't be added doesn't prevent the others from being added
+// @Tags user-pairs
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param pairs body []models.UserPairs true "User pairs data"
+// @Success 200 {array} models.UserPairsBulkResult "Outcome of every pair"
+// @Failure 400 {object} models.Response "Invalid input data"
+// @Failure 500 {object} models.Response "Internal server error"
+// @Router /api/user/pair/bulk [post]
+func (uc *userPairsController) BulkAdd(c *fiber.Ctx) error {
+	var pairs []models.UserPairs                // Initialize a slice to hold the new pairs data
+	userID := c.Locals("user").(models.User).ID // Retrieve authenticated user's ID from context locals
+
+	// Parse the request body into pairs
+	if err := c.BodyParser(&pairs); err != nil {
+		uc.logger.Error(err)
+
+		c.Status(http.StatusBadRequest)
+
+		return c.JSON(models.Response{
+			Result: errInvalidInputData, // Return error if parsing fails
+			Code:   models.CodeInvalidInput,
+		})
+	}
+
+	if len(pairs) == 0 || len(pairs) > maxBulkPairs {
+		c.Status(http.StatusBadRequest)
+
+		return c.JSON(models.Response{
+			Result: fmt.Sprintf("from 1 to %d pairs are expected", maxBulkPairs), // Return error if the number of pairs is out of range
+			Code:   models.CodeInvalidInput,
+		})
+	}
+
+	results := make([]models.UserPairsBulkResult, len(pairs))
+	if err := uc.addPairs(c, userID, pairs, results); err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error message in JSON format
+	}
+
+	return c.JSON(results) // Return the outcome of every pair in JSON format
+}
+
+// addPairs adds the pairs of the user and stores the outcome of every pair in the results of the same index.
+// The pairs whose results already carry an error, e.g. the rows of an import which couldn't be parsed, are skipped.
+//
+// The function performs the following steps:
+// 1. Normalizes every pair as Add does and marks the pairs of an invalid format or unsupported exchanges as failed.
+// 2. Marks the pairs the exchanges don't list or haven't loaded yet as failed.
+// 3. Calls the service to validate and add the remaining pairs in a single transaction.
+// 4. Subscribes the exchanges to the added pairs.
+//
+// Returns:
+//   - The error of the service if none of the pairs could be added, the results are incomplete then.
+func (uc *userPairsController) addPairs(c *fiber.Ctx, userID int, pairs []models.UserPairs, results []models.UserPairsBulkResult) error {
+	exchanges := make([]exchange.Exchange, len(pairs)) // Exchanges of the pairs, nil if the exchange isn't supported
+
+	toAdd := make([]models.UserPairs, 0, len(pairs)) // Pairs of the supported exchanges
+	toAddIndexes := make([]int, 0, len(pairs))       // Indexes of the pairs passed to the service among all pairs
+	for i, pairData := range pairs {
+		if results[i].Error != "" {
+			continue // The pair was rejected before it could be added
+		}
+
+		pairData.UserID = userID
+		pairData.Exchange = strings.ToLower(strings.TrimSpace(pairData.Exchange)) // Exchange names are in lower case
+
+		pair, err := models.NormalizePair(pairData.Pair) // Pairs of the exchanges are in the BASE/QUOTE form
+		if err != nil {
+			results[i] = models.UserPairsBulkResult{Exchange: pairData.Exchange, Pair: pairData.Pair}
+			results[i].Error, results[i].Code = uc.describePairError(err)
+
+			continue
+		}
+
+		pairData.Pair = pair
+		results[i] = models.UserPairsBulkResult{Exchange: pairData.Exchange, Pair: pairData.Pair}
+
+		exchange, ok := uc.allExchangesStorage.Get(pairData.Exchange)
+		if !ok {
+			results[i].Error, results[i].Code = "exchange not found", models.CodeNotFound // The pair of an unsupported exchange isn't stored
+
+			continue
+		}
+
+		if !exchange.PairsLoaded() {
+			results[i].Error, results[i].Code = errPairsNotLoaded, models.CodeUnavailable
+
+			continue
+		}
+
+		if !exchange.HasPair(pairData.Pair) {
+			results[i].Error, results[i].Code = errPairNotListed, models.CodePairNotListed // The pair the exchange doesn't offer isn't stored
+
+			continue
+		}
+
+		exchanges[i] = exchange
+		toAdd = append(toAdd, pairData)
+		toAddIndexes = append(toAddIndexes, i)
+	}
+
+	if len(toAdd) == 0 {
+		return nil // None of the pairs can be added
+	}
+
+	// Call the service to add the pairs to the database
+	pairErrors, err := uc.userPairsService.BulkAdd(c.Context(), toAdd)
+	if err != nil {
+		return err
+	}
+
+	added := false
+	for i, index := range toAddIndexes {
+		if i < len(pairErrors) && pairErrors[i] != nil {
+			results[index].Error, results[index].Code = uc.describePairError(pairErrors[i])
+
+			continue
+		}
+
+		results[index].Added = true
+		exchanges[index].AddPairToSubscribedPairs(results[index].Pair)
+		added = true
+	}
+
+	if added {
+		uc.userService.SetUserIdIntoMemory(userID)
+	}
+
+	return nil
+}
+
+// Import restores the pairs of the authenticated user from a file, e.g. the one downloaded by Export.
+//
+// The function performs the following steps:
+// 1. Parses the request body as CSV if its content type is text/csv, or as a JSON array of `UserPairs` otherwise.
+// 2. Returns 400 if the body can't be parsed, has no pairs or too many of them.
+// 3. Marks the CSV rows whose values can't be parsed as skipped.
+// 4. Adds the remaining pairs as BulkAdd does, so the pairs are validated, limited and added in a single transaction.
+// 5. Returns a JSON response with the numbers of the imported and skipped pairs and the outcome of every pair.
+//
+// The CSV must start with a header row naming its columns, the exchange, pair and exact_value columns are required,
+// the search_mode, multiplier, window, tolerance, side and price_range ones are optional. Empty values are left unset.
+//
+// @Summary Import user pairs
+// @Description Restore several pairs of the authenticated user from a CSV file with a header row or a JSON array. A pair which can't be imported doesn't prevent the others from being imported
+// @Tags user-pairs
+// @Accept text/csv
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param pairs body []models.UserPairs true "User pairs file"
+// @Success 200 {object} models.UserPairsImportResult "Summary of the import"
+// @Failure 400 {object} models.Response "Invalid input data"
+// @Failure 500 {object} models.Response "Internal server error"
+// @Router /api/user/pair/import [post]
+func (uc *userPairsController) Import(c *fiber.Ctx) error {
+	userID := c.Locals("user").(models.User).ID // Retrieve authenticated user's ID from context locals
+
+	var (
+		pairs   []models.UserPairs
+		results []models.UserPairsBulkResult
+		err     error
+	)
+
+	contentType := strings.ToLower(strings.TrimSpace(c.Get(fiber.HeaderContentType)))
+	if strings.HasPrefix(contentType, mimeTextCsv) {
+		pairs, results, err = parsePairsCsv(c.Body())
+	} else {
+		err = c.BodyParser(&pairs)
+		results = make([]models.UserPairsBulkResult, len(pairs))
+	}
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+
+		return c.JSON(models.Response{
+			Result: errInvalidInputData, // Return error if parsing fails
+			Code:   models.CodeInvalidInput,
+		})
+	}
+
+	if len(pairs) == 0 || len(pairs) > maxBulkPairs {
+		c.Status(http.StatusBadRequest)
+
+		return c.JSON(models.Response{
+			Result: fmt.Sprintf("from 1 to %d pairs are expected", maxBulkPairs), // Return error if the number of pairs is out of range
+			Code:   models.CodeInvalidInput,
+		})
+	}
+
+	if err := uc.addPairs(c, userID, pairs, results); err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error message in JSON format
+	}
+
+	summary := models.UserPairsImportResult{Pairs: results}
+	for _, result := range results {
+		if result.Added {
+			summary.Imported++
+		} else {
+			summary.Skipped++
+		}
+	}
+
+	return c.JSON(summary) // Return the summary of the import in JSON format
+}
+
+// parsePairsCsv parses the rows of the CSV file into the pairs, the header row names the columns.
+//
+// Returns:
+//   - The pairs of the rows in the order of the file.
+//   - The results of the rows of the same index, carrying the error of the rows whose values can't be parsed.
+//   - An error if the file isn't a CSV or its header lacks a required column.
+func parsePairsCsv(body []byte) ([]models.UserPairs, []models.UserPairsBulkResult, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := make(map[string]int, len(header)) // Indexes of the columns by name
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	for _, column := range []string{"exchange", "pair", "exact_value"} {
+		if _, ok := columns[column]; !ok {
+			return nil, nil, fmt.Errorf("missing %s column", column)
+		}
+	}
+
+	var (
+		pairs   []models.UserPairs
+		results []models.UserPairsBulkResult
+	)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, nil, err
+		}
+
+		value := func(column string) string { // Value of the column, empty if the row lacks it
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+
+			return strings.TrimSpace(record[i])
+		}
+
+		pairData := models.UserPairs{
+			Exchange:   value("exchange"),
+			Pair:       value("pair"),
+			SearchMode: models.SearchMode(value("search_mode")),
+			Side:       value("side"),
+		}
+		result := models.UserPairsBulkResult{Exchange: pairData.Exchange, Pair: pairData.Pair}
+
+		if err != nil {
+			result.Error = "row must have as many values as the header"
+		} else {
+			result.Error = parseCsvValues(&pairData, value)
+		}
+		if result.Error != "" { // The row is skipped by addPairs
+			result.Code = models.CodeInvalidInput
+		}
+
+		pairs = append(pairs, pairData)
+		results = append(results, result)
+	}
+
+	return pairs, results, nil
+}
+
+// parseCsvValues parses the numeric values of the CSV row into the pair, leaving the empty ones unset.
+//
+// Returns:
+//   - The description of the first value which isn't a number, empty if all of them are parsed.
+func parseCsvValues(pairData *models.UserPairs, value func(column string) string) string {
+	floats := []struct {
+		column string
+		target *float64
+	}{
+		{"exact_value", &pairData.ExactValue},
+		{"multiplier", &pairData.Multiplier},
+		{"tolerance", &pairData.Tolerance},
+		{"price_range", &pairData.PriceRange},
+	}
+
+	for _, field := range floats {
+		if value(field.column) == "" {
+			continue
+		}
+
+		parsed, err := strconv.ParseFloat(value(field.column), 64)
+		if err != nil {
+			return field.column + " must be a number"
+		}
+
+		*field.target = parsed
+	}
+
+	if window := value("window"); window != "" {
+		parsed, err := strconv.Atoi(window)
+		if err != nil {
+			return "window must be an integer"
+		}
+
+		pairData.Window = parsed
+	}
+
+	return ""
+}
+
+// describePairError returns the client-facing message and code of the error of a pair of a bulk request.
+// The details of an internal error are logged, the client gets only its code.
+func (uc *userPairsController) describePairError(err error) (string, string) {
+	description, known := describeError(err)
+	if !known {
+		uc.logger.Error(err)
+	}
+
+	return description.message, description.code
+}
+
 // UpdateExactValue updates an existing user pair in the database.
 // It retrieves the authenticated user's ID from the context,
 // parses the request body to obtain the updated pair data,
@@ -142,19 +536,14 @@ func (uc *userPairsController) UpdateExactValue(c *fiber.Ctx) error {
 		c.Status(http.StatusBadRequest)
 
 		return c.JSON(models.Response{
-			Result: "invalid input data", // Return error if parsing fails
+			Result: errInvalidInputData, // Return error if parsing fails
+			Code:   models.CodeInvalidInput,
 		})
 	}
 
 	// Call the service to update the existing pair in the database
 	if err := uc.userPairsService.UpdateExactValue(c.Context(), pairData); err != nil {
-		uc.logger.Error(err)
-
-		c.Status(http.StatusInternalServerError)
-
-		return c.JSON(models.Response{
-			Result: err.Error(), // Return error message in JSON format
-		})
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error message in JSON format
 	}
 
 	return c.JSON(models.Response{
@@ -163,44 +552,229 @@ func (uc *userPairsController) UpdateExactValue(c *fiber.Ctx) error {
 }
 
 // GetAllUserPairs retrieves all user pairs associated with the authenticated user.
-// It fetches the user's ID from the context and calls the service to get all pairs.
+// It fetches the user's ID from the context and calls the service to get all pairs,
+// or only the pairs of one exchange if the exchange query parameter is set.
+// If the limit or the offset query parameter is set, a page of the pairs is returned with the number of all pairs instead.
 //
 // The function performs the following steps:
 // 1. Retrieves the authenticated user's ID from context locals.
-// 2. Calls the service to get all pairs associated with the user's ID.
-// 3. Returns a JSON response containing the list of user pairs or an error message.
+// 2. Returns a page of the pairs if the limit or the offset is set, see getUserPairsPage.
+// 3. Validates the exchange name if the exchange query parameter is set.
+// 4. Calls the service to get the pairs associated with the user's ID, filtered by the exchange if it is set.
+// 5. Returns a JSON response containing the list of user pairs or an error message.
 //
 // @Summary Retrieve all pairs for the authenticated user
-// @Description Get all user pairs associated with the authenticated user's account
+// @Description Get all user pairs associated with the authenticated user's account, optionally only the pairs of one exchange.
+// @Description With the limit or the offset the pairs sorted by exchange and pair are returned by pages of at most 500 pairs
+// @Description as a models.UserPairsPage object with the number of all pairs instead of the list.
 // @Tags user-pairs
 // @Produce json
 // @Param Authorization header string true "Access token"
+// @Param exchange query string false "Return only the pairs of the exchange, e.g. binance_spot. Can't be combined with the pagination"
+// @Param limit query int false "Maximum number of pairs of the page, 50 when only the offset is set" minimum(0) maximum(500)
+// @Param offset query int false "Number of pairs skipped before the page" minimum(0)
 // @Success 200 {array} models.UserPairs "List of user pairs"
+// @Failure 400 {object} models.Response "Invalid exchange name or pagination"
 // @Failure 500 {object} models.Response "Internal server error"
 // @Router /api/user/pair/all-pairs [get]
 func (uc *userPairsController) GetAllUserPairs(c *fiber.Ctx) error {
 	userID := c.Locals("user").(models.User).ID // Retrieve authenticated user's ID from context locals
+	exchange := c.Query("exchange")             // Exchange the pairs are filtered by, empty for all pairs
 
-	// Call the service to get all pairs associated with the authenticated user's ID
-	userPairs, err := uc.userPairsService.GetAllUserPairs(c.Context(), userID)
+	if c.Query("limit") != "" || c.Query("offset") != "" {
+		return uc.getUserPairsPage(c, userID, exchange)
+	}
+
+	var (
+		userPairs []models.UserPairs
+		err       error
+	)
+
+	if exchange != "" {
+		if err := service.CheckExchangeName(exchange); err != nil {
+			return c.JSON(errorResponse(c, uc.logger, err)) // Return validation error message in JSON format
+		}
+
+		// Call the service to get the pairs of the exchange associated with the authenticated user's ID
+		userPairs, err = uc.userPairsService.GetUserPairsByExchange(c.Context(), userID, exchange)
+	} else {
+		// Call the service to get all pairs associated with the authenticated user's ID
+		userPairs, err = uc.userPairsService.GetAllUserPairs(c.Context(), userID)
+	}
 	if err != nil {
-		uc.logger.Error(err)
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error message in JSON format
+	}
+
+	return c.JSON(userPairs) // Return list of user pairs in JSON format
+}
+
+// GetUserPair retrieves a single pair of the authenticated user on an exchange with its search settings,
+// so a pair can be edited without fetching all pairs of the user.
+//
+// The function performs the following steps:
+// 1. Retrieves the authenticated user's ID from context locals.
+// 2. Normalizes the pair query parameter to the BASE/QUOTE form like the pair of Add, so BTCUSDT finds BTC/USDT.
+// 3. Calls the service to get the pair of the exchange, which validates the exchange name.
+// 4. Returns a JSON response containing the pair, or 404 if the user has no such pair.
+//
+// @Summary Retrieve a single pair of the authenticated user
+// @Description Get a pair of the authenticated user on an exchange with its exact value, side, tolerance and the other search settings
+// @Tags user-pairs
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param exchange query string true "Exchange of the pair, e.g. binance_spot"
+// @Param pair query string true "The pair, e.g. BTC/USDT"
+// @Success 200 {object} models.UserPairs "The pair of the user"
+// @Failure 400 {object} models.Response "Invalid exchange name or pair format"
+// @Failure 404 {object} models.Response "The user has no such pair"
+// @Failure 500 {object} models.Response "Internal server error"
+// @Router /api/user/pair [get]
+func (uc *userPairsController) GetUserPair(c *fiber.Ctx) error {
+	userID := c.Locals("user").(models.User).ID // Retrieve authenticated user's ID from context locals
+
+	pair, err := models.NormalizePair(c.Query("pair")) // Retrieve pair from query string in the form it is stored
+	if err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error if the assets of the pair can't be told apart
+	}
+
+	userPair, err := uc.userPairsService.GetUserPair(c.Context(), userID, c.Query("exchange"), pair)
+	if err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error message in JSON format
+	}
 
-		c.Status(http.StatusInternalServerError)
+	return c.JSON(userPair) // Return the user pair in JSON format
+}
+
+// getUserPairsPage returns a page of the pairs of the user sorted by exchange and pair with the number of all pairs.
+// It returns 400 if the limit or the offset isn't a valid number or is out of range, or the exchange filter is set,
+// since the pages are taken from all pairs of the user.
+func (uc *userPairsController) getUserPairsPage(c *fiber.Ctx, userID int, exchange string) error {
+	var pagination models.UserPairsPagination // Initialize a UserPairsPagination struct to hold the query parameters
+
+	if err := c.QueryParser(&pagination); err != nil || exchange != "" {
+		c.Status(http.StatusBadRequest)
 
 		return c.JSON(models.Response{
-			Result: err.Error(), // Return error message in JSON format
+			Result: "invalid query parameters", // Return error if parsing fails or the filter is combined with the page
+			Code:   models.CodeInvalidInput,
 		})
 	}
 
-	return c.JSON(userPairs) // Return list of user pairs in JSON format
+	// Call the service to get the page of the pairs associated with the authenticated user's ID
+	page, err := uc.userPairsService.GetUserPairsPage(c.Context(), userID, pagination)
+	if err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error message in JSON format
+	}
+
+	return c.JSON(page) // Return the page of user pairs in JSON format
+}
+
+// mimeTextCsv is the content type of the user pairs exported and imported as CSV.
+const mimeTextCsv = "text/csv"
+
+// exportHeader is the header row of the user pairs exported as CSV.
+var exportHeader = []string{"exchange", "pair", "exact_value", "search_mode", "multiplier", "window", "tolerance", "side", "price_range"}
+
+// Export returns all user pairs of the authenticated user as a file, so the user can back up or share the watchlist.
+//
+// The function performs the following steps:
+// 1. Retrieves the authenticated user's ID from context locals.
+// 2. Validates the format query parameter, CSV if it is omitted.
+// 3. Calls the service to get all pairs associated with the user's ID.
+// 4. Returns the pairs as an attachment in the requested format or an error message.
+//
+// The CSV has a header row followed by a row per pair. The empty search mode is exported as the exact mode it means
+// and the empty side as both sides.
+//
+// @Summary Export the pairs of the authenticated user
+// @Description Download all user pairs as a CSV file with a header row or as a JSON array
+// @Tags user-pairs
+// @Produce text/csv
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param format query string false "Format of the file, csv when omitted" Enums(csv, json)
+// @Success 200 {array} models.UserPairs "User pairs file"
+// @Failure 400 {object} models.Response "Invalid format"
+// @Failure 500 {object} models.Response "Internal server error"
+// @Router /api/user/pair/export [get]
+func (uc *userPairsController) Export(c *fiber.Ctx) error {
+	userID := c.Locals("user").(models.User).ID // Retrieve authenticated user's ID from context locals
+
+	format := c.Query("format", "csv")
+	if format != "csv" && format != "json" {
+		c.Status(http.StatusBadRequest)
+
+		return c.JSON(models.Response{
+			Result: "format must be csv or json",
+			Code:   models.CodeInvalidInput,
+		})
+	}
+
+	userPairs, err := uc.userPairsService.GetAllUserPairs(c.Context(), userID)
+	if err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error message in JSON format
+	}
+
+	if format == "json" {
+		c.Attachment("pairs.json")
+
+		if userPairs == nil {
+			userPairs = []models.UserPairs{} // Export an empty array rather than null
+		}
+
+		return c.JSON(userPairs)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write(exportHeader); err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err))
+	}
+
+	for _, userPair := range userPairs {
+		searchMode := userPair.SearchMode
+		if searchMode == "" {
+			searchMode = models.SearchModeExact
+		}
+
+		side := userPair.Side
+		if side == "" {
+			side = models.SideBoth
+		}
+
+		record := []string{
+			userPair.Exchange,
+			userPair.Pair,
+			strconv.FormatFloat(userPair.ExactValue, 'f', -1, 64),
+			string(searchMode),
+			strconv.FormatFloat(userPair.Multiplier, 'f', -1, 64),
+			strconv.Itoa(userPair.Window),
+			strconv.FormatFloat(userPair.Tolerance, 'f', -1, 64),
+			side,
+			strconv.FormatFloat(userPair.PriceRange, 'f', -1, 64),
+		}
+		if err := writer.Write(record); err != nil {
+			return c.JSON(errorResponse(c, uc.logger, err))
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err))
+	}
+
+	c.Attachment("pairs.csv")
+	c.Set(fiber.HeaderContentType, mimeTextCsv+"; charset=utf-8")
+
+	return c.Send(buf.Bytes())
 }
 
-// GetAllUserFoundVolumes retrieves all found volumes associated with the authenticated user.
+// GetAllUserFoundVolumes retrieves the found volumes associated with the authenticated user.
 //
-// This method extracts the user's ID from the context locals and calls the
-// foundVolumesService to fetch all found volumes related to that user.
-// If an error occurs during this process, it returns an appropriate error message.
+// This method extracts the user's ID from the context locals, parses the filter from the query
+// parameters and calls the foundVolumesService to fetch the matching found volumes of that user,
+// the most recently found first. If an error occurs during this process, it returns an appropriate error message.
 //
 // Parameters:
 //   - c: A pointer to fiber.Ctx, which contains information about the HTTP request
@@ -213,34 +787,309 @@ func (uc *userPairsController) GetAllUserPairs(c *fiber.Ctx) error {
 // Possible Responses:
 //   - On success, it returns a JSON response containing a list of found volumes
 //     associated with the authenticated user.
+//   - If the query parameters are invalid, it sets the HTTP status to 400 (Bad Request)
+//     and returns a JSON response containing the error message.
 //   - If an error occurs during retrieval, it sets the HTTP status to 500 (Internal Server Error)
 //     and returns a JSON response containing the error message.
 //
-// @Summary Retrieve all found volumes for the authenticated user
-// @Description This endpoint retrieves a list of all found volumes associated with the authenticated user.
+// @Summary Retrieve found volumes for the authenticated user
+// @Description This endpoint retrieves a filtered page of the found volumes associated with the authenticated user, the farthest from the price first by default. The found volumes with equal sort values are returned the most recently found first.
 // @Tags user-pairs
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Access token"
+// @Param exchange query string false "Exchange name"
+// @Param pair query string false "Trading pair"
+// @Param side query string false "Order book side" Enums(asks, bids)
+// @Param min_volume query number false "Minimum volume"
+// @Param limit query int false "Maximum number of found volumes, all when zero"
+// @Param offset query int false "Number of found volumes to skip"
+// @Param sort query string false "Sort key, difference when omitted" Enums(difference, volume, price)
+// @Param order query string false "Sort order, desc when omitted" Enums(asc, desc)
 // @Success 200 {array} models.FoundVolume "Success"
+// @Failure 400 {object} models.Response "Invalid query parameters"
 // @Failure 500 {object} models.Response "Internal Server Error"
 // @Router /api/user/pair/found-volumes [get]
 func (uc *userPairsController) GetAllUserFoundVolumes(c *fiber.Ctx) error {
 	userID := c.Locals("user").(models.User).ID // Retrieve authenticated user's ID from context locals
 
-	// Call the service to get all pairs associated with the authenticated user's ID
-	foundVolumes, err := uc.foundVolumesService.GetAllFoundVolume(userID)
+	var filter models.FoundVolumesFilter // Initialize a FoundVolumesFilter struct to hold the query parameters
+
+	// Parse the query parameters into filter and validate them
+	if err := c.QueryParser(&filter); err != nil {
+		c.Status(http.StatusBadRequest)
+
+		return c.JSON(models.Response{
+			Result: "invalid query parameters", // Return error if parsing fails
+			Code:   models.CodeInvalidInput,
+		})
+	}
+
+	if err := service.CheckFoundVolumesFilter(filter); err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return validation error message in JSON format
+	}
+
+	// Call the service to get the found volumes associated with the authenticated user's ID
+	foundVolumes, err := uc.foundVolumesService.GetAllFoundVolume(userID, filter)
 	if err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error message in JSON format
+	}
+
+	return c.JSON(foundVolumes) // Return list of user pairs in JSON format
+}
+
+// Replace replaces all pairs of the authenticated user with the pairs of the request, e.g. to sync them from another device.
+// Either all changes are applied or none of them, an empty list removes all pairs of the user.
+//
+// The function performs the following steps:
+// 1. Parses the request body into a slice of `UserPairs` and normalizes every pair as Add does.
+// 2. Returns 400 if a pair has an invalid format, its exchange isn't supported or doesn't list it,
+// and 503 if the pairs of its exchange haven't been loaded yet, before anything is changed.
+// 3. Calls the service to store the pairs in a single transaction, returning 400 if a pair is invalid,
+// listed twice or the user would exceed the limit of pairs.
+// 4. Subscribes the exchanges to the added pairs and unsubscribes them from the removed pairs nobody else watches,
+// clearing the found volumes of the removed pairs.
+// 5. Returns a JSON response with the added, updated and removed pairs.
+//
+// @Summary Replace all user pairs
+// @Description Replace all pairs of the authenticated user with the given pairs in a single transaction. The pairs missing from the request are removed, the new ones added and the changed ones updated
+// @Tags user-pairs
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param pairs body []models.UserPairs true "All pairs the user should have"
+// @Success 200 {object} models.UserPairsReplaceResult "Applied changes"
+// @Failure 400 {object} models.Response "Invalid input data or pair format, the pair isn't listed on the exchange or the maximum number of pairs per user reached"
+// @Failure 500 {object} models.Response "Internal server error"
+// @Failure 503 {object} models.Response "The pairs of the exchange haven't been loaded yet"
+// @Router /api/user/pair/replace [put]
+func (uc *userPairsController) Replace(c *fiber.Ctx) error {
+	var pairs []models.UserPairs                // Initialize a slice to hold the desired pairs data
+	userID := c.Locals("user").(models.User).ID // Retrieve authenticated user's ID from context locals
+
+	// Parse the request body into pairs
+	if err := c.BodyParser(&pairs); err != nil {
 		uc.logger.Error(err)
 
-		c.Status(http.StatusInternalServerError)
+		c.Status(http.StatusBadRequest)
 
 		return c.JSON(models.Response{
-			Result: err.Error(), // Return error message in JSON format
+			Result: errInvalidInputData, // Return error if parsing fails
+			Code:   models.CodeInvalidInput,
 		})
 	}
 
-	return c.JSON(foundVolumes) // Return list of user pairs in JSON format
+	for i := range pairs {
+		pair, err := models.NormalizePair(pairs[i].Pair) // Pairs of the exchanges are in the BASE/QUOTE form
+		if err != nil {
+			return c.JSON(errorResponse(c, uc.logger, err)) // Return error if the assets of the pair can't be told apart
+		}
+
+		pairs[i].Pair = pair
+		pairs[i].Exchange = strings.ToLower(strings.TrimSpace(pairs[i].Exchange)) // Exchange names are in lower case
+
+		exchange, ok := uc.allExchangesStorage.Get(pairs[i].Exchange)
+		if !ok {
+			c.Status(http.StatusBadRequest)
+
+			return c.JSON(models.Response{
+				Result: "exchange not found", // Return error if the exchange isn't supported
+				Code:   models.CodeNotFound,
+			})
+		}
+
+		if !exchange.PairsLoaded() {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(pairsNotLoadedRetryAfter))
+			c.Status(http.StatusServiceUnavailable)
+
+			return c.JSON(models.Response{
+				Result: errPairsNotLoaded, // The exchange has just started, the request can be repeated later
+				Code:   models.CodeUnavailable,
+			})
+		}
+
+		if !exchange.HasPair(pairs[i].Pair) {
+			c.Status(http.StatusBadRequest)
+
+			return c.JSON(models.Response{
+				Result: errPairNotListed, // Return error if the exchange doesn't offer the pair
+				Code:   models.CodePairNotListed,
+			})
+		}
+	}
+
+	// Call the service to replace the pairs in the database
+	result, err := uc.userPairsService.ReplaceUserPairs(c.Context(), userID, pairs)
+	if err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error message in JSON format
+	}
+
+	if len(pairs) > 0 {
+		uc.userService.SetUserIdIntoMemory(userID)
+	} else {
+		uc.userService.DeleteUserIdFromMemory(userID) // The user has no pairs to search volumes of anymore
+	}
+
+	for _, pairData := range result.Added {
+		if exchange, ok := uc.allExchangesStorage.Get(pairData.Exchange); ok {
+			exchange.AddPairToSubscribedPairs(pairData.Pair)
+		}
+	}
+
+	for _, pairData := range result.Removed {
+		// Remove the pair from the subscribed pairs of the exchange if nobody else watches it there
+		if exchange, ok := uc.allExchangesStorage.Get(pairData.Exchange); ok {
+			subscribers, err := uc.userPairsService.CountPairSubscribers(c.Context(), pairData.Exchange, pairData.Pair)
+			if err != nil {
+				uc.logger.Error(err) // Keep the subscription rather than stop fetching the book for other users
+			} else if subscribers == 0 {
+				exchange.DeletePairFromSubscribedPairs(pairData.Pair) // Nobody watches the pair on the exchange anymore
+			}
+		}
+
+		if err := uc.foundVolumesService.DeleteFoundVolume(c.Context(), pairData); err != nil {
+			uc.logger.Error(err)
+		}
+	}
+
+	return c.JSON(result) // Return the applied changes in JSON format
+}
+
+// DeleteAllPairs handles the HTTP request to delete all pairs of the authenticated user.
+//
+// The pairs are removed from the database at once, so the user doesn't end up with a part of them.
+// Afterwards, for every removed pair the exchange unsubscribes from it if no other user watches it there,
+// and the found volumes of the pair are cleared.
+//
+// Parameters:
+//   - c: A pointer to fiber.Ctx, which contains information about the HTTP request
+//     and response, including context locals.
+//
+// Returns:
+//   - error: If an error occurs during the deletion process, it returns an error
+//     indicating that the operation failed. If successful, it returns nil.
+//
+// Possible Responses:
+//   - On success, it returns a JSON response with a message indicating that
+//     the pairs were deleted successfully.
+//   - If an error occurs during deletion, it sets the HTTP status to 500 (Internal Server Error)
+//     and returns a JSON response containing the error message.
+//
+// @Summary Delete all user pairs
+// @Description Remove all pairs of the authenticated user and their found volumes
+// @Tags user-pairs
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Success 200 {object} models.Response "Successful response indicating the pairs were deleted"
+// @Failure 500 {object} models.Response "Internal server error"
+// @Router /api/user/pair/all [delete]
+func (uc *userPairsController) DeleteAllPairs(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.User) // Retrieve authenticated user from context locals
+
+	// The pairs are read before the deletion to know which subscriptions and found volumes to clear
+	userPairs, err := uc.userPairsService.GetAllUserPairs(c.Context(), user.ID)
+	if err == nil {
+		err = uc.userPairsService.DeleteAllUserPairs(c.Context(), user.ID)
+	}
+	if err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error message in JSON format
+	}
+
+	uc.userService.DeleteUserIdFromMemory(user.ID) // Remove the user's ID from the in-memory storage
+
+	for _, userPairData := range userPairs {
+		// Remove the pair from the subscribed pairs of the exchange if nobody else watches it there
+		if exchange, ok := uc.allExchangesStorage.Get(userPairData.Exchange); ok {
+			subscribers, err := uc.userPairsService.CountPairSubscribers(c.Context(), userPairData.Exchange, userPairData.Pair)
+			if err != nil {
+				uc.logger.Error(err) // Keep the subscription rather than stop fetching the book for other users
+			} else if subscribers == 0 {
+				exchange.DeletePairFromSubscribedPairs(userPairData.Pair) // Nobody watches the pair on the exchange anymore
+			}
+		}
+
+		if err := uc.foundVolumesService.DeleteFoundVolume(c.Context(), userPairData); err != nil {
+			uc.logger.Error(err)
+		}
+	}
+
+	return c.JSON(models.Response{
+		Result: "all pairs deleted successfully", // Return success message in JSON format
+	})
+}
+
+// FoundVolumesWebsocketUpgrade checks that the request asks for a websocket upgrade before it is
+// passed to StreamFoundVolumes.
+//
+// Parameters:
+//   - c: A pointer to fiber.Ctx, which contains information about the HTTP request.
+//
+// Returns:
+//   - error: An error if the response could not be sent.
+//
+// Possible Responses:
+//   - If the request is not a websocket upgrade, it sets the HTTP status to 426 (Upgrade Required)
+//     and returns a JSON response containing the error message.
+//
+// @Summary Stream found volumes of the authenticated user
+// @Description This endpoint upgrades the connection to a websocket and pushes every new found volume of the authenticated user as a JSON message.
+// @Tags user-pairs
+// @Param Authorization header string true "Access token"
+// @Success 101 {object} models.FoundVolume "Switching Protocols, then found volumes are sent as messages"
+// @Failure 426 {object} models.Response "Upgrade Required"
+// @Router /api/user/pair/found-volumes/ws [get]
+func (uc *userPairsController) FoundVolumesWebsocketUpgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		c.Status(http.StatusUpgradeRequired)
+
+		return c.JSON(models.Response{
+			Result: "websocket upgrade required", // Return error message in JSON format
+			Code:   models.CodeUpgradeRequired,
+		})
+	}
+
+	return c.Next() // Proceed to the websocket handler
+}
+
+// StreamFoundVolumes pushes the found volumes of the authenticated user over the websocket connection.
+//
+// This method subscribes to the found volume events of the user and writes every received found volume
+// to the connection as JSON. The subscription is cancelled once the client disconnects or a write fails.
+//
+// Parameters:
+//   - conn: The websocket connection with the authenticated user stored in its locals.
+func (uc *userPairsController) StreamFoundVolumes(conn *websocket.Conn) {
+	userID := conn.Locals("user").(models.User).ID // Retrieve authenticated user's ID from connection locals
+
+	subscriber, unsubscribe := uc.foundVolumesService.Subscribe(userID)
+	defer unsubscribe()
+
+	closed := make(chan struct{}) // Closed when the client disconnects
+
+	go func() {
+		defer close(closed)
+
+		for { // Read until the connection fails, client messages are ignored
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case foundVolume, ok := <-subscriber:
+			if !ok {
+				return
+			}
+
+			if err := conn.WriteJSON(foundVolume); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
 }
 
 // DeletePair handles the HTTP request to delete a user pair from the database.
@@ -250,8 +1099,12 @@ func (uc *userPairsController) GetAllUserFoundVolumes(c *fiber.Ctx) error {
 // object with the user ID and pair information. The function calls the
 // userPairsService to perform the deletion.
 //
+// Other users may watch the same pair, so an exchange unsubscribes from the pair
+// only when no remaining user pair references the pair on that exchange.
+//
 // Query Parameters:
-//   - pair: The identifier of the user pair to be deleted, extracted from the query string.
+//   - pair: The identifier of the user pair to be deleted, extracted from the query string. It is normalized
+//     to the BASE/QUOTE form like the pair of Add, so BTCUSDT deletes BTC/USDT.
 //
 // Parameters:
 //   - c: A pointer to fiber.Ctx, which contains information about the HTTP request
@@ -264,6 +1117,7 @@ func (uc *userPairsController) GetAllUserFoundVolumes(c *fiber.Ctx) error {
 // Possible Responses:
 //   - On success, it returns a JSON response with a message indicating that
 //     the pair was deleted successfully.
+//   - If the assets of the pair can't be told apart, it sets the HTTP status to 400 (Bad Request).
 //   - If an error occurs during deletion, it sets the HTTP status to 500 (Internal Server Error)
 //     and returns a JSON response containing the error message.
 //
@@ -275,13 +1129,17 @@ func (uc *userPairsController) GetAllUserFoundVolumes(c *fiber.Ctx) error {
 // @Param Authorization header string true "Access token"
 // @Param        pair   query      string  true  "The pair that should be deleted"
 // @Success 200 {object} models.Response "Successful response indicating the pair was deleted"
-// @Failure 400 {object} models.Response "Invalid input data"
+// @Failure 400 {object} models.Response "Invalid pair format"
 // @Failure 500 {object} models.Response "Internal server error"
 // @Router /api/user/pair [delete]
 func (uc *userPairsController) DeletePair(c *fiber.Ctx) error {
-	pair := c.Query("pair")                // Retrieve pair from query string
 	user := c.Locals("user").(models.User) // Retrieve authenticated user from context locals
 
+	pair, err := models.NormalizePair(c.Query("pair")) // Retrieve pair from query string in the form it is stored
+	if err != nil {
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error if the assets of the pair can't be told apart
+	}
+
 	userPairData := models.UserPairs{
 		UserID: user.ID, // Set the UserID field to the authenticated user's ID
 		Pair:   pair,    // Set the Pair field to the trading pair retrieved from the query
@@ -289,23 +1147,25 @@ func (uc *userPairsController) DeletePair(c *fiber.Ctx) error {
 
 	// Call the service to delete the specified pair from the database
 	if err := uc.userPairsService.DeletePair(c.Context(), userPairData); err != nil {
-		uc.logger.Error(err)
-
-		c.Status(http.StatusInternalServerError) // Set HTTP status to 500 if an error occurs
-
-		return c.JSON(models.Response{
-			Result: err.Error(), // Return error message in JSON format
-		})
+		return c.JSON(errorResponse(c, uc.logger, err)) // Return error message in JSON format
 	}
 
 	uc.userService.DeleteUserIdFromMemory(user.ID) // Remove the user's ID from the in-memory storage
 
-	// Iterate over all exchanges and remove the pair from their subscribed pairs
+	// Iterate over all exchanges and remove the pair from the subscribed pairs of those nobody else watches it on
 	for _, exchange := range uc.allExchangesStorage.All() {
-		exchange.DeletePairFromSubscribedPairs(pair) // Remove the pair from each exchange's subscribed pairs
-
 		userPairData.Exchange = exchange.ExchangeName() // Set the Exchange field to the exchange's name
-		uc.foundVolumesService.DeleteFoundVolume(userPairData)
+
+		subscribers, err := uc.userPairsService.CountPairSubscribers(c.Context(), userPairData.Exchange, pair)
+		if err != nil {
+			uc.logger.Error(err) // Keep the subscription rather than stop fetching the book for other users
+		} else if subscribers == 0 {
+			exchange.DeletePairFromSubscribedPairs(pair) // Nobody watches the pair on the exchange anymore
+		}
+
+		if err := uc.foundVolumesService.DeleteFoundVolume(c.Context(), userPairData); err != nil {
+			uc.logger.Error(err)
+		}
 	}
 
 	return c.JSON(models.Response{
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"net/http"
+
+	"cvs/internal/models"
+	"cvs/internal/service"
+	"cvs/internal/service/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// userSettingsController handles operations related to the notification preferences of users.
+type userSettingsController struct {
+	userSettingsService service.UserSettingsService // Service for managing user settings
+	logger              logger.Logger
+}
+
+// NewUserSettingsController creates a new instance of userSettingsController.
+//
+// Parameters:
+//   - userSettingsService: The service for managing user settings.
+//   - logger: The logger of the failed requests.
+//
+// Returns:
+//   - *userSettingsController: A pointer to the initialized userSettingsController instance.
+func NewUserSettingsController(userSettingsService service.UserSettingsService, logger logger.Logger) *userSettingsController {
+	return &userSettingsController{
+		userSettingsService: userSettingsService,
+		logger:              logger,
+	}
+}
+
+// GetSettings retrieves the notification preferences of the authenticated user.
+// A user who has never saved the settings gets the default ones.
+//
+// @Summary Retrieve the settings of the authenticated user
+// @Description Get the notification preferences of the authenticated user
+// @Tags user-settings
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Success 200 {object} models.UserSettings "Settings of the user"
+// @Failure 500 {object} models.Response "Internal server error"
+// @Router /api/user/settings [get]
+func (usc *userSettingsController) GetSettings(c *fiber.Ctx) error {
+	userID := c.Locals("user").(models.User).ID // Retrieve authenticated user's ID from context locals
+
+	settings, err := usc.userSettingsService.GetSettings(c.Context(), userID)
+	if err != nil {
+		return c.JSON(errorResponse(c, usc.logger, err)) // Return error message in JSON format
+	}
+
+	return c.JSON(settings) // Return the settings in JSON format
+}
+
+// UpdateSettings replaces the notification preferences of the authenticated user.
+//
+// The function performs the following steps:
+// 1. Parses the request body into a `UserSettings` struct and sets the authenticated user's ID.
+// 2. Returns 400 if the settings are invalid, e.g. the minimum volume to notify about is negative.
+// 3. Calls the service to save the settings and returns a JSON response indicating success or failure.
+//
+// @Summary Update the settings of the authenticated user
+// @Description Replace the notification preferences of the authenticated user. Found volumes below min_volume_notify are not pushed to the user
+// @Tags user-settings
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Access token"
+// @Param settings body models.UserSettings true "User settings"
+// @Success 200 {object} models.Response "Settings updated"
+// @Failure 400 {object} models.Response "Invalid input data"
+// @Failure 500 {object} models.Response "Internal server error"
+// @Router /api/user/settings [put]
+func (usc *userSettingsController) UpdateSettings(c *fiber.Ctx) error {
+	var settings models.UserSettings
+
+	// Parse the request body into settings
+	if err := c.BodyParser(&settings); err != nil {
+		c.Status(http.StatusBadRequest)
+
+		return c.JSON(models.Response{
+			Result: errInvalidInputData, // Return error if parsing fails
+			Code:   models.CodeInvalidInput,
+		})
+	}
+
+	settings.UserID = c.Locals("user").(models.User).ID // The settings always belong to the authenticated user
+
+	if err := service.CheckUserSettings(settings); err != nil {
+		return c.JSON(errorResponse(c, usc.logger, err)) // Return validation error message in JSON format
+	}
+
+	if err := usc.userSettingsService.UpdateSettings(c.Context(), settings); err != nil {
+		return c.JSON(errorResponse(c, usc.logger, err)) // Return error message in JSON format
+	}
+
+	return c.JSON(models.Response{
+		Result: "settings updated successfully",
+	}) // Return success message in JSON format
+}
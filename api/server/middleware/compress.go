@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
+)
+
+// Compression levels accepted by Compress.
+const (
+	CompressionDisabled        = -1 // The responses aren't compressed
+	CompressionDefault         = 0  // Balance between the speed and the size of the responses
+	CompressionBestSpeed       = 1  // The fastest compression
+	CompressionBestCompression = 2  // The smallest responses
+)
+
+// Compress is a middleware that compresses the responses with gzip, deflate or brotli,
+// whichever the client accepts in the Accept-Encoding header.
+//
+// The found volumes and the pairs of the exchanges are large JSON arrays, which shrink several times.
+// The websocket upgrade requests are skipped, since the upgraded connection isn't an HTTP response
+// and the compression would break the handshake.
+//
+// Parameters:
+//   - level int: The compression level, one of the Compression constants. The levels out of the range use the default one.
+//
+// Returns:
+//   - fiber.Handler: A Fiber handler compressing the responses, or passing them through if the compression is disabled.
+func Compress(level int) fiber.Handler {
+	if level < CompressionDisabled || level > CompressionBestCompression {
+		level = CompressionDefault
+	}
+
+	return compress.New(compress.Config{
+		Next: func(c *fiber.Ctx) bool {
+			return websocket.IsWebSocketUpgrade(c) // Leave the handshake of the websocket intact
+		},
+		Level: compress.Level(level),
+	})
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"cvs/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RestrictIPs is a middleware that allows only the clients whose IP address is in the allowlist to proceed.
+//
+// The client IP address is taken from c.IP(), so the X-Forwarded-For header is respected only when the request
+// comes from a trusted proxy configured in the Fiber application. The clients outside the allowlist are rejected
+// with 403. An empty allowlist allows every client, so the restriction is opt-in.
+//
+// Parameters:
+//   - allowed []string: The allowed IP addresses and CIDR ranges, e.g. "10.0.0.0/8" or "127.0.0.1".
+//
+// Returns:
+//   - fiber.Handler: A Fiber handler function that performs the IP address check.
+//
+// RestrictIPs panics if an entry is neither an IP address nor a CIDR range, so a misconfiguration fails at startup.
+func RestrictIPs(allowed []string) fiber.Handler {
+	networks := make([]*net.IPNet, 0, len(allowed))
+	for _, entry := range allowed {
+		networks = append(networks, parseAllowedNetwork(entry))
+	}
+
+	return func(c *fiber.Ctx) error {
+		if len(networks) == 0 {
+			return c.Next() // Nothing is restricted without an allowlist
+		}
+
+		if ip := net.ParseIP(c.IP()); ip != nil {
+			for _, network := range networks {
+				if network.Contains(ip) {
+					return c.Next() // Proceed to the next middleware or handler
+				}
+			}
+		}
+
+		c.Status(http.StatusForbidden)
+
+		return c.JSON(models.Response{
+			Result: "access denied from this IP address", // Return error if the client isn't allowed
+		})
+	}
+}
+
+// parseAllowedNetwork parses an entry of the allowlist, a single IP address is treated as a network of one address.
+func parseAllowedNetwork(entry string) *net.IPNet {
+	entry = strings.TrimSpace(entry)
+
+	if strings.Contains(entry, "/") {
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			panic("invalid CIDR range in the IP allowlist: " + entry)
+		}
+
+		return network
+	}
+
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		panic("invalid IP address in the IP allowlist: " + entry)
+	}
+
+	bits := 8 * net.IPv6len
+	if ipv4 := ip.To4(); ipv4 != nil {
+		ip, bits = ipv4, 8*net.IPv4len
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+}
@@ -8,13 +8,19 @@ and responses, allowing for processing such as logging, authentication, rate lim
 The following middlewares are configured in this package:
 
   - CORS Middleware: Manages Cross-Origin Resource Sharing settings to control which origins can access resources.
-  - Logger Middleware: Logs incoming requests and responses to a specified log file for monitoring and debugging.
+  - Request Logger Middleware: Assigns an ID to every request and logs the request with it, so it can be correlated with the logs of the services.
   - Rate Limiter Middleware: Limits the number of requests from a single IP address to prevent abuse and ensure fair usage.
+    A tighter limiter is applied to the authentication routes by AuthLimiter.
+  - Compression Middleware: Compresses the responses for the clients accepting it, except the websocket upgrades.
 
 The middleware functions included in this package are:
 
  1. **MiddlewaresSetup**: Configures and applies the necessary middlewares to the provided Fiber application instance.
- 2. **IsAuthenticated**: A middleware that checks if the user is authenticated using JSON Web Tokens (JWT). It verifies the presence and validity of the JWT in the Authorization header.
+ 2. **AuthLimiter**: A stricter rate limiter for the authentication routes, which are the usual target of brute force.
+ 3. **IsAuthenticated**: A middleware that checks if the user is authenticated using JSON Web Tokens (JWT). It verifies the presence and validity of the JWT in the Authorization header.
+ 4. **IsAuthorized**: A middleware that allows only the authenticated users with the required role to proceed.
+ 5. **RestrictIPs**: A middleware that allows only the clients from the allowlisted IP addresses and CIDR ranges to proceed.
+ 6. **Compress**: A middleware that compresses the responses with the configured level.
 
 Example usage of this package can be seen in the main application file where these middlewares are applied to the Fiber app instance.
 */
@@ -23,12 +29,19 @@ package middleware
 import (
 	"cvs/internal/models"  // Importing models for data structures
 	"cvs/internal/service" // Importing service layer for business logic
+	"cvs/internal/service/logger"
 	"net/http"
+	"time"
 
 	"github.com/gofiber/fiber/v2"                    // Importing Fiber framework
 	"github.com/gofiber/fiber/v2/middleware/cors"    // Importing CORS middleware
 	"github.com/gofiber/fiber/v2/middleware/limiter" // Importing rate limiting middleware
-	"github.com/gofiber/fiber/v2/middleware/logger"  // Importing logging middleware
+)
+
+const (
+	defaultGlobalRateLimit     = 1000        // Default maximum number of requests from an IP address to the whole API
+	defaultAuthRateLimit       = 10          // Default maximum number of requests from an IP address to the authentication routes
+	defaultRateLimitExpiration = time.Minute // Default time window the requests are counted in
 )
 
 // MiddlewaresSetup configures and applies various middlewares to the provided Fiber application.
@@ -39,51 +52,106 @@ import (
 //   - Allows specific HTTP methods (POST, GET, DELETE, PUT) for cross-origin requests.
 //   - Specifies allowed headers (Accept, Accept-Language, Content-Type) in requests.
 //
-// 2. Logger Middleware:
-//   - Logs incoming requests and responses to a specified log file.
-//   - The log output can be customized by modifying the logger configuration.
+// 2. Request Logger Middleware:
+//   - Assigns an ID to every request and returns it in the X-Request-ID header.
+//   - Logs the method, path, status and latency of the request with the ID.
 //
 // 3. Rate Limiter Middleware:
 //   - Limits the maximum number of requests per IP address to prevent abuse.
-//   - Configured to allow a maximum of 1000 requests from a single IP address.
+//   - Every client IP address has its own budget, so one client can't exhaust the limit of the others.
+//
+// 4. Compression Middleware:
+//   - Compresses the responses with the encoding accepted by the client, see Compress.
 //
 // Parameters:
 //   - server *fiber.App: The Fiber application instance to which the middlewares will be applied.
+//   - globalRateLimit int: The maximum number of requests from an IP address within the expiration, 1000 if it isn't positive.
+//   - rateLimitExpiration time.Duration: The time window the requests are counted in, a minute if it isn't positive.
+//   - logger logger.Logger: The logger the requests are logged to.
+//   - compressionLevel int: The level of the response compression, CompressionDisabled turns it off.
 //
 // Example Usage:
 //
 //	func main() {
 //	    app := fiber.New()
-//	    middleware.MiddlewaresSetup(app)
+//	    middleware.Setup(app, 1000, time.Minute, appLogger, middleware.CompressionDefault)
 //	    app.Listen(":3000")
 //	}
-func Setup(server *fiber.App) {
+func Setup(server *fiber.App, globalRateLimit int, rateLimitExpiration time.Duration, logger logger.Logger, compressionLevel int) {
+	if globalRateLimit <= 0 {
+		globalRateLimit = defaultGlobalRateLimit
+	}
+
 	server.Use(
 		cors.New(cors.Config{
-			AllowMethods: "POST, GET, DELETE, PUT",                               // Specify allowed HTTP methods
-			AllowHeaders: "Accept, Accept-Language, Content-Type, Authorization", // Specify allowed headers
-		}),
-		logger.New(),
-		limiter.New(limiter.Config{
-			Max: 1000, // Set maximum number of requests per IP address
+			AllowMethods:  "POST, GET, DELETE, PUT",                               // Specify allowed HTTP methods
+			AllowHeaders:  "Accept, Accept-Language, Content-Type, Authorization", // Specify allowed headers
+			ExposeHeaders: RequestIDHeader,                                        // Let the browsers read the ID of the request
 		}),
+		RequestLogger(logger),
+		ipLimiter(globalRateLimit, rateLimitExpiration),
+		Compress(compressionLevel),
 	)
 }
 
+// AuthLimiter returns a rate limiter for the authentication routes, such as signup, login and password recovery.
+//
+// The limiter is stricter than the global one, as these routes are the target of credential stuffing
+// and email flooding. The requests are counted per client IP address, and all routes using the
+// returned handler share the budget of the address.
+//
+// Parameters:
+//   - max int: The maximum number of requests from an IP address within the expiration, 10 if it isn't positive.
+//   - expiration time.Duration: The time window the requests are counted in, a minute if it isn't positive.
+//
+// Returns:
+//   - fiber.Handler: A Fiber handler responding with 429 Too Many Requests once the limit is reached.
+func AuthLimiter(max int, expiration time.Duration) fiber.Handler {
+	if max <= 0 {
+		max = defaultAuthRateLimit
+	}
+
+	return ipLimiter(max, expiration)
+}
+
+// ipLimiter returns a rate limiter counting the requests per client IP address.
+func ipLimiter(max int, expiration time.Duration) fiber.Handler {
+	if expiration <= 0 {
+		expiration = defaultRateLimitExpiration
+	}
+
+	return limiter.New(limiter.Config{
+		Max:        max,        // Set maximum number of requests per IP address
+		Expiration: expiration, // Set the time window the requests are counted in
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return c.IP() // Every client IP address has its own budget
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			c.Status(http.StatusTooManyRequests)
+
+			return c.JSON(models.Response{
+				Result: "too many requests, try again later", // Return error message in JSON format
+			})
+		},
+	})
+}
+
 // IsAuthenticated is a middleware that checks if the user is authenticated using JWT.
 //
 // This middleware retrieves the JWT from the Authorization header and validates it by parsing
 // the token to extract user ID and session ID. It then checks if the user exists in the database
-// and whether the session ID matches. If authentication is successful, it stores the user information
-// in context locals for later use; otherwise, it returns an error response.
+// and whether the session of the token wasn't revoked. Users whose email isn't verified yet are rejected with 403.
+// If authentication is successful, it updates the time the session was last seen at and stores the user
+// and the session in context locals for later use; otherwise, it returns an error response.
 //
 // Parameters:
 //   - jwtService service.JwtService: The service responsible for parsing JWT tokens.
 //   - userService service.UserService: The service responsible for user-related operations.
+//   - sessionService service.SessionService: The service responsible for the sessions of users.
 //
 // Returns:
 //   - fiber.Handler: A Fiber handler function that performs authentication checks.
-func IsAuthenticated(jwtService service.JwtService, userService service.UserService) fiber.Handler {
+func IsAuthenticated(jwtService service.JwtService, userService service.UserService, sessionService service.SessionService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		jwt := c.Get("Authorization")     // Retrieve the JWT from the Authorization header
 		c.Status(http.StatusUnauthorized) // Set the default response status to Unauthorized
@@ -103,15 +171,62 @@ func IsAuthenticated(jwtService service.JwtService, userService service.UserServ
 			})
 		}
 
-		if sessionId != userFromDB.SessionID {
+		session, err := sessionService.GetSession(c.Context(), userID, sessionId) // Fetch the session the token was issued for
+		if err != nil {
+			return c.JSON(models.Response{
+				Result: "invalid token", // Return error if the session was revoked
+			})
+		}
+
+		if !userFromDB.Verified {
+			c.Status(http.StatusForbidden)
+
 			return c.JSON(models.Response{
-				Result: "invalid token", // Return error if session ID does not match
+				Result: "email is not verified, follow the link sent to your email", // Return error if the account isn't active yet
 			})
 		}
 
+		sessionService.TouchSession(c.Context(), session) // The last seen time is informative, so its errors don't reject the request
+
 		c.Locals("user", userFromDB) // Store the authenticated user in context locals for later use
+		c.Locals("session", session) // Store the current session in context locals for later use
 		c.Status(http.StatusOK)
 
 		return c.Next() // Proceed to the next middleware or handler
 	}
 }
+
+// IsAuthorized is a middleware that allows only the users with the given role to proceed.
+//
+// It must be applied after IsAuthenticated, which stores the authenticated user in context locals.
+// The role is checked against the user stored in the database rather than the role claim of the token,
+// so a changed role takes effect immediately. Requests without an authenticated user are rejected with 401
+// and the ones of users lacking the role with 403.
+//
+// Parameters:
+//   - role string: The role required to proceed, e.g. models.RoleAdmin.
+//
+// Returns:
+//   - fiber.Handler: A Fiber handler function that performs the role check.
+func IsAuthorized(role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			c.Status(http.StatusUnauthorized)
+
+			return c.JSON(models.Response{
+				Result: "user not found", // Return error if the user isn't authenticated
+			})
+		}
+
+		if user.Role != role {
+			c.Status(http.StatusForbidden)
+
+			return c.JSON(models.Response{
+				Result: "access denied, " + role + " role is required", // Return error if the user lacks the role
+			})
+		}
+
+		return c.Next() // Proceed to the next middleware or handler
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"cvs/internal/service/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID" // Response header carrying the ID of the request
+	requestIDLocal  = "requestID"    // Key of the ID of the request in context locals
+)
+
+// RequestLogger is a middleware that assigns an ID to every request and logs the request once it is handled.
+//
+// The ID is a random UUID, which is stored in context locals and returned in the X-Request-ID header,
+// so the log lines of the controllers and the reports of the clients can be correlated with the request.
+// The method, path, status and latency of the request are logged with the ID.
+//
+// Parameters:
+//   - logger logger.Logger: The logger the requests are logged to.
+//
+// Returns:
+//   - fiber.Handler: A Fiber handler assigning the ID and logging the request.
+func RequestLogger(logger logger.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		requestID := uuid.NewString()
+
+		c.Locals(requestIDLocal, requestID) // Store the ID for the controllers
+		c.Set(RequestIDHeader, requestID)   // Return the ID to the client
+
+		err := c.Next() // Handle the request
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = http.StatusInternalServerError // The error handler replies 500 unless the error carries a status
+
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		logger.Infof("request_id=%s method=%s path=%s status=%d latency=%s", requestID, c.Method(), c.Path(), status, time.Since(start))
+
+		return err
+	}
+}
+
+// RequestID returns the ID assigned to the request by RequestLogger,
+// so the controllers can add it to their own log lines.
+//
+// Parameters:
+//   - c *fiber.Ctx: The context of the request.
+//
+// Returns:
+//   - string: The ID of the request, empty if RequestLogger isn't used.
+func RequestID(c *fiber.Ctx) string {
+	requestID, _ := c.Locals(requestIDLocal).(string)
+
+	return requestID
+}
@@ -0,0 +1,30 @@
+package route
+
+import (
+	"cvs/api/server/controller" // Importing the controller package for handling admin operations
+	"cvs/internal/service/exchange"
+	"cvs/internal/service/logger"
+
+	"github.com/gofiber/fiber/v2" // Importing Fiber framework for web server
+)
+
+// NewAdminRouter sets up the routes available only to the users with the admin role.
+//
+// This function defines the following routes, which require the group to be protected
+// by the authentication and admin role middlewares:
+//   - GET /api/admin/exchanges/stats: Endpoint to retrieve the scanning load and the last fetch status of every exchange.
+//   - POST /api/admin/exchanges/:name/refresh-pairs: Endpoint to fetch the pairs listed on the exchange on demand.
+//
+// Parameters:
+//   - group: A Fiber router group for organizing admin routes.
+//   - allExchangesStorage: A storage for all exchanges, allowing access to exchange-related operations.
+func NewAdminRouter(
+	group fiber.Router,
+	allExchangesStorage exchange.AllExchanges,
+	logger logger.Logger,
+) {
+	ac := controller.NewAdminController(allExchangesStorage, logger) // Create a new instance of AdminController
+
+	group.Get("/exchanges/stats", ac.GetExchangesStats)                   // Route for retrieving the stats of all exchanges
+	group.Post("/exchanges/:name/refresh-pairs", ac.RefreshExchangePairs) // Route for refreshing the pairs of an exchange
+}
@@ -0,0 +1,35 @@
+package route
+
+import (
+	"cvs/api/server/controller" // Importing the controller package for handling exchange operations
+	"cvs/internal/service/exchange"
+	"cvs/internal/service/logger"
+
+	"github.com/gofiber/fiber/v2" // Importing Fiber framework for web server
+)
+
+// NewExchangesRouter sets up the read-only routes of the supported exchanges.
+//
+// This function defines the following routes, which don't require authentication:
+//   - GET /api/exchanges: Endpoint to retrieve the names of all supported exchanges.
+//   - GET /api/exchanges/:name/pairs: Endpoint to retrieve all pairs available on the exchange.
+//   - GET /api/exchanges/:name/spread: Endpoint to retrieve the best prices, the spread and the mid price of a pair.
+//   - GET /api/exchanges/:name/orderbook: Endpoint to retrieve the price levels of the order book of a pair.
+//   - GET /api/exchanges/:name/search-volume: Endpoint to preview the volumes an exact value would find in the order book of a pair.
+//
+// Parameters:
+//   - group: A Fiber router group for organizing exchange-related routes.
+//   - allExchangesStorage: A storage for all exchanges, allowing access to exchange-related operations.
+func NewExchangesRouter(
+	group fiber.Router,
+	allExchangesStorage exchange.AllExchanges,
+	logger logger.Logger,
+) {
+	ec := controller.NewExchangesController(allExchangesStorage, logger) // Create a new instance of ExchangesController
+
+	group.Get("", ec.GetExchanges)                         // Route for retrieving all exchange names
+	group.Get("/:name/pairs", ec.GetExchangePairs)         // Route for retrieving all pairs of the exchange
+	group.Get("/:name/spread", ec.GetExchangeSpread)       // Route for retrieving the spread of a pair on the exchange
+	group.Get("/:name/orderbook", ec.GetExchangeOrderbook) // Route for retrieving the order book of a pair on the exchange
+	group.Get("/:name/search-volume", ec.PreviewVolumes)   // Route for previewing the volumes found in the order book of a pair
+}
@@ -0,0 +1,31 @@
+package route
+
+import (
+	"time"
+
+	"cvs/api/server/controller" // Importing the controller package for handling health probes
+	"cvs/internal/service/exchange"
+
+	"github.com/gofiber/fiber/v2" // Importing Fiber framework for web server
+)
+
+// NewHealthRouter sets up the liveness and readiness routes of the service.
+//
+// This function defines the following routes, which don't require authentication:
+//   - GET /health: Endpoint reporting that the process is alive.
+//   - GET /ready: Endpoint reporting the connectivity of every exchange, responds with 503 if any of them is stale.
+//
+// Parameters:
+//   - router: A Fiber router the health routes are mounted on.
+//   - allExchangesStorage: A storage for all exchanges, allowing access to exchange-related operations.
+//   - readinessStaleness: The maximum time since the last successful fetch of a ready exchange.
+func NewHealthRouter(
+	router fiber.Router,
+	allExchangesStorage exchange.AllExchanges,
+	readinessStaleness time.Duration,
+) {
+	hc := controller.NewHealthController(allExchangesStorage, readinessStaleness) // Create a new instance of healthController
+
+	router.Get("/health", hc.Health) // Route for the liveness probe
+	router.Get("/ready", hc.Ready)   // Route for the readiness probe
+}
@@ -0,0 +1,18 @@
+package route
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// NewMetricsRouter sets up the route exposing the Prometheus metrics.
+//
+// This function defines the following route, which doesn't require authentication:
+//   - GET /metrics: Endpoint serving the metrics of the default Prometheus registry in the text exposition format.
+//
+// Parameters:
+//   - router: A Fiber router the metrics route is mounted on.
+func NewMetricsRouter(router fiber.Router) {
+	router.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
+}
@@ -0,0 +1,29 @@
+package route
+
+import (
+	"cvs/api/server/controller" // Importing the controller package for handling exchange operations
+	"cvs/internal/service/exchange"
+	"cvs/internal/service/logger"
+
+	"github.com/gofiber/fiber/v2" // Importing Fiber framework for web server
+)
+
+// NewPairsRouter sets up the read-only routes of the pairs of all exchanges.
+//
+// This function defines the following routes, which don't require authentication:
+//   - GET /api/pairs/search: Endpoint to search the pairs of all exchanges by their base or quote asset.
+//   - GET /api/pairs/aggregate-volume: Endpoint to preview the volumes of a pair across all exchanges listing it.
+//
+// Parameters:
+//   - group: A Fiber router group for organizing pairs-related routes.
+//   - allExchangesStorage: A storage for all exchanges, allowing access to exchange-related operations.
+func NewPairsRouter(
+	group fiber.Router,
+	allExchangesStorage exchange.AllExchanges,
+	logger logger.Logger,
+) {
+	ec := controller.NewExchangesController(allExchangesStorage, logger) // Create a new instance of ExchangesController
+
+	group.Get("/search", ec.SearchPairs)                // Route for searching the pairs of all exchanges
+	group.Get("/aggregate-volume", ec.AggregateVolumes) // Route for previewing the volumes of a pair across all exchanges
+}
@@ -12,6 +12,12 @@ Key functionalities provided by this package include:
 2. **Documentation Routes**: A dedicated route group for API documentation, making it easier to access and view API specifications.
 3. **User Routes**: Routes related to user operations, such as registration, login, and profile management.
 4. **User Pairs Routes**: Routes specifically for managing user pairs, which require authentication to access.
+5. **User Settings Routes**: Routes for the notification preferences of the user, which require authentication to access.
+   The routes listing and revoking the sessions of the user require authentication as well.
+6. **Health Routes**: Liveness and readiness probes reporting the connectivity of the exchanges.
+7. **Pairs Routes**: The search of pairs and of their volumes across all exchanges, which doesn't require authentication.
+8. **Metrics Route**: Prometheus metrics of the scan loops and the requests to the exchanges.
+9. **Admin Routes**: Operator endpoints, which require authentication, the admin role and an allowlisted client IP address.
 
 The following functions are defined in this package:
 
@@ -23,8 +29,12 @@ Example usage of this package can be seen in the main application file where the
 package route
 
 import (
+	"time"
+
 	"cvs/api/server/middleware" // Importing middleware for route protection
-	"cvs/internal/service"      // Importing services for business logic
+	"cvs/internal/config"
+	"cvs/internal/models"
+	"cvs/internal/service" // Importing services for business logic
 	"cvs/internal/service/exchange"
 	"cvs/internal/service/logger"
 
@@ -49,13 +59,46 @@ import (
 //   - Sets up a nested route group under `/user/pairs` for managing user pairs,
 //   - Requires authentication via JWT middleware.
 //
+// 4. **User Settings Route Group**:
+//   - Sets up a nested route group under `/user/settings` for the notification preferences of the user,
+//   - Requires authentication via JWT middleware.
+//
+// 5. **User Sessions Route Group**:
+//   - Sets up a nested route group under `/user/sessions` listing and revoking the sessions of the user,
+//   - Requires authentication via JWT middleware.
+//
+// 6. **Exchanges Route Group**:
+//   - Sets up a route group under `/exchanges` listing the supported exchanges and their pairs.
+//   - Doesn't require authentication, so the pairs can be chosen before subscribing.
+//
+// 7. **Pairs Route Group**:
+//   - Sets up a route group under `/pairs` searching the pairs and their volumes on all exchanges at once.
+//   - Doesn't require authentication, like the exchanges routes.
+//
+// 8. **Health Routes**:
+//   - Sets up the `/health` and `/ready` probes on the root of the application.
+//
+// 9. **Metrics Route**:
+//   - Sets up the `/metrics` route serving the Prometheus metrics on the root of the application.
+//
+// 10. **Admin Route Group**:
+//   - Sets up a route group under `/admin` for the operator endpoints.
+//   - Requires an allowlisted client IP address, authentication via JWT middleware and the admin role.
+//
 // Parameters:
 //   - fiber *fiber.App: The Fiber application instance to which the routes will be applied.
 //   - userService service.UserService: The service responsible for user-related operations.
+//   - sessionService service.SessionService: The service responsible for the sessions of users.
 //   - userPairsService service.UserPairsService: The service responsible for managing user pairs.
 //   - jwtService service.JwtService: The service responsible for handling JWT operations.
+//   - emailService service.EmailService: The service responsible for sending emails to users.
 //   - foundVolumesService service.FoundVolumesService: The service responsible for managing found volumes.
+//   - userSettingsService service.UserSettingsService: The service responsible for the notification preferences of users.
 //   - allExchangesStorage exchange.AllExchanges: The storage for all exchanges, allowing access to exchange-related operations.
+//   - readinessStaleness time.Duration: The maximum time since the last successful fetch of a ready exchange.
+//   - adminAllowedIPs []string: The IP addresses and CIDR ranges allowed to reach the admin routes, every one if empty.
+//   - authLimiter fiber.Handler: The rate limiter of the signup, login and forgot password routes.
+//   - passwordPolicy config.PasswordPolicyConfig: The rules the passwords of users must satisfy.
 //
 // Example Usage:
 //
@@ -70,28 +113,50 @@ import (
 func Setup(
 	fiber *fiber.App,
 	userService service.UserService,
+	sessionService service.SessionService,
 	userPairsService service.UserPairsService,
 	jwtService service.JwtService,
+	emailService service.EmailService,
 	foundVolumesService service.FoundVolumesService,
+	userSettingsService service.UserSettingsService,
 	allExchangesStorage exchange.AllExchanges,
+	readinessStaleness time.Duration,
+	adminAllowedIPs []string,
+	authLimiter fiber.Handler,
+	passwordPolicy config.PasswordPolicyConfig,
 	logger logger.Logger,
 ) {
+	NewHealthRouter(fiber, allExchangesStorage, readinessStaleness) // Initialize health probes
+	NewMetricsRouter(fiber)                                         // Initialize Prometheus metrics
+
 	api := fiber.Group("/api") // Create a new group for API routes
 
 	// Group routes for documentation
 	docsRoute := fiber.Group("/docs")
 	NewDocsRouter(docsRoute) // Initialize documentation routes
 
+	exchangesRoute := api.Group("/exchanges")                       // Create a group for exchange-related routes
+	NewExchangesRouter(exchangesRoute, allExchangesStorage, logger) // Initialize exchange routes
+
+	pairsRoute := api.Group("/pairs")                       // Create a group for the pairs of all exchanges
+	NewPairsRouter(pairsRoute, allExchangesStorage, logger) // Initialize pairs routes
+
 	userRoute := api.Group("/user") // Create a group for user-related routes
 	NewUserRouter(
 		userRoute,
 		userService,
+		sessionService,
 		jwtService,
+		emailService,
 		allExchangesStorage,
+		authLimiter,
+		passwordPolicy,
 		logger,
 	) // Initialize user routes
 
-	userPairsRoute := userRoute.Group("/pair").Use(middleware.IsAuthenticated(jwtService, userService)) // Create a protected group for user pairs
+	isAuthenticated := middleware.IsAuthenticated(jwtService, userService, sessionService) // Middleware of the routes requiring authentication
+
+	userPairsRoute := userRoute.Group("/pair").Use(isAuthenticated) // Create a protected group for user pairs
 	NewUserPairsRouter(
 		userPairsRoute,
 		userPairsService,
@@ -100,4 +165,17 @@ func Setup(
 		allExchangesStorage,
 		logger,
 	) // Initialize user pairs routes
+
+	userSettingsRoute := userRoute.Group("/settings").Use(isAuthenticated) // Create a protected group for user settings
+	NewUserSettingsRouter(userSettingsRoute, userSettingsService, logger)  // Initialize user settings routes
+
+	userSessionsRoute := userRoute.Group("/sessions").Use(isAuthenticated) // Create a protected group for user sessions
+	NewUserSessionsRouter(userSessionsRoute, sessionService, logger)       // Initialize user sessions routes
+
+	adminRoute := api.Group("/admin").Use(
+		middleware.RestrictIPs(adminAllowedIPs), // Reject the clients outside the allowlist before touching the database
+		isAuthenticated,
+		middleware.IsAuthorized(models.RoleAdmin),
+	) // Create a group for admins only
+	NewAdminRouter(adminRoute, allExchangesStorage, logger) // Initialize admin routes
 }
@@ -6,7 +6,8 @@ import (
 	"cvs/internal/service/exchange"
 	"cvs/internal/service/logger"
 
-	"github.com/gofiber/fiber/v2" // Importing Fiber framework for web server
+	"github.com/gofiber/contrib/websocket" // Importing websocket support for Fiber
+	"github.com/gofiber/fiber/v2"          // Importing Fiber framework for web server
 )
 
 // NewUserPairsRouter sets up the routes related to user pairs for the application.
@@ -15,19 +16,27 @@ import (
 //
 // 1. **Add User Pair**:
 //   - POST /api/user/pair/add: Endpoint to create a new user pair in the database.
+//   - POST /api/user/pair/bulk: Endpoint to create several user pairs in the database at once.
+//   - POST /api/user/pair/import: Endpoint to restore user pairs from a CSV or JSON file.
 //
 // 2. **Update User Pair**:
 //   - PUT /api/user/pair/update-exact-value: Endpoint to update an existing user pair in the database.
 //
-// 3. **Get All User Pairs**:
+// 3. **Get User Pairs**:
+//   - GET /api/user/pair: Endpoint to retrieve a single user pair of an exchange with its search settings.
 //   - GET /api/user/pair/all-pairs: Endpoint to retrieve all user pairs associated with the authenticated user.
+//   - GET /api/user/pair/export: Endpoint to download all user pairs as a CSV or JSON file.
 //
 // 4. **Delete User Pair**:
 //   - DELETE /api/user/pair: Endpoint to delete a specific user pair from the database.
+//   - DELETE /api/user/pair/all: Endpoint to delete all pairs of the authenticated user at once.
 //
 // 5. **Get All User Found Volumes**:
 //   - GET /api/user/pair/found-volumes: Endpoint to retrieve all found volumes associated with the authenticated user.
 //
+// 6. **Stream User Found Volumes**:
+//   - GET /api/user/pair/found-volumes/ws: Websocket endpoint pushing new found volumes of the authenticated user.
+//
 // Parameters:
 //   - group: A Fiber router group for organizing user pair-related routes.
 //   - userPairsService: A service responsible for managing user pairs data.
@@ -52,8 +61,15 @@ func NewUserPairsRouter(
 
 	// Define routes for managing user pairs
 	group.Post("/add", upc.Add)                            // Route for adding a new user pair
+	group.Post("/bulk", upc.BulkAdd)                       // Route for adding several user pairs at once
+	group.Post("/import", upc.Import)                      // Route for restoring user pairs from a file
 	group.Put("/update-exact-value", upc.UpdateExactValue) // Route for updating an existing user pair
+	group.Put("/replace", upc.Replace)                     // Route for replacing all pairs of the user
+	group.Get("/", upc.GetUserPair)                        // Route for retrieving a single user pair
 	group.Get("/all-pairs", upc.GetAllUserPairs)           // Route for retrieving all user pairs
+	group.Get("/export", upc.Export)                       // Route for downloading all user pairs as a file
 	group.Delete("/", upc.DeletePair)                      // Route for deleting a specific user pair
+	group.Delete("/all", upc.DeleteAllPairs)               // Route for deleting all pairs of the user
 	group.Get("/found-volumes", upc.GetAllUserFoundVolumes)
+	group.Get("/found-volumes/ws", upc.FoundVolumesWebsocketUpgrade, websocket.New(upc.StreamFoundVolumes)) // Route for streaming found volumes
 }
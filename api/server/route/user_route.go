@@ -3,7 +3,8 @@ package route
 import (
 	"cvs/api/server/controller" // Importing the controller package for handling requests
 	"cvs/api/server/middleware" // Importing middleware for request authentication
-	"cvs/internal/service"      // Importing service layer for business logic
+	"cvs/internal/config"
+	"cvs/internal/service" // Importing service layer for business logic
 	"cvs/internal/service/exchange"
 	"cvs/internal/service/logger"
 
@@ -15,9 +16,15 @@ import (
 // This function creates a new router group for user operations and defines the following routes:
 //
 // 1. **Authentication Routes**:
-//   - POST /api/auth/signup: Endpoint for user registration.
-//   - POST /api/auth/login: Endpoint for user login.
-//   - GET /api/auth/tokens: Endpoint to retrieve tokens, requires authentication.
+//   - POST /api/auth/signup: Endpoint for user registration, rate limited by the auth limiter.
+//   - POST /api/auth/login: Endpoint for user login, rate limited by the auth limiter.
+//   - GET /api/auth/tokens: Endpoint to rotate the refresh token and retrieve new tokens, requires the refresh token.
+//   - POST /api/auth/forgot-password: Endpoint to request a password reset token by email, rate limited by the auth limiter.
+//   - POST /api/auth/reset-password: Endpoint to set a new password using the reset token.
+//   - GET /api/auth/verify: Endpoint to verify the user's email using the token sent on signup.
+//   - PUT /api/auth/email: Endpoint to request a change of the user's email, requires authentication.
+//   - GET /api/auth/email/confirm: Endpoint to confirm the new email using the token sent to it.
+//   - POST /api/auth/logout: Endpoint to revoke the current session, requires authentication.
 //
 // 2. **User Management Routes**:
 //   - PUT /api/user/update-password: Endpoint to update the user's password, requires authentication.
@@ -26,21 +33,37 @@ import (
 // Parameters:
 //   - group: A Fiber router group for organizing user-related routes.
 //   - userService: A service responsible for user-related operations.
+//   - sessionService: A service responsible for the sessions of users.
 //   - jwtService: A service responsible for handling JWT operations.
+//   - emailService: A service responsible for sending emails to users.
+//   - authLimiter: A rate limiter applied to the routes which are the target of brute force and email flooding.
+//   - passwordPolicy: The rules the passwords set on signup, update and reset must satisfy.
 func NewUserRouter(
 	group fiber.Router,
 	userService service.UserService,
+	sessionService service.SessionService,
 	jwtService service.JwtService,
+	emailService service.EmailService,
 	allExchangesStorage exchange.AllExchanges,
+	authLimiter fiber.Handler,
+	passwordPolicy config.PasswordPolicyConfig,
 	logger logger.Logger,
 ) {
-	uc := controller.NewUserController(userService, jwtService, allExchangesStorage, logger) // Create a new instance of UserController
+	uc := controller.NewUserController(userService, sessionService, jwtService, emailService, allExchangesStorage, passwordPolicy, logger) // Create a new instance of UserController
+	isAuthenticated := middleware.IsAuthenticated(jwtService, userService, sessionService)                                                 // Middleware of the routes requiring authentication
 
-	authRoutes := group.Group("/auth")                                                        // Create a sub-group for authentication routes
-	authRoutes.Post("/signup", uc.Signup)                                                     // Route for user signup
-	authRoutes.Post("/login", uc.Login)                                                       // Route for user login
-	authRoutes.Get("/tokens", middleware.IsAuthenticated(jwtService, userService), uc.Tokens) // Route to get tokens with authentication
+	authRoutes := group.Group("/auth")                                  // Create a sub-group for authentication routes
+	authRoutes.Post("/signup", authLimiter, uc.Signup)                  // Route for user signup
+	authRoutes.Post("/login", authLimiter, uc.Login)                    // Route for user login
+	authRoutes.Get("/tokens", uc.Tokens)                                // Route to get tokens, the refresh token is validated by the controller
+	authRoutes.Post("/forgot-password", authLimiter, uc.ForgotPassword) // Route to request a password reset token
+	authRoutes.Post("/reset-password", uc.ResetPassword)                // Route to reset password with the reset token
+	authRoutes.Get("/verify", uc.VerifyEmail)                           // Route to verify email with the verification token
+	authRoutes.Get("/email/confirm", uc.ConfirmEmail)                   // Route to confirm the new email with the email change token
 
-	group.Put("/update-password", middleware.IsAuthenticated(jwtService, userService), uc.UpdatePassword) // Route to update password with authentication
-	group.Delete("", middleware.IsAuthenticated(jwtService, userService), uc.DeleteUser)                  // Route to delete user account with authentication
+	authRoutes.Post("/logout", isAuthenticated, uc.Logout)    // Route to revoke the current session with authentication
+	authRoutes.Put("/email", isAuthenticated, uc.ChangeEmail) // Route to request an email change with authentication
+
+	group.Put("/update-password", isAuthenticated, uc.UpdatePassword) // Route to update password with authentication
+	group.Delete("", isAuthenticated, uc.DeleteUser)                  // Route to delete user account with authentication
 }
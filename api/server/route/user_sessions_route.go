@@ -0,0 +1,33 @@
+package route
+
+import (
+	"cvs/api/server/controller" // Importing the controller package for handling user sessions operations
+	"cvs/internal/service"      // Importing service layer for business logic related to user sessions
+	"cvs/internal/service/logger"
+
+	"github.com/gofiber/fiber/v2" // Importing Fiber framework for web server
+)
+
+// NewUserSessionsRouter sets up the routes related to the sessions of users.
+//
+// This function defines the following routes:
+//
+// 1. **List User Sessions**:
+//   - GET /api/user/sessions: Endpoint to list the sessions of the authenticated user.
+//
+// 2. **Revoke User Session**:
+//   - DELETE /api/user/sessions/:id: Endpoint to revoke a session of the authenticated user.
+//
+// Parameters:
+//   - group: A Fiber router group for organizing user sessions routes.
+//   - sessionService: A service responsible for managing the sessions of users.
+func NewUserSessionsRouter(
+	group fiber.Router,
+	sessionService service.SessionService,
+	logger logger.Logger,
+) {
+	sc := controller.NewSessionController(sessionService, logger) // Create a new instance of SessionController
+
+	group.Get("", sc.List)          // Route for listing the sessions
+	group.Delete("/:id", sc.Revoke) // Route for revoking a session
+}
@@ -0,0 +1,33 @@
+package route
+
+import (
+	"cvs/api/server/controller" // Importing the controller package for handling user settings operations
+	"cvs/internal/service"      // Importing service layer for business logic related to user settings
+	"cvs/internal/service/logger"
+
+	"github.com/gofiber/fiber/v2" // Importing Fiber framework for web server
+)
+
+// NewUserSettingsRouter sets up the routes related to the notification preferences of users.
+//
+// This function defines the following routes:
+//
+// 1. **Get User Settings**:
+//   - GET /api/user/settings: Endpoint to retrieve the settings of the authenticated user.
+//
+// 2. **Update User Settings**:
+//   - PUT /api/user/settings: Endpoint to replace the settings of the authenticated user.
+//
+// Parameters:
+//   - group: A Fiber router group for organizing user settings routes.
+//   - userSettingsService: A service responsible for managing user settings.
+func NewUserSettingsRouter(
+	group fiber.Router,
+	userSettingsService service.UserSettingsService,
+	logger logger.Logger,
+) {
+	usc := controller.NewUserSettingsController(userSettingsService, logger) // Create a new instance of UserSettingsController
+
+	group.Get("", usc.GetSettings)    // Route for retrieving the settings
+	group.Put("", usc.UpdateSettings) // Route for updating the settings
+}
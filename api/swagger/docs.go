@@ -16,16 +16,16 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/user": {
-            "delete": {
-                "description": "Delete the authenticated user's account",
+        "/api/admin/exchanges/stats": {
+            "get": {
+                "description": "Get per exchange the number of subscribed and listed pairs, the status of the last fetch and the minimum volume floor. Requires the admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Delete a user account",
+                "summary": "Exchanges stats",
                 "parameters": [
                     {
                         "type": "string",
@@ -37,13 +37,22 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successful response",
+                        "description": "Stats of the exchanges",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ExchangeStats"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "Admin role is required",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
@@ -51,45 +60,60 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/auth/login": {
+        "/api/admin/exchanges/{name}/refresh-pairs": {
             "post": {
-                "description": "Authenticate a user and issue tokens if successful",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Fetch the pairs listed on the exchange now instead of waiting for the periodic refresh. Requires the admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Log in a user",
+                "summary": "Refresh pairs of an exchange",
                 "parameters": [
                     {
-                        "description": "User login data",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UserAuth"
-                        }
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "binance_spot",
+                        "description": "Exchange name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "New tokens data",
+                        "description": "Pairs of the exchange are refreshed",
                         "schema": {
-                            "$ref": "#/definitions/models.Tokens"
+                            "$ref": "#/definitions/models.ExchangePairsRefresh"
                         }
                     },
-                    "400": {
-                        "description": "Invalid input data",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "Admin role is required",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Exchange not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "502": {
+                        "description": "Exchange request failed",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
@@ -97,45 +121,79 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/auth/signup": {
-            "post": {
-                "description": "Create a new user account with email and password.\nReturns the access token, the refresh token, and the time when the access token ceases to be valid. After the access token has ceased to be valid, you need to send a request along the path \"/api/user/auth/token\" to get a new pair of tokens.",
-                "consumes": [
+        "/api/exchanges": {
+            "get": {
+                "description": "Get the names of all supported exchanges, which are used as the exchange of user pairs",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "exchanges"
+                ],
+                "summary": "List exchanges",
+                "responses": {
+                    "200": {
+                        "description": "List of exchange names",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/exchanges/{name}/orderbook": {
+            "get": {
+                "description": "Get the asks sorted by price ascending and the bids sorted by price descending of a pair subscribed by any user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "exchanges"
                 ],
-                "summary": "Sign up a new user",
+                "summary": "Get the order book of a pair",
                 "parameters": [
                     {
-                        "description": "User registration data",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UserAuth"
-                        }
+                        "type": "string",
+                        "example": "binance_spot",
+                        "description": "Exchange name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "BTC/USDT",
+                        "description": "Pair name",
+                        "name": "pair",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 20,
+                        "description": "Maximum number of levels of each side, all levels if omitted or zero",
+                        "name": "depth",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successful response with tokens data",
+                        "description": "Order book of the pair",
                         "schema": {
-                            "$ref": "#/definitions/models.Tokens"
+                            "$ref": "#/definitions/models.OrderbookSnapshot"
                         }
                     },
                     "400": {
-                        "description": "Invalid input data",
+                        "description": "Pair is required or depth is invalid",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "404": {
+                        "description": "Exchange or order book not found",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
@@ -143,37 +201,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/auth/tokens": {
+        "/api/exchanges/{name}/pairs": {
             "get": {
-                "description": "Retrieve new access and refresh tokens for the authenticated user",
+                "description": "Get all pairs available on the exchange, which can be added as user pairs",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "users"
+                    "exchanges"
                 ],
-                "summary": "Get new tokens",
+                "summary": "List pairs of an exchange",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Refresh token",
-                        "name": "Authorization",
-                        "in": "header",
+                        "example": "binance_spot",
+                        "description": "Exchange name",
+                        "name": "name",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Case-insensitive substring of the pair name",
+                        "name": "search",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successful response with new tokens",
-                        "schema": {
-                            "$ref": "#/definitions/models.Tokens"
-                        }
-                    },
-                    "401": {
-                        "description": "Invalid refresh token",
+                        "description": "List of exchange pairs",
                         "schema": {
-                            "$ref": "#/definitions/models.Response"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ExchangePairs"
+                            }
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "404": {
+                        "description": "Exchange not found",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
@@ -181,50 +247,70 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/pair": {
-            "delete": {
-                "description": "Remove an existing pair for the authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/exchanges/{name}/search-volume": {
+            "get": {
+                "description": "Search the current order book of a pair subscribed by any user for the volumes the exact value would find, without storing them",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user-pairs"
+                    "exchanges"
                 ],
-                "summary": "Delete a user pair",
+                "summary": "Preview the volumes of a value",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Access token",
-                        "name": "Authorization",
-                        "in": "header",
+                        "example": "binance_spot",
+                        "description": "Exchange name",
+                        "name": "name",
+                        "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "The pair that should be deleted",
+                        "example": "BTC/USDT",
+                        "description": "Pair name",
                         "name": "pair",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "number",
+                        "example": 10,
+                        "description": "Exact value the volumes must reach",
+                        "name": "value",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "asks",
+                            "bids"
+                        ],
+                        "type": "string",
+                        "description": "Side of the order book, both sides if omitted",
+                        "name": "side",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successful response indicating the pair was deleted",
+                        "description": "Volumes the value would find",
                         "schema": {
-                            "$ref": "#/definitions/models.Response"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.FoundVolume"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid input data",
+                        "description": "Pair, value or side is invalid",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "404": {
+                        "description": "Exchange, pair or order book not found",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
@@ -232,52 +318,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/pair/add": {
-            "post": {
-                "description": "Create a new pair for the authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/exchanges/{name}/spread": {
+            "get": {
+                "description": "Get the best bid, best ask, spread and mid price of a pair subscribed by any user. For a one-sided order book the spread and the mid price are zero",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user-pairs"
+                    "exchanges"
                 ],
-                "summary": "Add a new user pair",
+                "summary": "Get the spread of a pair",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Access token",
-                        "name": "Authorization",
-                        "in": "header",
+                        "example": "binance_spot",
+                        "description": "Exchange name",
+                        "name": "name",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "description": "User pair data",
+                        "type": "string",
+                        "example": "BTC/USDT",
+                        "description": "Pair name",
                         "name": "pair",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UserPairs"
-                        }
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successful response indicating the pair was added",
+                        "description": "Price snapshot of the pair",
                         "schema": {
-                            "$ref": "#/definitions/models.Response"
+                            "$ref": "#/definitions/models.PriceSnapshot"
                         }
                     },
                     "400": {
-                        "description": "Invalid input data",
+                        "description": "Pair is required",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "404": {
+                        "description": "Exchange or order book not found",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
@@ -285,37 +368,59 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/pair/all-pairs": {
+        "/api/pairs/aggregate-volume": {
             "get": {
-                "description": "Get all user pairs associated with the authenticated user's account",
+                "description": "Search the current order books of a pair on every exchange listing it for the volumes the exact value would find, grouped by the exchange and sorted by the volume",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user-pairs"
+                    "pairs"
                 ],
-                "summary": "Retrieve all pairs for the authenticated user",
+                "summary": "Aggregate the volumes of a pair across exchanges",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Access token",
-                        "name": "Authorization",
-                        "in": "header",
+                        "example": "BTC/USDT",
+                        "description": "Pair name",
+                        "name": "pair",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "number",
+                        "example": 10,
+                        "description": "Exact value the volumes must reach",
+                        "name": "value",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "asks",
+                            "bids"
+                        ],
+                        "type": "string",
+                        "description": "Side of the order book, both sides if omitted",
+                        "name": "side",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of user pairs",
+                        "description": "Volumes the value would find grouped by the exchange",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.UserPairs"
-                            }
+                            "$ref": "#/definitions/models.AggregatedVolumes"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "400": {
+                        "description": "Pair, value or side is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "No exchange lists the pair",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
@@ -323,40 +428,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/pair/found-volumes": {
+        "/api/pairs/search": {
             "get": {
-                "description": "This endpoint retrieves a list of all found volumes associated with the authenticated user.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the pairs of all exchanges whose base or quote asset contains the query, grouped by the exchange",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user-pairs"
+                    "pairs"
                 ],
-                "summary": "Retrieve all found volumes for the authenticated user",
+                "summary": "Search pairs across exchanges",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Access token",
-                        "name": "Authorization",
-                        "in": "header",
+                        "example": "BTC",
+                        "description": "Case-insensitive substring of the base or quote asset",
+                        "name": "q",
+                        "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success",
+                        "description": "Found pairs grouped by the exchange",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.FoundVolume"
+                                "$ref": "#/definitions/models.ExchangePairsGroup"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Query is required",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
@@ -364,19 +467,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/pair/update-exact-value": {
-            "put": {
-                "description": "Update an existing pair for the authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/user": {
+            "delete": {
+                "description": "Delete the authenticated user's account",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user-pairs"
+                    "users"
                 ],
-                "summary": "Update the exact value of a user pair",
+                "summary": "Delete a user account",
                 "parameters": [
                     {
                         "type": "string",
@@ -384,26 +484,11 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "description": "User pair data",
-                        "name": "pair",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UserPairs"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successful response indicating the pair was updated",
-                        "schema": {
-                            "$ref": "#/definitions/models.Response"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid input data",
+                        "description": "Successful response",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
@@ -417,9 +502,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/update-password": {
+        "/api/user/auth/email": {
             "put": {
-                "description": "Update the password for the authenticated user.\nReturns the access token, the refresh token, and the time when the access token ceases to be valid. After the access token has ceased to be valid, you need to send a request along the path \"/api/user/auth/token\" to get a new pair of tokens.",
+                "description": "Request a change of the email of the authenticated user. The email is changed once it is confirmed by the link sent to the new email, the link is valid for 24 hours.",
                 "consumes": [
                     "application/json"
                 ],
@@ -429,7 +514,7 @@ const docTemplate = `{
                 "tags": [
                     "users"
                 ],
-                "summary": "Update user password",
+                "summary": "Change user email",
                 "parameters": [
                     {
                         "type": "string",
@@ -439,29 +524,1337 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Passwords data",
-                        "name": "passwords",
+                        "description": "New email",
+                        "name": "email",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.PasswordUpdate"
+                            "$ref": "#/definitions/models.EmailChange"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "New tokens data",
+                        "description": "Confirmation link sent",
                         "schema": {
-                            "$ref": "#/definitions/models.Tokens"
+                            "$ref": "#/definitions/models.Response"
                         }
                     },
                     "400": {
-                        "description": "Invalid password",
+                        "description": "Invalid email",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
                         }
                     },
-                    "500": {
+                    "409": {
+                        "description": "Email already registered",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/auth/email/confirm": {
+            "get": {
+                "description": "Confirm the new email of the user using the token sent by \"/api/user/auth/email\". The token is valid for 24 hours.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Confirm user email change",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email change token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email changed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid, expired or superseded email change token",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already registered",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/auth/forgot-password": {
+            "post": {
+                "description": "Send a password reset token to the user's email. The token is valid for 15 minutes and is invalidated once the password is changed or reset.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Request a password reset",
+                "parameters": [
+                    {
+                        "description": "User email",
+                        "name": "email",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PasswordForgot"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/auth/login": {
+            "post": {
+                "description": "Authenticate a user and issue tokens if successful",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Log in a user",
+                "parameters": [
+                    {
+                        "description": "User login data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserAuth"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "New tokens data",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/auth/logout": {
+            "post": {
+                "description": "Sign the authenticated user out of the current session. The access and refresh tokens of the session stop being accepted, the other sessions stay active.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Log out",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/auth/reset-password": {
+            "post": {
+                "description": "Set a new password using the token sent by \"/api/user/auth/forgot-password\".\nReturns the access token, the refresh token, and the time when the access token ceases to be valid.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Reset user password",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "passwords",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PasswordReset"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "New tokens data",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reset token or password",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/auth/signup": {
+            "post": {
+                "description": "Create a new user account with email and password. The account is active once the email is verified by the link sent to it.\nReturns the access token, the refresh token, and the time when the access token ceases to be valid. After the access token has ceased to be valid, you need to send a request along the path \"/api/user/auth/token\" to get a new pair of tokens.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Sign up a new user",
+                "parameters": [
+                    {
+                        "description": "User registration data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserAuth"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response with tokens data",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already registered",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/auth/tokens": {
+            "get": {
+                "description": "Retrieve new access and refresh tokens for the authenticated user.\nEvery refresh token can be used only once. Reusing a refresh token invalidates the session, so the user has to log in again.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get new tokens",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Refresh token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response with new tokens",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tokens"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid, revoked or reused refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/auth/verify": {
+            "get": {
+                "description": "Verify the email of the user using the token sent by \"/api/user/auth/signup\". The token is valid for 24 hours.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Verify user email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email verification token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email verified",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or expired verification token",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already verified",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/pair": {
+            "get": {
+                "description": "Get a pair of the authenticated user on an exchange with its exact value, side, tolerance and the other search settings",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-pairs"
+                ],
+                "summary": "Retrieve a single pair of the authenticated user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exchange of the pair, e.g. binance_spot",
+                        "name": "exchange",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The pair, e.g. BTC/USDT",
+                        "name": "pair",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The pair of the user",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserPairs"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid exchange name or pair format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "The user has no such pair",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove an existing pair for the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-pairs"
+                ],
+                "summary": "Delete a user pair",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The pair that should be deleted",
+                        "name": "pair",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response indicating the pair was deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pair format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/pair/add": {
+            "post": {
+                "description": "Create a new pair for the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-pairs"
+                ],
+                "summary": "Add a new user pair",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "User pair data",
+                        "name": "pair",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserPairs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response indicating the pair was added",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data or pair format, the pair isn't listed on the exchange or the maximum number of pairs per user reached",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "The pairs of the exchange haven't been loaded yet",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/pair/all": {
+            "delete": {
+                "description": "Remove all pairs of the authenticated user and their found volumes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-pairs"
+                ],
+                "summary": "Delete all user pairs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response indicating the pairs were deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/pair/all-pairs": {
+            "get": {
+                "description": "Get all user pairs associated with the authenticated user's account, optionally only the pairs of one exchange.\nWith the limit or the offset the pairs sorted by exchange and pair are returned by pages of at most 500 pairs\nas a models.UserPairsPage object with the number of all pairs instead of the list.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-pairs"
+                ],
+                "summary": "Retrieve all pairs for the authenticated user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return only the pairs of the exchange, e.g. binance_spot. Can't be combined with the pagination",
+                        "name": "exchange",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Maximum number of pairs of the page, 50 when only the offset is set",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Number of pairs skipped before the page",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of user pairs",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserPairs"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid exchange name or pagination",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/pair/bulk": {
+            "post": {
+                "description": "Create several pairs for the authenticated user at once. A pair which can't be added doesn't prevent the others from being added",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-pairs"
+                ],
+                "summary": "Add several user pairs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "User pairs data",
+                        "name": "pairs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserPairs"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Outcome of every pair",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserPairsBulkResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/pair/export": {
+            "get": {
+                "description": "Download all user pairs as a CSV file with a header row or as a JSON array",
+                "produces": [
+                    "text/csv",
+                    "application/json"
+                ],
+                "tags": [
+                    "user-pairs"
+                ],
+                "summary": "Export the pairs of the authenticated user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "Format of the file, csv when omitted",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User pairs file",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserPairs"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/pair/found-volumes": {
+            "get": {
+                "description": "This endpoint retrieves a filtered page of the found volumes associated with the authenticated user, the farthest from the price first by default. The found volumes with equal sort values are returned the most recently found first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-pairs"
+                ],
+                "summary": "Retrieve found volumes for the authenticated user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exchange name",
+                        "name": "exchange",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trading pair",
+                        "name": "pair",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asks",
+                            "bids"
+                        ],
+                        "type": "string",
+                        "description": "Order book side",
+                        "name": "side",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum volume",
+                        "name": "min_volume",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of found volumes, all when zero",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of found volumes to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "difference",
+                            "volume",
+                            "price"
+                        ],
+                        "type": "string",
+                        "description": "Sort key, difference when omitted",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, desc when omitted",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.FoundVolume"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/pair/found-volumes/ws": {
+            "get": {
+                "description": "This endpoint upgrades the connection to a websocket and pushes every new found volume of the authenticated user as a JSON message.",
+                "tags": [
+                    "user-pairs"
+                ],
+                "summary": "Stream found volumes of the authenticated user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols, then found volumes are sent as messages",
+                        "schema": {
+                            "$ref": "#/definitions/models.FoundVolume"
+                        }
+                    },
+                    "426": {
+                        "description": "Upgrade Required",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/pair/import": {
+            "post": {
+                "description": "Restore several pairs of the authenticated user from a CSV file with a header row or a JSON array. A pair which can't be imported doesn't prevent the others from being imported",
+                "consumes": [
+                    "text/csv",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-pairs"
+                ],
+                "summary": "Import user pairs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "User pairs file",
+                        "name": "pairs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserPairs"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Summary of the import",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserPairsImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/pair/replace": {
+            "put": {
+                "description": "Replace all pairs of the authenticated user with the given pairs in a single transaction. The pairs missing from the request are removed, the new ones added and the changed ones updated",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-pairs"
+                ],
+                "summary": "Replace all user pairs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "All pairs the user should have",
+                        "name": "pairs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserPairs"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Applied changes",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserPairsReplaceResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data or pair format, the pair isn't listed on the exchange or the maximum number of pairs per user reached",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "The pairs of the exchange haven't been loaded yet",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/pair/update-exact-value": {
+            "put": {
+                "description": "Update an existing pair for the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-pairs"
+                ],
+                "summary": "Update the exact value of a user pair",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "User pair data",
+                        "name": "pair",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserPairs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response indicating the pair was updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/sessions": {
+            "get": {
+                "description": "Get the devices the authenticated user is logged in on. Every login starts a new session, which stays active until it is revoked or the user logs out of it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-sessions"
+                ],
+                "summary": "List the sessions of the authenticated user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sessions of the user",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Session"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/sessions/{id}": {
+            "delete": {
+                "description": "Log the authenticated user out of a device. The access and refresh tokens of the session stop being accepted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-sessions"
+                ],
+                "summary": "Revoke a session of the authenticated user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1234,
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session revoked",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid session ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/settings": {
+            "get": {
+                "description": "Get the notification preferences of the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-settings"
+                ],
+                "summary": "Retrieve the settings of the authenticated user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Settings of the user",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserSettings"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the notification preferences of the authenticated user. Found volumes below min_volume_notify are not pushed to the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-settings"
+                ],
+                "summary": "Update the settings of the authenticated user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "User settings",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserSettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Settings updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/update-password": {
+            "put": {
+                "description": "Update the password for the authenticated user. All sessions of the user are revoked, so the other devices have to log in again.\nReturns the access token, the refresh token, and the time when the access token ceases to be valid. After the access token has ceased to be valid, you need to send a request along the path \"/api/user/auth/token\" to get a new pair of tokens.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update user password",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Passwords data",
+                        "name": "passwords",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PasswordUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "New tokens data",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid old password or weak new password",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
                         "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/models.Response"
@@ -469,23 +1862,283 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
+        },
+        "/health": {
+            "get": {
+                "description": "Check that the process is alive",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "Process is alive",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/ready": {
+            "get": {
+                "description": "Report per exchange whether the last fetch succeeded and how stale the data is",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "All exchanges are ready",
+                        "schema": {
+                            "$ref": "#/definitions/models.Readiness"
+                        }
+                    },
+                    "503": {
+                        "description": "At least one exchange is not ready",
+                        "schema": {
+                            "$ref": "#/definitions/models.Readiness"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AggregatedVolumes": {
+            "type": "object",
+            "properties": {
+                "exchanges": {
+                    "description": "Exchanges with found volumes sorted by their total volume descending",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExchangeVolumes"
+                    }
+                },
+                "pair": {
+                    "type": "string",
+                    "example": "BTC/USDT"
+                },
+                "total_volume": {
+                    "description": "Sum of the found volumes of all exchanges",
+                    "type": "number",
+                    "example": 57
+                }
+            }
+        },
+        "models.EmailChange": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "New email of the user, it replaces the current one once confirmed",
+                    "type": "string",
+                    "example": "new@example.com"
+                }
+            }
+        },
+        "models.ExchangePairs": {
+            "type": "object",
+            "properties": {
+                "exchange": {
+                    "type": "string",
+                    "example": "binance_spot"
+                },
+                "pair": {
+                    "type": "string",
+                    "example": "BTC/USDT"
+                }
+            }
+        },
+        "models.ExchangePairsGroup": {
+            "type": "object",
+            "properties": {
+                "exchange": {
+                    "type": "string",
+                    "example": "binance_spot"
+                },
+                "pairs": {
+                    "description": "Found pairs sorted by the pair name",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExchangePairs"
+                    }
+                }
+            }
+        },
+        "models.ExchangePairsRefresh": {
+            "type": "object",
+            "properties": {
+                "exchange": {
+                    "type": "string",
+                    "example": "binance_spot"
+                },
+                "listed_pairs": {
+                    "description": "Number of pairs listed on the exchange after the refresh",
+                    "type": "integer",
+                    "example": 1500
+                }
+            }
+        },
+        "models.ExchangeReadiness": {
+            "type": "object",
+            "properties": {
+                "circuit_breaker": {
+                    "description": "State of the circuit breaker pausing the requests",
+                    "type": "string",
+                    "enum": [
+                        "closed",
+                        "open",
+                        "half_open"
+                    ],
+                    "example": "closed"
+                },
+                "exchange": {
+                    "type": "string",
+                    "example": "binance_spot"
+                },
+                "last_error": {
+                    "description": "Error of the last fetch, empty if it succeeded",
+                    "type": "string",
+                    "example": "response has no body"
+                },
+                "last_successful_fetch": {
+                    "description": "Zero if no fetch of the exchange has succeeded yet",
+                    "type": "string"
+                },
+                "ready": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "staleness": {
+                    "description": "Time since the last successful fetch, empty if there was none",
+                    "type": "string",
+                    "example": "3.2s"
+                },
+                "subscribed_pairs": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "subscriptions_loaded": {
+                    "description": "Whether the subscribed pairs have been loaded from the database",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.ExchangeStats": {
+            "type": "object",
+            "properties": {
+                "blacklisted_pairs": {
+                    "description": "Pairs which are never polled even if subscribed",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "LUNA/USDT"
+                    ]
+                },
+                "circuit_breaker": {
+                    "description": "State of the circuit breaker pausing the requests",
+                    "type": "string",
+                    "enum": [
+                        "closed",
+                        "open",
+                        "half_open"
+                    ],
+                    "example": "closed"
+                },
+                "exchange": {
+                    "type": "string",
+                    "example": "binance_spot"
+                },
+                "last_error": {
+                    "description": "Error of the last fetch, empty if it succeeded",
+                    "type": "string",
+                    "example": "response has no body"
+                },
+                "last_successful_fetch": {
+                    "description": "Zero if no fetch of the exchange has succeeded yet",
+                    "type": "string"
+                },
+                "listed_pairs": {
+                    "description": "Number of pairs listed on the exchange, zero until they are loaded",
+                    "type": "integer",
+                    "example": 1500
+                },
+                "min_volume_floor": {
+                    "description": "Volume below which the levels are ignored, zero if none is ignored",
+                    "type": "number",
+                    "example": 0.5
+                },
+                "subscribed_pairs": {
+                    "description": "Number of pairs added by at least one user",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "models.ExchangeVolumes": {
+            "type": "object",
+            "properties": {
+                "exchange": {
+                    "type": "string",
+                    "example": "binance_spot"
+                },
+                "total_volume": {
+                    "description": "Sum of the found volumes of the exchange",
+                    "type": "number",
+                    "example": 42
+                },
+                "volumes": {
+                    "description": "Found volumes sorted by the volume descending",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FoundVolume"
+                    }
+                }
+            }
+        },
         "models.FoundVolume": {
             "type": "object",
             "properties": {
+                "band_high": {
+                    "description": "Highest price of the levels summed in the cumulative search mode, zero in the other modes",
+                    "type": "number"
+                },
+                "band_low": {
+                    "description": "Lowest price of the levels summed in the cumulative search mode, zero in the other modes",
+                    "type": "number"
+                },
                 "difference": {
-                    "description": "Difference between found volume and best ask or best bid and found volume in percent",
+                    "description": "Non-negative distance of the price from the best price of the side in percent of the best price",
+                    "type": "number"
+                },
+                "distance_from_last": {
+                    "description": "Distance between the found volume and the last trade price in percent, negative below the last price",
                     "type": "number"
                 },
                 "exchange": {
                     "type": "string"
                 },
+                "expired": {
+                    "description": "Set on the found volumes pushed to subscribers when they expire without being found again",
+                    "type": "boolean"
+                },
                 "index": {
                     "description": "Number of rows between found volume index and best ask or best bid and found volume index",
                     "type": "integer"
                 },
+                "last_price": {
+                    "description": "Price of the last trade of the pair, zero if it isn't known",
+                    "type": "number"
+                },
                 "pair": {
                     "type": "string"
                 },
@@ -503,6 +2156,70 @@ const docTemplate = `{
                 }
             }
         },
+        "models.OrderbookLevel": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "type": "number",
+                    "example": 50000.5
+                },
+                "volume": {
+                    "type": "number",
+                    "example": 1.25
+                }
+            }
+        },
+        "models.OrderbookSnapshot": {
+            "type": "object",
+            "properties": {
+                "asks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderbookLevel"
+                    }
+                },
+                "bids": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderbookLevel"
+                    }
+                },
+                "exchange": {
+                    "type": "string",
+                    "example": "binance_spot"
+                },
+                "pair": {
+                    "type": "string",
+                    "example": "BTC/USDT"
+                }
+            }
+        },
+        "models.PasswordForgot": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                }
+            }
+        },
+        "models.PasswordReset": {
+            "type": "object",
+            "properties": {
+                "new_password": {
+                    "type": "string",
+                    "example": "new_password"
+                },
+                "new_password_repeat": {
+                    "type": "string",
+                    "example": "new_password"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "reset_token"
+                }
+            }
+        },
         "models.PasswordUpdate": {
             "type": "object",
             "properties": {
@@ -520,14 +2237,117 @@ const docTemplate = `{
                 }
             }
         },
+        "models.PriceSnapshot": {
+            "type": "object",
+            "properties": {
+                "best_ask": {
+                    "type": "number",
+                    "example": 50000.5
+                },
+                "best_bid": {
+                    "type": "number",
+                    "example": 49999.5
+                },
+                "exchange": {
+                    "type": "string",
+                    "example": "binance_spot"
+                },
+                "mid": {
+                    "description": "Average of the best ask and the best bid",
+                    "type": "number",
+                    "example": 50000
+                },
+                "pair": {
+                    "type": "string",
+                    "example": "BTC/USDT"
+                },
+                "spread": {
+                    "description": "Difference between the best ask and the best bid",
+                    "type": "number",
+                    "example": 1
+                },
+                "spread_percent": {
+                    "description": "Spread in percent of the mid price",
+                    "type": "number",
+                    "example": 0.002
+                }
+            }
+        },
+        "models.Readiness": {
+            "type": "object",
+            "properties": {
+                "exchanges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExchangeReadiness"
+                    }
+                },
+                "ready": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
         "models.Response": {
             "type": "object",
             "properties": {
+                "code": {
+                    "description": "Machine-readable code of the error, empty on success",
+                    "type": "string",
+                    "example": "validation_failed"
+                },
                 "result": {
                     "type": "string"
                 }
             }
         },
+        "models.SearchMode": {
+            "type": "string",
+            "enum": [
+                "exact",
+                "relative",
+                "cumulative"
+            ],
+            "x-enum-comments": {
+                "SearchModeCumulative": "Finds bands of adjacent levels whose total volume is at least the exact value",
+                "SearchModeExact": "Finds levels whose volume is at least the exact value, or within the tolerance of it",
+                "SearchModeRelative": "Finds levels whose volume stands out from the surrounding levels"
+            },
+            "x-enum-varnames": [
+                "SearchModeExact",
+                "SearchModeRelative",
+                "SearchModeCumulative"
+            ]
+        },
+        "models.Session": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-08-01T12:00:00Z"
+                },
+                "current": {
+                    "description": "Whether the session made the request listing the sessions",
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "description": "Unique among the sessions of the user",
+                    "type": "integer",
+                    "example": 1234
+                },
+                "last_seen_at": {
+                    "description": "Time of the last authenticated request of the session",
+                    "type": "string",
+                    "example": "2024-08-02T12:00:00Z"
+                },
+                "user_agent": {
+                    "description": "User agent of the client which logged in",
+                    "type": "string",
+                    "example": "Mozilla/5.0"
+                }
+            }
+        },
         "models.Tokens": {
             "type": "object",
             "properties": {
@@ -535,6 +2355,7 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "expires_at": {
+                    "description": "Unix time in seconds the access token expires at",
                     "type": "integer"
                 },
                 "refresh": {
@@ -559,6 +2380,7 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "exact_value": {
+                    "description": "In the relative mode it is the minimum volume of a found level, in the cumulative mode the minimum total of a band",
                     "type": "number",
                     "example": 3
                 },
@@ -566,11 +2388,163 @@ const docTemplate = `{
                     "type": "string",
                     "example": "binance_spot"
                 },
+                "multiplier": {
+                    "description": "How many times a level must exceed the median of its neighbours in the relative mode",
+                    "type": "number",
+                    "example": 5
+                },
+                "pair": {
+                    "type": "string",
+                    "example": "BTC/USDT"
+                },
+                "price_range": {
+                    "description": "Width of the bands summed in the cumulative mode in percent of the price of their lowest level",
+                    "type": "number",
+                    "example": 0.5
+                },
+                "search_mode": {
+                    "description": "Empty value means the exact mode",
+                    "enum": [
+                        "exact",
+                        "relative",
+                        "cumulative"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SearchMode"
+                        }
+                    ],
+                    "example": "exact"
+                },
+                "side": {
+                    "description": "Side of the order book whose found volumes are recorded, empty value means both sides",
+                    "type": "string",
+                    "enum": [
+                        "asks",
+                        "bids",
+                        "both"
+                    ],
+                    "example": "both"
+                },
+                "tolerance": {
+                    "description": "Percent of the exact value a level volume may deviate by in the exact mode, zero finds every level of at least the exact value",
+                    "type": "number",
+                    "example": 5
+                },
+                "window": {
+                    "description": "Number of neighbour levels on each side compared in the relative mode",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "models.UserPairsBulkResult": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "code": {
+                    "description": "Machine-readable code of the reason",
+                    "type": "string",
+                    "example": "validation_failed"
+                },
+                "error": {
+                    "description": "Reason the pair wasn't added",
+                    "type": "string",
+                    "example": "invalid pair name format"
+                },
+                "exchange": {
+                    "type": "string",
+                    "example": "binance_spot"
+                },
                 "pair": {
                     "type": "string",
                     "example": "BTC/USDT"
                 }
             }
+        },
+        "models.UserPairsImportResult": {
+            "type": "object",
+            "properties": {
+                "imported": {
+                    "description": "Number of the added pairs",
+                    "type": "integer",
+                    "example": 2
+                },
+                "pairs": {
+                    "description": "Outcome of every pair in the order of the file",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserPairsBulkResult"
+                    }
+                },
+                "skipped": {
+                    "description": "Number of the pairs which weren't added",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.UserPairsReplaceResult": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "description": "Pairs which weren't stored",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserPairs"
+                    }
+                },
+                "removed": {
+                    "description": "Stored pairs missing from the replacing pairs",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserPairs"
+                    }
+                },
+                "updated": {
+                    "description": "Stored pairs whose search settings changed, with the new settings",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserPairs"
+                    }
+                }
+            }
+        },
+        "models.UserSettings": {
+            "type": "object",
+            "properties": {
+                "alert_cooldown_seconds": {
+                    "description": "Time in seconds after an alert during which the same pair of the same exchange doesn't alert the user again,\nso a flickering volume level doesn't flood the channels. Zero uses the cooldown configured for the service.",
+                    "type": "integer",
+                    "example": 300
+                },
+                "min_volume_notify": {
+                    "description": "Found volumes below it are not notified about",
+                    "type": "number",
+                    "example": 100
+                },
+                "notify_email": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "notify_telegram": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "webhook_secret": {
+                    "description": "Key of the HMAC-SHA256 signature of the posted body",
+                    "type": "string",
+                    "example": "secret"
+                },
+                "webhook_url": {
+                    "description": "The found volumes are posted to it, empty to disable the webhook",
+                    "type": "string",
+                    "example": "https://example.com/hooks/cvs"
+                }
+            }
         }
     }
 }`
@@ -14,9 +14,7 @@ import (
 	"os/signal"
 	"time"
 
-	"github.com/goccy/go-json" // Importing JSON encoding/decoding library
-
-	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/cast"
 )
 
 var (
@@ -37,46 +35,127 @@ func Run() {
 	db := postgresStorage.DB() // Get the underlying database connection
 
 	// Initialize repositories for data access
-	userPairsRepository := repository.NewUserPairsRepository(db) // User pairs repository for managing user pair data
-	userRepository := repository.NewUserRepository(db)           // User repository for managing user data
+	userPairsRepository := repository.NewUserPairsRepository(db)       // User pairs repository for managing user pair data
+	userRepository := repository.NewUserRepository(db)                 // User repository for managing user data
+	foundVolumesRepository := repository.NewFoundVolumesRepository(db) // Found volumes repository for persisting found volumes
+	userSettingsRepository := repository.NewUserSettingsRepository(db) // User settings repository for persisting notification preferences
+	sessionRepository := repository.NewSessionRepository(db)           // Session repository for the sessions of users
 
 	// Initialize services that contain business logic
-	userPairsService := service.NewUserPairsService(userPairsRepository, timeout)                                                                    // Service for user pairs operations
-	userService := service.NewUserService(userRepository, timeout)                                                                                   // Service for user operations
-	httpRequestService := service.NewHttpRequestService(timeout)                                                                                     // Service for making HTTP requests
-	jwtService := service.NewJwtService(cfg.JwtSecretKey, time.Duration(cfg.AccessTokenLifetimeHours), time.Duration(cfg.RefreshTokenLifetimeHours)) // Service for managing JWT tokens
-	foundVolumeService := service.NewFoundVolumesService()                                                                                           // Service with found volumes storage                                                                                        // Service for storing found volumes
-	userService.GetUsersIdFromDB(ctx)
+	userPairsService := service.NewUserPairsService(userPairsRepository, cfg.MaxPairsPerUser, timeout)              // Service for user pairs operations
+	userService := service.NewUserService(userRepository, timeout)                                                  // Service for user operations
+	sessionService := service.NewSessionService(sessionRepository, timeout)                                         // Service for the sessions of users
+	httpRequestService := service.NewHttpRequestService(cfg.HttpRequestTimeout, cfg.UserAgent)                      // Service for making HTTP requests
+	userSettingsService := service.NewUserSettingsService(userSettingsRepository, timeout)                          // Service for notification preferences
+	emailService := service.NewEmailService(cfg.Smtp, cfg.ResetPasswordUrl, cfg.VerifyEmailUrl, cfg.ChangeEmailUrl) // Service for sending emails to users
 
 	appLogger := logger.NewApiLogger(cfg)
 	appLogger.InitLogger()
-	allExchangesStorage := exchange.NewAllExchangesService(appLogger) // Initialize the AllExchanges service
 
-	// Initialize exchanges and their services
-	exchange.InitAllExchanges(
-		userService,
-		userPairsService,
-		httpRequestService,
-		foundVolumeService,
-		allExchangesStorage,
-		appLogger,
+	// Load the IDs of all users before the exchanges start, so the volumes of every user are searched from the first scan
+	if err := userService.GetUsersIdFromDB(ctx); err != nil {
+		appLogger.Fatal(err)
+	}
+
+	// Services for delivering the found volumes through the channels configured by users and storing them
+	notificationService := service.NewNotificationService(userSettingsService, appLogger, cfg.AlertCooldown, service.NewWebhookNotifier(cfg.Webhook))
+	foundVolumesWriter := repository.NewBatchedFoundVolumesRepository(foundVolumesRepository, cfg.FoundVolumesFlushInterval, appLogger)
+	defer foundVolumesWriter.Flush(ctx) // Write the found volumes buffered since the last flush before the database is closed
+	foundVolumeService := service.NewFoundVolumesService(foundVolumesWriter, userSettingsService, appLogger, timeout)
+
+	// Service for managing JWT tokens, the application can't issue valid tokens with invalid lifetimes
+	jwtService, err := service.NewJwtService(
+		cfg.JwtSecretKey,
+		cfg.JwtKeyId,
+		cfg.JwtPreviousKeys,
+		time.Duration(cfg.AccessTokenLifetimeHours),
+		time.Duration(cfg.RefreshTokenLifetimeHours),
+		cfg.JwtIssuer,
+		cfg.JwtAudience,
+		cfg.JwtClockSkewLeeway,
 	)
+	if err != nil {
+		appLogger.Fatal(err)
+	}
+
+	// Restore the found volumes of all users which were persisted before the restart
+	var usersIDs []int
+	for _, userID := range userService.GetUsersIdFromMemory().Keys() {
+		usersIDs = append(usersIDs, cast.ToInt(userID))
+	}
 
-	fiber := fiber.New(fiber.Config{
-		JSONEncoder: json.Marshal,   // Set custom JSON encoder for responses
-		JSONDecoder: json.Unmarshal, // Set custom JSON decoder for requests
-		Immutable:   true,           // Enable immutable routes (for performance)
+	if err := foundVolumeService.GetFoundVolumesFromDB(ctx, usersIDs); err != nil {
+		appLogger.Error(err)
+	}
+	allExchangesStorage := exchange.NewAllExchangesService(appLogger) // Initialize the AllExchanges service
+
+	exchangesCtx, stopExchanges := context.WithCancel(ctx) // Context that stops the exchanges work on shutdown
+	defer stopExchanges()
+
+	// Deliver the found volumes of all users through the channels they configured
+	notifications, unsubscribeNotifications := foundVolumeService.Subscribe(service.AllUsers)
+	defer unsubscribeNotifications()
+
+	go notificationService.Consume(exchangesCtx, notifications)
+
+	// Flush the writes of the found volumes buffered by the scans periodically
+	foundVolumesWriter.Start(exchangesCtx)
+
+	// Remove the found volumes which weren't found again for too long, e.g. after the wall disappeared
+	foundVolumeService.StartExpirySweeper(exchangesCtx, cfg.FoundVolumeTTL, cfg.FoundVolumeSweepInterval, appLogger)
+
+	// Remove the sessions whose refresh tokens have expired, so they aren't stored nor listed forever
+	sessionService.StartExpirySweeper(exchangesCtx, time.Duration(cfg.RefreshTokenLifetimeHours)*time.Hour, appLogger)
+
+	// Remove the order books which weren't updated for too long, e.g. after their pairs were unsubscribed
+	exchange.StartOrderbookEvictor(exchangesCtx, cfg.OrderbookTTL, appLogger)
+
+	// Refresh the pairs listed on the exchanges, so the listings changed after the start are picked up
+	exchange.StartPairsRefresher(exchangesCtx, allExchangesStorage, cfg.PairsRefreshInterval, appLogger)
+
+	if cfg.DryRun {
+		appLogger.Warn("Dry run mode, the found volumes are logged but neither stored nor notified about")
+	}
+
+	// Initialize exchanges and their services, the pairs of the users are subscribed before any exchange polls
+	exchange.InitAllExchanges(exchangesCtx, allExchangesStorage, exchange.Dependencies{
+		UserService:         userService,
+		UserPairsService:    userPairsService,
+		HttpRequestService:  httpRequestService,
+		FoundVolumesService: foundVolumeService,
+		Logger:              appLogger,
+		RequestIntervals:    cfg.RequestIntervals,
+		QuoteFilters:        cfg.QuoteFilters,
+		VolumeSearchWorkers: cfg.VolumeSearchWorkers,
+		OrderbookBatchSize:  cfg.OrderbookBatchSize,
+		MinVolumeFloor:      cfg.MinVolumeFloor,
+		RequestHeaders:      cfg.ExchangeHeaders(),
+		BaseUrls:            cfg.ExchangeBaseUrls,
+		CircuitBreaker:      cfg.CircuitBreaker,
+		PairBlacklists:      cfg.PairBlacklists,
+		ResponseBodyLimits:  cfg.ResponseBodyLimits,
+		DryRun:              cfg.DryRun,
+		EnabledExchanges:    cfg.EnabledExchanges,
 	})
-	middleware.Setup(fiber)
+
+	fiber := NewFiberApp(cfg)
+	middleware.Setup(fiber, cfg.RateLimit.GlobalMax, cfg.RateLimit.Expiration, appLogger, cfg.CompressionLevel)
 
 	// Setup routes for the Fiber application with provided services
 	route.Setup(
 		fiber,
 		userService,
+		sessionService,
 		userPairsService,
 		jwtService,
+		emailService,
 		foundVolumeService,
+		userSettingsService,
 		allExchangesStorage,
+		cfg.ReadinessStaleness,
+		cfg.AdminAllowedIPs,
+		middleware.AuthLimiter(cfg.RateLimit.AuthMax, cfg.RateLimit.Expiration),
+		cfg.PasswordPolicy,
 		appLogger,
 	)
 
@@ -87,6 +166,7 @@ func Run() {
 	go func() {
 		<-c // Wait for an interrupt signal
 		appLogger.Info("Gracefully shutting down...")
+		stopExchanges()  // Stop polling and streaming exchanges data
 		fiber.Shutdown() // Shutdown the Fiber server gracefully
 	}()
 
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"cvs/internal/config"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultReadTimeout  = 10 * time.Second // Time a client has to send the request, used when the config doesn't set it
+	defaultWriteTimeout = 10 * time.Second // Time a client has to read the response, used when the config doesn't set it
+	defaultIdleTimeout  = time.Minute      // Time a keep-alive connection waits for the next request, used when the config doesn't set it
+)
+
+// NewFiberApp creates the Fiber application serving the API.
+//
+// The read, write and idle timeouts are always set, so the slow or hung clients, e.g. the ones sending
+// the request byte by byte, can't hold the connections forever. The upgraded websocket connections
+// aren't limited by them, since the deadlines are cleared before the upgrade.
+//
+// Parameters:
+//   - cfg: The configuration with the server timeouts and the trusted proxies.
+//
+// Returns:
+//   - The configured Fiber application without any middleware or routes.
+func NewFiberApp(cfg *config.Config) *fiber.App {
+	return fiber.New(fiber.Config{
+		JSONEncoder: json.Marshal,   // Set custom JSON encoder for responses
+		JSONDecoder: json.Unmarshal, // Set custom JSON decoder for requests
+		Immutable:   true,           // Enable immutable routes (for performance)
+
+		// Drop the connections of the clients which don't send the request or read the response in time
+		ReadTimeout:  durationOrDefault(cfg.HttpServer.ReadTimeout, defaultReadTimeout),
+		WriteTimeout: durationOrDefault(cfg.HttpServer.WriteTimeout, defaultWriteTimeout),
+		IdleTimeout:  durationOrDefault(cfg.HttpServer.IdleTimeout, defaultIdleTimeout),
+
+		// Take the client IP address from the X-Forwarded-For header only behind the trusted proxies
+		ProxyHeader:             fiber.HeaderXForwardedFor,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.TrustedProxies,
+	})
+}
+
+// durationOrDefault returns the configured duration, or the default one if it isn't positive.
+func durationOrDefault(configured, fallback time.Duration) time.Duration {
+	if configured <= 0 {
+		return fallback
+	}
+
+	return configured
+}
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"net/http"
 	"os"
+	"time"
+
+	"cvs/internal/models"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -14,6 +18,79 @@ type PostgresConfig struct {
 	Host     string `yaml:"db_host"`     // Host where the database server is located
 	Port     string `yaml:"db_port"`     // Port on which the database server is listening
 	SslMode  string `yaml:"db_ssl_mode"` // SSL mode for database connection (e.g., "disable", "require")
+
+	// The connection pool is shared by the repositories and the scanner loops, non-positive values use the defaults.
+	MaxOpenConns    int           `yaml:"db_max_open_conns"`    // Maximum number of open connections, defaults to 25
+	MaxIdleConns    int           `yaml:"db_max_idle_conns"`    // Maximum number of idle connections, defaults to 25
+	ConnMaxLifetime time.Duration `yaml:"db_conn_max_lifetime"` // Maximum time a connection is reused, defaults to 5m
+}
+
+// SmtpConfig holds the settings of the SMTP server used to send emails to users.
+type SmtpConfig struct {
+	Host     string `yaml:"host"`     // Host of the SMTP server
+	Port     string `yaml:"port"`     // Port of the SMTP server
+	UserName string `yaml:"username"` // User name for the authentication, empty if it isn't required
+	Password string `yaml:"password"` // Password for the authentication
+	From     string `yaml:"from"`     // Sender address of the emails
+}
+
+// RateLimitConfig holds the settings of the rate limiters of the API.
+// The requests are counted per client IP address, non-positive values use the defaults.
+type RateLimitConfig struct {
+	GlobalMax  int           `yaml:"global_max"` // Maximum number of requests to the whole API, defaults to 1000
+	AuthMax    int           `yaml:"auth_max"`   // Maximum number of requests to the signup, login and forgot password routes, defaults to 10
+	Expiration time.Duration `yaml:"expiration"` // Time window the requests are counted in, defaults to 1m
+}
+
+// WebhookConfig holds the settings of the delivery of found volumes to the webhooks of users.
+// Non-positive values use the defaults.
+type WebhookConfig struct {
+	Attempts int           `yaml:"attempts"` // Maximum number of delivery attempts of a found volume, defaults to 3
+	Backoff  time.Duration `yaml:"backoff"`  // Delay before the first retry, doubled for every next one, defaults to 1s
+	Timeout  time.Duration `yaml:"timeout"`  // Timeout of a single delivery attempt, defaults to 5s
+
+	// Allow posting to the loopback, private and link-local addresses, e.g. to a receiver running next to the scanner
+	// in development. The webhook URLs saved by the users must point to public addresses regardless.
+	AllowPrivateAddresses bool `yaml:"allow_private_addresses"`
+}
+
+// HttpServerConfig holds the timeouts of the connections of the API clients, so the slow or hung clients
+// can't hold the connections forever. Non-positive values use the defaults.
+type HttpServerConfig struct {
+	ReadTimeout  time.Duration `yaml:"read_timeout"`  // Maximum time of reading the request, defaults to 10s
+	WriteTimeout time.Duration `yaml:"write_timeout"` // Maximum time of writing the response, defaults to 10s
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`  // Maximum time a keep-alive connection waits for the next request, defaults to 1m
+}
+
+// CircuitBreakerConfig holds the settings of the circuit breakers pausing the requests to the exchanges which keep failing,
+// e.g. while an exchange is down or has banned us. Non-positive values use the defaults.
+type CircuitBreakerConfig struct {
+	FailureThreshold int           `yaml:"failure_threshold"` // Number of consecutive failed fetches opening the breaker, defaults to 5
+	Cooldown         time.Duration `yaml:"cooldown"`          // Time the requests are paused for before the recovery is tested, defaults to 30s
+}
+
+// ResponseBodyLimitConfig holds the maximum sizes of the response bodies read from the exchange API per endpoint type,
+// so a misbehaving exchange returning a huge body can't exhaust the memory. A larger body is treated as a failed request.
+// Non-positive values use the defaults.
+type ResponseBodyLimitConfig struct {
+	Pairs     int64 `yaml:"pairs"`     // Maximum size of the pairs responses in bytes, defaults to 64 MiB
+	Orderbook int64 `yaml:"orderbook"` // Maximum size of the order book and ticker responses in bytes, defaults to 8 MiB
+}
+
+// PasswordPolicyConfig holds the rules the passwords of users must satisfy when they are set,
+// on signup, on update and on reset. A non-positive minimum length uses the default.
+type PasswordPolicyConfig struct {
+	MinLength     int  `yaml:"min_length"`     // Minimum number of characters, defaults to 8
+	RequireDigit  bool `yaml:"require_digit"`  // Whether a digit is required
+	RequireUpper  bool `yaml:"require_upper"`  // Whether an upper case letter is required
+	RequireSymbol bool `yaml:"require_symbol"` // Whether a punctuation or symbol character is required
+}
+
+// ApiKeyConfig holds the header carrying the API key of an exchange, some exchanges raise the rate limits of keyed requests.
+type ApiKeyConfig struct {
+	Header string `yaml:"header"`  // Name of the header, e.g. "X-MBX-APIKEY"
+	Key    string `yaml:"key"`     // API key, used when the environment variable isn't set
+	KeyEnv string `yaml:"key_env"` // Environment variable holding the API key, so it isn't kept in the config file
 }
 
 // Logger config
@@ -23,19 +100,184 @@ type Logger struct {
 	DisableStacktrace bool   `yaml:"disable_stacktrace"`
 	Encoding          string `yaml:"encoding"`
 	Level             string `yaml:"level"`
+
+	// The logs are written to the file, which is rotated by size, instead of stderr when the path is set.
+	// Non-positive rotation values use the defaults of lumberjack, zero max backups and max age keep all the old files.
+	FilePath   string `yaml:"file_path"`   // Path of the log file, empty to write to stderr
+	MaxSize    int    `yaml:"max_size"`    // Size of the log file in megabytes after which it is rotated, defaults to 100
+	MaxBackups int    `yaml:"max_backups"` // Maximum number of the rotated files kept
+	MaxAge     int    `yaml:"max_age"`     // Maximum number of days the rotated files are kept
+
+	// Sampling of the repeated logs, e.g. the same failed request of every pair logged by the scan loops every cycle.
+	Sampling LogSampling `yaml:"sampling"`
+}
+
+// LogSampling configures the sampling of the logs with the same level and message.
+// Every tick the first Initial of such logs are written and then every Thereafter-th of them,
+// so a spike of new errors is still written while their repetitions are dropped. Sampling is disabled when Initial is unset.
+type LogSampling struct {
+	Tick       time.Duration `yaml:"tick"`       // Interval the logs are counted in, defaults to 1s
+	Initial    int           `yaml:"initial"`    // Number of the same logs written every tick before the sampling starts
+	Thereafter int           `yaml:"thereafter"` // Every how many of the same logs one is written after the initial ones, zero drops all of them
 }
 
 // Config aggregates all configuration settings needed by the application.
 type Config struct {
-	Postgres                  PostgresConfig `yaml:"postgres"` // PostgreSQL configuration
-	Logger                    Logger         `yaml:"logger"`
-	JwtSecretKey              string         `yaml:"jwt_secret_key"` // Secret key used for signing JWTs
-	LogLevel                  string         `yaml:"log_level"`      // Logging level
-	ServerMode                string         `yaml:"server_mode"`
-	ServerPort                string         `yaml:"server_port"`                  // Port on which the server will run
-	AccessTokenLifetimeHours  int            `yaml:"access_token_lifetime_hours"`  // Lifetime of access tokens in hours
-	RefreshTokenLifetimeHours int            `yaml:"refresh_token_lifetime_hours"` // Lifetime of refresh tokens in hours
-	ContextTimeout            int            `yaml:"context_timeout"`              // Timeout duration for context operations in seconds
+	Postgres                  PostgresConfig       `yaml:"postgres"` // PostgreSQL configuration
+	Logger                    Logger               `yaml:"logger"`
+	Smtp                      SmtpConfig           `yaml:"smtp"`            // SMTP server configuration
+	RateLimit                 RateLimitConfig      `yaml:"rate_limit"`      // Rate limiters configuration
+	Webhook                   WebhookConfig        `yaml:"webhook"`         // Webhooks delivery configuration
+	HttpServer                HttpServerConfig     `yaml:"http_server"`     // API server timeouts configuration
+	CircuitBreaker            CircuitBreakerConfig `yaml:"circuit_breaker"` // Exchange circuit breakers configuration
+	PasswordPolicy            PasswordPolicyConfig `yaml:"password_policy"` // Password strength rules of users
+	JwtSecretKey              string               `yaml:"jwt_secret_key"`  // Secret key used for signing JWTs
+	LogLevel                  string               `yaml:"log_level"`       // Logging level
+	ServerMode                string               `yaml:"server_mode"`
+	ServerPort                string               `yaml:"server_port"`                  // Port on which the server will run
+	AccessTokenLifetimeHours  int                  `yaml:"access_token_lifetime_hours"`  // Lifetime of access tokens in hours, 1 when unset
+	RefreshTokenLifetimeHours int                  `yaml:"refresh_token_lifetime_hours"` // Lifetime of refresh tokens in hours, 720 when unset
+	JwtIssuer                 string               `yaml:"jwt_issuer"`                   // Issuer claim of the JWTs, not validated when empty
+	JwtAudience               string               `yaml:"jwt_audience"`                 // Audience claim of the JWTs, not validated when empty
+	JwtClockSkewLeeway        time.Duration        `yaml:"jwt_clock_skew_leeway"`        // Time the exp, nbf and iat claims of the JWTs may be off by, 30s when unset
+	ContextTimeout            int                  `yaml:"context_timeout"`              // Timeout duration for context operations in seconds
+	ResetPasswordUrl          string               `yaml:"reset_password_url"`           // Page the password reset token is sent to, the token is appended as a query parameter
+	VerifyEmailUrl            string               `yaml:"verify_email_url"`             // Endpoint the email verification token is sent to, the token is appended as a query parameter
+	ChangeEmailUrl            string               `yaml:"change_email_url"`             // Endpoint the email change token is sent to, the token is appended as a query parameter
+
+	// Key id of the JWT secret key set as the kid header of the issued tokens, not set when empty.
+	// Rotating the secret key, the key id is changed and the former key is moved to the previous keys.
+	JwtKeyId string `yaml:"jwt_key_id"`
+
+	// Secret keys the JWTs signed before the rotation are verified with keyed by key id, so they stay valid
+	// until they expire. The tokens without the kid header are verified with the key of the empty id.
+	JwtPreviousKeys map[string]string `yaml:"jwt_previous_keys"`
+
+	// Quote assets whose pairs are loaded keyed by exchange name (e.g. "binance_spot": {whitelist: [USDT]}).
+	// Exchanges missing from the map load the pairs of all quote assets.
+	QuoteFilters map[string]models.QuoteFilter `yaml:"quote_filters"`
+
+	// Timeout of a single request to the exchange API, including reading the response.
+	// Defaults to 10s when unset.
+	HttpRequestTimeout time.Duration `yaml:"http_request_timeout"`
+
+	// Value of the User-Agent header of the requests to the exchange API. Defaults to "Crypto-Volume-Scanner" when unset.
+	UserAgent string `yaml:"user_agent"`
+
+	// API keys sent with the requests to the exchange API keyed by exchange name (e.g. "binance_spot").
+	// Exchanges missing from the map are requested anonymously.
+	ExchangeApiKeys map[string]ApiKeyConfig `yaml:"exchange_api_keys"`
+
+	// Names of the exchanges started by the service, e.g. "bybit_spot", or of all markets of an exchange, e.g. "binance".
+	// All exchanges are started when unset, an unknown name stops the service at startup.
+	EnabledExchanges []string `yaml:"enabled_exchanges"`
+
+	// Time between requests to the exchange API keyed by exchange name (e.g. "binance_spot": 1s).
+	// Exchanges missing from the map use their default interval.
+	RequestIntervals map[string]time.Duration `yaml:"request_intervals"`
+
+	// Maximum time since the last successful fetch of an exchange with subscribed pairs after which
+	// the readiness endpoint reports the service as not ready. Defaults to 1m when unset.
+	ReadinessStaleness time.Duration `yaml:"readiness_staleness"`
+
+	// Maximum number of users whose volumes are searched concurrently by each exchange.
+	// Defaults to 16 when unset.
+	VolumeSearchWorkers int `yaml:"volume_search_workers"`
+
+	// Number of pairs whose order books are fetched by one request on the exchanges supporting batches.
+	// Batches carry only the best levels of the order books, so they are disabled when unset.
+	OrderbookBatchSize int `yaml:"orderbook_batch_size"`
+
+	// Volume below which the levels of the order books are ignored regardless of the user settings,
+	// so the dust levels of small books don't flood the found volumes. No level is ignored when unset.
+	MinVolumeFloor float64 `yaml:"min_volume_floor"`
+
+	// Time a found volume is kept without being found again, e.g. after the wall disappeared.
+	// Defaults to 10m when unset.
+	FoundVolumeTTL time.Duration `yaml:"found_volume_ttl"`
+
+	// Time between the sweeps removing the expired found volumes. Defaults to 1m when unset.
+	FoundVolumeSweepInterval time.Duration `yaml:"found_volume_sweep_interval"`
+
+	// Time an order book is kept without being updated, e.g. after a request for it kept failing.
+	// The books are still removed when nobody watches their pairs anymore. Stale books are kept when unset.
+	OrderbookTTL time.Duration `yaml:"orderbook_ttl"`
+
+	// Time between the refreshes of the pairs listed on the exchanges, so the new listings are scanned
+	// and the delisted pairs are dropped. The pairs are loaded on the start only when unset.
+	PairsRefreshInterval time.Duration `yaml:"pairs_refresh_interval"`
+
+	// Time the writes of the found volumes are buffered for before they are flushed to the database at once,
+	// so a scan doesn't make a query per found volume. Every write goes to the database directly when unset.
+	FoundVolumesFlushInterval time.Duration `yaml:"found_volumes_flush_interval"`
+
+	// Time after an alert during which the same pair of the same exchange doesn't alert the user again,
+	// unless the user sets another cooldown in the settings. Every found volume is alerted about when unset.
+	AlertCooldown time.Duration `yaml:"alert_cooldown"`
+
+	// Level of the compression of the responses: -1 disables it, 0 is the default level, 1 the best speed
+	// and 2 the best compression. Defaults to 0 when unset.
+	CompressionLevel int `yaml:"compression_level"`
+
+	// IP addresses and CIDR ranges of the proxies whose X-Forwarded-For header is trusted to carry the client IP address.
+	// The header is ignored when unset, so the clients can't spoof their address.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+
+	// IP addresses and CIDR ranges allowed to reach the admin routes in addition to the admin role.
+	// Every client IP address is allowed when unset.
+	AdminAllowedIPs []string `yaml:"admin_allowed_ips"`
+
+	// Base URLs replacing the production hosts of the exchange API keyed by exchange name,
+	// e.g. "binance_spot": "https://testnet.binance.vision" or the URL of a caching proxy.
+	// The websocket of an exchange with a base URL is disabled, so its order books are polled.
+	// The exchanges missing from the map request the production hosts.
+	ExchangeBaseUrls map[string]string `yaml:"exchange_base_urls"`
+
+	// Pairs whose order books and tickers are never polled keyed by exchange name (e.g. "binance_spot": [BTC/USDT]),
+	// so the symbols which keep failing, e.g. delisted but still listed ones, don't flood the logs. The pairs are
+	// skipped even if users subscribe to them. The exchanges missing from the map poll all subscribed pairs.
+	PairBlacklists map[string][]string `yaml:"pair_blacklists"`
+
+	// Maximum sizes of the response bodies read from the exchange API per endpoint type.
+	ResponseBodyLimits ResponseBodyLimitConfig `yaml:"response_body_limits"`
+
+	// Whether the scanner only logs the found volumes instead of storing them and notifying users,
+	// so the detection can be debugged against the live exchanges. Disabled when unset.
+	DryRun bool `yaml:"dry_run"`
+
+	// Maximum number of pairs a single user can subscribe to, every pair multiplies the scanning load.
+	// Defaults to 100 when unset.
+	MaxPairsPerUser int `yaml:"max_pairs_per_user"`
+}
+
+// ExchangeHeaders returns the headers sent with the requests to the exchange API keyed by exchange name.
+//
+// The API key of an exchange is read from its environment variable, falling back to the key of the config file.
+// The exchanges without a header name or a key are left out, so they are requested anonymously.
+//
+// Returns:
+//   - A map of the headers keyed by exchange name.
+func (cfg *Config) ExchangeHeaders() map[string]http.Header {
+	headers := make(map[string]http.Header, len(cfg.ExchangeApiKeys))
+
+	for exchangeName, apiKey := range cfg.ExchangeApiKeys {
+		key := apiKey.Key
+		if apiKey.KeyEnv != "" {
+			if envKey := os.Getenv(apiKey.KeyEnv); envKey != "" {
+				key = envKey // The environment takes precedence over the config file
+			}
+		}
+
+		if apiKey.Header == "" || key == "" {
+			continue
+		}
+
+		header := http.Header{}
+		header.Set(apiKey.Header, key)
+		headers[exchangeName] = header
+	}
+
+	return headers
 }
 
 // NewConfig creates a new configuration instance by loading settings from a specified path.
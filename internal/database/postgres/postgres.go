@@ -5,11 +5,18 @@ import (
 	"cvs/internal/config"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 )
 
+const (
+	defaultMaxOpenConns    = 25              // Default maximum number of open connections
+	defaultMaxIdleConns    = 25              // Default maximum number of idle connections
+	defaultConnMaxLifetime = 5 * time.Minute // Default maximum time a connection is reused
+)
+
 type Postgres interface {
 	Migration()
 	DB() *sqlx.DB
@@ -30,6 +37,8 @@ func NewPostgresDB(cfg config.PostgresConfig) Postgres {
 		panic(err)
 	}
 
+	ConfigurePool(db, cfg)
+
 	err = db.Ping()
 	if err != nil {
 		panic(err)
@@ -42,20 +51,71 @@ func NewPostgresDB(cfg config.PostgresConfig) Postgres {
 	}
 }
 
+// ConfigurePool applies the connection pool settings of the config to the database.
+// The settings which aren't positive use the defaults, so the pool is bounded under the load of the scanner loops.
+//
+// Parameters:
+//   - db: The database whose connection pool is configured.
+//   - cfg: The config with the connection pool settings.
+func ConfigurePool(db *sqlx.DB, cfg config.PostgresConfig) {
+	maxOpenConns, maxIdleConns, connMaxLifetime := cfg.MaxOpenConns, cfg.MaxIdleConns, cfg.ConnMaxLifetime
+	if maxOpenConns <= 0 {
+		maxOpenConns = defaultMaxOpenConns
+	}
+	if maxIdleConns <= 0 {
+		maxIdleConns = defaultMaxIdleConns
+	}
+	if connMaxLifetime <= 0 {
+		connMaxLifetime = defaultConnMaxLifetime
+	}
+
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxIdleConns) // Lowered to the maximum number of open connections if it is larger
+	db.SetConnMaxLifetime(connMaxLifetime)
+}
+
 func (s *postgres) Migration() {
 	_, err := s.db.ExecContext(context.Background(), `
 		CREATE TABLE IF NOT EXISTS users (
 			id serial PRIMARY KEY,
-			session_id integer NOT NULL CHECK (session_id > 0),  --the session ID is needed to link the access token and the refresh token
+			password_version integer NOT NULL CHECK (password_version > 0),  --changed with the password, so the password reset tokens issued before it are rejected
 			email varchar(255) NOT NULL CHECK (email != ''),
 			password bytea NOT NULL,
-			refresh_token bytea NOT NULL,
 			created_at timestamp DEFAULT now(),
 			updated_at timestamp DEFAULT now(),
 			UNIQUE (email),
 			CONSTRAINT password_not_empty CHECK (octet_length(password) > 0)
 		);
 
+		DO $$
+		BEGIN
+			IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'session_id') THEN
+				ALTER TABLE users RENAME COLUMN session_id TO password_version;  --the column versions the password since the sessions are stored per row of user_sessions
+			END IF;
+		END $$;
+
+		ALTER TABLE users
+			ADD COLUMN IF NOT EXISTS verified boolean NOT NULL DEFAULT true;  --the accounts created before the email verification stay active
+		ALTER TABLE users
+			ALTER COLUMN verified SET DEFAULT false;
+		ALTER TABLE users
+			ADD COLUMN IF NOT EXISTS pending_email varchar(255) NOT NULL DEFAULT '';  --empty if no email change was requested
+		ALTER TABLE users
+			ADD COLUMN IF NOT EXISTS role varchar(16) NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'admin'));  --admins are promoted by the operator in the database
+
+		CREATE TABLE IF NOT EXISTS user_sessions (
+			id integer NOT NULL CHECK (id > 0),  --the session ID links the access token and the refresh token to the session
+			user_id integer NOT NULL CHECK (user_id > 0) REFERENCES users(id) ON DELETE CASCADE,
+			refresh_token bytea NOT NULL,
+			user_agent varchar(512) NOT NULL DEFAULT '',
+			created_at timestamp NOT NULL DEFAULT now(),
+			last_seen_at timestamp NOT NULL DEFAULT now(),
+			PRIMARY KEY (user_id, id)
+		);
+
+		ALTER TABLE users
+			DROP COLUMN IF EXISTS refresh_token;  --the refresh tokens are stored per session, the users log in again after the upgrade
+
 		CREATE TABLE IF NOT EXISTS user_pairs (
 			user_id integer NOT NULL CHECK (user_id > 0) REFERENCES users(id) ON DELETE CASCADE,
 			exchange varchar(255) NOT NULL CHECK (exchange != ''),
@@ -64,7 +124,48 @@ func (s *postgres) Migration() {
 			UNIQUE (user_id, exchange, pair)  
 		);
 
-		CREATE INDEX idx_user_pairs_user_id ON user_pairs(user_id);
+		ALTER TABLE user_pairs
+			ADD COLUMN IF NOT EXISTS search_mode varchar(16) NOT NULL DEFAULT 'exact' CHECK (search_mode IN ('exact', 'relative')),
+			ADD COLUMN IF NOT EXISTS multiplier double precision NOT NULL DEFAULT 0,  --used by the relative search mode only
+			ADD COLUMN IF NOT EXISTS window_size integer NOT NULL DEFAULT 0,  --used by the relative search mode only
+			ADD COLUMN IF NOT EXISTS tolerance double precision NOT NULL DEFAULT 0 CHECK (tolerance >= 0 AND tolerance <= 100),  --used by the exact search mode only
+			ADD COLUMN IF NOT EXISTS side varchar(4) NOT NULL DEFAULT 'both' CHECK (side IN ('asks', 'bids', 'both')),  --side of the order book whose found volumes are recorded
+			ADD COLUMN IF NOT EXISTS price_range double precision NOT NULL DEFAULT 0 CHECK (price_range >= 0 AND price_range <= 100);  --used by the cumulative search mode only
+		ALTER TABLE user_pairs
+			DROP CONSTRAINT IF EXISTS user_pairs_search_mode_check,
+			ADD CONSTRAINT user_pairs_search_mode_check CHECK (search_mode IN ('exact', 'relative', 'cumulative'));
+
+		CREATE INDEX IF NOT EXISTS idx_user_pairs_user_id ON user_pairs(user_id);
+
+		CREATE TABLE IF NOT EXISTS found_volumes (
+			user_id integer NOT NULL CHECK (user_id > 0) REFERENCES users(id) ON DELETE CASCADE,
+			exchange varchar(255) NOT NULL CHECK (exchange != ''),
+			pair varchar(255) NOT NULL CHECK (pair != ''),
+			side varchar(4) NOT NULL CHECK (side IN ('asks', 'bids')),
+			price double precision NOT NULL,
+			volume_index integer NOT NULL DEFAULT 0,
+			difference double precision NOT NULL DEFAULT 0,
+			volume double precision NOT NULL,
+			volume_time_found timestamp NOT NULL DEFAULT now(),
+			CONSTRAINT found_volumes_unique_key UNIQUE (user_id, pair, exchange, side)
+		);
+		ALTER TABLE found_volumes
+			ADD COLUMN IF NOT EXISTS last_price double precision NOT NULL DEFAULT 0,  --zero if the last trade price wasn't known
+			ADD COLUMN IF NOT EXISTS distance_from_last double precision NOT NULL DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS band_low double precision NOT NULL DEFAULT 0,  --prices of the levels summed in the cumulative search mode, zero in the other modes
+			ADD COLUMN IF NOT EXISTS band_high double precision NOT NULL DEFAULT 0;
+
+		CREATE TABLE IF NOT EXISTS user_settings (
+			user_id integer PRIMARY KEY CHECK (user_id > 0) REFERENCES users(id) ON DELETE CASCADE,
+			notify_telegram boolean NOT NULL DEFAULT false,
+			notify_email boolean NOT NULL DEFAULT false,
+			min_volume_notify double precision NOT NULL DEFAULT 0 CHECK (min_volume_notify >= 0)
+		);
+
+		ALTER TABLE user_settings
+			ADD COLUMN IF NOT EXISTS webhook_url text NOT NULL DEFAULT '',  --empty disables the webhook
+			ADD COLUMN IF NOT EXISTS webhook_secret text NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS alert_cooldown_seconds integer NOT NULL DEFAULT 0 CHECK (alert_cooldown_seconds >= 0);  --zero uses the cooldown of the service
 	`)
 	if err != nil {
 		fmt.Println("Migration error! ", err)
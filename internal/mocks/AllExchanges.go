@@ -35,10 +35,14 @@ func (_m *AllExchanges) All() []exchange.Exchange {
 }
 
 // Get provides a mock function with given fields: exchangeName
-func (_m *AllExchanges) Get(exchangeName string) exchange.Exchange {
+func (_m *AllExchanges) Get(exchangeName string) (exchange.Exchange, bool) {
 	ret := _m.Called(exchangeName)
 
 	var r0 exchange.Exchange
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(string) (exchange.Exchange, bool)); ok {
+		return rf(exchangeName)
+	}
 	if rf, ok := ret.Get(0).(func(string) exchange.Exchange); ok {
 		r0 = rf(exchangeName)
 	} else {
@@ -47,7 +51,13 @@ func (_m *AllExchanges) Get(exchangeName string) exchange.Exchange {
 		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(exchangeName)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
 }
 
 type mockConstructorTestingTNewAllExchanges interface {
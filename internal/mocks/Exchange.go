@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	exchange "cvs/internal/service/exchange"
 
 	mock "github.com/stretchr/testify/mock"
@@ -20,6 +22,76 @@ func (_m *Exchange) AddPairToSubscribedPairs(pair string) {
 	_m.Called(pair)
 }
 
+// AllPairs provides a mock function with given fields:
+func (_m *Exchange) AllPairs() []models.ExchangePairs {
+	ret := _m.Called()
+
+	var r0 []models.ExchangePairs
+	if rf, ok := ret.Get(0).(func() []models.ExchangePairs); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.ExchangePairs)
+		}
+	}
+
+	return r0
+}
+
+// AllPairsCount provides a mock function with given fields:
+func (_m *Exchange) AllPairsCount() int {
+	ret := _m.Called()
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
+// BestPrices provides a mock function with given fields: pair
+func (_m *Exchange) BestPrices(pair string) (models.PriceSnapshot, bool) {
+	ret := _m.Called(pair)
+
+	var r0 models.PriceSnapshot
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(string) (models.PriceSnapshot, bool)); ok {
+		return rf(pair)
+	}
+	if rf, ok := ret.Get(0).(func(string) models.PriceSnapshot); ok {
+		r0 = rf(pair)
+	} else {
+		r0 = ret.Get(0).(models.PriceSnapshot)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(pair)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// BlacklistedPairs provides a mock function with given fields:
+func (_m *Exchange) BlacklistedPairs() []string {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
 // ClearSubscribedPairsStorage provides a mock function with given fields:
 func (_m *Exchange) ClearSubscribedPairsStorage() {
 	_m.Called()
@@ -44,29 +116,146 @@ func (_m *Exchange) ExchangeName() string {
 	return r0
 }
 
-// FillPairsSubscribedStorage provides a mock function with given fields:
-func (_m *Exchange) FillPairsSubscribedStorage() {
-	_m.Called()
+// FillPairsSubscribedStorage provides a mock function with given fields: ctx
+func (_m *Exchange) FillPairsSubscribedStorage(ctx context.Context) {
+	_m.Called(ctx)
 }
 
-// FindVolumeInOrderbookPeriodically provides a mock function with given fields:
-func (_m *Exchange) FindVolumeInOrderbookPeriodically() {
-	_m.Called()
+// FindVolumeInOrderbookPeriodically provides a mock function with given fields: ctx
+func (_m *Exchange) FindVolumeInOrderbookPeriodically(ctx context.Context) {
+	_m.Called(ctx)
 }
 
-// GetAllPairsOfExchange provides a mock function with given fields:
-func (_m *Exchange) GetAllPairsOfExchange() {
-	_m.Called()
+// GetAllPairsOfExchange provides a mock function with given fields: ctx
+func (_m *Exchange) GetAllPairsOfExchange(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
 }
 
-// GetOrderbookDataFromExchange provides a mock function with given fields: pair
-func (_m *Exchange) GetOrderbookDataFromExchange(pair string) {
-	_m.Called(pair)
+// GetOrderbookDataFromExchange provides a mock function with given fields: ctx, pair
+func (_m *Exchange) GetOrderbookDataFromExchange(ctx context.Context, pair string) {
+	_m.Called(ctx, pair)
 }
 
-// GetOrderbookPeriodically provides a mock function with given fields:
-func (_m *Exchange) GetOrderbookPeriodically() {
-	_m.Called()
+// GetOrderbookPeriodically provides a mock function with given fields: ctx
+func (_m *Exchange) GetOrderbookPeriodically(ctx context.Context) {
+	_m.Called(ctx)
+}
+
+// HasPair provides a mock function with given fields: pair
+func (_m *Exchange) HasPair(pair string) bool {
+	ret := _m.Called(pair)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(pair)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// MinVolumeFloor provides a mock function with given fields:
+func (_m *Exchange) MinVolumeFloor() float64 {
+	ret := _m.Called()
+
+	var r0 float64
+	if rf, ok := ret.Get(0).(func() float64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	return r0
+}
+
+// OrderbookSnapshot provides a mock function with given fields: pair, depth
+func (_m *Exchange) OrderbookSnapshot(pair string, depth int) (models.OrderbookSnapshot, bool) {
+	ret := _m.Called(pair, depth)
+
+	var r0 models.OrderbookSnapshot
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(string, int) (models.OrderbookSnapshot, bool)); ok {
+		return rf(pair, depth)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) models.OrderbookSnapshot); ok {
+		r0 = rf(pair, depth)
+	} else {
+		r0 = ret.Get(0).(models.OrderbookSnapshot)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) bool); ok {
+		r1 = rf(pair, depth)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// PairsLoaded provides a mock function with given fields:
+func (_m *Exchange) PairsLoaded() bool {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// PreviewVolumes provides a mock function with given fields: pair, exactValue
+func (_m *Exchange) PreviewVolumes(pair string, exactValue float64) ([]models.FoundVolume, bool) {
+	ret := _m.Called(pair, exactValue)
+
+	var r0 []models.FoundVolume
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(string, float64) ([]models.FoundVolume, bool)); ok {
+		return rf(pair, exactValue)
+	}
+	if rf, ok := ret.Get(0).(func(string, float64) []models.FoundVolume); ok {
+		r0 = rf(pair, exactValue)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.FoundVolume)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, float64) bool); ok {
+		r1 = rf(pair, exactValue)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// SearchPairs provides a mock function with given fields: substr
+func (_m *Exchange) SearchPairs(substr string) []models.ExchangePairs {
+	ret := _m.Called(substr)
+
+	var r0 []models.ExchangePairs
+	if rf, ok := ret.Get(0).(func(string) []models.ExchangePairs); ok {
+		r0 = rf(substr)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.ExchangePairs)
+		}
+	}
+
+	return r0
 }
 
 // SetEchangePairsToStorage provides a mock function with given fields: exchangePairsSlice
@@ -79,9 +268,42 @@ func (_m *Exchange) SetExchangeIntoAllExchangesStorage(_a0 exchange.Exchange) {
 	_m.Called(_a0)
 }
 
-// StartWork provides a mock function with given fields:
-func (_m *Exchange) StartWork() {
-	_m.Called()
+// StartOrderbookWebsocket provides a mock function with given fields: ctx
+func (_m *Exchange) StartOrderbookWebsocket(ctx context.Context) {
+	_m.Called(ctx)
+}
+
+// StartWork provides a mock function with given fields: ctx
+func (_m *Exchange) StartWork(ctx context.Context) {
+	_m.Called(ctx)
+}
+
+// Status provides a mock function with given fields:
+func (_m *Exchange) Status() models.ExchangeStatus {
+	ret := _m.Called()
+
+	var r0 models.ExchangeStatus
+	if rf, ok := ret.Get(0).(func() models.ExchangeStatus); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(models.ExchangeStatus)
+	}
+
+	return r0
+}
+
+// SubscribedPairsCount provides a mock function with given fields:
+func (_m *Exchange) SubscribedPairsCount() int {
+	ret := _m.Called()
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
 }
 
 type mockConstructorTestingTNewExchange interface {
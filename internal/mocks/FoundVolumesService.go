@@ -3,9 +3,13 @@
 package mocks
 
 import (
+	context "context"
 	models "cvs/internal/models"
+	logger "cvs/internal/service/logger"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // FoundVolumesService is an autogenerated mock type for the FoundVolumesService type
@@ -13,40 +17,115 @@ type FoundVolumesService struct {
 	mock.Mock
 }
 
-// DeleteFoundVolume provides a mock function with given fields: userPairData
-func (_m *FoundVolumesService) DeleteFoundVolume(userPairData models.UserPairs) {
-	_m.Called(userPairData)
+// DeleteFoundVolume provides a mock function with given fields: ctx, userPairData
+func (_m *FoundVolumesService) DeleteFoundVolume(ctx context.Context, userPairData models.UserPairs) error {
+	ret := _m.Called(ctx, userPairData)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UserPairs) error); ok {
+		r0 = rf(ctx, userPairData)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
 }
 
-// GetAllFoundVolume provides a mock function with given fields: userID
-func (_m *FoundVolumesService) GetAllFoundVolume(userID int) ([]models.FoundVolume, error) {
-	ret := _m.Called(userID)
+// GetAllFoundVolume provides a mock function with given fields: userID, filter
+func (_m *FoundVolumesService) GetAllFoundVolume(userID int, filter models.FoundVolumesFilter) ([]models.FoundVolume, error) {
+	ret := _m.Called(userID, filter)
 
 	var r0 []models.FoundVolume
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int) ([]models.FoundVolume, error)); ok {
+	if rf, ok := ret.Get(0).(func(int, models.FoundVolumesFilter) ([]models.FoundVolume, error)); ok {
+		return rf(userID, filter)
+	}
+	if rf, ok := ret.Get(0).(func(int, models.FoundVolumesFilter) []models.FoundVolume); ok {
+		r0 = rf(userID, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.FoundVolume)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int, models.FoundVolumesFilter) error); ok {
+		r1 = rf(userID, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFoundVolumesFromDB provides a mock function with given fields: ctx, userIDs
+func (_m *FoundVolumesService) GetFoundVolumesFromDB(ctx context.Context, userIDs []int) error {
+	ret := _m.Called(ctx, userIDs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int) error); ok {
+		r0 = rf(ctx, userIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StartExpirySweeper provides a mock function with given fields: ctx, ttl, interval, _a3
+func (_m *FoundVolumesService) StartExpirySweeper(ctx context.Context, ttl time.Duration, interval time.Duration, _a3 logger.Logger) {
+	_m.Called(ctx, ttl, interval, _a3)
+}
+
+// Subscribe provides a mock function with given fields: userID
+func (_m *FoundVolumesService) Subscribe(userID int) (<-chan models.FoundVolume, func()) {
+	ret := _m.Called(userID)
+
+	var r0 <-chan models.FoundVolume
+	var r1 func()
+	if rf, ok := ret.Get(0).(func(int) (<-chan models.FoundVolume, func())); ok {
 		return rf(userID)
 	}
-	if rf, ok := ret.Get(0).(func(int) []models.FoundVolume); ok {
+	if rf, ok := ret.Get(0).(func(int) <-chan models.FoundVolume); ok {
 		r0 = rf(userID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.FoundVolume)
+			r0 = ret.Get(0).(<-chan models.FoundVolume)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int) error); ok {
+	if rf, ok := ret.Get(1).(func(int) func()); ok {
 		r1 = rf(userID)
 	} else {
-		r1 = ret.Error(1)
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(func())
+		}
 	}
 
 	return r0, r1
 }
 
-// UpsertFoundVolume provides a mock function with given fields: userData, foundVolume
-func (_m *FoundVolumesService) UpsertFoundVolume(userData models.UserPairs, foundVolume models.FoundVolume) {
-	_m.Called(userData, foundVolume)
+// UpsertFoundVolume provides a mock function with given fields: ctx, userData, foundVolume
+func (_m *FoundVolumesService) UpsertFoundVolume(ctx context.Context, userData models.UserPairs, foundVolume models.FoundVolume) (bool, error) {
+	ret := _m.Called(ctx, userData, foundVolume)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UserPairs, models.FoundVolume) (bool, error)); ok {
+		return rf(ctx, userData, foundVolume)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.UserPairs, models.FoundVolume) bool); ok {
+		r0 = rf(ctx, userData, foundVolume)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.UserPairs, models.FoundVolume) error); ok {
+		r1 = rf(ctx, userData, foundVolume)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 type mockConstructorTestingTNewFoundVolumesService interface {
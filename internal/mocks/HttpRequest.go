@@ -3,8 +3,11 @@
 package mocks
 
 import (
+	context "context"
 	http "net/http"
 
+	time "time"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -13,23 +16,47 @@ type HttpRequest struct {
 	mock.Mock
 }
 
-// Get provides a mock function with given fields: url
-func (_m *HttpRequest) Get(url string) (http.Response, error) {
-	ret := _m.Called(url)
+// Get provides a mock function with given fields: url, headers
+func (_m *HttpRequest) Get(url string, headers http.Header) (http.Response, error) {
+	ret := _m.Called(url, headers)
+
+	var r0 http.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, http.Header) (http.Response, error)); ok {
+		return rf(url, headers)
+	}
+	if rf, ok := ret.Get(0).(func(string, http.Header) http.Response); ok {
+		r0 = rf(url, headers)
+	} else {
+		r0 = ret.Get(0).(http.Response)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, http.Header) error); ok {
+		r1 = rf(url, headers)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWithRetry provides a mock function with given fields: ctx, url, headers, attempts, backoff
+func (_m *HttpRequest) GetWithRetry(ctx context.Context, url string, headers http.Header, attempts int, backoff time.Duration) (http.Response, error) {
+	ret := _m.Called(ctx, url, headers, attempts, backoff)
 
 	var r0 http.Response
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (http.Response, error)); ok {
-		return rf(url)
+	if rf, ok := ret.Get(0).(func(context.Context, string, http.Header, int, time.Duration) (http.Response, error)); ok {
+		return rf(ctx, url, headers, attempts, backoff)
 	}
-	if rf, ok := ret.Get(0).(func(string) http.Response); ok {
-		r0 = rf(url)
+	if rf, ok := ret.Get(0).(func(context.Context, string, http.Header, int, time.Duration) http.Response); ok {
+		r0 = rf(ctx, url, headers, attempts, backoff)
 	} else {
 		r0 = ret.Get(0).(http.Response)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(url)
+	if rf, ok := ret.Get(1).(func(context.Context, string, http.Header, int, time.Duration) error); ok {
+		r1 = rf(ctx, url, headers, attempts, backoff)
 	} else {
 		r1 = ret.Error(1)
 	}
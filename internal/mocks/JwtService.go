@@ -9,30 +9,30 @@ type JwtService struct {
 	mock.Mock
 }
 
-// CreateAccessToken provides a mock function with given fields: userId, sessionId
-func (_m *JwtService) CreateAccessToken(userId int, sessionId int) (string, int64, error) {
-	ret := _m.Called(userId, sessionId)
+// CreateAccessToken provides a mock function with given fields: userId, sessionId, role
+func (_m *JwtService) CreateAccessToken(userId int, sessionId int, role string) (string, int64, error) {
+	ret := _m.Called(userId, sessionId, role)
 
 	var r0 string
 	var r1 int64
 	var r2 error
-	if rf, ok := ret.Get(0).(func(int, int) (string, int64, error)); ok {
-		return rf(userId, sessionId)
+	if rf, ok := ret.Get(0).(func(int, int, string) (string, int64, error)); ok {
+		return rf(userId, sessionId, role)
 	}
-	if rf, ok := ret.Get(0).(func(int, int) string); ok {
-		r0 = rf(userId, sessionId)
+	if rf, ok := ret.Get(0).(func(int, int, string) string); ok {
+		r0 = rf(userId, sessionId, role)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(int, int) int64); ok {
-		r1 = rf(userId, sessionId)
+	if rf, ok := ret.Get(1).(func(int, int, string) int64); ok {
+		r1 = rf(userId, sessionId, role)
 	} else {
 		r1 = ret.Get(1).(int64)
 	}
 
-	if rf, ok := ret.Get(2).(func(int, int) error); ok {
-		r2 = rf(userId, sessionId)
+	if rf, ok := ret.Get(2).(func(int, int, string) error); ok {
+		r2 = rf(userId, sessionId, role)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -40,6 +40,30 @@ func (_m *JwtService) CreateAccessToken(userId int, sessionId int) (string, int6
 	return r0, r1, r2
 }
 
+// CreateChangeEmailToken provides a mock function with given fields: userId, email
+func (_m *JwtService) CreateChangeEmailToken(userId int, email string) (string, error) {
+	ret := _m.Called(userId, email)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int, string) (string, error)); ok {
+		return rf(userId, email)
+	}
+	if rf, ok := ret.Get(0).(func(int, string) string); ok {
+		r0 = rf(userId, email)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(int, string) error); ok {
+		r1 = rf(userId, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateRefreshToken provides a mock function with given fields: userId, sessionId
 func (_m *JwtService) CreateRefreshToken(userId int, sessionId int) (string, error) {
 	ret := _m.Called(userId, sessionId)
@@ -64,6 +88,54 @@ func (_m *JwtService) CreateRefreshToken(userId int, sessionId int) (string, err
 	return r0, r1
 }
 
+// CreateResetPasswordToken provides a mock function with given fields: userId, passwordVersion
+func (_m *JwtService) CreateResetPasswordToken(userId int, passwordVersion int) (string, error) {
+	ret := _m.Called(userId, passwordVersion)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int, int) (string, error)); ok {
+		return rf(userId, passwordVersion)
+	}
+	if rf, ok := ret.Get(0).(func(int, int) string); ok {
+		r0 = rf(userId, passwordVersion)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(int, int) error); ok {
+		r1 = rf(userId, passwordVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateVerifyEmailToken provides a mock function with given fields: userId
+func (_m *JwtService) CreateVerifyEmailToken(userId int) (string, error) {
+	ret := _m.Called(userId)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int) (string, error)); ok {
+		return rf(userId)
+	}
+	if rf, ok := ret.Get(0).(func(int) string); ok {
+		r0 = rf(userId)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Parse provides a mock function with given fields: token
 func (_m *JwtService) Parse(token string) (int, int, error) {
 	ret := _m.Called(token)
@@ -95,6 +167,123 @@ func (_m *JwtService) Parse(token string) (int, int, error) {
 	return r0, r1, r2
 }
 
+// ParseChangeEmailToken provides a mock function with given fields: token
+func (_m *JwtService) ParseChangeEmailToken(token string) (int, string, error) {
+	ret := _m.Called(token)
+
+	var r0 int
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string) (int, string, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(string) int); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) string); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(token)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ParseRefreshToken provides a mock function with given fields: token
+func (_m *JwtService) ParseRefreshToken(token string) (int, int, error) {
+	ret := _m.Called(token)
+
+	var r0 int
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string) (int, int, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(string) int); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) int); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(token)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ParseResetPasswordToken provides a mock function with given fields: token
+func (_m *JwtService) ParseResetPasswordToken(token string) (int, int, error) {
+	ret := _m.Called(token)
+
+	var r0 int
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string) (int, int, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(string) int); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) int); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(token)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ParseVerifyEmailToken provides a mock function with given fields: token
+func (_m *JwtService) ParseVerifyEmailToken(token string) (int, error) {
+	ret := _m.Called(token)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (int, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(string) int); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewJwtService interface {
 	mock.TestingT
 	Cleanup(func())
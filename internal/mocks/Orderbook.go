@@ -6,6 +6,8 @@ import (
 	models "cvs/internal/models"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // Orderbook is an autogenerated mock type for the Orderbook type
@@ -13,6 +15,11 @@ type Orderbook struct {
 	mock.Mock
 }
 
+// ApplyDelta provides a mock function with given fields: pair, askUpdates, bidUpdates
+func (_m *Orderbook) ApplyDelta(pair string, askUpdates [][]interface{}, bidUpdates [][]interface{}) {
+	_m.Called(pair, askUpdates, bidUpdates)
+}
+
 // Asks provides a mock function with given fields: pair
 func (_m *Orderbook) Asks(pair string) map[string]interface{} {
 	ret := _m.Called(pair)
@@ -45,13 +52,77 @@ func (_m *Orderbook) Bids(pair string) map[string]interface{} {
 	return r0
 }
 
-// SearchVolume provides a mock function with given fields: pair, exchange, search
-func (_m *Orderbook) SearchVolume(pair string, exchange string, search float64) []models.FoundVolume {
-	ret := _m.Called(pair, exchange, search)
+// BestPrices provides a mock function with given fields: pair
+func (_m *Orderbook) BestPrices(pair string) (models.PriceSnapshot, bool) {
+	ret := _m.Called(pair)
+
+	var r0 models.PriceSnapshot
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(string) (models.PriceSnapshot, bool)); ok {
+		return rf(pair)
+	}
+	if rf, ok := ret.Get(0).(func(string) models.PriceSnapshot); ok {
+		r0 = rf(pair)
+	} else {
+		r0 = ret.Get(0).(models.PriceSnapshot)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(pair)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// EvictStale provides a mock function with given fields: maxAge
+func (_m *Orderbook) EvictStale(maxAge time.Duration) int {
+	ret := _m.Called(maxAge)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(time.Duration) int); ok {
+		r0 = rf(maxAge)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
+// Release provides a mock function with given fields: pair, holder
+func (_m *Orderbook) Release(pair string, holder string) {
+	_m.Called(pair, holder)
+}
+
+// Retain provides a mock function with given fields: pair, holder
+func (_m *Orderbook) Retain(pair string, holder string) {
+	_m.Called(pair, holder)
+}
+
+// SearchCumulativeVolume provides a mock function with given fields: pair, exchange, threshold, priceRange
+func (_m *Orderbook) SearchCumulativeVolume(pair string, exchange string, threshold float64, priceRange float64) []models.FoundVolume {
+	ret := _m.Called(pair, exchange, threshold, priceRange)
+
+	var r0 []models.FoundVolume
+	if rf, ok := ret.Get(0).(func(string, string, float64, float64) []models.FoundVolume); ok {
+		r0 = rf(pair, exchange, threshold, priceRange)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.FoundVolume)
+		}
+	}
+
+	return r0
+}
+
+// SearchVolume provides a mock function with given fields: pair, exchange, search, tolerance, floor
+func (_m *Orderbook) SearchVolume(pair string, exchange string, search float64, tolerance float64, floor float64) []models.FoundVolume {
+	ret := _m.Called(pair, exchange, search, tolerance, floor)
 
 	var r0 []models.FoundVolume
-	if rf, ok := ret.Get(0).(func(string, string, float64) []models.FoundVolume); ok {
-		r0 = rf(pair, exchange, search)
+	if rf, ok := ret.Get(0).(func(string, string, float64, float64, float64) []models.FoundVolume); ok {
+		r0 = rf(pair, exchange, search, tolerance, floor)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.FoundVolume)
@@ -61,6 +132,46 @@ func (_m *Orderbook) SearchVolume(pair string, exchange string, search float64)
 	return r0
 }
 
+// SearchVolumeRelative provides a mock function with given fields: pair, exchange, multiplier, window
+func (_m *Orderbook) SearchVolumeRelative(pair string, exchange string, multiplier float64, window int) []models.FoundVolume {
+	ret := _m.Called(pair, exchange, multiplier, window)
+
+	var r0 []models.FoundVolume
+	if rf, ok := ret.Get(0).(func(string, string, float64, int) []models.FoundVolume); ok {
+		r0 = rf(pair, exchange, multiplier, window)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.FoundVolume)
+		}
+	}
+
+	return r0
+}
+
+// Snapshot provides a mock function with given fields: pair, depth
+func (_m *Orderbook) Snapshot(pair string, depth int) (models.OrderbookSnapshot, bool) {
+	ret := _m.Called(pair, depth)
+
+	var r0 models.OrderbookSnapshot
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(string, int) (models.OrderbookSnapshot, bool)); ok {
+		return rf(pair, depth)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) models.OrderbookSnapshot); ok {
+		r0 = rf(pair, depth)
+	} else {
+		r0 = ret.Get(0).(models.OrderbookSnapshot)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) bool); ok {
+		r1 = rf(pair, depth)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
 // Upsert provides a mock function with given fields: pair, asks, bids
 func (_m *Orderbook) Upsert(pair string, asks [][]interface{}, bids [][]interface{}) {
 	_m.Called(pair, asks, bids)
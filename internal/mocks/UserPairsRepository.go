@@ -28,6 +28,94 @@ func (_m *UserPairsRepository) Add(ctx context.Context, pairData models.UserPair
 	return r0
 }
 
+// BulkAdd provides a mock function with given fields: ctx, pairs
+func (_m *UserPairsRepository) BulkAdd(ctx context.Context, pairs []models.UserPairs) ([]error, error) {
+	ret := _m.Called(ctx, pairs)
+
+	var r0 []error
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []models.UserPairs) ([]error, error)); ok {
+		return rf(ctx, pairs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []models.UserPairs) []error); ok {
+		r0 = rf(ctx, pairs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]error)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []models.UserPairs) error); ok {
+		r1 = rf(ctx, pairs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountPairSubscribers provides a mock function with given fields: ctx, exchange, pair
+func (_m *UserPairsRepository) CountPairSubscribers(ctx context.Context, exchange string, pair string) (int, error) {
+	ret := _m.Called(ctx, exchange, pair)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (int, error)); ok {
+		return rf(ctx, exchange, pair)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int); ok {
+		r0 = rf(ctx, exchange, pair)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, exchange, pair)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountUserPairs provides a mock function with given fields: ctx, userID
+func (_m *UserPairsRepository) CountUserPairs(ctx context.Context, userID int) (int, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (int, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) int); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteAllUserPairs provides a mock function with given fields: ctx, userID
+func (_m *UserPairsRepository) DeleteAllUserPairs(ctx context.Context, userID int) error {
+	ret := _m.Called(ctx, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeletePair provides a mock function with given fields: ctx, pairData
 func (_m *UserPairsRepository) DeletePair(ctx context.Context, pairData models.UserPairs) error {
 	ret := _m.Called(ctx, pairData)
@@ -94,6 +182,106 @@ func (_m *UserPairsRepository) GetPairsByExchange(ctx context.Context, exchange
 	return r0, r1
 }
 
+// GetUserPair provides a mock function with given fields: ctx, userID, exchange, pair
+func (_m *UserPairsRepository) GetUserPair(ctx context.Context, userID int, exchange string, pair string) (models.UserPairs, error) {
+	ret := _m.Called(ctx, userID, exchange, pair)
+
+	var r0 models.UserPairs
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string) (models.UserPairs, error)); ok {
+		return rf(ctx, userID, exchange, pair)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string) models.UserPairs); ok {
+		r0 = rf(ctx, userID, exchange, pair)
+	} else {
+		r0 = ret.Get(0).(models.UserPairs)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, string) error); ok {
+		r1 = rf(ctx, userID, exchange, pair)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserPairsByExchange provides a mock function with given fields: ctx, userID, exchange
+func (_m *UserPairsRepository) GetUserPairsByExchange(ctx context.Context, userID int, exchange string) ([]models.UserPairs, error) {
+	ret := _m.Called(ctx, userID, exchange)
+
+	var r0 []models.UserPairs
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) ([]models.UserPairs, error)); ok {
+		return rf(ctx, userID, exchange)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) []models.UserPairs); ok {
+		r0 = rf(ctx, userID, exchange)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.UserPairs)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(ctx, userID, exchange)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserPairsPaged provides a mock function with given fields: ctx, userID, limit, offset
+func (_m *UserPairsRepository) GetUserPairsPaged(ctx context.Context, userID int, limit int, offset int) ([]models.UserPairs, error) {
+	ret := _m.Called(ctx, userID, limit, offset)
+
+	var r0 []models.UserPairs
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]models.UserPairs, error)); ok {
+		return rf(ctx, userID, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []models.UserPairs); ok {
+		r0 = rf(ctx, userID, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.UserPairs)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) error); ok {
+		r1 = rf(ctx, userID, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplaceUserPairs provides a mock function with given fields: ctx, userID, pairs
+func (_m *UserPairsRepository) ReplaceUserPairs(ctx context.Context, userID int, pairs []models.UserPairs) (models.UserPairsReplaceResult, error) {
+	ret := _m.Called(ctx, userID, pairs)
+
+	var r0 models.UserPairsReplaceResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, []models.UserPairs) (models.UserPairsReplaceResult, error)); ok {
+		return rf(ctx, userID, pairs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, []models.UserPairs) models.UserPairsReplaceResult); ok {
+		r0 = rf(ctx, userID, pairs)
+	} else {
+		r0 = ret.Get(0).(models.UserPairsReplaceResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, []models.UserPairs) error); ok {
+		r1 = rf(ctx, userID, pairs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateExactValue provides a mock function with given fields: ctx, pairData
 func (_m *UserPairsRepository) UpdateExactValue(ctx context.Context, pairData models.UserPairs) error {
 	ret := _m.Called(ctx, pairData)
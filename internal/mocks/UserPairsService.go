@@ -28,6 +28,70 @@ func (_m *UserPairsService) Add(ctx context.Context, pairData models.UserPairs)
 	return r0
 }
 
+// BulkAdd provides a mock function with given fields: ctx, pairs
+func (_m *UserPairsService) BulkAdd(ctx context.Context, pairs []models.UserPairs) ([]error, error) {
+	ret := _m.Called(ctx, pairs)
+
+	var r0 []error
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []models.UserPairs) ([]error, error)); ok {
+		return rf(ctx, pairs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []models.UserPairs) []error); ok {
+		r0 = rf(ctx, pairs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]error)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []models.UserPairs) error); ok {
+		r1 = rf(ctx, pairs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountPairSubscribers provides a mock function with given fields: ctx, exchange, pair
+func (_m *UserPairsService) CountPairSubscribers(ctx context.Context, exchange string, pair string) (int, error) {
+	ret := _m.Called(ctx, exchange, pair)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (int, error)); ok {
+		return rf(ctx, exchange, pair)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int); ok {
+		r0 = rf(ctx, exchange, pair)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, exchange, pair)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteAllUserPairs provides a mock function with given fields: ctx, userID
+func (_m *UserPairsService) DeleteAllUserPairs(ctx context.Context, userID int) error {
+	ret := _m.Called(ctx, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeletePair provides a mock function with given fields: ctx, pairData
 func (_m *UserPairsService) DeletePair(ctx context.Context, pairData models.UserPairs) error {
 	ret := _m.Called(ctx, pairData)
@@ -94,6 +158,104 @@ func (_m *UserPairsService) GetPairsByExchange(ctx context.Context, exchange str
 	return r0, r1
 }
 
+// GetUserPair provides a mock function with given fields: ctx, userID, exchange, pair
+func (_m *UserPairsService) GetUserPair(ctx context.Context, userID int, exchange string, pair string) (models.UserPairs, error) {
+	ret := _m.Called(ctx, userID, exchange, pair)
+
+	var r0 models.UserPairs
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string) (models.UserPairs, error)); ok {
+		return rf(ctx, userID, exchange, pair)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string) models.UserPairs); ok {
+		r0 = rf(ctx, userID, exchange, pair)
+	} else {
+		r0 = ret.Get(0).(models.UserPairs)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, string) error); ok {
+		r1 = rf(ctx, userID, exchange, pair)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserPairsByExchange provides a mock function with given fields: ctx, userID, exchange
+func (_m *UserPairsService) GetUserPairsByExchange(ctx context.Context, userID int, exchange string) ([]models.UserPairs, error) {
+	ret := _m.Called(ctx, userID, exchange)
+
+	var r0 []models.UserPairs
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) ([]models.UserPairs, error)); ok {
+		return rf(ctx, userID, exchange)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) []models.UserPairs); ok {
+		r0 = rf(ctx, userID, exchange)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.UserPairs)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(ctx, userID, exchange)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserPairsPage provides a mock function with given fields: ctx, userID, pagination
+func (_m *UserPairsService) GetUserPairsPage(ctx context.Context, userID int, pagination models.UserPairsPagination) (models.UserPairsPage, error) {
+	ret := _m.Called(ctx, userID, pagination)
+
+	var r0 models.UserPairsPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.UserPairsPagination) (models.UserPairsPage, error)); ok {
+		return rf(ctx, userID, pagination)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.UserPairsPagination) models.UserPairsPage); ok {
+		r0 = rf(ctx, userID, pagination)
+	} else {
+		r0 = ret.Get(0).(models.UserPairsPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, models.UserPairsPagination) error); ok {
+		r1 = rf(ctx, userID, pagination)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplaceUserPairs provides a mock function with given fields: ctx, userID, pairs
+func (_m *UserPairsService) ReplaceUserPairs(ctx context.Context, userID int, pairs []models.UserPairs) (models.UserPairsReplaceResult, error) {
+	ret := _m.Called(ctx, userID, pairs)
+
+	var r0 models.UserPairsReplaceResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, []models.UserPairs) (models.UserPairsReplaceResult, error)); ok {
+		return rf(ctx, userID, pairs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, []models.UserPairs) models.UserPairsReplaceResult); ok {
+		r0 = rf(ctx, userID, pairs)
+	} else {
+		r0 = ret.Get(0).(models.UserPairsReplaceResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, []models.UserPairs) error); ok {
+		r1 = rf(ctx, userID, pairs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateExactValue provides a mock function with given fields: ctx, pairData
 func (_m *UserPairsService) UpdateExactValue(ctx context.Context, pairData models.UserPairs) error {
 	ret := _m.Called(ctx, pairData)
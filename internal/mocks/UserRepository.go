@@ -4,8 +4,11 @@ package mocks
 
 import (
 	context "context"
+
 	models "cvs/internal/models"
 
+	repository "cvs/internal/repository"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -14,6 +17,20 @@ type UserRepository struct {
 	mock.Mock
 }
 
+// ConfirmEmail provides a mock function with given fields: ctx, userID, email
+func (_m *UserRepository) ConfirmEmail(ctx context.Context, userID int, email string) error {
+	ret := _m.Called(ctx, userID, email)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(ctx, userID, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteUser provides a mock function with given fields: ctx, clientID
 func (_m *UserRepository) DeleteUser(ctx context.Context, clientID int) error {
 	ret := _m.Called(ctx, clientID)
@@ -126,6 +143,44 @@ func (_m *UserRepository) InsertUser(ctx context.Context, user models.User) (int
 	return r0, r1
 }
 
+// InsertUserWithSession provides a mock function with given fields: ctx, user, newSession
+func (_m *UserRepository) InsertUserWithSession(ctx context.Context, user models.User, newSession repository.NewSessionFunc) (int, error) {
+	ret := _m.Called(ctx, user, newSession)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.User, repository.NewSessionFunc) (int, error)); ok {
+		return rf(ctx, user, newSession)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.User, repository.NewSessionFunc) int); ok {
+		r0 = rf(ctx, user, newSession)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.User, repository.NewSessionFunc) error); ok {
+		r1 = rf(ctx, user, newSession)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetPendingEmail provides a mock function with given fields: ctx, userID, email
+func (_m *UserRepository) SetPendingEmail(ctx context.Context, userID int, email string) error {
+	ret := _m.Called(ctx, userID, email)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(ctx, userID, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdatePassword provides a mock function with given fields: ctx, user
 func (_m *UserRepository) UpdatePassword(ctx context.Context, user models.User) error {
 	ret := _m.Called(ctx, user)
@@ -140,13 +195,13 @@ func (_m *UserRepository) UpdatePassword(ctx context.Context, user models.User)
 	return r0
 }
 
-// UpdateRefreshToken provides a mock function with given fields: ctx, user
-func (_m *UserRepository) UpdateRefreshToken(ctx context.Context, user models.User) error {
-	ret := _m.Called(ctx, user)
+// VerifyUser provides a mock function with given fields: ctx, userID
+func (_m *UserRepository) VerifyUser(ctx context.Context, userID int) error {
+	ret := _m.Called(ctx, userID)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, models.User) error); ok {
-		r0 = rf(ctx, user)
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, userID)
 	} else {
 		r0 = ret.Error(0)
 	}
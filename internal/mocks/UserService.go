@@ -10,6 +10,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	models "cvs/internal/models"
+
+	repository "cvs/internal/repository"
 )
 
 // UserService is an autogenerated mock type for the UserService type
@@ -17,6 +19,20 @@ type UserService struct {
 	mock.Mock
 }
 
+// ConfirmEmail provides a mock function with given fields: ctx, userID, email
+func (_m *UserService) ConfirmEmail(ctx context.Context, userID int, email string) error {
+	ret := _m.Called(ctx, userID, email)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(ctx, userID, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteUser provides a mock function with given fields: ctx, userID
 func (_m *UserService) DeleteUser(ctx context.Context, userID int) error {
 	ret := _m.Called(ctx, userID)
@@ -136,6 +152,44 @@ func (_m *UserService) InsertUser(ctx context.Context, user models.User) (int, e
 	return r0, r1
 }
 
+// InsertUserWithSession provides a mock function with given fields: ctx, user, newSession
+func (_m *UserService) InsertUserWithSession(ctx context.Context, user models.User, newSession repository.NewSessionFunc) (int, error) {
+	ret := _m.Called(ctx, user, newSession)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.User, repository.NewSessionFunc) (int, error)); ok {
+		return rf(ctx, user, newSession)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.User, repository.NewSessionFunc) int); ok {
+		r0 = rf(ctx, user, newSession)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.User, repository.NewSessionFunc) error); ok {
+		r1 = rf(ctx, user, newSession)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetPendingEmail provides a mock function with given fields: ctx, userID, email
+func (_m *UserService) SetPendingEmail(ctx context.Context, userID int, email string) error {
+	ret := _m.Called(ctx, userID, email)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(ctx, userID, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SetUserIdIntoMemory provides a mock function with given fields: userID
 func (_m *UserService) SetUserIdIntoMemory(userID int) {
 	_m.Called(userID)
@@ -155,13 +209,13 @@ func (_m *UserService) UpdatePassword(ctx context.Context, user models.User) err
 	return r0
 }
 
-// UpdateRefreshToken provides a mock function with given fields: c, user
-func (_m *UserService) UpdateRefreshToken(c context.Context, user models.User) error {
-	ret := _m.Called(c, user)
+// VerifyUser provides a mock function with given fields: ctx, userID
+func (_m *UserService) VerifyUser(ctx context.Context, userID int) error {
+	ret := _m.Called(ctx, userID)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, models.User) error); ok {
-		r0 = rf(c, user)
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, userID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -13,6 +13,8 @@ type BinancePairsJSONResponse struct {
 	Symbols         []struct {
 		Symbol                     string   `json:"symbol"`
 		Status                     string   `json:"status"`
+		ContractType               string   `json:"contractType"`
+		ContractStatus             string   `json:"contractStatus"`
 		BaseAsset                  string   `json:"baseAsset"`
 		BaseAssetPrecision         int      `json:"baseAssetPrecision"`
 		QuoteAsset                 string   `json:"quoteAsset"`
@@ -54,6 +56,19 @@ type BinanceOrderbookJSONResponse struct {
 	LastUpdateID int64           `json:"lastUpdateId"`
 }
 
+type BinanceBookTickerJSONResponse struct {
+	Symbol   string `json:"symbol"`
+	BidPrice string `json:"bidPrice"`
+	BidQty   string `json:"bidQty"`
+	AskPrice string `json:"askPrice"`
+	AskQty   string `json:"askQty"`
+}
+
+type BinanceTickerPriceJSONResponse struct {
+	Symbol string `json:"symbol"`
+	Price  string `json:"price"`
+}
+
 type BinanceFuturesLevel2JSONResponse struct {
 	LastUpdateID int        `json:"lastUpdateId"`
 	E            int64      `json:"E"`
@@ -62,6 +77,29 @@ type BinanceFuturesLevel2JSONResponse struct {
 	Asks         [][]string `json:"asks"`
 }
 
+type BinanceDepthStreamJSONResponse struct {
+	Stream string            `json:"stream"`
+	Data   BinanceDepthEvent `json:"data"`
+}
+
+// BinanceDepthEvent is a diff-depth event of a single pair, the updates of its order book between two update IDs.
+type BinanceDepthEvent struct {
+	EventType         string          `json:"e"`
+	EventTime         int64           `json:"E"`
+	Symbol            string          `json:"s"`
+	FirstUpdateID     int64           `json:"U"`
+	FinalUpdateID     int64           `json:"u"`
+	PrevFinalUpdateID int64           `json:"pu"` // ID of the final update of the previous event, sent by the futures streams only
+	Bids              [][]interface{} `json:"b"`
+	Asks              [][]interface{} `json:"a"`
+}
+
+type BinanceWebsocketRequest struct {
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+	ID     int64    `json:"id"`
+}
+
 type BinanceKlinesJSONResponse [][]interface{}
 
 type BinancePairsTable struct {
@@ -7,6 +7,7 @@ type BybitPairsJSONResponse struct {
 		Category string `json:"category"`
 		List     []struct {
 			Symbol        string `json:"symbol"`
+			ContractType  string `json:"contractType"`
 			BaseCoin      string `json:"baseCoin"`
 			QuoteCoin     string `json:"quoteCoin"`
 			Innovation    string `json:"innovation"`
@@ -43,3 +44,16 @@ type BybitOrderbookJSONResponse struct {
 	} `json:"retExtInfo"`
 	Time int64 `json:"time"`
 }
+
+type BybitTickersJSONResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		Category string `json:"category"`
+		List     []struct {
+			Symbol    string `json:"symbol"`
+			LastPrice string `json:"lastPrice"`
+		} `json:"list"`
+	} `json:"result"`
+	Time int64 `json:"time"`
+}
@@ -0,0 +1,38 @@
+package models
+
+type CoinbasePairsJSONResponse struct {
+	Products []struct {
+		ProductId         string `json:"product_id"`
+		BaseCurrencyId    string `json:"base_currency_id"`
+		QuoteCurrencyId   string `json:"quote_currency_id"`
+		ProductType       string `json:"product_type"`
+		Status            string `json:"status"`
+		TradingDisabled   bool   `json:"trading_disabled"`
+		IsDisabled        bool   `json:"is_disabled"`
+		CancelOnly        bool   `json:"cancel_only"`
+		LimitOnly         bool   `json:"limit_only"`
+		PostOnly          bool   `json:"post_only"`
+		BaseIncrement     string `json:"base_increment"`
+		QuoteIncrement    string `json:"quote_increment"`
+		BaseDisplaySymbol string `json:"base_display_symbol"`
+	} `json:"products"`
+	NumProducts int `json:"num_products"`
+}
+
+type CoinbaseOrderbookJSONResponse struct {
+	Pricebook struct {
+		ProductId string                   `json:"product_id"`
+		Bids      []CoinbaseOrderbookLevel `json:"bids"`
+		Asks      []CoinbaseOrderbookLevel `json:"asks"`
+		Time      string                   `json:"time"`
+	} `json:"pricebook"`
+	Last         string `json:"last"` // Price of the last trade of the product
+	Error        string `json:"error"`
+	ErrorDetails string `json:"error_details"`
+	Message      string `json:"message"`
+}
+
+type CoinbaseOrderbookLevel struct {
+	Price string `json:"price"`
+	Size  string `json:"size"`
+}
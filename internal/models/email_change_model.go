@@ -0,0 +1,5 @@
+package models
+
+type EmailChange struct {
+	Email string `json:"email" example:"new@example.com"` // New email of the user, it replaces the current one once confirmed
+}
@@ -1,6 +1,37 @@
 package models
 
+import (
+	"slices"
+	"strings"
+)
+
 type ExchangePairs struct {
-	Pair     string
-	Exchange string
+	Pair     string `json:"pair" example:"BTC/USDT"`
+	Exchange string `json:"exchange" example:"binance_spot"`
+}
+
+// ExchangePairsGroup holds the pairs of an exchange found by a search across all exchanges.
+type ExchangePairsGroup struct {
+	Exchange string          `json:"exchange" example:"binance_spot"`
+	Pairs    []ExchangePairs `json:"pairs"` // Found pairs sorted by the pair name
+}
+
+// QuoteFilter narrows down the pairs of an exchange by their quote assets, e.g. to scan only the USDT pairs.
+// The assets are compared case-insensitively. An empty filter allows every quote asset.
+type QuoteFilter struct {
+	Whitelist []string `yaml:"whitelist"` // Quote assets whose pairs are stored, every asset if empty
+	Blacklist []string `yaml:"blacklist"` // Quote assets whose pairs are skipped even if they are whitelisted
+}
+
+// Allows reports whether the pairs of the quote asset pass the filter.
+func (f QuoteFilter) Allows(quote string) bool {
+	matches := func(asset string) bool {
+		return strings.EqualFold(asset, quote)
+	}
+
+	if len(f.Whitelist) != 0 && !slices.ContainsFunc(f.Whitelist, matches) {
+		return false
+	}
+
+	return !slices.ContainsFunc(f.Blacklist, matches)
 }
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// States of the circuit breaker pausing the requests to an exchange which keeps failing.
+const (
+	CircuitBreakerClosed   = "closed"    // The exchange is requested as usual
+	CircuitBreakerOpen     = "open"      // The requests are paused after consecutive failures
+	CircuitBreakerHalfOpen = "half_open" // A single request tests whether the exchange has recovered
+)
+
+// ExchangeStatus describes the connectivity of an exchange.
+type ExchangeStatus struct {
+	Exchange            string    `json:"exchange" example:"binance_spot"`
+	LastSuccessfulFetch time.Time `json:"last_successful_fetch"`                               // Zero if no fetch of the exchange has succeeded yet
+	LastError           string    `json:"last_error,omitempty" example:"response has no body"` // Error of the last fetch, empty if it succeeded
+	SubscribedPairs     int       `json:"subscribed_pairs" example:"2"`
+	SubscriptionsLoaded bool      `json:"subscriptions_loaded" example:"true"`                            // Whether the subscribed pairs have been loaded from the database
+	CircuitBreaker      string    `json:"circuit_breaker" enums:"closed,open,half_open" example:"closed"` // State of the circuit breaker pausing the requests
+}
+
+// ExchangeStats describes the scanning load of an exchange reported by the admin endpoint.
+type ExchangeStats struct {
+	Exchange            string    `json:"exchange" example:"binance_spot"`
+	SubscribedPairs     int       `json:"subscribed_pairs" example:"2"`                                   // Number of pairs added by at least one user
+	ListedPairs         int       `json:"listed_pairs" example:"1500"`                                    // Number of pairs listed on the exchange, zero until they are loaded
+	LastSuccessfulFetch time.Time `json:"last_successful_fetch"`                                          // Zero if no fetch of the exchange has succeeded yet
+	LastError           string    `json:"last_error,omitempty" example:"response has no body"`            // Error of the last fetch, empty if it succeeded
+	MinVolumeFloor      float64   `json:"min_volume_floor" example:"0.5"`                                 // Volume below which the levels are ignored, zero if none is ignored
+	CircuitBreaker      string    `json:"circuit_breaker" enums:"closed,open,half_open" example:"closed"` // State of the circuit breaker pausing the requests
+	BlacklistedPairs    []string  `json:"blacklisted_pairs,omitempty" example:"LUNA/USDT"`                // Pairs which are never polled even if subscribed
+}
+
+// ExchangePairsRefresh is the result of the refresh of the pairs of an exchange requested by an admin.
+type ExchangePairsRefresh struct {
+	Exchange    string `json:"exchange" example:"binance_spot"`
+	ListedPairs int    `json:"listed_pairs" example:"1500"` // Number of pairs listed on the exchange after the refresh
+}
+
+// ExchangeReadiness is the status of an exchange reported by the readiness endpoint.
+type ExchangeReadiness struct {
+	ExchangeStatus
+	Staleness string `json:"staleness,omitempty" example:"3.2s"` // Time since the last successful fetch, empty if there was none
+	Ready     bool   `json:"ready" example:"true"`
+}
+
+type Readiness struct {
+	Ready     bool                `json:"ready" example:"true"`
+	Exchanges []ExchangeReadiness `json:"exchanges"`
+}
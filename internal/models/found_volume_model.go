@@ -3,12 +3,46 @@ package models
 import "time"
 
 type FoundVolume struct {
-	Exchange        string    `json:"exchange"`
-	Pair            string    `json:"pair"`
-	Price           float64   `json:"price"`
-	Index           int       `json:"index"`      // Number of rows between found volume index and best ask or best bid and found volume index
-	Difference      float64   `json:"difference"` // Difference between found volume and best ask or best bid and found volume in percent
-	Volume          float64   `json:"volume"`
-	VolumeTimeFound time.Time `json:"volume_time_found"`
-	Side            string    `json:"side"`
+	UserID           int       `json:"-" db:"-"` // ID of the user the volume was found for, set on the published events
+	Exchange         string    `json:"exchange" db:"exchange"`
+	Pair             string    `json:"pair" db:"pair"`
+	Price            float64   `json:"price" db:"price"`
+	Index            int       `json:"index" db:"volume_index"`    // Number of rows between found volume index and best ask or best bid and found volume index
+	Difference       float64   `json:"difference" db:"difference"` // Non-negative distance of the price from the best price of the side in percent of the best price
+	Volume           float64   `json:"volume" db:"volume"`
+	VolumeTimeFound  time.Time `json:"volume_time_found" db:"volume_time_found"`
+	Side             string    `json:"side" db:"side"`
+	Expired          bool      `json:"expired,omitempty" db:"-"`                   // Set on the found volumes pushed to subscribers when they expire without being found again
+	LastPrice        float64   `json:"last_price" db:"last_price"`                 // Price of the last trade of the pair, zero if it isn't known
+	DistanceFromLast float64   `json:"distance_from_last" db:"distance_from_last"` // Distance between the found volume and the last trade price in percent, negative below the last price
+	BandLow          float64   `json:"band_low,omitempty" db:"band_low"`           // Lowest price of the levels summed in the cumulative search mode, zero in the other modes
+	BandHigh         float64   `json:"band_high,omitempty" db:"band_high"`         // Highest price of the levels summed in the cumulative search mode, zero in the other modes
+}
+
+// ExchangeVolumes holds the volumes of a pair an exact value would find on an exchange, see AggregatedVolumes.
+type ExchangeVolumes struct {
+	Exchange    string        `json:"exchange" example:"binance_spot"`
+	TotalVolume float64       `json:"total_volume" example:"42"` // Sum of the found volumes of the exchange
+	Volumes     []FoundVolume `json:"volumes"`                   // Found volumes sorted by the volume descending
+}
+
+// AggregatedVolumes holds the volumes of a pair an exact value would find across all exchanges listing it.
+type AggregatedVolumes struct {
+	Pair        string            `json:"pair" example:"BTC/USDT"`
+	TotalVolume float64           `json:"total_volume" example:"57"` // Sum of the found volumes of all exchanges
+	Exchanges   []ExchangeVolumes `json:"exchanges"`                 // Exchanges with found volumes sorted by their total volume descending
+}
+
+// FoundVolumesFilter narrows down, sorts and paginates the found volumes of a user.
+// Empty fields don't filter, a zero limit returns all the remaining found volumes.
+// The found volumes are sorted by the difference descending unless the sort key or the order is set.
+type FoundVolumesFilter struct {
+	Exchange  string  `query:"exchange" example:"binance_spot"`
+	Pair      string  `query:"pair" example:"BTC/USDT"`
+	Side      string  `query:"side" example:"asks"`
+	MinVolume float64 `query:"min_volume" example:"10"`
+	Limit     int     `query:"limit" example:"20"`
+	Offset    int     `query:"offset" example:"0"`
+	Sort      string  `query:"sort" example:"difference"` // Sort key: difference, volume or price
+	Order     string  `query:"order" example:"desc"`      // Sort order: asc or desc
 }
@@ -0,0 +1,39 @@
+package models
+
+type KrakenPairsJSONResponse struct {
+	Error  []string `json:"error"`
+	Result map[string]struct {
+		Altname           string `json:"altname"`
+		Wsname            string `json:"wsname"`
+		AclassBase        string `json:"aclass_base"`
+		Base              string `json:"base"`
+		AclassQuote       string `json:"aclass_quote"`
+		Quote             string `json:"quote"`
+		PairDecimals      int    `json:"pair_decimals"`
+		CostDecimals      int    `json:"cost_decimals"`
+		LotDecimals       int    `json:"lot_decimals"`
+		LotMultiplier     int    `json:"lot_multiplier"`
+		Ordermin          string `json:"ordermin"`
+		Costmin           string `json:"costmin"`
+		TickSize          string `json:"tick_size"`
+		Status            string `json:"status"`
+		MarginCall        int    `json:"margin_call"`
+		MarginStop        int    `json:"margin_stop"`
+		FeeVolumeCurrency string `json:"fee_volume_currency"`
+	} `json:"result"`
+}
+
+type KrakenOrderbookJSONResponse struct {
+	Error  []string `json:"error"`
+	Result map[string]struct {
+		Asks [][]interface{} `json:"asks"`
+		Bids [][]interface{} `json:"bids"`
+	} `json:"result"`
+}
+
+type KrakenTickerJSONResponse struct {
+	Error  []string `json:"error"`
+	Result map[string]struct {
+		C []string `json:"c"` // Last trade closed in the [price, lot volume] format
+	} `json:"result"`
+}
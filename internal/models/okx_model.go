@@ -0,0 +1,41 @@
+package models
+
+type OkxPairsJSONResponse struct {
+	Code string `json:"code"`
+	Msg  string `json:"msg"`
+	Data []struct {
+		InstType   string `json:"instType"`
+		InstId     string `json:"instId"`
+		Uly        string `json:"uly"`
+		InstFamily string `json:"instFamily"`
+		BaseCcy    string `json:"baseCcy"`
+		QuoteCcy   string `json:"quoteCcy"`
+		SettleCcy  string `json:"settleCcy"`
+		CtVal      string `json:"ctVal"`
+		CtValCcy   string `json:"ctValCcy"`
+		CtType     string `json:"ctType"`
+		TickSz     string `json:"tickSz"`
+		LotSz      string `json:"lotSz"`
+		State      string `json:"state"`
+	} `json:"data"`
+}
+
+type OkxOrderbookJSONResponse struct {
+	Code string `json:"code"`
+	Msg  string `json:"msg"`
+	Data []struct {
+		Asks [][]interface{} `json:"asks"`
+		Bids [][]interface{} `json:"bids"`
+		Ts   string          `json:"ts"`
+	} `json:"data"`
+}
+
+type OkxTickerJSONResponse struct {
+	Code string `json:"code"`
+	Msg  string `json:"msg"`
+	Data []struct {
+		InstId string `json:"instId"`
+		Last   string `json:"last"`
+		Ts     string `json:"ts"`
+	} `json:"data"`
+}
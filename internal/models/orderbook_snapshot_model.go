@@ -0,0 +1,16 @@
+package models
+
+// OrderbookLevel is a price level of the order book.
+type OrderbookLevel struct {
+	Price  float64 `json:"price" example:"50000.5"`
+	Volume float64 `json:"volume" example:"1.25"`
+}
+
+// OrderbookSnapshot holds the order book of a pair as the scanner sees it.
+// Both sides start with the best price, so the asks are sorted by price ascending and the bids descending.
+type OrderbookSnapshot struct {
+	Exchange string           `json:"exchange" example:"binance_spot"`
+	Pair     string           `json:"pair" example:"BTC/USDT"`
+	Asks     []OrderbookLevel `json:"asks"`
+	Bids     []OrderbookLevel `json:"bids"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidPair is returned by NormalizePair for a symbol which can't be split into the base and quote assets.
+var ErrInvalidPair = errors.New("invalid pair format, expected e.g. BTC/USDT, BTC-USDT or BTCUSDT")
+
+// pairSeparators separate the base and quote assets in the common formats of the pairs, e.g. BTC-USDT.
+var pairSeparators = []string{"/", "-", "_", ":"}
+
+// knownQuoteAssets are matched against the end of a symbol without a separator, e.g. BTCUSDT.
+var knownQuoteAssets = []string{
+	"USDT", "USDC", "FDUSD", "BUSD", "TUSD", "DAI", "USD",
+	"BTC", "ETH", "BNB", "SOL", "XRP", "TRX", "DOGE",
+	"EUR", "GBP", "TRY", "BRL", "JPY", "AUD", "RUB", "UAH",
+}
+
+// NormalizePair converts a pair typed in a common format to the canonical BASE/QUOTE form the exchanges are matched on.
+//
+// The symbol is trimmed and converted to upper case. A symbol with a separator, e.g. BTC-USDT, BTC_USDT or btc/usdt,
+// is split by it. A concatenated symbol, e.g. BTCUSDT, is split by the longest known quote asset it ends with,
+// so BTCUSDT becomes BTC/USDT rather than BTCUSD/T.
+//
+// Parameters:
+//   - raw: The pair as it was submitted by the user.
+//
+// Returns:
+//   - The pair in the BASE/QUOTE form and nil, or ErrInvalidPair if the assets can't be told apart.
+func NormalizePair(raw string) (string, error) {
+	symbol := strings.ToUpper(strings.TrimSpace(raw))
+
+	for _, separator := range pairSeparators {
+		if base, quote, found := strings.Cut(symbol, separator); found {
+			return joinPair(base, quote)
+		}
+	}
+
+	quote := "" // The longest known quote asset the symbol ends with
+	for _, asset := range knownQuoteAssets {
+		if len(asset) > len(quote) && len(asset) < len(symbol) && strings.HasSuffix(symbol, asset) {
+			quote = asset
+		}
+	}
+
+	if quote == "" {
+		return "", ErrInvalidPair // The quote asset is unknown, so the symbol can't be split
+	}
+
+	return joinPair(strings.TrimSuffix(symbol, quote), quote)
+}
+
+// joinPair joins the assets into the BASE/QUOTE form, checking that both of them are alphanumeric.
+func joinPair(base, quote string) (string, error) {
+	if !isAsset(base) || !isAsset(quote) {
+		return "", ErrInvalidPair
+	}
+
+	return base + "/" + quote, nil
+}
+
+// isAsset reports whether the name of the asset is not empty and consists of letters and digits only.
+func isAsset(asset string) bool {
+	if asset == "" {
+		return false
+	}
+
+	for _, r := range asset {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,11 @@
+package models
+
+type PasswordForgot struct {
+	Email string `json:"email" example:"user@example.com"`
+}
+
+type PasswordReset struct {
+	Token             string `json:"token" example:"reset_token"`
+	NewPassword       string `json:"new_password" example:"new_password"`
+	NewPasswordRepeat string `json:"new_password_repeat" example:"new_password"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// PriceSnapshot holds the top of the order book of a pair.
+// The prices of an empty side are zero, the spread and the mid are zero unless both sides have orders.
+type PriceSnapshot struct {
+	Exchange      string  `json:"exchange" example:"binance_spot"`
+	Pair          string  `json:"pair" example:"BTC/USDT"`
+	BestBid       float64 `json:"best_bid" example:"49999.5"`
+	BestAsk       float64 `json:"best_ask" example:"50000.5"`
+	Spread        float64 `json:"spread" example:"1"`             // Difference between the best ask and the best bid
+	SpreadPercent float64 `json:"spread_percent" example:"0.002"` // Spread in percent of the mid price
+	Mid           float64 `json:"mid" example:"50000"`            // Average of the best ask and the best bid
+}
@@ -2,4 +2,21 @@ package models
 
 type Response struct {
 	Result string `json:"result"`
+	Code   string `json:"code,omitempty" example:"validation_failed"` // Machine-readable code of the error, empty on success
 }
+
+// Codes of the error responses. They are stable, so the clients can tell the failures apart
+// without parsing the messages, which may change.
+const (
+	CodeInvalidInput       = "invalid_input"       // The request body or the query can't be parsed
+	CodeValidationFailed   = "validation_failed"   // The data fails one of the checks of the service
+	CodeInvalidCredentials = "invalid_credentials" // The email or the password is wrong
+	CodeInvalidToken       = "invalid_token"       // The token is invalid, expired or already used
+	CodeNotFound           = "not_found"           // The requested entity doesn't exist
+	CodeConflict           = "conflict"            // The entity already exists or is in the requested state
+	CodePairNotListed      = "pair_not_listed"     // The exchange doesn't offer the pair
+	CodePairsLimitReached  = "pairs_limit_reached" // The user already has the maximum number of pairs
+	CodeUnavailable        = "unavailable"         // The request can't be served yet and may be repeated later
+	CodeUpgradeRequired    = "upgrade_required"    // The request isn't a websocket upgrade
+	CodeInternal           = "internal_error"      // The request failed on the server, the details are only logged
+)
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/matthewhartstonge/argon2"
+)
+
+// Session is a login of the user on a device. Every login starts a new session, so the user can stay logged in
+// on several devices at once. The access and refresh tokens are bound to the session by its ID,
+// so revoking the session invalidates them.
+type Session struct {
+	ID           int       `json:"id" db:"id" example:"1234"` // Unique among the sessions of the user
+	UserID       int       `json:"-" db:"user_id"`
+	RefreshToken []byte    `json:"-" db:"refresh_token"`                             // Salted argon2 hash of the refresh token of the session
+	UserAgent    string    `json:"user_agent" db:"user_agent" example:"Mozilla/5.0"` // User agent of the client which logged in
+	CreatedAt    time.Time `json:"created_at" db:"created_at" example:"2024-08-01T12:00:00Z"`
+	LastSeenAt   time.Time `json:"last_seen_at" db:"last_seen_at" example:"2024-08-02T12:00:00Z"` // Time of the last authenticated request of the session
+	Current      bool      `json:"current" db:"-" example:"true"`                                 // Whether the session made the request listing the sessions
+}
+
+// SetRefreshToken stores the salted argon2 hash of the refresh token, so the token itself is never persisted.
+func (s *Session) SetRefreshToken(refreshToken string) error {
+	hashedToken, err := argon.HashEncoded([]byte(refreshToken))
+	s.RefreshToken = hashedToken
+
+	return err
+}
+
+// CompareRefreshToken checks the refresh token against the stored hash.
+// The hashes are compared in constant time, so the comparison doesn't reveal how much of the token matched.
+func (s *Session) CompareRefreshToken(refreshToken string) error {
+	if len(s.RefreshToken) == 0 {
+		return errors.New("refresh token wasn't set")
+	}
+
+	ok, err := argon2.VerifyEncoded([]byte(refreshToken), s.RefreshToken)
+	if !ok {
+		return errors.New("comparison refresh tokens failed")
+	}
+
+	return err
+}
@@ -3,5 +3,5 @@ package models
 type Tokens struct {
 	Access    string `json:"access"`
 	Refresh   string `json:"refresh"`
-	ExpiresAt int64  `json:"expires_at"`
+	ExpiresAt int64  `json:"expires_at"` // Unix time in seconds the access token expires at
 }
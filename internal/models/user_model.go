@@ -9,14 +9,22 @@ import (
 
 var argon = argon2.DefaultConfig()
 
+// Roles of the users. Every user signs up with RoleUser, admins are promoted by the operator in the database.
+const (
+	RoleUser  = "user"  // Regular user managing their own pairs
+	RoleAdmin = "admin" // Operator allowed to access the admin endpoints
+)
+
 type User struct {
-	ID           int
-	SessionID    int `db:"session_id"`
-	Email        string
-	RefreshToken []byte `db:"refresh_token"`
-	Password     []byte
-	CreatedAt    time.Time `json:"-" db:"created_at" default:"now()" `
-	UpdatedAt    time.Time `json:"-" db:"updated_at" default:"now()"`
+	ID              int
+	PasswordVersion int `db:"password_version"` // Changed with the password, so the password reset tokens issued before it are rejected
+	Email           string
+	Password        []byte
+	Verified        bool      `db:"verified"`      // Whether the email of the user is verified, unverified users can't access their account
+	PendingEmail    string    `db:"pending_email"` // New email awaiting the confirmation by the link sent to it, empty if no change was requested
+	Role            string    `db:"role"`          // Role of the user, RoleUser or RoleAdmin
+	CreatedAt       time.Time `json:"-" db:"created_at" default:"now()" `
+	UpdatedAt       time.Time `json:"-" db:"updated_at" default:"now()"`
 }
 
 func (u *User) SetPassword(password string) error {
@@ -26,13 +34,6 @@ func (u *User) SetPassword(password string) error {
 	return err
 }
 
-func (u *User) SetRefreshToken(refreshToken string) error {
-	hashedToken, err := argon.HashEncoded([]byte(refreshToken))
-	u.RefreshToken = hashedToken
-
-	return err
-}
-
 func (u *User) ComparePassword(password string) error {
 	ok, err := argon2.VerifyEncoded([]byte(password), u.Password)
 	if !ok {
@@ -41,12 +42,3 @@ func (u *User) ComparePassword(password string) error {
 
 	return err
 }
-
-func (u *User) CompareRefreshToken(refreshToken string) error {
-	ok, err := argon2.VerifyEncoded([]byte(refreshToken), u.RefreshToken)
-	if !ok {
-		return errors.New("comparison refresh tokens failed")
-	}
-
-	return err
-}
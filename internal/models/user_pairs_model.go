@@ -1,8 +1,154 @@
 package models
 
+// SearchMode defines how the volumes of a user pair are searched in the order book.
+type SearchMode string
+
+const (
+	SearchModeExact      SearchMode = "exact"      // Finds levels whose volume is at least the exact value, or within the tolerance of it
+	SearchModeRelative   SearchMode = "relative"   // Finds levels whose volume stands out from the surrounding levels
+	SearchModeCumulative SearchMode = "cumulative" // Finds bands of adjacent levels whose total volume is at least the exact value
+)
+
+// Sides of the order book whose found volumes are recorded for a user pair.
+const (
+	SideAsks = "asks" // Only the sell orders, e.g. the resistance levels
+	SideBids = "bids" // Only the buy orders, e.g. the support levels
+	SideBoth = "both" // Both sides of the order book
+)
+
 type UserPairs struct {
-	UserID     int     `json:"-" db:"user_id"`
-	Exchange   string  `json:"exchange" example:"binance_spot"`
-	Pair       string  `json:"pair" example:"BTC/USDT"`
-	ExactValue float64 `json:"exact_value" db:"exact_value" example:"3"`
+	UserID     int        `json:"-" db:"user_id"`
+	Exchange   string     `json:"exchange" example:"binance_spot"`
+	Pair       string     `json:"pair" example:"BTC/USDT"`
+	ExactValue float64    `json:"exact_value" db:"exact_value" example:"3"`                                                 // In the relative mode it is the minimum volume of a found level, in the cumulative mode the minimum total of a band
+	SearchMode SearchMode `json:"search_mode,omitempty" db:"search_mode" enums:"exact,relative,cumulative" example:"exact"` // Empty value means the exact mode
+	Multiplier float64    `json:"multiplier,omitempty" db:"multiplier" example:"5"`                                         // How many times a level must exceed the median of its neighbours in the relative mode
+	Window     int        `json:"window,omitempty" db:"window_size" example:"10"`                                           // Number of neighbour levels on each side compared in the relative mode
+	Tolerance  float64    `json:"tolerance,omitempty" db:"tolerance" example:"5"`                                           // Percent of the exact value a level volume may deviate by in the exact mode, zero finds every level of at least the exact value
+	Side       string     `json:"side,omitempty" db:"side" enums:"asks,bids,both" example:"both"`                           // Side of the order book whose found volumes are recorded, empty value means both sides
+	PriceRange float64    `json:"price_range,omitempty" db:"price_range" example:"0.5"`                                     // Width of the bands summed in the cumulative mode in percent of the price of their lowest level
+}
+
+// IsRelative reports whether the volumes of the pair are searched relative to the surrounding levels.
+func (up UserPairs) IsRelative() bool {
+	return up.SearchMode == SearchModeRelative
+}
+
+// IsCumulative reports whether the volumes of the pair are searched as the total volume of the levels of price bands.
+func (up UserPairs) IsCumulative() bool {
+	return up.SearchMode == SearchModeCumulative
+}
+
+// WatchesSide reports whether the volumes found on the side of the order book, asks or bids, are recorded for the pair.
+func (up UserPairs) WatchesSide(side string) bool {
+	return up.Side == "" || up.Side == SideBoth || up.Side == side
+}
+
+// UserPairsPagination selects a page of the pairs of a user. A zero limit uses the default page size.
+type UserPairsPagination struct {
+	Limit  int `query:"limit" example:"50"` // Maximum number of pairs of the page
+	Offset int `query:"offset" example:"0"` // Number of pairs skipped before the page
+}
+
+// UserPairsPage is a page of the pairs of a user sorted by exchange and pair.
+type UserPairsPage struct {
+	Pairs  []UserPairs `json:"pairs"`               // Pairs of the page, empty past the last pair
+	Total  int         `json:"total" example:"120"` // Number of all pairs of the user
+	Limit  int         `json:"limit" example:"50"`  // Maximum number of pairs of the page
+	Offset int         `json:"offset" example:"0"`  // Number of pairs skipped before the page
+}
+
+// UserPairsReplaceResult is the difference between the stored pairs of a user and the pairs replacing them.
+type UserPairsReplaceResult struct {
+	Added   []UserPairs `json:"added"`   // Pairs which weren't stored
+	Updated []UserPairs `json:"updated"` // Stored pairs whose search settings changed, with the new settings
+	Removed []UserPairs `json:"removed"` // Stored pairs missing from the replacing pairs
+}
+
+// userPairKey identifies a pair of a user, a user has a pair of an exchange once.
+type userPairKey struct {
+	exchange string
+	pair     string
+}
+
+// DiffUserPairs computes the changes turning the stored pairs of a user into the desired ones.
+// The pairs are matched by the exchange and the pair, a matched pair is updated if any of its search settings differ.
+// The added and updated pairs are in the order of the desired pairs, the removed ones in the order of the stored pairs.
+//
+// Parameters:
+//   - stored: The pairs the user has.
+//   - desired: The pairs the user should have, each of them at most once.
+//
+// Returns:
+//   - The added, updated and removed pairs, empty rather than nil if there are none.
+func DiffUserPairs(stored, desired []UserPairs) UserPairsReplaceResult {
+	diff := UserPairsReplaceResult{Added: []UserPairs{}, Updated: []UserPairs{}, Removed: []UserPairs{}}
+
+	storedByKey := make(map[userPairKey]UserPairs, len(stored))
+	for _, pairData := range stored {
+		storedByKey[userPairKey{pairData.Exchange, pairData.Pair}] = pairData
+	}
+
+	desiredKeys := make(map[userPairKey]bool, len(desired))
+	for _, pairData := range desired {
+		key := userPairKey{pairData.Exchange, pairData.Pair}
+		desiredKeys[key] = true
+
+		storedPair, ok := storedByKey[key]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, pairData)
+		case !sameSearchSettings(storedPair, pairData):
+			diff.Updated = append(diff.Updated, pairData)
+		}
+	}
+
+	for _, pairData := range stored {
+		if !desiredKeys[userPairKey{pairData.Exchange, pairData.Pair}] {
+			diff.Removed = append(diff.Removed, pairData)
+		}
+	}
+
+	return diff
+}
+
+// sameSearchSettings reports whether the pairs are searched the same way,
+// an empty search mode is the exact one and an empty side is both sides.
+func sameSearchSettings(a, b UserPairs) bool {
+	if a.SearchMode == "" {
+		a.SearchMode = SearchModeExact
+	}
+	if b.SearchMode == "" {
+		b.SearchMode = SearchModeExact
+	}
+	if a.Side == "" {
+		a.Side = SideBoth
+	}
+	if b.Side == "" {
+		b.Side = SideBoth
+	}
+
+	return a.ExactValue == b.ExactValue &&
+		a.SearchMode == b.SearchMode &&
+		a.Multiplier == b.Multiplier &&
+		a.Window == b.Window &&
+		a.Tolerance == b.Tolerance &&
+		a.Side == b.Side &&
+		a.PriceRange == b.PriceRange
+}
+
+// UserPairsBulkResult is the outcome of adding one of the pairs of a bulk request.
+type UserPairsBulkResult struct {
+	Exchange string `json:"exchange" example:"binance_spot"`
+	Pair     string `json:"pair" example:"BTC/USDT"`
+	Added    bool   `json:"added" example:"true"`
+	Error    string `json:"error,omitempty" example:"invalid pair name format"` // Reason the pair wasn't added
+	Code     string `json:"code,omitempty" example:"validation_failed"`         // Machine-readable code of the reason
+}
+
+// UserPairsImportResult is the summary of an import of the user pairs.
+type UserPairsImportResult struct {
+	Imported int                   `json:"imported" example:"2"` // Number of the added pairs
+	Skipped  int                   `json:"skipped" example:"1"`  // Number of the pairs which weren't added
+	Pairs    []UserPairsBulkResult `json:"pairs"`                // Outcome of every pair in the order of the file
 }
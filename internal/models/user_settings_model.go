@@ -0,0 +1,16 @@
+package models
+
+// UserSettings holds the notification preferences of a user.
+// A user who has never saved the settings gets the zero value, so every found volume is notified about.
+type UserSettings struct {
+	UserID          int     `json:"-" db:"user_id"`
+	NotifyTelegram  bool    `json:"notify_telegram" db:"notify_telegram" example:"false"`
+	NotifyEmail     bool    `json:"notify_email" db:"notify_email" example:"true"`
+	MinVolumeNotify float64 `json:"min_volume_notify" db:"min_volume_notify" example:"100"`               // Found volumes below it are not notified about
+	WebhookUrl      string  `json:"webhook_url" db:"webhook_url" example:"https://example.com/hooks/cvs"` // The found volumes are posted to it, empty to disable the webhook
+	WebhookSecret   string  `json:"webhook_secret" db:"webhook_secret" example:"secret"`                  // Key of the HMAC-SHA256 signature of the posted body
+
+	// Time in seconds after an alert during which the same pair of the same exchange doesn't alert the user again,
+	// so a flickering volume level doesn't flood the channels. Zero uses the cooldown configured for the service.
+	AlertCooldownSeconds int `json:"alert_cooldown_seconds" db:"alert_cooldown_seconds" example:"300"`
+}
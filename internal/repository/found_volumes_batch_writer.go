@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"cvs/internal/models"
+	"cvs/internal/service/logger"
+)
+
+// BatchedFoundVolumesRepository is a FoundVolumesRepository buffering the upserts and deletes of the found volumes
+// and flushing them to the database at once, so a scan doesn't make a query per found volume.
+type BatchedFoundVolumesRepository interface {
+	FoundVolumesRepository
+	Start(ctx context.Context)       // Method to start flushing the buffered writes periodically
+	Flush(ctx context.Context) error // Method to write the buffered writes to the database at once
+}
+
+// batchedFoundVolumesRepository is a concrete implementation of the BatchedFoundVolumesRepository interface.
+// It coalesces the writes of the same found volume, so only the last write of every found volume is flushed.
+type batchedFoundVolumesRepository struct {
+	repository    FoundVolumesRepository // Repository the buffered writes are flushed to
+	flushInterval time.Duration          // Time between the flushes, zero writes every found volume through
+	logger        logger.Logger
+
+	mu      sync.Mutex                         // Guards the pending writes
+	pending map[string]pendingFoundVolumeWrite // Last buffered write of every found volume keyed by user, pair, exchange and side
+
+	flushMu sync.Mutex // Serializes the flushes, so the writes of a found volume reach the database in order
+}
+
+// pendingFoundVolumeWrite is a buffered write of a found volume.
+type pendingFoundVolumeWrite struct {
+	foundVolume models.FoundVolume // Found volume with the ID of its user
+	delete      bool               // Whether the found volume is deleted rather than upserted
+}
+
+// NewBatchedFoundVolumesRepository creates a new instance of batchedFoundVolumesRepository.
+//
+// Parameters:
+//   - repository: The repository the buffered writes are flushed to.
+//   - flushInterval: The time the writes are buffered for. A non-positive interval disables the buffering,
+//     so every write goes to the repository directly.
+//   - logger: The logger of the failed periodic flushes.
+//
+// Returns:
+//   - An instance of BatchedFoundVolumesRepository. Start must be called for the buffered writes to be flushed periodically,
+//     and Flush on shutdown, so the writes buffered since the last flush aren't lost.
+func NewBatchedFoundVolumesRepository(
+	repository FoundVolumesRepository,
+	flushInterval time.Duration,
+	logger logger.Logger,
+) BatchedFoundVolumesRepository {
+	return &batchedFoundVolumesRepository{
+		repository:    repository,
+		flushInterval: flushInterval,
+		logger:        logger,
+		pending:       make(map[string]pendingFoundVolumeWrite),
+	}
+}
+
+// Upsert buffers the upsert of the found volume of the user until the next flush,
+// replacing the previous buffered write of the same found volume.
+// Without buffering the found volume is upserted directly.
+func (b *batchedFoundVolumesRepository) Upsert(ctx context.Context, userID int, foundVolume models.FoundVolume) error {
+	if b.flushInterval <= 0 {
+		return b.repository.Upsert(ctx, userID, foundVolume)
+	}
+
+	b.buffer(userID, foundVolume, false)
+
+	return nil
+}
+
+// Delete buffers the delete of the found volume of the user until the next flush,
+// replacing the previous buffered write of the same found volume, e.g. its upsert.
+// Without buffering the found volume is deleted directly.
+func (b *batchedFoundVolumesRepository) Delete(ctx context.Context, userID int, foundVolume models.FoundVolume) error {
+	if b.flushInterval <= 0 {
+		return b.repository.Delete(ctx, userID, foundVolume)
+	}
+
+	b.buffer(userID, foundVolume, true)
+
+	return nil
+}
+
+// GetByUser flushes the buffered writes, so they aren't missing from the result, and retrieves the found volumes of the user.
+func (b *batchedFoundVolumesRepository) GetByUser(ctx context.Context, userID int) ([]models.FoundVolume, error) {
+	if err := b.Flush(ctx); err != nil {
+		return nil, err
+	}
+
+	return b.repository.GetByUser(ctx, userID)
+}
+
+// WriteBatch flushes the buffered writes, so they are applied before the batch, and writes the batch to the repository.
+func (b *batchedFoundVolumesRepository) WriteBatch(ctx context.Context, upserts, deletes []models.FoundVolume) error {
+	if err := b.Flush(ctx); err != nil {
+		return err
+	}
+
+	return b.repository.WriteBatch(ctx, upserts, deletes)
+}
+
+// Start flushes the buffered writes every flush interval until the context is cancelled.
+// A failed flush is logged, and its writes are flushed again by the next one unless they were replaced meanwhile.
+// Nothing is started if the buffering is disabled.
+func (b *batchedFoundVolumesRepository) Start(ctx context.Context) {
+	if b.flushInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(b.flushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := b.Flush(ctx); err != nil {
+					b.logger.Error(err)
+				}
+			}
+		}
+	}()
+}
+
+// Flush writes all buffered writes to the repository by one batch.
+//
+// If the batch fails, its writes are buffered again, except for the found volumes written again meanwhile,
+// whose newer writes are kept. The error of the batch is returned.
+func (b *batchedFoundVolumesRepository) Flush(ctx context.Context) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	writes := b.pending
+	b.pending = make(map[string]pendingFoundVolumeWrite)
+	b.mu.Unlock()
+
+	if len(writes) == 0 {
+		return nil // Nothing was written since the last flush
+	}
+
+	var upserts, deletes []models.FoundVolume
+	for _, write := range writes {
+		if write.delete {
+			deletes = append(deletes, write.foundVolume)
+		} else {
+			upserts = append(upserts, write.foundVolume)
+		}
+	}
+
+	err := b.repository.WriteBatch(ctx, upserts, deletes)
+	if err != nil {
+		b.mu.Lock()
+		for key, write := range writes {
+			if _, replaced := b.pending[key]; !replaced {
+				b.pending[key] = write // Retry the write by the next flush
+			}
+		}
+		b.mu.Unlock()
+	}
+
+	return err
+}
+
+// buffer replaces the buffered write of the found volume of the user.
+func (b *batchedFoundVolumesRepository) buffer(userID int, foundVolume models.FoundVolume, delete bool) {
+	foundVolume.UserID = userID
+	key := strconv.Itoa(userID) + "|" + foundVolume.Pair + "|" + foundVolume.Exchange + "|" + foundVolume.Side
+
+	b.mu.Lock()
+	b.pending[key] = pendingFoundVolumeWrite{foundVolume: foundVolume, delete: delete}
+	b.mu.Unlock()
+}
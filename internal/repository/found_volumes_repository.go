@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"cvs/internal/models" // Importing domain models for found volumes
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx" // Importing sqlx for database interactions
+)
+
+// FoundVolumesRepository defines the interface for operations related to found volumes.
+// It includes methods for upserting, retrieving, and deleting found volumes of users.
+type FoundVolumesRepository interface {
+	Upsert(ctx context.Context, userID int, foundVolume models.FoundVolume) error // Method to insert or update a found volume of a user
+	GetByUser(ctx context.Context, userID int) ([]models.FoundVolume, error)      // Method to retrieve all found volumes of a user
+	Delete(ctx context.Context, userID int, foundVolume models.FoundVolume) error // Method to delete a found volume of a user
+	WriteBatch(ctx context.Context, upserts, deletes []models.FoundVolume) error  // Method to upsert and delete the found volumes of several users in one transaction
+}
+
+const (
+	foundVolumesBatchColumns = 13   // Number of columns written for every found volume upserted by a batch
+	foundVolumesBatchRows    = 1000 // Maximum number of found volumes upserted by one statement, which keeps its parameters below the limit of Postgres
+)
+
+// foundVolumesRepository is a concrete implementation of the FoundVolumesRepository interface.
+// It holds a reference to the database connection.
+type foundVolumesRepository struct {
+	db *sqlx.DB // Database connection
+}
+
+// NewFoundVolumesRepository creates a new instance of foundVolumesRepository.
+// It initializes the repository with a database connection.
+//
+// Parameters:
+//   - db: The database connection to be used by the repository.
+//
+// Returns:
+//   - An instance of FoundVolumesRepository.
+func NewFoundVolumesRepository(db *sqlx.DB) FoundVolumesRepository {
+	return &foundVolumesRepository{db} // Return a new instance of foundVolumesRepository
+}
+
+// Upsert inserts a found volume of the user into the database or updates it if a volume with the same
+// pair, exchange and side already exists for the user.
+// It takes context, user ID and found volume as parameters and returns an error if any occurs.
+func (fvr *foundVolumesRepository) Upsert(ctx context.Context, userID int, foundVolume models.FoundVolume) error {
+	const op = directoryPath + "found_volumes_repository.Upsert" // Operation name for logging
+
+	queryString := fmt.Sprintf(`
+		INSERT INTO %s (
+			user_id,
+			exchange,
+			pair,
+			side,
+			price,
+			volume_index,
+			difference,
+			volume,
+			volume_time_found,
+			last_price,
+			distance_from_last,
+			band_low,
+			band_high
+		)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT ON CONSTRAINT found_volumes_unique_key DO UPDATE
+		SET price=EXCLUDED.price,
+			volume_index=EXCLUDED.volume_index,
+			difference=EXCLUDED.difference,
+			volume=EXCLUDED.volume,
+			volume_time_found=EXCLUDED.volume_time_found,
+			last_price=EXCLUDED.last_price,
+			distance_from_last=EXCLUDED.distance_from_last,
+			band_low=EXCLUDED.band_low,
+			band_high=EXCLUDED.band_high;
+	`, foundVolumesTable) // SQL query string for upserting data
+
+	_, err := fvr.db.ExecContext(
+		ctx,
+		queryString,
+		userID,
+		foundVolume.Exchange,
+		foundVolume.Pair,
+		foundVolume.Side,
+		foundVolume.Price,
+		foundVolume.Index,
+		foundVolume.Difference,
+		foundVolume.Volume,
+		foundVolume.VolumeTimeFound,
+		foundVolume.LastPrice,
+		foundVolume.DistanceFromLast,
+		foundVolume.BandLow,
+		foundVolume.BandHigh,
+	) // Execute the SQL query with provided parameters
+	if err != nil {
+		return repoError(op) // Return wrapped error
+	}
+
+	return nil // Return nil if no errors occurred
+}
+
+// GetByUser retrieves all found volumes associated with a given user ID from the database.
+// It takes context and user ID as parameters and returns a slice of FoundVolume and an error if any occurs.
+func (fvr *foundVolumesRepository) GetByUser(ctx context.Context, userID int) ([]models.FoundVolume, error) {
+	const op = directoryPath + "found_volumes_repository.GetByUser" // Operation name for logging
+	var foundVolumes []models.FoundVolume                           // Slice to hold retrieved found volumes
+
+	queryString := fmt.Sprintf(`
+		SELECT exchange, pair, side, price, volume_index, difference, volume, volume_time_found, last_price, distance_from_last, band_low, band_high
+		FROM %s WHERE user_id=$1;
+	`, foundVolumesTable) // SQL query string for selecting data
+
+	err := fvr.db.SelectContext(ctx, &foundVolumes, queryString, userID) // Execute the SQL query and scan results into the slice
+	if err != nil {
+		return foundVolumes, repoError(op) // Return empty slice and wrapped error
+	}
+
+	return foundVolumes, nil // Return retrieved found volumes and nil if no errors occurred
+}
+
+// Delete removes the found volume of the user identified by its pair, exchange and side from the database.
+// Deleting a volume which doesn't exist is not an error.
+// It takes context, user ID and found volume as parameters and returns an error if any occurs.
+func (fvr *foundVolumesRepository) Delete(ctx context.Context, userID int, foundVolume models.FoundVolume) error {
+	const op = directoryPath + "found_volumes_repository.Delete" // Operation name for logging
+
+	queryString := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE user_id=$1 AND pair=$2 AND exchange=$3 AND side=$4
+	`, foundVolumesTable) // SQL query string for deleting data
+
+	_, err := fvr.db.ExecContext(
+		ctx,
+		queryString,
+		userID,
+		foundVolume.Pair,
+		foundVolume.Exchange,
+		foundVolume.Side,
+	) // Execute the SQL query with provided parameters
+	if err != nil {
+		return repoError(op) // Return wrapped error
+	}
+
+	return nil // Return nil if no errors occurred
+}
+
+// WriteBatch upserts and deletes the found volumes of several users in one transaction.
+// The user of every found volume is taken from its UserID.
+//
+// The deletes are executed first, then the upserts are inserted by multi-row statements of up to foundVolumesBatchRows
+// found volumes. The found volumes of the users which no longer exist are skipped, so a user deleted while
+// the writes were buffered doesn't fail the whole batch. A found volume must appear at most once in the upserts.
+// It takes context, found volumes to upsert and found volumes to delete as parameters and returns an error if any occurs,
+// in which case none of the writes is applied.
+func (fvr *foundVolumesRepository) WriteBatch(ctx context.Context, upserts, deletes []models.FoundVolume) error {
+	const op = directoryPath + "found_volumes_repository.WriteBatch" // Operation name for logging
+	errFn := repoError(op)                                           // Error handling function
+
+	if len(upserts) == 0 && len(deletes) == 0 {
+		return nil // Nothing to write
+	}
+
+	tx, err := fvr.db.BeginTxx(ctx, nil) // Start the transaction all writes are applied in
+	if err != nil {
+		return errFn
+	}
+	defer tx.Rollback() // Roll back the transaction unless it was committed
+
+	deleteQuery := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE user_id=$1 AND pair=$2 AND exchange=$3 AND side=$4
+	`, foundVolumesTable) // SQL query string for deleting data
+
+	for _, foundVolume := range deletes {
+		if _, err := tx.ExecContext(ctx, deleteQuery, foundVolume.UserID, foundVolume.Pair, foundVolume.Exchange, foundVolume.Side); err != nil {
+			return errFn
+		}
+	}
+
+	for start := 0; start < len(upserts); start += foundVolumesBatchRows {
+		rows := upserts[start:min(start+foundVolumesBatchRows, len(upserts))]
+
+		if _, err := tx.ExecContext(ctx, upsertBatchQuery(len(rows)), upsertBatchArgs(rows)...); err != nil {
+			return errFn
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errFn
+	}
+
+	return nil // Return nil if no errors occurred
+}
+
+// upsertBatchQuery returns the statement upserting the number of found volumes at once.
+// The values are cast explicitly, as their types can't be inferred from the columns inside the VALUES list.
+func upsertBatchQuery(rows int) string {
+	const rowPlaceholders = "($%d::integer, $%d::varchar, $%d::varchar, $%d::varchar, $%d::double precision, $%d::integer, " +
+		"$%d::double precision, $%d::double precision, $%d::timestamp, $%d::double precision, $%d::double precision, " +
+		"$%d::double precision, $%d::double precision)"
+
+	values := make([]string, 0, rows)
+	for row := range rows {
+		placeholders := make([]interface{}, 0, foundVolumesBatchColumns)
+		for column := 1; column <= foundVolumesBatchColumns; column++ {
+			placeholders = append(placeholders, row*foundVolumesBatchColumns+column)
+		}
+
+		values = append(values, fmt.Sprintf(rowPlaceholders, placeholders...))
+	}
+
+	return fmt.Sprintf(`
+		INSERT INTO %[1]s (
+			user_id,
+			exchange,
+			pair,
+			side,
+			price,
+			volume_index,
+			difference,
+			volume,
+			volume_time_found,
+			last_price,
+			distance_from_last,
+			band_low,
+			band_high
+		)
+		SELECT v.* FROM (VALUES %[2]s) AS v(
+			user_id,
+			exchange,
+			pair,
+			side,
+			price,
+			volume_index,
+			difference,
+			volume,
+			volume_time_found,
+			last_price,
+			distance_from_last,
+			band_low,
+			band_high
+		)
+		WHERE EXISTS (SELECT 1 FROM %[3]s WHERE id=v.user_id)
+		ON CONFLICT ON CONSTRAINT found_volumes_unique_key DO UPDATE
+		SET price=EXCLUDED.price,
+			volume_index=EXCLUDED.volume_index,
+			difference=EXCLUDED.difference,
+			volume=EXCLUDED.volume,
+			volume_time_found=EXCLUDED.volume_time_found,
+			last_price=EXCLUDED.last_price,
+			distance_from_last=EXCLUDED.distance_from_last,
+			band_low=EXCLUDED.band_low,
+			band_high=EXCLUDED.band_high;
+	`, foundVolumesTable, strings.Join(values, ", "), userTable) // SQL query string for upserting data
+}
+
+// upsertBatchArgs returns the arguments of the statement returned by upsertBatchQuery in the order of its columns.
+func upsertBatchArgs(foundVolumes []models.FoundVolume) []interface{} {
+	args := make([]interface{}, 0, len(foundVolumes)*foundVolumesBatchColumns)
+	for _, foundVolume := range foundVolumes {
+		args = append(args,
+			foundVolume.UserID,
+			foundVolume.Exchange,
+			foundVolume.Pair,
+			foundVolume.Side,
+			foundVolume.Price,
+			foundVolume.Index,
+			foundVolume.Difference,
+			foundVolume.Volume,
+			foundVolume.VolumeTimeFound,
+			foundVolume.LastPrice,
+			foundVolume.DistanceFromLast,
+			foundVolume.BandLow,
+			foundVolume.BandHigh,
+		)
+	}
+
+	return args
+}
@@ -1,13 +1,45 @@
 package repository
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
 
 const (
-	userTable      = "users"
-	userPairsTable = "user_pairs"
-	directoryPath  = "internal.repository."
+	userTable         = "users"
+	userPairsTable    = "user_pairs"
+	foundVolumesTable = "found_volumes"
+	userSettingsTable = "user_settings"
+	userSessionsTable = "user_sessions"
+	directoryPath     = "internal.repository."
+
+	uniqueViolationCode = "23505" // Postgres error code of a violated unique constraint
 )
 
+// ErrEmailAlreadyExists is returned when a user is inserted with the email of another user.
+// It is exported, so the handlers can tell a duplicate signup from the failures of the database.
+var ErrEmailAlreadyExists = errors.New("user with this email already exists")
+
+// ErrPendingEmailNotFound is returned when an email is confirmed which isn't the pending email of the user,
+// e.g. because another email was requested after it or it was already confirmed.
+var ErrPendingEmailNotFound = errors.New("email change wasn't requested or was superseded")
+
+// ErrSessionNotFound is returned when a session doesn't exist or belongs to another user,
+// e.g. because it was revoked or the user logged out of it.
+var ErrSessionNotFound = errors.New("session not found")
+
+// ErrUserPairNotFound is returned when the user has no pair of the exchange with the name.
+var ErrUserPairNotFound = errors.New("pair not found")
+
 var repoError = func(op string) error {
 	return fmt.Errorf("something went wrong in %s", op)
 }
+
+// isUniqueViolation reports whether the error is a Postgres unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode
+}
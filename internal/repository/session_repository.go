@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"cvs/internal/models" // Importing domain models for sessions
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx" // Importing sqlx for database interactions
+)
+
+// SessionRepository defines the interface for operations related to the sessions of users.
+// It includes methods for starting, retrieving, refreshing and revoking sessions.
+type SessionRepository interface {
+	InsertSession(ctx context.Context, session models.Session) error                                   // Method to insert a new session
+	GetSession(ctx context.Context, userID, sessionID int) (models.Session, error)                     // Method to retrieve a session of a user
+	GetUserSessions(ctx context.Context, userID int) ([]models.Session, error)                         // Method to retrieve all sessions of a user
+	RotateRefreshToken(ctx context.Context, session models.Session, previousRefreshToken []byte) error // Method to replace a session's refresh token only if it wasn't rotated yet
+	TouchSession(ctx context.Context, userID, sessionID int) error                                     // Method to update the time a session was last seen at
+	DeleteSession(ctx context.Context, userID, sessionID int) error                                    // Method to delete a session of a user
+	DeleteExpiredSessions(ctx context.Context, ttl time.Duration) (int64, error)                       // Method to delete the sessions not seen for longer than the TTL
+	DeleteUserSessions(ctx context.Context, userID int) error                                          // Method to delete all sessions of a user
+}
+
+// sessionRepository is a concrete implementation of the SessionRepository interface.
+// It holds a reference to the database connection.
+type sessionRepository struct {
+	db *sqlx.DB // Database connection
+}
+
+// NewSessionRepository creates a new instance of sessionRepository.
+// It initializes the repository with a database connection.
+//
+// Parameters:
+//   - db: The database connection to be used by the repository.
+//
+// Returns:
+//   - An instance of SessionRepository.
+func NewSessionRepository(db *sqlx.DB) SessionRepository {
+	return &sessionRepository{db} // Return a new instance of sessionRepository
+}
+
+// insertSessionQuery is the SQL query string for inserting a session, shared with the signup transaction.
+var insertSessionQuery = fmt.Sprintf(`
+	INSERT INTO %s (
+		id,
+		user_id,
+		refresh_token,
+		user_agent
+	)
+	values ($1, $2, $3, $4);
+`, userSessionsTable)
+
+// InsertSession inserts a new session into the database.
+// The created and last seen times of the session are set by the database.
+// It returns an error if the session can't be inserted, e.g. because the user already has a session with the ID.
+func (sr *sessionRepository) InsertSession(ctx context.Context, session models.Session) error {
+	const op = directoryPath + "session_repository.InsertSession" // Operation name for logging
+
+	_, err := sr.db.ExecContext(
+		ctx,
+		insertSessionQuery,
+		session.ID,
+		session.UserID,
+		session.RefreshToken,
+		session.UserAgent,
+	) // Execute the SQL query with provided parameters
+	if err != nil {
+		return repoError(op) // Return wrapped error
+	}
+
+	return nil // Return nil if no errors occurred
+}
+
+// GetSession retrieves a session of the user from the database.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - userID: The ID of the user the session belongs to.
+//   - sessionID: The ID of the session.
+//
+// Returns:
+//   - The session.
+//   - ErrSessionNotFound if the user has no session with the ID, an error if any other error occurs.
+func (sr *sessionRepository) GetSession(ctx context.Context, userID, sessionID int) (models.Session, error) {
+	const op = directoryPath + "session_repository.GetSession" // Operation name for logging
+	var session models.Session                                 // Variable to hold retrieved session
+
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE user_id=$1 AND id=$2;`, userSessionsTable) // SQL query string for selecting data
+
+	err := sr.db.GetContext(ctx, &session, query, userID, sessionID) // Execute the SQL query and scan the result into the session
+	if errors.Is(err, sql.ErrNoRows) {
+		return session, ErrSessionNotFound
+	}
+	if err != nil {
+		return session, repoError(op) // Return empty session and wrapped error
+	}
+
+	return session, nil // Return retrieved session and nil if no errors occurred
+}
+
+// GetUserSessions retrieves all sessions of the user from the database, the most recently seen first.
+// It returns an empty slice if the user has no sessions and an error if any occurs.
+func (sr *sessionRepository) GetUserSessions(ctx context.Context, userID int) ([]models.Session, error) {
+	const op = directoryPath + "session_repository.GetUserSessions" // Operation name for logging
+	sessions := []models.Session{}                                  // Slice to hold retrieved sessions
+
+	query := fmt.Sprintf(`
+		SELECT * FROM %s
+		WHERE user_id=$1
+		ORDER BY last_seen_at DESC, id;`, userSessionsTable) // SQL query string for selecting data
+
+	err := sr.db.SelectContext(ctx, &sessions, query, userID) // Execute the SQL query and scan results into the sessions slice
+	if err != nil {
+		return sessions, repoError(op) // Return empty slice and wrapped error
+	}
+
+	return sessions, nil // Return retrieved sessions and nil if no errors occurred
+}
+
+// RotateRefreshToken replaces the refresh token of an existing session in the database
+// only if the stored refresh token hash still equals the previous one.
+// The session is seen at the time of the rotation, since the refresh is a request of it.
+// It returns an error if the token was already rotated, the session was revoked or any other error occurs.
+func (sr *sessionRepository) RotateRefreshToken(ctx context.Context, session models.Session, previousRefreshToken []byte) error {
+	const op = directoryPath + "session_repository.RotateRefreshToken" // Operation name for logging
+
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET refresh_token=$1,
+			last_seen_at=now()
+		WHERE user_id=$2 AND id=$3 AND refresh_token=$4;`, userSessionsTable) // SQL query string for updating data
+
+	rows, err := sr.db.ExecContext(
+		ctx,
+		query,
+		session.RefreshToken,
+		session.UserID,
+		session.ID,
+		previousRefreshToken,
+	) // Execute the SQL query with provided parameters
+	if err != nil {
+		return repoError(op) // Return wrapped error
+	}
+
+	rowsAffected, _ := rows.RowsAffected() // Get the number of rows affected by the update
+	if rowsAffected == 0 {                 // Check if the token was rotated concurrently
+		return repoError(op) // Return wrapped error
+	}
+
+	return nil // Return nil if no errors occurred
+}
+
+// TouchSession sets the time the session was last seen at to the current time.
+// It returns ErrSessionNotFound if the user has no session with the ID and an error if any other error occurs.
+func (sr *sessionRepository) TouchSession(ctx context.Context, userID, sessionID int) error {
+	const op = directoryPath + "session_repository.TouchSession" // Operation name for logging
+
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET last_seen_at=now()
+		WHERE user_id=$1 AND id=$2;`, userSessionsTable) // SQL query string for updating data
+
+	rows, err := sr.db.ExecContext(ctx, query, userID, sessionID) // Execute the SQL query with provided parameters
+	if err != nil {
+		return repoError(op) // Return wrapped error
+	}
+
+	rowsAffected, _ := rows.RowsAffected() // Get the number of rows affected by the update
+	if rowsAffected == 0 {                 // Check if the session exists
+		return ErrSessionNotFound
+	}
+
+	return nil // Return nil if no errors occurred
+}
+
+// DeleteSession removes a session of the user from the database, so the tokens bound to it are no longer accepted.
+// It returns ErrSessionNotFound if the user has no session with the ID and an error if any other error occurs.
+func (sr *sessionRepository) DeleteSession(ctx context.Context, userID, sessionID int) error {
+	const op = directoryPath + "session_repository.DeleteSession" // Operation name for logging
+
+	query := fmt.Sprintf(`
+        DELETE FROM %s
+        WHERE user_id=$1 AND id=$2;`, userSessionsTable) // SQL query string for deleting data
+
+	rows, err := sr.db.ExecContext(ctx, query, userID, sessionID) // Execute the SQL query with provided parameters
+	if err != nil {
+		return repoError(op) // Return wrapped error
+	}
+
+	rowsAffected, _ := rows.RowsAffected() // Get the number of rows affected by the deletion
+	if rowsAffected == 0 {                 // Check if the session exists
+		return ErrSessionNotFound
+	}
+
+	return nil // Return nil if no errors occurred
+}
+
+// DeleteExpiredSessions removes the sessions of all users which weren't seen for longer than the TTL.
+// The time is compared by the database, which sets the time the sessions were last seen at.
+// It returns the number of removed sessions and an error if any occurs.
+func (sr *sessionRepository) DeleteExpiredSessions(ctx context.Context, ttl time.Duration) (int64, error) {
+	const op = directoryPath + "session_repository.DeleteExpiredSessions" // Operation name for logging
+
+	query := fmt.Sprintf(`
+        DELETE FROM %s
+        WHERE last_seen_at < now() - make_interval(secs => $1);`, userSessionsTable) // SQL query string for deleting data
+
+	result, err := sr.db.ExecContext(ctx, query, ttl.Seconds())
+	if err != nil {
+		return 0, repoError(op) // Return wrapped error
+	}
+
+	deleted, _ := result.RowsAffected() // Get the number of removed sessions
+
+	return deleted, nil // Return nil if no errors occurred
+}
+
+// DeleteUserSessions removes all sessions of the user from the database, logging the user out of every device.
+// A user without sessions is not an error. It returns an error if any occurs.
+func (sr *sessionRepository) DeleteUserSessions(ctx context.Context, userID int) error {
+	const op = directoryPath + "session_repository.DeleteUserSessions" // Operation name for logging
+
+	query := fmt.Sprintf(`
+        DELETE FROM %s
+        WHERE user_id=$1;`, userSessionsTable) // SQL query string for deleting data
+
+	if _, err := sr.db.ExecContext(ctx, query, userID); err != nil {
+		return repoError(op) // Return wrapped error
+	}
+
+	return nil // Return nil if no errors occurred
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"cvs/internal/models" // Importing domain models for user pairs
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/jmoiron/sqlx" // Importing sqlx for database interactions
@@ -11,11 +13,19 @@ import (
 // UserPairsRepository defines the interface for operations related to user pairs.
 // It includes methods for adding, updating, retrieving, and deleting user pairs.
 type UserPairsRepository interface {
-	Add(ctx context.Context, pairData models.UserPairs) error                    // Method to add a new user pair
-	UpdateExactValue(ctx context.Context, pairData models.UserPairs) error       // Method to update the exact value of a user pair
-	GetAllUserPairs(ctx context.Context, userID int) ([]models.UserPairs, error) // Method to retrieve all user pairs for a given user ID
-	GetPairsByExchange(ctx context.Context, exchange string) ([]string, error)   // Method to retrieve all pairs for a given exchange name
-	DeletePair(ctx context.Context, pairData models.UserPairs) error             // Method to delete a specific user pair
+	Add(ctx context.Context, pairData models.UserPairs) error                                                          // Method to add a new user pair
+	BulkAdd(ctx context.Context, pairs []models.UserPairs) ([]error, error)                                            // Method to add several user pairs in a single transaction
+	UpdateExactValue(ctx context.Context, pairData models.UserPairs) error                                             // Method to update the exact value of a user pair
+	GetAllUserPairs(ctx context.Context, userID int) ([]models.UserPairs, error)                                       // Method to retrieve all user pairs for a given user ID
+	GetUserPairsPaged(ctx context.Context, userID, limit, offset int) ([]models.UserPairs, error)                      // Method to retrieve a page of the user pairs sorted by exchange and pair
+	GetUserPair(ctx context.Context, userID int, exchange, pair string) (models.UserPairs, error)                      // Method to retrieve a single pair of the user on an exchange
+	GetUserPairsByExchange(ctx context.Context, userID int, exchange string) ([]models.UserPairs, error)               // Method to retrieve the user pairs of a given exchange
+	GetPairsByExchange(ctx context.Context, exchange string) ([]string, error)                                         // Method to retrieve all pairs for a given exchange name
+	CountPairSubscribers(ctx context.Context, exchange, pair string) (int, error)                                      // Method to count the users watching a pair on an exchange
+	CountUserPairs(ctx context.Context, userID int) (int, error)                                                       // Method to count the pairs of a user
+	DeletePair(ctx context.Context, pairData models.UserPairs) error                                                   // Method to delete a specific user pair
+	DeleteAllUserPairs(ctx context.Context, userID int) error                                                          // Method to delete all pairs of a user
+	ReplaceUserPairs(ctx context.Context, userID int, pairs []models.UserPairs) (models.UserPairsReplaceResult, error) // Method to replace all pairs of a user in a single transaction
 }
 
 // userPairsRepository is a concrete implementation of the UserPairsRepository interface.
@@ -47,10 +57,16 @@ func (upr *userPairsRepository) Add(ctx context.Context, pairData models.UserPai
 			user_id,
 			exchange, 
 			pair,
-			exact_value
+			exact_value,
+			search_mode,
+			multiplier,
+			window_size,
+			tolerance,
+			side,
+			price_range
 		)
-		values ($1, $2, $3, $4)
-	`, userPairsTable) // SQL query string for inserting data
+		values ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'exact'), $6, $7, $8, COALESCE(NULLIF($9, ''), 'both'), $10)
+	`, userPairsTable) // SQL query string for inserting data, an empty search mode means the exact one and an empty side both sides
 
 	_, err := upr.db.ExecContext(
 		ctx,
@@ -59,6 +75,12 @@ func (upr *userPairsRepository) Add(ctx context.Context, pairData models.UserPai
 		pairData.Exchange,
 		pairData.Pair,
 		pairData.ExactValue,
+		pairData.SearchMode,
+		pairData.Multiplier,
+		pairData.Window,
+		pairData.Tolerance,
+		pairData.Side,
+		pairData.PriceRange,
 	) // Execute the SQL query with provided parameters
 	if err != nil {
 		return errFn // Return wrapped error
@@ -67,7 +89,87 @@ func (upr *userPairsRepository) Add(ctx context.Context, pairData models.UserPai
 	return nil // Return nil if no errors occurred
 }
 
-// UpdateExactValue updates the exact value of an existing user pair in the database.
+// BulkAdd inserts several user pairs into the database in a single transaction.
+// Every pair is inserted under its own savepoint, so a pair which can't be inserted,
+// e.g. because the user already has it, doesn't prevent the others from being stored.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - pairs: The user pairs to be inserted.
+//
+// Returns:
+//   - The errors of the pairs in the order of the pairs, nil for the inserted ones.
+//   - An error if the transaction fails, in which case none of the pairs is stored.
+func (upr *userPairsRepository) BulkAdd(ctx context.Context, pairs []models.UserPairs) ([]error, error) {
+	const op = directoryPath + "user_pairs_repository.BulkAdd" // Operation name for logging
+	errFn := repoError(op)                                     // Error handling function
+
+	queryString := fmt.Sprintf(`
+		INSERT INTO %s (
+			user_id,
+			exchange, 
+			pair,
+			exact_value,
+			search_mode,
+			multiplier,
+			window_size,
+			tolerance,
+			side,
+			price_range
+		)
+		values ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'exact'), $6, $7, $8, COALESCE(NULLIF($9, ''), 'both'), $10)
+	`, userPairsTable) // SQL query string for inserting data, an empty search mode means the exact one and an empty side both sides
+
+	tx, err := upr.db.BeginTxx(ctx, nil) // Start the transaction all pairs are inserted in
+	if err != nil {
+		return nil, errFn
+	}
+	defer tx.Rollback() // Roll back the transaction unless it was committed
+
+	pairErrors := make([]error, len(pairs))
+	for i, pairData := range pairs {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT bulk_add_pair"); err != nil {
+			return nil, errFn
+		}
+
+		_, err := tx.ExecContext(
+			ctx,
+			queryString,
+			pairData.UserID,
+			pairData.Exchange,
+			pairData.Pair,
+			pairData.ExactValue,
+			pairData.SearchMode,
+			pairData.Multiplier,
+			pairData.Window,
+			pairData.Tolerance,
+			pairData.Side,
+			pairData.PriceRange,
+		) // Execute the SQL query with provided parameters
+		if err != nil {
+			pairErrors[i] = errFn
+
+			// Undo the failed insert only, the transaction can't be used until it is rolled back to the savepoint
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT bulk_add_pair"); err != nil {
+				return nil, errFn
+			}
+
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT bulk_add_pair"); err != nil {
+			return nil, errFn
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errFn
+	}
+
+	return pairErrors, nil // Return the errors of the pairs which weren't inserted
+}
+
+// UpdateExactValue updates the exact value and the search settings of an existing user pair in the database.
 // It takes context and pair data as parameters and returns an error if any occurs.
 func (upr *userPairsRepository) UpdateExactValue(ctx context.Context, pairData models.UserPairs) error {
 	const op = directoryPath + "user_pairs_repository.UpdateExactValue" // Operation name for logging
@@ -75,9 +177,15 @@ func (upr *userPairsRepository) UpdateExactValue(ctx context.Context, pairData m
 
 	queryString := fmt.Sprintf(`
 		UPDATE %s 
-		SET exact_value=$1
+		SET exact_value=$1,
+			search_mode=COALESCE(NULLIF($5, ''), 'exact'),
+			multiplier=$6,
+			window_size=$7,
+			tolerance=$8,
+			side=COALESCE(NULLIF($9, ''), 'both'),
+			price_range=$10
 		WHERE user_id=$2 AND exchange=$3 AND pair=$4;
-	`, userPairsTable) // SQL query string for updating data
+	`, userPairsTable) // SQL query string for updating data, an empty search mode means the exact one and an empty side both sides
 
 	rows, err := upr.db.ExecContext(
 		ctx,
@@ -86,6 +194,12 @@ func (upr *userPairsRepository) UpdateExactValue(ctx context.Context, pairData m
 		pairData.UserID,
 		pairData.Exchange,
 		pairData.Pair,
+		pairData.SearchMode,
+		pairData.Multiplier,
+		pairData.Window,
+		pairData.Tolerance,
+		pairData.Side,
+		pairData.PriceRange,
 	) // Execute the SQL query with provided parameters
 	rowsAffected, _ := rows.RowsAffected() // Get the number of rows affected by the update
 	if err != nil || rowsAffected == 0 {   // Check for errors or if no rows were updated
@@ -113,6 +227,85 @@ func (upr *userPairsRepository) GetAllUserPairs(ctx context.Context, userID int)
 	return userPairs, nil // Return retrieved user pairs and nil if no errors occurred
 }
 
+// GetUserPairsPaged retrieves a page of the user pairs associated with a given user ID from the database.
+// The pairs are sorted by exchange and pair, so the pages don't overlap while the pairs aren't changed.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - userID: The ID of the user whose pairs are retrieved.
+//   - limit: The maximum number of the retrieved pairs.
+//   - offset: The number of pairs skipped before the page.
+//
+// Returns:
+//   - The pairs of the page, an empty slice past the last pair of the user.
+//   - An error if any occurs.
+func (upr *userPairsRepository) GetUserPairsPaged(ctx context.Context, userID, limit, offset int) ([]models.UserPairs, error) {
+	const op = directoryPath + "user_pairs_repository.GetUserPairsPaged" // Operation name for logging
+	userPairs := []models.UserPairs{}                                    // Slice to hold retrieved user pairs
+
+	queryString := fmt.Sprintf(`
+		SELECT * FROM %s
+		WHERE user_id=$1
+		ORDER BY exchange, pair
+		LIMIT $2 OFFSET $3;
+	`, userPairsTable) // SQL query string for selecting data
+
+	err := upr.db.SelectContext(ctx, &userPairs, queryString, userID, limit, offset) // Execute the SQL query and scan results into the slice
+	if err != nil {
+		return userPairs, repoError(op) // Return empty slice and wrapped error
+	}
+
+	return userPairs, nil // Return retrieved user pairs and nil if no errors occurred
+}
+
+// GetUserPair retrieves a single pair of the user on the exchange from the database.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - userID: The ID of the user whose pair is retrieved.
+//   - exchange: The name of the exchange of the pair.
+//   - pair: The name of the pair in the BASE/QUOTE form it is stored in.
+//
+// Returns:
+//   - The pair with its search settings.
+//   - ErrUserPairNotFound if the user has no such pair, an error if any other error occurs.
+func (upr *userPairsRepository) GetUserPair(ctx context.Context, userID int, exchange, pair string) (models.UserPairs, error) {
+	const op = directoryPath + "user_pairs_repository.GetUserPair" // Operation name for logging
+	var userPair models.UserPairs                                  // Variable to hold retrieved user pair
+
+	queryString := fmt.Sprintf(`
+		SELECT * FROM %s WHERE user_id=$1 AND exchange=$2 AND pair=$3;
+	`, userPairsTable) // SQL query string for selecting data
+
+	err := upr.db.GetContext(ctx, &userPair, queryString, userID, exchange, pair) // Execute the SQL query and scan the result into the pair
+	if errors.Is(err, sql.ErrNoRows) {
+		return userPair, ErrUserPairNotFound
+	}
+	if err != nil {
+		return userPair, repoError(op) // Return empty pair and wrapped error
+	}
+
+	return userPair, nil // Return retrieved user pair and nil if no errors occurred
+}
+
+// GetUserPairsByExchange retrieves the user pairs of a given exchange associated with a given user ID from the database.
+// It takes context, user ID and exchange name as parameters and returns a slice of UserPairs and an error if any occurs.
+func (upr *userPairsRepository) GetUserPairsByExchange(ctx context.Context, userID int, exchange string) ([]models.UserPairs, error) {
+	const op = directoryPath + "user_pairs_repository.GetUserPairsByExchange" // Operation name for logging
+	var userPairs []models.UserPairs                                          // Slice to hold retrieved user pairs
+
+	queryString := fmt.Sprintf(`
+		SELECT * FROM %s WHERE user_id=$1 AND exchange=$2;
+	`, userPairsTable) // SQL query string for selecting data
+
+	err := upr.db.SelectContext(ctx, &userPairs, queryString, userID, exchange) // Execute the SQL query and scan results into the slice
+	if err != nil {
+		return userPairs, repoError(op) // Return empty slice and wrapped error
+	}
+
+	return userPairs, nil // Return retrieved user pairs and nil if no errors occurred
+}
+
 // GetPairsByExchange retrieves all user pairs for a given exchange name from the database.
 // It takes context and exchange name as parameters and returns a slice of strings and an error if any occurs.
 func (upr *userPairsRepository) GetPairsByExchange(ctx context.Context, exchange string) ([]string, error) {
@@ -131,6 +324,42 @@ func (upr *userPairsRepository) GetPairsByExchange(ctx context.Context, exchange
 	return exchangePairs, nil // Return retrieved user pairs and nil if no errors occurred
 }
 
+// CountPairSubscribers counts the users who have the pair on the exchange among their pairs.
+// It takes context, exchange name and pair as parameters and returns the number of users and an error if any occurs.
+func (upr *userPairsRepository) CountPairSubscribers(ctx context.Context, exchange, pair string) (int, error) {
+	const op = directoryPath + "user_pairs_repository.CountPairSubscribers" // Operation name for logging
+	var subscribers int                                                     // Number of users watching the pair
+
+	queryString := fmt.Sprintf(`
+		SELECT COUNT(DISTINCT user_id) FROM %s WHERE exchange=$1 AND pair=$2;
+	`, userPairsTable) // SQL query string for counting users
+
+	err := upr.db.GetContext(ctx, &subscribers, queryString, exchange, pair) // Execute the SQL query and scan the result into the count
+	if err != nil {
+		return subscribers, repoError(op) // Return zero and wrapped error
+	}
+
+	return subscribers, nil // Return the number of users and nil if no errors occurred
+}
+
+// CountUserPairs counts the pairs of the user across all exchanges.
+// It takes context and user ID as parameters and returns the number of pairs and an error if any occurs.
+func (upr *userPairsRepository) CountUserPairs(ctx context.Context, userID int) (int, error) {
+	const op = directoryPath + "user_pairs_repository.CountUserPairs" // Operation name for logging
+	var pairsCount int                                                // Number of pairs of the user
+
+	queryString := fmt.Sprintf(`
+		SELECT COUNT(*) FROM %s WHERE user_id=$1;
+	`, userPairsTable) // SQL query string for counting pairs
+
+	err := upr.db.GetContext(ctx, &pairsCount, queryString, userID) // Execute the SQL query and scan the result into the count
+	if err != nil {
+		return pairsCount, repoError(op) // Return zero and wrapped error
+	}
+
+	return pairsCount, nil // Return the number of pairs and nil if no errors occurred
+}
+
 // DeletePair removes a specific user pair from the database.
 // It takes context and pair data as parameters and returns an error if any occurs.
 func (upr *userPairsRepository) DeletePair(ctx context.Context, pairData models.UserPairs) error {
@@ -154,3 +383,124 @@ func (upr *userPairsRepository) DeletePair(ctx context.Context, pairData models.
 
 	return nil // Return nil if no errors occurred
 }
+
+// DeleteAllUserPairs removes all pairs of the user from the database.
+// The pairs are removed by a single statement, so either all of them are removed or none.
+// A user without pairs is not an error. It takes context and user ID as parameters and returns an error if any occurs.
+func (upr *userPairsRepository) DeleteAllUserPairs(ctx context.Context, userID int) error {
+	const op = directoryPath + "user_pairs_repository.DeleteAllUserPairs" // Operation name for logging
+
+	queryString := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE user_id=$1
+	`, userPairsTable) // SQL query string for deleting data
+
+	_, err := upr.db.ExecContext(ctx, queryString, userID) // Execute the SQL query with provided parameters
+	if err != nil {
+		return repoError(op) // Return wrapped error
+	}
+
+	return nil // Return nil if no errors occurred
+}
+
+// ReplaceUserPairs replaces all pairs of the user with the given pairs in a single transaction,
+// so the user has either all of the new pairs or the old ones.
+//
+// The stored pairs are locked and compared with the new ones by models.DiffUserPairs, then the missing pairs
+// are deleted, the changed ones updated and the new ones inserted. It takes context, user ID and the new pairs
+// as parameters and returns the applied changes and an error if any occurs.
+func (upr *userPairsRepository) ReplaceUserPairs(ctx context.Context, userID int, pairs []models.UserPairs) (models.UserPairsReplaceResult, error) {
+	const op = directoryPath + "user_pairs_repository.ReplaceUserPairs" // Operation name for logging
+	errFn := repoError(op)                                              // Error handling function
+
+	tx, err := upr.db.BeginTxx(ctx, nil) // Start the transaction all changes are applied in
+	if err != nil {
+		return models.UserPairsReplaceResult{}, errFn
+	}
+	defer tx.Rollback() // Roll back the transaction unless it was committed
+
+	var stored []models.UserPairs // Pairs of the user before the replacement
+
+	selectQuery := fmt.Sprintf(`
+		SELECT * FROM %s WHERE user_id=$1 FOR UPDATE;
+	`, userPairsTable) // SQL query string locking the pairs of the user until the transaction ends
+
+	if err := tx.SelectContext(ctx, &stored, selectQuery, userID); err != nil {
+		return models.UserPairsReplaceResult{}, errFn
+	}
+
+	diff := models.DiffUserPairs(stored, pairs)
+
+	deleteQuery := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE user_id=$1 AND exchange=$2 AND pair=$3;
+	`, userPairsTable) // SQL query string for deleting a pair of an exchange
+
+	for _, pairData := range diff.Removed {
+		if _, err := tx.ExecContext(ctx, deleteQuery, userID, pairData.Exchange, pairData.Pair); err != nil {
+			return models.UserPairsReplaceResult{}, errFn
+		}
+	}
+
+	updateQuery := fmt.Sprintf(`
+		UPDATE %s
+		SET exact_value=$4,
+			search_mode=COALESCE(NULLIF($5, ''), 'exact'),
+			multiplier=$6,
+			window_size=$7,
+			tolerance=$8,
+			side=COALESCE(NULLIF($9, ''), 'both'),
+			price_range=$10
+		WHERE user_id=$1 AND exchange=$2 AND pair=$3;
+	`, userPairsTable) // SQL query string for updating data, an empty search mode means the exact one and an empty side both sides
+
+	insertQuery := fmt.Sprintf(`
+		INSERT INTO %s (
+			user_id,
+			exchange,
+			pair,
+			exact_value,
+			search_mode,
+			multiplier,
+			window_size,
+			tolerance,
+			side,
+			price_range
+		)
+		values ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'exact'), $6, $7, $8, COALESCE(NULLIF($9, ''), 'both'), $10)
+	`, userPairsTable) // SQL query string for inserting data, an empty search mode means the exact one and an empty side both sides
+
+	for _, change := range []struct {
+		query string
+		pairs []models.UserPairs
+	}{
+		{updateQuery, diff.Updated},
+		{insertQuery, diff.Added},
+	} {
+		for _, pairData := range change.pairs {
+			_, err := tx.ExecContext(
+				ctx,
+				change.query,
+				userID,
+				pairData.Exchange,
+				pairData.Pair,
+				pairData.ExactValue,
+				pairData.SearchMode,
+				pairData.Multiplier,
+				pairData.Window,
+				pairData.Tolerance,
+				pairData.Side,
+				pairData.PriceRange,
+			) // Execute the SQL query with provided parameters
+			if err != nil {
+				return models.UserPairsReplaceResult{}, errFn
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.UserPairsReplaceResult{}, errFn
+	}
+
+	return diff, nil // Return the applied changes
+}
@@ -11,15 +11,22 @@ import (
 // UserRepository defines the interface for operations related to users.
 // It includes methods for inserting, updating, retrieving, and deleting user records.
 type UserRepository interface {
-	InsertUser(ctx context.Context, user models.User) (int, error)         // Method to insert a new user
-	UpdatePassword(ctx context.Context, user models.User) error            // Method to update a user's password
-	UpdateRefreshToken(ctx context.Context, user models.User) error        // Method to update a user's refresh token
-	GetUserById(ctx context.Context, userID int) (models.User, error)      // Method to retrieve a user by ID
-	GetUserByEmail(ctx context.Context, email string) (models.User, error) // Method to retrieve a user by email
-	GetAllIDs(ctx context.Context) ([]int, error)                          // Method to get all user IDs
-	DeleteUser(ctx context.Context, clientID int) error                    // Method to delete a user by ID
+	InsertUser(ctx context.Context, user models.User) (int, error)                                       // Method to insert a new user
+	InsertUserWithSession(ctx context.Context, user models.User, newSession NewSessionFunc) (int, error) // Method to insert a new user along with the first session of it
+	UpdatePassword(ctx context.Context, user models.User) error                                          // Method to update a user's password
+	VerifyUser(ctx context.Context, userID int) error                                                    // Method to mark a user's email as verified
+	SetPendingEmail(ctx context.Context, userID int, email string) error                                 // Method to store the new email of a user until it is confirmed
+	ConfirmEmail(ctx context.Context, userID int, email string) error                                    // Method to replace a user's email with the confirmed pending one
+	GetUserById(ctx context.Context, userID int) (models.User, error)                                    // Method to retrieve a user by ID
+	GetUserByEmail(ctx context.Context, email string) (models.User, error)                               // Method to retrieve a user by email
+	GetAllIDs(ctx context.Context) ([]int, error)                                                        // Method to get all user IDs
+	DeleteUser(ctx context.Context, clientID int) error                                                  // Method to delete a user by ID together with the pairs, found volumes and settings of the user
 }
 
+// NewSessionFunc starts the first session of the newly inserted user, issuing the tokens bound to it.
+// It is called with the user whose ID is already assigned, since the tokens are issued for it.
+type NewSessionFunc func(user *models.User) (models.Session, error)
+
 // userRepository is a concrete implementation of the UserRepository interface.
 // It holds a reference to the database connection.
 type userRepository struct {
@@ -39,7 +46,8 @@ func NewUserRepository(db *sqlx.DB) UserRepository {
 }
 
 // InsertUser inserts a new user into the database.
-// It returns the newly created user's ID and an error if any occurs.
+// It returns the newly created user's ID and an error if any occurs,
+// ErrEmailAlreadyExists if another user has the same email.
 func (ur *userRepository) InsertUser(ctx context.Context, user models.User) (int, error) {
 	const op = directoryPath + "user_repository.InsertUser" // Operation name for logging
 
@@ -48,8 +56,8 @@ func (ur *userRepository) InsertUser(ctx context.Context, user models.User) (int
 		INSERT INTO %s (
 			email,
 			password,
-			refresh_token,
-			session_id
+			password_version,
+			verified
 		)
 		values ($1, $2, $3, $4)
 		RETURNING id;				
@@ -61,9 +69,12 @@ func (ur *userRepository) InsertUser(ctx context.Context, user models.User) (int
 		query,
 		user.Email,
 		user.Password,
-		user.RefreshToken,
-		user.SessionID,
+		user.PasswordVersion,
+		user.Verified,
 	) // Execute the SQL query and return the newly created user's ID
+	if isUniqueViolation(err) {
+		return 0, ErrEmailAlreadyExists // The email is the only unique column set by the query
+	}
 	if err != nil {
 		return 0, repoError(op) // Return zero ID and wrapped error
 	}
@@ -71,6 +82,80 @@ func (ur *userRepository) InsertUser(ctx context.Context, user models.User) (int
 	return clientID, nil // Return the newly created user's ID and nil if no errors occurred
 }
 
+// InsertUserWithSession inserts a new user and the first session of it in a single transaction.
+// The session is started by newSession after the user is inserted, because the tokens are issued for the user ID.
+// If newSession or any query fails, the transaction is rolled back, so no user without a session is left.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - user: The user to be inserted.
+//   - newSession: The function starting the first session of the inserted user.
+//
+// Returns:
+//   - The newly created user's ID.
+//   - An error if the user or the session can't be stored, ErrEmailAlreadyExists if another user has the same email.
+func (ur *userRepository) InsertUserWithSession(ctx context.Context, user models.User, newSession NewSessionFunc) (int, error) {
+	const op = directoryPath + "user_repository.InsertUserWithSession" // Operation name for logging
+	errFn := repoError(op)                                             // Error handling function
+
+	insertQuery := fmt.Sprintf(`
+		INSERT INTO %s (
+			email,
+			password,
+			password_version,
+			verified
+		)
+		values ($1, $2, $3, $4)
+		RETURNING id;
+	`, userTable) // SQL query string for inserting data
+
+	tx, err := ur.db.BeginTxx(ctx, nil) // Start the transaction the user and the session are stored in
+	if err != nil {
+		return 0, errFn
+	}
+	defer tx.Rollback() // Roll back the transaction unless it was committed
+
+	err = tx.GetContext(
+		ctx,
+		&user.ID,
+		insertQuery,
+		user.Email,
+		user.Password,
+		user.PasswordVersion,
+		user.Verified,
+	) // Execute the SQL query and store the newly created user's ID
+	if isUniqueViolation(err) {
+		return 0, ErrEmailAlreadyExists // The email is the only unique column set by the query
+	}
+	if err != nil {
+		return 0, errFn
+	}
+
+	// Let the caller issue the tokens of the session for the newly created user's ID
+	session, err := newSession(&user)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		insertSessionQuery,
+		session.ID,
+		user.ID,
+		session.RefreshToken,
+		session.UserAgent,
+	) // Execute the SQL query with provided parameters
+	if err != nil {
+		return 0, errFn
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errFn
+	}
+
+	return user.ID, nil // Return the newly created user's ID and nil if no errors occurred
+}
+
 // UpdatePassword updates an existing user's password in the database.
 // It returns an error if any occurs.
 func (ur *userRepository) UpdatePassword(ctx context.Context, user models.User) error {
@@ -79,17 +164,15 @@ func (ur *userRepository) UpdatePassword(ctx context.Context, user models.User)
 	query := fmt.Sprintf(`
 		UPDATE %s 
 		SET password=$1,
-			refresh_token=$2,
-			session_id=$3,
+			password_version=$2,
 			updated_at='now()'
-		WHERE id=$4;`, userTable) // SQL query string for updating data
+		WHERE id=$3;`, userTable) // SQL query string for updating data
 
 	rows, err := ur.db.ExecContext(
 		ctx,
 		query,
 		user.Password,
-		user.RefreshToken,
-		user.SessionID,
+		user.PasswordVersion,
 		user.ID,
 	) // Execute the SQL query with provided parameters
 	rowsAffected, _ := rows.RowsAffected() // Get the number of rows affected by the update
@@ -100,33 +183,112 @@ func (ur *userRepository) UpdatePassword(ctx context.Context, user models.User)
 	return nil // Return nil if no errors occurred
 }
 
-// UpdateRefreshToken updates an existing user's refresh token in the database.
-// It returns an error if any occurs.
-func (ur *userRepository) UpdateRefreshToken(ctx context.Context, user models.User) error {
-	const op = directoryPath + "user_repository.UpdateRefreshToken" // Operation name for logging
+// VerifyUser marks the email of an existing user as verified in the database.
+// It returns an error if the user doesn't exist or any other error occurs.
+func (ur *userRepository) VerifyUser(ctx context.Context, userID int) error {
+	const op = directoryPath + "user_repository.VerifyUser" // Operation name for logging
 
 	query := fmt.Sprintf(`
 		UPDATE %s 
-		SET refresh_token=$1,
-			session_id=$2,
+		SET verified=true,
 			updated_at='now()'
-		WHERE id=$3;`, userTable) // SQL query string for updating data
+		WHERE id=$1;`, userTable) // SQL query string for updating data
+
+	rows, err := ur.db.ExecContext(ctx, query, userID) // Execute the SQL query with provided parameters
+	if err != nil {
+		return repoError(op) // Return wrapped error
+	}
 
-	rows, err := ur.db.ExecContext(
-		ctx,
-		query,
-		user.RefreshToken,
-		user.SessionID,
-		user.ID,
-	) // Execute the SQL query with provided parameters
 	rowsAffected, _ := rows.RowsAffected() // Get the number of rows affected by the update
-	if err != nil || rowsAffected == 0 {   // Check for errors or if no rows were updated
+	if rowsAffected == 0 {                 // Check if the user exists
 		return repoError(op) // Return wrapped error
 	}
 
 	return nil // Return nil if no errors occurred
 }
 
+// SetPendingEmail stores the new email of an existing user until it is confirmed.
+// The email of the user isn't changed, so the user can still log in with it.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - userID: The ID of the user changing the email.
+//   - email: The new email of the user.
+//
+// Returns:
+//   - ErrEmailAlreadyExists if another user is registered with the email.
+//   - An error if the user doesn't exist or any other error occurs.
+func (ur *userRepository) SetPendingEmail(ctx context.Context, userID int, email string) error {
+	const op = directoryPath + "user_repository.SetPendingEmail" // Operation name for logging
+
+	var exists bool // Whether a user is registered with the email
+
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE email=$1);`, userTable) // SQL query string for checking the email
+	if err := ur.db.GetContext(ctx, &exists, query, email); err != nil {
+		return repoError(op) // Return wrapped error
+	}
+	if exists {
+		return ErrEmailAlreadyExists
+	}
+
+	query = fmt.Sprintf(`
+		UPDATE %s 
+		SET pending_email=$2,
+			updated_at='now()'
+		WHERE id=$1;`, userTable) // SQL query string for updating data
+
+	rows, err := ur.db.ExecContext(ctx, query, userID, email) // Execute the SQL query with provided parameters
+	if err != nil {
+		return repoError(op) // Return wrapped error
+	}
+
+	rowsAffected, _ := rows.RowsAffected() // Get the number of rows affected by the update
+	if rowsAffected == 0 {                 // Check if the user exists
+		return repoError(op) // Return wrapped error
+	}
+
+	return nil // Return nil if no errors occurred
+}
+
+// ConfirmEmail replaces the email of an existing user with the pending one.
+// The email is replaced only if it is still the pending email of the user, so a link sent
+// for an email which was requested earlier can't replace the latest requested one.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - userID: The ID of the user changing the email.
+//   - email: The confirmed email.
+//
+// Returns:
+//   - ErrEmailAlreadyExists if another user has registered with the email since it was requested.
+//   - ErrPendingEmailNotFound if the email isn't the pending email of the user.
+//   - An error if any other error occurs.
+func (ur *userRepository) ConfirmEmail(ctx context.Context, userID int, email string) error {
+	const op = directoryPath + "user_repository.ConfirmEmail" // Operation name for logging
+
+	query := fmt.Sprintf(`
+		UPDATE %s 
+		SET email=pending_email,
+			pending_email='',
+			updated_at='now()'
+		WHERE id=$1 AND pending_email=$2 AND pending_email!='';`, userTable) // SQL query string for updating data
+
+	rows, err := ur.db.ExecContext(ctx, query, userID, email) // Execute the SQL query with provided parameters
+	if isUniqueViolation(err) {
+		return ErrEmailAlreadyExists
+	}
+	if err != nil {
+		return repoError(op) // Return wrapped error
+	}
+
+	rowsAffected, _ := rows.RowsAffected() // Get the number of rows affected by the update
+	if rowsAffected == 0 {                 // Check if the email is still pending
+		return ErrPendingEmailNotFound
+	}
+
+	return nil // Return nil if no errors occurred
+}
+
 // GetUserById retrieves a user from the database by their ID.
 // It returns the user and an error if any occurs.
 func (ur *userRepository) GetUserById(ctx context.Context, userID int) (models.User, error) {
@@ -188,19 +350,46 @@ func (ur *userRepository) GetAllIDs(ctx context.Context) ([]int, error) {
 	return allIDs, nil // Return retrieved IDs and nil if no errors occurred
 }
 
-// DeleteUser removes a specific user from the database by their ID.
-// It returns an error if any occurs.
+// DeleteUser removes a specific user from the database by their ID together with the rows of the user
+// in the found volumes, user pairs, user settings and user sessions tables.
+//
+// The rows are deleted in a single transaction, so either nothing is deleted or no orphaned row is left.
+// The rows of the other tables are deleted explicitly rather than relying on the foreign keys, because the tables
+// of the databases created before the foreign keys were added don't cascade the deletion.
+// It returns an error if any occurs, including the user not being found.
 func (ur *userRepository) DeleteUser(ctx context.Context, clientID int) error {
 	const op = directoryPath + "user_repository.DeleteUser" // Operation name for logging
+	errFn := repoError(op)                                  // Error handling function
+
+	tx, err := ur.db.BeginTxx(ctx, nil) // Start the transaction all rows of the user are deleted in
+	if err != nil {
+		return errFn
+	}
+	defer tx.Rollback() // Roll back the transaction unless it was committed
+
+	// Delete the rows referencing the user before the user itself
+	for _, table := range []string{foundVolumesTable, userPairsTable, userSettingsTable, userSessionsTable} {
+		query := fmt.Sprintf(`DELETE FROM %s WHERE user_id=$1;`, table) // SQL query string for deleting data of the user
+
+		if _, err := tx.ExecContext(ctx, query, clientID); err != nil {
+			return errFn
+		}
+	}
 
 	query := fmt.Sprintf(`
         DELETE FROM %s 
         WHERE id=$1`, userTable) // SQL query string for deleting data
 
-	rows, err := ur.db.ExecContext(ctx, query, clientID) // Execute the SQL query with provided parameters
-	rowsAffected, _ := rows.RowsAffected()               // Get number of rows affected by delete operation
-	if err != nil || rowsAffected == 0 {                 // Check for errors or if no rows were deleted
-		return repoError(op) // Return wrapped error
+	rows, err := tx.ExecContext(ctx, query, clientID) // Execute the SQL query with provided parameters
+	if err != nil {
+		return errFn
+	}
+	if rowsAffected, _ := rows.RowsAffected(); rowsAffected == 0 { // Check if no rows were deleted
+		return errFn // Return wrapped error
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errFn
 	}
 
 	return nil // Return nil if no errors occurred
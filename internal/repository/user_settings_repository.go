@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"cvs/internal/models" // Importing domain models for user settings
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx" // Importing sqlx for database interactions
+)
+
+// UserSettingsRepository defines the interface for operations related to user settings.
+// It includes methods for retrieving and saving the notification preferences of users.
+type UserSettingsRepository interface {
+	Get(ctx context.Context, userID int) (models.UserSettings, error) // Method to retrieve the settings of a user
+	Upsert(ctx context.Context, settings models.UserSettings) error   // Method to insert or update the settings of a user
+}
+
+// userSettingsRepository is a concrete implementation of the UserSettingsRepository interface.
+// It holds a reference to the database connection.
+type userSettingsRepository struct {
+	db *sqlx.DB // Database connection
+}
+
+// NewUserSettingsRepository creates a new instance of userSettingsRepository.
+// It initializes the repository with a database connection.
+//
+// Parameters:
+//   - db: The database connection to be used by the repository.
+//
+// Returns:
+//   - An instance of UserSettingsRepository.
+func NewUserSettingsRepository(db *sqlx.DB) UserSettingsRepository {
+	return &userSettingsRepository{db} // Return a new instance of userSettingsRepository
+}
+
+// Get retrieves the settings of the user from the database.
+// A user who has never saved the settings gets the default ones, which is not an error.
+// It takes context and user ID as parameters and returns the settings and an error if any occurs.
+func (usr *userSettingsRepository) Get(ctx context.Context, userID int) (models.UserSettings, error) {
+	const op = directoryPath + "user_settings_repository.Get" // Operation name for logging
+	settings := models.UserSettings{UserID: userID}           // Default settings of the user
+
+	queryString := fmt.Sprintf(`
+		SELECT user_id, notify_telegram, notify_email, min_volume_notify, webhook_url, webhook_secret, alert_cooldown_seconds
+		FROM %s WHERE user_id=$1;
+	`, userSettingsTable) // SQL query string for selecting data
+
+	err := usr.db.GetContext(ctx, &settings, queryString, userID) // Execute the SQL query and scan the result into the settings
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.UserSettings{UserID: userID}, nil // The user has never saved the settings
+	}
+	if err != nil {
+		return settings, repoError(op) // Return default settings and wrapped error
+	}
+
+	return settings, nil // Return retrieved settings and nil if no errors occurred
+}
+
+// Upsert inserts the settings of the user into the database or updates them if they already exist.
+// It takes context and settings as parameters and returns an error if any occurs.
+func (usr *userSettingsRepository) Upsert(ctx context.Context, settings models.UserSettings) error {
+	const op = directoryPath + "user_settings_repository.Upsert" // Operation name for logging
+
+	queryString := fmt.Sprintf(`
+		INSERT INTO %s (
+			user_id,
+			notify_telegram,
+			notify_email,
+			min_volume_notify,
+			webhook_url,
+			webhook_secret,
+			alert_cooldown_seconds
+		)
+		values ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id) DO UPDATE
+		SET notify_telegram=EXCLUDED.notify_telegram,
+			notify_email=EXCLUDED.notify_email,
+			min_volume_notify=EXCLUDED.min_volume_notify,
+			webhook_url=EXCLUDED.webhook_url,
+			webhook_secret=EXCLUDED.webhook_secret,
+			alert_cooldown_seconds=EXCLUDED.alert_cooldown_seconds;
+	`, userSettingsTable) // SQL query string for upserting data
+
+	_, err := usr.db.ExecContext(
+		ctx,
+		queryString,
+		settings.UserID,
+		settings.NotifyTelegram,
+		settings.NotifyEmail,
+		settings.MinVolumeNotify,
+		settings.WebhookUrl,
+		settings.WebhookSecret,
+		settings.AlertCooldownSeconds,
+	) // Execute the SQL query with provided parameters
+	if err != nil {
+		return repoError(op) // Return wrapped error
+	}
+
+	return nil // Return nil if no errors occurred
+}
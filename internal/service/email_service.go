@@ -0,0 +1,150 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/url"
+	"strings"
+
+	"cvs/internal/config"
+)
+
+// EmailService defines the interface for sending emails to users.
+type EmailService interface {
+	Send(to, subject, body string) error           // Method to send a plain text email
+	SendResetPasswordToken(to, token string) error // Method to send a password reset token to the user
+	SendVerifyEmailToken(to, token string) error   // Method to send an email verification token to the user
+	SendChangeEmailToken(to, token string) error   // Method to send an email change token to the new email of the user
+}
+
+// emailService is a concrete implementation of EmailService which sends emails over SMTP.
+type emailService struct {
+	address string    // Address of the SMTP server in the host:port form
+	from    string    // Sender address of the emails
+	auth    smtp.Auth // Authentication used by the SMTP server, nil if it isn't required
+
+	resetPasswordUrl string // Page the password reset token is sent to, empty to send the bare token
+	verifyEmailUrl   string // Endpoint the email verification token is sent to, empty to send the bare token
+	changeEmailUrl   string // Endpoint the email change token is sent to, empty to send the bare token
+}
+
+// NewEmailService creates a new instance of emailService.
+//
+// Parameters:
+//   - cfg: The SMTP server settings. The authentication is skipped when the user name is empty.
+//   - resetPasswordUrl: The page the password reset token is appended to as the "token" query parameter.
+//   - verifyEmailUrl: The endpoint the email verification token is appended to as the "token" query parameter.
+//   - changeEmailUrl: The endpoint the email change token is appended to as the "token" query parameter.
+//
+// Returns:
+//   - An instance of EmailService.
+func NewEmailService(cfg config.SmtpConfig, resetPasswordUrl, verifyEmailUrl, changeEmailUrl string) EmailService {
+	var auth smtp.Auth
+	if cfg.UserName != "" {
+		auth = smtp.PlainAuth("", cfg.UserName, cfg.Password, cfg.Host)
+	}
+
+	return &emailService{
+		address: net.JoinHostPort(cfg.Host, cfg.Port),
+		from:    cfg.From,
+		auth:    auth,
+
+		resetPasswordUrl: resetPasswordUrl,
+		verifyEmailUrl:   verifyEmailUrl,
+		changeEmailUrl:   changeEmailUrl,
+	}
+}
+
+// Send sends a plain text email to the recipient.
+//
+// Parameters:
+//   - to: The email address of the recipient.
+//   - subject: The subject of the email.
+//   - body: The plain text body of the email.
+//
+// Returns:
+//   - An error if the email could not be sent.
+func (es *emailService) Send(to, subject, body string) error {
+	if strings.ContainsAny(to+subject, "\r\n") { // Don't let the values inject headers
+		return fmt.Errorf("invalid email headers")
+	}
+
+	message := "From: " + es.from + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" +
+		body
+
+	return smtp.SendMail(es.address, es.auth, es.from, []string{to}, []byte(message))
+}
+
+// SendResetPasswordToken sends the password reset token to the user.
+// The token is sent as a link to the reset password page if it is configured.
+//
+// Parameters:
+//   - to: The email address of the user.
+//   - token: The password reset token.
+//
+// Returns:
+//   - An error if the email could not be sent.
+func (es *emailService) SendResetPasswordToken(to, token string) error {
+	reset := token
+	if es.resetPasswordUrl != "" {
+		reset = es.resetPasswordUrl + "?token=" + url.QueryEscape(token)
+	}
+
+	body := "A password reset was requested for your account.\r\n\r\n" +
+		"Use the following to set a new password within 15 minutes:\r\n" +
+		reset + "\r\n\r\n" +
+		"If you didn't request it, ignore this email."
+
+	return es.Send(to, "Password reset", body)
+}
+
+// SendVerifyEmailToken sends the email verification token to the user.
+// The token is sent as a link to the verification endpoint if it is configured.
+//
+// Parameters:
+//   - to: The email address of the user.
+//   - token: The email verification token.
+//
+// Returns:
+//   - An error if the email could not be sent.
+func (es *emailService) SendVerifyEmailToken(to, token string) error {
+	verify := token
+	if es.verifyEmailUrl != "" {
+		verify = es.verifyEmailUrl + "?token=" + url.QueryEscape(token)
+	}
+
+	body := "Thank you for signing up.\r\n\r\n" +
+		"Use the following to verify your email within 24 hours:\r\n" +
+		verify + "\r\n\r\n" +
+		"If you didn't sign up, ignore this email."
+
+	return es.Send(to, "Email verification", body)
+}
+
+// SendChangeEmailToken sends the email change token to the new email of the user.
+// The token is sent as a link to the confirmation endpoint if it is configured.
+//
+// Parameters:
+//   - to: The new email of the user.
+//   - token: The email change token.
+//
+// Returns:
+//   - An error if the email could not be sent.
+func (es *emailService) SendChangeEmailToken(to, token string) error {
+	confirm := token
+	if es.changeEmailUrl != "" {
+		confirm = es.changeEmailUrl + "?token=" + url.QueryEscape(token)
+	}
+
+	body := "A change of the email of your account to this one was requested.\r\n\r\n" +
+		"Use the following to confirm the new email within 24 hours:\r\n" +
+		confirm + "\r\n\r\n" +
+		"If you didn't request it, ignore this email."
+
+	return es.Send(to, "Email change", body)
+}
@@ -9,9 +9,9 @@ import (
 // AllExchanges defines the interface for managing multiple exchange instances.
 // It includes methods for adding and retrieving exchanges.
 type AllExchanges interface {
-	Add(exchange Exchange)            // Method to add a new exchange to the storage
-	Get(exchangeName string) Exchange // Method to retrieve an exchange by its name
-	All() []Exchange                  // Method to retrieve all exchanges stored in the storage
+	Add(exchange Exchange)                    // Method to add a new exchange to the storage
+	Get(exchangeName string) (Exchange, bool) // Method to retrieve an exchange by its name
+	All() []Exchange                          // Method to retrieve all exchanges stored in the storage
 }
 
 // allExchanges is a concrete implementation of the AllExchanges interface.
@@ -37,14 +37,9 @@ func (ae *allExchanges) Add(exchange Exchange) {
 }
 
 // Get retrieves an exchange by its name from the storage.
-// If the exchange does not exist, it logs a message and returns a nil value.
-func (ae *allExchanges) Get(exchangeName string) Exchange {
-	exchange, exists := ae.exchanges.Get(exchangeName) // Attempt to retrieve the exchange from the map
-	if !exists {
-		ae.logger.Errorf("exchange with name %s does not exist in AllExchanges storage", exchangeName)
-	}
-
-	return exchange // Return the retrieved exchange (or nil if not found)
+// The second return value reports whether the exchange exists, the returned exchange is nil if it doesn't.
+func (ae *allExchanges) Get(exchangeName string) (Exchange, bool) {
+	return ae.exchanges.Get(exchangeName) // Attempt to retrieve the exchange from the map
 }
 
 // All retrieves all exchanges stored in the concurrent map.
@@ -1,24 +1,33 @@
 package exchange
 
 import (
+	"context"
+	"net/url"
 	"strings"
 	"time"
 
 	"cvs/internal/models"
-	"cvs/internal/service"
-	"cvs/internal/service/logger"
 	"cvs/internal/service/orderbook"
 
 	"github.com/goccy/go-json"
 	cmap "github.com/orcaman/concurrent-map/v2"
 )
 
+const (
+	binanceCoinmPerpetualSuffix    = "_PERP"     // Suffix of the COIN-M perpetual symbols, e.g. "BTCUSD_PERP"
+	binanceCoinmPerpetualContracts = "PERPETUAL" // Contract type of the COIN-M perpetual symbols
+)
+
 // Overall data for all sections of the Binance exchange
 var (
-	binanceTimeBetweenRequests = 3 * time.Second                       // Time interval between requests to the Binance API
-	binancePairsJsonModel      = models.BinancePairsJSONResponse{}     // Model for Binance pairs JSON response
-	binanceOrderbookJsonModel  = models.BinanceOrderbookJSONResponse{} // Model for Binance order book JSON response
-	binanceOrderbookService    = orderbook.NewOrderbook()              // Instance of the order book service for managing order data
+	binanceTimeBetweenRequests   = 3 * time.Second                       // Time interval between requests to the Binance API
+	binanceRequestWeightLimit    = 6000                                  // Weight of the requests Binance Spot allows per minute
+	binanceFuturesWeightLimit    = 2400                                  // Weight of the requests Binance USDT-M and COIN-M Futures allow per minute
+	binanceUsWeightLimit         = 1200                                  // Weight of the requests Binance US allows per minute
+	binancePairsJsonModel        = models.BinancePairsJSONResponse{}     // Model for Binance pairs JSON response
+	binanceOrderbookJsonModel    = models.BinanceOrderbookJSONResponse{} // Model for Binance order book JSON response
+	binanceOrderbookService      = orderbook.NewOrderbook()              // Instance of the order book service for managing order data
+	binanceCoinmOrderbookService = orderbook.NewOrderbook()              // Instance of the order book service of COIN-M, whose pairs are named as the Binance US ones
 
 	// Function to parse order book JSON response from Binance
 	binanceOrderbookJsonParse = func(bodyBytes []byte) ([][]interface{}, [][]interface{}, error) {
@@ -30,6 +39,33 @@ var (
 		return model.Asks, model.Bids, err
 	}
 
+	// Function to parse the last traded price from the Binance ticker price response
+	binanceTickerJsonParse = func(bodyBytes []byte) (float64, error) {
+		var model models.BinanceTickerPriceJSONResponse
+
+		if err := json.Unmarshal(bodyBytes, &model); err != nil {
+			return 0, err
+		}
+
+		return parseLastPrice(model.Price)
+	}
+
+	// Function to parse the last traded price from the Binance COIN-M ticker price response,
+	// which is a list even when a single symbol is requested
+	binanceCoinmTickerJsonParse = func(bodyBytes []byte) (float64, error) {
+		var model []models.BinanceTickerPriceJSONResponse
+
+		if err := json.Unmarshal(bodyBytes, &model); err != nil {
+			return 0, err
+		}
+
+		if len(model) == 0 {
+			return 0, errNoLastPrice
+		}
+
+		return parseLastPrice(model[0].Price)
+	}
+
 	// Function to format Binance API URLs with the trading pair
 	binanceUrlFormatter = func(url, pair string) string {
 		pairFormatted := strings.Replace(pair, "/", "", -1)                 // Remove slashes from the pair string
@@ -38,6 +74,11 @@ var (
 		return replacer.Replace(url) // Return the formatted URL
 	}
 
+	// Function to format Binance COIN-M API URLs with the perpetual pair, e.g. "BTC/USD" -> "BTCUSD_PERP"
+	binanceCoinmUrlFormatter = func(url, pair string) string {
+		return binanceUrlFormatter(url, pair+binanceCoinmPerpetualSuffix)
+	}
+
 	// Function to parse exchange pairs from Binance API response
 	binanceExchangePairsJsonParse = func(exchangeName string, bodyBytes []byte) ([]models.ExchangePairs, error) {
 		var model models.BinancePairsJSONResponse
@@ -50,10 +91,6 @@ var (
 		var exchangePairsSlice []models.ExchangePairs // Slice to hold parsed exchange pairs
 
 		for i := 0; i < len(model.Symbols); i++ { // Iterate over all symbols in pairs data
-			if model.Symbols[i].QuoteAsset == "BUSD" { // Skip pairs with BUSD as quote asset
-				continue
-			}
-
 			exchangePairsSlice = append(exchangePairsSlice, models.ExchangePairs{
 				Pair:     model.Symbols[i].BaseAsset + "/" + model.Symbols[i].QuoteAsset, // Construct pair string
 				Exchange: exchangeName,                                                   // Set exchange name
@@ -62,46 +99,65 @@ var (
 
 		return exchangePairsSlice, nil // Return the slice of exchange pairs
 	}
+
+	// Function to parse the perpetual pairs from Binance COIN-M API response.
+	// The delivery contracts share the base and quote assets of the perpetual ones, so they are skipped.
+	binanceCoinmExchangePairsJsonParse = func(exchangeName string, bodyBytes []byte) ([]models.ExchangePairs, error) {
+		var model models.BinancePairsJSONResponse
+		// Unmarshal the response body into jsonData to inspect the response
+		err := json.Unmarshal(bodyBytes, &model)
+		if err != nil {
+			return []models.ExchangePairs{}, errUnmarshal("exchange pairs", exchangeName) // Return error if unmarshalling fails
+		}
+
+		var exchangePairsSlice []models.ExchangePairs // Slice to hold parsed exchange pairs
+
+		for _, symbol := range model.Symbols {
+			if symbol.ContractType != binanceCoinmPerpetualContracts {
+				continue // Skip the quarterly delivery contracts, e.g. "BTCUSD_250627"
+			}
+
+			exchangePairsSlice = append(exchangePairsSlice, models.ExchangePairs{
+				Pair:     symbol.BaseAsset + "/" + symbol.QuoteAsset, // Construct pair string, e.g. "BTC/USD"
+				Exchange: exchangeName,                               // Set exchange name
+			})
+		}
+
+		return exchangePairsSlice, nil // Return the slice of exchange pairs
+	}
 )
 
+// init registers the Binance exchanges, so they are created and started by InitAllExchanges.
+func init() {
+	RegisterExchange("binance", NewBinance)
+}
+
 // NewBinance initializes instances of different Binance exchanges.
 //
 // This function creates and returns a slice of Exchange instances for various Binance exchanges,
-// including Spot and Futures exchanges. It uses the provided user service, user pairs service,
+// including Spot, USDT-M Futures and COIN-M Futures exchanges. It uses the provided user service, user pairs service,
 // HTTP request service, and found volume service to set up each exchange's data.
 //
 // Parameters:
-//   - userService: The service for managing user data.
-//   - userPairsService: The service for managing user pairs data.
-//   - httpRequestService: The service for making HTTP requests.
-//   - foundVolumeService: The service for managing found volumes.
+//   - deps: The services and the settings shared by all exchanges, see Dependencies.
 //
 // Returns:
 //   - []Exchange: A slice containing instances of different Binance exchanges.
-func NewBinance(
-	userService service.UserService,
-	userPairsService service.UserPairsService,
-	httpRequestService service.HttpRequest,
-	foundVolumeService service.FoundVolumesService,
-	logger logger.Logger,
-) []Exchange {
+func NewBinance(deps Dependencies) []Exchange {
 	var binances []Exchange // Slice to hold instances of different Binance exchanges
 	initFunctions := []func(exchangesData *ExchangeData) *ExchangeData{
 		setBinanceSpotData,
 		setBinanceFuturesData,
+		setBinanceCoinmData,
 		setBinanceUsData,
 	}
 
 	for _, function := range initFunctions {
-		exchangeData := setBinanceOverallData(
-			userService,
-			userPairsService,
-			httpRequestService,
-			foundVolumeService,
-			logger,
-		)
-
-		binances = append(binances, function(exchangeData))
+		exchangeData := setBinanceOverallData(deps)
+		exchangeData = function(exchangeData)
+		exchangeData.applySettings(deps) // Apply the settings configured for the exchange
+
+		binances = append(binances, exchangeData)
 	}
 
 	return binances // Return the slice of Binance exchanges
@@ -114,26 +170,17 @@ func NewBinance(
 // with settings for handling trading pairs, order books, and request formatting.
 //
 // Parameters:
-//   - userService: The service for managing user data.
-//   - userPairsService: The service for managing user pairs data.
-//   - httpRequestService: The service for making HTTP requests.
-//   - foundVolumeService: The service for managing found volumes.
+//   - deps: The services shared by all exchanges, see Dependencies.
 //
 // Returns:
 //   - *exchange: A pointer to the initialized exchange struct, ready for use in API interactions.
-func setBinanceOverallData(
-	userService service.UserService,
-	userPairsService service.UserPairsService,
-	httpRequestService service.HttpRequest,
-	foundVolumeService service.FoundVolumesService,
-	logger logger.Logger,
-) *ExchangeData {
+func setBinanceOverallData(deps Dependencies) *ExchangeData {
 	binanceExchangesData := ExchangeData{
-		userService:            userService,
-		userPairsService:       userPairsService,
-		httpRequestService:     httpRequestService,
-		foundVolumesService:    foundVolumeService,
-		logger:                 logger,
+		userService:            deps.UserService,
+		userPairsService:       deps.UserPairsService,
+		httpRequestService:     deps.HttpRequestService,
+		foundVolumesService:    deps.FoundVolumesService,
+		logger:                 deps.Logger,
 		pairsJsonModel:         binancePairsJsonModel,            // Set pairs JSON model for exchanges
 		orderbookJsonModel:     binanceOrderbookJsonModel,        // Set orderbook JSON model for exchanges
 		urlFormatter:           binanceUrlFormatter,              // Set URL formatter function for exchanges
@@ -142,8 +189,13 @@ func setBinanceOverallData(
 		pairsSubscribed:        cmap.New[bool](),                 // Initialize subscribed pairs list as empty
 		allPairsOfExchange:     cmap.New[models.ExchangePairs](), // Initialize concurrent map for all pairs of the exchange
 		orderbookJsonParse:     binanceOrderbookJsonParse,        // Set order book JSON parsing function for exchanges
-		exchangePairsJsonParse: binanceExchangePairsJsonParse,    // Set exchange pairs JSON parsing function for exchanges
+		tickerJsonParse:        binanceTickerJsonParse,
+		lastPrices:             cmap.New[lastPrice](),
+		exchangePairsJsonParse: binanceExchangePairsJsonParse, // Set exchange pairs JSON parsing function for exchanges
+		websocketResubscribe:   make(chan struct{}, 1),        // Initialize the channel for websocket resubscription requests
+		rateLimitWeight:        binanceRequestWeightLimit,     // The responses tell only the used weight
 	}
+	binanceExchangesData.observeRateLimitUsage() // Slow down as the used weight approaches the limit
 
 	return &binanceExchangesData
 }
@@ -162,6 +214,10 @@ func setBinanceSpotData(exchangesData *ExchangeData) *ExchangeData {
 	exchangesData.exchangeName = "binance_spot"                                                        // Set the name of the exchange to "binanceSpot"
 	exchangesData.pairsUrlForGetRequest = "https://api.binance.com/api/v3/exchangeInfo"                // URL for getting pairs information
 	exchangesData.orderbookUrlForGetRequest = "https://api.binance.com/api/v1/depth?symbol=&limit=500" // URL for getting order book data
+	exchangesData.tickerUrlForGetRequest = "https://api.binance.com/api/v3/ticker/price?symbol="       // URL for getting the last traded price
+	exchangesData.websocketUrl = "wss://stream.binance.com:9443/stream"                                // URL of the combined streams websocket
+	exchangesData.orderbookBatchUrl = "https://api.binance.com/api/v3/ticker/bookTicker"               // URL for getting the best levels of several pairs
+	exchangesData.batchFetcher = binanceBookTickerFetcher(exchangesData, true)
 
 	return exchangesData // Return updated exchanges data
 }
@@ -180,6 +236,11 @@ func setBinanceUsData(exchangesData *ExchangeData) *ExchangeData {
 	exchangesData.exchangeName = "binance_us"                                                         // Set the name of the exchange to "binanceUs"
 	exchangesData.pairsUrlForGetRequest = "https://api.binance.us/api/v3/exchangeInfo"                // URL for getting pairs information from Binance US
 	exchangesData.orderbookUrlForGetRequest = "https://api.binance.us/api/v3/depth?symbol=&limit=500" // URL for getting order book data from Binance US
+	exchangesData.tickerUrlForGetRequest = "https://api.binance.us/api/v3/ticker/price?symbol="       // URL for getting the last traded price from Binance US
+	exchangesData.websocketUrl = "wss://stream.binance.us:9443/stream"                                // URL of the combined streams websocket of Binance US
+	exchangesData.orderbookBatchUrl = "https://api.binance.us/api/v3/ticker/bookTicker"               // URL for getting the best levels of all pairs of Binance US
+	exchangesData.batchFetcher = binanceBookTickerFetcher(exchangesData, false)
+	exchangesData.rateLimitWeight = binanceUsWeightLimit
 
 	return exchangesData // Return updated exchanges data
 }
@@ -198,6 +259,115 @@ func setBinanceFuturesData(exchangesData *ExchangeData) *ExchangeData {
 	exchangesData.exchangeName = "binance_futures"                                                       // Set the name of the exchange to "binanceFutures"
 	exchangesData.pairsUrlForGetRequest = "https://fapi.binance.com/fapi/v1/exchangeInfo"                // URL for getting futures pairs information
 	exchangesData.orderbookUrlForGetRequest = "https://fapi.binance.com/fapi/v1/depth?symbol=&limit=500" // URL for getting futures order book data
+	exchangesData.tickerUrlForGetRequest = "https://fapi.binance.com/fapi/v1/ticker/price?symbol="       // URL for getting the last traded futures price
+	exchangesData.websocketUrl = "wss://fstream.binance.com/stream"                                      // URL of the futures combined streams websocket
+	exchangesData.orderbookBatchUrl = "https://fapi.binance.com/fapi/v1/ticker/bookTicker"               // URL for getting the best levels of all futures pairs
+	exchangesData.batchFetcher = binanceBookTickerFetcher(exchangesData, false)
+	exchangesData.rateLimitWeight = binanceFuturesWeightLimit
 
 	return exchangesData // Return updated exchanges data
 }
+
+// setBinanceCoinmData sets up data specific to the Binance COIN-M Futures exchange.
+//
+// This function configures the exchange struct with settings specific to the Binance COIN-M Futures exchange,
+// including URLs for API calls and initializing necessary fields. Only the perpetual contracts are loaded,
+// and their volumes are in contracts of a fixed USD value rather than in the base asset.
+//
+// The order books are fetched over REST only: the websocket stream names and the bookTicker batches
+// are built from the linear symbols, which don't match the COIN-M ones. The order books are stored apart
+// from the other sections, as the pairs such as "BTC/USD" are named the same as the Binance US ones.
+//
+// Parameters:
+//   - exchangesData: A pointer to the exchange struct to be configured.
+//
+// Returns:
+//   - *exchange: A pointer to the updated exchange struct.
+func setBinanceCoinmData(exchangesData *ExchangeData) *ExchangeData {
+	exchangesData.exchangeName = "binance_coinm"                                                         // Set the name of the exchange to "binanceCoinm"
+	exchangesData.pairsUrlForGetRequest = "https://dapi.binance.com/dapi/v1/exchangeInfo"                // URL for getting COIN-M pairs information
+	exchangesData.orderbookUrlForGetRequest = "https://dapi.binance.com/dapi/v1/depth?symbol=&limit=500" // URL for getting COIN-M order book data
+	exchangesData.tickerUrlForGetRequest = "https://dapi.binance.com/dapi/v1/ticker/price?symbol="       // URL for getting the last traded COIN-M price
+	exchangesData.urlFormatter = binanceCoinmUrlFormatter                                                // Set URL formatter function for perpetual symbols
+	exchangesData.orderbookService = binanceCoinmOrderbookService                                        // The contracts mustn't overwrite the Binance US books of the same pairs
+	exchangesData.tickerJsonParse = binanceCoinmTickerJsonParse
+	exchangesData.exchangePairsJsonParse = binanceCoinmExchangePairsJsonParse
+	exchangesData.rateLimitWeight = binanceFuturesWeightLimit
+
+	return exchangesData // Return updated exchanges data
+}
+
+// binanceBookTickerFetcher returns the batch fetcher of the Binance exchange, which gets the best bid and ask
+// levels of several pairs by one bookTicker request.
+//
+// The bookTicker endpoint returns only the best level of each side, so the order books stored from its
+// response are shallower than the ones fetched from the depth endpoint. Pairs absent from the response
+// are absent from the returned map as well.
+//
+// Parameters:
+//   - exchangeData: The exchange whose HTTP request service and batch URL are used for the requests.
+//   - symbolsParam: Whether the endpoint filters the tickers by the symbols parameter. If it doesn't,
+//     the tickers of all symbols are requested and the ones of the other pairs are skipped.
+//
+// Returns:
+//   - func(ctx context.Context, pairs []string) (map[string]bookData, error): The fetcher returning the order books by pairs.
+func binanceBookTickerFetcher(exchangeData *ExchangeData, symbolsParam bool) func(ctx context.Context, pairs []string) (map[string]bookData, error) {
+	return func(ctx context.Context, pairs []string) (map[string]bookData, error) {
+		pairsBySymbol := make(map[string]string, len(pairs)) // Pairs by the symbols used by Binance, e.g. "BTCUSDT"
+		symbols := make([]string, 0, len(pairs))
+
+		for _, pair := range pairs {
+			symbol := strings.Replace(pair, "/", "", -1) // Remove slashes from the pair string
+			pairsBySymbol[symbol] = pair
+			symbols = append(symbols, `"`+symbol+`"`)
+		}
+
+		requestUrl := exchangeData.orderbookBatchUrl
+		if symbolsParam {
+			requestUrl += "?symbols=" + url.QueryEscape("["+strings.Join(symbols, ",")+"]")
+		}
+
+		resp, err := exchangeData.httpRequestService.GetWithRetry(ctx, requestUrl, exchangeData.requestHeaders, requestAttempts, requestBackoff)
+		if err != nil || resp.Body == nil {
+			return nil, responseError(err)
+		}
+
+		defer resp.Body.Close() // Ensure response body is closed after reading
+
+		bodyBytes, err := readBody(resp.Body, exchangeData.orderbookBodyLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		if isNonJsonResponse(resp, bodyBytes) {
+			return nil, exchangeData.warnNonJsonResponse(resp, bodyBytes, exchangeData.orderbookBatchUrl)
+		}
+
+		if !isSuccessStatus(resp.StatusCode) {
+			exchangeData.throttle(resp) // The body is an error of the exchange, e.g. a rate limit
+
+			return nil, errUnexpectedStatus(resp.StatusCode)
+		}
+
+		var tickers []models.BinanceBookTickerJSONResponse
+		if err := json.Unmarshal(bodyBytes, &tickers); err != nil {
+			return nil, errUnmarshal("book ticker", exchangeData.exchangeName)
+		}
+
+		books := make(map[string]bookData, len(pairs))
+
+		for _, ticker := range tickers {
+			pair, ok := pairsBySymbol[ticker.Symbol]
+			if !ok {
+				continue // Skip the tickers of pairs which weren't requested
+			}
+
+			books[pair] = bookData{
+				asks: [][]interface{}{{ticker.AskPrice, ticker.AskQty}},
+				bids: [][]interface{}{{ticker.BidPrice, ticker.BidQty}},
+			}
+		}
+
+		return books, nil
+	}
+}
@@ -0,0 +1,172 @@
+package exchange
+
+import (
+	"testing"
+
+	"cvs/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBinanceCoinmExchangePairsJsonParse tests parsing of the Binance COIN-M exchangeInfo response into exchange pairs.
+func TestBinanceCoinmExchangePairsJsonParse(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name          string                 // Name of the test case
+		body          string                 // Response body of the exchangeInfo request
+		expectedPairs []models.ExchangePairs // Pairs expected to be parsed
+		expectErr     bool                   // Expected outcome: true if an error is expected
+	}{
+		{
+			name: "Perpetual and delivery contracts",
+			body: `{
+				"timezone": "UTC",
+				"serverTime": 1700000000000,
+				"rateLimits": [],
+				"exchangeFilters": [],
+				"symbols": [
+					{
+						"symbol": "BTCUSD_PERP",
+						"pair": "BTCUSD",
+						"contractType": "PERPETUAL",
+						"deliveryDate": 4133404800000,
+						"contractStatus": "TRADING",
+						"contractSize": 100,
+						"marginAsset": "BTC",
+						"baseAsset": "BTC",
+						"quoteAsset": "USD",
+						"pricePrecision": 1,
+						"quantityPrecision": 0
+					},
+					{
+						"symbol": "BTCUSD_250627",
+						"pair": "BTCUSD",
+						"contractType": "CURRENT_QUARTER",
+						"deliveryDate": 1751011200000,
+						"contractStatus": "TRADING",
+						"contractSize": 100,
+						"marginAsset": "BTC",
+						"baseAsset": "BTC",
+						"quoteAsset": "USD",
+						"pricePrecision": 1,
+						"quantityPrecision": 0
+					},
+					{
+						"symbol": "ETHUSD_PERP",
+						"pair": "ETHUSD",
+						"contractType": "PERPETUAL",
+						"deliveryDate": 4133404800000,
+						"contractStatus": "TRADING",
+						"contractSize": 10,
+						"marginAsset": "ETH",
+						"baseAsset": "ETH",
+						"quoteAsset": "USD",
+						"pricePrecision": 2,
+						"quantityPrecision": 0
+					}
+				]
+			}`,
+			expectedPairs: []models.ExchangePairs{
+				{Pair: "BTC/USD", Exchange: "binance_coinm"},
+				{Pair: "ETH/USD", Exchange: "binance_coinm"},
+			},
+		},
+		{
+			name:      "Invalid JSON",
+			body:      `<html>502 Bad Gateway</html>`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			pairs, err := binanceCoinmExchangePairsJsonParse("binance_coinm", []byte(tc.body))
+
+			if tc.expectErr {
+				assert.Error(t, err)
+				assert.Empty(t, pairs)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPairs, pairs)
+		})
+	}
+}
+
+// TestBinanceCoinmOrderbookService tests that the COIN-M order books are stored apart from the Binance US ones
+// of the same pairs, so upserting the book of a contract leaves the book of the spot pair unchanged.
+func TestBinanceCoinmOrderbookService(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	const pair = "COINM/USD" // The order book services are shared by the tests, so use a pair no other test uses
+
+	binances := NewBinance(Dependencies{})
+	coinm, us := binances[2].(*ExchangeData), binances[3].(*ExchangeData)
+	assert.Equal(t, "binance_coinm", coinm.exchangeName)
+	assert.Equal(t, "binance_us", us.exchangeName)
+
+	us.orderbookService.Upsert(pair, [][]interface{}{{"101", "2"}}, [][]interface{}{{"99", "1"}})
+	coinm.orderbookService.Upsert(pair, [][]interface{}{{"201", "5"}}, [][]interface{}{{"199", "4"}})
+
+	usPrices, ok := us.BestPrices(pair)
+	assert.True(t, ok)
+	assert.Equal(t, 101.0, usPrices.BestAsk) // Unchanged by the COIN-M book
+	assert.Equal(t, 99.0, usPrices.BestBid)
+
+	coinmPrices, ok := coinm.BestPrices(pair)
+	assert.True(t, ok)
+	assert.Equal(t, 201.0, coinmPrices.BestAsk)
+	assert.Equal(t, 199.0, coinmPrices.BestBid)
+}
+
+// TestBinanceUrlFormatter tests that the pair is inserted into the URL as the Binance symbol.
+func TestBinanceUrlFormatter(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name        string                        // Name of the test case
+		formatter   func(url, pair string) string // Formatter of the exchange section
+		url         string                        // URL passed to the formatter
+		pair        string                        // Trading pair passed to the formatter
+		expectedUrl string                        // URL expected to be formatted
+	}{
+		{
+			name:        "Linear",
+			formatter:   binanceUrlFormatter,
+			url:         "https://fapi.binance.com/fapi/v1/depth?symbol=&limit=500",
+			pair:        "BTC/USDT",
+			expectedUrl: "https://fapi.binance.com/fapi/v1/depth?symbol=BTCUSDT&limit=500",
+		},
+		{
+			name:        "COIN-M depth",
+			formatter:   binanceCoinmUrlFormatter,
+			url:         "https://dapi.binance.com/dapi/v1/depth?symbol=&limit=500",
+			pair:        "BTC/USD",
+			expectedUrl: "https://dapi.binance.com/dapi/v1/depth?symbol=BTCUSD_PERP&limit=500",
+		},
+		{
+			name:        "COIN-M ticker",
+			formatter:   binanceCoinmUrlFormatter,
+			url:         "https://dapi.binance.com/dapi/v1/ticker/price?symbol=",
+			pair:        "ETH/USD",
+			expectedUrl: "https://dapi.binance.com/dapi/v1/ticker/price?symbol=ETHUSD_PERP",
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			assert.Equal(t, tc.expectedUrl, tc.formatter(tc.url, tc.pair))
+		})
+	}
+}
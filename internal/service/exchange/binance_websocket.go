@@ -0,0 +1,453 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cvs/internal/models"
+
+	"github.com/fasthttp/websocket"
+	"github.com/goccy/go-json"
+	"github.com/spf13/cast"
+	"go.uber.org/zap"
+)
+
+const (
+	binanceDepthStreamSuffix = "@depth@100ms"   // Suffix of the diff-depth stream name with 100ms update speed
+	websocketMinBackoff      = time.Second      // Initial delay before reconnecting to the websocket
+	websocketMaxBackoff      = 64 * time.Second // Maximum delay between websocket reconnection attempts
+	maxBufferedDepthEvents   = 1000             // Maximum number of events of a pair buffered while its snapshot is fetched
+)
+
+var (
+	// Function to build the Binance diff-depth stream name of the trading pair (e.g. "BTC/USDT" -> "btcusdt@depth@100ms")
+	binanceDepthStreamName = func(pair string) string {
+		return strings.ToLower(strings.Replace(pair, "/", "", -1)) + binanceDepthStreamSuffix
+	}
+)
+
+// binanceDepthBook tracks the order book of a single pair which is built from a REST snapshot
+// and kept up to date by applying diff-depth events received from the websocket.
+// The levels are held until the first event is applied, then the order book service holds them.
+type binanceDepthBook struct {
+	lastUpdateID int64                  // ID of the last update applied to the book
+	synced       bool                   // Whether the first event after the snapshot was applied
+	asks         map[string]interface{} // Ask levels of the snapshot: price -> quantity, nil once synced
+	bids         map[string]interface{} // Bid levels of the snapshot: price -> quantity, nil once synced
+}
+
+// binanceDepthSnapshot is the result of the REST snapshot request of a pair's book.
+type binanceDepthSnapshot struct {
+	pair string            // Trading pair of the snapshot
+	book *binanceDepthBook // Book built from the snapshot, nil if the request failed
+	err  error             // Error of the request or of the parsing
+}
+
+// binanceDepthSession holds the state of a single websocket connection.
+// It is owned by one goroutine, so its fields don't need any synchronization. The snapshots are fetched
+// by separate goroutines, which deliver them through the snapshots channel.
+type binanceDepthSession struct {
+	conn      *websocket.Conn                       // Websocket connection to the combined stream endpoint
+	streams   map[string]string                     // Subscribed stream names mapped to their trading pairs
+	books     map[string]*binanceDepthBook          // Local order books by trading pair
+	pending   map[string][]models.BinanceDepthEvent // Events buffered by trading pair while the snapshot of its book is fetched
+	snapshots chan binanceDepthSnapshot             // Snapshots fetched for the pairs in pending
+	ctx       context.Context                       // Done when the connection is no longer served, so the snapshots are cancelled and discarded
+	requestID int64                                 // ID of the last request sent over the connection
+}
+
+// newBinanceDepthSession creates the state of a websocket connection, which is served until the context is done.
+func newBinanceDepthSession(ctx context.Context, conn *websocket.Conn) *binanceDepthSession {
+	return &binanceDepthSession{
+		conn:      conn,
+		streams:   make(map[string]string),
+		books:     make(map[string]*binanceDepthBook),
+		pending:   make(map[string][]models.BinanceDepthEvent),
+		snapshots: make(chan binanceDepthSnapshot),
+		ctx:       ctx,
+	}
+}
+
+// StartOrderbookWebsocket keeps the order book of the subscribed pairs up to date through the exchange websocket.
+//
+// This method runs as a goroutine. It waits for at least one subscribed pair, connects to the combined
+// stream endpoint of the exchange and subscribes to the diff-depth stream of every subscribed pair. Each
+// pair's book is initialized from a REST snapshot and then kept in sync by applying the incremental
+// updates received from the socket, after which the result is upserted into the order book service.
+//
+// When the connection drops, the method reconnects with an exponential backoff. While the socket is down,
+// GetOrderbookPeriodically falls back to fetching order books over REST.
+//
+// The method does nothing for exchanges that have no websocket URL configured. Otherwise it runs
+// until the provided context is cancelled, which also closes the connection.
+func (e *ExchangeData) StartOrderbookWebsocket(ctx context.Context) {
+	if e.websocketUrl == "" {
+		return // The exchange doesn't support websocket streaming
+	}
+
+	e.runningLoops.Add(1)
+
+	go func() {
+		defer e.runningLoops.Add(-1)
+
+		backoff := websocketMinBackoff // Delay before the next connection attempt
+		idleRounds := 0                // Number of consecutive rounds without subscribed pairs
+
+		for {
+			if len(e.pollablePairs()) == 0 { // Don't keep a connection open while nothing is subscribed but the blacklisted pairs
+				idleRounds++
+
+				if !sleepContext(ctx, idleSleep(idleRounds)) {
+					return
+				}
+
+				continue
+			}
+
+			idleRounds = 0
+
+			conn, _, err := websocket.DefaultDialer.DialContext(ctx, e.websocketUrl, nil)
+			if err == nil {
+				backoff = websocketMinBackoff // Reset the backoff after a successful connection
+
+				err = e.readOrderbookWebsocket(ctx, conn)
+				conn.Close()
+			}
+
+			e.websocketConnected.Store(false) // Let the REST polling take over while the socket is down
+
+			if ctx.Err() != nil {
+				return // The work is cancelled, don't reconnect
+			}
+
+			errExchange(
+				e.logger,
+				fmt.Sprintf("Websocket disconnected, reconnecting in %s: %v", backoff, err),
+				e.exchangeName,
+				e.websocketUrl,
+			)
+
+			if !sleepContext(ctx, backoff) {
+				return
+			}
+
+			backoff = min(backoff*2, websocketMaxBackoff) // Increase the delay exponentially
+		}
+	}()
+}
+
+// readOrderbookWebsocket serves a single websocket connection until it fails.
+//
+// It subscribes to the streams of the currently subscribed pairs, then handles incoming depth events,
+// the fetched snapshots and resubscription requests sent by AddPairToSubscribedPairs and DeletePairFromSubscribedPairs.
+// The snapshots are fetched outside of this loop, so a slow or retried request doesn't stall the streams of the other pairs.
+//
+// Returns:
+//   - error: The error which terminated the connection or the context error if it was cancelled.
+func (e *ExchangeData) readOrderbookWebsocket(ctx context.Context, conn *websocket.Conn) error {
+	messages := make(chan []byte)                 // Messages read from the connection
+	readErr := make(chan error, 1)                // Error which stopped the reading
+	sessionCtx, cancel := context.WithCancel(ctx) // Cancelled when this method returns
+	defer cancel()
+
+	session := newBinanceDepthSession(sessionCtx, conn)
+
+	go func() {
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				readErr <- err
+
+				return
+			}
+
+			select {
+			case messages <- message:
+			case <-sessionCtx.Done():
+				return
+			}
+		}
+	}()
+
+	if err := e.syncWebsocketSubscriptions(session); err != nil {
+		return err
+	}
+
+	e.websocketConnected.Store(true)
+
+	for {
+		select {
+		case <-e.websocketResubscribe:
+			if err := e.syncWebsocketSubscriptions(session); err != nil {
+				return err
+			}
+		case message := <-messages:
+			e.handleDepthEvent(session, message)
+		case snapshot := <-session.snapshots:
+			e.handleDepthSnapshot(session, snapshot)
+		case err := <-readErr:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// syncWebsocketSubscriptions subscribes to the streams of newly subscribed pairs and unsubscribes
+// from the streams of pairs that are no longer subscribed. Local books of removed pairs are dropped.
+// The blacklisted pairs are neither streamed nor snapshotted, like they aren't polled.
+func (e *ExchangeData) syncWebsocketSubscriptions(session *binanceDepthSession) error {
+	wanted := make(map[string]string) // Stream names of the currently subscribed pairs which aren't blacklisted
+	for _, pair := range e.pollablePairs() {
+		wanted[binanceDepthStreamName(pair)] = pair
+	}
+
+	var subscribe, unsubscribe []string
+
+	for stream, pair := range wanted {
+		if _, ok := session.streams[stream]; !ok {
+			subscribe = append(subscribe, stream)
+			session.streams[stream] = pair
+		}
+	}
+
+	for stream, pair := range session.streams {
+		if _, ok := wanted[stream]; !ok {
+			unsubscribe = append(unsubscribe, stream)
+			delete(session.streams, stream)
+			delete(session.books, pair)
+			delete(session.pending, pair) // The snapshot fetched in the meantime is discarded
+		}
+	}
+
+	if err := session.send("UNSUBSCRIBE", unsubscribe); err != nil {
+		return err
+	}
+
+	return session.send("SUBSCRIBE", subscribe)
+}
+
+// send writes a subscription request for the given streams to the connection.
+// Nothing is sent when the list of streams is empty.
+func (s *binanceDepthSession) send(method string, streams []string) error {
+	if len(streams) == 0 {
+		return nil
+	}
+
+	s.requestID++
+
+	return s.conn.WriteJSON(models.BinanceWebsocketRequest{
+		Method: method,
+		Params: streams,
+		ID:     s.requestID,
+	})
+}
+
+// handleDepthEvent applies a diff-depth event to the local book of its pair.
+//
+// The book of the pair is initialized from a REST snapshot on its first event. The snapshot is fetched
+// in the background and the events of the pair are buffered until it arrives, see handleDepthSnapshot.
+func (e *ExchangeData) handleDepthEvent(session *binanceDepthSession, message []byte) {
+	var event models.BinanceDepthStreamJSONResponse
+
+	if err := json.Unmarshal(message, &event); err != nil || event.Stream == "" {
+		return // Responses to subscription requests don't carry a stream name
+	}
+
+	pair, ok := session.streams[event.Stream]
+	if !ok {
+		return // The pair was unsubscribed in the meantime
+	}
+
+	book, ok := session.books[pair]
+	if ok {
+		e.applyDepthEvent(session, pair, book, event.Data)
+
+		return
+	}
+
+	events, fetching := session.pending[pair]
+	if !fetching {
+		e.fetchDepthSnapshot(session, pair)
+	}
+
+	if len(events) >= maxBufferedDepthEvents {
+		events = events[1:] // The oldest events are the most likely to be included in the snapshot
+	}
+
+	session.pending[pair] = append(events, event.Data)
+}
+
+// fetchDepthSnapshot requests the snapshot of the pair's book in a separate goroutine
+// and delivers it to the session, unless the connection is no longer served.
+func (e *ExchangeData) fetchDepthSnapshot(session *binanceDepthSession, pair string) {
+	go func() {
+		book, err := e.getDepthSnapshot(session.ctx, pair)
+
+		select {
+		case session.snapshots <- binanceDepthSnapshot{pair: pair, book: book, err: err}:
+		case <-session.ctx.Done():
+		}
+	}()
+}
+
+// handleDepthSnapshot initializes the book of the pair from the fetched snapshot and applies the events
+// buffered while it was fetched. The snapshot of a pair unsubscribed in the meantime is discarded.
+// If the request failed, the error is recorded and the snapshot is requested again on the next event.
+func (e *ExchangeData) handleDepthSnapshot(session *binanceDepthSession, snapshot binanceDepthSnapshot) {
+	events, ok := session.pending[snapshot.pair]
+	if !ok {
+		return // The pair was unsubscribed or its book was built from another snapshot
+	}
+
+	delete(session.pending, snapshot.pair)
+
+	if snapshot.err != nil {
+		errExchange(e.logger, snapshot.err.Error(), e.exchangeName, e.orderbookUrlForGetRequest, zap.String("pair", snapshot.pair))
+		e.recordFetchError(snapshot.err)
+
+		return
+	}
+
+	session.books[snapshot.pair] = snapshot.book
+
+	for _, event := range events {
+		if !e.applyDepthEvent(session, snapshot.pair, snapshot.book, event) {
+			return // The book is rebuilt from a new snapshot on the next event
+		}
+	}
+}
+
+// applyDepthEvent applies the event to the book of the pair in the order book service.
+// The first event after the snapshot upserts the whole book, the next ones update only the changed levels.
+//
+// Events which are older than the snapshot are dropped. If a gap between the snapshot and the event or between
+// two consecutive events is detected, the book is discarded and rebuilt from a new snapshot on the next event.
+//
+// Returns:
+//   - bool: False if the book was discarded because of a gap, true otherwise.
+func (e *ExchangeData) applyDepthEvent(session *binanceDepthSession, pair string, book *binanceDepthBook, event models.BinanceDepthEvent) bool {
+	if event.FinalUpdateID <= book.lastUpdateID {
+		return true // The event is already included in the snapshot
+	}
+
+	var inSequence bool
+
+	switch {
+	case !book.synced: // The first event must overlap the snapshot
+		inSequence = event.FirstUpdateID <= book.lastUpdateID+1
+	case event.PrevFinalUpdateID != 0: // Futures events reference the previous event
+		inSequence = event.PrevFinalUpdateID == book.lastUpdateID
+	default: // Spot events follow each other without gaps
+		inSequence = event.FirstUpdateID == book.lastUpdateID+1
+	}
+
+	if !inSequence {
+		delete(session.books, pair) // Rebuild the book from a new snapshot
+
+		return false
+	}
+
+	if book.synced {
+		e.orderbookService.ApplyDelta(pair, event.Asks, event.Bids)
+	} else { // The book was built from a new snapshot, so it replaces the stored one
+		applyDepthLevels(book.asks, event.Asks)
+		applyDepthLevels(book.bids, event.Bids)
+
+		e.orderbookService.Upsert(pair, depthLevels(book.asks), depthLevels(book.bids))
+
+		book.asks, book.bids = nil, nil // The order book service holds the levels from now on
+	}
+
+	book.synced = true
+	book.lastUpdateID = event.FinalUpdateID
+
+	e.recordFetchSuccess()
+
+	return true
+}
+
+// getDepthSnapshot fetches the order book snapshot of the pair over REST.
+//
+// The response is checked like the one of GetOrderbookDataFromExchange: a response without a body,
+// a non-2xx response, which also backs off the next requests, and a response which isn't JSON
+// fail the snapshot instead of being parsed as an empty book.
+//
+// Returns:
+//   - *binanceDepthBook: The book built from the snapshot.
+//   - error: An error if the request or the parsing fails.
+func (e *ExchangeData) getDepthSnapshot(ctx context.Context, pair string) (*binanceDepthBook, error) {
+	resp, err := e.httpRequestService.GetWithRetry(ctx, e.urlFormatter(e.orderbookUrlForGetRequest, pair), e.requestHeaders, requestAttempts, requestBackoff)
+	if err != nil || resp.Body == nil {
+		return nil, responseError(err)
+	}
+	defer resp.Body.Close() // Ensure response body is closed after reading
+
+	bodyBytes, err := readBody(resp.Body, e.orderbookBodyLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNonJsonResponse(resp, bodyBytes) {
+		return nil, e.warnNonJsonResponse(resp, bodyBytes, e.orderbookUrlForGetRequest, zap.String("pair", pair))
+	}
+
+	if !isSuccessStatus(resp.StatusCode) {
+		e.throttle(resp) // The exchange applies the same rate limits as to the polled order books
+
+		return nil, errUnexpectedStatus(resp.StatusCode)
+	}
+
+	var model models.BinanceOrderbookJSONResponse
+
+	if err := json.Unmarshal(bodyBytes, &model); err != nil {
+		return nil, errUnmarshal("orderbook snapshot", e.exchangeName)
+	}
+
+	if model.LastUpdateID == 0 {
+		return nil, errors.New("empty orderbook snapshot of " + pair)
+	}
+
+	book := &binanceDepthBook{
+		lastUpdateID: model.LastUpdateID,
+		asks:         make(map[string]interface{}, len(model.Asks)),
+		bids:         make(map[string]interface{}, len(model.Bids)),
+	}
+
+	applyDepthLevels(book.asks, model.Asks)
+	applyDepthLevels(book.bids, model.Bids)
+
+	return book, nil
+}
+
+// applyDepthLevels sets the quantities of the price levels into the book side.
+// A level with zero quantity is removed from the book side.
+func applyDepthLevels(side map[string]interface{}, levels [][]interface{}) {
+	for _, level := range levels {
+		if len(level) < 2 {
+			continue
+		}
+
+		price := fmt.Sprintf("%v", level[0])
+
+		if cast.ToFloat64(level[1]) == 0 {
+			delete(side, price)
+		} else {
+			side[price] = level[1]
+		}
+	}
+}
+
+// depthLevels converts the book side into the price/quantity pairs accepted by the order book service.
+func depthLevels(side map[string]interface{}) [][]interface{} {
+	levels := make([][]interface{}, 0, len(side))
+
+	for price, quantity := range side {
+		levels = append(levels, []interface{}{price, quantity})
+	}
+
+	return levels
+}
@@ -0,0 +1,435 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cvs/internal/config"
+	"cvs/internal/models"
+	"cvs/internal/service"
+	"cvs/internal/service/logger"
+	"cvs/internal/service/orderbook"
+
+	"github.com/fasthttp/websocket"
+	"github.com/goccy/go-json"
+	cmap "github.com/orcaman/concurrent-map/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDepthStream = "btcusdt@depth@100ms" // Stream of the pair fed to the depth sessions in the tests
+
+// newDepthTestExchange returns the exchange whose snapshots are served by the handler and a session
+// subscribed to the depth stream of BTC/USDT. The session is served until the test ends.
+func newDepthTestExchange(t *testing.T, handler http.HandlerFunc) (*ExchangeData, *binanceDepthSession) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	testLogger := logger.NewApiLogger(&config.Config{Logger: config.Logger{Level: "fatal"}}) // Keep the expected errors out of the test output
+	testLogger.InitLogger()
+
+	exchangeData := &ExchangeData{
+		exchangeName:              "binance_spot",
+		httpRequestService:        service.NewHttpRequestService(time.Second, ""),
+		logger:                    testLogger,
+		orderbookService:          orderbook.NewOrderbook(),
+		allPairsOfExchange:        cmap.New[models.ExchangePairs](),
+		pairsSubscribed:           cmap.New[bool](),
+		orderbookUrlForGetRequest: server.URL + "/depth?symbol=",
+		urlFormatter:              binanceUrlFormatter,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	session := newBinanceDepthSession(ctx, nil)
+	session.streams[testDepthStream] = "BTC/USDT"
+
+	return exchangeData, session
+}
+
+// snapshotHandler serves the snapshots of the given last update IDs by request, the last one repeats.
+func snapshotHandler(lastUpdateIDs ...int64) http.HandlerFunc {
+	var requests atomic.Int32
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		request := int(requests.Add(1))
+
+		json.NewEncoder(w).Encode(models.BinanceOrderbookJSONResponse{
+			LastUpdateID: lastUpdateIDs[min(request, len(lastUpdateIDs))-1],
+			Asks:         [][]interface{}{{"101", "2"}, {"102", "3"}},
+			Bids:         [][]interface{}{{"99", "1"}},
+		})
+	}
+}
+
+// feedDepthEvents passes the events of BTC/USDT to the session as the messages of its stream.
+func feedDepthEvents(t *testing.T, exchangeData *ExchangeData, session *binanceDepthSession, events ...models.BinanceDepthEvent) {
+	for _, event := range events {
+		message, err := json.Marshal(models.BinanceDepthStreamJSONResponse{Stream: testDepthStream, Data: event})
+		assert.NoError(t, err)
+
+		exchangeData.handleDepthEvent(session, message)
+	}
+}
+
+// receiveDepthSnapshot waits for the snapshot fetched in the background and passes it to the session.
+func receiveDepthSnapshot(t *testing.T, exchangeData *ExchangeData, session *binanceDepthSession) {
+	select {
+	case snapshot := <-session.snapshots:
+		exchangeData.handleDepthSnapshot(session, snapshot)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the snapshot wasn't fetched")
+	}
+}
+
+// TestHandleDepthEvent tests that the events buffered while the snapshot is fetched and the events received
+// afterwards are applied in sequence, and that the book is discarded on a gap of the spot or futures streams.
+func TestHandleDepthEvent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string                     // Name of the test case
+		buffered         []models.BinanceDepthEvent // Events received while the snapshot of update 100 is fetched
+		live             []models.BinanceDepthEvent // Events received after the snapshot
+		expectedBook     bool                       // Whether the book is expected to be kept
+		expectedUpdateID int64                      // Expected ID of the last update applied to the kept book
+		expectedAsks     map[string]interface{}     // Expected ask levels of the kept book or of its snapshot, if no event was applied
+	}{
+		{
+			name:             "Stale Events Dropped",
+			buffered:         []models.BinanceDepthEvent{{FirstUpdateID: 90, FinalUpdateID: 95, Asks: [][]interface{}{{"101", "9"}}}},
+			live:             []models.BinanceDepthEvent{{FirstUpdateID: 96, FinalUpdateID: 100, Asks: [][]interface{}{{"101", "9"}}}},
+			expectedBook:     true,
+			expectedUpdateID: 100,
+			expectedAsks:     map[string]interface{}{"101": "2", "102": "3"}, // Both events are included in the snapshot
+		},
+		{
+			name: "First Event Overlaps Snapshot",
+			buffered: []models.BinanceDepthEvent{
+				{FirstUpdateID: 90, FinalUpdateID: 95},
+				{FirstUpdateID: 96, FinalUpdateID: 105, Asks: [][]interface{}{{"101", "0"}, {"103", "4"}}},
+			},
+			expectedBook:     true,
+			expectedUpdateID: 105,
+			expectedAsks:     map[string]interface{}{"102": "3", "103": "4"}, // A zero quantity removes the level
+		},
+		{
+			name:     "First Event After Gap",
+			buffered: []models.BinanceDepthEvent{{FirstUpdateID: 102, FinalUpdateID: 110}}, // Update 101 is missing
+		},
+		{
+			name:             "Spot Events In Sequence",
+			buffered:         []models.BinanceDepthEvent{{FirstUpdateID: 99, FinalUpdateID: 101}},
+			live:             []models.BinanceDepthEvent{{FirstUpdateID: 102, FinalUpdateID: 103, Asks: [][]interface{}{{"104", "5"}}}},
+			expectedBook:     true,
+			expectedUpdateID: 103,
+			expectedAsks:     map[string]interface{}{"101": "2", "102": "3", "104": "5"},
+		},
+		{
+			name:     "Spot Gap",
+			buffered: []models.BinanceDepthEvent{{FirstUpdateID: 99, FinalUpdateID: 101}},
+			live:     []models.BinanceDepthEvent{{FirstUpdateID: 103, FinalUpdateID: 104}}, // Update 102 is missing
+		},
+		{
+			name:             "Futures Events Reference Previous Event",
+			buffered:         []models.BinanceDepthEvent{{FirstUpdateID: 99, FinalUpdateID: 101, PrevFinalUpdateID: 90}},
+			live:             []models.BinanceDepthEvent{{FirstUpdateID: 105, FinalUpdateID: 110, PrevFinalUpdateID: 101}}, // The IDs of the futures events aren't contiguous
+			expectedBook:     true,
+			expectedUpdateID: 110,
+			expectedAsks:     map[string]interface{}{"101": "2", "102": "3"},
+		},
+		{
+			name:     "Futures Gap",
+			buffered: []models.BinanceDepthEvent{{FirstUpdateID: 99, FinalUpdateID: 101, PrevFinalUpdateID: 90}},
+			live:     []models.BinanceDepthEvent{{FirstUpdateID: 105, FinalUpdateID: 110, PrevFinalUpdateID: 103}}, // The event after 101 is missing
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			exchangeData, session := newDepthTestExchange(t, snapshotHandler(100))
+
+			feedDepthEvents(t, exchangeData, session, tc.buffered...)
+			assert.Len(t, session.pending["BTC/USDT"], len(tc.buffered)) // Buffered until the snapshot arrives
+			assert.NotContains(t, session.books, "BTC/USDT")
+
+			receiveDepthSnapshot(t, exchangeData, session)
+			feedDepthEvents(t, exchangeData, session, tc.live...)
+
+			book, ok := session.books["BTC/USDT"]
+			assert.Equal(t, tc.expectedBook, ok)
+			assert.Empty(t, session.pending) // No new snapshot was requested
+
+			if !tc.expectedBook {
+				return
+			}
+
+			assert.Equal(t, tc.expectedUpdateID, book.lastUpdateID)
+
+			asks := book.asks // The levels of the snapshot are held until the first event is applied
+			if book.synced {
+				asks = exchangeData.orderbookService.Asks("BTC/USDT")
+			}
+			assert.Equal(t, tc.expectedAsks, asks)
+		})
+	}
+}
+
+// TestApplyDepthEventMatchesUpsert tests that the book updated by the events in sequence is the same
+// as the full upsert of the snapshot with the events applied, and that the synced book drops its levels.
+func TestApplyDepthEventMatchesUpsert(t *testing.T) {
+	t.Parallel()
+
+	exchangeData, session := newDepthTestExchange(t, snapshotHandler(100))
+
+	events := []models.BinanceDepthEvent{
+		{FirstUpdateID: 99, FinalUpdateID: 101, Asks: [][]interface{}{{"103", "1"}}, Bids: [][]interface{}{{"98", "6"}}},
+		{FirstUpdateID: 102, FinalUpdateID: 103, Asks: [][]interface{}{{"101", "0"}, {"102", "7"}}, Bids: [][]interface{}{{"97", "2"}}},
+		{FirstUpdateID: 104, FinalUpdateID: 106, Asks: [][]interface{}{{"100.5", "4"}}, Bids: [][]interface{}{{"99", "0.00000000"}, {"98", "1"}}},
+		{FirstUpdateID: 107, FinalUpdateID: 107, Asks: [][]interface{}{{"105", "0"}}}, // A level which isn't in the book
+	}
+
+	feedDepthEvents(t, exchangeData, session, events[0])
+	receiveDepthSnapshot(t, exchangeData, session)
+	feedDepthEvents(t, exchangeData, session, events[1:]...)
+
+	// Build the same book from the snapshot served by snapshotHandler and upsert it at once
+	asks := map[string]interface{}{"101": "2", "102": "3"}
+	bids := map[string]interface{}{"99": "1"}
+	for _, event := range events {
+		applyDepthLevels(asks, event.Asks)
+		applyDepthLevels(bids, event.Bids)
+	}
+
+	upserted := orderbook.NewOrderbook()
+	upserted.Upsert("BTC/USDT", depthLevels(asks), depthLevels(bids))
+
+	expected, ok := upserted.Snapshot("BTC/USDT", 0)
+	assert.True(t, ok)
+
+	actual, ok := exchangeData.orderbookService.Snapshot("BTC/USDT", 0)
+	assert.True(t, ok)
+	assert.Equal(t, expected, actual)
+
+	assert.Equal(t, upserted.Asks("BTC/USDT"), exchangeData.orderbookService.Asks("BTC/USDT"))
+	assert.Equal(t, upserted.Bids("BTC/USDT"), exchangeData.orderbookService.Bids("BTC/USDT"))
+
+	book := session.books["BTC/USDT"]
+	if assert.NotNil(t, book) {
+		assert.Equal(t, int64(107), book.lastUpdateID)
+		assert.Nil(t, book.asks) // The order book service holds the levels of the synced book
+		assert.Nil(t, book.bids)
+	}
+}
+
+// TestHandleDepthEventRebuild tests that the book discarded on a gap is rebuilt from a new snapshot on the next event,
+// and that the snapshot of a pair unsubscribed while it was fetched is discarded.
+func TestHandleDepthEventRebuild(t *testing.T) {
+	t.Parallel()
+
+	exchangeData, session := newDepthTestExchange(t, snapshotHandler(100, 200))
+
+	feedDepthEvents(t, exchangeData, session, models.BinanceDepthEvent{FirstUpdateID: 99, FinalUpdateID: 101})
+	receiveDepthSnapshot(t, exchangeData, session)
+
+	feedDepthEvents(t, exchangeData, session, models.BinanceDepthEvent{FirstUpdateID: 150, FinalUpdateID: 160}) // A gap
+	assert.NotContains(t, session.books, "BTC/USDT")
+
+	feedDepthEvents(t, exchangeData, session, models.BinanceDepthEvent{FirstUpdateID: 195, FinalUpdateID: 205})
+	receiveDepthSnapshot(t, exchangeData, session)
+
+	book, ok := session.books["BTC/USDT"]
+	if assert.True(t, ok) {
+		assert.Equal(t, int64(205), book.lastUpdateID) // Built from the second snapshot
+	}
+
+	snapshot, ok := exchangeData.BestPrices("BTC/USDT")
+	assert.True(t, ok)
+	assert.Equal(t, 101.0, snapshot.BestAsk)
+
+	delete(session.books, "BTC/USDT")
+	feedDepthEvents(t, exchangeData, session, models.BinanceDepthEvent{FirstUpdateID: 206, FinalUpdateID: 207})
+	delete(session.pending, "BTC/USDT") // Unsubscribed while the snapshot is fetched, see syncWebsocketSubscriptions
+
+	receiveDepthSnapshot(t, exchangeData, session)
+	assert.NotContains(t, session.books, "BTC/USDT")
+}
+
+// TestGetDepthSnapshot tests that the snapshot responses are checked like the polled order books.
+func TestGetDepthSnapshot(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string // Name of the test case
+		status      int    // Status code of the response
+		contentType string // Content type of the response
+		body        string // Body of the response
+		expectedErr error  // Expected error, nil if the snapshot is expected to be parsed
+		throttled   bool   // Whether the response is expected to be counted as rate limited
+	}{
+		{
+			name:   "Snapshot",
+			status: http.StatusOK,
+			body:   `{"lastUpdateId":100,"asks":[["101","2"]],"bids":[["99","1"]]}`,
+		},
+		{
+			name:        "Rate Limited",
+			status:      http.StatusTooManyRequests,
+			body:        `{"code":-1003,"msg":"Too many requests"}`,
+			expectedErr: errUnexpectedStatus(http.StatusTooManyRequests),
+			throttled:   true,
+		},
+		{
+			name:        "Ban Page",
+			status:      http.StatusOK,
+			contentType: "text/html",
+			body:        `<html>banned</html>`,
+			expectedErr: errNonJsonResponse,
+		},
+		{
+			name:        "Empty Snapshot",
+			status:      http.StatusOK,
+			body:        `{}`,
+			expectedErr: errors.New("empty orderbook snapshot of BTC/USDT"),
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			exchangeData, _ := newDepthTestExchange(t, func(w http.ResponseWriter, r *http.Request) {
+				if tc.contentType != "" {
+					w.Header().Set("Content-Type", tc.contentType)
+				}
+
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			})
+
+			book, err := exchangeData.getDepthSnapshot(context.Background(), "BTC/USDT")
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(100), book.lastUpdateID)
+				assert.Equal(t, map[string]interface{}{"101": "2"}, book.asks)
+			} else {
+				assert.Nil(t, book)
+				assert.ErrorContains(t, err, tc.expectedErr.Error())
+			}
+
+			assert.Equal(t, tc.throttled, exchangeData.rateLimitHits > 0)
+		})
+	}
+}
+
+// TestApplyDepthLevels tests that the levels set, replace and remove the quantities of the book side.
+func TestApplyDepthLevels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string                 // Name of the test case
+		levels   [][]interface{}        // Levels applied to the side holding 100: 1 and 101: 2
+		expected map[string]interface{} // Expected side after the levels are applied
+	}{
+		{
+			name:     "New Level",
+			levels:   [][]interface{}{{"102", "3"}},
+			expected: map[string]interface{}{"100": "1", "101": "2", "102": "3"},
+		},
+		{
+			name:     "Replaced Quantity",
+			levels:   [][]interface{}{{"101", "5"}},
+			expected: map[string]interface{}{"100": "1", "101": "5"},
+		},
+		{
+			name:     "Zero Quantity Removes Level",
+			levels:   [][]interface{}{{"100", "0.00000000"}},
+			expected: map[string]interface{}{"101": "2"},
+		},
+		{
+			name:     "Zero Quantity Of Missing Level",
+			levels:   [][]interface{}{{"105", "0"}},
+			expected: map[string]interface{}{"100": "1", "101": "2"},
+		},
+		{
+			name:     "Incomplete Level Skipped",
+			levels:   [][]interface{}{{"103"}, {}},
+			expected: map[string]interface{}{"100": "1", "101": "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			side := map[string]interface{}{"100": "1", "101": "2"}
+			applyDepthLevels(side, tc.levels)
+
+			assert.Equal(t, tc.expected, side)
+		})
+	}
+}
+
+// TestSyncWebsocketSubscriptionsBlacklist tests that the blacklisted pairs aren't streamed even if subscribed.
+func TestSyncWebsocketSubscriptionsBlacklist(t *testing.T) {
+	t.Parallel()
+
+	requests := make(chan models.BinanceWebsocketRequest, 1) // Subscription requests received by the server
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			var request models.BinanceWebsocketRequest
+			if err := conn.ReadJSON(&request); err != nil {
+				return
+			}
+
+			requests <- request
+		}
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	exchangeData, _ := newDepthTestExchange(t, snapshotHandler(100))
+	exchangeData.pairBlacklist = map[string]bool{"LUNA/USDT": true}
+	exchangeData.pairsSubscribed.Set("BTC/USDT", true)
+	exchangeData.pairsSubscribed.Set("LUNA/USDT", true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	session := newBinanceDepthSession(ctx, conn)
+	assert.NoError(t, exchangeData.syncWebsocketSubscriptions(session))
+
+	select {
+	case request := <-requests:
+		assert.Equal(t, "SUBSCRIBE", request.Method)
+		assert.Equal(t, []string{testDepthStream}, request.Params) // The blacklisted pair isn't subscribed to
+	case <-time.After(5 * time.Second):
+		t.Fatal("the subscription request wasn't sent")
+	}
+
+	assert.Equal(t, map[string]string{testDepthStream: "BTC/USDT"}, session.streams)
+}
@@ -1,18 +1,19 @@
 package exchange
 
 import (
+	"errors"
 	"strings"
 	"time"
 
 	"cvs/internal/models"
-	"cvs/internal/service"
-	"cvs/internal/service/logger"
 	"cvs/internal/service/orderbook"
 
 	"github.com/goccy/go-json"
 	cmap "github.com/orcaman/concurrent-map/v2"
 )
 
+const bybitInversePerpetualContracts = "InversePerpetual" // Contract type of the inverse perpetual symbols
+
 // Overall data for all sections of the Bybit exchange
 var (
 	bybitTimeBetweenRequests = 3 * time.Second                     // Time interval between requests to the Bybit API
@@ -30,6 +31,21 @@ var (
 		return model.Result.Asks, model.Result.Bids, err
 	}
 
+	// Function to parse the last traded price from the Bybit tickers response
+	bybitTickerJsonParse = func(bodyBytes []byte) (float64, error) {
+		var model models.BybitTickersJSONResponse
+
+		if err := json.Unmarshal(bodyBytes, &model); err != nil {
+			return 0, err
+		}
+
+		if model.RetCode != 0 || len(model.Result.List) == 0 { // Bybit reports failures in the code and the message of the envelope
+			return 0, errors.New(model.RetMsg)
+		}
+
+		return parseLastPrice(model.Result.List[0].LastPrice)
+	}
+
 	// Function to format Bybit API URLs with the trading pair
 	bybitUrlFormatter = func(url, pair string) string {
 		pairFormatted := strings.Replace(pair, "/", "", -1)                 // Remove slashes from the pair string
@@ -38,7 +54,9 @@ var (
 		return replacer.Replace(url) // Return the formatted URL
 	}
 
-	// Function to parse exchange pairs from Bybit API response
+	// Function to parse exchange pairs from Bybit API response.
+	// The requests are made by the symbol rebuilt from the pair, see bybitUrlFormatter, so the instruments whose
+	// symbol differs from it are skipped, e.g. the USDC perpetual "ETHPERP" would be requested as "ETHUSDC".
 	bybitExchangePairsJsonParse = func(exchangeName string, bodyBytes []byte) ([]models.ExchangePairs, error) {
 		var model models.BybitPairsJSONResponse
 
@@ -51,9 +69,40 @@ var (
 		var exchangePairsSlice []models.ExchangePairs // Slice to hold parsed exchange pairs
 
 		for i := 0; i < len(model.Result.List); i++ { // Iterate over all symbols in pairs data
+			if model.Result.List[i].Symbol != model.Result.List[i].BaseCoin+model.Result.List[i].QuoteCoin {
+				continue // The order book of the pair would be requested by another symbol
+			}
+
+			exchangePairsSlice = append(exchangePairsSlice, models.ExchangePairs{
+				Pair:     model.Result.List[i].BaseCoin + "/" + model.Result.List[i].QuoteCoin, // Construct pair string
+				Exchange: exchangeName,                                                         // Set exchange name
+			})
+		}
+
+		return exchangePairsSlice, nil // Return the slice of exchange pairs
+	}
+
+	// Function to parse the perpetual pairs from Bybit inverse API response.
+	// The inverse futures share the base and quote coins of the perpetual ones, so they are skipped.
+	bybitInverseExchangePairsJsonParse = func(exchangeName string, bodyBytes []byte) ([]models.ExchangePairs, error) {
+		var model models.BybitPairsJSONResponse
+
+		// Unmarshal the response body into jsonData to inspect the response
+		err := json.Unmarshal(bodyBytes, &model)
+		if err != nil {
+			return []models.ExchangePairs{}, errUnmarshal("exchange pairs", exchangeName) // Return error if unmarshalling fails
+		}
+
+		var exchangePairsSlice []models.ExchangePairs // Slice to hold parsed exchange pairs
+
+		for _, instrument := range model.Result.List {
+			if instrument.ContractType != bybitInversePerpetualContracts {
+				continue // Skip the inverse futures with a delivery date, e.g. "BTCUSDH25"
+			}
+
 			exchangePairsSlice = append(exchangePairsSlice, models.ExchangePairs{
-				Pair:     model.Result.List[i].BaseCoin + "/" + model.Result.List[i].BaseCoin, // Construct pair string
-				Exchange: exchangeName,                                                        // Set exchange name
+				Pair:     instrument.BaseCoin + "/" + instrument.QuoteCoin, // Construct pair string, e.g. "BTC/USD"
+				Exchange: exchangeName,                                     // Set exchange name
 			})
 		}
 
@@ -61,43 +110,36 @@ var (
 	}
 )
 
+// init registers the Bybit exchanges, so they are created and started by InitAllExchanges.
+func init() {
+	RegisterExchange("bybit", NewBybit)
+}
+
 // NewBybit initializes instances of different Bybit exchanges.
 //
 // This function creates and returns a slice of Exchange instances for various Bybit exchanges,
-// including Spot and Futures exchanges. It uses the provided user service, user pairs service,
+// including Spot, linear Futures and inverse exchanges. It uses the provided user service, user pairs service,
 // HTTP request service, and found volume service to set up each exchange's data.
 //
 // Parameters:
-//   - userService: The service for managing user data.
-//   - userPairsService: The service for managing user pairs data.
-//   - httpRequestService: The service for making HTTP requests.
-//   - foundVolumeService: The service for managing found volumes.
+//   - deps: The services and the settings shared by all exchanges, see Dependencies.
 //
 // Returns:
 //   - []Exchange: A slice containing instances of different Bybit exchanges.
-func NewBybit(
-	userService service.UserService,
-	userPairsService service.UserPairsService,
-	httpRequestService service.HttpRequest,
-	foundVolumeService service.FoundVolumesService,
-	logger logger.Logger,
-) []Exchange {
+func NewBybit(deps Dependencies) []Exchange {
 	var bybits []Exchange // Slice to hold instances of different Bybit exchanges
 	initFunctions := []func(exchangesData *ExchangeData) *ExchangeData{
 		setBybitSpotData,
 		setBybitFuturesData,
+		setBybitInverseData,
 	}
 
 	for _, function := range initFunctions {
-		exchangeData := setBybitOverallData(
-			userService,
-			userPairsService,
-			httpRequestService,
-			foundVolumeService,
-			logger,
-		)
-
-		bybits = append(bybits, function(exchangeData))
+		exchangeData := setBybitOverallData(deps)
+		exchangeData = function(exchangeData)
+		exchangeData.applySettings(deps) // Apply the settings configured for the exchange
+
+		bybits = append(bybits, exchangeData)
 	}
 
 	return bybits // Return the slice of Bybit exchanges
@@ -110,26 +152,17 @@ func NewBybit(
 // with settings for handling trading pairs, order books, and request formatting.
 //
 // Parameters:
-//   - userService: The service for managing user data.
-//   - userPairsService: The service for managing user pairs data.
-//   - httpRequestService: The service for making HTTP requests.
-//   - foundVolumeService: The service for managing found volumes.
+//   - deps: The services shared by all exchanges, see Dependencies.
 //
 // Returns:
 //   - *exchange: A pointer to the initialized exchange struct, ready for use in API interactions.
-func setBybitOverallData(
-	userService service.UserService,
-	userPairsService service.UserPairsService,
-	httpRequestService service.HttpRequest,
-	foundVolumeService service.FoundVolumesService,
-	logger logger.Logger,
-) *ExchangeData {
+func setBybitOverallData(deps Dependencies) *ExchangeData {
 	bybitExchangesData := ExchangeData{
-		userService:            userService,
-		userPairsService:       userPairsService,
-		httpRequestService:     httpRequestService,
-		foundVolumesService:    foundVolumeService,
-		logger:                 logger,
+		userService:            deps.UserService,
+		userPairsService:       deps.UserPairsService,
+		httpRequestService:     deps.HttpRequestService,
+		foundVolumesService:    deps.FoundVolumesService,
+		logger:                 deps.Logger,
 		pairsJsonModel:         bybitPairsJsonModel,              // Set pairs JSON model for exchanges
 		orderbookJsonModel:     bybitOrderbookJsonModel,          // Set orderbook JSON model for exchanges
 		urlFormatter:           bybitUrlFormatter,                // Set URL formatter function for exchanges
@@ -138,9 +171,13 @@ func setBybitOverallData(
 		pairsSubscribed:        cmap.New[bool](),                 // Initialize subscribed pairs list as empty
 		allPairsOfExchange:     cmap.New[models.ExchangePairs](), // Initialize concurrent map for all pairs of the exchange
 		orderbookJsonParse:     bybitOrderbookJsonParse,          // Set order book JSON parsing function for exchanges
-		exchangePairsJsonParse: bybitExchangePairsJsonParse,      // Set exchange pairs JSON parsing function for exchanges
+		tickerJsonParse:        bybitTickerJsonParse,
+		lastPrices:             cmap.New[lastPrice](),
+		exchangePairsJsonParse: bybitExchangePairsJsonParse, // Set exchange pairs JSON parsing function for exchanges
 	}
 
+	bybitExchangesData.observeRateLimitUsage() // Slow down as the requests approach the limit, which the responses tell
+
 	return &bybitExchangesData
 }
 
@@ -160,6 +197,7 @@ func setBybitSpotData(exchangesData *ExchangeData) *ExchangeData {
 	exchangesData.exchangeName = "bybit_spot"                                                                                          // Set the name of the exchange to "bybitSpot"
 	exchangesData.pairsUrlForGetRequest = "https://api.bytick.com/v5/market/instruments-info?category=" + category                     // URL for getting pairs information
 	exchangesData.orderbookUrlForGetRequest = "https://api.bytick.com/v5/market/orderbook?category=" + category + "&symbol=&limit=200" // URL for getting order book data
+	exchangesData.tickerUrlForGetRequest = "https://api.bytick.com/v5/market/tickers?category=" + category + "&symbol="                // URL for getting the last traded price
 
 	return exchangesData // Return updated exchanges data
 }
@@ -180,6 +218,30 @@ func setBybitFuturesData(exchangesData *ExchangeData) *ExchangeData {
 	exchangesData.exchangeName = "bybit_futures"                                                                                       // Set the name of the exchange to "bybitFutures"
 	exchangesData.pairsUrlForGetRequest = "https://api.bytick.com/v5/market/instruments-info?category=" + category                     // URL for getting futures pairs information
 	exchangesData.orderbookUrlForGetRequest = "https://api.bytick.com/v5/market/orderbook?category=" + category + "&symbol=&limit=200" // URL for getting futures order book data
+	exchangesData.tickerUrlForGetRequest = "https://api.bytick.com/v5/market/tickers?category=" + category + "&symbol="                // URL for getting the last traded futures price
+
+	return exchangesData // Return updated exchanges data
+}
+
+// setBybitInverseData sets up data specific to the Bybit inverse perpetual exchange.
+//
+// This function configures the exchange struct with settings specific to the Bybit inverse exchange,
+// including URLs for API calls and initializing necessary fields. The contracts are margined in the
+// base coin, so their volumes are in contracts of a fixed USD value rather than in the base asset.
+//
+// Parameters:
+//   - exchangesData: A pointer to the exchange struct to be configured.
+//
+// Returns:
+//   - *exchange: A pointer to the updated exchange struct.
+func setBybitInverseData(exchangesData *ExchangeData) *ExchangeData {
+	const category = "inverse"
+
+	exchangesData.exchangeName = "bybit_inverse"                                                                                       // Set the name of the exchange to "bybitInverse"
+	exchangesData.pairsUrlForGetRequest = "https://api.bytick.com/v5/market/instruments-info?category=" + category                     // URL for getting inverse pairs information
+	exchangesData.orderbookUrlForGetRequest = "https://api.bytick.com/v5/market/orderbook?category=" + category + "&symbol=&limit=200" // URL for getting inverse order book data
+	exchangesData.tickerUrlForGetRequest = "https://api.bytick.com/v5/market/tickers?category=" + category + "&symbol="                // URL for getting the last traded inverse price
+	exchangesData.exchangePairsJsonParse = bybitInverseExchangePairsJsonParse
 
 	return exchangesData // Return updated exchanges data
 }
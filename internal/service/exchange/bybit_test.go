@@ -0,0 +1,250 @@
+package exchange
+
+import (
+	"testing"
+
+	"cvs/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBybitExchangePairsJsonParse tests parsing of the Bybit instruments-info response into exchange pairs.
+func TestBybitExchangePairsJsonParse(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name          string                 // Name of the test case
+		exchangeName  string                 // Name of the exchange passed to the parser
+		body          string                 // Response body of the instruments-info request
+		expectedPairs []models.ExchangePairs // Pairs expected to be parsed
+		symbols       []string               // Symbols of the expected pairs, which their order books are requested by
+		expectErr     bool                   // Expected outcome: true if an error is expected
+	}{
+		{
+			name:         "Spot category",
+			exchangeName: "bybit_spot",
+			body: `{
+				"retCode": 0,
+				"retMsg": "OK",
+				"result": {
+					"category": "spot",
+					"list": [
+						{
+							"symbol": "BTCUSDT",
+							"baseCoin": "BTC",
+							"quoteCoin": "USDT",
+							"innovation": "0",
+							"status": "Trading",
+							"marginTrading": "both",
+							"lotSizeFilter": {
+								"basePrecision": "0.000001",
+								"quotePrecision": "0.00000001",
+								"minOrderQty": "0.000048",
+								"maxOrderQty": "71.73956243",
+								"minOrderAmt": "1",
+								"maxOrderAmt": "2000000"
+							},
+							"priceFilter": {"tickSize": "0.01"}
+						},
+						{
+							"symbol": "ETHBTC",
+							"baseCoin": "ETH",
+							"quoteCoin": "BTC",
+							"innovation": "0",
+							"status": "Trading",
+							"marginTrading": "none",
+							"lotSizeFilter": {
+								"basePrecision": "0.0001",
+								"quotePrecision": "0.0000001",
+								"minOrderQty": "0.0001",
+								"maxOrderQty": "2500",
+								"minOrderAmt": "0.00001",
+								"maxOrderAmt": "100"
+							},
+							"priceFilter": {"tickSize": "0.000001"}
+						}
+					]
+				},
+				"retExtInfo": {},
+				"time": 1672712468011
+			}`,
+			expectedPairs: []models.ExchangePairs{
+				{Pair: "BTC/USDT", Exchange: "bybit_spot"},
+				{Pair: "ETH/BTC", Exchange: "bybit_spot"},
+			},
+			symbols: []string{"BTCUSDT", "ETHBTC"},
+		},
+		{
+			name:         "Linear category",
+			exchangeName: "bybit_futures",
+			body: `{
+				"retCode": 0,
+				"retMsg": "OK",
+				"result": {
+					"category": "linear",
+					"list": [
+						{
+							"symbol": "BTCUSDT",
+							"contractType": "LinearPerpetual",
+							"status": "Trading",
+							"baseCoin": "BTC",
+							"quoteCoin": "USDT",
+							"launchTime": "1584230400000",
+							"deliveryTime": "0",
+							"priceScale": "2",
+							"priceFilter": {"minPrice": "0.10", "maxPrice": "199999.80", "tickSize": "0.10"},
+							"lotSizeFilter": {"maxOrderQty": "100.000", "minOrderQty": "0.001", "qtyStep": "0.001"},
+							"settleCoin": "USDT"
+						},
+						{
+							"symbol": "ETHPERP",
+							"contractType": "LinearPerpetual",
+							"status": "Trading",
+							"baseCoin": "ETH",
+							"quoteCoin": "USDC",
+							"launchTime": "1669974400000",
+							"deliveryTime": "0",
+							"priceScale": "2",
+							"priceFilter": {"minPrice": "0.05", "maxPrice": "99999.90", "tickSize": "0.05"},
+							"lotSizeFilter": {"maxOrderQty": "1500.00", "minOrderQty": "0.01", "qtyStep": "0.01"},
+							"settleCoin": "USDC"
+						}
+					],
+					"nextPageCursor": ""
+				},
+				"retExtInfo": {},
+				"time": 1672712495660
+			}`,
+			expectedPairs: []models.ExchangePairs{
+				{Pair: "BTC/USDT", Exchange: "bybit_futures"}, // The USDC perpetual isn't requested by its symbol "ETHPERP", so it is skipped
+			},
+			symbols: []string{"BTCUSDT"},
+		},
+		{
+			name:         "Invalid JSON",
+			exchangeName: "bybit_spot",
+			body:         `<html>502 Bad Gateway</html>`,
+			expectErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			pairs, err := bybitExchangePairsJsonParse(tc.exchangeName, []byte(tc.body))
+
+			if tc.expectErr {
+				assert.Error(t, err)
+				assert.Empty(t, pairs)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPairs, pairs)
+
+			for i, pair := range pairs { // The order book of every pair is requested by the symbol of its instrument
+				assert.Equal(t, "symbol="+tc.symbols[i], bybitUrlFormatter("symbol=", pair.Pair))
+			}
+		})
+	}
+}
+
+// TestBybitInverseExchangePairsJsonParse tests parsing of the Bybit inverse instruments-info response into exchange pairs.
+func TestBybitInverseExchangePairsJsonParse(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name          string                 // Name of the test case
+		body          string                 // Response body of the instruments-info request
+		expectedPairs []models.ExchangePairs // Pairs expected to be parsed
+		expectErr     bool                   // Expected outcome: true if an error is expected
+	}{
+		{
+			name: "Perpetual and delivery contracts",
+			body: `{
+				"retCode": 0,
+				"retMsg": "OK",
+				"result": {
+					"category": "inverse",
+					"list": [
+						{
+							"symbol": "BTCUSD",
+							"contractType": "InversePerpetual",
+							"status": "Trading",
+							"baseCoin": "BTC",
+							"quoteCoin": "USD",
+							"launchTime": "1542211200000",
+							"deliveryTime": "0",
+							"priceScale": "2",
+							"priceFilter": {"minPrice": "0.50", "maxPrice": "999999.00", "tickSize": "0.50"},
+							"lotSizeFilter": {"maxOrderQty": "1000000", "minOrderQty": "1", "qtyStep": "1"},
+							"settleCoin": "BTC"
+						},
+						{
+							"symbol": "BTCUSDH25",
+							"contractType": "InverseFutures",
+							"status": "Trading",
+							"baseCoin": "BTC",
+							"quoteCoin": "USD",
+							"launchTime": "1726214400000",
+							"deliveryTime": "1743148800000",
+							"priceScale": "2",
+							"priceFilter": {"minPrice": "0.50", "maxPrice": "999999.00", "tickSize": "0.50"},
+							"lotSizeFilter": {"maxOrderQty": "1000000", "minOrderQty": "1", "qtyStep": "1"},
+							"settleCoin": "BTC"
+						},
+						{
+							"symbol": "ETHUSD",
+							"contractType": "InversePerpetual",
+							"status": "Trading",
+							"baseCoin": "ETH",
+							"quoteCoin": "USD",
+							"launchTime": "1548633600000",
+							"deliveryTime": "0",
+							"priceScale": "2",
+							"priceFilter": {"minPrice": "0.05", "maxPrice": "99999.90", "tickSize": "0.05"},
+							"lotSizeFilter": {"maxOrderQty": "1000000", "minOrderQty": "1", "qtyStep": "1"},
+							"settleCoin": "ETH"
+						}
+					],
+					"nextPageCursor": ""
+				},
+				"retExtInfo": {},
+				"time": 1700000000000
+			}`,
+			expectedPairs: []models.ExchangePairs{
+				{Pair: "BTC/USD", Exchange: "bybit_inverse"},
+				{Pair: "ETH/USD", Exchange: "bybit_inverse"},
+			},
+		},
+		{
+			name:      "Invalid JSON",
+			body:      `<html>502 Bad Gateway</html>`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			pairs, err := bybitInverseExchangePairsJsonParse("bybit_inverse", []byte(tc.body))
+
+			if tc.expectErr {
+				assert.Error(t, err)
+				assert.Empty(t, pairs)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPairs, pairs)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package exchange
+
+import (
+	"sync"
+	"time"
+
+	"cvs/internal/config"
+	"cvs/internal/models"
+)
+
+const (
+	defaultBreakerFailureThreshold = 5                // Consecutive failed fetches opening the circuit breaker when the config doesn't set it
+	defaultBreakerCooldown         = 30 * time.Second // Time the requests are paused for when the config doesn't set it
+)
+
+// circuitBreaker stops the requests to an exchange which keeps failing, e.g. while it is down or has banned us,
+// so the poll loop doesn't keep hammering it and prolong the ban.
+//
+// The breaker is closed while the exchange responds. After failureThreshold consecutive failed fetches it opens
+// and no request is allowed until the cooldown passes. Then it is half-open and a single request is allowed
+// to test the recovery: a success closes the breaker and a failure opens it for another cooldown.
+//
+// The zero value is a closed breaker using the default threshold and cooldown.
+type circuitBreaker struct {
+	mu               sync.Mutex    // Guards the state of the breaker
+	failureThreshold int           // Number of consecutive failed fetches opening the breaker
+	cooldown         time.Duration // Time the requests are paused for once the breaker opens
+	failures         int           // Number of consecutive failed fetches
+	state            string        // One of the models.CircuitBreaker states, empty means closed
+	openedAt         time.Time     // Time the breaker was opened at
+}
+
+// configure sets the threshold and the cooldown of the breaker, non-positive values use the defaults.
+func (b *circuitBreaker) configure(cfg config.CircuitBreakerConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failureThreshold = cfg.FailureThreshold
+	b.cooldown = cfg.Cooldown
+}
+
+// allow reports whether a request to the exchange may be sent.
+// Once the cooldown of the open breaker passes, the breaker becomes half-open and allows the request testing the recovery,
+// the following requests aren't allowed until its result is recorded.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case models.CircuitBreakerOpen:
+		if time.Since(b.openedAt) < b.cooldownOrDefault() {
+			return false
+		}
+
+		b.state = models.CircuitBreakerHalfOpen
+
+		return true // The request testing the recovery
+	case models.CircuitBreakerHalfOpen:
+		return false // The request testing the recovery hasn't finished yet
+	default:
+		return true
+	}
+}
+
+// isClosed reports whether the exchange is considered up, so the best-effort requests, e.g. of the last prices, may be sent.
+// Unlike allow, it never takes the request testing the recovery.
+func (b *circuitBreaker) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state == "" || b.state == models.CircuitBreakerClosed
+}
+
+// retryIn returns the time left until the open breaker allows the request testing the recovery, zero if it isn't open.
+func (b *circuitBreaker) retryIn() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != models.CircuitBreakerOpen {
+		return 0
+	}
+
+	return max(b.cooldownOrDefault()-time.Since(b.openedAt), 0)
+}
+
+// recordSuccess closes the breaker and resets the consecutive failures.
+func (b *circuitBreaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.state = models.CircuitBreakerClosed
+}
+
+// recordFailure counts a failed fetch, opening the breaker after the threshold of consecutive failures
+// or if the request testing the recovery failed.
+func (b *circuitBreaker) recordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+
+	threshold := b.failureThreshold
+	if threshold <= 0 {
+		threshold = defaultBreakerFailureThreshold
+	}
+
+	if b.state == models.CircuitBreakerHalfOpen || b.failures >= threshold {
+		b.state = models.CircuitBreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// State returns the state of the breaker reported by the status of the exchange.
+func (b *circuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == "" {
+		return models.CircuitBreakerClosed
+	}
+
+	return b.state
+}
+
+// cooldownOrDefault returns the configured cooldown or the default one if it isn't set. The caller must hold mu.
+func (b *circuitBreaker) cooldownOrDefault() time.Duration {
+	if b.cooldown <= 0 {
+		return defaultBreakerCooldown
+	}
+
+	return b.cooldown
+}
@@ -0,0 +1,183 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cvs/internal/config"
+	"cvs/internal/models"
+	"cvs/internal/service"
+	"cvs/internal/service/logger"
+	"cvs/internal/service/orderbook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCircuitBreaker tests that the breaker opens after the threshold of consecutive failures,
+// allows a single request testing the recovery after the cooldown and closes once it succeeds.
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	cooldown := 20 * time.Millisecond
+
+	var breaker circuitBreaker
+	breaker.configure(config.CircuitBreakerConfig{FailureThreshold: 3, Cooldown: cooldown})
+
+	breaker.recordFailure()
+	breaker.recordFailure()
+	assert.Equal(t, models.CircuitBreakerClosed, breaker.State())
+	assert.True(t, breaker.allow())
+
+	breaker.recordFailure() // The threshold is reached
+	assert.Equal(t, models.CircuitBreakerOpen, breaker.State())
+	assert.False(t, breaker.allow())
+	assert.False(t, breaker.isClosed())
+	assert.Greater(t, breaker.retryIn(), time.Duration(0))
+
+	time.Sleep(cooldown)
+
+	assert.True(t, breaker.allow()) // The request testing the recovery
+	assert.Equal(t, models.CircuitBreakerHalfOpen, breaker.State())
+	assert.False(t, breaker.allow()) // Only one request is tested
+	assert.False(t, breaker.isClosed())
+	assert.Equal(t, time.Duration(0), breaker.retryIn())
+
+	breaker.recordFailure() // The recovery failed, the breaker opens for another cooldown
+	assert.Equal(t, models.CircuitBreakerOpen, breaker.State())
+	assert.False(t, breaker.allow())
+
+	time.Sleep(cooldown)
+
+	assert.True(t, breaker.allow())
+	breaker.recordSuccess()
+	assert.Equal(t, models.CircuitBreakerClosed, breaker.State())
+	assert.True(t, breaker.allow())
+	assert.True(t, breaker.isClosed())
+
+	breaker.recordFailure() // The failures are counted from zero again
+	assert.Equal(t, models.CircuitBreakerClosed, breaker.State())
+}
+
+// TestCircuitBreakerDefaults tests that the zero value of the breaker uses the default threshold and cooldown.
+func TestCircuitBreakerDefaults(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	var breaker circuitBreaker
+	breaker.configure(config.CircuitBreakerConfig{})
+
+	for i := 0; i < defaultBreakerFailureThreshold-1; i++ {
+		breaker.recordFailure()
+	}
+	assert.Equal(t, models.CircuitBreakerClosed, breaker.State())
+
+	breaker.recordFailure()
+	assert.Equal(t, models.CircuitBreakerOpen, breaker.State())
+	assert.InDelta(t, defaultBreakerCooldown.Seconds(), breaker.retryIn().Seconds(), 1)
+}
+
+// TestFetchOrderbooksCircuitBreaker tests that the requests to a failing exchange stop once the breaker opens
+// and a successful request testing the recovery closes it.
+func TestFetchOrderbooksCircuitBreaker(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	testLogger := logger.NewApiLogger(&config.Config{Logger: config.Logger{Level: "error"}}) // Keep the expected warnings out of the test output
+	testLogger.InitLogger()
+
+	var (
+		requests atomic.Int32
+		failing  atomic.Bool
+	)
+	failing.Store(true)
+
+	exchangeData := &ExchangeData{
+		exchangeName:     "binance_spot",
+		logger:           testLogger,
+		orderbookService: orderbook.NewOrderbook(),
+		batchFetcher: func(_ context.Context, pairs []string) (map[string]bookData, error) {
+			requests.Add(1)
+
+			if failing.Load() {
+				return nil, errors.New("exchange is down")
+			}
+
+			return map[string]bookData{}, nil
+		},
+	}
+	exchangeData.setOrderbookBatchSize(1) // Every pair is a separate request
+	exchangeData.circuitBreaker.configure(config.CircuitBreakerConfig{FailureThreshold: 2, Cooldown: 50 * time.Millisecond})
+
+	pairs := []string{"BTC/USDT", "ETH/USDT", "XRP/USDT", "SOL/USDT"}
+
+	assert.True(t, exchangeData.fetchOrderbooks(context.Background(), pairs)) // Pauses for the cooldown once the breaker opens
+	assert.Equal(t, int32(2), requests.Load())                                // The pairs after the threshold aren't requested
+	assert.Equal(t, models.CircuitBreakerOpen, exchangeData.circuitBreaker.State())
+
+	failing.Store(false)
+
+	assert.True(t, exchangeData.fetchOrderbooks(context.Background(), pairs))
+	assert.Equal(t, int32(2+len(pairs)), requests.Load())
+	assert.Equal(t, models.CircuitBreakerClosed, exchangeData.circuitBreaker.State())
+}
+
+// TestGetOrderbookDataCircuitBreakerPairFailures tests that the failures caused by the pairs, e.g. an empty side
+// or a 400 of a delisted symbol, don't open the breaker, while the failures of the exchange itself do.
+func TestGetOrderbookDataCircuitBreakerPairFailures(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(int(status.Load()))
+
+		if r.URL.Query().Get("symbol") == "DELISTEDUSDT" {
+			w.Write([]byte(`{"code":-1121,"msg":"Invalid symbol."}`))
+
+			return
+		}
+
+		w.Write([]byte(`{"lastUpdateId":1,"bids":[],"asks":[["101","1"]]}`)) // The bids side is empty
+	}))
+	defer server.Close()
+
+	testLogger := logger.NewApiLogger(&config.Config{Logger: config.Logger{Level: "fatal"}}) // Keep the expected errors out of the test output
+	testLogger.InitLogger()
+
+	exchangeData := &ExchangeData{
+		exchangeName:              "binance_spot",
+		httpRequestService:        service.NewHttpRequestService(time.Second, ""),
+		logger:                    testLogger,
+		orderbookService:          orderbook.NewOrderbook(),
+		orderbookUrlForGetRequest: server.URL + "/depth?symbol=",
+		urlFormatter:              binanceUrlFormatter,
+		orderbookJsonParse:        binanceOrderbookJsonParse,
+	}
+	exchangeData.circuitBreaker.configure(config.CircuitBreakerConfig{FailureThreshold: 2, Cooldown: time.Minute})
+
+	for _, pair := range []string{"BTC/USDT", "ETH/USDT", "XRP/USDT", "SOL/USDT"} { // Several empty-side pairs in a row
+		exchangeData.GetOrderbookDataFromExchange(context.Background(), pair)
+	}
+	assert.Equal(t, models.CircuitBreakerClosed, exchangeData.circuitBreaker.State())
+
+	status.Store(http.StatusBadRequest)
+	for i := 0; i < 3; i++ {
+		exchangeData.GetOrderbookDataFromExchange(context.Background(), "DELISTED/USDT")
+	}
+	assert.Equal(t, models.CircuitBreakerClosed, exchangeData.circuitBreaker.State())
+
+	exchangeData.statusMu.Lock()
+	assert.Equal(t, errUnexpectedStatus(http.StatusBadRequest).Error(), exchangeData.lastError) // Still reported by the status
+	exchangeData.statusMu.Unlock()
+
+	status.Store(http.StatusTooManyRequests)
+	for i := 0; i < 2; i++ {
+		exchangeData.GetOrderbookDataFromExchange(context.Background(), "BTC/USDT")
+	}
+	assert.Equal(t, models.CircuitBreakerOpen, exchangeData.circuitBreaker.State()) // The exchange limits the requests
+}
@@ -0,0 +1,189 @@
+package exchange
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"cvs/internal/models"
+	"cvs/internal/service/orderbook"
+
+	"github.com/goccy/go-json"
+	cmap "github.com/orcaman/concurrent-map/v2"
+)
+
+// Overall data for all sections of the Coinbase exchange
+var (
+	coinbaseTimeBetweenRequests = 3 * time.Second                        // Time interval between requests to the Coinbase API
+	coinbasePairsJsonModel      = models.CoinbasePairsJSONResponse{}     // Model for Coinbase pairs JSON response
+	coinbaseOrderbookJsonModel  = models.CoinbaseOrderbookJSONResponse{} // Model for Coinbase order book JSON response
+	coinbaseOrderbookService    = orderbook.NewOrderbook()               // Instance of the order book service for managing order data
+
+	// Function to parse order book JSON response from Coinbase
+	coinbaseOrderbookJsonParse = func(bodyBytes []byte) ([][]interface{}, [][]interface{}, error) {
+		var model models.CoinbaseOrderbookJSONResponse
+
+		// Unmarshal the response body into jsonData to inspect the response
+		err := json.Unmarshal(bodyBytes, &model)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if model.Error != "" { // Coinbase reports failures in the error and the message of the response
+			return nil, nil, errors.New(model.Error + ": " + model.Message)
+		}
+
+		return coinbaseLevels(model.Pricebook.Asks), coinbaseLevels(model.Pricebook.Bids), nil
+	}
+
+	// Function to parse the last traded price from the Coinbase order book response limited to the best levels
+	coinbaseTickerJsonParse = func(bodyBytes []byte) (float64, error) {
+		var model models.CoinbaseOrderbookJSONResponse
+
+		if err := json.Unmarshal(bodyBytes, &model); err != nil {
+			return 0, err
+		}
+
+		if model.Error != "" { // Coinbase reports failures in the error and the message of the response
+			return 0, errors.New(model.Error + ": " + model.Message)
+		}
+
+		return parseLastPrice(model.Last)
+	}
+
+	// Function to format Coinbase API URLs with the trading pair, e.g. "BTC/USDT" -> "BTC-USDT"
+	coinbaseUrlFormatter = func(url, pair string) string {
+		pairFormatted := strings.Replace(pair, "/", "-", -1)                        // Separate the assets with a dash
+		replacer := strings.NewReplacer("product_id=", "product_id="+pairFormatted) // Replace "product_id=" in the URL with the formatted pair
+
+		return replacer.Replace(url) // Return the formatted URL
+	}
+
+	// Function to parse exchange pairs from Coinbase API response
+	coinbaseExchangePairsJsonParse = func(exchangeName string, bodyBytes []byte) ([]models.ExchangePairs, error) {
+		var model models.CoinbasePairsJSONResponse
+
+		// Unmarshal the response body into jsonData to inspect the response
+		err := json.Unmarshal(bodyBytes, &model)
+		if err != nil {
+			return []models.ExchangePairs{}, errUnmarshal("exchange pairs", exchangeName) // Return error if unmarshalling fails
+		}
+
+		var exchangePairsSlice []models.ExchangePairs // Slice to hold parsed exchange pairs
+
+		for _, product := range model.Products { // Iterate over all products in pairs data
+			if product.ProductType != "SPOT" || product.Status != "online" || product.TradingDisabled || product.IsDisabled {
+				continue // Skip products which can't be traded
+			}
+
+			// The product ID is used rather than the currencies, as it is what the order book is requested by
+			base, quote, ok := strings.Cut(product.ProductId, "-")
+			if !ok || base == "" || quote == "" {
+				continue
+			}
+
+			exchangePairsSlice = append(exchangePairsSlice, models.ExchangePairs{
+				Pair:     base + "/" + quote, // Construct pair string
+				Exchange: exchangeName,       // Set exchange name
+			})
+		}
+
+		return exchangePairsSlice, nil // Return the slice of exchange pairs
+	}
+)
+
+// coinbaseLevels converts the price levels of a Coinbase order book side into price and size pairs.
+func coinbaseLevels(levels []models.CoinbaseOrderbookLevel) [][]interface{} {
+	result := make([][]interface{}, 0, len(levels))
+	for _, level := range levels {
+		result = append(result, []interface{}{level.Price, level.Size})
+	}
+
+	return result
+}
+
+// init registers the Coinbase exchanges, so they are created and started by InitAllExchanges.
+func init() {
+	RegisterExchange("coinbase", NewCoinbase)
+}
+
+// NewCoinbase initializes instances of different Coinbase exchanges.
+//
+// This function creates and returns a slice of Exchange instances for the Coinbase Advanced Trade
+// Spot exchange. It uses the provided user service, user pairs service, HTTP request service,
+// and found volume service to set up each exchange's data.
+//
+// Parameters:
+//   - deps: The services and the settings shared by all exchanges, see Dependencies.
+//
+// Returns:
+//   - []Exchange: A slice containing instances of different Coinbase exchanges.
+func NewCoinbase(deps Dependencies) []Exchange {
+	var coinbases []Exchange // Slice to hold instances of different Coinbase exchanges
+	initFunctions := []func(exchangesData *ExchangeData) *ExchangeData{
+		setCoinbaseSpotData,
+	}
+
+	for _, function := range initFunctions {
+		exchangeData := setCoinbaseOverallData(deps)
+		exchangeData = function(exchangeData)
+		exchangeData.applySettings(deps) // Apply the settings configured for the exchange
+
+		coinbases = append(coinbases, exchangeData)
+	}
+
+	return coinbases // Return the slice of Coinbase exchanges
+}
+
+// setCoinbaseOverallData initializes and sets up overall data for all Coinbase exchanges.
+//
+// This function creates an instance of the exchange struct and populates it with the necessary services,
+// models, and configurations required for interacting with Coinbase exchanges. It prepares the exchange
+// with settings for handling trading pairs, order books, and request formatting.
+//
+// Parameters:
+//   - deps: The services shared by all exchanges, see Dependencies.
+//
+// Returns:
+//   - *exchange: A pointer to the initialized exchange struct, ready for use in API interactions.
+func setCoinbaseOverallData(deps Dependencies) *ExchangeData {
+	coinbaseExchangesData := ExchangeData{
+		userService:            deps.UserService,
+		userPairsService:       deps.UserPairsService,
+		httpRequestService:     deps.HttpRequestService,
+		foundVolumesService:    deps.FoundVolumesService,
+		logger:                 deps.Logger,
+		pairsJsonModel:         coinbasePairsJsonModel,           // Set pairs JSON model for exchanges
+		orderbookJsonModel:     coinbaseOrderbookJsonModel,       // Set orderbook JSON model for exchanges
+		timeBetweenRequests:    coinbaseTimeBetweenRequests,      // Set time between requests for exchanges
+		orderbookService:       coinbaseOrderbookService,         // Assign order book service instance to exchanges data
+		pairsSubscribed:        cmap.New[bool](),                 // Initialize subscribed pairs list as empty
+		allPairsOfExchange:     cmap.New[models.ExchangePairs](), // Initialize concurrent map for all pairs of the exchange
+		urlFormatter:           coinbaseUrlFormatter,             // Set URL formatter function for exchanges
+		orderbookJsonParse:     coinbaseOrderbookJsonParse,       // Set order book JSON parsing function for exchanges
+		tickerJsonParse:        coinbaseTickerJsonParse,
+		lastPrices:             cmap.New[lastPrice](),
+		exchangePairsJsonParse: coinbaseExchangePairsJsonParse, // Set exchange pairs JSON parsing function for exchanges
+	}
+
+	return &coinbaseExchangesData
+}
+
+// setCoinbaseSpotData sets up data specific to the Coinbase Spot exchange.
+//
+// This function configures the exchange struct with settings specific to the Coinbase Spot exchange,
+// including URLs for API calls and initializing necessary fields.
+//
+// Parameters:
+//   - exchangesData: A pointer to the exchange struct to be configured.
+//
+// Returns:
+//   - *exchange: A pointer to the updated exchange struct.
+func setCoinbaseSpotData(exchangesData *ExchangeData) *ExchangeData {
+	exchangesData.exchangeName = "coinbase_spot"                                                                                    // Set the name of the exchange to "coinbaseSpot"
+	exchangesData.pairsUrlForGetRequest = "https://api.coinbase.com/api/v3/brokerage/market/products?product_type=SPOT"             // URL for getting pairs information
+	exchangesData.orderbookUrlForGetRequest = "https://api.coinbase.com/api/v3/brokerage/market/product_book?product_id=&limit=250" // URL for getting order book data
+	exchangesData.tickerUrlForGetRequest = "https://api.coinbase.com/api/v3/brokerage/market/product_book?product_id=&limit=1"      // URL for getting the last traded price along with the best levels
+
+	return exchangesData // Return updated exchanges data
+}
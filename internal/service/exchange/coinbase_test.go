@@ -0,0 +1,181 @@
+package exchange
+
+import (
+	"testing"
+
+	"cvs/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCoinbaseExchangePairsJsonParse tests parsing of the Coinbase products response into exchange pairs.
+func TestCoinbaseExchangePairsJsonParse(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name          string                 // Name of the test case
+		body          string                 // Response body of the products request
+		expectedPairs []models.ExchangePairs // Pairs expected to be parsed
+		expectErr     bool                   // Expected outcome: true if an error is expected
+	}{
+		{
+			name: "Spot products",
+			body: `{
+				"products": [
+					{
+						"product_id": "BTC-USDT",
+						"price": "64012.35",
+						"base_currency_id": "BTC",
+						"quote_currency_id": "USDT",
+						"product_type": "SPOT",
+						"status": "online",
+						"trading_disabled": false,
+						"is_disabled": false,
+						"cancel_only": false,
+						"limit_only": false,
+						"post_only": false,
+						"base_increment": "0.00000001",
+						"quote_increment": "0.01",
+						"base_display_symbol": "BTC"
+					},
+					{
+						"product_id": "ETH-USD",
+						"base_currency_id": "ETH",
+						"quote_currency_id": "USD",
+						"product_type": "SPOT",
+						"status": "online"
+					},
+					{
+						"product_id": "OLD-USD",
+						"base_currency_id": "OLD",
+						"quote_currency_id": "USD",
+						"product_type": "SPOT",
+						"status": "delisted"
+					},
+					{
+						"product_id": "HALT-USD",
+						"base_currency_id": "HALT",
+						"quote_currency_id": "USD",
+						"product_type": "SPOT",
+						"status": "online",
+						"trading_disabled": true
+					},
+					{
+						"product_id": "BIT-31JAN25-CDE",
+						"product_type": "FUTURE",
+						"status": "online"
+					}
+				],
+				"num_products": 5
+			}`,
+			expectedPairs: []models.ExchangePairs{
+				{Pair: "BTC/USDT", Exchange: "coinbase_spot"},
+				{Pair: "ETH/USD", Exchange: "coinbase_spot"},
+			},
+		},
+		{
+			name:      "Invalid JSON",
+			body:      `<html>502 Bad Gateway</html>`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			pairs, err := coinbaseExchangePairsJsonParse("coinbase_spot", []byte(tc.body))
+
+			if tc.expectErr {
+				assert.Error(t, err)
+				assert.Empty(t, pairs)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPairs, pairs)
+		})
+	}
+}
+
+// TestCoinbaseOrderbookJsonParse tests parsing of the Coinbase product book response into asks and bids.
+func TestCoinbaseOrderbookJsonParse(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name         string          // Name of the test case
+		body         string          // Response body of the product book request
+		expectedAsks [][]interface{} // Asks expected to be parsed
+		expectedBids [][]interface{} // Bids expected to be parsed
+		expectErr    bool            // Expected outcome: true if an error is expected
+	}{
+		{
+			name: "Product book",
+			body: `{
+				"pricebook": {
+					"product_id": "BTC-USDT",
+					"bids": [{"price": "64010.5", "size": "0.25"}, {"price": "64009.1", "size": "1.5"}],
+					"asks": [{"price": "64011.2", "size": "0.1"}],
+					"time": "2024-10-18T12:00:00.000000Z"
+				},
+				"last": "64010.9",
+				"mid_market": "64010.85",
+				"spread_bps": "0.1"
+			}`,
+			expectedAsks: [][]interface{}{{"64011.2", "0.1"}},
+			expectedBids: [][]interface{}{{"64010.5", "0.25"}, {"64009.1", "1.5"}},
+		},
+		{
+			name:         "Empty book",
+			body:         `{"pricebook": {"product_id": "NEW-USD", "bids": [], "asks": [], "time": "2024-10-18T12:00:00Z"}}`,
+			expectedAsks: [][]interface{}{},
+			expectedBids: [][]interface{}{},
+		},
+		{
+			name:      "Error response",
+			body:      `{"error": "NOT_FOUND", "error_details": "ProductID is invalid", "message": "ProductID is invalid"}`,
+			expectErr: true,
+		},
+		{
+			name:      "Invalid JSON",
+			body:      `<html>502 Bad Gateway</html>`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			asks, bids, err := coinbaseOrderbookJsonParse([]byte(tc.body))
+
+			if tc.expectErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedAsks, asks)
+			assert.Equal(t, tc.expectedBids, bids)
+		})
+	}
+}
+
+// TestCoinbaseUrlFormatter tests that the pair is inserted into the URL as the Coinbase product ID.
+func TestCoinbaseUrlFormatter(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	const url = "https://api.coinbase.com/api/v3/brokerage/market/product_book?product_id=&limit=250"
+
+	assert.Equal(
+		t,
+		"https://api.coinbase.com/api/v3/brokerage/market/product_book?product_id=BTC-USDT&limit=250",
+		coinbaseUrlFormatter(url, "BTC/USDT"),
+	)
+}
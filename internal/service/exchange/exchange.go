@@ -1,25 +1,74 @@
 package exchange
 
 import (
+	"bytes"
 	"context"
+	"cvs/internal/config"
 	"cvs/internal/models"  // Importing models for domain-specific data structures
 	"cvs/internal/service" // Importing service layer for user and order book services
 	"cvs/internal/service/logger"
+	"cvs/internal/service/metrics"
 	"cvs/internal/service/orderbook"
+	"errors"
 	"fmt"
 	"io"
-
+	"math/rand"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	cmap "github.com/orcaman/concurrent-map/v2" // Importing concurrent map for thread-safe storage
 	"go.uber.org/zap"
 )
 
+const (
+	requestAttempts = 3                      // Maximum number of attempts of a request to the exchange API
+	requestBackoff  = 500 * time.Millisecond // Delay before the first retry of a failed request to the exchange API
+
+	subscriptionsLoadAttempts = 3           // Maximum number of attempts to load the subscribed pairs from the database
+	subscriptionsLoadBackoff  = time.Second // Delay before the first retry of a failed load of the subscribed pairs
+
+	defaultVolumeSearchWorkers = 16 // Default maximum number of users whose volumes are searched concurrently
+
+	maxThrottledInterval = time.Minute // Upper bound of the time between requests increased after rate limited responses
+
+	rateLimitSlowDownUsage = 0.8 // Used part of the rate limit from which the time between requests is doubled
+	rateLimitSpeedUpUsage  = 0.5 // Used part of the rate limit below which the increased time between requests is halved
+
+	idleInterval    = time.Second     // Sleep of the loops before checking the subscribed pairs again
+	maxIdleInterval = 4 * time.Second // Upper bound of the idle sleep growing while there is nothing to do
+	sleepJitter     = 0.2             // Fraction of a sleep randomly added or removed, so the exchanges don't wake in lockstep
+
+	bodySampleLength = 256 // Maximum number of bytes of an unexpected response body which are logged
+
+	defaultPairsBodyLimit     = 64 << 20 // Default maximum size of the pairs responses in bytes
+	defaultOrderbookBodyLimit = 8 << 20  // Default maximum size of the order book and ticker responses in bytes
+
+	lastPriceInterval = 30 * time.Second // Time between the rounds fetching the last traded prices of the subscribed pairs
+	lastPriceMaxAge   = 5 * time.Minute  // Age after which a cached last traded price is too stale to be added to the found volumes
+)
+
 var (
 	AllExchangesStorage AllExchanges // All exchanges storage
 
+	errNoResponseBody = errors.New("response has no body")              // Error for requests which returned neither a body nor an error
+	errNoLastPrice    = errors.New("response has no last traded price") // Error for ticker responses without a positive price
+
+	// Error for responses which aren't JSON, e.g. the HTML page Binance returns with a 418 to a banned IP
+	errNonJsonResponse = errors.New("response isn't JSON, requests are rate limited or banned")
+
+	errResponseTooLarge = errors.New("response body is too large") // Error for bodies above the limit of their endpoint type, see readBody
+
+	errUnexpectedStatus = func(statusCode int) error {
+		return fmt.Errorf("unexpected response status: %d", statusCode) // Error for non-2xx responses of the exchange
+	}
 	errUnmarshal = func(dataType, exchange string) error {
 		return fmt.Errorf("response unmarshal error: %s %s", exchange, dataType) // Error for unmarshalling failures
 	}
@@ -28,29 +77,68 @@ var (
 		msg,
 		exchangeName,
 		url string,
+		fields ...zap.Field, // Additional context, e.g. the pair
 	) {
-		logger.Error(
+		args := []interface{}{
 			msg,
 			zap.String("exchange", exchangeName),
 			zap.String("url", url),
-		)
+		}
+		for _, field := range fields {
+			args = append(args, field)
+		}
+
+		logger.Error(args...)
+	}
+	warnExchange = func(
+		logger logger.Logger,
+		msg,
+		exchangeName,
+		url string,
+		err error,
+		fields ...zap.Field, // Additional context, e.g. the pair
+	) {
+		args := []interface{}{
+			msg,
+			zap.String("exchange", exchangeName),
+			zap.String("url", url),
+			zap.Error(err),
+		}
+		for _, field := range fields {
+			args = append(args, field)
+		}
+
+		logger.Warn(args...)
 	}
 )
 
 // Exchange defines the interface for managing exchange operations.
 // It includes methods for retrieving pairs, getting order books, and finding volumes.
 type Exchange interface {
-	StartWork()                                                         // Method to start the exchange's work
-	GetAllPairsOfExchange()                                             // Method to retrieve all pairs available on the exchange
-	GetOrderbookPeriodically()                                          // Method to fetch order book data periodically
-	FindVolumeInOrderbookPeriodically()                                 // Method to find volume in the order book periodically
-	FillPairsSubscribedStorage()                                        // Method to fill exchange pairs subscribed to pairs subscribed storage
-	ExchangeName() string                                               // Method to get the name of the exchange
-	AddPairToSubscribedPairs(pair string)                               // Method to add a pair to the list of subscribed pairs
-	ClearSubscribedPairsStorage()                                       // Method to clear the list of subscribed pairs
-	DeletePairFromSubscribedPairs(pair string)                          // Method to delete a pair from the list of subscribed pairs
-	SetEchangePairsToStorage(exchangePairsSlice []models.ExchangePairs) // Method to set the exchange pairs into the allPairsOfExchange storage
-	GetOrderbookDataFromExchange(pair string)                           // Method to get the order book data from the exchange
+	StartWork(ctx context.Context)                                               // Method to start the exchange's work
+	GetAllPairsOfExchange(ctx context.Context) error                             // Method to retrieve all pairs available on the exchange
+	GetOrderbookPeriodically(ctx context.Context)                                // Method to fetch order book data periodically
+	StartOrderbookWebsocket(ctx context.Context)                                 // Method to keep order book data up to date through the websocket
+	FindVolumeInOrderbookPeriodically(ctx context.Context)                       // Method to find volume in the order book periodically
+	FillPairsSubscribedStorage(ctx context.Context)                              // Method to fill exchange pairs subscribed to pairs subscribed storage
+	ExchangeName() string                                                        // Method to get the name of the exchange
+	AddPairToSubscribedPairs(pair string)                                        // Method to add a pair to the list of subscribed pairs
+	ClearSubscribedPairsStorage()                                                // Method to clear the list of subscribed pairs
+	DeletePairFromSubscribedPairs(pair string)                                   // Method to delete a pair from the list of subscribed pairs
+	SetEchangePairsToStorage(exchangePairsSlice []models.ExchangePairs)          // Method to set the exchange pairs into the allPairsOfExchange storage
+	GetOrderbookDataFromExchange(ctx context.Context, pair string)               // Method to get the order book data from the exchange
+	AllPairs() []models.ExchangePairs                                            // Method to get all pairs available on the exchange
+	SearchPairs(substr string) []models.ExchangePairs                            // Method to search the pairs by their base or quote asset
+	HasPair(pair string) bool                                                    // Method to check whether the exchange lists a pair
+	PairsLoaded() bool                                                           // Method to check whether the pairs of the exchange have been loaded
+	AllPairsCount() int                                                          // Method to get the number of pairs listed on the exchange
+	SubscribedPairsCount() int                                                   // Method to get the number of pairs the exchange is subscribed to
+	MinVolumeFloor() float64                                                     // Method to get the volume below which the levels are ignored
+	BlacklistedPairs() []string                                                  // Method to get the pairs whose order books are never polled
+	Status() models.ExchangeStatus                                               // Method to get the connectivity status of the exchange
+	BestPrices(pair string) (models.PriceSnapshot, bool)                         // Method to get the best prices, the spread and the mid price of a pair
+	OrderbookSnapshot(pair string, depth int) (models.OrderbookSnapshot, bool)   // Method to get the price levels of a pair sorted by price
+	PreviewVolumes(pair string, exactValue float64) ([]models.FoundVolume, bool) // Method to search the order book of a pair for volumes without storing them
 }
 
 // exchange is a concrete implementation of the Exchange interface.
@@ -65,102 +153,78 @@ type ExchangeData struct {
 	allPairsOfExchange  cmap.ConcurrentMap[string, models.ExchangePairs] // Concurrent map storing all pairs available on this exchange
 	pairsSubscribed     cmap.ConcurrentMap[string, bool]                 // List of pairs that are subscribed to updates
 	timeBetweenRequests time.Duration                                    // Duration between requests to the exchange API
+	rateLimitWeight     int                                              // Weight of the requests the exchange allows per minute, used if the responses tell only the used weight
+	volumeSearchWorkers int                                              // Maximum number of users whose volumes are searched concurrently
+	quoteFilter         models.QuoteFilter                               // Quote assets whose pairs are stored in allPairsOfExchange
+	orderbookBatchSize  int                                              // Number of pairs whose order books are fetched by one request, zero fetches every pair separately
+	minVolumeFloor      float64                                          // Volume below which the levels are ignored by the exact search, zero ignores none
+	pairBlacklist       map[string]bool                                  // Pairs which are never polled even if subscribed, set once at startup
+	requestHeaders      http.Header                                      // Headers sent with every request to the exchange API, nil sends none
+	pairsBodyLimit      int64                                            // Maximum size of the pairs responses in bytes, zero doesn't limit it
+	orderbookBodyLimit  int64                                            // Maximum size of the order book and ticker responses in bytes, zero doesn't limit it
+	dryRun              bool                                             // Whether the found volumes are only logged, neither stored nor notified about
 	logger              logger.Logger
 
+	websocketConnected   atomic.Bool   // Whether the order book websocket is connected
+	websocketResubscribe chan struct{} // Signals the websocket to resync its subscriptions with the subscribed pairs
+	runningLoops         atomic.Int64  // Number of background loops of the exchange which are running
+	subscriptionsLoaded  atomic.Bool   // Whether the subscribed pairs have been loaded from the database, see FillPairsSubscribedStorage
+
+	statusMu            sync.Mutex // Guards the status of the last fetch from the exchange
+	lastSuccessfulFetch time.Time  // Time of the last successful fetch of pairs or order book data
+	lastError           string     // Error of the last fetch, empty if it succeeded
+
+	circuitBreaker circuitBreaker // Pauses the requests while the exchange keeps failing, fed by the fetch results
+
+	throttleMu    sync.Mutex    // Guards the throttling of the requests after unexpected responses
+	backoffUntil  time.Time     // Time before which no order book is requested
+	rateLimitHits int           // Number of consecutive rate limited responses, each doubles the time between requests
+	usageInterval time.Duration // Time between requests increased while the rate limit usage is high, zero while it is low
+
+	lastPrices cmap.ConcurrentMap[string, lastPrice] // Last traded prices of the subscribed pairs keyed by pair
+
 	pairsUrlForGetRequest     string                                                                      // URL for getting pairs information from the exchange
 	orderbookUrlForGetRequest string                                                                      // URL for getting order book data from the exchange
+	websocketUrl              string                                                                      // URL of the order book websocket, empty if the exchange isn't streamed
 	exchangeName              string                                                                      // Name of the exchange
 	pairsJsonModel            interface{}                                                                 // Model for pairs JSON response
 	orderbookJsonModel        interface{}                                                                 // Model for order book JSON response
 	urlFormatter              func(url, pair string) string                                               // Function to format URLs with trading pairs
 	orderbookJsonParse        func(bodyBytes []byte) ([][]interface{}, [][]interface{}, error)            // Function to parse order book JSON response
 	exchangePairsJsonParse    func(exchangeName string, bodyBytes []byte) ([]models.ExchangePairs, error) // Function to parse exchange pairs from JSON response
+	orderbookBatchUrl         string                                                                      // URL for getting the order books of several pairs by one request
+	batchFetcher              func(ctx context.Context, pairs []string) (map[string]bookData, error)      // Function to fetch the order books of several pairs by one request, nil if the exchange has no batch endpoint
+	tickerUrlForGetRequest    string                                                                      // URL for getting the last traded price of a pair, formatted by urlFormatter
+	tickerJsonParse           func(bodyBytes []byte) (float64, error)                                     // Function to parse the last traded price from JSON response, nil if the exchange has no ticker endpoint
+}
+
+// lastPrice holds the last traded price of a pair and the time it was fetched.
+type lastPrice struct {
+	price     float64   // Price of the last trade
+	fetchedAt time.Time // Time the price was fetched from the exchange
+}
+
+// bookData holds the asks and bids of the order book of a pair fetched by a batch request.
+type bookData struct {
+	asks [][]interface{} // Ask levels in the [price, volume] format
+	bids [][]interface{} // Bid levels in the [price, volume] format
 }
 
 // InitAllExchanges initializes instances of all exchanges and starts their operations.
 //
-// This function creates and initializes instances of various exchanges (Binance and Bybit) by
-// utilizing the provided services. It sets up goroutines to manage the retrieval of trading pairs,
-// order book data, and volume finding processes for each exchange concurrently.
+// This function creates the exchanges of every factory registered by RegisterExchange (Binance, Bybit, Kraken,
+// OKX and Coinbase) by utilizing the provided services. Every exchange starts the retrieval of trading pairs,
+// order book data, and volume finding processes concurrently.
 //
 // Parameters:
-//   - userService: The service for managing user data.
-//   - userPairsService: The service for managing user pairs data.
-//   - httpRequestService: The service for making HTTP requests.
-//   - foundVolumesStorage: The service for managing found volumes data.
+//   - ctx: The context which stops the background work of all exchanges when it is cancelled.
 //   - allExchangesStorage: The storage that holds all exchanges, allowing access to exchange-related operations.
+//   - deps: The services and the settings shared by all exchanges, see Dependencies. The function panics
+//     if deps.EnabledExchanges lists a name which matches no exchange.
 //
-// This function does not return any values. It manages concurrency using goroutines and waits for
-// all initialization tasks to complete before returning.
-func InitAllExchanges(
-	userService service.UserService,
-	userPairsService service.UserPairsService,
-	httpRequestService service.HttpRequest,
-	foundVolumesStorage service.FoundVolumesService,
-	allExchangesStorage AllExchanges,
-	logger logger.Logger,
-) AllExchanges {
-	var wg sync.WaitGroup
-
-	wg.Add(2)
-
-	go func() {
-		defer wg.Done()
-
-		// Create instances of Binance exchanges
-		binances := NewBinance(
-			userService,
-			userPairsService,
-			httpRequestService,
-			foundVolumesStorage,
-			logger,
-		)
-
-		var binanceWg sync.WaitGroup
-
-		for _, binance := range binances {
-			allExchangesStorage.Add(binance)
-
-			binanceWg.Add(1)
-			go func(binance Exchange) {
-				defer binanceWg.Done()
-
-				binance.StartWork()
-			}(binance)
-		}
-
-		binanceWg.Wait() // Wait for all Binance goroutines to finish
-	}()
-
-	go func() {
-		defer wg.Done()
-
-		// Create instances of Bybit exchanges
-		bybits := NewBybit(
-			userService,
-			userPairsService,
-			httpRequestService,
-			foundVolumesStorage,
-			logger,
-		)
-
-		var bybitWg sync.WaitGroup
-
-		for _, bybit := range bybits {
-			allExchangesStorage.Add(bybit)
-
-			bybitWg.Add(1)
-			go func(bybit Exchange) {
-				defer bybitWg.Done()
-
-				bybit.StartWork()
-			}(bybit)
-		}
-
-		bybitWg.Wait() // Wait for all Binance goroutines to finish
-	}()
-
-	wg.Wait() // Wait for the initial goroutine to finish
+// This function waits for all exchanges to start before returning the storage holding them.
+func InitAllExchanges(ctx context.Context, allExchangesStorage AllExchanges, deps Dependencies) AllExchanges {
+	exchangeRegistry.startAll(ctx, allExchangesStorage, deps)
 
 	return allExchangesStorage
 }
@@ -168,12 +232,20 @@ func InitAllExchanges(
 // StartWork starts the exchange's work by filling the pairs subscribed storage, retrieving all
 // pairs available on the exchange, and starting the periodic fetching of order book data and
 // finding volume in the order book. This method calls the following methods in order: FillPairsSubscribedStorage,
-// GetAllPairsOfExchange, FindVolumeInOrderbookPeriodically, and GetOrderbookPeriodically.
-func (e *ExchangeData) StartWork() {
-	e.FillPairsSubscribedStorage()        // Fill pairs subscribed storage
-	e.GetAllPairsOfExchange()             // Retrieve all pairs available on exchange instance
-	e.FindVolumeInOrderbookPeriodically() // Start finding volume in the order book periodically
-	e.GetOrderbookPeriodically()          // Start fetching order book data periodically
+// GetAllPairsOfExchange, FindVolumeInOrderbookPeriodically, StartOrderbookWebsocket, GetOrderbookPeriodically
+// and getLastPricesPeriodically. All the background loops stop when the provided context is cancelled.
+//
+// The subscribed pairs aren't loaded again if they were already loaded before, e.g. by InitAllExchanges
+// backfilling the subscriptions of all exchanges before any of them starts polling.
+func (e *ExchangeData) StartWork(ctx context.Context) {
+	if !e.subscriptionsLoaded.Load() {
+		e.FillPairsSubscribedStorage(ctx) // Fill pairs subscribed storage
+	}
+	e.GetAllPairsOfExchange(ctx)             // Retrieve all pairs available on exchange instance
+	e.FindVolumeInOrderbookPeriodically(ctx) // Start finding volume in the order book periodically
+	e.StartOrderbookWebsocket(ctx)           // Start streaming order book data if the exchange supports it
+	e.GetOrderbookPeriodically(ctx)          // Start fetching order book data periodically
+	e.getLastPricesPeriodically(ctx)         // Start fetching the last traded prices if the exchange has a ticker
 }
 
 // GetAllPairsOfExchange retrieves all trading pairs available on the exchange.
@@ -188,34 +260,63 @@ func (e *ExchangeData) StartWork() {
 // 3. Parses the JSON response into a slice of ExchangePairs.
 // 4. Logs any errors encountered during parsing.
 // 5. Calls SetEchangePairsToStorage to store the retrieved pairs in storage.
+// 6. Removes the stored pairs which are no longer listed, if the response was parsed.
 //
-// This method does not return any values and does not produce errors directly.
-// However, it logs any errors encountered during the HTTP request or JSON parsing.
+// The method is called on the start of the exchange, by the periodic refresh (see StartPairsRefresher)
+// and by the admin endpoint refreshing the pairs on demand, so the listings changed intraday are picked up.
+// The pairs missing from an empty list aren't removed, since an exchange doesn't delist all of its pairs at once.
+//
+// Parameters:
+//   - ctx: The context of the request, cancelling it aborts the request and its retries.
+//
+// Returns:
+//   - error: The error of the request or of the parsing, it is also logged and recorded in the status of the exchange.
+//
+// If the request fails or the response isn't JSON, e.g. a ban page, a warning is logged and the stored pairs are left untouched.
+// A body above pairsBodyLimit isn't read any further and fails the request, see readBody.
 //
 // Example usage:
 //
-//	e.GetAllPairsOfExchange()
-func (e *ExchangeData) GetAllPairsOfExchange() {
-	resp, err := e.httpRequestService.Get(e.pairsUrlForGetRequest) // Make a GET request to retrieve pairs information
-	if err != nil {
-		errExchange(
+//	e.GetAllPairsOfExchange(ctx)
+func (e *ExchangeData) GetAllPairsOfExchange(ctx context.Context) error {
+	resp, err := e.httpRequestService.GetWithRetry(ctx, e.pairsUrlForGetRequest, e.requestHeaders, requestAttempts, requestBackoff) // Make a GET request to retrieve pairs information
+	if err != nil || resp.Body == nil {
+		// The response has no body to read, so skip this update
+		warnExchange(
 			e.logger,
 			"Error while getting all pairs of exchange",
 			e.exchangeName,
 			e.pairsUrlForGetRequest,
+			err,
 		)
+		err = responseError(err)
+		e.recordFetchError(err)
+
+		return err
 	}
 	defer resp.Body.Close() // Ensure response body is closed after reading
 
-	bodyBytes, err := io.ReadAll(resp.Body) // Read response body into bytes
+	bodyBytes, err := readBody(resp.Body, e.pairsBodyLimit) // Read response body into bytes
 	if err != nil {
-		errExchange(
+		warnExchange(
 			e.logger,
 			"Body bytes read error",
 			e.exchangeName,
 			e.pairsUrlForGetRequest,
+			err,
 		)
+		e.recordFetchError(err)
+
+		return err
 	}
+
+	if isNonJsonResponse(resp, bodyBytes) {
+		err = e.warnNonJsonResponse(resp, bodyBytes, e.pairsUrlForGetRequest)
+		e.recordFetchError(err)
+
+		return err // Keep the stored pairs until the exchange responds with them
+	}
+
 	exchangePairsSlice, err := e.exchangePairsJsonParse(e.exchangeName, bodyBytes) // Parse JSON response into exchange pairs slice
 	if err != nil {
 		errExchange(
@@ -224,9 +325,18 @@ func (e *ExchangeData) GetAllPairsOfExchange() {
 			e.exchangeName,
 			e.pairsUrlForGetRequest,
 		)
+		e.recordFetchError(err)
+	} else {
+		e.recordFetchSuccess()
 	}
 
 	e.SetEchangePairsToStorage(exchangePairsSlice) // Store the retrieved pairs in storage
+
+	if err == nil && len(exchangePairsSlice) > 0 {
+		e.removeDelistedPairs(exchangePairsSlice) // Drop the pairs which are no longer listed
+	}
+
+	return err
 }
 
 // FillPairsSubscribedStorage retrieves and stores the subscribed trading pairs for the exchange.
@@ -235,20 +345,46 @@ func (e *ExchangeData) GetAllPairsOfExchange() {
 // It uses the exchange's name to get the relevant pairs and stores them in the
 // pairsSubscribed field of the exchange struct.
 //
-// If an error occurs while retrieving the pairs, it logs the error with context about the operation.
+// A failed load is retried up to subscriptionsLoadAttempts times with a doubling backoff, as the pairs missed
+// at startup wouldn't be polled until their users add them again. If every attempt fails, it logs the error
+// with context about the operation and the exchange keeps reporting the subscriptions as not loaded, see Status.
+// The pairs added by the users meanwhile are kept, as the loaded pairs are only added to the storage.
 //
 // Example usage:
 //
-//	e.FillPairsSubscribedStorage()
-func (e *ExchangeData) FillPairsSubscribedStorage() {
-	pairs, err := e.userPairsService.GetPairsByExchange(context.Background(), e.exchangeName)
+//	e.FillPairsSubscribedStorage(ctx)
+func (e *ExchangeData) FillPairsSubscribedStorage(ctx context.Context) {
+	var (
+		pairs []string
+		err   error
+	)
+
+	backoff := subscriptionsLoadBackoff
+	for attempt := 1; attempt <= subscriptionsLoadAttempts; attempt++ {
+		pairs, err = e.userPairsService.GetPairsByExchange(ctx, e.exchangeName)
+		if err == nil || attempt == subscriptionsLoadAttempts || !sleepContext(ctx, backoff) {
+			break
+		}
+
+		backoff *= 2
+	}
+
 	if err != nil {
-		e.logger.Error(err.Error())
+		e.logger.Error(
+			"Error while getting subscribed pairs",
+			zap.String("exchange", e.exchangeName),
+			zap.Error(err),
+		)
+
+		return
 	}
 
 	for _, pair := range pairs {
-		e.pairsSubscribed.Set(pair, true) // Store each pair in the exchange's pairsSubscribed field
+		e.pairsSubscribed.Set(pair, true)               // Store each pair in the exchange's pairsSubscribed field
+		e.orderbookService.Retain(pair, e.exchangeName) // Keep the order book of the pair while the exchange watches it
 	}
+
+	e.subscriptionsLoaded.Store(true)
 }
 
 // GetOrderbookDataFromExchange retrieves order book data for a specific trading pair from the exchange.
@@ -258,38 +394,99 @@ func (e *ExchangeData) FillPairsSubscribedStorage() {
 // into asks and bids, and updates the order book service with this data.
 //
 // Parameters:
+//   - ctx: The context of the request, cancelling it aborts the request and its retries.
 //   - pair: A string representing the trading pair for which to retrieve order book data.
 //
 // This method does not return any values and does not produce errors directly.
 // However, it logs any errors encountered during the HTTP request or JSON parsing.
-// If an error occurs during parsing, it will be logged with the exchange name and operation context.
+// If an error occurs during parsing or either side of the order book is empty, e.g. the exchange returned
+// a maintenance page or a truncated body, it will be logged with the exchange name, the URL, the pair and
+// a sample of the body, and the previous order book is kept until the next successful poll.
+// If the request fails, a warning is logged and the order book is left untouched.
+// A non-2xx response, e.g. a 429 rate limit or a 418 ban of Binance, isn't parsed: its status and body
+// are logged, the order book is left untouched and the next requests back off, see throttle.
+// A response which isn't JSON, e.g. the HTML page of a ban, is reported as such, see warnNonJsonResponse.
+// A body above orderbookBodyLimit isn't read any further and fails the request, see readBody.
+//
+// Only the failures of the exchange itself count toward the circuit breaker: the request errors, the 5xx,
+// 429 and 418 responses and the responses which aren't JSON. The failures caused by the pair, e.g. an empty
+// side or a 400 of a delisted symbol, are recorded as the last error only, see recordPairFetchError.
 //
 // Example usage:
 //
-//	e.GetOrderbookDataFromExchange("BTC/USD")
-func (e *ExchangeData) GetOrderbookDataFromExchange(pair string) {
+//	e.GetOrderbookDataFromExchange(ctx, "BTC/USD")
+func (e *ExchangeData) GetOrderbookDataFromExchange(ctx context.Context, pair string) {
+	start := time.Now()
+
 	// Make a GET request to retrieve order book data using formatted URL
-	resp, err := e.httpRequestService.Get(e.urlFormatter(e.orderbookUrlForGetRequest, pair))
-	if err != nil {
-		errExchange(
+	resp, err := e.httpRequestService.GetWithRetry(ctx, e.urlFormatter(e.orderbookUrlForGetRequest, pair), e.requestHeaders, requestAttempts, requestBackoff)
+	if err != nil || resp.Body == nil {
+		// The response has no body to read, so keep the previous order book until the next poll
+		warnExchange(
 			e.logger,
 			"Error while getting orderbook",
 			e.exchangeName,
 			e.orderbookUrlForGetRequest,
+			err,
+			zap.String("pair", pair),
 		)
+		e.recordFetchError(responseError(err))
+		metrics.ObserveOrderbookFetch(e.exchangeName, start, false)
+
+		return
 	}
 
 	defer resp.Body.Close() // Ensure response body is closed after reading
 
 	// Read the response body into bytes
-	bodyBytes, err := io.ReadAll(resp.Body)
+	bodyBytes, err := readBody(resp.Body, e.orderbookBodyLimit)
 	if err != nil {
-		errExchange(
+		warnExchange(
 			e.logger,
 			"Body bytes read error",
 			e.exchangeName,
 			e.orderbookUrlForGetRequest,
+			err,
+			zap.String("pair", pair),
+		)
+		if errors.Is(err, errResponseTooLarge) {
+			e.recordPairFetchError(err) // The exchange responded with the book of the pair, which is too deep
+		} else {
+			e.recordFetchError(err)
+		}
+		metrics.ObserveOrderbookFetch(e.exchangeName, start, false)
+
+		return
+	}
+
+	if isNonJsonResponse(resp, bodyBytes) {
+		// The exchange answered with a page instead of the API, e.g. the HTML of a ban, so it isn't parsed
+		e.recordFetchError(e.warnNonJsonResponse(resp, bodyBytes, e.orderbookUrlForGetRequest, zap.String("pair", pair)))
+		metrics.ObserveOrderbookFetch(e.exchangeName, start, false)
+
+		return // Keep the previous order book until the exchange responds successfully
+	}
+
+	if !isSuccessStatus(resp.StatusCode) {
+		// The body is an error of the exchange, e.g. a rate limit, so it isn't parsed as an empty order book
+		warnExchange(
+			e.logger,
+			"Unexpected orderbook response status",
+			e.exchangeName,
+			e.orderbookUrlForGetRequest,
+			errUnexpectedStatus(resp.StatusCode),
+			zap.String("pair", pair),
+			zap.String("body", bodySample(bodyBytes)),
 		)
+		if isExchangeFailureStatus(resp.StatusCode) {
+			e.recordFetchError(errUnexpectedStatus(resp.StatusCode))
+		} else {
+			e.recordPairFetchError(errUnexpectedStatus(resp.StatusCode)) // E.g. a 400 of a delisted symbol
+		}
+		metrics.ObserveOrderbookFetch(e.exchangeName, start, false)
+		e.throttle(resp)
+
+		return // Keep the previous order book until the exchange responds successfully
 	}
 	// Parse JSON response into asks and bids slices
 	asks, bids, err := e.orderbookJsonParse(bodyBytes)
@@ -300,107 +497,610 @@ func (e *ExchangeData) GetOrderbookDataFromExchange(pair string) {
 			"Empty asks or bids or error while parsing JSON",
 			e.exchangeName,
 			e.orderbookUrlForGetRequest,
+			zap.String("pair", pair),
+			zap.Error(err),
+			zap.String("body", bodySample(bodyBytes)),
 		)
+		e.recordPairFetchError(errUnmarshal("orderbook", e.exchangeName)) // The book of the pair is empty, not the exchange down
+		metrics.ObserveOrderbookFetch(e.exchangeName, start, false)
+
+		return // Keep the previous order book instead of wiping it with the empty one
 	}
 
+	e.recordFetchSuccess()
+	e.resetThrottle()
+	metrics.ObserveOrderbookFetch(e.exchangeName, start, true)
+
 	// Update or insert order book data into the order book service
 	e.orderbookService.Upsert(pair, asks, bids) // Update or insert order book data into the order book service
 }
 
+// getOrderbookBatchFromExchange retrieves the order books of several pairs by one request using
+// the batch fetcher of the exchange and stores them in the order book service.
+//
+// Pairs missing from the response or having an empty side keep their previous order book, like
+// in GetOrderbookDataFromExchange. If the request fails, the error is logged and recorded as the
+// last error of the exchange, and no order book is changed.
+//
+// Parameters:
+//   - ctx: The context of the request, cancelling it aborts the request and its retries.
+//   - pairs: The trading pairs whose order books are fetched, e.g. "BTC/USDT".
+func (e *ExchangeData) getOrderbookBatchFromExchange(ctx context.Context, pairs []string) {
+	start := time.Now()
+
+	books, err := e.batchFetcher(ctx, pairs)
+	if errors.Is(err, errNonJsonResponse) { // Already reported by the batch fetcher, see warnNonJsonResponse
+		e.recordFetchError(err)
+		metrics.ObserveOrderbookFetch(e.exchangeName, start, false)
+
+		return
+	}
+	if err != nil {
+		warnExchange(
+			e.logger,
+			"Error while getting orderbook batch",
+			e.exchangeName,
+			e.orderbookBatchUrl,
+			err,
+			zap.Strings("pairs", pairs),
+		)
+		e.recordFetchError(err)
+		metrics.ObserveOrderbookFetch(e.exchangeName, start, false)
+
+		return
+	}
+
+	e.recordFetchSuccess()
+	e.resetThrottle()
+	metrics.ObserveOrderbookFetch(e.exchangeName, start, true)
+
+	for _, pair := range pairs {
+		book, ok := books[pair]
+		if !ok || len(book.asks) == 0 || len(book.bids) == 0 {
+			continue // Keep the previous order book instead of wiping it with the empty one
+		}
+
+		e.orderbookService.Upsert(pair, book.asks, book.bids) // Update or insert order book data into the order book service
+	}
+}
+
+// getLastPricesPeriodically fetches the last traded prices of the subscribed pairs every lastPriceInterval,
+// so the found volumes can be put in the context of the last trade, see withLastPrice.
+//
+// The prices are best-effort: a failed request is logged and the pair keeps its previous price until it gets stale,
+// so the volume detection never waits for the ticker. The loop isn't started if the exchange has no ticker endpoint.
+// No price is fetched while the circuit breaker isn't closed, the order book requests test the recovery of the exchange.
+// The blacklisted pairs are skipped, as their tickers fail like their order books.
+// Like GetOrderbookPeriodically, it sleeps between requests to avoid rate limiting and runs until the context is cancelled.
+func (e *ExchangeData) getLastPricesPeriodically(ctx context.Context) {
+	if e.tickerJsonParse == nil || e.tickerUrlForGetRequest == "" {
+		return // The exchange has no ticker endpoint
+	}
+
+	e.runningLoops.Add(1)
+
+	go func() {
+		defer e.runningLoops.Add(-1)
+
+		for {
+			for _, pair := range e.pollablePairs() { // Iterate over each subscribed pair which isn't blacklisted
+				if !e.circuitBreaker.isClosed() {
+					break // The exchange keeps failing, so the prices are fetched once it recovers
+				}
+
+				e.getLastPriceFromExchange(ctx, pair)
+
+				if !sleepContext(ctx, e.nextRequestDelay()) { // Sleep briefly between requests to avoid rate limiting
+					return
+				}
+			}
+
+			if !sleepContext(ctx, withJitter(lastPriceInterval)) { // Sleep before the next round
+				return
+			}
+		}
+	}()
+}
+
+// getLastPriceFromExchange fetches the last traded price of the pair from the ticker endpoint of the exchange
+// and stores it in the last prices of the exchange.
+//
+// The request isn't retried, as the next round fetches the price again, and it is cancelled with the context,
+// so a stopped exchange doesn't wait for a slow ticker endpoint. Errors are logged as warnings and don't
+// change the status of the exchange, which reflects the order book fetches only. A non-2xx response backs off
+// the next requests like an order book response, as the exchange applies the same rate limits, see throttle.
+//
+// Parameters:
+//   - ctx: The context which cancels the request.
+//   - pair: The trading pair whose last traded price is fetched, e.g. "BTC/USDT".
+func (e *ExchangeData) getLastPriceFromExchange(ctx context.Context, pair string) {
+	tickerUrl := e.urlFormatter(e.tickerUrlForGetRequest, pair)
+
+	resp, err := e.httpRequestService.GetWithRetry(ctx, tickerUrl, e.requestHeaders, 1, 0) // A single attempt
+	if err != nil || resp.Body == nil {
+		warnExchange(e.logger, "Error while getting last price", e.exchangeName, e.tickerUrlForGetRequest, responseError(err), zap.String("pair", pair))
+
+		return
+	}
+
+	defer resp.Body.Close() // Ensure response body is closed after reading
+
+	bodyBytes, err := readBody(resp.Body, e.orderbookBodyLimit)
+	if err != nil {
+		warnExchange(e.logger, "Body bytes read error", e.exchangeName, e.tickerUrlForGetRequest, err, zap.String("pair", pair))
+
+		return
+	}
+
+	if isNonJsonResponse(resp, bodyBytes) {
+		e.warnNonJsonResponse(resp, bodyBytes, e.tickerUrlForGetRequest, zap.String("pair", pair))
+
+		return
+	}
+
+	if !isSuccessStatus(resp.StatusCode) {
+		warnExchange(
+			e.logger,
+			"Unexpected last price response status",
+			e.exchangeName,
+			e.tickerUrlForGetRequest,
+			errUnexpectedStatus(resp.StatusCode),
+			zap.String("pair", pair),
+			zap.String("body", bodySample(bodyBytes)),
+		)
+		e.throttle(resp)
+
+		return
+	}
+
+	price, err := e.tickerJsonParse(bodyBytes)
+	if err != nil {
+		warnExchange(
+			e.logger,
+			"Error while parsing last price",
+			e.exchangeName,
+			e.tickerUrlForGetRequest,
+			err,
+			zap.String("pair", pair),
+			zap.String("body", bodySample(bodyBytes)),
+		)
+
+		return
+	}
+
+	e.setLastPrice(pair, price)
+}
+
+// setLastPrice stores the last traded price of the pair fetched now.
+func (e *ExchangeData) setLastPrice(pair string, price float64) {
+	e.lastPrices.Set(pair, lastPrice{price: price, fetchedAt: time.Now()})
+}
+
+// withLastPrice returns the found volume with the last traded price of its pair and the distance
+// of the volume from it in percent, positive if the volume is above the last price and negative if it is below.
+//
+// The found volume is returned unchanged if nothing was found on its side, the exchange has no ticker endpoint
+// or the last price of the pair hasn't been fetched within lastPriceMaxAge.
+func (e *ExchangeData) withLastPrice(volume models.FoundVolume) models.FoundVolume {
+	if volume.Price == 0 || e.tickerJsonParse == nil {
+		return volume
+	}
+
+	last, ok := e.lastPrices.Get(volume.Pair)
+	if !ok || last.price <= 0 || time.Since(last.fetchedAt) > lastPriceMaxAge {
+		return volume // A stale price would put the volume in a misleading context
+	}
+
+	volume.LastPrice = last.price
+	volume.DistanceFromLast = (volume.Price - last.price) / last.price * 100
+
+	return volume
+}
+
+// parseLastPrice parses the last traded price returned by a ticker endpoint, which must be positive.
+func parseLastPrice(price string) (float64, error) {
+	parsed, err := strconv.ParseFloat(price, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if parsed <= 0 {
+		return 0, errNoLastPrice
+	}
+
+	return parsed, nil
+}
+
+// isNonJsonResponse reports whether the response body isn't JSON, so it must not be parsed as the data of the exchange.
+//
+// Such a body starts with "<", e.g. the HTML page Binance returns with a 418 to a banned IP or a page of a proxy,
+// or has a content type other than JSON and doesn't look like JSON either, e.g. a plain text notice.
+// The type alone isn't trusted, as some endpoints send JSON as text/plain.
+func isNonJsonResponse(resp http.Response, body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return false // Nothing to tell, the empty body is reported by the parser
+	}
+
+	if trimmed[0] == '<' {
+		return true // An HTML or XML page
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "" || strings.Contains(mediaType, "json") {
+		return false
+	}
+
+	return trimmed[0] != '{' && trimmed[0] != '['
+}
+
+// warnNonJsonResponse reports a response which isn't JSON as a sign the requests are rate limited or banned,
+// rather than as a parse failure masking the cause, and backs off the next requests, see throttle.
+// The warning tells the status, the content type and a sample of the body of the response
+// along with the time until the next request is sent.
+//
+// Parameters:
+//   - resp: The response which isn't JSON.
+//   - body: The body of the response.
+//   - requestUrl: The URL of the endpoint, which is logged.
+//   - fields: Additional context, e.g. the pair.
+//
+// Returns:
+//   - The error wrapping errNonJsonResponse with the status of the response.
+func (e *ExchangeData) warnNonJsonResponse(resp http.Response, body []byte, requestUrl string, fields ...zap.Field) error {
+	e.throttle(resp)
+
+	err := fmt.Errorf("%w, status %d", errNonJsonResponse, resp.StatusCode)
+
+	warnExchange(
+		e.logger,
+		"Non-JSON response, requests are probably rate limited or banned",
+		e.exchangeName,
+		requestUrl,
+		err,
+		append([]zap.Field{
+			zap.Int("status", resp.StatusCode),
+			zap.String("content_type", resp.Header.Get("Content-Type")),
+			zap.Duration("retry_in", e.nextRequestDelay()),
+			zap.String("body", bodySample(body)),
+		}, fields...)...,
+	)
+
+	return err
+}
+
+// readBody reads the response body, but not more than the limit, so a misbehaving exchange
+// returning a huge body can't exhaust the memory. A non-positive limit reads the whole body.
+//
+// Returns:
+//   - The body of the response.
+//   - The error wrapping errResponseTooLarge if the body is above the limit, or the error of the read.
+func readBody(body io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(body)
+	}
+
+	bodyBytes, err := io.ReadAll(io.LimitReader(body, limit+1)) // One more byte tells whether the body is above the limit
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(bodyBytes)) > limit {
+		return nil, fmt.Errorf("%w, above %d bytes", errResponseTooLarge, limit)
+	}
+
+	return bodyBytes, nil
+}
+
+// bodySample returns the beginning of the response body, so an unexpected response can be recognized in the logs
+// without logging whole pages.
+func bodySample(body []byte) string {
+	if len(body) <= bodySampleLength {
+		return string(body)
+	}
+
+	return string(body[:bodySampleLength]) + "..."
+}
+
 // GetOrderbookPeriodically fetches order book data from the exchange for subscribed pairs at regular intervals.
 //
 // This method runs as a goroutine and continuously checks for subscribed pairs.
 // If there are subscribed pairs, it iterates over each pair and retrieves the order book data
-// from the exchange using the GetOrderbookDataFromExchange method.
+// from the exchange using the GetOrderbookDataFromExchange method. The blacklisted pairs are skipped
+// even if they are subscribed, see setPairBlacklist. If the exchange supports batches
+// and the batch size is set, the pairs are fetched in batches instead.
 // It sleeps for timeBetweenRequests variable  value milliseconds between requests to avoid hitting rate limits imposed by the exchange API.
-// If there are no subscribed pairs, it waits for 1 second before checking again.
+// If there are no subscribed pairs, it waits before checking again, starting from 1 second and backing off
+// up to maxIdleInterval while nothing is subscribed. All sleeps of the loop are jittered, see idleSleep.
+// While the order book websocket of the exchange is connected, the polling is paused and
+// serves only as a fallback for the time the socket is down.
 //
-// This method will run indefinitely until the application is terminated or the goroutine is stopped.
+// This method will run until the provided context is cancelled.
 //
 // Possible Errors:
 //   - Errors may occur during the retrieval of order book data, but these errors are logged
 //     and do not interrupt the execution of this method.
-func (e *ExchangeData) GetOrderbookPeriodically() {
+func (e *ExchangeData) GetOrderbookPeriodically(ctx context.Context) {
+	e.runningLoops.Add(1)
+
 	go func() {
+		defer e.runningLoops.Add(-1)
+
+		idleRounds := 0 // Number of consecutive rounds without polling
+
 		for {
-			pairsSubscribed := e.pairsSubscribed.Keys() // Get all subscribed pairs keys
+			pairsSubscribed := e.pollablePairs() // Get the subscribed pairs which aren't blacklisted
 
-			if len(pairsSubscribed) != 0 { // Check if there are any subscribed pairs
-				for _, pair := range pairsSubscribed { // Iterate over each subscribed pair
-					e.GetOrderbookDataFromExchange(pair) // Fetch order book data from the exchange
+			if len(pairsSubscribed) != 0 && !e.websocketConnected.Load() { // Poll only while there is no live websocket
+				idleRounds = 0
 
-					time.Sleep(e.timeBetweenRequests) // Sleep briefly between requests to avoid rate limiting
+				if !e.fetchOrderbooks(ctx, pairsSubscribed) {
+					return
 				}
+			} else {
+				idleRounds++
 			}
 
-			time.Sleep(time.Second) // Sleep before checking again
+			if !sleepContext(ctx, idleSleep(idleRounds)) { // Sleep before checking again
+				return
+			}
 		}
 	}()
 }
 
+// pollablePairs returns the subscribed pairs which are polled, i.e. the ones which aren't blacklisted
+// and, once the pairs of the exchange are loaded, are still listed on the exchange.
+// The delisted pairs stay subscribed, so they are polled again if the exchange relists them.
+func (e *ExchangeData) pollablePairs() []string {
+	pairs := e.pairsSubscribed.Keys()
+	pairsLoaded := e.PairsLoaded()
+	if len(e.pairBlacklist) == 0 && !pairsLoaded {
+		return pairs
+	}
+
+	return slices.DeleteFunc(pairs, func(pair string) bool {
+		return e.pairBlacklist[pair] || (pairsLoaded && !e.allPairsOfExchange.Has(pair))
+	})
+}
+
+// fetchOrderbooks fetches the order books of the given pairs once, sleeping between requests to avoid rate limiting.
+// The sleep is longer while the exchange is backing off after unexpected responses, see throttle.
+// While the circuit breaker is open the round is stopped and the loop sleeps until the recovery can be tested.
+//
+// If the exchange has a batch fetcher and the batch size is set, the pairs are fetched in batches of
+// orderbookBatchSize pairs. Otherwise the order book of every pair is fetched by a separate request.
+//
+// Returns:
+//   - bool: False if the context was cancelled while sleeping, true otherwise.
+func (e *ExchangeData) fetchOrderbooks(ctx context.Context, pairs []string) bool {
+	if e.batchFetcher == nil || e.orderbookBatchSize <= 0 {
+		for _, pair := range pairs { // Iterate over each subscribed pair
+			if !e.circuitBreaker.allow() {
+				return e.pauseRequests(ctx)
+			}
+
+			e.GetOrderbookDataFromExchange(ctx, pair) // Fetch order book data from the exchange
+
+			if !sleepContext(ctx, e.nextRequestDelay()) { // Sleep briefly between requests to avoid rate limiting
+				return false
+			}
+		}
+
+		return true
+	}
+
+	for start := 0; start < len(pairs); start += e.orderbookBatchSize {
+		end := min(start+e.orderbookBatchSize, len(pairs))
+
+		if !e.circuitBreaker.allow() {
+			return e.pauseRequests(ctx)
+		}
+
+		e.getOrderbookBatchFromExchange(ctx, pairs[start:end]) // Fetch the order books of the whole batch by one request
+
+		if !sleepContext(ctx, e.nextRequestDelay()) { // Sleep briefly between requests to avoid rate limiting
+			return false
+		}
+	}
+
+	return true
+}
+
+// pauseRequests sleeps until the open circuit breaker allows the request testing the recovery of the exchange.
+//
+// Returns:
+//   - bool: False if the context was cancelled while sleeping, true otherwise.
+func (e *ExchangeData) pauseRequests(ctx context.Context) bool {
+	retryIn := e.circuitBreaker.retryIn()
+	if retryIn > 0 {
+		e.statusMu.Lock()
+		lastError := e.lastError
+		e.statusMu.Unlock()
+
+		e.logger.Warn(
+			"Circuit breaker is open, pausing requests",
+			zap.String("exchange", e.exchangeName),
+			zap.Duration("retry_in", retryIn),
+			zap.String("last_error", lastError),
+		)
+	}
+
+	return sleepContext(ctx, retryIn)
+}
+
 // FindVolumeInOrderbookPeriodically searches for trading volumes in the order book
 // for subscribed pairs at regular intervals.
 //
 // This method runs as a goroutine and continuously checks for subscribed pairs.
-// If there are no subscribed pairs, it waits for one second before checking again.
+// If there are no subscribed pairs, it waits before checking again, starting from one second and backing off
+// up to maxIdleInterval while nothing is subscribed. All sleeps of the loop are jittered, see idleSleep.
 // For each subscribed pair, it retrieves the user IDs from memory and processes
 // each user's settings to search for volumes in the order book using the specified
 // exact values. The found volumes are then upserted into the found volumes service.
 //
 // The method utilizes goroutines to handle concurrent processing of user settings
 // and volume searches, ensuring that multiple users can be processed simultaneously.
+// At most volumeSearchWorkers users are processed at the same time, and all users are
+// processed for a pair before the next pair is taken.
 //
-// Note: This method will run indefinitely until the application is terminated or
-// the goroutine is stopped.
+// Note: This method will run until the provided context is cancelled.
 //
 // Possible Errors:
 //   - Errors may occur during the retrieval of user pairs or while searching for volumes,
 //     but these errors are logged and do not interrupt the execution of this method.
-func (e *ExchangeData) FindVolumeInOrderbookPeriodically() {
+func (e *ExchangeData) FindVolumeInOrderbookPeriodically(ctx context.Context) {
+	e.runningLoops.Add(1)
+
 	go func() {
+		defer e.runningLoops.Add(-1)
+
+		idleRounds := 0 // Number of consecutive rounds without subscribed pairs
+
 		for {
 			pairsSubscribed := e.pairsSubscribed.Keys() // Get all subscribed pairs keys
+			metrics.SetSubscribedPairs(e.exchangeName, len(pairsSubscribed))
+
+			if len(pairsSubscribed) == 0 {
+				idleRounds++
+			} else { // Search the volumes of the subscribed pairs
+				idleRounds = 0
 
-			if len(pairsSubscribed) != 0 { // Check if there are any subscribed pairs
 				for _, pair := range pairsSubscribed { // Iterate over each subscribed pair
-					var wg sync.WaitGroup // WaitGroup to manage goroutines
+					if ctx.Err() != nil { // Stop before processing the next pair if the work is cancelled
+						return
+					}
 
+					var wg sync.WaitGroup                                 // WaitGroup to manage goroutines
+					workers := make(chan struct{}, e.volumeSearchWorkers) // Semaphore limiting the users searched concurrently
+
+				users:
 					for _, userID := range e.userService.GetUsersIdFromMemory().Keys() {
+						select {
+						case workers <- struct{}{}: // Wait for a free worker
+						case <-ctx.Done():
+							break users
+						}
+
 						wg.Add(1) // Increment WaitGroup counter
 
 						go func(userID string) { // Start a new goroutine for each user ID
-							defer wg.Done() // Decrement counter when done
+							defer wg.Done()              // Decrement counter when done
+							defer func() { <-workers }() // Release the worker
 
-							userIdInt, _ := strconv.Atoi(userID) // Convert user ID to int
-
-							userSettings, _ := e.userPairsService.GetAllUserPairs(context.Background(), userIdInt)
-
-							for _, pairSettings := range userSettings { // Iterate over each user's pair settings
-								foundVolumes := e.orderbookService.SearchVolume(pair, e.exchangeName, pairSettings.ExactValue) // Search for volumes
-
-								for _, volume := range foundVolumes { // Iterate over found volumes
-									e.foundVolumesService.UpsertFoundVolume(pairSettings, volume) // Upsert volume into service
-								}
-							}
+							e.findUserVolumes(ctx, pair, userID)
 						}(userID)
-
-						time.Sleep(100 * time.Millisecond) // Sleep briefly between processing users
 					}
 
 					wg.Wait() // Wait for all goroutines to finish before proceeding to the next pair
 				}
 			}
 
-			time.Sleep(time.Second)
+			if !sleepContext(ctx, idleSleep(idleRounds)) {
+				return
+			}
 		}
 	}()
 }
 
+// findUserVolumes searches the order book of the pair for the volumes of the user's settings of the pair on the exchange
+// and upserts them into the found volumes service. Only the volumes of the sides watched by the pair settings are recorded.
+// The settings of the other pairs of the user are skipped, as the upsert would drop the sides they don't watch.
+// In the dry run mode the found volumes are only logged, so they are neither stored nor notified about.
+//
+// Parameters:
+//   - ctx: The context of the search.
+//   - pair: The trading pair whose order book is searched.
+//   - userID: The ID of the user as it is stored in memory.
+func (e *ExchangeData) findUserVolumes(ctx context.Context, pair, userID string) {
+	userIdInt, _ := strconv.Atoi(userID) // Convert user ID to int
+
+	userSettings, _ := e.userPairsService.GetAllUserPairs(ctx, userIdInt)
+
+	for _, pairSettings := range userSettings { // Iterate over each user's pair settings
+		if pairSettings.Exchange != e.exchangeName || pairSettings.Pair != pair {
+			continue // The settings of another pair, their thresholds and sides don't apply to this order book
+		}
+
+		foundVolumes := e.searchVolumes(pair, pairSettings) // Search for volumes in the mode chosen by the user
+
+		found := 0
+		for _, volume := range foundVolumes { // Iterate over found volumes
+			volume = e.withLastPrice(volume) // Add the context of the last trade if its price is known
+
+			watched := volume.Price != 0 && pairSettings.WatchesSide(volume.Side) // A zero price means nothing was found on the side
+			if watched {
+				found++
+			}
+
+			if e.dryRun {
+				if watched {
+					e.logger.Info(
+						"Dry run, found volume isn't stored nor notified",
+						zap.String("exchange", e.exchangeName),
+						zap.String("pair", volume.Pair),
+						zap.String("side", volume.Side),
+						zap.Float64("price", volume.Price),
+						zap.Float64("volume", volume.Volume),
+						zap.Int("user_id", userIdInt),
+					)
+				}
+
+				continue
+			}
+
+			// Upsert volume into service, it drops the volumes of the sides the user doesn't watch
+			if _, err := e.foundVolumesService.UpsertFoundVolume(ctx, pairSettings, volume); err != nil {
+				e.logger.Error(err)
+			}
+		}
+		metrics.AddVolumesFound(userIdInt, found)
+	}
+}
+
+// searchVolumes searches the order book of the pair for volumes in the search mode of the user pair settings.
+//
+// In the exact mode, the levels whose volume is at least the exact value are found,
+// or only the levels within the tolerance of the exact value if the tolerance is set.
+// In the relative mode, the levels standing out from the surrounding levels are found,
+// and the exact value is the minimum volume such a level must have to be reported.
+// In both modes the levels below the minimum volume floor of the exchange are never found.
+// In the cumulative mode, the bands of adjacent levels within the price range whose total volume
+// is at least the exact value are found, so a wall spread across several levels is found as well.
+//
+// Parameters:
+//   - pair: The trading pair whose order book is searched.
+//   - pairSettings: The user pair settings defining the search mode.
+//
+// Returns:
+//   - The found volumes of both sides, with a zero price for the sides where nothing was found.
+func (e *ExchangeData) searchVolumes(pair string, pairSettings models.UserPairs) []models.FoundVolume {
+	if pairSettings.IsCumulative() {
+		return e.orderbookService.SearchCumulativeVolume(pair, e.exchangeName, pairSettings.ExactValue, pairSettings.PriceRange)
+	}
+
+	if !pairSettings.IsRelative() {
+		return e.orderbookService.SearchVolume(pair, e.exchangeName, pairSettings.ExactValue, pairSettings.Tolerance, e.minVolumeFloor)
+	}
+
+	foundVolumes := e.orderbookService.SearchVolumeRelative(pair, e.exchangeName, pairSettings.Multiplier, pairSettings.Window)
+	for i, volume := range foundVolumes {
+		if volume.Price != 0 && volume.Volume < max(pairSettings.ExactValue, e.minVolumeFloor) { // The level stands out, but it is too small
+			foundVolumes[i] = models.FoundVolume{
+				Pair:     volume.Pair,
+				Exchange: volume.Exchange,
+				Side:     volume.Side,
+			}
+		}
+	}
+
+	return foundVolumes
+}
+
 // SetEchangePairsToStorage stores all pairs of an exchange into its storage.
 //
 // This method takes a slice of ExchangePairs and iterates over each pair.
-// For each pair, it adds the pair data to the exchange's storage using a
-// concurrent map for thread-safe operations.
+// For each pair allowed by the quote filter of the exchange, it adds the pair data
+// to the exchange's storage using a concurrent map for thread-safe operations.
 //
 // Parameters:
 //   - exchangePairsSlice: A slice of models.ExchangePairs containing the pairs
@@ -411,10 +1111,490 @@ func (e *ExchangeData) FindVolumeInOrderbookPeriodically() {
 // is initialized properly. If the slice is empty, no operations are performed.
 func (e *ExchangeData) SetEchangePairsToStorage(exchangePairsSlice []models.ExchangePairs) {
 	for _, pairData := range exchangePairsSlice { // Iterate over each pair data
+		if !e.quoteFilter.Allows(quoteAsset(pairData.Pair)) {
+			continue // Skip the pairs of the quote assets the exchange isn't configured to scan
+		}
+
 		e.allPairsOfExchange.Set(pairData.Pair, pairData) // Store each pair in the concurrent map
 	}
 }
 
+// removeDelistedPairs removes the stored pairs which are missing from the pairs listed on the exchange.
+// The subscribed pairs among them are no longer polled, see pollablePairs.
+//
+// The pairs skipped by the quote filter are missing from the storage as well, so they are removed too.
+func (e *ExchangeData) removeDelistedPairs(listedPairs []models.ExchangePairs) {
+	listed := make(map[string]struct{}, len(listedPairs))
+	for _, pairData := range listedPairs {
+		listed[pairData.Pair] = struct{}{}
+	}
+
+	for _, pair := range e.allPairsOfExchange.Keys() {
+		if _, ok := listed[pair]; !ok {
+			e.allPairsOfExchange.Remove(pair) // The pair was delisted since the last refresh
+		}
+	}
+}
+
+// quoteAsset returns the quote asset of the pair in the "BASE/QUOTE" format, empty if the pair has no quote asset.
+func quoteAsset(pair string) string {
+	_, quote, _ := strings.Cut(pair, "/")
+
+	return quote
+}
+
+// AllPairs returns all pairs available on the exchange sorted by the pair name.
+//
+// The pairs are loaded by GetAllPairsOfExchange, so the result is empty until the exchange has started its work.
+func (e *ExchangeData) AllPairs() []models.ExchangePairs {
+	pairs := make([]models.ExchangePairs, 0, e.allPairsOfExchange.Count())
+	for _, pairData := range e.allPairsOfExchange.Items() {
+		pairs = append(pairs, pairData)
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Pair < pairs[j].Pair
+	})
+
+	return pairs
+}
+
+// SearchPairs returns the pairs of the exchange whose base or quote asset contains the substring, sorted by the pair name.
+//
+// The match is case-insensitive, so "btc" finds both "BTC/USDT" and "ETH/BTC". The substring isn't matched
+// across the slash, and an empty substring matches every pair.
+func (e *ExchangeData) SearchPairs(substr string) []models.ExchangePairs {
+	substr = strings.ToUpper(strings.TrimSpace(substr))
+
+	pairs := []models.ExchangePairs{}
+	for _, pairData := range e.AllPairs() {
+		base, quote, _ := strings.Cut(strings.ToUpper(pairData.Pair), "/")
+		if strings.Contains(base, substr) || strings.Contains(quote, substr) {
+			pairs = append(pairs, pairData)
+		}
+	}
+
+	return pairs
+}
+
+// HasPair reports whether the pair is listed on the exchange.
+//
+// The pairs are loaded by GetAllPairsOfExchange, so false is returned for every pair until they are loaded;
+// PairsLoaded tells such a case apart from a pair the exchange doesn't list.
+// The pair may be in any format accepted by models.NormalizePair, e.g. BTCUSDT or BTC-USDT.
+func (e *ExchangeData) HasPair(pair string) bool {
+	if e.allPairsOfExchange.Has(pair) {
+		return true
+	}
+
+	normalized, err := models.NormalizePair(pair)
+
+	return err == nil && e.allPairsOfExchange.Has(normalized)
+}
+
+// PairsLoaded reports whether the pairs of the exchange have been loaded by GetAllPairsOfExchange.
+func (e *ExchangeData) PairsLoaded() bool {
+	return e.allPairsOfExchange.Count() > 0
+}
+
+// AllPairsCount returns the number of pairs listed on the exchange, zero until the pairs are loaded.
+func (e *ExchangeData) AllPairsCount() int {
+	return e.allPairsOfExchange.Count()
+}
+
+// SubscribedPairsCount returns the number of pairs the exchange is subscribed to, that is the pairs
+// of the exchange added by at least one user.
+func (e *ExchangeData) SubscribedPairsCount() int {
+	return e.pairsSubscribed.Count()
+}
+
+// MinVolumeFloor returns the volume below which the levels of the order books are ignored
+// regardless of the user settings, zero if no level is ignored.
+func (e *ExchangeData) MinVolumeFloor() float64 {
+	return e.minVolumeFloor
+}
+
+// BlacklistedPairs returns the pairs whose order books are never polled, e.g. the delisted symbols
+// which are still listed but keep failing, sorted alphabetically. It returns nil if no pair is blacklisted.
+func (e *ExchangeData) BlacklistedPairs() []string {
+	if len(e.pairBlacklist) == 0 {
+		return nil
+	}
+
+	pairs := make([]string, 0, len(e.pairBlacklist))
+	for pair := range e.pairBlacklist {
+		pairs = append(pairs, pair)
+	}
+
+	sort.Strings(pairs)
+
+	return pairs
+}
+
+// BestPrices returns the best bid and ask prices, the spread and the mid price of the pair.
+//
+// The order book is only kept for the subscribed pairs, so false is returned for a pair
+// nobody is subscribed to, as well as for a pair whose order book hasn't been fetched yet or is empty.
+func (e *ExchangeData) BestPrices(pair string) (models.PriceSnapshot, bool) {
+	snapshot, ok := e.orderbookService.BestPrices(pair)
+	snapshot.Exchange = e.exchangeName
+
+	return snapshot, ok
+}
+
+// OrderbookSnapshot returns the price levels of the order book of the pair on the exchange.
+// At most depth levels of each side are returned, all of them if it isn't positive.
+// The order book is kept only for the subscribed pairs, so false is returned for the other ones.
+func (e *ExchangeData) OrderbookSnapshot(pair string, depth int) (models.OrderbookSnapshot, bool) {
+	snapshot, ok := e.orderbookService.Snapshot(pair, depth)
+	snapshot.Exchange = e.exchangeName
+
+	return snapshot, ok
+}
+
+// PreviewVolumes searches the current order book of the pair for the volumes an exact value would find,
+// like the exact search of a user pair without a tolerance, but the found volumes aren't stored or published.
+//
+// Only the sides where a volume was found are returned, the asks before the bids. The order book is kept only
+// for the subscribed pairs, so false is returned for the other ones and for the pairs whose book hasn't been fetched yet.
+func (e *ExchangeData) PreviewVolumes(pair string, exactValue float64) ([]models.FoundVolume, bool) {
+	foundVolumes := e.orderbookService.SearchVolume(pair, e.exchangeName, exactValue, 0, e.minVolumeFloor)
+	if len(foundVolumes) == 0 {
+		return nil, false // There is no order book of the pair
+	}
+
+	previewed := []models.FoundVolume{}
+	for _, volume := range foundVolumes {
+		if volume.Price != 0 { // A zero price means nothing was found on the side
+			previewed = append(previewed, e.withLastPrice(volume))
+		}
+	}
+
+	sort.Slice(previewed, func(i, j int) bool {
+		return previewed[i].Side < previewed[j].Side // The sides are searched concurrently, so their order varies
+	})
+
+	return previewed, true
+}
+
+// Status returns the connectivity status of the exchange.
+//
+// The status is updated by every fetch of pairs or order book data, including the updates received
+// through the order book websocket.
+func (e *ExchangeData) Status() models.ExchangeStatus {
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+
+	return models.ExchangeStatus{
+		Exchange:            e.exchangeName,
+		LastSuccessfulFetch: e.lastSuccessfulFetch,
+		LastError:           e.lastError,
+		SubscribedPairs:     e.SubscribedPairsCount(),
+		SubscriptionsLoaded: e.subscriptionsLoaded.Load(),
+		CircuitBreaker:      e.circuitBreaker.State(),
+	}
+}
+
+// recordFetchSuccess marks the last fetch from the exchange as successful and closes the circuit breaker.
+func (e *ExchangeData) recordFetchSuccess() {
+	e.circuitBreaker.recordSuccess()
+
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+
+	e.lastSuccessfulFetch = time.Now()
+	e.lastError = ""
+}
+
+// recordFetchError marks the last fetch from the exchange as failed with the error,
+// the consecutive failures open the circuit breaker.
+func (e *ExchangeData) recordFetchError(err error) {
+	e.circuitBreaker.recordFailure()
+
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+
+	e.lastError = err.Error()
+}
+
+// recordPairFetchError marks the last fetch from the exchange as failed with an error caused by the pair,
+// e.g. an empty order book of a delisted symbol. The exchange responded, so the error doesn't count toward
+// the circuit breaker, and closes it if the fetch was the request testing the recovery.
+func (e *ExchangeData) recordPairFetchError(err error) {
+	e.circuitBreaker.recordSuccess()
+
+	e.statusMu.Lock()
+	defer e.statusMu.Unlock()
+
+	e.lastError = err.Error()
+}
+
+// isSuccessStatus reports whether the response status is 2xx.
+func isSuccessStatus(statusCode int) bool {
+	return statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices
+}
+
+// isRateLimitStatus reports whether the response status tells the requests are limited by the exchange.
+// Binance returns 418 to the clients which are banned for ignoring 429.
+func isRateLimitStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusTeapot
+}
+
+// isExchangeFailureStatus reports whether the response status tells the exchange fails or limits the requests,
+// unlike the other 4xx statuses, which are caused by the requested pair, e.g. a delisted symbol.
+func isExchangeFailureStatus(statusCode int) bool {
+	return statusCode >= http.StatusInternalServerError || isRateLimitStatus(statusCode)
+}
+
+// throttle backs off the requests to the exchange after a non-2xx response.
+//
+// No order book is requested until the delay of the Retry-After header of the response passes,
+// or the time between requests if there is no such header. Every consecutive rate limited response
+// doubles the time between requests up to maxThrottledInterval, until the exchange responds successfully.
+func (e *ExchangeData) throttle(resp http.Response) {
+	e.throttleMu.Lock()
+	defer e.throttleMu.Unlock()
+
+	if isRateLimitStatus(resp.StatusCode) {
+		e.rateLimitHits++
+	}
+
+	delay, ok := service.ParseRetryAfter(resp.Header.Get("Retry-After"))
+	if !ok {
+		delay = e.throttledInterval()
+	}
+
+	e.backoffUntil = time.Now().Add(delay)
+}
+
+// resetThrottle restores the configured time between requests once the exchange responds successfully.
+func (e *ExchangeData) resetThrottle() {
+	e.throttleMu.Lock()
+	defer e.throttleMu.Unlock()
+
+	e.rateLimitHits = 0
+}
+
+// nextRequestDelay returns the time to wait before the next request to the exchange,
+// which is the time between requests increased after rate limited responses, or longer while backing off.
+func (e *ExchangeData) nextRequestDelay() time.Duration {
+	e.throttleMu.Lock()
+	defer e.throttleMu.Unlock()
+
+	return max(e.throttledInterval(), time.Until(e.backoffUntil))
+}
+
+// throttledInterval returns the time between requests doubled for every consecutive rate limited response,
+// but not above maxThrottledInterval unless the configured time is longer, or the time increased
+// by adjustToRateLimitUsage if it is longer. The caller must hold throttleMu.
+func (e *ExchangeData) throttledInterval() time.Duration {
+	interval := e.timeBetweenRequests
+	for i := 0; i < e.rateLimitHits && interval < maxThrottledInterval; i++ {
+		interval *= 2
+	}
+
+	if e.rateLimitHits > 0 {
+		interval = max(min(interval, maxThrottledInterval), e.timeBetweenRequests)
+	}
+
+	return max(interval, e.usageInterval)
+}
+
+// observeRateLimitUsage makes the requests of the exchange report the rate limit usage of their responses,
+// so the time between requests is tuned by adjustToRateLimitUsage.
+func (e *ExchangeData) observeRateLimitUsage() {
+	e.httpRequestService = service.NewRateLimitObserver(e.httpRequestService, e.adjustToRateLimitUsage)
+}
+
+// adjustToRateLimitUsage tunes the time between requests to the rate limit usage reported by the exchange,
+// so the exchange is requested as often as it allows without being banned.
+//
+// Every response using at least rateLimitSlowDownUsage of the limit doubles the time between requests
+// up to maxThrottledInterval, and every response using at most rateLimitSpeedUpUsage of it halves the time back
+// until the configured one is restored. The usage in between keeps the time. If the response tells only
+// the used weight, the limit is the rateLimitWeight of the exchange, the usage is ignored if it isn't set.
+func (e *ExchangeData) adjustToRateLimitUsage(usage service.RateLimitUsage) {
+	if usage.Limit <= 0 {
+		usage.Limit = e.rateLimitWeight
+	}
+
+	if usage.Limit <= 0 {
+		return // The usage can't be told without the limit
+	}
+
+	e.throttleMu.Lock()
+	defer e.throttleMu.Unlock()
+
+	switch ratio := usage.Ratio(); {
+	case ratio >= rateLimitSlowDownUsage:
+		e.usageInterval = max(min(max(e.usageInterval, e.timeBetweenRequests)*2, maxThrottledInterval), e.timeBetweenRequests)
+	case ratio <= rateLimitSpeedUpUsage && e.usageInterval > 0:
+		e.usageInterval /= 2
+		if e.usageInterval <= e.timeBetweenRequests {
+			e.usageInterval = 0 // The configured time between requests is restored
+		}
+	}
+}
+
+// responseError returns the error of a request which returned no body to read.
+func responseError(err error) error {
+	if err == nil {
+		return errNoResponseBody
+	}
+
+	return err
+}
+
+// applySettings applies the settings shared by all exchanges, e.g. the request intervals and the circuit breaker.
+//
+// The settings configured per exchange are looked up by the exchange name, so this method must be called after
+// the data of the exchange is set. The order book batch size is applied to the exchanges having a batch fetcher only.
+func (e *ExchangeData) applySettings(deps Dependencies) {
+	e.setTimeBetweenRequests(deps.RequestIntervals) // Override the default interval if it is configured
+	e.setQuoteFilter(deps.QuoteFilters)
+	e.setVolumeSearchWorkers(deps.VolumeSearchWorkers)
+	if e.batchFetcher != nil {
+		e.setOrderbookBatchSize(deps.OrderbookBatchSize)
+	}
+	e.setMinVolumeFloor(deps.MinVolumeFloor)
+	e.setRequestHeaders(deps.RequestHeaders)
+	e.setBaseUrl(deps.BaseUrls)
+	e.circuitBreaker.configure(deps.CircuitBreaker)
+	e.setPairBlacklist(deps.PairBlacklists)
+	e.setResponseBodyLimits(deps.ResponseBodyLimits)
+	e.dryRun = deps.DryRun
+}
+
+// setTimeBetweenRequests sets the time between requests configured for the exchange.
+//
+// The interval is looked up by the exchange name, so this method must be called after the name is set.
+// If the exchange has no interval configured or the configured value isn't positive, the default
+// interval of the exchange is kept.
+func (e *ExchangeData) setTimeBetweenRequests(requestIntervals map[string]time.Duration) {
+	if interval, ok := requestIntervals[e.exchangeName]; ok && interval > 0 {
+		e.timeBetweenRequests = interval
+	}
+}
+
+// setOrderbookBatchSize sets the number of pairs whose order books are fetched by one request.
+// If the value isn't positive, the order book of every pair is fetched separately.
+func (e *ExchangeData) setOrderbookBatchSize(orderbookBatchSize int) {
+	if orderbookBatchSize < 0 {
+		orderbookBatchSize = 0
+	}
+
+	e.orderbookBatchSize = orderbookBatchSize
+}
+
+// setMinVolumeFloor sets the volume below which the levels are ignored by the exact search.
+// If the value is negative, no level is ignored.
+func (e *ExchangeData) setMinVolumeFloor(minVolumeFloor float64) {
+	e.minVolumeFloor = max(minVolumeFloor, 0)
+}
+
+// setPairBlacklist sets the pairs which are never polled if they are configured for the exchange.
+// The pairs are normalized, so the symbols of the exchange, e.g. "BTCUSDT", match the subscribed pairs.
+// A pair which can't be normalized is logged and ignored.
+func (e *ExchangeData) setPairBlacklist(pairBlacklists map[string][]string) {
+	for _, rawPair := range pairBlacklists[e.exchangeName] {
+		pair, err := models.NormalizePair(rawPair)
+		if err != nil {
+			e.logger.Error("Invalid blacklisted pair", zap.String("exchange", e.exchangeName), zap.String("pair", rawPair))
+
+			continue
+		}
+
+		if e.pairBlacklist == nil {
+			e.pairBlacklist = make(map[string]bool)
+		}
+
+		e.pairBlacklist[pair] = true
+	}
+}
+
+// setResponseBodyLimits sets the maximum sizes of the response bodies read from the exchange API per endpoint type.
+// If a value isn't positive, the default limit of its endpoint type is used.
+func (e *ExchangeData) setResponseBodyLimits(limits config.ResponseBodyLimitConfig) {
+	e.pairsBodyLimit = defaultPairsBodyLimit
+	if limits.Pairs > 0 {
+		e.pairsBodyLimit = limits.Pairs
+	}
+
+	e.orderbookBodyLimit = defaultOrderbookBodyLimit
+	if limits.Orderbook > 0 {
+		e.orderbookBodyLimit = limits.Orderbook
+	}
+}
+
+// setRequestHeaders sets the headers sent with the requests to the exchange API if they are configured for it.
+func (e *ExchangeData) setRequestHeaders(requestHeaders map[string]http.Header) {
+	e.requestHeaders = requestHeaders[e.exchangeName]
+}
+
+// setBaseUrl replaces the scheme and the host of the request URLs of the exchange with the base URL configured for it,
+// e.g. a testnet or a caching proxy. The path of the base URL prefixes the paths of the requests, and their queries are kept.
+//
+// The websocket streams from the production host regardless of the base URL, so it is disabled and the order books
+// are polled from the base URL instead. An invalid base URL can't be requested, so the production hosts are kept.
+//
+// The base URL is looked up by the exchange name, so this method must be called after the URLs are set.
+func (e *ExchangeData) setBaseUrl(baseUrls map[string]string) {
+	rawBaseUrl := baseUrls[e.exchangeName]
+	if rawBaseUrl == "" {
+		return
+	}
+
+	baseUrl, err := url.Parse(rawBaseUrl)
+	if err != nil || baseUrl.Scheme == "" || baseUrl.Host == "" {
+		e.logger.Error("Invalid exchange base url", zap.String("exchange", e.exchangeName), zap.String("url", rawBaseUrl))
+
+		return
+	}
+
+	e.pairsUrlForGetRequest = replaceBaseUrl(e.pairsUrlForGetRequest, baseUrl)
+	e.orderbookUrlForGetRequest = replaceBaseUrl(e.orderbookUrlForGetRequest, baseUrl)
+	e.tickerUrlForGetRequest = replaceBaseUrl(e.tickerUrlForGetRequest, baseUrl)
+	e.orderbookBatchUrl = replaceBaseUrl(e.orderbookBatchUrl, baseUrl)
+	e.websocketUrl = "" // Poll the order books, the stream would mix the production data in
+}
+
+// replaceBaseUrl replaces the scheme and the host of the request URL with the ones of the base URL
+// and prefixes its path with the path of the base URL. An empty request URL is kept empty.
+func replaceBaseUrl(requestUrl string, baseUrl *url.URL) string {
+	if requestUrl == "" {
+		return "" // The exchange doesn't make such requests
+	}
+
+	parsedUrl, err := url.Parse(requestUrl)
+	if err != nil {
+		return requestUrl // The URLs of the exchanges are constants, so it doesn't happen
+	}
+
+	parsedUrl.Scheme = baseUrl.Scheme
+	parsedUrl.Host = baseUrl.Host
+	parsedUrl.Path = strings.TrimSuffix(baseUrl.Path, "/") + parsedUrl.Path
+
+	return parsedUrl.String()
+}
+
+// setQuoteFilter sets the quote filter configured for the exchange.
+//
+// The filter is looked up by the exchange name, so this method must be called after the name is set.
+// If the exchange has no filter configured, the pairs of all quote assets are stored.
+func (e *ExchangeData) setQuoteFilter(quoteFilters map[string]models.QuoteFilter) {
+	e.quoteFilter = quoteFilters[e.exchangeName]
+}
+
+// setVolumeSearchWorkers sets the maximum number of users whose volumes are searched concurrently.
+// If the value isn't positive, the default limit is used.
+func (e *ExchangeData) setVolumeSearchWorkers(volumeSearchWorkers int) {
+	if volumeSearchWorkers <= 0 {
+		volumeSearchWorkers = defaultVolumeSearchWorkers
+	}
+
+	e.volumeSearchWorkers = volumeSearchWorkers
+}
+
 // ExchangeName returns the name of the exchange.
 func (e *ExchangeData) ExchangeName() string {
 	return e.exchangeName
@@ -425,15 +1605,77 @@ func (e *ExchangeData) ExchangeName() string {
 // This method does not return any values and does not produce errors. If the pair is already subscribed, this method has no effect.
 func (e *ExchangeData) AddPairToSubscribedPairs(pair string) {
 	e.pairsSubscribed.Set(pair, true)
+	e.orderbookService.Retain(pair, e.exchangeName) // Keep the order book of the pair while the exchange watches it
+	e.resubscribeWebsocket()
 }
 
+// ClearSubscribedPairsStorage removes all the pairs from the set of subscribed pairs for this exchange
+// and releases their order books, so the books nobody else watches are removed from the order book service.
 func (e *ExchangeData) ClearSubscribedPairsStorage() {
-	e.pairsSubscribed.Clear()
+	for _, pair := range e.pairsSubscribed.Keys() {
+		e.pairsSubscribed.Remove(pair)
+		e.orderbookService.Release(pair, e.exchangeName)
+	}
+	e.resubscribeWebsocket()
 }
 
 // DeletePairFromSubscribedPairs deletes a trading pair from the set of subscribed pairs for this exchange.
-// It takes a string parameter representing the pair to be deleted and sets the value in the concurrent map to false.
+// It takes a string parameter representing the pair to be deleted and removes it from the concurrent map.
+// The order book of the pair is released, so it is removed once no other section of the exchange watches the pair.
 // This method does not return any values and does not produce errors. If the pair is not subscribed, this method has no effect.
 func (e *ExchangeData) DeletePairFromSubscribedPairs(pair string) {
 	e.pairsSubscribed.Remove(pair)
+	e.orderbookService.Release(pair, e.exchangeName)
+	e.resubscribeWebsocket()
+}
+
+// sleepContext pauses the current goroutine for the given duration or until the context is cancelled.
+//
+// Returns:
+//   - bool: false if the context was cancelled before the duration elapsed, true otherwise.
+func sleepContext(ctx context.Context, duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// idleSleep returns the sleep of a loop before it checks the subscribed pairs again.
+//
+// After an active round the loop sleeps for idleInterval. Every consecutive idle round, e.g. while nothing
+// is subscribed, doubles the sleep up to maxIdleInterval, so the idle exchanges check less often.
+// The sleep is randomly changed by up to sleepJitter of it, so the loops of all exchanges don't wake
+// in lockstep and burst the requests once the pairs are subscribed.
+//
+// Parameters:
+//   - idleRounds: The number of consecutive rounds the loop had nothing to do, zero after an active round.
+//
+// Returns:
+//   - The jittered duration to sleep.
+func idleSleep(idleRounds int) time.Duration {
+	interval := idleInterval
+	for i := 1; i < idleRounds && interval < maxIdleInterval; i++ {
+		interval *= 2
+	}
+
+	return withJitter(min(interval, maxIdleInterval))
+}
+
+// withJitter returns the duration randomly increased or decreased by up to sleepJitter of it.
+func withJitter(duration time.Duration) time.Duration {
+	return duration + time.Duration((rand.Float64()*2-1)*sleepJitter*float64(duration))
+}
+
+// resubscribeWebsocket asks the order book websocket to resync its subscriptions with the subscribed pairs.
+// The request is dropped if one is already pending or if the exchange isn't streamed.
+func (e *ExchangeData) resubscribeWebsocket() {
+	select {
+	case e.websocketResubscribe <- struct{}{}:
+	default:
+	}
 }
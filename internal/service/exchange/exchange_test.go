@@ -0,0 +1,1199 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cvs/internal/config"
+	"cvs/internal/models"
+	"cvs/internal/service"
+	"cvs/internal/service/logger"
+	"cvs/internal/service/orderbook"
+
+	cmap "github.com/orcaman/concurrent-map/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRequestIntervals tests that the configured time between requests is applied to the created exchanges.
+func TestRequestIntervals(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	requestIntervals := map[string]time.Duration{
+		"binance_spot":  500 * time.Millisecond, // Overridden interval
+		"bybit_futures": 2 * time.Second,        // Overridden interval
+		"bybit_spot":    0,                      // Non-positive values keep the default
+	}
+
+	expectedIntervals := map[string]time.Duration{
+		"binance_spot":    500 * time.Millisecond,
+		"binance_futures": binanceTimeBetweenRequests,
+		"binance_coinm":   binanceTimeBetweenRequests,
+		"binance_us":      binanceTimeBetweenRequests,
+		"bybit_spot":      bybitTimeBetweenRequests,
+		"bybit_futures":   2 * time.Second,
+		"bybit_inverse":   bybitTimeBetweenRequests,
+	}
+
+	exchanges := append(
+		NewBinance(Dependencies{RequestIntervals: requestIntervals}),
+		NewBybit(Dependencies{RequestIntervals: requestIntervals})...,
+	)
+
+	assert.Equal(t, len(expectedIntervals), len(exchanges))
+
+	for _, exchange := range exchanges {
+		exchangeData := exchange.(*ExchangeData)
+
+		assert.Equal(
+			t,
+			expectedIntervals[exchangeData.ExchangeName()],
+			exchangeData.timeBetweenRequests,
+			"unexpected interval of %s",
+			exchangeData.ExchangeName(),
+		)
+	}
+}
+
+// TestStartWorkStopsOnContextCancel tests that the background loops of the exchange exit once the context is cancelled.
+func TestStartWorkStopsOnContextCancel(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	exchangeData := &ExchangeData{
+		allPairsOfExchange:   cmap.New[models.ExchangePairs](),
+		pairsSubscribed:      cmap.New[bool](), // No subscribed pairs, so the loops only wait
+		websocketResubscribe: make(chan struct{}, 1),
+		websocketUrl:         "wss://example.com/stream",
+		timeBetweenRequests:  time.Second,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	exchangeData.GetOrderbookPeriodically(ctx)
+	exchangeData.StartOrderbookWebsocket(ctx)
+	exchangeData.FindVolumeInOrderbookPeriodically(ctx)
+
+	assert.Equal(t, int64(3), exchangeData.runningLoops.Load())
+
+	cancel()
+
+	assert.Eventually(t, func() bool {
+		return exchangeData.runningLoops.Load() == 0
+	}, 500*time.Millisecond, 10*time.Millisecond)
+}
+
+// flakyUserPairsService is a user pairs service failing to get the pairs of the exchange a number of times
+// before it returns them, the other methods aren't implemented.
+type flakyUserPairsService struct {
+	service.UserPairsService // Panics if a method which isn't overridden is called
+
+	failures int      // Number of the calls which fail before the pairs are returned
+	pairs    []string // Pairs returned once the calls stop failing
+	calls    atomic.Int32
+}
+
+func (f *flakyUserPairsService) GetPairsByExchange(ctx context.Context, exchange string) ([]string, error) {
+	if int(f.calls.Add(1)) <= f.failures {
+		return nil, errors.New("connection refused")
+	}
+
+	return f.pairs, nil
+}
+
+// TestFillPairsSubscribedStorage tests that a failed load of the subscribed pairs is retried and that
+// the subscriptions are reported as loaded only once the pairs are loaded.
+func TestFillPairsSubscribedStorage(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	testLogger := logger.NewApiLogger(&config.Config{Logger: config.Logger{Level: "fatal"}}) // Keep the expected error out of the test output
+	testLogger.InitLogger()
+
+	tests := []struct {
+		name           string // Name of the test case
+		failures       int    // Number of the loads which fail
+		expectedPairs  int    // Number of the pairs expected to be subscribed
+		expectedLoaded bool   // Whether the subscriptions are expected to be reported as loaded
+	}{
+		{name: "Loaded", failures: 0, expectedPairs: 2, expectedLoaded: true},
+		{name: "Loaded After Retry", failures: 1, expectedPairs: 2, expectedLoaded: true},
+		{name: "Every Attempt Fails", failures: subscriptionsLoadAttempts, expectedPairs: 0, expectedLoaded: false},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allows subtests to run in parallel
+
+			userPairsService := &flakyUserPairsService{failures: tc.failures, pairs: []string{"BTC/USDT", "ETH/USDT"}}
+			exchangeData := &ExchangeData{
+				exchangeName:     "binance_spot",
+				userPairsService: userPairsService,
+				orderbookService: orderbook.NewOrderbook(),
+				pairsSubscribed:  cmap.New[bool](),
+				logger:           testLogger,
+			}
+
+			exchangeData.FillPairsSubscribedStorage(context.Background())
+
+			assert.Equal(t, tc.expectedPairs, exchangeData.SubscribedPairsCount())
+			assert.Equal(t, tc.expectedLoaded, exchangeData.subscriptionsLoaded.Load())
+			assert.Equal(t, int32(min(tc.failures+1, subscriptionsLoadAttempts)), userPairsService.calls.Load())
+		})
+	}
+}
+
+// TestGetOrderbookPeriodicallySkipsBlacklistedPairs tests that the order book of a blacklisted pair isn't fetched
+// even if it is subscribed, while the other subscribed pairs are.
+func TestGetOrderbookPeriodicallySkipsBlacklistedPairs(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	var (
+		mu        sync.Mutex
+		requested [][]string // Pairs of every batch request
+	)
+
+	exchangeData := &ExchangeData{
+		exchangeName:       "binance_spot",
+		orderbookService:   orderbook.NewOrderbook(),
+		allPairsOfExchange: cmap.New[models.ExchangePairs](),
+		pairsSubscribed:    cmap.New[bool](),
+		batchFetcher: func(_ context.Context, pairs []string) (map[string]bookData, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			requested = append(requested, append([]string(nil), pairs...))
+
+			return map[string]bookData{}, nil
+		},
+	}
+	exchangeData.setOrderbookBatchSize(10) // All pairs are fetched by one request
+	exchangeData.setPairBlacklist(map[string][]string{
+		"binance_spot":    {"lunausdt"}, // The symbol of the exchange is normalized
+		"binance_futures": {"BTC/USDT"}, // Blacklisted on another exchange only
+	})
+
+	for _, pair := range []string{"BTC/USDT", "LUNA/USDT", "ETH/USDT"} {
+		exchangeData.pairsSubscribed.Set(pair, true)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	exchangeData.GetOrderbookPeriodically(ctx)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(requested) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, pairs := range requested {
+		assert.ElementsMatch(t, []string{"BTC/USDT", "ETH/USDT"}, pairs)
+	}
+
+	assert.Equal(t, []string{"LUNA/USDT"}, exchangeData.BlacklistedPairs())
+}
+
+// TestGetAllPairsOfExchangeRefreshSkipsDelistedPairs tests that a subscribed pair isn't polled once a refresh
+// of the pairs shows the exchange delisted it, and that it is polled again once the exchange relists it.
+func TestGetAllPairsOfExchangeRefreshSkipsDelistedPairs(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	responses := []string{
+		`{"symbols": [{"baseAsset": "BTC", "quoteAsset": "USDT"}, {"baseAsset": "ETH", "quoteAsset": "USDT"}]}`,
+		`{"symbols": [{"baseAsset": "BTC", "quoteAsset": "USDT"}, {"baseAsset": "SOL", "quoteAsset": "USDT"}]}`, // ETH/USDT is delisted
+		`{"symbols": [{"baseAsset": "BTC", "quoteAsset": "USDT"}, {"baseAsset": "ETH", "quoteAsset": "USDT"}]}`, // ETH/USDT is relisted
+	}
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(responses[requests.Add(1)-1]))
+	}))
+	defer server.Close()
+
+	exchangeData := &ExchangeData{
+		exchangeName:           "binance_spot",
+		httpRequestService:     service.NewHttpRequestService(time.Second, ""),
+		allPairsOfExchange:     cmap.New[models.ExchangePairs](),
+		pairsSubscribed:        cmap.New[bool](),
+		pairsUrlForGetRequest:  server.URL,
+		exchangePairsJsonParse: binanceExchangePairsJsonParse,
+	}
+	exchangeData.pairsSubscribed.Set("BTC/USDT", true)
+	exchangeData.pairsSubscribed.Set("ETH/USDT", true)
+
+	assert.ElementsMatch(t, []string{"BTC/USDT", "ETH/USDT"}, exchangeData.pollablePairs()) // The pairs aren't loaded yet
+
+	assert.NoError(t, exchangeData.GetAllPairsOfExchange(context.Background()))
+	assert.ElementsMatch(t, []string{"BTC/USDT", "ETH/USDT"}, exchangeData.pollablePairs())
+
+	assert.NoError(t, exchangeData.GetAllPairsOfExchange(context.Background()))
+	assert.Equal(t, []string{"BTC/USDT"}, exchangeData.pollablePairs())
+	assert.Equal(t, 2, exchangeData.SubscribedPairsCount()) // The delisted pair stays subscribed
+
+	assert.NoError(t, exchangeData.GetAllPairsOfExchange(context.Background()))
+	assert.ElementsMatch(t, []string{"BTC/USDT", "ETH/USDT"}, exchangeData.pollablePairs())
+}
+
+// TestSetBaseUrl tests that the base URL replaces the hosts of the request URLs, keeps their queries
+// and disables the websocket streaming from the production host.
+func TestSetBaseUrl(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	exchangeData := setBinanceSpotData(&ExchangeData{})
+	exchangeData.setBaseUrl(map[string]string{"binance_spot": "https://testnet.binance.vision"})
+
+	assert.Equal(t, "https://testnet.binance.vision/api/v3/exchangeInfo", exchangeData.pairsUrlForGetRequest)
+	assert.Equal(t, "https://testnet.binance.vision/api/v1/depth?symbol=&limit=500", exchangeData.orderbookUrlForGetRequest)
+	assert.Equal(t, "https://testnet.binance.vision/api/v3/ticker/price?symbol=", exchangeData.tickerUrlForGetRequest)
+	assert.Equal(t, "https://testnet.binance.vision/api/v3/ticker/bookTicker", exchangeData.orderbookBatchUrl)
+	assert.Empty(t, exchangeData.websocketUrl)
+
+	otherExchangeData := setBinanceFuturesData(&ExchangeData{})
+	otherExchangeData.setBaseUrl(map[string]string{"binance_spot": "https://testnet.binance.vision"})
+
+	assert.Equal(t, "https://fapi.binance.com/fapi/v1/exchangeInfo", otherExchangeData.pairsUrlForGetRequest)
+	assert.NotEmpty(t, otherExchangeData.websocketUrl)
+}
+
+// TestDeletePairFromSubscribedPairsEvictsOrderbook tests that the order book shared by the sections of an exchange
+// is removed only after the last section unsubscribes from the pair.
+func TestDeletePairFromSubscribedPairsEvictsOrderbook(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	orderbookService := orderbook.NewOrderbook()
+	spot := &ExchangeData{
+		exchangeName:     "binance_spot",
+		orderbookService: orderbookService,
+		pairsSubscribed:  cmap.New[bool](),
+	}
+	futures := &ExchangeData{
+		exchangeName:     "binance_futures",
+		orderbookService: orderbookService,
+		pairsSubscribed:  cmap.New[bool](),
+	}
+
+	spot.AddPairToSubscribedPairs("BTC/USDT")
+	futures.AddPairToSubscribedPairs("BTC/USDT")
+	orderbookService.Upsert("BTC/USDT", [][]interface{}{{"100", "1"}}, [][]interface{}{{"99", "1"}})
+
+	spot.DeletePairFromSubscribedPairs("BTC/USDT")
+	_, ok := orderbookService.Snapshot("BTC/USDT", 0)
+	assert.True(t, ok, "The futures section still watches the pair")
+
+	futures.DeletePairFromSubscribedPairs("BTC/USDT")
+	_, ok = orderbookService.Snapshot("BTC/USDT", 0)
+	assert.False(t, ok, "Nobody watches the pair anymore")
+	assert.Equal(t, 0, futures.SubscribedPairsCount())
+}
+
+// TestSearchVolumesRelativeMinimumVolume tests that the exact value is the minimum volume of the levels found in the relative mode.
+func TestSearchVolumesRelativeMinimumVolume(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	orderbookService := orderbook.NewOrderbook()
+	orderbookService.Upsert(
+		"BTC/USDT",
+		[][]interface{}{{"100", "1"}, {"101", "1"}, {"102", "10"}, {"103", "1"}, {"104", "1"}}, // Ask outlier of volume 10
+		[][]interface{}{{"95", "1"}, {"96", "40"}, {"97", "1"}, {"98", "1"}, {"99", "1"}},      // Bid outlier of volume 40
+	)
+
+	exchangeData := &ExchangeData{
+		exchangeName:     "binance_spot",
+		orderbookService: orderbookService,
+	}
+
+	foundVolumes := exchangeData.searchVolumes("BTC/USDT", models.UserPairs{
+		Pair:       "BTC/USDT",
+		Exchange:   "binance_spot",
+		ExactValue: 20, // The ask outlier is too small
+		SearchMode: models.SearchModeRelative,
+		Multiplier: 5,
+		Window:     2,
+	})
+
+	assert.Equal(t, 2, len(foundVolumes))
+
+	for _, volume := range foundVolumes {
+		assert.Equal(t, "BTC/USDT", volume.Pair)
+		assert.Equal(t, "binance_spot", volume.Exchange)
+
+		if volume.Side == "asks" {
+			assert.Equal(t, 0.0, volume.Price) // Not reported, so the previously found volume is removed
+		} else {
+			assert.Equal(t, 96.0, volume.Price)
+		}
+	}
+}
+
+// TestPreviewVolumes tests that the volumes found by an exact value are returned without being stored,
+// only for the sides where something was found and with the asks first.
+func TestPreviewVolumes(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	orderbookService := orderbook.NewOrderbook()
+	orderbookService.Upsert(
+		"BTC/USDT",
+		[][]interface{}{{"100", "1"}, {"101", "12"}, {"102", "3"}},
+		[][]interface{}{{"99", "2"}, {"98", "15"}, {"97", "3"}},
+	)
+
+	exchangeData := &ExchangeData{
+		exchangeName:     "binance_spot",
+		orderbookService: orderbookService,
+		minVolumeFloor:   1,
+	}
+
+	foundVolumes, ok := exchangeData.PreviewVolumes("BTC/USDT", 13)
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(foundVolumes)) // No ask reaches the value
+	assert.Equal(t, "bids", foundVolumes[0].Side)
+	assert.Equal(t, 98.0, foundVolumes[0].Price)
+
+	foundVolumes, ok = exchangeData.PreviewVolumes("BTC/USDT", 10)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(foundVolumes))
+	assert.Equal(t, "asks", foundVolumes[0].Side)
+	assert.Equal(t, 101.0, foundVolumes[0].Price)
+	assert.Equal(t, "bids", foundVolumes[1].Side)
+
+	_, ok = exchangeData.PreviewVolumes("ETH/USDT", 10)
+	assert.False(t, ok) // The pair has no order book
+}
+
+// TestSearchVolumesMinVolumeFloor tests that the levels below the minimum volume floor of the exchange
+// aren't found in either search mode, even if the exact value of the user is lower.
+func TestSearchVolumesMinVolumeFloor(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	orderbookService := orderbook.NewOrderbook()
+	orderbookService.Upsert(
+		"BTC/USDT",
+		[][]interface{}{{"100", "0.1"}, {"101", "0.1"}, {"102", "2"}, {"103", "0.1"}, {"104", "0.1"}}, // Dust ask outlier of volume 2
+		[][]interface{}{{"95", "1"}, {"96", "40"}, {"97", "1"}, {"98", "1"}, {"99", "1"}},             // Bid outlier of volume 40
+	)
+
+	exchangeData := &ExchangeData{
+		exchangeName:     "binance_spot",
+		orderbookService: orderbookService,
+	}
+	exchangeData.setMinVolumeFloor(5)
+
+	exact := models.UserPairs{Pair: "BTC/USDT", Exchange: "binance_spot", ExactValue: 0.1}
+	relative := models.UserPairs{
+		Pair:       "BTC/USDT",
+		Exchange:   "binance_spot",
+		ExactValue: 0.1,
+		SearchMode: models.SearchModeRelative,
+		Multiplier: 5,
+		Window:     2,
+	}
+
+	for _, pairSettings := range []models.UserPairs{exact, relative} {
+		foundVolumes := exchangeData.searchVolumes("BTC/USDT", pairSettings)
+		assert.Equal(t, 2, len(foundVolumes))
+
+		for _, volume := range foundVolumes {
+			if volume.Side == "asks" {
+				assert.Equal(t, 0.0, volume.Price, "mode %q", pairSettings.SearchMode) // Every ask is dust
+			} else {
+				assert.Equal(t, 96.0, volume.Price, "mode %q", pairSettings.SearchMode)
+			}
+		}
+	}
+}
+
+// TestAllPairs tests that the pairs of the exchange are returned sorted by the pair name.
+func TestAllPairs(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	exchangeData := &ExchangeData{
+		allPairsOfExchange: cmap.New[models.ExchangePairs](),
+	}
+	assert.Empty(t, exchangeData.AllPairs()) // No pairs are loaded yet
+
+	exchangeData.SetEchangePairsToStorage([]models.ExchangePairs{
+		{Pair: "ETH/USDT", Exchange: "binance_spot"},
+		{Pair: "BTC/USDT", Exchange: "binance_spot"},
+		{Pair: "ADA/USDT", Exchange: "binance_spot"},
+	})
+
+	assert.Equal(t, []models.ExchangePairs{
+		{Pair: "ADA/USDT", Exchange: "binance_spot"},
+		{Pair: "BTC/USDT", Exchange: "binance_spot"},
+		{Pair: "ETH/USDT", Exchange: "binance_spot"},
+	}, exchangeData.AllPairs())
+}
+
+// TestSearchPairs tests that the pairs are found by a case-insensitive substring of their base or quote asset.
+func TestSearchPairs(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	exchangeData := &ExchangeData{
+		allPairsOfExchange: cmap.New[models.ExchangePairs](),
+	}
+	exchangeData.SetEchangePairsToStorage([]models.ExchangePairs{
+		{Pair: "ETH/BTC", Exchange: "binance_spot"},
+		{Pair: "BTC/USDT", Exchange: "binance_spot"},
+		{Pair: "WBTC/USDT", Exchange: "binance_spot"},
+		{Pair: "ADA/USDT", Exchange: "binance_spot"},
+	})
+
+	assert.Equal(t, []models.ExchangePairs{
+		{Pair: "BTC/USDT", Exchange: "binance_spot"},
+		{Pair: "ETH/BTC", Exchange: "binance_spot"}, // The quote asset matches
+		{Pair: "WBTC/USDT", Exchange: "binance_spot"},
+	}, exchangeData.SearchPairs("btc"))
+	assert.Empty(t, exchangeData.SearchPairs("C/U")) // The substring isn't matched across the slash
+	assert.Len(t, exchangeData.SearchPairs("usdt"), 3)
+}
+
+// TestHasPair tests that the listed pairs are told apart from the unlisted ones only after the pairs are loaded.
+func TestHasPair(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	exchangeData := &ExchangeData{
+		allPairsOfExchange: cmap.New[models.ExchangePairs](),
+	}
+	assert.False(t, exchangeData.PairsLoaded()) // No pairs are loaded yet
+	assert.False(t, exchangeData.HasPair("BTC/USDT"))
+
+	exchangeData.SetEchangePairsToStorage([]models.ExchangePairs{
+		{Pair: "BTC/USDT", Exchange: "binance_spot"},
+	})
+
+	assert.True(t, exchangeData.PairsLoaded())
+	assert.Equal(t, 1, exchangeData.AllPairsCount())
+	assert.True(t, exchangeData.HasPair("BTC/USDT"))
+	assert.False(t, exchangeData.HasPair("FOO/BAR")) // The exchange doesn't list the pair
+	assert.True(t, exchangeData.HasPair("btcusdt"))  // The pair is normalized before it is looked up
+	assert.True(t, exchangeData.HasPair("BTC-USDT"))
+}
+
+// TestStatus tests that the status of the exchange reflects the result of the last fetch.
+func TestStatus(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	exchangeData := &ExchangeData{
+		exchangeName:    "binance_spot",
+		pairsSubscribed: cmap.New[bool](),
+	}
+	exchangeData.pairsSubscribed.Set("BTC/USDT", true)
+
+	status := exchangeData.Status()
+	assert.Equal(t, "binance_spot", status.Exchange)
+	assert.True(t, status.LastSuccessfulFetch.IsZero()) // Nothing is fetched yet
+	assert.Equal(t, 1, status.SubscribedPairs)
+	assert.Equal(t, 1, exchangeData.SubscribedPairsCount())
+	assert.Equal(t, models.CircuitBreakerClosed, status.CircuitBreaker)
+
+	exchangeData.recordFetchError(responseError(nil))
+	status = exchangeData.Status()
+	assert.Equal(t, errNoResponseBody.Error(), status.LastError)
+	assert.True(t, status.LastSuccessfulFetch.IsZero())
+
+	before := time.Now()
+	exchangeData.recordFetchSuccess()
+	status = exchangeData.Status()
+	assert.Empty(t, status.LastError) // The error is cleared by the successful fetch
+	assert.False(t, status.LastSuccessfulFetch.Before(before))
+
+	lastSuccessfulFetch := status.LastSuccessfulFetch
+	exchangeData.recordFetchError(errUnmarshal("orderbook", "binance_spot"))
+	status = exchangeData.Status()
+	assert.NotEmpty(t, status.LastError)
+	assert.Equal(t, lastSuccessfulFetch, status.LastSuccessfulFetch) // A failed fetch keeps the last success
+
+	for i := 0; i < defaultBreakerFailureThreshold; i++ {
+		exchangeData.recordFetchError(errNoResponseBody)
+	}
+	assert.Equal(t, models.CircuitBreakerOpen, exchangeData.Status().CircuitBreaker) // The consecutive failures open the breaker
+}
+
+// TestGetOrderbookBatchFromExchange tests that the order books of all pairs returned by the batch fetcher are stored.
+func TestGetOrderbookBatchFromExchange(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	orderbookService := orderbook.NewOrderbook()
+	orderbookService.Upsert("ADA/USDT", [][]interface{}{{"0.6", "10"}}, [][]interface{}{{"0.4", "10"}}) // Previous order book
+
+	exchangeData := &ExchangeData{
+		exchangeName:     "binance_spot",
+		orderbookService: orderbookService,
+		pairsSubscribed:  cmap.New[bool](),
+		batchFetcher: func(_ context.Context, pairs []string) (map[string]bookData, error) {
+			assert.Equal(t, []string{"BTC/USDT", "ETH/USDT", "ADA/USDT"}, pairs)
+
+			return map[string]bookData{
+				"BTC/USDT": {asks: [][]interface{}{{"101", "1"}}, bids: [][]interface{}{{"99", "2"}}},
+				"ETH/USDT": {asks: [][]interface{}{{"11", "3"}}, bids: [][]interface{}{{"9", "4"}}},
+				// ADA/USDT is missing from the response
+			}, nil
+		},
+	}
+
+	exchangeData.getOrderbookBatchFromExchange(context.Background(), []string{"BTC/USDT", "ETH/USDT", "ADA/USDT"})
+
+	testCases := []struct {
+		pair    string
+		bestBid float64
+		bestAsk float64
+	}{
+		{pair: "BTC/USDT", bestBid: 99, bestAsk: 101},
+		{pair: "ETH/USDT", bestBid: 9, bestAsk: 11},
+		{pair: "ADA/USDT", bestBid: 0.4, bestAsk: 0.6}, // The previous order book is kept
+	}
+
+	for _, tt := range testCases {
+		snapshot, ok := exchangeData.BestPrices(tt.pair)
+
+		assert.True(t, ok, tt.pair)
+		assert.Equal(t, tt.bestBid, snapshot.BestBid, tt.pair)
+		assert.Equal(t, tt.bestAsk, snapshot.BestAsk, tt.pair)
+	}
+
+	assert.Empty(t, exchangeData.Status().LastError)
+}
+
+// TestThrottle tests that the requests back off for the delay of the Retry-After header, the consecutive
+// rate limited responses double the time between requests up to the limit, and a success restores it.
+func TestThrottle(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	rateLimited := func(retryAfter string) http.Response {
+		resp := http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+
+		return resp
+	}
+
+	exchangeData := &ExchangeData{timeBetweenRequests: 10 * time.Second}
+
+	assert.Equal(t, 10*time.Second, exchangeData.nextRequestDelay())
+
+	exchangeData.throttle(rateLimited("30")) // The exchange tells how long to wait
+	assert.InDelta(t, 30*time.Second, exchangeData.nextRequestDelay(), float64(time.Second))
+
+	exchangeData.throttle(rateLimited("")) // Without the header the doubled time between requests is waited
+	assert.Equal(t, 40*time.Second, exchangeData.nextRequestDelay())
+
+	exchangeData.throttle(http.Response{StatusCode: http.StatusTeapot}) // The ban of Binance is a rate limit as well
+	exchangeData.throttle(rateLimited(""))
+	assert.Equal(t, maxThrottledInterval, exchangeData.nextRequestDelay()) // The increase is limited
+
+	exchangeData.throttle(http.Response{StatusCode: http.StatusServiceUnavailable}) // Not a rate limit, so the time isn't increased
+	assert.Equal(t, maxThrottledInterval, exchangeData.nextRequestDelay())
+
+	exchangeData.resetThrottle()
+	exchangeData.backoffUntil = time.Time{} // The back off has passed
+	assert.Equal(t, 10*time.Second, exchangeData.nextRequestDelay())
+}
+
+// TestAdjustToRateLimitUsage tests that the time between requests grows while the responses report a high used weight
+// and shrinks back to the configured one once the used weight recedes.
+func TestAdjustToRateLimitUsage(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	var usedWeight atomic.Int64
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-MBX-USED-WEIGHT-1M", strconv.FormatInt(usedWeight.Load(), 10))
+	}))
+	defer server.Close()
+
+	exchangeData := &ExchangeData{
+		timeBetweenRequests: 10 * time.Second,
+		rateLimitWeight:     1000,
+		httpRequestService:  service.NewHttpRequestService(time.Second, ""),
+	}
+	exchangeData.observeRateLimitUsage()
+
+	request := func(weight int64) time.Duration {
+		usedWeight.Store(weight)
+
+		resp, err := exchangeData.httpRequestService.GetWithRetry(context.Background(), server.URL, nil, 1, time.Millisecond)
+		assert.NoError(t, err)
+		resp.Body.Close()
+
+		return exchangeData.nextRequestDelay()
+	}
+
+	assert.Equal(t, 10*time.Second, request(300)) // The low usage keeps the configured time
+	assert.Equal(t, 20*time.Second, request(850)) // The usage approaching the limit slows the requests down
+	assert.Equal(t, 40*time.Second, request(950)) // and further while it stays high
+	assert.Equal(t, 40*time.Second, request(700)) // The moderate usage keeps the time
+	assert.Equal(t, time.Minute, request(990))    // The increase is limited
+	assert.Equal(t, time.Minute, request(1000))   // even at the limit
+	assert.Equal(t, 30*time.Second, request(200)) // The receding usage speeds the requests back up
+	assert.Equal(t, 15*time.Second, request(100)) // gradually
+	assert.Equal(t, 10*time.Second, request(0))   // until the configured time is restored
+	assert.Equal(t, 10*time.Second, request(0))   // but not below it
+
+	exchangeData.rateLimitWeight = 0 // The usage can't be told without the limit
+	assert.Equal(t, 10*time.Second, request(1000))
+}
+
+// TestIdleSleep tests that the sleep of the loops doubles with the consecutive idle rounds up to the limit
+// and is jittered within the expected range.
+func TestIdleSleep(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		idleRounds int
+		expected   time.Duration // Sleep without the jitter
+	}{
+		{idleRounds: 0, expected: idleInterval},
+		{idleRounds: 1, expected: idleInterval},
+		{idleRounds: 2, expected: 2 * idleInterval},
+		{idleRounds: 3, expected: 4 * idleInterval},
+		{idleRounds: 10, expected: maxIdleInterval},
+	}
+
+	for _, tt := range tests {
+		lowest := time.Duration(float64(tt.expected) * (1 - sleepJitter))
+		highest := time.Duration(float64(tt.expected) * (1 + sleepJitter))
+
+		sleeps := make(map[time.Duration]struct{})
+		for range 100 {
+			sleep := idleSleep(tt.idleRounds)
+
+			assert.GreaterOrEqual(t, sleep, lowest, "idle rounds %d", tt.idleRounds)
+			assert.LessOrEqual(t, sleep, highest, "idle rounds %d", tt.idleRounds)
+
+			sleeps[sleep] = struct{}{}
+		}
+
+		assert.Greater(t, len(sleeps), 1, "the sleep of idle rounds %d must be jittered", tt.idleRounds)
+	}
+}
+
+// TestFetchOrderbooksInBatches tests that the pairs are split into batches of the configured size
+// and that the order book of every pair is fetched separately when batches are disabled.
+func TestFetchOrderbooksInBatches(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	pairs := []string{"A/USDT", "B/USDT", "C/USDT", "D/USDT", "E/USDT"}
+
+	testCases := []struct {
+		name      string
+		batchSize int
+		expected  [][]string
+	}{
+		{
+			name:      "Batches of two pairs",
+			batchSize: 2,
+			expected:  [][]string{{"A/USDT", "B/USDT"}, {"C/USDT", "D/USDT"}, {"E/USDT"}},
+		},
+		{
+			name:      "One batch of all pairs",
+			batchSize: 10,
+			expected:  [][]string{pairs},
+		},
+		{
+			name:      "Batches disabled",
+			batchSize: 0,
+			expected:  nil, // The batch fetcher isn't used
+		},
+	}
+
+	for _, tt := range testCases {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allows this subtest to run in parallel with other subtests
+
+			var (
+				mu      sync.Mutex
+				batches [][]string
+			)
+
+			exchangeData := &ExchangeData{
+				exchangeName:     "binance_spot",
+				orderbookService: orderbook.NewOrderbook(),
+				batchFetcher: func(_ context.Context, pairs []string) (map[string]bookData, error) {
+					mu.Lock()
+					defer mu.Unlock()
+
+					batches = append(batches, append([]string(nil), pairs...))
+
+					return map[string]bookData{}, nil
+				},
+			}
+			exchangeData.setOrderbookBatchSize(tc.batchSize)
+
+			if tc.batchSize == 0 {
+				// Without batches the depth of every pair is requested separately
+				httpRequestService := service.NewHttpRequestService(time.Second, "")
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Write([]byte(`{"asks":[["101","1"]],"bids":[["99","1"]]}`))
+				}))
+				defer server.Close()
+
+				exchangeData.httpRequestService = httpRequestService
+				exchangeData.orderbookUrlForGetRequest = server.URL + "?symbol="
+				exchangeData.urlFormatter = binanceUrlFormatter
+				exchangeData.orderbookJsonParse = binanceOrderbookJsonParse
+			}
+
+			assert.True(t, exchangeData.fetchOrderbooks(context.Background(), pairs))
+			assert.Equal(t, tc.expected, batches)
+
+			if tc.batchSize == 0 {
+				for _, pair := range pairs {
+					_, ok := exchangeData.BestPrices(pair)
+					assert.True(t, ok, pair)
+				}
+			}
+		})
+	}
+}
+
+// TestBinanceBookTickerFetcher tests that the bookTicker response is parsed into the best levels of the requested pairs.
+func TestBinanceBookTickerFetcher(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	var requestedSymbols string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedSymbols = r.URL.Query().Get("symbols")
+
+		w.Write([]byte(`[
+			{"symbol":"BTCUSDT","bidPrice":"99.5","bidQty":"2","askPrice":"100.5","askQty":"3"},
+			{"symbol":"ETHUSDT","bidPrice":"9.5","bidQty":"20","askPrice":"10.5","askQty":"30"},
+			{"symbol":"XRPUSDT","bidPrice":"0.5","bidQty":"1","askPrice":"0.6","askQty":"1"}
+		]`))
+	}))
+	defer server.Close()
+
+	exchangeData := &ExchangeData{
+		exchangeName:       "binance_spot",
+		httpRequestService: service.NewHttpRequestService(time.Second, ""),
+		orderbookBatchUrl:  server.URL,
+	}
+
+	books, err := binanceBookTickerFetcher(exchangeData, true)(context.Background(), []string{"BTC/USDT", "ETH/USDT"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `["BTCUSDT","ETHUSDT"]`, requestedSymbols)
+	assert.Equal(t, map[string]bookData{
+		"BTC/USDT": {asks: [][]interface{}{{"100.5", "3"}}, bids: [][]interface{}{{"99.5", "2"}}},
+		"ETH/USDT": {asks: [][]interface{}{{"10.5", "30"}}, bids: [][]interface{}{{"9.5", "20"}}},
+	}, books) // The ticker of the pair which wasn't requested is skipped
+}
+
+// TestBinanceBookTickerFetcherNonJsonResponse tests that the HTML page of a weight ban is reported
+// as rate limited or banned instead of a parse failure and backs off for the time the exchange tells.
+func TestBinanceBookTickerFetcherNonJsonResponse(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	testLogger := logger.NewApiLogger(&config.Config{Logger: config.Logger{Level: "error"}}) // Keep the expected warning out of the test output
+	testLogger.InitLogger()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte(`<html><body>Way too many requests, IP banned</body></html>`))
+	}))
+	defer server.Close()
+
+	exchangeData := &ExchangeData{
+		exchangeName:       "binance_spot",
+		httpRequestService: service.NewHttpRequestService(time.Second, ""),
+		logger:             testLogger,
+		orderbookBatchUrl:  server.URL,
+	}
+
+	books, err := binanceBookTickerFetcher(exchangeData, true)(context.Background(), []string{"BTC/USDT"})
+
+	assert.Nil(t, books)
+	assert.True(t, errors.Is(err, errNonJsonResponse))
+	assert.ErrorContains(t, err, "status 418")
+	assert.InDelta(t, 30, exchangeData.nextRequestDelay().Seconds(), 1) // The ban is waited out
+}
+
+// TestResponseBodyLimit tests that a response streaming a body above the limit isn't read past the limit
+// and fails the request, so neither the order book nor the pairs are stored, while a body within the limit is.
+func TestResponseBodyLimit(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	testLogger := logger.NewApiLogger(&config.Config{Logger: config.Logger{Level: "error"}}) // Keep the expected warnings out of the test output
+	testLogger.InitLogger()
+
+	const limit = 1024 // Maximum size of the bodies in bytes
+
+	var written atomic.Int64 // Bytes of the huge body written by the server
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("symbol") == "SMALLUSDT" {
+			w.Write([]byte(`{"lastUpdateId":1,"bids":[["99","5"]],"asks":[["101","3"]]}`))
+
+			return
+		}
+
+		w.Write([]byte(`{"symbols":[`))
+
+		chunk := []byte(strings.Repeat(`{"baseAsset":"BTC","quoteAsset":"USDT"},`, 100))
+		for i := 0; i < 10000; i++ { // Stream up to 40 MB, far above the limit, until the client stops reading
+			n, err := w.Write(chunk)
+			written.Add(int64(n))
+			if err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	exchangeData := &ExchangeData{
+		exchangeName:              "binance_spot",
+		httpRequestService:        service.NewHttpRequestService(10*time.Second, ""),
+		logger:                    testLogger,
+		orderbookService:          orderbook.NewOrderbook(),
+		allPairsOfExchange:        cmap.New[models.ExchangePairs](),
+		pairsSubscribed:           cmap.New[bool](),
+		pairsUrlForGetRequest:     server.URL + "/exchangeInfo",
+		orderbookUrlForGetRequest: server.URL + "/depth?symbol=",
+		urlFormatter:              binanceUrlFormatter,
+		orderbookJsonParse:        binanceOrderbookJsonParse,
+		exchangePairsJsonParse:    binanceExchangePairsJsonParse,
+	}
+	exchangeData.setResponseBodyLimits(config.ResponseBodyLimitConfig{Pairs: limit, Orderbook: limit})
+
+	exchangeData.GetOrderbookDataFromExchange(context.Background(), "HUGE/USDT")
+
+	_, ok := exchangeData.BestPrices("HUGE/USDT")
+	assert.False(t, ok)
+	assert.Contains(t, exchangeData.Status().LastError, errResponseTooLarge.Error())
+
+	exchangeData.GetAllPairsOfExchange(context.Background())
+
+	assert.False(t, exchangeData.PairsLoaded())
+	assert.Contains(t, exchangeData.Status().LastError, errResponseTooLarge.Error())
+	assert.Less(t, written.Load(), int64(10000*4000)) // The server stopped streaming once the client stopped reading
+
+	exchangeData.GetOrderbookDataFromExchange(context.Background(), "SMALL/USDT") // A body within the limit is read as usual
+
+	_, ok = exchangeData.BestPrices("SMALL/USDT")
+	assert.True(t, ok)
+	assert.Empty(t, exchangeData.Status().LastError)
+}
+
+// TestReadBody tests that the body is read up to the limit only and that a body above it is rejected.
+func TestReadBody(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name          string // Name of the test case
+		size          int    // Size of the body in bytes
+		limit         int64  // Maximum size of the body
+		expectedError bool   // Whether the body is expected to be rejected
+	}{
+		{name: "Within Limit", size: 100, limit: 200},
+		{name: "Exactly Limit", size: 200, limit: 200},
+		{name: "Above Limit", size: 201, limit: 200, expectedError: true},
+		{name: "Far Above Limit", size: 1 << 20, limit: 200, expectedError: true},
+		{name: "No Limit", size: 1 << 20, limit: 0},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allows subtests to run in parallel
+
+			body := strings.NewReader(strings.Repeat("a", tc.size))
+
+			bodyBytes, err := readBody(body, tc.limit)
+
+			if tc.expectedError {
+				assert.ErrorIs(t, err, errResponseTooLarge)
+				assert.Nil(t, bodyBytes)
+				assert.Equal(t, tc.size-int(tc.limit)-1, body.Len()) // Only one byte above the limit was read
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, bodyBytes, tc.size)
+		})
+	}
+}
+
+// TestIsNonJsonResponse tests which response bodies are recognized as not being JSON.
+func TestIsNonJsonResponse(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name        string // Name of the test case
+		contentType string // Content type of the response
+		body        string // Body of the response
+		expected    bool   // Whether the body is expected not to be JSON
+	}{
+		{name: "HTML Page", contentType: "text/html", body: "<html><body>banned</body></html>", expected: true},
+		{name: "HTML Page Without Type", body: "\n  <!DOCTYPE html><html></html>", expected: true},
+		{name: "HTML Page As JSON", contentType: "application/json", body: "<html></html>", expected: true},
+		{name: "Plain Text Notice", contentType: "text/plain; charset=utf-8", body: "Forbidden", expected: true},
+		{name: "JSON As Plain Text", contentType: "text/plain", body: `{"lastUpdateId":1}`, expected: false},
+		{name: "JSON Array As Plain Text", contentType: "text/plain", body: `[{"symbol":"BTCUSDT"}]`, expected: false},
+		{name: "JSON", contentType: "application/json;charset=UTF-8", body: `{"code":-1003}`, expected: false},
+		{name: "Text Without Type", body: "Forbidden", expected: false}, // Left to the parser, the type doesn't tell
+		{name: "Empty Body", contentType: "text/html", body: "  ", expected: false},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allows subtests to run in parallel
+
+			resp := http.Response{Header: http.Header{}}
+			if tc.contentType != "" {
+				resp.Header.Set("Content-Type", tc.contentType)
+			}
+
+			assert.Equal(t, tc.expected, isNonJsonResponse(resp, []byte(tc.body)))
+		})
+	}
+}
+
+// TestGetLastPriceFromExchange tests that the last traded price is fetched from the ticker endpoint of the pair
+// and that a failed or cancelled request keeps the previous price.
+func TestGetLastPriceFromExchange(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	var requestedSymbol string
+	status := http.StatusOK
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedSymbol = r.URL.Query().Get("symbol")
+
+		w.WriteHeader(status)
+		w.Write([]byte(`{"symbol":"BTCUSDT","price":"100.5"}`))
+	}))
+	defer server.Close()
+
+	testLogger := logger.NewApiLogger(&config.Config{Logger: config.Logger{Level: "error"}}) // Keep the expected warnings out of the test output
+	testLogger.InitLogger()
+
+	exchangeData := &ExchangeData{
+		exchangeName:           "binance_spot",
+		httpRequestService:     service.NewHttpRequestService(time.Second, ""),
+		logger:                 testLogger,
+		tickerUrlForGetRequest: server.URL + "?symbol=",
+		urlFormatter:           binanceUrlFormatter,
+		tickerJsonParse:        binanceTickerJsonParse,
+		lastPrices:             cmap.New[lastPrice](),
+	}
+
+	exchangeData.getLastPriceFromExchange(context.Background(), "BTC/USDT")
+
+	last, ok := exchangeData.lastPrices.Get("BTC/USDT")
+	assert.True(t, ok)
+	assert.Equal(t, "BTCUSDT", requestedSymbol)
+	assert.Equal(t, 100.5, last.price)
+
+	status = http.StatusServiceUnavailable
+	exchangeData.setLastPrice("BTC/USDT", 99)
+	exchangeData.getLastPriceFromExchange(context.Background(), "BTC/USDT")
+
+	last, _ = exchangeData.lastPrices.Get("BTC/USDT")
+	assert.Equal(t, float64(99), last.price) // The previous price is kept
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	status = http.StatusOK
+	exchangeData.getLastPriceFromExchange(ctx, "BTC/USDT")
+
+	last, _ = exchangeData.lastPrices.Get("BTC/USDT")
+	assert.Equal(t, float64(99), last.price) // The request is cancelled with the context
+}
+
+// TestWithLastPrice tests that the found volumes are put in the context of the last traded price of their pair
+// only if the price is known and fresh.
+func TestWithLastPrice(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name             string             // Name of the test case
+		lastPrice        float64            // Last traded price of the pair, zero if it isn't stored
+		fetchedAgo       time.Duration      // Time since the last traded price was fetched
+		volume           models.FoundVolume // Found volume passed to withLastPrice
+		expectedLast     float64            // Expected last price of the found volume
+		expectedDistance float64            // Expected distance of the found volume from the last price
+	}{
+		{
+			name:             "Ask Above Last Price",
+			lastPrice:        100,
+			volume:           models.FoundVolume{Pair: "BTC/USDT", Side: "asks", Price: 102},
+			expectedLast:     100,
+			expectedDistance: 2,
+		},
+		{
+			name:             "Bid Below Last Price",
+			lastPrice:        200,
+			volume:           models.FoundVolume{Pair: "BTC/USDT", Side: "bids", Price: 190},
+			expectedLast:     200,
+			expectedDistance: -5,
+		},
+		{
+			name:   "Unknown Last Price",
+			volume: models.FoundVolume{Pair: "BTC/USDT", Side: "asks", Price: 102},
+		},
+		{
+			name:       "Stale Last Price",
+			lastPrice:  100,
+			fetchedAgo: lastPriceMaxAge + time.Minute,
+			volume:     models.FoundVolume{Pair: "BTC/USDT", Side: "asks", Price: 102},
+		},
+		{
+			name:      "Nothing Found",
+			lastPrice: 100,
+			volume:    models.FoundVolume{Pair: "BTC/USDT", Side: "asks"},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			exchangeData := &ExchangeData{
+				tickerJsonParse: binanceTickerJsonParse,
+				lastPrices:      cmap.New[lastPrice](),
+			}
+			if tc.lastPrice != 0 {
+				exchangeData.lastPrices.Set("BTC/USDT", lastPrice{price: tc.lastPrice, fetchedAt: time.Now().Add(-tc.fetchedAgo)})
+			}
+
+			volume := exchangeData.withLastPrice(tc.volume)
+
+			assert.Equal(t, tc.expectedLast, volume.LastPrice)
+			assert.InDelta(t, tc.expectedDistance, volume.DistanceFromLast, 1e-9)
+			assert.Equal(t, tc.volume.Price, volume.Price) // The found volume itself isn't changed
+		})
+	}
+}
+
+// TestTickerJsonParse tests parsing of the last traded price from the ticker responses of the exchanges.
+func TestTickerJsonParse(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name          string                                  // Name of the test case
+		parse         func(bodyBytes []byte) (float64, error) // Ticker parser of the exchange
+		body          string                                  // Response body of the ticker request
+		expectedPrice float64                                 // Price expected to be parsed
+		expectErr     bool                                    // Expected outcome: true if an error is expected
+	}{
+		{
+			name:          "Binance",
+			parse:         binanceTickerJsonParse,
+			body:          `{"symbol":"BTCUSDT","price":"50000.10"}`,
+			expectedPrice: 50000.10,
+		},
+		{
+			name:          "Binance COIN-M",
+			parse:         binanceCoinmTickerJsonParse,
+			body:          `[{"symbol":"BTCUSD_PERP","ps":"BTCUSD","price":"50000.15","time":1700000000000}]`,
+			expectedPrice: 50000.15,
+		},
+		{
+			name:      "Binance COIN-M Unknown Symbol",
+			parse:     binanceCoinmTickerJsonParse,
+			body:      `[]`,
+			expectErr: true,
+		},
+		{
+			name:          "Bybit",
+			parse:         bybitTickerJsonParse,
+			body:          `{"retCode":0,"retMsg":"OK","result":{"category":"spot","list":[{"symbol":"BTCUSDT","lastPrice":"50000.2"}]}}`,
+			expectedPrice: 50000.2,
+		},
+		{
+			name:      "Bybit Error",
+			parse:     bybitTickerJsonParse,
+			body:      `{"retCode":10001,"retMsg":"Not supported symbols","result":{"list":[]}}`,
+			expectErr: true,
+		},
+		{
+			name:          "Kraken",
+			parse:         krakenTickerJsonParse,
+			body:          `{"error":[],"result":{"XXBTZUSD":{"a":["50001","1","1.0"],"b":["49999","1","1.0"],"c":["50000.3","0.01"]}}}`,
+			expectedPrice: 50000.3,
+		},
+		{
+			name:      "Kraken Error",
+			parse:     krakenTickerJsonParse,
+			body:      `{"error":["EQuery:Unknown asset pair"]}`,
+			expectErr: true,
+		},
+		{
+			name:          "OKX",
+			parse:         okxTickerJsonParse,
+			body:          `{"code":"0","msg":"","data":[{"instId":"BTC-USDT","last":"50000.4","ts":"1700000000000"}]}`,
+			expectedPrice: 50000.4,
+		},
+		{
+			name:      "OKX Error",
+			parse:     okxTickerJsonParse,
+			body:      `{"code":"51001","msg":"Instrument ID does not exist","data":[]}`,
+			expectErr: true,
+		},
+		{
+			name:          "Coinbase",
+			parse:         coinbaseTickerJsonParse,
+			body:          `{"pricebook":{"product_id":"BTC-USD","bids":[],"asks":[]},"last":"50000.5"}`,
+			expectedPrice: 50000.5,
+		},
+		{
+			name:      "Missing Price",
+			parse:     coinbaseTickerJsonParse,
+			body:      `{"pricebook":{"product_id":"BTC-USD","bids":[],"asks":[]}}`,
+			expectErr: true,
+		},
+		{
+			name:      "Invalid JSON",
+			parse:     binanceTickerJsonParse,
+			body:      `<html>maintenance</html>`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			price, err := tc.parse([]byte(tc.body))
+
+			if tc.expectErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPrice, price)
+		})
+	}
+}
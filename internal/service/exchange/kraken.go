@@ -0,0 +1,218 @@
+package exchange
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"cvs/internal/models"
+	"cvs/internal/service/orderbook"
+
+	"github.com/goccy/go-json"
+	cmap "github.com/orcaman/concurrent-map/v2"
+)
+
+// Overall data for all sections of the Kraken exchange
+var (
+	krakenTimeBetweenRequests = 3 * time.Second                      // Time interval between requests to the Kraken API
+	krakenPairsJsonModel      = models.KrakenPairsJSONResponse{}     // Model for Kraken pairs JSON response
+	krakenOrderbookJsonModel  = models.KrakenOrderbookJSONResponse{} // Model for Kraken order book JSON response
+	krakenOrderbookService    = orderbook.NewOrderbook()             // Instance of the order book service for managing order data
+
+	// Kraken names of the assets which differ from the names used by other exchanges
+	krakenAssetNames = map[string]string{
+		"BTC":  "XBT",
+		"DOGE": "XDG",
+	}
+
+	// Common names of the assets which Kraken names differently
+	krakenCommonAssetNames = map[string]string{
+		"XBT": "BTC",
+		"XDG": "DOGE",
+	}
+
+	// Function to parse order book JSON response from Kraken
+	krakenOrderbookJsonParse = func(bodyBytes []byte) ([][]interface{}, [][]interface{}, error) {
+		var model models.KrakenOrderbookJSONResponse
+
+		// Unmarshal the response body into jsonData to inspect the response
+		err := json.Unmarshal(bodyBytes, &model)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(model.Error) != 0 { // Kraken reports failures in the error list of a successful response
+			return nil, nil, errors.New(strings.Join(model.Error, ", "))
+		}
+
+		for _, book := range model.Result { // The result is keyed by the Kraken pair name, e.g. "XXBTZUSD"
+			return book.Asks, book.Bids, nil
+		}
+
+		return nil, nil, nil
+	}
+
+	// Function to parse the last traded price from the Kraken ticker response
+	krakenTickerJsonParse = func(bodyBytes []byte) (float64, error) {
+		var model models.KrakenTickerJSONResponse
+
+		if err := json.Unmarshal(bodyBytes, &model); err != nil {
+			return 0, err
+		}
+
+		if len(model.Error) != 0 { // Kraken reports failures in the error list of a successful response
+			return 0, errors.New(strings.Join(model.Error, ", "))
+		}
+
+		for _, ticker := range model.Result { // The result is keyed by the Kraken pair name, e.g. "XXBTZUSD"
+			if len(ticker.C) == 0 {
+				break
+			}
+
+			return parseLastPrice(ticker.C[0])
+		}
+
+		return 0, errNoLastPrice
+	}
+
+	// Function to format Kraken API URLs with the trading pair
+	krakenUrlFormatter = func(url, pair string) string {
+		assets := strings.Split(pair, "/") // Split the pair into the base and quote assets
+
+		for i, asset := range assets {
+			if krakenName, ok := krakenAssetNames[asset]; ok { // Use the Kraken name of the asset, e.g. "BTC" -> "XBT"
+				assets[i] = krakenName
+			}
+		}
+
+		pairFormatted := strings.Join(assets, "")                       // Join the assets without a separator
+		replacer := strings.NewReplacer("pair=", "pair="+pairFormatted) // Replace "pair=" in the URL with the formatted pair
+
+		return replacer.Replace(url) // Return the formatted URL
+	}
+
+	// Function to parse exchange pairs from Kraken API response
+	krakenExchangePairsJsonParse = func(exchangeName string, bodyBytes []byte) ([]models.ExchangePairs, error) {
+		var model models.KrakenPairsJSONResponse
+
+		// Unmarshal the response body into jsonData to inspect the response
+		err := json.Unmarshal(bodyBytes, &model)
+		if err != nil || len(model.Error) != 0 {
+			return []models.ExchangePairs{}, errUnmarshal("exchange pairs", exchangeName) // Return error if unmarshalling fails
+		}
+
+		var exchangePairsSlice []models.ExchangePairs // Slice to hold parsed exchange pairs
+
+		for _, pairData := range model.Result { // Iterate over all pairs keyed by their Kraken names
+			if pairData.Wsname == "" { // Skip dark pool pairs which have no websocket name
+				continue
+			}
+
+			assets := strings.Split(pairData.Wsname, "/") // Websocket name holds the readable pair, e.g. "XBT/USD"
+
+			for i, asset := range assets {
+				if commonName, ok := krakenCommonAssetNames[asset]; ok { // Use the common name of the asset, e.g. "XBT" -> "BTC"
+					assets[i] = commonName
+				}
+			}
+
+			exchangePairsSlice = append(exchangePairsSlice, models.ExchangePairs{
+				Pair:     strings.Join(assets, "/"), // Construct pair string
+				Exchange: exchangeName,              // Set exchange name
+			})
+		}
+
+		sort.Slice(exchangePairsSlice, func(i, j int) bool { // Keep the order stable since the result is a JSON object
+			return exchangePairsSlice[i].Pair < exchangePairsSlice[j].Pair
+		})
+
+		return exchangePairsSlice, nil // Return the slice of exchange pairs
+	}
+)
+
+// init registers the Kraken exchanges, so they are created and started by InitAllExchanges.
+func init() {
+	RegisterExchange("kraken", NewKraken)
+}
+
+// NewKraken initializes instances of different Kraken exchanges.
+//
+// This function creates and returns a slice of Exchange instances for various Kraken exchanges,
+// currently the Spot exchange only. It uses the provided user service, user pairs service,
+// HTTP request service, and found volume service to set up each exchange's data.
+//
+// Parameters:
+//   - deps: The services and the settings shared by all exchanges, see Dependencies.
+//
+// Returns:
+//   - []Exchange: A slice containing instances of different Kraken exchanges.
+func NewKraken(deps Dependencies) []Exchange {
+	var krakens []Exchange // Slice to hold instances of different Kraken exchanges
+	initFunctions := []func(exchangesData *ExchangeData) *ExchangeData{
+		setKrakenSpotData,
+	}
+
+	for _, function := range initFunctions {
+		exchangeData := setKrakenOverallData(deps)
+		exchangeData = function(exchangeData)
+		exchangeData.applySettings(deps) // Apply the settings configured for the exchange
+
+		krakens = append(krakens, exchangeData)
+	}
+
+	return krakens // Return the slice of Kraken exchanges
+}
+
+// setKrakenOverallData initializes and sets up overall data for all Kraken exchanges.
+//
+// This function creates an instance of the exchange struct and populates it with the necessary services,
+// models, and configurations required for interacting with Kraken exchanges. It prepares the exchange
+// with settings for handling trading pairs, order books, and request formatting.
+//
+// Parameters:
+//   - deps: The services shared by all exchanges, see Dependencies.
+//
+// Returns:
+//   - *exchange: A pointer to the initialized exchange struct, ready for use in API interactions.
+func setKrakenOverallData(deps Dependencies) *ExchangeData {
+	krakenExchangesData := ExchangeData{
+		userService:            deps.UserService,
+		userPairsService:       deps.UserPairsService,
+		httpRequestService:     deps.HttpRequestService,
+		foundVolumesService:    deps.FoundVolumesService,
+		logger:                 deps.Logger,
+		pairsJsonModel:         krakenPairsJsonModel,             // Set pairs JSON model for exchanges
+		orderbookJsonModel:     krakenOrderbookJsonModel,         // Set orderbook JSON model for exchanges
+		urlFormatter:           krakenUrlFormatter,               // Set URL formatter function for exchanges
+		timeBetweenRequests:    krakenTimeBetweenRequests,        // Set time between requests for exchanges
+		orderbookService:       krakenOrderbookService,           // Assign order book service instance to exchanges data
+		pairsSubscribed:        cmap.New[bool](),                 // Initialize subscribed pairs list as empty
+		allPairsOfExchange:     cmap.New[models.ExchangePairs](), // Initialize concurrent map for all pairs of the exchange
+		orderbookJsonParse:     krakenOrderbookJsonParse,         // Set order book JSON parsing function for exchanges
+		tickerJsonParse:        krakenTickerJsonParse,
+		lastPrices:             cmap.New[lastPrice](),
+		exchangePairsJsonParse: krakenExchangePairsJsonParse, // Set exchange pairs JSON parsing function for exchanges
+	}
+
+	return &krakenExchangesData
+}
+
+// setKrakenSpotData sets up data specific to the Kraken Spot exchange.
+//
+// This function configures the exchange struct with settings specific to the Kraken Spot exchange,
+// including URLs for API calls and initializing necessary fields.
+//
+// Parameters:
+//   - exchangesData: A pointer to the exchange struct to be configured.
+//
+// Returns:
+//   - *exchange: A pointer to the updated exchange struct.
+func setKrakenSpotData(exchangesData *ExchangeData) *ExchangeData {
+	exchangesData.exchangeName = "kraken_spot"                                                        // Set the name of the exchange to "krakenSpot"
+	exchangesData.pairsUrlForGetRequest = "https://api.kraken.com/0/public/AssetPairs"                // URL for getting pairs information
+	exchangesData.orderbookUrlForGetRequest = "https://api.kraken.com/0/public/Depth?pair=&count=100" // URL for getting order book data
+	exchangesData.tickerUrlForGetRequest = "https://api.kraken.com/0/public/Ticker?pair="             // URL for getting the last traded price
+
+	return exchangesData // Return updated exchanges data
+}
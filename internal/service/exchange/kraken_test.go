@@ -0,0 +1,189 @@
+package exchange
+
+import (
+	"testing"
+
+	"cvs/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestKrakenExchangePairsJsonParse tests parsing of the Kraken asset pairs response into exchange pairs.
+func TestKrakenExchangePairsJsonParse(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name          string                 // Name of the test case
+		body          string                 // Response body of the asset pairs request
+		expectedPairs []models.ExchangePairs // Pairs expected to be parsed
+		expectErr     bool                   // Expected outcome: true if an error is expected
+	}{
+		{
+			name: "Asset pairs",
+			body: `{
+				"error": [],
+				"result": {
+					"XXBTZUSD": {
+						"altname": "XBTUSD",
+						"wsname": "XBT/USD",
+						"aclass_base": "currency",
+						"base": "XXBT",
+						"aclass_quote": "currency",
+						"quote": "ZUSD",
+						"pair_decimals": 1,
+						"cost_decimals": 5,
+						"lot_decimals": 8,
+						"lot_multiplier": 1,
+						"ordermin": "0.0001",
+						"costmin": "0.5",
+						"tick_size": "0.1",
+						"status": "online"
+					},
+					"XETHXXBT": {
+						"altname": "ETHXBT",
+						"wsname": "ETH/XBT",
+						"aclass_base": "currency",
+						"base": "XETH",
+						"aclass_quote": "currency",
+						"quote": "XXBT",
+						"pair_decimals": 5,
+						"status": "online"
+					},
+					"XDGUSD": {
+						"altname": "XDGUSD",
+						"wsname": "XDG/USD",
+						"base": "XXDG",
+						"quote": "ZUSD",
+						"status": "online"
+					},
+					"XXBTZUSD.d": {
+						"altname": "XBTUSD.d",
+						"base": "XXBT",
+						"quote": "ZUSD"
+					}
+				}
+			}`,
+			expectedPairs: []models.ExchangePairs{
+				{Pair: "BTC/USD", Exchange: "kraken_spot"},
+				{Pair: "DOGE/USD", Exchange: "kraken_spot"},
+				{Pair: "ETH/BTC", Exchange: "kraken_spot"},
+			},
+		},
+		{
+			name:      "Error response",
+			body:      `{"error": ["EGeneral:Too many requests"]}`,
+			expectErr: true,
+		},
+		{
+			name:      "Invalid JSON",
+			body:      `<html>502 Bad Gateway</html>`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			pairs, err := krakenExchangePairsJsonParse("kraken_spot", []byte(tc.body))
+
+			if tc.expectErr {
+				assert.Error(t, err)
+				assert.Empty(t, pairs)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPairs, pairs)
+		})
+	}
+}
+
+// TestKrakenOrderbookJsonParse tests parsing of the Kraken depth response into asks and bids.
+func TestKrakenOrderbookJsonParse(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name         string          // Name of the test case
+		body         string          // Response body of the depth request
+		expectedAsks [][]interface{} // Asks expected to be parsed
+		expectedBids [][]interface{} // Bids expected to be parsed
+		expectErr    bool            // Expected outcome: true if an error is expected
+	}{
+		{
+			name: "Depth",
+			body: `{
+				"error": [],
+				"result": {
+					"XXBTZUSD": {
+						"asks": [["67010.10000", "0.512", 1716902543], ["67011.00000", "1.000", 1716902541]],
+						"bids": [["67010.00000", "2.301", 1716902544]]
+					}
+				}
+			}`,
+			expectedAsks: [][]interface{}{{"67010.10000", "0.512", float64(1716902543)}, {"67011.00000", "1.000", float64(1716902541)}},
+			expectedBids: [][]interface{}{{"67010.00000", "2.301", float64(1716902544)}},
+		},
+		{
+			name:      "Unknown pair",
+			body:      `{"error": ["EQuery:Unknown asset pair"]}`,
+			expectErr: true,
+		},
+		{
+			name:      "Invalid JSON",
+			body:      `<html>502 Bad Gateway</html>`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			asks, bids, err := krakenOrderbookJsonParse([]byte(tc.body))
+
+			if tc.expectErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedAsks, asks)
+			assert.Equal(t, tc.expectedBids, bids)
+		})
+	}
+}
+
+// TestKrakenUrlFormatter tests that the pair is inserted into the URL using the Kraken asset names.
+func TestKrakenUrlFormatter(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	const url = "https://api.kraken.com/0/public/Depth?pair=&count=100"
+
+	tests := []struct {
+		name        string // Name of the test case
+		pair        string // Trading pair passed to the formatter
+		expectedUrl string // URL expected to be formatted
+	}{
+		{name: "Bitcoin", pair: "BTC/USD", expectedUrl: "https://api.kraken.com/0/public/Depth?pair=XBTUSD&count=100"},
+		{name: "Dogecoin", pair: "DOGE/USD", expectedUrl: "https://api.kraken.com/0/public/Depth?pair=XDGUSD&count=100"},
+		{name: "Bitcoin quote", pair: "ETH/BTC", expectedUrl: "https://api.kraken.com/0/public/Depth?pair=ETHXBT&count=100"},
+		{name: "Common names", pair: "SOL/EUR", expectedUrl: "https://api.kraken.com/0/public/Depth?pair=SOLEUR&count=100"},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			assert.Equal(t, tc.expectedUrl, krakenUrlFormatter(url, tc.pair))
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package exchange
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"cvs/internal/models"
+	"cvs/internal/service/orderbook"
+
+	"github.com/goccy/go-json"
+	cmap "github.com/orcaman/concurrent-map/v2"
+)
+
+// Overall data for all sections of the OKX exchange
+var (
+	okxTimeBetweenRequests = 3 * time.Second                   // Time interval between requests to the OKX API
+	okxPairsJsonModel      = models.OkxPairsJSONResponse{}     // Model for OKX pairs JSON response
+	okxOrderbookJsonModel  = models.OkxOrderbookJSONResponse{} // Model for OKX order book JSON response
+	okxOrderbookService    = orderbook.NewOrderbook()          // Instance of the order book service for managing order data
+
+	// Function to parse order book JSON response from OKX
+	okxOrderbookJsonParse = func(bodyBytes []byte) ([][]interface{}, [][]interface{}, error) {
+		var model models.OkxOrderbookJSONResponse
+
+		// Unmarshal the response body into jsonData to inspect the response
+		err := json.Unmarshal(bodyBytes, &model)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if model.Code != "0" { // OKX reports failures in the code and the message of the envelope
+			return nil, nil, errors.New(model.Msg)
+		}
+
+		for _, book := range model.Data { // The data holds the book of the requested instrument only
+			return book.Asks, book.Bids, nil
+		}
+
+		return nil, nil, nil
+	}
+
+	// Function to parse the last traded price from the OKX ticker response
+	okxTickerJsonParse = func(bodyBytes []byte) (float64, error) {
+		var model models.OkxTickerJSONResponse
+
+		if err := json.Unmarshal(bodyBytes, &model); err != nil {
+			return 0, err
+		}
+
+		if model.Code != "0" || len(model.Data) == 0 { // OKX reports failures in the code and the message of the envelope
+			return 0, errors.New(model.Msg)
+		}
+
+		return parseLastPrice(model.Data[0].Last)
+	}
+
+	// Function to format OKX API URLs with the spot trading pair, e.g. "BTC/USDT" -> "BTC-USDT"
+	okxSpotUrlFormatter = func(url, pair string) string {
+		pairFormatted := strings.Replace(pair, "/", "-", -1)                // Separate the assets with a dash
+		replacer := strings.NewReplacer("instId=", "instId="+pairFormatted) // Replace "instId=" in the URL with the formatted pair
+
+		return replacer.Replace(url) // Return the formatted URL
+	}
+
+	// Function to format OKX API URLs with the perpetual swap pair, e.g. "BTC/USDT" -> "BTC-USDT-SWAP"
+	okxSwapUrlFormatter = func(url, pair string) string {
+		return okxSpotUrlFormatter(url, pair+"-SWAP")
+	}
+
+	// Function to parse exchange pairs from OKX API response
+	okxExchangePairsJsonParse = func(exchangeName string, bodyBytes []byte) ([]models.ExchangePairs, error) {
+		var model models.OkxPairsJSONResponse
+
+		// Unmarshal the response body into jsonData to inspect the response
+		err := json.Unmarshal(bodyBytes, &model)
+		if err != nil || model.Code != "0" {
+			return []models.ExchangePairs{}, errUnmarshal("exchange pairs", exchangeName) // Return error if unmarshalling fails
+		}
+
+		var exchangePairsSlice []models.ExchangePairs // Slice to hold parsed exchange pairs
+
+		for _, instrument := range model.Data { // Iterate over all instruments in pairs data
+			if instrument.State != "live" { // Skip instruments which can't be traded
+				continue
+			}
+
+			base, quote := instrument.BaseCcy, instrument.QuoteCcy
+			if base == "" { // Swaps have no currencies, their underlying holds the pair, e.g. "BTC-USDT"
+				base, quote, _ = strings.Cut(instrument.Uly, "-")
+			}
+
+			if base == "" || quote == "" {
+				continue
+			}
+
+			exchangePairsSlice = append(exchangePairsSlice, models.ExchangePairs{
+				Pair:     base + "/" + quote, // Construct pair string
+				Exchange: exchangeName,       // Set exchange name
+			})
+		}
+
+		return exchangePairsSlice, nil // Return the slice of exchange pairs
+	}
+)
+
+// init registers the OKX exchanges, so they are created and started by InitAllExchanges.
+func init() {
+	RegisterExchange("okx", NewOkx)
+}
+
+// NewOkx initializes instances of different OKX exchanges.
+//
+// This function creates and returns a slice of Exchange instances for various OKX exchanges,
+// including Spot and perpetual Swap exchanges. It uses the provided user service, user pairs service,
+// HTTP request service, and found volume service to set up each exchange's data.
+//
+// Parameters:
+//   - deps: The services and the settings shared by all exchanges, see Dependencies.
+//
+// Returns:
+//   - []Exchange: A slice containing instances of different OKX exchanges.
+func NewOkx(deps Dependencies) []Exchange {
+	var okxs []Exchange // Slice to hold instances of different OKX exchanges
+	initFunctions := []func(exchangesData *ExchangeData) *ExchangeData{
+		setOkxSpotData,
+		setOkxSwapData,
+	}
+
+	for _, function := range initFunctions {
+		exchangeData := setOkxOverallData(deps)
+		exchangeData = function(exchangeData)
+		exchangeData.applySettings(deps) // Apply the settings configured for the exchange
+
+		okxs = append(okxs, exchangeData)
+	}
+
+	return okxs // Return the slice of OKX exchanges
+}
+
+// setOkxOverallData initializes and sets up overall data for all OKX exchanges.
+//
+// This function creates an instance of the exchange struct and populates it with the necessary services,
+// models, and configurations required for interacting with OKX exchanges. It prepares the exchange
+// with settings for handling trading pairs and order books, the request formatting is set per section.
+//
+// Parameters:
+//   - deps: The services shared by all exchanges, see Dependencies.
+//
+// Returns:
+//   - *exchange: A pointer to the initialized exchange struct, ready for use in API interactions.
+func setOkxOverallData(deps Dependencies) *ExchangeData {
+	okxExchangesData := ExchangeData{
+		userService:            deps.UserService,
+		userPairsService:       deps.UserPairsService,
+		httpRequestService:     deps.HttpRequestService,
+		foundVolumesService:    deps.FoundVolumesService,
+		logger:                 deps.Logger,
+		pairsJsonModel:         okxPairsJsonModel,                // Set pairs JSON model for exchanges
+		orderbookJsonModel:     okxOrderbookJsonModel,            // Set orderbook JSON model for exchanges
+		timeBetweenRequests:    okxTimeBetweenRequests,           // Set time between requests for exchanges
+		orderbookService:       okxOrderbookService,              // Assign order book service instance to exchanges data
+		pairsSubscribed:        cmap.New[bool](),                 // Initialize subscribed pairs list as empty
+		allPairsOfExchange:     cmap.New[models.ExchangePairs](), // Initialize concurrent map for all pairs of the exchange
+		orderbookJsonParse:     okxOrderbookJsonParse,            // Set order book JSON parsing function for exchanges
+		tickerJsonParse:        okxTickerJsonParse,
+		lastPrices:             cmap.New[lastPrice](),
+		exchangePairsJsonParse: okxExchangePairsJsonParse, // Set exchange pairs JSON parsing function for exchanges
+	}
+
+	return &okxExchangesData
+}
+
+// setOkxSpotData sets up data specific to the OKX Spot exchange.
+//
+// This function configures the exchange struct with settings specific to the OKX Spot exchange,
+// including URLs for API calls and initializing necessary fields.
+//
+// Parameters:
+//   - exchangesData: A pointer to the exchange struct to be configured.
+//
+// Returns:
+//   - *exchange: A pointer to the updated exchange struct.
+func setOkxSpotData(exchangesData *ExchangeData) *ExchangeData {
+	exchangesData.exchangeName = "okx_spot"                                                             // Set the name of the exchange to "okxSpot"
+	exchangesData.pairsUrlForGetRequest = "https://www.okx.com/api/v5/public/instruments?instType=SPOT" // URL for getting pairs information
+	exchangesData.orderbookUrlForGetRequest = "https://www.okx.com/api/v5/market/books?instId=&sz=200"  // URL for getting order book data
+	exchangesData.tickerUrlForGetRequest = "https://www.okx.com/api/v5/market/ticker?instId="           // URL for getting the last traded price
+	exchangesData.urlFormatter = okxSpotUrlFormatter                                                    // Set URL formatter function for spot instruments
+
+	return exchangesData // Return updated exchanges data
+}
+
+// setOkxSwapData sets up data specific to the OKX perpetual Swap exchange.
+//
+// This function configures the exchange struct with settings specific to the OKX Swap exchange,
+// including URLs for API calls and initializing necessary fields.
+// The volumes of the swap order book are in contracts rather than in the base asset.
+//
+// Parameters:
+//   - exchangesData: A pointer to the exchange struct to be configured.
+//
+// Returns:
+//   - *exchange: A pointer to the updated exchange struct.
+func setOkxSwapData(exchangesData *ExchangeData) *ExchangeData {
+	exchangesData.exchangeName = "okx_swap"                                                             // Set the name of the exchange to "okxSwap"
+	exchangesData.pairsUrlForGetRequest = "https://www.okx.com/api/v5/public/instruments?instType=SWAP" // URL for getting swap pairs information
+	exchangesData.orderbookUrlForGetRequest = "https://www.okx.com/api/v5/market/books?instId=&sz=200"  // URL for getting swap order book data
+	exchangesData.tickerUrlForGetRequest = "https://www.okx.com/api/v5/market/ticker?instId="           // URL for getting the last traded swap price
+	exchangesData.urlFormatter = okxSwapUrlFormatter                                                    // Set URL formatter function for swap instruments
+
+	return exchangesData // Return updated exchanges data
+}
@@ -0,0 +1,224 @@
+package exchange
+
+import (
+	"testing"
+
+	"cvs/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOkxExchangePairsJsonParse tests parsing of the OKX instruments response into exchange pairs.
+func TestOkxExchangePairsJsonParse(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name          string                 // Name of the test case
+		exchangeName  string                 // Name of the exchange passed to the parser
+		body          string                 // Response body of the instruments request
+		expectedPairs []models.ExchangePairs // Pairs expected to be parsed
+		expectErr     bool                   // Expected outcome: true if an error is expected
+	}{
+		{
+			name:         "Spot instruments",
+			exchangeName: "okx_spot",
+			body: `{
+				"code": "0",
+				"msg": "",
+				"data": [
+					{
+						"instType": "SPOT",
+						"instId": "BTC-USDT",
+						"uly": "",
+						"instFamily": "",
+						"baseCcy": "BTC",
+						"quoteCcy": "USDT",
+						"settleCcy": "",
+						"ctVal": "",
+						"ctValCcy": "",
+						"ctType": "",
+						"tickSz": "0.1",
+						"lotSz": "0.00000001",
+						"state": "live"
+					},
+					{
+						"instType": "SPOT",
+						"instId": "ETH-BTC",
+						"baseCcy": "ETH",
+						"quoteCcy": "BTC",
+						"state": "live"
+					},
+					{
+						"instType": "SPOT",
+						"instId": "NEW-USDT",
+						"baseCcy": "NEW",
+						"quoteCcy": "USDT",
+						"state": "preopen"
+					}
+				]
+			}`,
+			expectedPairs: []models.ExchangePairs{
+				{Pair: "BTC/USDT", Exchange: "okx_spot"},
+				{Pair: "ETH/BTC", Exchange: "okx_spot"},
+			},
+		},
+		{
+			name:         "Swap instruments",
+			exchangeName: "okx_swap",
+			body: `{
+				"code": "0",
+				"msg": "",
+				"data": [
+					{
+						"instType": "SWAP",
+						"instId": "BTC-USDT-SWAP",
+						"uly": "BTC-USDT",
+						"instFamily": "BTC-USDT",
+						"baseCcy": "",
+						"quoteCcy": "",
+						"settleCcy": "USDT",
+						"ctVal": "0.01",
+						"ctValCcy": "BTC",
+						"ctType": "linear",
+						"state": "live"
+					},
+					{
+						"instType": "SWAP",
+						"instId": "ETH-USD-SWAP",
+						"uly": "ETH-USD",
+						"instFamily": "ETH-USD",
+						"settleCcy": "ETH",
+						"ctVal": "10",
+						"ctValCcy": "USD",
+						"ctType": "inverse",
+						"state": "live"
+					}
+				]
+			}`,
+			expectedPairs: []models.ExchangePairs{
+				{Pair: "BTC/USDT", Exchange: "okx_swap"},
+				{Pair: "ETH/USD", Exchange: "okx_swap"},
+			},
+		},
+		{
+			name:         "Error code",
+			exchangeName: "okx_spot",
+			body:         `{"code": "51001", "msg": "Instrument ID does not exist", "data": []}`,
+			expectErr:    true,
+		},
+		{
+			name:         "Invalid JSON",
+			exchangeName: "okx_spot",
+			body:         `<html>502 Bad Gateway</html>`,
+			expectErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			pairs, err := okxExchangePairsJsonParse(tc.exchangeName, []byte(tc.body))
+
+			if tc.expectErr {
+				assert.Error(t, err)
+				assert.Empty(t, pairs)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPairs, pairs)
+		})
+	}
+}
+
+// TestOkxOrderbookJsonParse tests parsing of the OKX books response into asks and bids.
+func TestOkxOrderbookJsonParse(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name         string          // Name of the test case
+		body         string          // Response body of the books request
+		expectedAsks [][]interface{} // Asks expected to be parsed
+		expectedBids [][]interface{} // Bids expected to be parsed
+		expectErr    bool            // Expected outcome: true if an error is expected
+	}{
+		{
+			name: "Books",
+			body: `{
+				"code": "0",
+				"msg": "",
+				"data": [
+					{
+						"asks": [["41006.8", "0.60038921", "0", "1"], ["41006.9", "1.2", "0", "3"]],
+						"bids": [["41006.3", "0.30178218", "0", "2"]],
+						"ts": "1629966436396"
+					}
+				]
+			}`,
+			expectedAsks: [][]interface{}{{"41006.8", "0.60038921", "0", "1"}, {"41006.9", "1.2", "0", "3"}},
+			expectedBids: [][]interface{}{{"41006.3", "0.30178218", "0", "2"}},
+		},
+		{
+			name:      "Error code",
+			body:      `{"code": "51001", "msg": "Instrument ID does not exist", "data": []}`,
+			expectErr: true,
+		},
+		{
+			name:      "Invalid JSON",
+			body:      `<html>502 Bad Gateway</html>`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			asks, bids, err := okxOrderbookJsonParse([]byte(tc.body))
+
+			if tc.expectErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedAsks, asks)
+			assert.Equal(t, tc.expectedBids, bids)
+		})
+	}
+}
+
+// TestOkxUrlFormatter tests that the pair is inserted into the URL as the OKX instrument ID.
+func TestOkxUrlFormatter(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	const url = "https://www.okx.com/api/v5/market/books?instId=&sz=200"
+
+	tests := []struct {
+		name        string                        // Name of the test case
+		formatter   func(url, pair string) string // Formatter of the exchange section
+		pair        string                        // Trading pair passed to the formatter
+		expectedUrl string                        // URL expected to be formatted
+	}{
+		{name: "Spot", formatter: okxSpotUrlFormatter, pair: "BTC/USDT", expectedUrl: "https://www.okx.com/api/v5/market/books?instId=BTC-USDT&sz=200"},
+		{name: "Swap", formatter: okxSwapUrlFormatter, pair: "BTC/USDT", expectedUrl: "https://www.okx.com/api/v5/market/books?instId=BTC-USDT-SWAP&sz=200"},
+		{name: "Inverse swap", formatter: okxSwapUrlFormatter, pair: "ETH/USD", expectedUrl: "https://www.okx.com/api/v5/market/books?instId=ETH-USD-SWAP&sz=200"},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			assert.Equal(t, tc.expectedUrl, tc.formatter(url, tc.pair))
+		})
+	}
+}
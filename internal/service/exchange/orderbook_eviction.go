@@ -0,0 +1,63 @@
+package exchange
+
+import (
+	"context"
+	"cvs/internal/service/logger"
+	"cvs/internal/service/orderbook"
+	"time"
+)
+
+// maxOrderbookEvictionInterval is the longest time between the sweeps removing the stale order books.
+const maxOrderbookEvictionInterval = time.Minute
+
+// orderbookServices returns the order book services shared by the sections of every exchange.
+func orderbookServices() []orderbook.Orderbook {
+	return []orderbook.Orderbook{
+		binanceOrderbookService,
+		binanceCoinmOrderbookService,
+		bybitOrderbookService,
+		krakenOrderbookService,
+		okxOrderbookService,
+		coinbaseOrderbookService,
+	}
+}
+
+// StartOrderbookEvictor starts removing the order books which weren't updated for longer than the TTL.
+//
+// The order book of a pair is removed as soon as the last section of the exchange unsubscribes from it,
+// but a request started before the unsubscription may store the book again afterwards, and the book of
+// a pair whose requests keep failing is never updated. The evictor removes such books, so they don't stay
+// in memory forever. A book of a watched pair is stored again by its next update.
+//
+// The evictor runs in its own goroutine until the context is cancelled.
+//
+// Parameters:
+//   - ctx: The context which stops the evictor when it is cancelled.
+//   - ttl: The time an order book is kept without being updated. A non-positive value disables the evictor.
+//   - logger: The logger of the number of removed order books.
+func StartOrderbookEvictor(ctx context.Context, ttl time.Duration, logger logger.Logger) {
+	if ttl <= 0 {
+		return // The books are removed only on the unsubscriptions
+	}
+
+	go func() {
+		ticker := time.NewTicker(min(ttl, maxOrderbookEvictionInterval))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				evicted := 0
+				for _, orderbookService := range orderbookServices() {
+					evicted += orderbookService.EvictStale(ttl)
+				}
+
+				if evicted > 0 {
+					logger.Debugf("Removed %d stale order books", evicted)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,47 @@
+package exchange
+
+import (
+	"context"
+	"cvs/internal/service/logger"
+	"time"
+)
+
+// StartPairsRefresher starts refreshing the pairs listed on every exchange periodically.
+//
+// The pairs are loaded once on the start of an exchange, but the exchanges list and delist pairs intraday.
+// The refresher calls GetAllPairsOfExchange of every exchange on each tick, so the new listings can be
+// watched without a restart and the delisted pairs are dropped. A failed refresh keeps the stored pairs.
+//
+// The refresher runs in its own goroutine until the context is cancelled.
+//
+// Parameters:
+//   - ctx: The context which stops the refresher when it is cancelled.
+//   - allExchangesStorage: The storage of the exchanges whose pairs are refreshed.
+//   - interval: The time between the refreshes. A non-positive value disables the refresher.
+//   - logger: The logger of the number of refreshed exchanges.
+func StartPairsRefresher(ctx context.Context, allExchangesStorage AllExchanges, interval time.Duration, logger logger.Logger) {
+	if interval <= 0 {
+		return // The pairs are loaded on the start only
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				exchanges, failed := allExchangesStorage.All(), 0
+				for _, exchange := range exchanges {
+					if err := exchange.GetAllPairsOfExchange(ctx); err != nil {
+						failed++ // The error is logged and recorded in the status of the exchange already
+					}
+				}
+
+				logger.Debugf("Refreshed the pairs of %d exchanges, %d failed", len(exchanges), failed)
+			}
+		}
+	}()
+}
@@ -0,0 +1,186 @@
+package exchange
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cvs/internal/config"
+	"cvs/internal/models"
+	"cvs/internal/service"
+	"cvs/internal/service/logger"
+)
+
+// exchangeRegistry holds the factories of the exchanges started by InitAllExchanges.
+var exchangeRegistry = newRegistry()
+
+// Dependencies holds the services and the settings shared by all exchanges, which are passed to their factories.
+type Dependencies struct {
+	UserService         service.UserService         // Service for managing user data
+	UserPairsService    service.UserPairsService    // Service for managing user pairs data
+	HttpRequestService  service.HttpRequest         // Service for making HTTP requests
+	FoundVolumesService service.FoundVolumesService // Service for managing found volumes
+	Logger              logger.Logger
+
+	// The settings keyed by exchange name apply to the exchanges present in the map only, the zero values
+	// of the other ones keep the defaults, so a dependency left unset doesn't change the exchanges.
+	RequestIntervals    map[string]time.Duration       // Time between requests to the exchange API keyed by exchange name
+	QuoteFilters        map[string]models.QuoteFilter  // Quote assets whose pairs are stored keyed by exchange name
+	VolumeSearchWorkers int                            // Maximum number of users whose volumes are searched concurrently, non-positive uses the default
+	OrderbookBatchSize  int                            // Number of pairs whose order books are fetched by one request, zero fetches every pair separately
+	MinVolumeFloor      float64                        // Volume below which the levels are ignored regardless of the user settings, zero ignores none
+	RequestHeaders      map[string]http.Header         // Headers sent with the requests to the exchange API keyed by exchange name, e.g. the API key
+	BaseUrls            map[string]string              // Base URLs replacing the production hosts of the exchange API keyed by exchange name
+	CircuitBreaker      config.CircuitBreakerConfig    // Settings of the circuit breakers pausing the requests to the failing exchanges
+	PairBlacklists      map[string][]string            // Pairs whose order books are never polled keyed by exchange name
+	ResponseBodyLimits  config.ResponseBodyLimitConfig // Maximum sizes of the response bodies read from the exchange API, non-positive uses the defaults
+	DryRun              bool                           // Whether the found volumes are only logged, neither stored nor notified about
+	EnabledExchanges    []string                       // Names of the exchanges which are started, all of them if empty
+}
+
+// Factory creates the exchanges of a single exchange, e.g. the spot and futures markets of Binance.
+type Factory func(deps Dependencies) []Exchange
+
+// registry holds the exchange factories by their names.
+type registry struct {
+	mu        sync.Mutex         // Guards the factories
+	factories map[string]Factory // Factories of the exchanges keyed by name
+}
+
+// newRegistry creates an empty registry of exchange factories.
+func newRegistry() *registry {
+	return &registry{factories: make(map[string]Factory)}
+}
+
+// RegisterExchange registers the factory of an exchange, so its exchanges are created and started by InitAllExchanges.
+// It is meant to be called from the init function of the file implementing the exchange.
+//
+// Parameters:
+//   - name: The unique name of the exchange, e.g. "binance".
+//   - factory: The function creating the exchanges from the shared dependencies.
+//
+// RegisterExchange panics if the factory is nil or an exchange with the same name is already registered,
+// so a mistake is found at startup.
+func RegisterExchange(name string, factory Factory) {
+	exchangeRegistry.register(name, factory)
+}
+
+// register adds the factory under the name, it panics on a nil factory or a duplicate name.
+func (r *registry) register(name string, factory Factory) {
+	if factory == nil {
+		panic("exchange factory is nil: " + name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exist := r.factories[name]; exist {
+		panic("exchange factory is already registered: " + name)
+	}
+
+	r.factories[name] = factory
+}
+
+// names returns the names of the registered exchanges sorted alphabetically.
+func (r *registry) names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// startAll creates the exchanges of every registered factory, adds the enabled ones to the storage and starts their work.
+// The exchanges are created in the order of their names, and the method returns once all of them have started.
+//
+// Before any exchange starts polling, the subscribed pairs of all of them are backfilled from the database,
+// so the startup doesn't depend on which exchange initializes first and no pair stored at startup is missed
+// by the first poll. The exchanges are added to the storage beforehand, so the pairs added meanwhile are kept.
+//
+// An exchange is enabled if deps.EnabledExchanges is empty or lists either its name, e.g. "bybit_spot",
+// or the name of its factory, e.g. "bybit" for all markets of Bybit. The exchanges which aren't enabled
+// are created but never started, so they don't make any request.
+//
+// startAll panics if deps.EnabledExchanges lists a name which is neither an exchange nor a factory,
+// so a typo in the configuration fails at startup instead of silently disabling the exchange.
+func (r *registry) startAll(ctx context.Context, allExchangesStorage AllExchanges, deps Dependencies) {
+	enabled := make(map[string]bool, len(deps.EnabledExchanges)) // Enabled names, whether each of them is known
+	for _, name := range deps.EnabledExchanges {
+		enabled[strings.ToLower(strings.TrimSpace(name))] = false
+	}
+
+	var exchanges []Exchange // Exchanges to start
+
+	for _, name := range r.names() {
+		r.mu.Lock()
+		factory := r.factories[name]
+		r.mu.Unlock()
+
+		_, factoryEnabled := enabled[name]
+		if factoryEnabled {
+			enabled[name] = true
+		}
+
+		for _, exchange := range factory(deps) {
+			_, exchangeEnabled := enabled[exchange.ExchangeName()]
+			if exchangeEnabled {
+				enabled[exchange.ExchangeName()] = true
+			}
+
+			if len(enabled) == 0 || factoryEnabled || exchangeEnabled {
+				exchanges = append(exchanges, exchange)
+			}
+		}
+	}
+
+	var unknown []string // Enabled names matching no exchange
+	for name, known := range enabled {
+		if !known {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) != 0 {
+		slices.Sort(unknown)
+
+		panic("unknown exchanges in the enabled exchanges: " + strings.Join(unknown, ", "))
+	}
+
+	var backfill sync.WaitGroup
+
+	for _, exchange := range exchanges {
+		allExchangesStorage.Add(exchange)
+
+		backfill.Add(1)
+		go func(exchange Exchange) {
+			defer backfill.Done()
+
+			exchange.FillPairsSubscribedStorage(ctx)
+		}(exchange)
+	}
+
+	backfill.Wait() // Wait for the subscriptions of all exchanges to be loaded before any of them polls
+
+	var wg sync.WaitGroup
+
+	for _, exchange := range exchanges {
+		wg.Add(1)
+		go func(exchange Exchange) {
+			defer wg.Done()
+
+			exchange.StartWork(ctx)
+		}(exchange)
+	}
+
+	wg.Wait() // Wait for all exchanges to start
+}
@@ -0,0 +1,198 @@
+package exchange
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeExchange is an exchange recording whether its subscriptions were loaded and its work was started,
+// the other methods aren't implemented.
+type fakeExchange struct {
+	Exchange // Panics if a method which isn't overridden is called
+
+	name    string
+	filled  atomic.Bool
+	started atomic.Bool
+	onStart func() // Called when the work is started, nil if nothing is checked
+}
+
+func (f *fakeExchange) ExchangeName() string {
+	return f.name
+}
+
+func (f *fakeExchange) FillPairsSubscribedStorage(ctx context.Context) {
+	f.filled.Store(true)
+}
+
+func (f *fakeExchange) StartWork(ctx context.Context) {
+	if f.onStart != nil {
+		f.onStart()
+	}
+
+	f.started.Store(true)
+}
+
+// TestRegistryStartAll tests that the exchanges of a registered factory are created with the shared dependencies,
+// added to the storage and started.
+func TestRegistryStartAll(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	spot := &fakeExchange{name: "fake_spot"}
+	futures := &fakeExchange{name: "fake_futures"}
+
+	var received Dependencies // Dependencies the factory was called with
+
+	exchanges := newRegistry()
+	exchanges.register("fake", func(deps Dependencies) []Exchange {
+		received = deps
+
+		return []Exchange{spot, futures}
+	})
+
+	allExchangesStorage := NewAllExchangesService(nil)
+	exchanges.startAll(context.Background(), allExchangesStorage, Dependencies{VolumeSearchWorkers: 4, MinVolumeFloor: 0.5})
+
+	assert.Equal(t, Dependencies{VolumeSearchWorkers: 4, MinVolumeFloor: 0.5}, received)
+	assert.True(t, spot.started.Load())
+	assert.True(t, futures.started.Load())
+
+	for _, name := range []string{"fake_spot", "fake_futures"} {
+		_, exist := allExchangesStorage.Get(name)
+		assert.True(t, exist, "%s must be stored", name)
+	}
+}
+
+// TestRegistryBackfillsBeforeStart tests that the subscriptions of all exchanges are loaded
+// before the work of any of them is started.
+func TestRegistryBackfillsBeforeStart(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	spot := &fakeExchange{name: "fake_spot"}
+	futures := &fakeExchange{name: "fake_futures"}
+
+	var startedEarly atomic.Bool // Whether an exchange started before the subscriptions of all of them were loaded
+
+	for _, exchange := range []*fakeExchange{spot, futures} {
+		exchange.onStart = func() {
+			if !spot.filled.Load() || !futures.filled.Load() {
+				startedEarly.Store(true)
+			}
+		}
+	}
+
+	exchanges := newRegistry()
+	exchanges.register("fake", func(deps Dependencies) []Exchange {
+		return []Exchange{spot, futures}
+	})
+
+	exchanges.startAll(context.Background(), NewAllExchangesService(nil), Dependencies{})
+
+	assert.True(t, spot.started.Load())
+	assert.True(t, futures.started.Load())
+	assert.False(t, startedEarly.Load())
+}
+
+// TestRegistryStartEnabled tests that only the enabled exchanges are stored and started, that the name
+// of a factory enables all of its exchanges and that an unknown name is rejected.
+func TestRegistryStartEnabled(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name     string   // Name of the test case
+		enabled  []string // Enabled exchanges
+		expected []string // Names of the exchanges expected to be started
+		panics   bool     // Whether the enabled exchanges are expected to be rejected
+	}{
+		{
+			name:     "All Exchanges",
+			expected: []string{"binance_spot", "binance_futures", "bybit_spot", "bybit_futures"},
+		},
+		{
+			name:     "Single Exchange",
+			enabled:  []string{"bybit_spot"},
+			expected: []string{"bybit_spot"}, // Binance isn't started
+		},
+		{
+			name:     "Factory Name",
+			enabled:  []string{" Binance "},
+			expected: []string{"binance_spot", "binance_futures"},
+		},
+		{
+			name:    "Unknown Exchange",
+			enabled: []string{"bybit_spot", "kucoin_spot"},
+			panics:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			created := make(map[string]*fakeExchange) // Exchanges created by the factories by name
+			fakeFactory := func(names ...string) Factory {
+				return func(deps Dependencies) []Exchange {
+					var exchanges []Exchange
+					for _, name := range names {
+						created[name] = &fakeExchange{name: name}
+						exchanges = append(exchanges, created[name])
+					}
+
+					return exchanges
+				}
+			}
+
+			exchanges := newRegistry()
+			exchanges.register("binance", fakeFactory("binance_spot", "binance_futures"))
+			exchanges.register("bybit", fakeFactory("bybit_spot", "bybit_futures"))
+
+			allExchangesStorage := NewAllExchangesService(nil)
+			start := func() {
+				exchanges.startAll(context.Background(), allExchangesStorage, Dependencies{EnabledExchanges: tc.enabled})
+			}
+
+			if tc.panics {
+				assert.PanicsWithValue(t, "unknown exchanges in the enabled exchanges: kucoin_spot", start)
+				assert.Empty(t, allExchangesStorage.All()) // Nothing is started with an invalid configuration
+
+				return
+			}
+
+			start()
+
+			for name, exchange := range created {
+				_, stored := allExchangesStorage.Get(name)
+				expected := slices.Contains(tc.expected, name)
+
+				assert.Equal(t, expected, exchange.started.Load(), "%s started", name)
+				assert.Equal(t, expected, stored, "%s stored", name)
+			}
+		})
+	}
+}
+
+// TestRegistryRegister tests that a duplicate name or a nil factory is rejected.
+func TestRegistryRegister(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	factory := func(deps Dependencies) []Exchange { return nil }
+
+	exchanges := newRegistry()
+	exchanges.register("fake", factory)
+
+	assert.Panics(t, func() { exchanges.register("fake", factory) })
+	assert.Panics(t, func() { exchanges.register("other", nil) })
+	assert.Equal(t, []string{"fake"}, exchanges.names())
+}
+
+// TestExchangesRegistered tests that every exchange implemented by the package registers its factory.
+func TestExchangesRegistered(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	assert.Equal(t, []string{"binance", "bybit", "coinbase", "kraken", "okx"}, exchangeRegistry.names())
+}
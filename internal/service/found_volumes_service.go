@@ -1,8 +1,15 @@
 package service
 
 import (
+	"context"
 	"cvs/internal/models"
+	"cvs/internal/repository"
+	"cvs/internal/service/logger"
+	"math"
+	"sort"
 	"strconv"
+	"sync"
+	"time"
 
 	cmap "github.com/orcaman/concurrent-map/v2"
 )
@@ -10,24 +17,78 @@ import (
 // FoundVolumesService defines the interface for managing found volumes.
 // This interface includes methods for updating or inserting found volume data and retrieving all found volumes for a user.
 type FoundVolumesService interface {
-	UpsertFoundVolume(userData models.UserPairs, foundVolume models.FoundVolume) // Method to update or insert found volume data
-	GetAllFoundVolume(userID int) ([]models.FoundVolume, error)                  // Method to retrieve all found volumes for a user
-	DeleteFoundVolume(userPairData models.UserPairs)                             // Method to delete found volume data
+	UpsertFoundVolume(ctx context.Context, userData models.UserPairs, foundVolume models.FoundVolume) (bool, error) // Method to update or insert found volume data
+	GetAllFoundVolume(userID int, filter models.FoundVolumesFilter) ([]models.FoundVolume, error)                   // Method to retrieve the found volumes of a user matching the filter
+	DeleteFoundVolume(ctx context.Context, userPairData models.UserPairs) error                                     // Method to delete found volume data
+	GetFoundVolumesFromDB(ctx context.Context, userIDs []int) error                                                 // Method to load found volumes of users from the database into memory
+	Subscribe(userID int) (<-chan models.FoundVolume, func())                                                       // Method to subscribe to the found volume events of a user
+	StartExpirySweeper(ctx context.Context, ttl, interval time.Duration, logger logger.Logger)                      // Method to start removing the found volumes which weren't found again for too long
 }
 
+// foundVolumesSubscriberBuffer is the number of found volumes buffered for a subscriber. When the buffer
+// of a slow subscriber is full, new found volumes are dropped for it instead of blocking the scanner.
+const foundVolumesSubscriberBuffer = 64
+
+// foundVolumesAllUsersSubscriberBuffer is the number of found volumes buffered for a subscriber of all users,
+// which receives the events of every user and so needs a larger buffer.
+const foundVolumesAllUsersSubscriberBuffer = 1024
+
+// AllUsers is the user ID subscribing to the found volume events of every user, e.g. to deliver notifications.
+// User IDs start from 1, so it doesn't collide with a real user.
+const AllUsers = 0
+
+// foundVolumeKeyDelimiter separates the pair, exchange and side in the key of a found volume.
+const foundVolumeKeyDelimiter = "|"
+
+// defaultFoundVolumeTTL is the time a found volume is kept without being found again when no TTL is configured.
+const defaultFoundVolumeTTL = 10 * time.Minute
+
+// defaultFoundVolumeSweepInterval is the time between the sweeps of expired found volumes when no interval is configured.
+const defaultFoundVolumeSweepInterval = time.Minute
+
+// foundVolumeChangeRatio is the relative change of the volume at the same price level above which
+// a found volume is treated as changed. Smaller changes are the usual order book noise.
+const foundVolumeChangeRatio = 0.05
+
 // foundVolumesService is a concrete implementation of FoundVolumesService.
-// It holds a concurrent map to store found volumes data.
+// It holds a concurrent map which serves as a hot cache of the found volumes stored in the database.
 type foundVolumesService struct {
 	//first key - userID
-	// second key - pair + exchange + side
-	foundVolumesData cmap.ConcurrentMap[string, cmap.ConcurrentMap[string, models.FoundVolume]]
+	// second key - foundVolumeKey of pair, exchange and side
+	foundVolumesData       cmap.ConcurrentMap[string, cmap.ConcurrentMap[string, models.FoundVolume]]
+	foundVolumesRepository repository.FoundVolumesRepository // Repository for persisting found volumes
+	userSettingsService    UserSettingsService               // Service deciding whether the user is notified about a found volume
+	logger                 logger.Logger                     // Logger of the events dropped for slow subscribers
+	contextTimeout         time.Duration                     // Timeout duration for context
+
+	subscribersMu sync.RWMutex                                 // Guards subscribers and the channels they hold
+	subscribers   map[int]map[chan models.FoundVolume]struct{} // Channels receiving new found volumes by user ID
 }
 
 // NewFoundVolumesService creates a new instance of foundVolumesService.
 // It initializes the concurrent map for storing found volumes data.
-func NewFoundVolumesService() FoundVolumesService {
+//
+// Parameters:
+//   - foundVolumesRepository: Repository for persisting found volumes data.
+//   - userSettingsService: Service consulted before the subscribers of a user are notified about a found volume.
+//   - logger: The logger of the found volume events dropped for slow subscribers.
+//   - timeout: Duration to set context timeout for operations.
+//
+// Returns:
+//   - An instance of FoundVolumesService.
+func NewFoundVolumesService(
+	foundVolumesRepository repository.FoundVolumesRepository,
+	userSettingsService UserSettingsService,
+	logger logger.Logger,
+	timeout time.Duration,
+) FoundVolumesService {
 	return &foundVolumesService{
-		foundVolumesData: cmap.New[cmap.ConcurrentMap[string, models.FoundVolume]](),
+		foundVolumesData:       cmap.New[cmap.ConcurrentMap[string, models.FoundVolume]](),
+		foundVolumesRepository: foundVolumesRepository,
+		userSettingsService:    userSettingsService,
+		logger:                 logger,
+		contextTimeout:         timeout,
+		subscribers:            make(map[int]map[chan models.FoundVolume]struct{}),
 	}
 }
 
@@ -36,74 +97,142 @@ func NewFoundVolumesService() FoundVolumesService {
 // This method retrieves the cached found volumes data for a specific user ID and either inserts
 // or updates the found volume identified by a unique key composed of the pair, exchange, and side attributes.
 // If the price of the found volume is zero, it will remove the existing entry instead of updating it.
+// A found volume of the side of the order book the user pair doesn't watch is removed the same way,
+// so the volumes found before the user narrowed the side down don't linger.
+// The change is written through to the database, so the found volumes survive a restart. The repository may buffer
+// the write and flush it along with the writes of other found volumes, see repository.BatchedFoundVolumesRepository.
+//
+// The same level is found again on every scan, so only a found volume which first appears or materially
+// changes is treated as new. A found volume materially changes if its price differs or its volume changes
+// by more than foundVolumeChangeRatio. A new found volume is published to the subscribers of the user
+// and of all users, e.g. the websocket and the notifications, unless the settings of the user opt out of it,
+// e.g. it is below their minimum volume to notify about.
 //
 // Parameters:
+//   - ctx: The context for managing request lifetime.
 //   - userPairData: A models.UserPairs struct containing information about the user and their trading pair.
 //   - foundVolume: A models.FoundVolume struct representing the volume data to be inserted or updated.
 //
-// This method does not return any values and does not produce errors. If there is no existing data for
-// the user ID, it creates a new entry. If the found volume's price is zero, it removes any existing
-// entry associated with that unique key.
-func (fvs *foundVolumesService) UpsertFoundVolume(userPairData models.UserPairs, foundVolume models.FoundVolume) {
-	userID := strconv.Itoa(userPairData.UserID)                                        // Convert UserID to string for use as a key
-	foundVolumeUniqueKey := foundVolume.Pair + foundVolume.Exchange + foundVolume.Side // Create a unique key for the found volume
+// Returns:
+//   - true if the found volume is new or materially changed, false if it was already stored or removed.
+//   - An error if writing the change to the database fails; the in-memory data is updated regardless.
+func (fvs *foundVolumesService) UpsertFoundVolume(ctx context.Context, userPairData models.UserPairs, foundVolume models.FoundVolume) (bool, error) {
+	if !userPairData.WatchesSide(foundVolume.Side) {
+		foundVolume = models.FoundVolume{
+			Pair:     foundVolume.Pair,
+			Exchange: foundVolume.Exchange,
+			Side:     foundVolume.Side,
+		} // The side isn't recorded for the user, so it is treated as if nothing was found on it
+	}
+
+	userID := strconv.Itoa(userPairData.UserID)                                                      // Convert UserID to string for use as a key
+	foundVolumeUniqueKey := foundVolumeKey(foundVolume.Pair, foundVolume.Exchange, foundVolume.Side) // Create a unique key for the found volume
+
+	isNew := foundVolume.Price != 0 // A zero price means nothing was found, so there is nothing to notify about
 
 	// Check if user data exists
 	userFoundVolumesData, ok := fvs.foundVolumesData.Get(userID) // Retrieve cached data for the user ID
 	if !ok {
 		var foundVolumesMap = cmap.New[models.FoundVolume]() // Create a new concurrent map for found volumes
 
-		foundVolumesMap.Set(foundVolumeUniqueKey, foundVolume) // Insert found volume data
-		fvs.foundVolumesData.Set(userID, foundVolumesMap)      // Store the new map in foundVolumesData
+		if foundVolume.Price != 0 { // A zero price means nothing was found, so there is nothing to store
+			foundVolumesMap.Set(foundVolumeUniqueKey, foundVolume) // Insert found volume data
+		}
+		fvs.foundVolumesData.Set(userID, foundVolumesMap) // Store the new map in foundVolumesData
+	} else {
+		if foundVolume.Price != 0 {
+			if stored, exist := userFoundVolumesData.Get(foundVolumeUniqueKey); exist {
+				isNew = foundVolumeChanged(stored, foundVolume) // The level was already found, notify only about a material change
+			}
+
+			userFoundVolumesData.Set(foundVolumeUniqueKey, foundVolume) // Update existing volume data
+		} else {
+			userFoundVolumesData.Remove(foundVolumeUniqueKey) // Remove entry if price is zero
+		}
 
-		return // Exit after inserting new data
+		fvs.foundVolumesData.Set(userID, userFoundVolumesData) // Update stored data for the user
 	}
 
-	if foundVolume.Price != 0 {
-		userFoundVolumesData.Set(foundVolumeUniqueKey, foundVolume) // Update existing volume data
-	} else {
-		userFoundVolumesData.Remove(foundVolumeUniqueKey) // Remove entry if price is zero
+	if isNew && fvs.userSettingsService.ShouldNotify(ctx, userPairData.UserID, foundVolume) {
+		fvs.publish(userPairData.UserID, foundVolume) // Notify the subscribers of the user and of all users
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fvs.contextTimeout) // Set up context with timeout
+	defer cancel()                                              // Ensure cancellation of context when done
+
+	if foundVolume.Price == 0 { // Nothing was found, so drop the stored volume
+		return isNew, fvs.foundVolumesRepository.Delete(ctx, userPairData.UserID, foundVolume)
 	}
 
-	fvs.foundVolumesData.Set(userID, userFoundVolumesData) // Update stored data for the user
+	return isNew, fvs.foundVolumesRepository.Upsert(ctx, userPairData.UserID, foundVolume)
 }
 
 // DeleteFoundVolume removes a specified found volume for a user from the stored data.
 //
 // This method retrieves the cached found volumes data for a specific user ID and attempts to remove
 // the found volume identified by a unique key composed of the pair and exchange attributes.
-// If the user does not have any found volumes stored, no action is taken.
+// Both sides of the found volume are deleted from the database as well. The cache mirrors the database,
+// so nothing is done if the user has no found volumes stored.
 //
 // Parameters:
+//   - ctx: The context for managing request lifetime.
 //   - userPairData: A models.UserPairs struct containing information about the user and their trading pair.
-//   - foundVolume: A models.FoundVolume struct representing the volume data to be deleted.
 //
-// This method does not return any values and does not produce errors. However, if the user ID is not found,
-// it will simply exit without making any changes.
-func (fvs *foundVolumesService) DeleteFoundVolume(userPairData models.UserPairs) {
-	userID := strconv.Itoa(userPairData.UserID)            // Convert UserID to string for use as a key
-	uniqueKey := userPairData.Pair + userPairData.Exchange // Create a unique key for the found volume
-	asksUniqueKey := uniqueKey + "asks"                    // Unique key for asks
-	bidsUniqueKey := uniqueKey + "bids"                    // Unique key for bids
+// Returns:
+//   - An error if deleting the found volumes from the database fails.
+func (fvs *foundVolumesService) DeleteFoundVolume(ctx context.Context, userPairData models.UserPairs) error {
+	userID := strconv.Itoa(userPairData.UserID)                                       // Convert UserID to string for use as a key
+	asksUniqueKey := foundVolumeKey(userPairData.Pair, userPairData.Exchange, "asks") // Unique key for asks
+	bidsUniqueKey := foundVolumeKey(userPairData.Pair, userPairData.Exchange, "bids") // Unique key for bids
 
 	// Retrieve cached data for the user ID
-	userFoundVolumesData, _ := fvs.foundVolumesData.Get(userID)
+	userFoundVolumesData, ok := fvs.foundVolumesData.Get(userID)
+	if !ok {
+		return nil // The user has no found volumes, so there is nothing to delete
+	}
 
 	// Remove both asks and bids using their unique keys
 	userFoundVolumesData.Remove(asksUniqueKey)
 	userFoundVolumesData.Remove(bidsUniqueKey)
+
+	ctx, cancel := context.WithTimeout(ctx, fvs.contextTimeout) // Set up context with timeout
+	defer cancel()                                              // Ensure cancellation of context when done
+
+	for _, side := range []string{"asks", "bids"} { // Delete both sides of the found volume
+		foundVolume := models.FoundVolume{
+			Pair:     userPairData.Pair,
+			Exchange: userPairData.Exchange,
+			Side:     side,
+		}
+
+		if err := fvs.foundVolumesRepository.Delete(ctx, userPairData.UserID, foundVolume); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-// GetAllFoundVolume retrieves all found volumes for a given user ID.
+// GetAllFoundVolume retrieves the found volumes for a given user ID which match the filter.
+//
+// The found volumes are sorted by the sort key and order of the filter, by the difference descending by default,
+// so the walls farthest from the price come first. The found volumes with equal values are sorted by
+// the time they were found, the most recent first. The sorted found volumes are paginated using
+// the limit and offset of the filter.
 //
 // Parameters:
 //   - userID: The ID of the user whose found volumes are to be retrieved.
+//   - filter: The filter and pagination settings. Empty fields don't filter.
 //
 // Returns:
 //   - A slice of FoundVolume and an error if any occurs during retrieval.
-func (fvs *foundVolumesService) GetAllFoundVolume(userID int) ([]models.FoundVolume, error) {
+func (fvs *foundVolumesService) GetAllFoundVolume(userID int, filter models.FoundVolumesFilter) ([]models.FoundVolume, error) {
 	var volumesToReturn []models.FoundVolume
 
+	if err := CheckFoundVolumesFilter(filter); err != nil {
+		return volumesToReturn, err
+	}
+
 	userFoundVolumes, ok := fvs.foundVolumesData.Get(strconv.Itoa(userID)) // Retrieve cached data for the user ID
 	if !ok {
 		err := errGettingFoundVolume // Custom error indicating failure to get found volume
@@ -112,8 +241,276 @@ func (fvs *foundVolumesService) GetAllFoundVolume(userID int) ([]models.FoundVol
 	}
 
 	for _, volume := range userFoundVolumes.Items() { // Iterate over all found volumes
+		if !foundVolumeMatchesFilter(volume, filter) {
+			continue
+		}
+
 		volumesToReturn = append(volumesToReturn, volume)
 	}
 
-	return volumesToReturn, nil // Return all found volumes retrieved
+	descending := filter.Order != "asc" // The largest values first by default
+
+	sort.Slice(volumesToReturn, func(i, j int) bool {
+		a, b := volumesToReturn[i], volumesToReturn[j]
+
+		if valueA, valueB := foundVolumeSortValue(a, filter.Sort), foundVolumeSortValue(b, filter.Sort); valueA != valueB {
+			if descending {
+				return valueA > valueB
+			}
+
+			return valueA < valueB
+		}
+
+		if !a.VolumeTimeFound.Equal(b.VolumeTimeFound) {
+			return a.VolumeTimeFound.After(b.VolumeTimeFound) // The most recent first
+		}
+
+		return foundVolumeKey(a.Pair, a.Exchange, a.Side) < foundVolumeKey(b.Pair, b.Exchange, b.Side) // Keep the order stable for equal times
+	})
+
+	if filter.Offset >= len(volumesToReturn) {
+		return []models.FoundVolume{}, nil // The page is past the last found volume
+	}
+
+	volumesToReturn = volumesToReturn[filter.Offset:]
+
+	if filter.Limit > 0 && filter.Limit < len(volumesToReturn) {
+		volumesToReturn = volumesToReturn[:filter.Limit]
+	}
+
+	return volumesToReturn, nil // Return the found volumes of the requested page
+}
+
+// foundVolumeKey builds the key of a found volume in the found volumes of a user.
+// The parts are joined with a delimiter, which doesn't occur in pair and exchange names,
+// so that different pairs and exchanges whose concatenations overlap don't share a key.
+func foundVolumeKey(pair, exchange, side string) string {
+	return pair + foundVolumeKeyDelimiter + exchange + foundVolumeKeyDelimiter + side
+}
+
+// foundVolumeSortValue returns the value of the found volume the found volumes are sorted by.
+// The difference is used for an empty sort key.
+func foundVolumeSortValue(volume models.FoundVolume, sortBy string) float64 {
+	switch sortBy {
+	case "volume":
+		return volume.Volume
+	case "price":
+		return volume.Price
+	default:
+		return volume.Difference
+	}
+}
+
+// foundVolumeMatchesFilter reports whether the found volume satisfies every non-empty field of the filter.
+func foundVolumeMatchesFilter(volume models.FoundVolume, filter models.FoundVolumesFilter) bool {
+	switch {
+	case filter.Exchange != "" && volume.Exchange != filter.Exchange:
+		return false
+	case filter.Pair != "" && volume.Pair != filter.Pair:
+		return false
+	case filter.Side != "" && volume.Side != filter.Side:
+		return false
+	case volume.Volume < filter.MinVolume:
+		return false
+	}
+
+	return true
+}
+
+// GetFoundVolumesFromDB retrieves the found volumes of the given users from the database and stores them in memory.
+// It is used on startup to rehydrate the in-memory cache.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - userIDs: The IDs of the users whose found volumes are to be loaded.
+//
+// Returns:
+//   - An error if any occurs during retrieval.
+func (fvs *foundVolumesService) GetFoundVolumesFromDB(ctx context.Context, userIDs []int) error {
+	ctx, cancel := context.WithTimeout(ctx, fvs.contextTimeout) // Set up context with timeout
+	defer cancel()                                              // Ensure cancellation of context when done
+
+	for _, userID := range userIDs {
+		foundVolumes, err := fvs.foundVolumesRepository.GetByUser(ctx, userID) // Get the stored found volumes of the user
+		if err != nil {
+			return err
+		}
+
+		if len(foundVolumes) == 0 {
+			continue
+		}
+
+		foundVolumesMap := cmap.New[models.FoundVolume]() // Create a new concurrent map for found volumes
+
+		for _, foundVolume := range foundVolumes {
+			foundVolumesMap.Set(foundVolumeKey(foundVolume.Pair, foundVolume.Exchange, foundVolume.Side), foundVolume) // Insert found volume data
+		}
+
+		fvs.foundVolumesData.Set(strconv.Itoa(userID), foundVolumesMap) // Store the map in foundVolumesData
+	}
+
+	return nil
+}
+
+// Subscribe subscribes to the found volume events of the user.
+//
+// Every subscriber has its own buffered channel, so the consumers, e.g. the websocket and the notifications,
+// receive the same events independently. The events are sent without blocking the scanner, so a subscriber
+// whose buffer is full misses them, which is logged.
+//
+// Parameters:
+//   - userID: The ID of the user whose found volumes are to be received, AllUsers to receive the ones of every user.
+//
+// Returns:
+//   - A channel receiving every new found volume of the user and the expired ones marked as such.
+//   - A function cancelling the subscription and closing the channel. It may be called more than once.
+func (fvs *foundVolumesService) Subscribe(userID int) (<-chan models.FoundVolume, func()) {
+	buffer := foundVolumesSubscriberBuffer
+	if userID == AllUsers {
+		buffer = foundVolumesAllUsersSubscriberBuffer
+	}
+
+	subscriber := make(chan models.FoundVolume, buffer)
+
+	fvs.subscribersMu.Lock()
+	if fvs.subscribers[userID] == nil {
+		fvs.subscribers[userID] = make(map[chan models.FoundVolume]struct{})
+	}
+
+	fvs.subscribers[userID][subscriber] = struct{}{}
+	fvs.subscribersMu.Unlock()
+
+	var once sync.Once
+
+	unsubscribe := func() {
+		once.Do(func() {
+			fvs.subscribersMu.Lock()
+			defer fvs.subscribersMu.Unlock()
+
+			delete(fvs.subscribers[userID], subscriber)
+			close(subscriber)
+
+			if len(fvs.subscribers[userID]) == 0 {
+				delete(fvs.subscribers, userID)
+			}
+		})
+	}
+
+	return subscriber, unsubscribe
+}
+
+// StartExpirySweeper starts removing the found volumes which weren't found again for longer than the TTL.
+//
+// A found volume is stored again with a new time on every scan which finds it, so a volume whose time
+// is older than the TTL belongs to a wall which has disappeared without a scan reporting it, e.g. because
+// the pair stopped being scanned. Every interval the expired found volumes are removed from memory and
+// the database, and published to the subscribers marked as expired.
+//
+// The sweeper runs in its own goroutine until the context is cancelled.
+//
+// Parameters:
+//   - ctx: The context which stops the sweeper when it is cancelled.
+//   - ttl: The time a found volume is kept without being found again. A non-positive value uses the default TTL.
+//   - interval: The time between the sweeps. A non-positive value uses the default interval.
+//   - logger: The logger of the found volumes which fail to be deleted from the database.
+func (fvs *foundVolumesService) StartExpirySweeper(ctx context.Context, ttl, interval time.Duration, logger logger.Logger) {
+	if ttl <= 0 {
+		ttl = defaultFoundVolumeTTL
+	}
+
+	if interval <= 0 {
+		interval = defaultFoundVolumeSweepInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				for _, err := range fvs.sweepExpired(ctx, now.Add(-ttl)) {
+					logger.Error(err) // Keep sweeping, the volume is removed from memory regardless
+				}
+			}
+		}
+	}()
+}
+
+// sweepExpired removes the found volumes found before the cutoff and notifies the subscribers of their users.
+//
+// A found volume is removed only if it is still expired when its shard is locked, so a volume found again
+// by a concurrent scan is kept.
+//
+// Returns:
+//   - The errors of deleting the expired found volumes from the database, one per failed deletion.
+func (fvs *foundVolumesService) sweepExpired(ctx context.Context, cutoff time.Time) []error {
+	var errs []error
+
+	for userIDKey, userFoundVolumesData := range fvs.foundVolumesData.Items() {
+		userID, err := strconv.Atoi(userIDKey)
+		if err != nil {
+			continue // Keys are always converted user IDs
+		}
+
+		for key, foundVolume := range userFoundVolumesData.Items() {
+			if !foundVolume.VolumeTimeFound.Before(cutoff) {
+				continue
+			}
+
+			removed := userFoundVolumesData.RemoveCb(key, func(_ string, stored models.FoundVolume, exists bool) bool {
+				return exists && stored.VolumeTimeFound.Before(cutoff) // Keep the volume if it was found again meanwhile
+			})
+			if !removed {
+				continue
+			}
+
+			foundVolume.Expired = true
+			fvs.publish(userID, foundVolume) // Let the subscribers drop the expired found volume
+
+			deleteCtx, cancel := context.WithTimeout(ctx, fvs.contextTimeout) // Set up context with timeout
+			if err := fvs.foundVolumesRepository.Delete(deleteCtx, userID, foundVolume); err != nil {
+				errs = append(errs, err)
+			}
+			cancel()
+		}
+	}
+
+	return errs
+}
+
+// foundVolumeChanged reports whether the found volume materially differs from the stored one,
+// that is it is found at another price or its volume changed by more than foundVolumeChangeRatio.
+func foundVolumeChanged(stored, foundVolume models.FoundVolume) bool {
+	if stored.Price != foundVolume.Price {
+		return true
+	}
+
+	return math.Abs(foundVolume.Volume-stored.Volume) > stored.Volume*foundVolumeChangeRatio
+}
+
+// publish sends the found volume to every subscriber of the user and of all users without blocking.
+// A subscriber whose buffer is full misses the found volume, which is logged.
+func (fvs *foundVolumesService) publish(userID int, foundVolume models.FoundVolume) {
+	foundVolume.UserID = userID // Let the subscribers of all users tell whose volume it is
+
+	fvs.subscribersMu.RLock()
+	defer fvs.subscribersMu.RUnlock()
+
+	for _, subscriberID := range []int{userID, AllUsers} {
+		for subscriber := range fvs.subscribers[subscriberID] {
+			select {
+			case subscriber <- foundVolume:
+			default: // The subscriber is too slow, skip it
+				fvs.logger.Warnf(
+					"dropping the found volume of %s on %s of user %d for a slow subscriber",
+					foundVolume.Pair,
+					foundVolume.Exchange,
+					userID,
+				)
+			}
+		}
+	}
 }
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFoundVolumeKey tests that the keys of pairs and exchanges whose concatenations overlap are distinct.
+func TestFoundVolumeKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                     string // Name of the test case
+		pair, exchange           string // First found volume
+		otherPair, otherExchange string // Found volume whose concatenation used to be the same
+	}{
+		{
+			name:          "Pair Suffix Moved To Exchange",
+			pair:          "BTC",
+			exchange:      "USDTbinance",
+			otherPair:     "BTCUSDT",
+			otherExchange: "binance",
+		},
+		{
+			name:          "Exchange Prefix Moved To Pair",
+			pair:          "ETHBTCbinance",
+			exchange:      "_spot",
+			otherPair:     "ETHBTC",
+			otherExchange: "binance_spot",
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.pair+tc.exchange, tc.otherPair+tc.otherExchange) // The concatenations collide
+
+			for _, side := range []string{"asks", "bids"} {
+				assert.NotEqual(t, foundVolumeKey(tc.pair, tc.exchange, side), foundVolumeKey(tc.otherPair, tc.otherExchange, side))
+			}
+		})
+	}
+}
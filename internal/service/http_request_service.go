@@ -1,57 +1,294 @@
 package service
 
 import (
+	"context"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
+
+	"cvs/internal/service/metrics"
+)
+
+const (
+	maxRetryAfter         = time.Minute      // Upper bound of the delay requested by the Retry-After header
+	defaultRequestTimeout = 10 * time.Second // Timeout of a single request used when none is configured
+	defaultUserAgent      = "Crypto-Volume-Scanner"
+)
+
+const (
+	binanceUsedWeightHeader = "X-Mbx-Used-Weight-1m" // Weight of the requests Binance counted within the current minute
+	bybitLimitHeader        = "X-Bapi-Limit"         // Number of requests Bybit allows within the current window
+	bybitLimitStatusHeader  = "X-Bapi-Limit-Status"  // Number of requests left within the current window of Bybit
 )
 
 // HttpRequest defines the interface for making HTTP requests.
-// This interface includes a method for performing GET requests.
+// This interface includes methods for performing GET requests.
+// The headers, e.g. the API key of an exchange, are added to the request and may be nil.
 type HttpRequest interface {
-	Get(url string) (http.Response, error) // Method to perform a GET request
+	Get(url string, headers http.Header) (http.Response, error)                                                                    // Method to perform a GET request
+	GetWithRetry(ctx context.Context, url string, headers http.Header, attempts int, backoff time.Duration) (http.Response, error) // Method to perform a GET request retrying transient failures
 }
 
 // httpRequest is a concrete implementation of HttpRequest.
 // It holds an HTTP client configured with a timeout.
 type httpRequest struct {
-	client http.Client // HTTP client for making requests
+	client         http.Client   // HTTP client for making requests
+	requestTimeout time.Duration // Deadline of a single request, including reading its body
+	userAgent      string        // Value of the User-Agent header of every request
 }
 
 // NewHttpRequestService creates a new instance of httpRequest.
 // It initializes the HTTP client with a specified request timeout.
 //
 // Parameters:
-//   - requestTimeout: Duration to set the timeout for HTTP requests.
+//   - requestTimeout: Duration to set the timeout for HTTP requests. If it isn't positive, 10 seconds are used.
+//   - userAgent: Value of the User-Agent header of every request. If it is empty, "Crypto-Volume-Scanner" is used.
 //
 // Returns:
 //   - An instance of HttpRequest.
-func NewHttpRequestService(requestTimeout time.Duration) HttpRequest {
+func NewHttpRequestService(requestTimeout time.Duration, userAgent string) HttpRequest {
+	if requestTimeout <= 0 {
+		requestTimeout = defaultRequestTimeout
+	}
+
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+
 	client := http.Client{
 		Timeout: requestTimeout, // Set the timeout for the HTTP client
 	}
 
 	return &httpRequest{
-		client: client, // Return an instance of httpRequest with the configured client
+		client:         client, // Return an instance of httpRequest with the configured client
+		requestTimeout: requestTimeout,
+		userAgent:      userAgent,
 	}
 }
 
+// cancelOnCloseBody is the body of a response which cancels the context of its request once it is closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc // Cancels the context of the request the body belongs to
+}
+
+// Close closes the body and releases the context of the request.
+func (b *cancelOnCloseBody) Close() error {
+	defer b.cancel()
+
+	return b.ReadCloser.Close()
+}
+
 // Get performs a GET request to the specified URL.
 //
+// The request runs under a context with the deadline of the request timeout, so a hung connection
+// is cancelled instead of blocking the caller. The deadline covers reading the body as well,
+// and the context is released once the body is closed.
+//
+// The request carries the configured User-Agent header, which is overridden by the headers if they set it.
+//
 // Parameters:
 //   - url: The URL to send the GET request to.
+//   - headers: The headers added to the request, e.g. the API key of an exchange. It may be nil.
 //
 // Returns:
-//   - The HTTP response and any error encountered during the request.
-func (hr *httpRequest) Get(url string) (http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil) // Create a new GET request
+//   - The HTTP response and any error encountered during the request. The error of a request
+//     exceeding the deadline wraps context.DeadlineExceeded.
+func (hr *httpRequest) Get(url string, headers http.Header) (http.Response, error) {
+	return hr.get(context.Background(), url, headers)
+}
+
+// get performs a GET request like Get, which is also cancelled once the parent context is done.
+func (hr *httpRequest) get(parent context.Context, url string, headers http.Header) (http.Response, error) {
+	ctx, cancel := context.WithTimeout(parent, hr.requestTimeout)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil) // Create a new GET request
 	if err != nil {
+		cancel()
+
 		return http.Response{}, err // Return an empty response and the error
 	}
 
+	req.Header.Set("User-Agent", hr.userAgent)
+	for name, values := range headers {
+		req.Header[http.CanonicalHeaderKey(name)] = values
+	}
+
 	resp, err := hr.client.Do(req) // Execute the GET request using the HTTP client
 	if err != nil {
+		cancel()
+
 		return http.Response{}, err // Return an empty response and the error
 	}
 
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel} // Keep the context alive until the body is read
+
 	return *resp, nil // Return the response from the GET request
 }
+
+// GetWithRetry performs a GET request to the specified URL, retrying it on transient failures.
+//
+// The request is retried on network errors and on 5xx and 429 responses. The delay before
+// a retry grows exponentially starting from backoff, unless the response carries
+// the Retry-After header, whose delay is used instead.
+//
+// Cancelling the context aborts both the request in flight and the wait before a retry,
+// so a shutdown isn't held up by a long Retry-After delay.
+//
+// Parameters:
+//   - ctx: The context of the requests and of the waits between them.
+//   - url: The URL to send the GET request to.
+//   - headers: The headers added to every attempt. It may be nil.
+//   - attempts: The maximum number of requests, values below 1 are treated as 1.
+//   - backoff: The delay before the first retry, doubled for every next one.
+//
+// Returns:
+//   - The HTTP response of the last attempt and any error encountered during it,
+//     or the context error if the context was cancelled while waiting for a retry.
+func (hr *httpRequest) GetWithRetry(ctx context.Context, url string, headers http.Header, attempts int, backoff time.Duration) (http.Response, error) {
+	delay := backoff
+
+	for attempt := 1; ; attempt++ {
+		resp, err := hr.get(ctx, url, headers)
+		if attempt >= attempts || (err == nil && !isRetryableStatus(resp.StatusCode)) {
+			return resp, err // Return the response if it succeeded or no attempts are left
+		}
+
+		wait := delay
+		if err == nil {
+			if retryAfter, ok := ParseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				wait = retryAfter // The server told how long to wait
+			}
+
+			io.Copy(io.Discard, resp.Body) // Drain the body so the connection can be reused
+			resp.Body.Close()
+		}
+
+		metrics.ObserveHttpRetry(url)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return http.Response{}, ctx.Err()
+		case <-timer.C:
+		}
+
+		delay *= 2
+	}
+}
+
+// isRetryableStatus reports whether the response status signals a transient failure.
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// ParseRetryAfter parses the value of the Retry-After header,
+// which is either a number of seconds or an HTTP date. It is also used by the exchanges
+// to back off after a response which wasn't retried.
+//
+// Returns:
+//   - The delay to wait, capped by maxRetryAfter, and whether the value was valid.
+func ParseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		retryAfter = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		retryAfter = time.Until(date)
+	} else {
+		return 0, false
+	}
+
+	return max(min(retryAfter, maxRetryAfter), 0), true
+}
+
+// RateLimitUsage is the part of the rate limit of an exchange used by the requests, as reported by the headers of a response.
+type RateLimitUsage struct {
+	Used  int // Weight or number of the requests counted by the exchange within the current window
+	Limit int // Weight or number of the requests the exchange allows within the window, zero if the response doesn't tell it
+}
+
+// Ratio returns the used part of the limit, e.g. 0.8 if 80% of the limit is used, or zero if the limit is unknown.
+func (u RateLimitUsage) Ratio() float64 {
+	if u.Limit <= 0 {
+		return 0
+	}
+
+	return float64(u.Used) / float64(u.Limit)
+}
+
+// ParseRateLimitUsage parses the rate limit headers of a response, the X-MBX-USED-WEIGHT-1M header
+// of Binance, which tells only the used weight, or the X-Bapi-Limit and X-Bapi-Limit-Status headers of Bybit.
+//
+// Returns:
+//   - The used part of the limit and whether the headers were found and valid.
+func ParseRateLimitUsage(header http.Header) (RateLimitUsage, bool) {
+	if used, err := strconv.Atoi(header.Get(binanceUsedWeightHeader)); err == nil && used >= 0 {
+		return RateLimitUsage{Used: used}, true
+	}
+
+	limit, err := strconv.Atoi(header.Get(bybitLimitHeader))
+	if err != nil || limit <= 0 {
+		return RateLimitUsage{}, false
+	}
+
+	remaining, err := strconv.Atoi(header.Get(bybitLimitStatusHeader))
+	if err != nil || remaining < 0 {
+		return RateLimitUsage{}, false
+	}
+
+	return RateLimitUsage{Used: max(limit-remaining, 0), Limit: limit}, true
+}
+
+// rateLimitObserver is an HttpRequest passing the rate limit usage of every response to a callback.
+type rateLimitObserver struct {
+	HttpRequest                            // Service making the requests
+	callback    func(usage RateLimitUsage) // Called with the usage of every response carrying the rate limit headers
+}
+
+// NewRateLimitObserver wraps the HTTP request service, so the callback is called with the rate limit usage
+// of every response carrying the rate limit headers, see ParseRateLimitUsage. It lets an exchange
+// slow down its requests as they approach the limit and speed up once the usage recedes.
+//
+// Parameters:
+//   - httpRequest: The service making the requests.
+//   - callback: The function called with the usage, synchronously before the response is returned.
+//
+// Returns:
+//   - An instance of HttpRequest.
+func NewRateLimitObserver(httpRequest HttpRequest, callback func(usage RateLimitUsage)) HttpRequest {
+	return &rateLimitObserver{HttpRequest: httpRequest, callback: callback}
+}
+
+// Get performs a GET request and reports the rate limit usage of its response.
+func (o *rateLimitObserver) Get(url string, headers http.Header) (http.Response, error) {
+	resp, err := o.HttpRequest.Get(url, headers)
+	o.observe(resp, err)
+
+	return resp, err
+}
+
+// GetWithRetry performs a GET request retrying transient failures and reports the rate limit usage of the last response.
+func (o *rateLimitObserver) GetWithRetry(ctx context.Context, url string, headers http.Header, attempts int, backoff time.Duration) (http.Response, error) {
+	resp, err := o.HttpRequest.GetWithRetry(ctx, url, headers, attempts, backoff)
+	o.observe(resp, err)
+
+	return resp, err
+}
+
+// observe calls the callback if the response carries the rate limit headers.
+func (o *rateLimitObserver) observe(resp http.Response, err error) {
+	if err != nil || resp.Header == nil {
+		return
+	}
+
+	if usage, ok := ParseRateLimitUsage(resp.Header); ok {
+		o.callback(usage)
+	}
+}
@@ -8,58 +8,178 @@ import (
 	"github.com/golang-jwt/jwt"
 )
 
+const (
+	defaultAccessTokenLifetimeHours  = 1                // Access token lifetime in hours used when the config omits it
+	defaultRefreshTokenLifetimeHours = 720              // Refresh token lifetime in hours used when the config omits it
+	refreshTokenType                 = "refresh"        // Value of the type claim of refresh tokens
+	resetPasswordTokenType           = "reset_password" // Value of the type claim of password reset tokens
+	resetPasswordTokenLifetime       = 15 * time.Minute // Duration before the password reset token expires
+	verifyEmailTokenType             = "verify_email"   // Value of the type claim of email verification tokens
+	verifyEmailTokenLifetime         = 24 * time.Hour   // Duration before the email verification token expires
+	changeEmailTokenType             = "change_email"   // Value of the type claim of email change tokens
+	changeEmailTokenLifetime         = 24 * time.Hour   // Duration before the email change token expires
+	defaultClockSkewLeeway           = 30 * time.Second // Leeway of the time claims used when the config omits it
+)
+
+// signingMethod is the only algorithm the tokens are signed with, the tokens signed with any other one are rejected,
+// so neither an unsigned token nor a token signed by another algorithm with the same key is accepted.
+var signingMethod = jwt.SigningMethodHS256
+
 // JwtService defines the interface for JSON Web Token (JWT) operations.
-// This interface includes methods for creating access and refresh tokens, as well as parsing tokens.
+// This interface includes methods for creating access, refresh, password reset, email verification
+// and email change tokens, as well as parsing tokens of each type.
 type JwtService interface {
-	CreateAccessToken(userId, sessionId int) (string, int64, error) // Method to create an access token
-	CreateRefreshToken(userId, sessionId int) (string, error)       // Method to create a refresh token
-	Parse(token string) (userId int, sessionId int, err error)      // Method to parse a token
+	CreateAccessToken(userId, sessionId int, role string) (string, int64, error)       // Method to create an access token
+	CreateRefreshToken(userId, sessionId int) (string, error)                          // Method to create a refresh token
+	CreateResetPasswordToken(userId, passwordVersion int) (string, error)              // Method to create a password reset token
+	CreateVerifyEmailToken(userId int) (string, error)                                 // Method to create an email verification token
+	CreateChangeEmailToken(userId int, email string) (string, error)                   // Method to create an email change token
+	Parse(token string) (userId int, sessionId int, err error)                         // Method to parse an access token
+	ParseRefreshToken(token string) (userId int, sessionId int, err error)             // Method to parse a refresh token
+	ParseResetPasswordToken(token string) (userId int, passwordVersion int, err error) // Method to parse a password reset token
+	ParseVerifyEmailToken(token string) (userId int, err error)                        // Method to parse an email verification token
+	ParseChangeEmailToken(token string) (userId int, email string, err error)          // Method to parse an email change token
 }
 
 // jwtService is a concrete implementation of JwtService.
-// It holds the secret key used for signing tokens and configuration for token lifetimes.
+// It holds the secret key used for signing tokens, the keys the tokens are verified with,
+// configuration for token lifetimes and the claims identifying the issuer and the audience of the tokens.
 type jwtService struct {
-	secretKey                 []byte        // Secret key for signing tokens
-	accessTokenLifetimeHours  time.Duration // Duration in hours before the access token expires
-	refreshTokenLifetimeHours time.Duration // Duration in hours before the refresh token expires
+	keyId                     string            // Key id of the secret key set as the kid header of the issued tokens, not set when empty
+	secretKey                 []byte            // Secret key for signing tokens
+	verificationKeys          map[string][]byte // Secret keys the tokens are verified with keyed by key id, including the current one
+	accessTokenLifetimeHours  time.Duration     // Duration in hours before the access token expires
+	refreshTokenLifetimeHours time.Duration     // Duration in hours before the refresh token expires
+	issuer                    string            // Value of the iss claim of the issued tokens, empty if it isn't set nor validated
+	audience                  string            // Value of the aud claim of the issued tokens, empty if it isn't set nor validated
+	clockSkewLeeway           time.Duration     // Time the exp, nbf and iat claims may be off by, so the clients with skewed clocks aren't rejected
+	now                       func() time.Time  // Clock the tokens are issued and validated by, time.Now outside of the tests
 }
 
+var (
+	errTokenLifetimeNotPositive   = errors.New("token lifetimes must be positive")
+	errAccessTokenOutlivesRefresh = errors.New("access token lifetime must be shorter than refresh token lifetime")
+	errInvalidIssuer              = errors.New("invalid token issuer")
+	errInvalidAudience            = errors.New("invalid token audience")
+	errPreviousKeyIdIsCurrent     = errors.New("previous jwt key id must differ from the current key id")
+	errPreviousKeyIsEmpty         = errors.New("previous jwt secret key must not be empty")
+	errUnknownKeyId               = errors.New("unknown token key id")
+	errClockSkewLeewayNegative    = errors.New("jwt clock skew leeway must not be negative")
+	errTokenExpired               = errors.New("token is expired")
+	errTokenNotValidYet           = errors.New("token is not valid yet")
+	errTokenUsedBeforeIssued      = errors.New("token used before issued")
+)
+
 // NewJwtService creates a new instance of jwtService.
-// It initializes the service with a secret key.
+// It initializes the service with a secret key and the token lifetimes.
+// A zero lifetime means it is omitted from the config, so the default one is used instead:
+// 1 hour for access tokens and 720 hours for refresh tokens.
+//
+// The issuer and the audience are set as the iss and aud claims of every issued token, and the parsed tokens
+// must carry the same ones. An empty issuer or audience is neither set nor validated.
+//
+// The tokens are signed with the secret key and carry its key id in the kid header. The parsed tokens are
+// verified with the key of the id they carry, either the current or one of the previous keys, so the tokens
+// signed before the secret key was rotated stay valid until they expire. The tokens without the kid header,
+// e.g. issued before the key ids were configured, are verified with the key of the empty id.
+//
+// The exp, nbf and iat claims of the parsed tokens are validated with the clock skew leeway, so a token
+// is accepted for the leeway after it expires or before it becomes valid. A zero leeway means it is omitted
+// from the config, so the default one of 30 seconds is used instead.
 //
 // Parameters:
 //   - secretKey: The secret key used for signing tokens.
+//   - keyId: The key id of the secret key, e.g. "2024-06". An empty id isn't set as the kid header.
+//   - previousKeys: The secret keys the tokens signed before the rotation are verified with keyed by key id.
+//   - accessTokenLifetimeHours: The number of hours before the access token expires.
+//   - refreshTokenLifetimeHours: The number of hours before the refresh token expires.
+//   - issuer: The issuer of the tokens, e.g. "crypto-volume-scanner".
+//   - audience: The audience of the tokens, e.g. "crypto-volume-scanner-api".
+//   - clockSkewLeeway: The time the time claims of the parsed tokens may be off by, e.g. 30 seconds.
 //
 // Returns:
 //   - An instance of JwtService.
+//   - An error if a lifetime or the leeway is negative, the access token doesn't expire before the refresh token,
+//     a previous key is empty or has the key id of the current one.
 func NewJwtService(
-	secretKey string,
+	secretKey,
+	keyId string,
+	previousKeys map[string]string,
 	accessTokenLifetimeHours,
 	refreshTokenLifetimeHours time.Duration,
-) JwtService {
+	issuer,
+	audience string,
+	clockSkewLeeway time.Duration,
+) (JwtService, error) {
+	if accessTokenLifetimeHours == 0 {
+		accessTokenLifetimeHours = defaultAccessTokenLifetimeHours
+	}
+	if refreshTokenLifetimeHours == 0 {
+		refreshTokenLifetimeHours = defaultRefreshTokenLifetimeHours
+	}
+
+	if accessTokenLifetimeHours < 0 || refreshTokenLifetimeHours < 0 {
+		return nil, errTokenLifetimeNotPositive // Such tokens would be issued already expired
+	}
+	if accessTokenLifetimeHours >= refreshTokenLifetimeHours {
+		return nil, errAccessTokenOutlivesRefresh // The refresh token must be usable after the access token expires
+	}
+
+	if clockSkewLeeway == 0 {
+		clockSkewLeeway = defaultClockSkewLeeway
+	}
+	if clockSkewLeeway < 0 {
+		return nil, errClockSkewLeewayNegative
+	}
+
+	verificationKeys := make(map[string][]byte, len(previousKeys)+1)
+	for previousKeyId, previousKey := range previousKeys {
+		if previousKeyId == keyId {
+			return nil, errPreviousKeyIdIsCurrent // The tokens of the id would be verified with an ambiguous key
+		}
+		if previousKey == "" {
+			return nil, errPreviousKeyIsEmpty // Anyone could sign the tokens verified with an empty key
+		}
+
+		verificationKeys[previousKeyId] = []byte(previousKey)
+	}
+	verificationKeys[keyId] = []byte(secretKey)
+
 	return &jwtService{
-		secretKey:                 []byte(secretKey),         // Convert secret key to byte slice
+		keyId:                     keyId,
+		secretKey:                 []byte(secretKey), // Convert secret key to byte slice
+		verificationKeys:          verificationKeys,
 		accessTokenLifetimeHours:  accessTokenLifetimeHours,  // Set access token lifetime in hours
 		refreshTokenLifetimeHours: refreshTokenLifetimeHours, // Set refresh token lifetime in hours
-	}
+		issuer:                    issuer,
+		audience:                  audience,
+		clockSkewLeeway:           clockSkewLeeway,
+		now:                       time.Now,
+	}, nil
 }
 
 // CreateAccessToken generates a new access token for a given user ID.
-// The token will expire in 20 hours.
+// The token expires after the configured access token lifetime, 1 hour by default.
+//
+// The role claim lets the clients tell what the user is allowed to access. The routes requiring a role
+// check the role stored in the database instead, so a changed role takes effect before the token expires.
 //
 // Parameters:
 //   - userId: The ID of the user for whom the access token is created.
+//   - sessionId: The current session ID of the user.
+//   - role: The role of the user, e.g. models.RoleUser.
 //
 // Returns:
-//   - The generated token as a string, its expiration time as an int64, and any error encountered.
-func (js *jwtService) CreateAccessToken(userId, sessionId int) (string, int64, error) {
-	expiresAt := time.Now().Add(time.Hour * js.accessTokenLifetimeHours).UnixMilli() // Set expiration time to 20 hours from now
+//   - The generated token as a string, its expiration time as a Unix time in seconds, and any error encountered.
+func (js *jwtService) CreateAccessToken(userId, sessionId int, role string) (string, int64, error) {
+	expiresAt := js.now().Add(time.Hour * js.accessTokenLifetimeHours).Unix() // The exp claim is in seconds, as the time claims are validated
 
 	// Create a new JWT with standard claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256,
+	token := js.newToken(
 		jwt.MapClaims{
 			"user_id":    userId,
 			"session_id": sessionId,
+			"role":       role,
 			"exp":        expiresAt,
 		},
 	)
@@ -73,7 +193,8 @@ func (js *jwtService) CreateAccessToken(userId, sessionId int) (string, int64, e
 }
 
 // CreateRefreshToken generates a new refresh token for a given user ID.
-// The refresh token will expire in 1200 hours (50 days).
+// The token carries a distinct type claim, so it can't be used as an access token, and expires
+// after the configured refresh token lifetime, 720 hours (30 days) by default.
 //
 // Parameters:
 //   - userId: The ID of the user for whom the refresh token is created.
@@ -81,11 +202,12 @@ func (js *jwtService) CreateAccessToken(userId, sessionId int) (string, int64, e
 // Returns:
 //   - The generated refresh token as a string and any error encountered.
 func (js *jwtService) CreateRefreshToken(userId, sessionId int) (string, error) {
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS384,
+	refreshToken := js.newToken(
 		jwt.MapClaims{
 			"user_id":    userId,
 			"session_id": sessionId,
-			"exp":        time.Now().Add(time.Hour * js.refreshTokenLifetimeHours).UnixMilli(),
+			"type":       refreshTokenType,
+			"exp":        js.now().Add(time.Hour * js.refreshTokenLifetimeHours).Unix(),
 		},
 	)
 
@@ -97,34 +219,302 @@ func (js *jwtService) CreateRefreshToken(userId, sessionId int) (string, error)
 	return tokenString, nil // Return the signed refresh token
 }
 
-// Parse validates and parses a given JWT token.
-// It retrieves the user ID from the claims if valid.
+// CreateResetPasswordToken generates a new password reset token for a given user ID.
+// The token carries a distinct type claim, so it can't be used as an access or refresh token,
+// and expires in 15 minutes.
+//
+// Parameters:
+//   - userId: The ID of the user who resets the password.
+//   - passwordVersion: The current password version of the user. Resetting the password changes it,
+//     so the token can be used only once.
+//
+// Returns:
+//   - The generated password reset token as a string and any error encountered.
+func (js *jwtService) CreateResetPasswordToken(userId, passwordVersion int) (string, error) {
+	resetToken := js.newToken(
+		jwt.MapClaims{
+			"user_id":    userId,
+			"session_id": passwordVersion, // Carried by the claim of the session ID, so the reset token is parsed as the other tokens
+			"type":       resetPasswordTokenType,
+			"exp":        js.now().Add(resetPasswordTokenLifetime).Unix(),
+		},
+	)
+
+	tokenString, err := resetToken.SignedString(js.secretKey) // Sign the password reset token with the secret key
+	if err != nil {
+		return "", err // Return empty string if signing fails
+	}
+
+	return tokenString, nil // Return the signed password reset token
+}
+
+// CreateVerifyEmailToken generates a new email verification token for a given user ID.
+// The token carries a distinct type claim, so it can't be used as an access or refresh token,
+// and expires in 24 hours.
+//
+// Parameters:
+//   - userId: The ID of the user whose email is verified.
+//
+// Returns:
+//   - The generated email verification token as a string and any error encountered.
+func (js *jwtService) CreateVerifyEmailToken(userId int) (string, error) {
+	verifyToken := js.newToken(
+		jwt.MapClaims{
+			"user_id": userId,
+			"type":    verifyEmailTokenType,
+			"exp":     js.now().Add(verifyEmailTokenLifetime).Unix(),
+		},
+	)
+
+	tokenString, err := verifyToken.SignedString(js.secretKey) // Sign the email verification token with the secret key
+	if err != nil {
+		return "", err // Return empty string if signing fails
+	}
+
+	return tokenString, nil // Return the signed email verification token
+}
+
+// CreateChangeEmailToken generates a new email change token for a given user ID.
+// The token carries the new email, so it confirms only the email it was sent to,
+// and a distinct type claim, so it can't be used as any other token. It expires in 24 hours.
+//
+// Parameters:
+//   - userId: The ID of the user who changes the email.
+//   - email: The new email of the user.
+//
+// Returns:
+//   - The generated email change token as a string and any error encountered.
+func (js *jwtService) CreateChangeEmailToken(userId int, email string) (string, error) {
+	changeToken := js.newToken(
+		jwt.MapClaims{
+			"user_id": userId,
+			"email":   email,
+			"type":    changeEmailTokenType,
+			"exp":     js.now().Add(changeEmailTokenLifetime).Unix(),
+		},
+	)
+
+	tokenString, err := changeToken.SignedString(js.secretKey) // Sign the email change token with the secret key
+	if err != nil {
+		return "", err // Return empty string if signing fails
+	}
+
+	return tokenString, nil // Return the signed email change token
+}
+
+// Parse validates and parses a given access token.
+// It retrieves the user ID from the claims if valid. Refresh, password reset, email verification
+// and email change tokens are rejected.
 //
 // Parameters:
-//   - token: The JWT token to be parsed.
+//   - token: The access token to be parsed.
 //
 // Returns:
 //   - The user ID as a string and any error encountered.
 func (js *jwtService) Parse(token string) (userId int, sessionId int, err error) {
-	t, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok { // Validate signing method
+	claims, err := js.parseClaims(token)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if _, typed := claims["type"]; typed { // Refresh, password reset, email verification and email change tokens don't authenticate requests
+		return 0, 0, errors.New("invalid token type")
+	}
+
+	return claimsIDs(claims)
+}
+
+// ParseRefreshToken validates and parses a refresh token.
+// Tokens of any other type, including access tokens, are rejected.
+//
+// Parameters:
+//   - token: The refresh token to be parsed.
+//
+// Returns:
+//   - The user ID, the session ID the token was issued for and any error encountered.
+func (js *jwtService) ParseRefreshToken(token string) (userId int, sessionId int, err error) {
+	claims, err := js.parseClaims(token)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if claims["type"] != refreshTokenType {
+		return 0, 0, errors.New("invalid token type")
+	}
+
+	return claimsIDs(claims)
+}
+
+// ParseResetPasswordToken validates and parses a password reset token.
+// Tokens of any other type are rejected.
+//
+// Parameters:
+//   - token: The password reset token to be parsed.
+//
+// Returns:
+//   - The user ID, the password version the token was issued for and any error encountered.
+func (js *jwtService) ParseResetPasswordToken(token string) (userId int, passwordVersion int, err error) {
+	claims, err := js.parseClaims(token)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if claims["type"] != resetPasswordTokenType {
+		return 0, 0, errors.New("invalid token type")
+	}
+
+	return claimsIDs(claims)
+}
+
+// ParseVerifyEmailToken validates and parses an email verification token.
+// Tokens of any other type are rejected.
+//
+// Parameters:
+//   - token: The email verification token to be parsed.
+//
+// Returns:
+//   - The user ID the token was issued for and any error encountered.
+func (js *jwtService) ParseVerifyEmailToken(token string) (userId int, err error) {
+	claims, err := js.parseClaims(token)
+	if err != nil {
+		return 0, err
+	}
+
+	if claims["type"] != verifyEmailTokenType {
+		return 0, errors.New("invalid token type")
+	}
+
+	userIdClaim, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, errors.New("invalid claims") // Return error if the user ID is missing
+	}
+
+	return int(userIdClaim), nil
+}
+
+// ParseChangeEmailToken validates and parses an email change token.
+// Tokens of any other type are rejected.
+//
+// Parameters:
+//   - token: The email change token to be parsed.
+//
+// Returns:
+//   - The user ID and the new email the token was issued for and any error encountered.
+func (js *jwtService) ParseChangeEmailToken(token string) (userId int, email string, err error) {
+	claims, err := js.parseClaims(token)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if claims["type"] != changeEmailTokenType {
+		return 0, "", errors.New("invalid token type")
+	}
+
+	userIdClaim, okUser := claims["user_id"].(float64)
+	emailClaim, okEmail := claims["email"].(string)
+	if !okUser || !okEmail || emailClaim == "" {
+		return 0, "", errors.New("invalid claims") // Return error if the user ID or the email is missing
+	}
+
+	return int(userIdClaim), emailClaim, nil
+}
+
+// parseClaims validates the signing method, the signature, the time claims, the issuer and the audience
+// of the token and returns its claims. The signature is verified with the key of the key id of the token.
+func (js *jwtService) parseClaims(token string) (jwt.MapClaims, error) {
+	parser := jwt.Parser{SkipClaimsValidation: true} // The time claims are validated with the leeway below
+
+	t, err := parser.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != signingMethod { // Pin the signing method, so the algorithm of the header can't be chosen by the client
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 
-		return js.secretKey, nil // Return the secret key for validation
+		keyId := "" // The tokens without the kid header are verified with the key of the empty id
+		if kid, ok := token.Header["kid"]; ok {
+			if keyId, ok = kid.(string); !ok {
+				return nil, errUnknownKeyId
+			}
+		}
+
+		key, ok := js.verificationKeys[keyId]
+		if !ok {
+			return nil, errUnknownKeyId // The key was removed from the config or the token wasn't issued by the service
+		}
+
+		return key, nil // Return the secret key for validation
 	})
 	if err != nil {
-		return 0, 0, err // Return empty string if parsing fails
+		return nil, err // Return nil claims if parsing fails
 	}
 
 	if !t.Valid { // Check if the token is valid
-		return 0, 0, errors.New("invalid token") // Return error if invalid
+		return nil, errors.New("invalid token") // Return error if invalid
 	}
 
 	claims, ok := t.Claims.(jwt.MapClaims) // Retrieve claims from the parsed token
 	if !ok {
-		return 0, 0, errors.New("invalid claims") // Return error if claims are not valid
+		return nil, errors.New("invalid claims") // Return error if claims are not valid
+	}
+
+	if err := js.verifyTimeClaims(claims); err != nil {
+		return nil, err
+	}
+
+	if js.issuer != "" && !claims.VerifyIssuer(js.issuer, true) {
+		return nil, errInvalidIssuer // The token was issued by another service sharing the secret key
+	}
+	if js.audience != "" && !claims.VerifyAudience(js.audience, true) {
+		return nil, errInvalidAudience // The token was issued for another service
+	}
+
+	return claims, nil
+}
+
+// verifyTimeClaims validates the exp, nbf and iat claims of the token against the current time
+// widened by the clock skew leeway. The claims missing from the token aren't validated.
+func (js *jwtService) verifyTimeClaims(claims jwt.MapClaims) error {
+	now := js.now().Unix()
+	leeway := int64(js.clockSkewLeeway / time.Second)
+
+	if !claims.VerifyExpiresAt(now-leeway, false) {
+		return errTokenExpired
+	}
+	if !claims.VerifyNotBefore(now+leeway, false) {
+		return errTokenNotValidYet
+	}
+	if !claims.VerifyIssuedAt(now+leeway, false) {
+		return errTokenUsedBeforeIssued
+	}
+
+	return nil
+}
+
+// newToken creates a token signed by the pinned signing method carrying the claims
+// along with the issuer and the audience of the service, if they are configured,
+// and the key id of the secret key in the kid header.
+func (js *jwtService) newToken(claims jwt.MapClaims) *jwt.Token {
+	if js.issuer != "" {
+		claims["iss"] = js.issuer
+	}
+	if js.audience != "" {
+		claims["aud"] = js.audience
+	}
+
+	token := jwt.NewWithClaims(signingMethod, claims)
+	if js.keyId != "" {
+		token.Header["kid"] = js.keyId
+	}
+
+	return token
+}
+
+// claimsIDs retrieves the user ID and the session ID from the claims.
+func claimsIDs(claims jwt.MapClaims) (userId int, sessionId int, err error) {
+	userIdClaim, okUser := claims["user_id"].(float64)
+	sessionIdClaim, okSession := claims["session_id"].(float64)
+	if !okUser || !okSession {
+		return 0, 0, errors.New("invalid claims") // Return error if the IDs are missing
 	}
 
-	return int(claims["user_id"].(float64)), int(claims["session_id"].(float64)), nil // Return the user ID if successful
+	return int(userIdClaim), int(sessionIdClaim), nil // Return the IDs if successful
 }
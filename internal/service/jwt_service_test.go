@@ -0,0 +1,73 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"cvs/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestJwtServiceTokensExpire tests that the access and refresh tokens issued by the service are accepted
+// within their lifetimes and the leeway, and rejected once the clock moves past them.
+func TestJwtServiceTokensExpire(t *testing.T) {
+	t.Parallel()
+
+	issued := time.Now()
+
+	tests := []struct {
+		name        string                                               // Name of the test case
+		createToken func(js *jwtService) (string, error)                 // Issues the token through the service
+		parseToken  func(js *jwtService, token string) (int, int, error) // Parses the token of its type
+		lifetime    time.Duration                                        // Lifetime of the issued token
+	}{
+		{
+			name: "Access Token",
+			createToken: func(js *jwtService) (string, error) {
+				token, _, err := js.CreateAccessToken(1, 2, models.RoleUser)
+
+				return token, err
+			},
+			parseToken: (*jwtService).Parse,
+			lifetime:   2 * time.Hour,
+		},
+		{
+			name: "Refresh Token",
+			createToken: func(js *jwtService) (string, error) {
+				return js.CreateRefreshToken(1, 2)
+			},
+			parseToken: (*jwtService).ParseRefreshToken,
+			lifetime:   48 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, err := NewJwtService("secret_key", "v1", nil, 2, 48, "issuer", "audience", 30*time.Second)
+			assert.NoError(t, err)
+
+			js := service.(*jwtService)
+			js.now = func() time.Time { return issued }
+
+			token, err := tc.createToken(js)
+			assert.NoError(t, err)
+
+			js.now = func() time.Time { return issued.Add(tc.lifetime - time.Minute) }
+			_, _, err = tc.parseToken(js, token)
+			assert.NoError(t, err) // The token is still valid before its lifetime ends
+
+			js.now = func() time.Time { return issued.Add(tc.lifetime + 10*time.Second) }
+			_, _, err = tc.parseToken(js, token)
+			assert.NoError(t, err) // Expired within the leeway
+
+			js.now = func() time.Time { return issued.Add(tc.lifetime + time.Minute) }
+			_, _, err = tc.parseToken(js, token)
+			assert.ErrorIs(t, err, errTokenExpired)
+		})
+	}
+}
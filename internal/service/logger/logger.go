@@ -2,11 +2,13 @@ package logger
 
 import (
 	"cvs/internal/config"
+	"cvs/internal/service/metrics"
 	"os"
 	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // Logger defines the interface for logging.
@@ -93,11 +95,54 @@ func (l *apiLogger) getLoggerLevel(cfg *config.Config) zapcore.Level {
 	return level
 }
 
+// newLogWriter returns the destination of the logs.
+// The logs are appended to the rotated log file if its path is configured, otherwise they are written to stderr.
+func newLogWriter(cfg config.Logger) zapcore.WriteSyncer {
+	if cfg.FilePath == "" {
+		return zapcore.AddSync(os.Stderr)
+	}
+
+	return zapcore.AddSync(&lumberjack.Logger{
+		Filename:   cfg.FilePath,
+		MaxSize:    cfg.MaxSize,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAge,
+	})
+}
+
+// defaultSamplingTick is the interval the repeated logs are counted in when the sampling has no tick configured.
+const defaultSamplingTick = time.Second
+
+// newSampledCore wraps the core with the sampling of the logs with the same level and message, if it is configured.
+// The dropped logs are counted by level, so a flood of errors is visible in the metrics even when it isn't in the logs.
+func newSampledCore(core zapcore.Core, sampling config.LogSampling) zapcore.Core {
+	if sampling.Initial <= 0 {
+		return core // Every log is written
+	}
+
+	tick := sampling.Tick
+	if tick <= 0 {
+		tick = defaultSamplingTick
+	}
+
+	return zapcore.NewSamplerWithOptions(
+		core,
+		tick,
+		sampling.Initial,
+		max(sampling.Thereafter, 0),
+		zapcore.SamplerHook(func(entry zapcore.Entry, decision zapcore.SamplingDecision) {
+			if decision&zapcore.LogDropped != 0 {
+				metrics.IncLogsDropped(entry.Level.String())
+			}
+		}),
+	)
+}
+
 // InitLogger initializes the logger with settings from the configuration.
 func (l *apiLogger) InitLogger() {
 	logLevel := l.getLoggerLevel(l.cfg)
 
-	logWriter := zapcore.AddSync(os.Stderr)
+	logWriter := newLogWriter(l.cfg.Logger)
 
 	var encoderCfg zapcore.EncoderConfig
 	if l.cfg.ServerMode == "dev" {
@@ -121,6 +166,7 @@ func (l *apiLogger) InitLogger() {
 	}
 
 	core := zapcore.NewCore(encoder, logWriter, zap.NewAtomicLevelAt(logLevel))
+	core = newSampledCore(core, l.cfg.Logger.Sampling) // Drop the repetitions of the same logs
 	logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
 
 	l.sugarLogger = logger.Sugar()
@@ -0,0 +1,101 @@
+// Package metrics defines the Prometheus metrics of the scanner.
+//
+// All metrics are registered in the default Prometheus registry, which is exposed by the /metrics route.
+package metrics
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "cvs" // Prefix of the names of all metrics
+
+const (
+	resultSuccess = "success" // Result label of a successful fetch
+	resultFailure = "failure" // Result label of a failed fetch
+)
+
+var (
+	orderbookFetches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orderbook_fetches_total",
+		Help:      "Number of order book fetches from the exchange API by result.",
+	}, []string{"exchange", "result"})
+
+	orderbookFetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "orderbook_fetch_duration_seconds",
+		Help:      "Latency of order book fetches from the exchange API, including retries.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"exchange"})
+
+	volumesFound = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "volumes_found_total",
+		Help:      "Number of volumes found in the order books for the user.",
+	}, []string{"user_id"})
+
+	subscribedPairs = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "subscribed_pairs",
+		Help:      "Number of pairs of the exchange which are subscribed to by users.",
+	}, []string{"exchange"})
+
+	logsDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logs_dropped_total",
+		Help:      "Number of logs dropped by the sampling of the repeated logs by level.",
+	}, []string{"level"})
+
+	httpRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_request_retries_total",
+		Help:      "Number of retried HTTP requests by the requested host.",
+	}, []string{"host"})
+)
+
+// ObserveOrderbookFetch records the result and the latency of an order book fetch.
+//
+// Parameters:
+//   - exchangeName: The name of the exchange the order book was fetched from.
+//   - start: The time the fetch started.
+//   - success: Whether the fetch returned a usable order book.
+func ObserveOrderbookFetch(exchangeName string, start time.Time, success bool) {
+	result := resultSuccess
+	if !success {
+		result = resultFailure
+	}
+
+	orderbookFetches.WithLabelValues(exchangeName, result).Inc()
+	orderbookFetchDuration.WithLabelValues(exchangeName).Observe(time.Since(start).Seconds())
+}
+
+// AddVolumesFound adds the number of volumes found for the user.
+func AddVolumesFound(userID int, count int) {
+	volumesFound.WithLabelValues(strconv.Itoa(userID)).Add(float64(count))
+}
+
+// IncLogsDropped counts a log of the level dropped by the sampling.
+func IncLogsDropped(level string) {
+	logsDropped.WithLabelValues(level).Inc()
+}
+
+// SetSubscribedPairs sets the number of subscribed pairs of the exchange.
+func SetSubscribedPairs(exchangeName string, count int) {
+	subscribedPairs.WithLabelValues(exchangeName).Set(float64(count))
+}
+
+// ObserveHttpRetry records a retry of the request to the URL.
+// The retries are labeled by the host only, so the query parameters don't inflate the number of series.
+func ObserveHttpRetry(rawUrl string) {
+	host := rawUrl
+	if parsedUrl, err := url.Parse(rawUrl); err == nil && parsedUrl.Host != "" {
+		host = parsedUrl.Host
+	}
+
+	httpRetries.WithLabelValues(host).Inc()
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"cvs/internal/models"
+	"cvs/internal/service/logger"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Notifier defines the interface of a channel the found volumes are delivered to users through.
+type Notifier interface {
+	Notify(ctx context.Context, settings models.UserSettings, foundVolume models.FoundVolume) error // Method to deliver a found volume to the user
+}
+
+// NotificationService defines the interface for notifying users about found volumes
+// through the channels they configured in their settings.
+type NotificationService interface {
+	Consume(ctx context.Context, foundVolumes <-chan models.FoundVolume)    // Method to deliver the found volumes received from a subscription
+	Notify(ctx context.Context, userID int, foundVolume models.FoundVolume) // Method to deliver a found volume through every channel of the user
+}
+
+// notificationService is a concrete implementation of NotificationService.
+// It delivers the found volumes through every notifier, each of which skips the users who haven't configured it.
+type notificationService struct {
+	userSettingsService UserSettingsService // Service providing the channels configured by the user
+	notifiers           []Notifier          // Channels the found volumes are delivered through
+	logger              logger.Logger       // Logger of the failed deliveries
+	alertCooldown       time.Duration       // Time after an alert the same pair doesn't alert the user again, unless the user overrides it
+
+	lastAlertsMu    sync.Mutex           // Guards lastAlerts and lastAlertsSweep
+	lastAlerts      map[string]time.Time // Time of the last alert by the key of the user, exchange and pair
+	lastAlertsSweep time.Time            // Time of the last removal of the stale alerts
+}
+
+// lastAlertsSweepInterval is the time between the removals of the alerts whose cooldown has surely elapsed.
+const lastAlertsSweepInterval = time.Hour
+
+// NewNotificationService creates a new instance of notificationService.
+//
+// Parameters:
+//   - userSettingsService: Service providing the settings of the channels of users.
+//   - logger: The logger of the failed deliveries.
+//   - alertCooldown: The time after an alert during which the same pair doesn't alert the user again,
+//     used for the users who haven't set their own cooldown. Zero disables the cooldown.
+//   - notifiers: The channels the found volumes are delivered through.
+//
+// Returns:
+//   - An instance of NotificationService.
+func NewNotificationService(
+	userSettingsService UserSettingsService,
+	logger logger.Logger,
+	alertCooldown time.Duration,
+	notifiers ...Notifier,
+) NotificationService {
+	return &notificationService{
+		userSettingsService: userSettingsService,
+		notifiers:           notifiers,
+		logger:              logger,
+		alertCooldown:       max(alertCooldown, 0),
+		lastAlerts:          make(map[string]time.Time),
+	}
+}
+
+// Consume delivers the found volumes received from a subscription to the found volume events
+// until the subscription is cancelled or the context is done. The expired found volumes are skipped,
+// users are only notified about the new ones.
+//
+// Parameters:
+//   - ctx: The context for managing the lifetime of the deliveries.
+//   - foundVolumes: The channel of the found volumes of the users, e.g. subscribed to all users.
+func (ns *notificationService) Consume(ctx context.Context, foundVolumes <-chan models.FoundVolume) {
+	for {
+		select {
+		case foundVolume, ok := <-foundVolumes:
+			if !ok {
+				return
+			}
+
+			if foundVolume.Expired {
+				continue
+			}
+
+			ns.Notify(ctx, foundVolume.UserID, foundVolume)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Notify delivers the found volume through every notifier in the background,
+// so a slow channel of one user doesn't hold up the scanner. The failed deliveries are logged.
+//
+// Once the user is alerted about a pair of an exchange, the next found volumes of the pair are skipped
+// until the cooldown elapses, so a volume level which repeatedly appears and disappears alerts only once.
+// The cooldown of the user settings takes precedence over the cooldown of the service.
+//
+// Parameters:
+//   - ctx: The context for managing the lifetime of the deliveries.
+//   - userID: The ID of the user the volume was found for.
+//   - foundVolume: The found volume.
+func (ns *notificationService) Notify(ctx context.Context, userID int, foundVolume models.FoundVolume) {
+	if len(ns.notifiers) == 0 {
+		return
+	}
+
+	settings, err := ns.userSettingsService.GetSettings(ctx, userID)
+	if err != nil {
+		ns.logger.Error(err)
+
+		return
+	}
+
+	if !ns.startCooldown(userID, foundVolume, ns.cooldownOf(settings)) {
+		return // The user was alerted about the pair recently
+	}
+
+	for _, notifier := range ns.notifiers {
+		go func(notifier Notifier) {
+			if err := notifier.Notify(ctx, settings, foundVolume); err != nil {
+				ns.logger.Errorf("notifying user %d about the found volume of %s on %s: %v", userID, foundVolume.Pair, foundVolume.Exchange, err)
+			}
+		}(notifier)
+	}
+}
+
+// cooldownOf returns the alert cooldown of the user, which is the cooldown of the service unless the user set one.
+func (ns *notificationService) cooldownOf(settings models.UserSettings) time.Duration {
+	if settings.AlertCooldownSeconds > 0 {
+		return time.Duration(settings.AlertCooldownSeconds) * time.Second
+	}
+
+	return ns.alertCooldown
+}
+
+// startCooldown reports whether the user can be alerted about the pair of the found volume and, if so,
+// records the alert, so the pair doesn't alert the user again until the cooldown elapses.
+// The records older than the longest cooldown are removed once in a while, so the map doesn't grow with stale pairs.
+//
+// Parameters:
+//   - userID: The ID of the user the volume was found for.
+//   - foundVolume: The found volume, whose exchange and pair identify the alert.
+//   - cooldown: The cooldown of the user, zero alerts about every found volume.
+//
+// Returns:
+//   - true if the user is alerted.
+func (ns *notificationService) startCooldown(userID int, foundVolume models.FoundVolume, cooldown time.Duration) bool {
+	if cooldown <= 0 {
+		return true
+	}
+
+	key := strconv.Itoa(userID) + foundVolumeKeyDelimiter + foundVolume.Exchange + foundVolumeKeyDelimiter + foundVolume.Pair
+	now := time.Now()
+
+	ns.lastAlertsMu.Lock()
+	defer ns.lastAlertsMu.Unlock()
+
+	if lastAlert, ok := ns.lastAlerts[key]; ok && now.Sub(lastAlert) < cooldown {
+		return false
+	}
+
+	if now.Sub(ns.lastAlertsSweep) >= lastAlertsSweepInterval {
+		for staleKey, lastAlert := range ns.lastAlerts {
+			if now.Sub(lastAlert) >= max(maxAlertCooldownSeconds*time.Second, ns.alertCooldown) {
+				delete(ns.lastAlerts, staleKey) // No cooldown is longer, so the record can't suppress an alert anymore
+			}
+		}
+
+		ns.lastAlertsSweep = now
+	}
+
+	ns.lastAlerts[key] = now
+
+	return true
+}
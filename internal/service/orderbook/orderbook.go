@@ -1,8 +1,11 @@
 package orderbook
 
 import (
+	"cmp"
 	"cvs/internal/models"
 	"fmt"
+	"math"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -14,16 +17,26 @@ import (
 // Orderbook defines the interface for managing an order book.
 // It includes methods for retrieving asks and bids, upserting data, and searching for volumes.
 type Orderbook interface {
-	Asks(pair string) map[string]interface{}                                 // Method to retrieve all ask orders for a given pair
-	Bids(pair string) map[string]interface{}                                 // Method to retrieve all bid orders for a given pair
-	Upsert(pair string, asks, bids [][]interface{})                          // Method to update or insert ask and bid orders
-	SearchVolume(pair, exchange string, search float64) []models.FoundVolume // Method to search for volumes based on a specified value
+	Asks(pair string) map[string]interface{}                                                          // Method to retrieve all ask orders for a given pair
+	Bids(pair string) map[string]interface{}                                                          // Method to retrieve all bid orders for a given pair
+	Upsert(pair string, asks, bids [][]interface{})                                                   // Method to update or insert ask and bid orders
+	ApplyDelta(pair string, askUpdates, bidUpdates [][]interface{})                                   // Method to apply incremental updates of ask and bid orders
+	SearchVolume(pair, exchange string, search, tolerance, floor float64) []models.FoundVolume        // Method to search for volumes based on a specified value
+	SearchVolumeRelative(pair, exchange string, multiplier float64, window int) []models.FoundVolume  // Method to search for volumes standing out from the surrounding levels
+	SearchCumulativeVolume(pair, exchange string, threshold, priceRange float64) []models.FoundVolume // Method to search for volumes spread across the adjacent levels of a price band
+	BestPrices(pair string) (models.PriceSnapshot, bool)                                              // Method to get the best prices, the spread and the mid price of a pair
+	Snapshot(pair string, depth int) (models.OrderbookSnapshot, bool)                                 // Method to get the price levels of a pair sorted by price
+	Retain(pair, holder string)                                                                       // Method to register a holder subscribed to the order book of a pair
+	Release(pair, holder string)                                                                      // Method to drop a holder of a pair, removing the book after the last one
+	EvictStale(maxAge time.Duration) int                                                              // Method to remove the order books which weren't updated for longer than the age
 }
 
 // orderbook is a concrete implementation of the Orderbook interface.
 // It holds a concurrent map to store order book data by pairs.
 type orderbook struct {
-	cmap.ConcurrentMap[string, orderbookData] // Concurrent map storing order book data by pair
+	cmap.ConcurrentMap[string, orderbookData]                            // Concurrent map storing order book data by pair
+	holders                                   map[string]map[string]bool // Holders subscribed to the order book by pair, e.g. the sections of an exchange
+	holdersMu                                 sync.Mutex                 // Mutex guarding the holders
 }
 
 // orderbookData holds the details of an order book entry.
@@ -36,6 +49,45 @@ type orderbookData struct {
 	bidsSortedByVolume []models.FoundVolume                    // Sorted list of bids by volume
 	asksSortedByPrice  []models.FoundVolume                    // Sorted list of asks by price
 	bidsSortedByPrice  []models.FoundVolume                    // Sorted list of bids by price
+	updatedAt          time.Time                               // Time of the last snapshot or delta applied to the book
+}
+
+// bestAsk returns the lowest ask price, the asks are sorted by price ascending.
+// It returns zero if there are no asks.
+func (d orderbookData) bestAsk() float64 {
+	if len(d.asksSortedByPrice) == 0 {
+		return 0
+	}
+
+	return d.asksSortedByPrice[0].Price
+}
+
+// bestBid returns the highest bid price, the bids are sorted by price ascending as well, so it is the last one.
+// It returns zero if there are no bids.
+func (d orderbookData) bestBid() float64 {
+	if len(d.bidsSortedByPrice) == 0 {
+		return 0
+	}
+
+	return d.bidsSortedByPrice[len(d.bidsSortedByPrice)-1].Price
+}
+
+// percentDistance calculates how far the price is from the best price of its side in percent of the best price.
+// The distance is non-negative for both sides, since an ask is never below the best ask
+// and a bid is never above the best bid, so the distances of the asks and the bids are comparable.
+//
+// Parameters:
+//   - price: The price of the found volume.
+//   - best: The best price of the side of the found volume.
+//
+// Returns:
+//   - The distance in percent, zero if the best price is unknown.
+func percentDistance(price, best float64) float64 {
+	if best == 0 {
+		return 0
+	}
+
+	return math.Abs(price-best) / best * 100
 }
 
 // sortedSlice holds two slices of FoundVolume sorted by volume and price.
@@ -48,7 +100,8 @@ type sortedSlice struct {
 // It initializes the concurrent map for storing order book data.
 func NewOrderbook() Orderbook {
 	level2Data := &orderbook{
-		cmap.New[orderbookData](), // Initialize the concurrent map for order book data
+		ConcurrentMap: cmap.New[orderbookData](),        // Initialize the concurrent map for order book data
+		holders:       make(map[string]map[string]bool), // Initialize the map for the holders of the books
 	}
 
 	return level2Data // Return the new orderbook instance
@@ -76,9 +129,10 @@ func (o *orderbook) Upsert(pair string, asks, bids [][]interface{}) {
 	o.Remove(pair) // Remove any existing data for the specified pair
 
 	level2Data := orderbookData{
-		Pair: pair,
-		asks: cmap.New[interface{}](), // Initialize concurrent map for asks
-		bids: cmap.New[interface{}](), // Initialize concurrent map for bids
+		Pair:      pair,
+		asks:      cmap.New[interface{}](), // Initialize concurrent map for asks
+		bids:      cmap.New[interface{}](), // Initialize concurrent map for bids
+		updatedAt: time.Now(),
 	}
 
 	wg.Add(2) // Prepare to wait for two goroutines
@@ -115,9 +169,127 @@ func (o *orderbook) Upsert(pair string, asks, bids [][]interface{}) {
 	o.Set(pair, level2Data) // Store the updated level2Data in the main order book structure
 }
 
+// ApplyDelta applies incremental updates of ask and bid orders to the order book of a trading pair.
+// Unlike Upsert, which replaces the whole book with a full snapshot, only the updated price levels
+// are changed, and the sorted slices are kept sorted by moving the changed levels only.
+//
+// Every update is a price and a volume pair. A level with a zero volume is removed, any other
+// volume replaces the volume of the level or adds it. If there is no book of the pair yet,
+// the updates are applied to an empty one.
+//
+// Parameters:
+//   - pair: The trading pair whose order book is updated.
+//   - askUpdates: The updated ask levels.
+//   - bidUpdates: The updated bid levels.
+func (o *orderbook) ApplyDelta(pair string, askUpdates, bidUpdates [][]interface{}) {
+	o.ConcurrentMap.Upsert(pair, orderbookData{}, func(exist bool, level2Data, _ orderbookData) orderbookData {
+		if !exist {
+			level2Data = orderbookData{
+				Pair: pair,
+				asks: cmap.New[interface{}](), // Initialize concurrent map for asks
+				bids: cmap.New[interface{}](), // Initialize concurrent map for bids
+			}
+		}
+
+		// The slices may be read by a running search, so they are copied before being changed
+		level2Data.asksSortedByPrice, level2Data.asksSortedByVolume = applySideDelta(
+			level2Data.asks,
+			slices.Clone(level2Data.asksSortedByPrice),
+			slices.Clone(level2Data.asksSortedByVolume),
+			askUpdates,
+		)
+		level2Data.bidsSortedByPrice, level2Data.bidsSortedByVolume = applySideDelta(
+			level2Data.bids,
+			slices.Clone(level2Data.bidsSortedByPrice),
+			slices.Clone(level2Data.bidsSortedByVolume),
+			bidUpdates,
+		)
+		level2Data.updatedAt = time.Now()
+
+		return level2Data // Store the updated level2Data in the main order book structure
+	})
+}
+
+// Retain registers the holder as subscribed to the order book of the pair, so the book isn't removed
+// by Release while the holder still uses it. Retaining a pair the holder already holds has no effect.
+//
+// Parameters:
+//   - pair: The trading pair whose order book is held.
+//   - holder: The name of the holder, e.g. the name of the exchange section sharing the order book service.
+func (o *orderbook) Retain(pair, holder string) {
+	o.holdersMu.Lock()
+	defer o.holdersMu.Unlock()
+
+	if o.holders[pair] == nil {
+		o.holders[pair] = make(map[string]bool, 1)
+	}
+
+	o.holders[pair][holder] = true
+}
+
+// Release drops the holder of the order book of the pair. Once the last holder is dropped,
+// the book is removed, so the books of the pairs nobody subscribes to anymore don't stay in memory.
+// Releasing a pair the holder doesn't hold has no effect.
+//
+// Parameters:
+//   - pair: The trading pair whose order book is released.
+//   - holder: The name of the holder passed to Retain.
+func (o *orderbook) Release(pair, holder string) {
+	o.holdersMu.Lock()
+	defer o.holdersMu.Unlock()
+
+	if !o.holders[pair][holder] {
+		return // Nothing to release
+	}
+
+	delete(o.holders[pair], holder)
+	if len(o.holders[pair]) > 0 {
+		return // Another holder still reads the book
+	}
+
+	delete(o.holders, pair)
+	o.Remove(pair) // The last holder is dropped, so nobody reads the book anymore
+}
+
+// EvictStale removes the order books which weren't updated for longer than the maximum age,
+// e.g. the books of pairs whose requests keep failing or which were fetched once more after
+// they were released. A stale book is removed regardless of its holders, the next update of
+// a held pair stores it again.
+//
+// Parameters:
+//   - maxAge: The time a book is kept without being updated.
+//
+// Returns:
+//   - The number of removed order books.
+func (o *orderbook) EvictStale(maxAge time.Duration) int {
+	cutoff := time.Now().Add(-maxAge)
+	evicted := 0
+
+	for _, pair := range o.Keys() {
+		removed := o.RemoveCb(pair, func(_ string, level2Data orderbookData, exists bool) bool {
+			return exists && level2Data.updatedAt.Before(cutoff) // A book updated concurrently is kept
+		})
+		if removed {
+			evicted++
+		}
+	}
+
+	return evicted
+}
+
 // SearchVolume retrieves found volumes based on a specified search value.
 // It searches both asks and bids concurrently.
-func (o *orderbook) SearchVolume(pair, exchange string, search float64) []models.FoundVolume {
+//
+// With a zero tolerance the first level whose volume is at least the search value is found.
+// Otherwise a level is found only if its volume is within the tolerance percent of the search value
+// on either side, and the smallest such volume is returned.
+//
+// The levels whose volume is below the floor are never found, so the dust levels of small books
+// don't match a low search value. A zero floor doesn't ignore any level.
+//
+// The difference of a found volume is its non-negative distance from the best price of its side
+// in percent of the best price, i.e. from the lowest ask for the asks and from the highest bid for the bids.
+func (o *orderbook) SearchVolume(pair, exchange string, search, tolerance, floor float64) []models.FoundVolume {
 	var volumes []models.FoundVolume // Slice to hold found volumes results
 	level2Data, exist := o.Get(pair) // Get the order book data for the specified pair
 	if !exist {                      // Check if data exists for the pair
@@ -127,18 +299,25 @@ func (o *orderbook) SearchVolume(pair, exchange string, search float64) []models
 	asksSlice := level2Data.asksSortedByVolume // Get sorted asks by volume from level2Data
 	bidsSlice := level2Data.bidsSortedByVolume // Get sorted bids by volume from level2Data
 
-	var wg sync.WaitGroup // WaitGroup to synchronize goroutines
+	minVolume, maxVolume := search, math.Inf(1) // Without a tolerance every volume above the search value matches
+	if tolerance > 0 {
+		minVolume = search * (1 - tolerance/100)
+		maxVolume = search * (1 + tolerance/100)
+	}
+
+	minVolume = max(minVolume, floor) // Ignore the dust levels regardless of the search value
+
+	var wg sync.WaitGroup                         // WaitGroup to synchronize goroutines
+	var asksVolume, bidsVolume models.FoundVolume // Found volumes of each side, written by their own goroutine
 
 	wg.Add(2) // Prepare to wait for two goroutines
 
 	go func() {
 		defer wg.Done() // Decrement WaitGroup counter when done
 
-		foundVolumeData := binarySearch(pair, asksSlice, search) // Perform binary search on asks slice
-		if foundVolumeData.Price != 0 {                          // Check if found volume has a valid price
-			percentDistance := (foundVolumeData.Price - level2Data.asksSortedByPrice[0].Price) / foundVolumeData.Price * 100 // Calculate percentage distance from first ask price
-
-			foundVolumeData.Difference = percentDistance // Store calculated difference in found volume data
+		foundVolumeData := rangeSearch(pair, asksSlice, minVolume, maxVolume) // Perform binary search on asks slice
+		if foundVolumeData.Price != 0 {                                       // Check if found volume has a valid price
+			foundVolumeData.Difference = percentDistance(foundVolumeData.Price, level2Data.bestAsk()) // Store the distance from the best ask
 			foundVolumeData.VolumeTimeFound = time.Now()
 		}
 
@@ -146,16 +325,14 @@ func (o *orderbook) SearchVolume(pair, exchange string, search float64) []models
 		foundVolumeData.Pair = pair
 		foundVolumeData.Exchange = exchange
 
-		volumes = append(volumes, foundVolumeData) // Append found volume data to results
+		asksVolume = foundVolumeData
 	}()
 	go func() {
 		defer wg.Done() // Decrement WaitGroup counter when done
 
-		foundVolumeData := binarySearch(pair, bidsSlice, search) // Perform binary search on bids slice
-		if foundVolumeData.Price != 0 {                          // Check if found volume has a valid price
-			percentDistance := (level2Data.bidsSortedByPrice[len(level2Data.bidsSortedByPrice)-1].Price - foundVolumeData.Price) / level2Data.bidsSortedByPrice[len(level2Data.bidsSortedByPrice)-1].Price * 100 // Calculate percentage distance from last bid price
-
-			foundVolumeData.Difference = percentDistance // Store calculated difference in found volume data
+		foundVolumeData := rangeSearch(pair, bidsSlice, minVolume, maxVolume) // Perform binary search on bids slice
+		if foundVolumeData.Price != 0 {                                       // Check if found volume has a valid price
+			foundVolumeData.Difference = percentDistance(foundVolumeData.Price, level2Data.bestBid()) // Store the distance from the best bid
 			foundVolumeData.VolumeTimeFound = time.Now()
 		}
 
@@ -163,14 +340,172 @@ func (o *orderbook) SearchVolume(pair, exchange string, search float64) []models
 		foundVolumeData.Pair = pair
 		foundVolumeData.Exchange = exchange
 
-		volumes = append(volumes, foundVolumeData) // Append found volume data to results
+		bidsVolume = foundVolumeData
 	}()
 
 	wg.Wait() // Wait for both goroutines to finish
 
+	volumes = append(volumes, asksVolume, bidsVolume) // Append found volume data to results
+
 	return volumes // Return all found volumes retrieved
 }
 
+// SearchVolumeRelative retrieves the volumes which stand out from the surrounding price levels.
+// A level is found if its volume exceeds the median volume of up to window levels on each side
+// of it by more than multiplier times. For every side the level with the largest excess is returned.
+//
+// Parameters:
+//   - pair: The trading pair whose order book is searched.
+//   - exchange: The name of the exchange, set to the found volumes.
+//   - multiplier: How many times the volume of a level must exceed the median of its neighbours.
+//   - window: The number of neighbour levels on each side of a level compared with it.
+//
+// Returns:
+//   - The found volumes of both sides. The price of a side's volume is zero if nothing was found,
+//     as it is done by SearchVolume.
+func (o *orderbook) SearchVolumeRelative(pair, exchange string, multiplier float64, window int) []models.FoundVolume {
+	var volumes []models.FoundVolume // Slice to hold found volumes results
+	level2Data, exist := o.Get(pair) // Get the order book data for the specified pair
+	if !exist {                      // Check if data exists for the pair
+		return volumes // Return empty slice if not found
+	}
+
+	asksVolume := relativeSearch(level2Data.asksSortedByPrice, multiplier, window) // Search the asks sorted by price
+	if asksVolume.Price != 0 {
+		asksVolume.Difference = percentDistance(asksVolume.Price, level2Data.bestAsk()) // Store the distance from the best ask
+		asksVolume.VolumeTimeFound = time.Now()
+	}
+	asksVolume.Side = "asks"
+	asksVolume.Pair = pair
+	asksVolume.Exchange = exchange
+
+	bidsVolume := relativeSearch(level2Data.bidsSortedByPrice, multiplier, window) // Search the bids sorted by price
+	if bidsVolume.Price != 0 {
+		bidsVolume.Difference = percentDistance(bidsVolume.Price, level2Data.bestBid()) // Store the distance from the best bid
+		bidsVolume.VolumeTimeFound = time.Now()
+	}
+	bidsVolume.Side = "bids"
+	bidsVolume.Pair = pair
+	bidsVolume.Exchange = exchange
+
+	return append(volumes, asksVolume, bidsVolume)
+}
+
+// SearchCumulativeVolume retrieves the walls spread across several adjacent price levels.
+// The volumes of the levels within a price band are summed, and a band is found if its total
+// is at least the threshold. A band starts at a level and spans the levels whose price is at most
+// priceRange percent above it. For every side the band with the largest total is returned.
+//
+// Parameters:
+//   - pair: The trading pair whose order book is searched.
+//   - exchange: The name of the exchange, set to the found volumes.
+//   - threshold: The total volume of the levels of a band at which it is found.
+//   - priceRange: The width of a band in percent of the price of its lowest level, zero sums only the levels of the same price.
+//
+// Returns:
+//   - The found volumes of both sides. The volume of a found band is its total, its price is the price of its largest level
+//     and the band low and high are the prices of its lowest and highest levels. The price of a side's volume is zero
+//     if nothing was found, as it is done by SearchVolume.
+func (o *orderbook) SearchCumulativeVolume(pair, exchange string, threshold, priceRange float64) []models.FoundVolume {
+	var volumes []models.FoundVolume // Slice to hold found volumes results
+	level2Data, exist := o.Get(pair) // Get the order book data for the specified pair
+	if !exist {                      // Check if data exists for the pair
+		return volumes // Return empty slice if not found
+	}
+
+	asksVolume := cumulativeSearch(level2Data.asksSortedByPrice, threshold, priceRange) // Search the asks sorted by price
+	if asksVolume.Price != 0 {
+		asksVolume.Difference = percentDistance(asksVolume.Price, level2Data.bestAsk()) // Store the distance from the best ask
+		asksVolume.VolumeTimeFound = time.Now()
+	}
+	asksVolume.Side = "asks"
+	asksVolume.Pair = pair
+	asksVolume.Exchange = exchange
+
+	bidsVolume := cumulativeSearch(level2Data.bidsSortedByPrice, threshold, priceRange) // Search the bids sorted by price
+	if bidsVolume.Price != 0 {
+		bidsVolume.Difference = percentDistance(bidsVolume.Price, level2Data.bestBid()) // Store the distance from the best bid
+		bidsVolume.VolumeTimeFound = time.Now()
+	}
+	bidsVolume.Side = "bids"
+	bidsVolume.Pair = pair
+	bidsVolume.Exchange = exchange
+
+	return append(volumes, asksVolume, bidsVolume)
+}
+
+// BestPrices calculates the top of the order book of a trading pair.
+// The best bid is the highest bid price and the best ask is the lowest ask price.
+//
+// Parameters:
+//   - pair: The trading pair whose order book is used.
+//
+// Returns:
+//   - The best bid and ask prices with the absolute and percentage spread and the mid price.
+//     For a one-sided book only the price of the present side is set.
+//   - false if there is no order book data for the pair or both of its sides are empty.
+func (o *orderbook) BestPrices(pair string) (models.PriceSnapshot, bool) {
+	snapshot := models.PriceSnapshot{Pair: pair}
+
+	level2Data, exist := o.Get(pair) // Get the order book data for the specified pair
+	if !exist {
+		return snapshot, false
+	}
+
+	asks, bids := level2Data.asksSortedByPrice, level2Data.bidsSortedByPrice
+	if len(asks) == 0 && len(bids) == 0 {
+		return snapshot, false
+	}
+
+	snapshot.BestAsk = level2Data.bestAsk() // Zero for a book without asks
+	snapshot.BestBid = level2Data.bestBid() // Zero for a book without bids
+
+	if snapshot.BestAsk > 0 && snapshot.BestBid > 0 { // The spread is only defined for a two-sided book
+		snapshot.Spread = snapshot.BestAsk - snapshot.BestBid
+		snapshot.Mid = (snapshot.BestAsk + snapshot.BestBid) / 2
+		snapshot.SpreadPercent = snapshot.Spread / snapshot.Mid * 100
+	}
+
+	return snapshot, true
+}
+
+// Snapshot returns the price levels of the order book of a trading pair.
+// Both sides start with the best price, the asks are sorted by price ascending and the bids descending.
+//
+// Parameters:
+//   - pair: The trading pair whose order book is returned.
+//   - depth: The maximum number of levels of each side, all levels are returned if it isn't positive.
+//
+// Returns:
+//   - The price levels of the asks and the bids of the pair.
+//   - false if there is no order book data for the pair or both of its sides are empty.
+func (o *orderbook) Snapshot(pair string, depth int) (models.OrderbookSnapshot, bool) {
+	snapshot := models.OrderbookSnapshot{
+		Pair: pair,
+		Asks: []models.OrderbookLevel{},
+		Bids: []models.OrderbookLevel{},
+	}
+
+	level2Data, exist := o.Get(pair) // Get the order book data for the specified pair
+	if !exist {
+		return snapshot, false
+	}
+
+	asks, bids := level2Data.asksSortedByPrice, level2Data.bidsSortedByPrice
+	if len(asks) == 0 && len(bids) == 0 {
+		return snapshot, false
+	}
+
+	for i := 0; i < len(asks) && (depth <= 0 || i < depth); i++ { // The lowest ask is the first one
+		snapshot.Asks = append(snapshot.Asks, models.OrderbookLevel{Price: asks[i].Price, Volume: asks[i].Volume})
+	}
+	for i := len(bids) - 1; i >= 0 && (depth <= 0 || len(bids)-1-i < depth); i-- { // The highest bid is the last one
+		snapshot.Bids = append(snapshot.Bids, models.OrderbookLevel{Price: bids[i].Price, Volume: bids[i].Volume})
+	}
+
+	return snapshot, true
+}
+
 // sortHashMap sorts a hashmap of interface values into slices sorted by volume and price.
 // It returns a sortedSlice containing both sorted slices.
 //
@@ -234,31 +569,231 @@ func sortHashMap(hashmap map[string]interface{}) sortedSlice {
 	}
 }
 
-// binarySearch performs a binary search on a slice of FoundVolumes to find a volume matching the search criteria.
-// It returns the FoundVolume that matches or is closest to the specified search value.
+// applySideDelta applies the updates of one side of the order book to its levels and sorted slices.
+// A changed level is removed from both slices and inserted back at the position of its new volume,
+// so the slices stay sorted without sorting them again.
+//
+// Parameters:
+//   - levels: The levels of the side by price, changed in place.
+//   - sortedByPrice: The levels of the side sorted by price, changed in place.
+//   - sortedByVolume: The levels of the side sorted by volume, changed in place.
+//   - updates: The price and volume pairs to apply, a zero volume removes the level.
+//
+// Returns:
+//   - The updated slices sorted by price and by volume.
+func applySideDelta(
+	levels cmap.ConcurrentMap[string, interface{}],
+	sortedByPrice, sortedByVolume []models.FoundVolume,
+	updates [][]interface{},
+) ([]models.FoundVolume, []models.FoundVolume) {
+	for _, update := range updates {
+		if len(update) < 2 {
+			continue
+		}
+
+		price := cast.ToFloat64(update[0])
+		volume := cast.ToFloat64(update[1])
+
+		// Find the position of the price, which is also where a new level is inserted
+		priceIndex, found := slices.BinarySearchFunc(sortedByPrice, price, func(level models.FoundVolume, price float64) int {
+			return cmp.Compare(level.Price, price)
+		})
+		if found {
+			sortedByVolume = removeByVolume(sortedByVolume, sortedByPrice[priceIndex])
+			sortedByPrice = slices.Delete(sortedByPrice, priceIndex, priceIndex+1)
+		}
+
+		if volume == 0 { // A zero volume means the level was removed
+			levels.Remove(fmt.Sprintf("%v", update[0]))
+
+			continue
+		}
+
+		levels.Set(fmt.Sprintf("%v", update[0]), update[1])
+
+		level := models.FoundVolume{Price: price, Volume: volume}
+		sortedByPrice = slices.Insert(sortedByPrice, priceIndex, level)
+
+		// Insert the level after the levels of the same volume, as a stable sort would place it
+		volumeIndex := sort.Search(len(sortedByVolume), func(i int) bool {
+			return sortedByVolume[i].Volume > volume
+		})
+		sortedByVolume = slices.Insert(sortedByVolume, volumeIndex, level)
+	}
+
+	return sortedByPrice, sortedByVolume
+}
+
+// removeByVolume removes the level of the same price from a slice sorted by volume.
+// The levels of the same volume aren't ordered by price, so they are checked one by one.
+func removeByVolume(sortedByVolume []models.FoundVolume, level models.FoundVolume) []models.FoundVolume {
+	index := sort.Search(len(sortedByVolume), func(i int) bool {
+		return sortedByVolume[i].Volume >= level.Volume
+	})
+
+	for ; index < len(sortedByVolume) && sortedByVolume[index].Volume == level.Volume; index++ {
+		if sortedByVolume[index].Price == level.Price {
+			return slices.Delete(sortedByVolume, index, index+1)
+		}
+	}
+
+	return sortedByVolume
+}
+
+// binarySearch performs a lower-bound binary search on a slice of FoundVolumes sorted by volume.
+// It returns the first FoundVolume whose volume is greater than or equal to the search value,
+// which is the smallest volume that satisfies the search.
 //
 // Parameters:
 //   - pair: The trading pair being searched (not used in this implementation but could be relevant for logging or context).
-//   - slice: A slice of FoundVolume objects sorted by volume.
+//   - slice: A slice of FoundVolume objects sorted by volume in ascending order.
 //   - search: The volume value to search for in the slice.
 //
 // Returns:
-//   - A FoundVolume object that matches the search criteria.
+//   - The first FoundVolume with a volume not less than the search value, or an empty FoundVolume
+//     if the slice is empty or every volume is less than the search value.
 func binarySearch(pair string, slice []models.FoundVolume, search float64) models.FoundVolume {
-	mid := len(slice) / 2                  // Calculate the midpoint index of the slice.
-	var foundVolumeData models.FoundVolume // Variable to hold the found volume data.
-
-	switch { // Determine which case to execute based on the length of the slice and the value at the midpoint.
-	case len(slice) == 0: // Base case: If the slice is empty,
-		foundVolumeData = models.FoundVolume{} // Return an empty FoundVolume.
-	case slice[mid].Volume >= search: // If the volume at the midpoint is greater than or equal to the search value,
-		foundVolumeData = slice[mid] // Set foundVolumeData to the midpoint volume (potential match).
-	case slice[mid].Volume < search: // If the volume at the midpoint is less than the search value,
-		// Recursively search in the right half of the slice (elements after mid).
-		foundVolumeData = binarySearch(pair, slice[mid+1:], search)
-	default: // This case handles any unexpected scenarios (though it should not be reached).
-		foundVolumeData = slice[mid] // Fallback to returning the midpoint volume.
-	}
-
-	return foundVolumeData // Return the found volume data (or an empty FoundVolume if not found).
+	low, high := 0, len(slice) // The answer index always stays within [low, high]
+
+	for low < high {
+		mid := low + (high-low)/2 // Calculate the midpoint index of the current range
+
+		if slice[mid].Volume < search { // The midpoint and everything before it are too small
+			low = mid + 1
+		} else { // The midpoint matches, but an earlier element may match as well
+			high = mid
+		}
+	}
+
+	if low == len(slice) { // Every volume is less than the search value
+		return models.FoundVolume{}
+	}
+
+	return slice[low] // Return the first volume which is not less than the search value
+}
+
+// rangeSearch finds the smallest volume between minVolume and maxVolume inclusive.
+//
+// Parameters:
+//   - pair: The trading pair whose volumes are searched.
+//   - slice: A slice of FoundVolume objects sorted by volume.
+//   - minVolume: The smallest volume which matches.
+//   - maxVolume: The largest volume which matches.
+//
+// Returns:
+//   - The first volume within the range, or an empty FoundVolume if there is no such volume.
+func rangeSearch(pair string, slice []models.FoundVolume, minVolume, maxVolume float64) models.FoundVolume {
+	foundVolume := binarySearch(pair, slice, minVolume)
+	if foundVolume.Volume > maxVolume { // The smallest large enough volume is already too large
+		return models.FoundVolume{}
+	}
+
+	return foundVolume
+}
+
+// relativeSearch finds the level whose volume exceeds the median volume of its neighbours the most.
+//
+// Parameters:
+//   - sortedByPrice: A slice of FoundVolume objects sorted by price, so the neighbours of a level are the nearest prices.
+//   - multiplier: How many times the volume of a level must exceed the median of its neighbours.
+//   - window: The number of neighbour levels on each side of a level compared with it.
+//
+// Returns:
+//   - The level with the largest ratio of its volume to the median of its neighbours among the levels
+//     whose ratio is above the multiplier, or an empty FoundVolume if there is no such level.
+func relativeSearch(sortedByPrice []models.FoundVolume, multiplier float64, window int) models.FoundVolume {
+	var (
+		found     models.FoundVolume // The level standing out the most
+		bestRatio float64            // Ratio of the found level volume to the median of its neighbours
+	)
+
+	if window < 1 {
+		return found
+	}
+
+	neighbours := make([]float64, 0, 2*window) // Buffer reused for the volumes of the neighbours
+
+	for i, level := range sortedByPrice {
+		neighbours = neighbours[:0]
+		for j := max(i-window, 0); j <= min(i+window, len(sortedByPrice)-1); j++ {
+			if j != i {
+				neighbours = append(neighbours, sortedByPrice[j].Volume)
+			}
+		}
+
+		median := medianOf(neighbours)
+		if median <= 0 {
+			continue // A level without neighbours or among empty ones can't be compared
+		}
+
+		if ratio := level.Volume / median; ratio > multiplier && ratio > bestRatio {
+			found, bestRatio = level, ratio
+		}
+	}
+
+	return found
+}
+
+// cumulativeSearch finds the price band whose levels have the largest total volume of at least the threshold.
+// The band is slid over the levels, so every level is added and removed from the total once.
+//
+// Parameters:
+//   - sortedByPrice: A slice of FoundVolume objects sorted by price ascending, so the levels of a band are adjacent.
+//   - threshold: The total volume of the levels of a band at which it is found.
+//   - priceRange: The width of a band in percent of the price of its lowest level.
+//
+// Returns:
+//   - The largest level of the found band with the total volume of the band and its lowest and highest prices,
+//     or an empty FoundVolume if no band reaches the threshold.
+func cumulativeSearch(sortedByPrice []models.FoundVolume, threshold, priceRange float64) models.FoundVolume {
+	var (
+		found      models.FoundVolume // The largest level of the found band
+		bestTotal  float64            // Total volume of the found band
+		start      int                // Index of the lowest level of the current band
+		total      float64            // Total volume of the current band
+		widthRatio = 1 + max(priceRange, 0)/100
+	)
+
+	for end, level := range sortedByPrice {
+		total += level.Volume
+
+		for level.Price > sortedByPrice[start].Price*widthRatio { // Drop the levels the band no longer reaches
+			total -= sortedByPrice[start].Volume
+			start++
+		}
+
+		if total < threshold || total <= bestTotal {
+			continue
+		}
+
+		largest := sortedByPrice[start]
+		for _, bandLevel := range sortedByPrice[start+1 : end+1] {
+			if bandLevel.Volume > largest.Volume {
+				largest = bandLevel
+			}
+		}
+
+		found, bestTotal = largest, total
+		found.Volume = total
+		found.BandLow = sortedByPrice[start].Price
+		found.BandHigh = level.Price
+	}
+
+	return found
+}
+
+// medianOf returns the median of the values, or zero if there are none. The values are sorted in place.
+func medianOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sort.Float64s(values)
+
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[middle-1] + values[middle]) / 2
+	}
+
+	return values[middle]
 }
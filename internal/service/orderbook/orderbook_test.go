@@ -0,0 +1,318 @@
+package orderbook
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"cvs/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBinarySearch tests that binarySearch returns the first volume which is not less than the search value.
+func TestBinarySearch(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	sortedByVolume := []models.FoundVolume{
+		{Price: 100, Volume: 1},
+		{Price: 101, Volume: 3},
+		{Price: 102, Volume: 3},
+		{Price: 103, Volume: 5},
+		{Price: 104, Volume: 8},
+		{Price: 105, Volume: 13},
+	}
+
+	tests := []struct {
+		name     string               // Name of the test case
+		slice    []models.FoundVolume // Slice sorted by volume
+		search   float64              // Volume to search for
+		expected models.FoundVolume   // Expected found volume
+	}{
+		{
+			name:     "Empty slice",
+			slice:    []models.FoundVolume{},
+			search:   1,
+			expected: models.FoundVolume{},
+		},
+		{
+			name:     "Search smaller than all",
+			slice:    sortedByVolume,
+			search:   0.5,
+			expected: models.FoundVolume{Price: 100, Volume: 1},
+		},
+		{
+			name:     "Search larger than all",
+			slice:    sortedByVolume,
+			search:   21,
+			expected: models.FoundVolume{},
+		},
+		{
+			name:     "Exact first value",
+			slice:    sortedByVolume,
+			search:   1,
+			expected: models.FoundVolume{Price: 100, Volume: 1},
+		},
+		{
+			name:     "Exact last value",
+			slice:    sortedByVolume,
+			search:   13,
+			expected: models.FoundVolume{Price: 105, Volume: 13},
+		},
+		{
+			name:     "Exact duplicated value returns the first one",
+			slice:    sortedByVolume,
+			search:   3,
+			expected: models.FoundVolume{Price: 101, Volume: 3},
+		},
+		{
+			name:     "Value between elements in the left half",
+			slice:    sortedByVolume,
+			search:   2,
+			expected: models.FoundVolume{Price: 101, Volume: 3},
+		},
+		{
+			name:     "Value between elements in the right half",
+			slice:    sortedByVolume,
+			search:   6,
+			expected: models.FoundVolume{Price: 104, Volume: 8},
+		},
+		{
+			name:     "Single element matches",
+			slice:    []models.FoundVolume{{Price: 100, Volume: 5}},
+			search:   5,
+			expected: models.FoundVolume{Price: 100, Volume: 5},
+		},
+		{
+			name:     "Single element doesn't match",
+			slice:    []models.FoundVolume{{Price: 100, Volume: 5}},
+			search:   5.1,
+			expected: models.FoundVolume{},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			assert.Equal(t, tc.expected, binarySearch("BTC/USDT", tc.slice, tc.search))
+		})
+	}
+}
+
+// TestRelativeSearch tests that relativeSearch returns the level standing out the most from its neighbours.
+func TestRelativeSearch(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	sortedByPrice := []models.FoundVolume{
+		{Price: 100, Volume: 2},
+		{Price: 101, Volume: 20}, // Outlier near the edge, which has fewer neighbours on one side
+		{Price: 102, Volume: 2},
+		{Price: 103, Volume: 3},
+		{Price: 104, Volume: 2},
+		{Price: 105, Volume: 12}, // Smaller outlier
+		{Price: 106, Volume: 2},
+	}
+
+	tests := []struct {
+		name       string               // Name of the test case
+		slice      []models.FoundVolume // Slice sorted by price
+		multiplier float64              // Multiplier the level must exceed
+		window     int                  // Neighbour levels on each side
+		expected   models.FoundVolume   // Expected found level
+	}{
+		{"Largest outlier", sortedByPrice, 3, 2, sortedByPrice[1]},
+		{"Multiplier too high", sortedByPrice, 20, 2, models.FoundVolume{}},
+		{"Zero window", sortedByPrice, 3, 0, models.FoundVolume{}},
+		{"Single level", sortedByPrice[:1], 3, 2, models.FoundVolume{}},
+		{"Empty slice", nil, 3, 2, models.FoundVolume{}},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, relativeSearch(tc.slice, tc.multiplier, tc.window))
+		})
+	}
+}
+
+// TestMedianOf tests the median calculation of odd, even and empty value sets.
+func TestMedianOf(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	assert.Equal(t, 2.0, medianOf([]float64{3, 1, 2}))
+	assert.Equal(t, 2.5, medianOf([]float64{4, 1, 3, 2}))
+	assert.Equal(t, 0.0, medianOf(nil))
+}
+
+// TestApplyDeltaMatchesUpsert tests that a sequence of deltas yields the same book as the full upsert of the resulting levels.
+func TestApplyDeltaMatchesUpsert(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	snapshotAsks := [][]interface{}{{"100", "5"}, {"101", "3"}, {"102", "3"}, {"103", "8"}}
+	snapshotBids := [][]interface{}{{"96", "2"}, {"97", "7"}, {"98", "2"}, {"99", "4"}}
+
+	tests := []struct {
+		name         string               // Name of the test case
+		snapshot     bool                 // Whether the deltas are applied to the snapshot or to an empty book
+		deltas       [][2][][]interface{} // Ask and bid updates applied one after another
+		expectedAsks [][]interface{}      // Levels of the equivalent full ask snapshot
+		expectedBids [][]interface{}      // Levels of the equivalent full bid snapshot
+	}{
+		{
+			name: "Deltas to an empty book",
+			deltas: [][2][][]interface{}{
+				{{{"100", "5"}, {"101", "3"}}, {{"99", "4"}}},
+				{{{"102", "3"}}, {{"98", "2"}, {"97", "7"}}},
+			},
+			expectedAsks: [][]interface{}{{"100", "5"}, {"101", "3"}, {"102", "3"}},
+			expectedBids: [][]interface{}{{"97", "7"}, {"98", "2"}, {"99", "4"}},
+		},
+		{
+			name:     "Volume updates",
+			snapshot: true,
+			deltas: [][2][][]interface{}{
+				{{{"101", "9"}}, {{"97", "1"}}},
+				{{{"103", "3"}}, {{"99", "2"}}},
+			},
+			expectedAsks: [][]interface{}{{"100", "5"}, {"101", "9"}, {"102", "3"}, {"103", "3"}},
+			expectedBids: [][]interface{}{{"96", "2"}, {"97", "1"}, {"98", "2"}, {"99", "2"}},
+		},
+		{
+			name:     "Level removals",
+			snapshot: true,
+			deltas: [][2][][]interface{}{
+				{{{"100", "0"}, {"102", "0"}}, {{"98", "0"}}},
+				{{{"105", "0"}}, {{"96", "0"}}}, // Removing a missing level changes nothing
+			},
+			expectedAsks: [][]interface{}{{"101", "3"}, {"103", "8"}},
+			expectedBids: [][]interface{}{{"97", "7"}, {"99", "4"}},
+		},
+		{
+			name:     "Mixed updates",
+			snapshot: true,
+			deltas: [][2][][]interface{}{
+				{{{"99.5", "1"}, {"103", "0"}, {"101", "5"}}, {{"99", "0"}, {"99.4", "6"}}},
+				{{{"104", "3"}, {"99.5", "0"}}, {{"95", "2"}, {"97", "0"}}},
+			},
+			expectedAsks: [][]interface{}{{"100", "5"}, {"101", "5"}, {"102", "3"}, {"104", "3"}},
+			expectedBids: [][]interface{}{{"95", "2"}, {"96", "2"}, {"98", "2"}, {"99.4", "6"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			deltaBook := NewOrderbook().(*orderbook)
+			if tc.snapshot {
+				deltaBook.Upsert("BTC/USDT", snapshotAsks, snapshotBids)
+			}
+			for _, delta := range tc.deltas {
+				deltaBook.ApplyDelta("BTC/USDT", delta[0], delta[1])
+			}
+
+			fullBook := NewOrderbook().(*orderbook)
+			fullBook.Upsert("BTC/USDT", tc.expectedAsks, tc.expectedBids)
+
+			assert.Equal(t, fullBook.Asks("BTC/USDT"), deltaBook.Asks("BTC/USDT"))
+			assert.Equal(t, fullBook.Bids("BTC/USDT"), deltaBook.Bids("BTC/USDT"))
+
+			expected, _ := fullBook.Get("BTC/USDT")
+			actual, _ := deltaBook.Get("BTC/USDT")
+
+			assert.Equal(t, withoutIndex(expected.asksSortedByPrice), withoutIndex(actual.asksSortedByPrice))
+			assert.Equal(t, withoutIndex(expected.bidsSortedByPrice), withoutIndex(actual.bidsSortedByPrice))
+			assertSortedByVolume(t, expected.asksSortedByVolume, actual.asksSortedByVolume)
+			assertSortedByVolume(t, expected.bidsSortedByVolume, actual.bidsSortedByVolume)
+		})
+	}
+}
+
+// TestApplyDeltaKeepsPreviousSlices tests that the slices read before a delta aren't changed by it.
+func TestApplyDeltaKeepsPreviousSlices(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	book := NewOrderbook().(*orderbook)
+	book.Upsert("BTC/USDT", [][]interface{}{{"100", "1"}, {"101", "2"}}, [][]interface{}{{"99", "1"}})
+
+	before, _ := book.Get("BTC/USDT")
+	asksSortedByPrice := withoutIndex(before.asksSortedByPrice)
+
+	book.ApplyDelta("BTC/USDT", [][]interface{}{{"100", "0"}, {"100.5", "4"}}, nil)
+
+	assert.Equal(t, asksSortedByPrice, withoutIndex(before.asksSortedByPrice))
+}
+
+// withoutIndex returns a copy of the levels with the index reset, since the index depends on the map iteration order.
+func withoutIndex(levels []models.FoundVolume) []models.FoundVolume {
+	result := make([]models.FoundVolume, 0, len(levels))
+	for _, level := range levels {
+		level.Index = 0
+		result = append(result, level)
+	}
+
+	return result
+}
+
+// assertSortedByVolume asserts that both slices are sorted by volume and hold the same levels.
+// The order of the levels of the same volume depends on the map iteration order, so it isn't compared.
+func assertSortedByVolume(t *testing.T, expected, actual []models.FoundVolume) {
+	t.Helper()
+
+	assert.True(t, sort.SliceIsSorted(actual, func(i, j int) bool { return actual[i].Volume < actual[j].Volume }))
+
+	byPrice := func(levels []models.FoundVolume) []models.FoundVolume {
+		levels = withoutIndex(levels)
+		sort.Slice(levels, func(i, j int) bool { return levels[i].Price < levels[j].Price })
+
+		return levels
+	}
+	assert.Equal(t, byPrice(expected), byPrice(actual))
+}
+
+// benchmarkLevels returns the levels of a book side of the given depth starting from the price.
+func benchmarkLevels(depth int, price float64) [][]interface{} {
+	levels := make([][]interface{}, 0, depth)
+	for i := 0; i < depth; i++ {
+		levels = append(levels, []interface{}{fmt.Sprintf("%v", price+float64(i)), fmt.Sprintf("%v", i%37+1)})
+	}
+
+	return levels
+}
+
+// BenchmarkUpsert measures replacing a 500-level book with a full snapshot.
+func BenchmarkUpsert(b *testing.B) {
+	book := NewOrderbook()
+	asks, bids := benchmarkLevels(500, 1000), benchmarkLevels(500, 500)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		book.Upsert("BTC/USDT", asks, bids)
+	}
+}
+
+// BenchmarkApplyDelta measures applying a delta of ten levels per side to a 500-level book.
+func BenchmarkApplyDelta(b *testing.B) {
+	book := NewOrderbook()
+	book.Upsert("BTC/USDT", benchmarkLevels(500, 1000), benchmarkLevels(500, 500))
+
+	askUpdates := [][]interface{}{{"1003", "0"}, {"1010", "42"}, {"1200.5", "3"}}
+	bidUpdates := [][]interface{}{{"503", "0"}, {"510", "42"}, {"700.5", "3"}}
+	for i := 0; i < 7; i++ {
+		askUpdates = append(askUpdates, []interface{}{fmt.Sprintf("%v", 1100+i), fmt.Sprintf("%v", i+10)})
+		bidUpdates = append(bidUpdates, []interface{}{fmt.Sprintf("%v", 600+i), fmt.Sprintf("%v", i+10)})
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		book.ApplyDelta("BTC/USDT", askUpdates, bidUpdates)
+	}
+}
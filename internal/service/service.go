@@ -1,31 +1,84 @@
 package service
 
 import (
+	"cvs/internal/config"
 	"cvs/internal/models"
 	"errors"
+	"fmt"
+	"net/url"
 	"regexp"
+	"unicode"
+	"unicode/utf8"
 )
 
 const (
 	pairRegex     = `^[\d\w]+([\-\/\_]{1})?[A-Za-z]+$`
-	exchangeRegex = `^(binance_spot|binance_futures|binance_us|bybit_spot|bybit_futures)$`
+	exchangeRegex = `^(binance_spot|binance_futures|binance_coinm|binance_us|bybit_spot|bybit_futures|bybit_inverse|kraken_spot|okx_spot|okx_swap|coinbase_spot)$`
 	emailRegex    = "^[a-zA-Z0-9.!#$%&'*+\\/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z])?)*$"
 	directoryPath = "internal.service."
+	maxWindow     = 100 // Maximum number of neighbour levels on each side compared in the relative search mode
+	maxTolerance  = 100 // Maximum percent of the exact value a found volume may deviate by
+	maxPriceRange = 100 // Maximum width of the bands summed in the cumulative search mode in percent
+
+	maxAlertCooldownSeconds = 24 * 60 * 60 // Maximum alert cooldown of the user settings, a day
+
+	defaultPasswordMinLength = 8 // Minimum number of characters of a password used when none is configured
 )
 
 var (
 	errGettingFoundVolume        = errors.New("error getting found volumes")
-	errEmailIsEmpty              = errors.New("email data is empty")
-	errPairNameIsEmpty           = errors.New("pair name is empty")
-	errExchangeNameIsEmpty       = errors.New("exchange name is empty")
-	errPasswordIsEmpty           = errors.New("user password value is empty")
-	errEmailInvalidFormat        = errors.New("invalid email format")
-	errPairNameInvalidFormat     = errors.New("invalid pair name format")
-	errExchangeNameInvalidFormat = errors.New("invalid exchange name format")
-	errIdBelowOne                = errors.New("user id must be above zero")
-	errExactValueBelowZero       = errors.New("exact value must be above zero")
+	errEmailIsEmpty              = NewValidationError("email data is empty")
+	errPairNameIsEmpty           = NewValidationError("pair name is empty")
+	errExchangeNameIsEmpty       = NewValidationError("exchange name is empty")
+	errPasswordIsEmpty           = NewValidationError("user password value is empty")
+	errPasswordWithoutDigit      = NewValidationError("password must contain a digit")
+	errPasswordWithoutUpper      = NewValidationError("password must contain an upper case letter")
+	errPasswordWithoutSymbol     = NewValidationError("password must contain a punctuation or symbol character")
+	errEmailInvalidFormat        = NewValidationError("invalid email format")
+	errPairNameInvalidFormat     = NewValidationError("invalid pair name format")
+	errExchangeNameInvalidFormat = NewValidationError("invalid exchange name format")
+	errIdBelowOne                = NewValidationError("user id must be above zero")
+	errExactValueBelowZero       = NewValidationError("exact value must be above zero")
+	errLimitBelowZero            = NewValidationError("limit must not be negative")
+	errOffsetBelowZero           = NewValidationError("offset must not be negative")
+	errPairsLimitOutOfRange      = NewValidationError("limit must be between 0 and 500")
+	errSideInvalidFormat         = NewValidationError("side must be asks or bids")
+	errSortInvalidFormat         = NewValidationError("sort must be difference, volume or price")
+	errOrderInvalidFormat        = NewValidationError("order must be asc or desc")
+	errSearchModeInvalidFormat   = NewValidationError("search mode must be exact, relative or cumulative")
+	errMultiplierNotAboveOne     = NewValidationError("multiplier must be above one in the relative search mode")
+	errWindowOutOfRange          = NewValidationError("window must be between 1 and 100 in the relative search mode")
+	errToleranceOutOfRange       = NewValidationError("tolerance must be between 0 and 100")
+	errPriceRangeOutOfRange      = NewValidationError("price range must be above 0 and at most 100 in the cumulative search mode")
+	errPairSideInvalidFormat     = NewValidationError("side must be asks, bids or both")
+	errMinVolumeNotifyBelowZero  = NewValidationError("min volume notify must not be negative")
+	errWebhookUrlInvalidFormat   = NewValidationError("webhook url must be an absolute http or https url")
+	errWebhookSecretIsEmpty      = NewValidationError("webhook secret is required with the webhook url")
+	errAlertCooldownOutOfRange   = NewValidationError("alert cooldown must be between 0 and 86400 seconds")
+	errPairDuplicated            = NewValidationError("pair of the exchange is listed more than once")
+
+	// ErrMaxPairsPerUserReached is returned when a new pair would exceed the limit of pairs of the user.
+	// It is exported, so the handlers can tell the exceeded limit from the failures of the service.
+	ErrMaxPairsPerUserReached = errors.New("maximum number of pairs per user reached")
 )
 
+// ValidationError is returned when the data fails one of the checks of the service.
+// Its message describes the failed check rather than the internals of the service,
+// so the handlers can show it to the client as it is.
+type ValidationError struct {
+	message string // Description of the failed check
+}
+
+// Error returns the description of the failed check.
+func (e *ValidationError) Error() string {
+	return e.message
+}
+
+// NewValidationError creates the validation error with the description of the failed check.
+func NewValidationError(message string) error {
+	return &ValidationError{message: message}
+}
+
 // CheckUserData validates the user data before operations like signing up and logging in.
 // It performs the following checks:
 //   - the Email field is not empty
@@ -47,15 +100,84 @@ func CheckUserData(user models.User) error {
 		return errPasswordIsEmpty
 	}
 
+	// Validate the format of the email against a predefined regex pattern
+	if err := CheckEmail(user.Email); err != nil {
+		return err
+	}
+
+	// If all checks pass without errors, return nil indicating that the user data is valid
+	return nil
+}
+
+// CheckEmail checks that the email is not empty and matches the predefined regex pattern.
+// It is used on its own where only the email of the user is provided, e.g. when the email is changed.
+func CheckEmail(email string) error {
+	if email == "" {
+		return errEmailIsEmpty
+	}
+
 	// Use a regular expression to validate the format of the email against a predefined regex pattern
-	isMatch, err := regexp.MatchString(emailRegex, user.Email)
+	isMatch, err := regexp.MatchString(emailRegex, email)
 	if err != nil || !isMatch {
 		// If there was an error during regex matching or if the email does not match the expected format,
 		// return an error indicating that the email format is invalid
 		return errEmailInvalidFormat
 	}
 
-	// If all checks pass without errors, return nil indicating that the user data is valid
+	return nil
+}
+
+// CheckPasswordStrength checks that the plain text password satisfies the password policy before it is hashed.
+// It performs the following checks:
+//   - the password is not empty
+//   - the password has at least the minimum number of characters, 8 when the policy doesn't set it
+//   - the password contains a digit, an upper case letter and a punctuation or symbol character, if the policy requires them
+//
+// Parameters:
+//   - password: The plain text password set by the user.
+//   - policy: The rules the password must satisfy.
+//
+// Returns:
+//   - A validation error describing the first failed rule, or nil if the password satisfies the policy.
+func CheckPasswordStrength(password string, policy config.PasswordPolicyConfig) error {
+	if password == "" {
+		return errPasswordIsEmpty
+	}
+
+	minLength := policy.MinLength
+	if minLength <= 0 {
+		minLength = defaultPasswordMinLength
+	}
+
+	// Count the characters rather than the bytes, so the non-ASCII passwords aren't favoured
+	if utf8.RuneCountInString(password) < minLength {
+		return NewValidationError(fmt.Sprintf("password must be at least %d characters long", minLength))
+	}
+
+	var hasDigit, hasUpper, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	if policy.RequireDigit && !hasDigit {
+		return errPasswordWithoutDigit
+	}
+
+	if policy.RequireUpper && !hasUpper {
+		return errPasswordWithoutUpper
+	}
+
+	if policy.RequireSymbol && !hasSymbol {
+		return errPasswordWithoutSymbol
+	}
+
 	return nil
 }
 
@@ -63,6 +185,11 @@ func CheckUserData(user models.User) error {
 //   - the Pair field is not empty
 //   - the Exchange field is not empty
 //   - the ExactValue is greater than or equal to 1
+//   - the SearchMode is empty, exact, relative or cumulative
+//   - in the relative search mode, the Multiplier is greater than 1 and the Window is between 1 and 100
+//   - in the cumulative search mode, the PriceRange is greater than 0 and at most 100
+//   - the Tolerance is between 0 and 100
+//   - the Side is empty, asks, bids or both
 //   - the UserID is greater than 0
 //   - the pair name matches a predefined regex pattern
 //   - the exchange name matches a predefined regex pattern
@@ -88,6 +215,37 @@ func CheckPairData(pairData models.UserPairs) error {
 		return errExactValueBelowZero
 	}
 
+	// Check the search mode and the settings it requires
+	switch pairData.SearchMode {
+	case "", models.SearchModeExact:
+	case models.SearchModeRelative:
+		if pairData.Multiplier <= 1 {
+			return errMultiplierNotAboveOne
+		}
+
+		if pairData.Window < 1 || pairData.Window > maxWindow {
+			return errWindowOutOfRange
+		}
+	case models.SearchModeCumulative:
+		if pairData.PriceRange <= 0 || pairData.PriceRange > maxPriceRange {
+			return errPriceRangeOutOfRange
+		}
+	default:
+		return errSearchModeInvalidFormat
+	}
+
+	// Check if Tolerance is a percent of the exact value
+	if pairData.Tolerance < 0 || pairData.Tolerance > maxTolerance {
+		return errToleranceOutOfRange
+	}
+
+	// Check the side of the order book the found volumes are recorded for
+	switch pairData.Side {
+	case "", models.SideAsks, models.SideBids, models.SideBoth:
+	default:
+		return errPairSideInvalidFormat
+	}
+
 	// Check if UserID is less than 1
 	if pairData.UserID < 1 {
 		// Return an error indicating that a valid user ID must be provided
@@ -102,14 +260,120 @@ func CheckPairData(pairData models.UserPairs) error {
 		return errPairNameInvalidFormat
 	}
 
+	// Validate the format of the exchange name against a predefined pattern
+	if err := CheckExchangeName(pairData.Exchange); err != nil {
+		return err
+	}
+
+	// If all checks pass without errors, return nil indicating that the trading pair data is valid
+	return nil
+}
+
+// CheckExchangeName checks if the provided exchange name is not empty and names one of the supported exchanges.
+//
+// If the check fails, an error is returned indicating the specific problem.
+// Otherwise, nil is returned indicating that the exchange name is valid.
+func CheckExchangeName(exchange string) error {
+	// Check if the exchange name is empty
+	if exchange == "" {
+		return errExchangeNameIsEmpty
+	}
+
 	// Use a regular expression to validate the format of the exchange name against a predefined pattern
-	isMatch, err = regexp.MatchString(exchangeRegex, pairData.Exchange)
+	isMatch, err := regexp.MatchString(exchangeRegex, exchange)
 	if err != nil || !isMatch {
 		// If there was an error during regex matching or if the exchange name does not match the expected format,
 		// return an error indicating that the exchange name format is invalid
 		return errExchangeNameInvalidFormat
 	}
 
-	// If all checks pass without errors, return nil indicating that the trading pair data is valid
+	return nil
+}
+
+// CheckFoundVolumesFilter checks if the provided filter satisfies the following criteria:
+//   - the Limit is not negative
+//   - the Offset is not negative
+//   - the Side is either empty, "asks" or "bids"
+//   - the Sort is either empty, "difference", "volume" or "price"
+//   - the Order is either empty, "asc" or "desc"
+//
+// If any of these checks fail, an error is returned indicating the specific problem.
+// If all checks pass, nil is returned indicating that the filter is valid.
+func CheckFoundVolumesFilter(filter models.FoundVolumesFilter) error {
+	// Check if Limit is negative
+	if filter.Limit < 0 {
+		return errLimitBelowZero
+	}
+
+	// Check if Offset is negative
+	if filter.Offset < 0 {
+		return errOffsetBelowZero
+	}
+
+	// Check if Side names one of the order book sides
+	if filter.Side != "" && filter.Side != "asks" && filter.Side != "bids" {
+		return errSideInvalidFormat
+	}
+
+	// Check if Sort names one of the sortable fields of the found volume
+	if filter.Sort != "" && filter.Sort != "difference" && filter.Sort != "volume" && filter.Sort != "price" {
+		return errSortInvalidFormat
+	}
+
+	// Check if Order names one of the sort orders
+	if filter.Order != "" && filter.Order != "asc" && filter.Order != "desc" {
+		return errOrderInvalidFormat
+	}
+
+	// If all checks pass without errors, return nil indicating that the filter is valid
+	return nil
+}
+
+// CheckUserSettings checks if the provided settings satisfy the following criteria:
+//   - the UserID is greater than 0
+//   - the MinVolumeNotify is not negative
+//   - the AlertCooldownSeconds is between 0 and a day
+//   - the WebhookUrl, if set, is an absolute http or https URL of a public address and the WebhookSecret is set with it
+//
+// If any of these checks fail, an error is returned indicating the specific problem.
+// If all checks pass, nil is returned indicating that the settings are valid.
+func CheckUserSettings(settings models.UserSettings) error {
+	// Check if UserID is less than 1
+	if settings.UserID < 1 {
+		return errIdBelowOne
+	}
+
+	// Check if MinVolumeNotify is negative
+	if settings.MinVolumeNotify < 0 {
+		return errMinVolumeNotifyBelowZero
+	}
+
+	// Check if AlertCooldownSeconds is out of range, a longer cooldown would practically mute the pair
+	if settings.AlertCooldownSeconds < 0 || settings.AlertCooldownSeconds > maxAlertCooldownSeconds {
+		return errAlertCooldownOutOfRange
+	}
+
+	// The webhook is disabled when the URL is empty
+	if settings.WebhookUrl == "" {
+		return nil
+	}
+
+	// Check if WebhookUrl is an absolute http or https URL
+	webhookUrl, err := url.Parse(settings.WebhookUrl)
+	if err != nil || (webhookUrl.Scheme != "http" && webhookUrl.Scheme != "https") || webhookUrl.Host == "" {
+		return errWebhookUrlInvalidFormat
+	}
+
+	// Check if WebhookUrl points to a public address, so the scanner can't be made to request its own network
+	if err := checkWebhookHost(webhookUrl.Hostname()); err != nil {
+		return err
+	}
+
+	// Check if WebhookSecret is set, the receiver can't verify unsigned found volumes
+	if settings.WebhookSecret == "" {
+		return errWebhookSecretIsEmpty
+	}
+
+	// If all checks pass without errors, return nil indicating that the settings are valid
 	return nil
 }
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"cvs/internal/models"
+	"cvs/internal/repository"
+	"cvs/internal/service/logger"
+	"time"
+)
+
+const (
+	// sessionTouchInterval is the minimum time between the updates of the time a session was last seen at,
+	// so the authenticated requests don't write to the database every time.
+	sessionTouchInterval = time.Minute
+
+	sessionSweepInterval = time.Hour // Time between the sweeps removing the expired sessions
+)
+
+// SessionService defines the interface for working with the sessions of users.
+// This interface includes methods for starting, retrieving, refreshing and revoking sessions.
+type SessionService interface {
+	InsertSession(ctx context.Context, session models.Session) error                                   // Start a new session
+	GetSession(ctx context.Context, userID, sessionID int) (models.Session, error)                     // Get a session of a user
+	GetUserSessions(ctx context.Context, userID int) ([]models.Session, error)                         // Get all sessions of a user
+	RotateRefreshToken(ctx context.Context, session models.Session, previousRefreshToken []byte) error // Replace a session's refresh token only if it wasn't rotated yet
+	TouchSession(ctx context.Context, session models.Session) error                                    // Update the time a session was last seen at
+	DeleteSession(ctx context.Context, userID, sessionID int) error                                    // Revoke a session of a user
+	DeleteUserSessions(ctx context.Context, userID int) error                                          // Revoke all sessions of a user
+	StartExpirySweeper(ctx context.Context, ttl time.Duration, logger logger.Logger)                   // Start removing the sessions whose refresh tokens have expired
+}
+
+// sessionService is a concrete implementation of SessionService.
+// It holds a reference to the SessionRepository.
+type sessionService struct {
+	sessionRepository repository.SessionRepository // Repository for accessing session data
+	contextTimeout    time.Duration                // Timeout duration for context management
+}
+
+// NewSessionService creates a new instance of sessionService.
+//
+// Parameters:
+//   - sessionRepository: Repository for managing session data.
+//   - timeout: Duration to set context timeout for operations.
+//
+// Returns:
+//   - An instance of SessionService.
+func NewSessionService(sessionRepository repository.SessionRepository, timeout time.Duration) SessionService {
+	return &sessionService{
+		sessionRepository: sessionRepository,
+		contextTimeout:    timeout,
+	}
+}
+
+// InsertSession stores a new session of the user in the database.
+//
+// Parameters:
+//   - c: The context for managing request lifetime.
+//   - session: The session with the hash of the refresh token issued for it.
+//
+// Returns:
+//   - An error if the operation fails; otherwise, nil.
+func (ss *sessionService) InsertSession(c context.Context, session models.Session) error {
+	ctx, cancel := context.WithTimeout(c, ss.contextTimeout) // Set up context with timeout
+	defer cancel()                                           // Ensure cancellation of context when done
+
+	err := ss.sessionRepository.InsertSession(ctx, session) // Call repository method to insert session
+
+	return err // Return any errors from the repository
+}
+
+// GetSession retrieves a session of the user from the database.
+//
+// Parameters:
+//   - c: The context for managing request lifetime.
+//   - userID: The ID of the user the session belongs to.
+//   - sessionID: The ID of the session.
+//
+// Returns:
+//   - The session and repository.ErrSessionNotFound if the user has no session with the ID,
+//     an error if the operation fails.
+func (ss *sessionService) GetSession(c context.Context, userID, sessionID int) (models.Session, error) {
+	ctx, cancel := context.WithTimeout(c, ss.contextTimeout) // Set up context with timeout
+	defer cancel()                                           // Ensure cancellation of context when done
+
+	session, err := ss.sessionRepository.GetSession(ctx, userID, sessionID) // Call repository method to get session
+
+	return session, err // Return retrieved session and any errors
+}
+
+// GetUserSessions retrieves all sessions of the user, the most recently seen first.
+//
+// Parameters:
+//   - c: The context for managing request lifetime.
+//   - userID: The ID of the user whose sessions are retrieved.
+//
+// Returns:
+//   - The sessions of the user and an error if the operation fails.
+func (ss *sessionService) GetUserSessions(c context.Context, userID int) ([]models.Session, error) {
+	if userID < 1 {
+		return nil, errIdBelowOne
+	}
+
+	ctx, cancel := context.WithTimeout(c, ss.contextTimeout) // Set up context with timeout
+	defer cancel()                                           // Ensure cancellation of context when done
+
+	sessions, err := ss.sessionRepository.GetUserSessions(ctx, userID) // Call repository method to get sessions
+
+	return sessions, err // Return retrieved sessions and any errors
+}
+
+// RotateRefreshToken replaces the refresh token of the session in the database.
+// The update is applied only if the stored refresh token is still the previous one,
+// so a refresh token can be rotated only once even by concurrent requests.
+//
+// Parameters:
+//   - c: The context for managing request lifetime.
+//   - session: The session containing the hash of the new refresh token.
+//   - previousRefreshToken: The hash of the refresh token being rotated.
+//
+// Returns:
+//   - An error if the token was already rotated or the operation fails; otherwise, nil.
+func (ss *sessionService) RotateRefreshToken(c context.Context, session models.Session, previousRefreshToken []byte) error {
+	ctx, cancel := context.WithTimeout(c, ss.contextTimeout) // Set up context with timeout
+	defer cancel()                                           // Ensure cancellation of context when done
+
+	err := ss.sessionRepository.RotateRefreshToken(ctx, session, previousRefreshToken) // Call repository method to rotate refresh token
+
+	return err // Return any errors from the repository
+}
+
+// TouchSession updates the time the session was last seen at.
+// The session seen within the last sessionTouchInterval isn't updated, so the time is accurate to the interval.
+//
+// Parameters:
+//   - c: The context for managing request lifetime.
+//   - session: The session which made a request.
+//
+// Returns:
+//   - An error if the operation fails; otherwise, nil.
+func (ss *sessionService) TouchSession(c context.Context, session models.Session) error {
+	if time.Since(session.LastSeenAt) < sessionTouchInterval {
+		return nil // The session was seen recently enough
+	}
+
+	ctx, cancel := context.WithTimeout(c, ss.contextTimeout) // Set up context with timeout
+	defer cancel()                                           // Ensure cancellation of context when done
+
+	err := ss.sessionRepository.TouchSession(ctx, session.UserID, session.ID) // Call repository method to touch session
+
+	return err // Return any errors from the repository
+}
+
+// DeleteSession revokes a session of the user, so the tokens bound to it are no longer accepted.
+//
+// Parameters:
+//   - c: The context for managing request lifetime.
+//   - userID: The ID of the user the session belongs to.
+//   - sessionID: The ID of the revoked session.
+//
+// Returns:
+//   - repository.ErrSessionNotFound if the user has no session with the ID.
+//   - An error if the operation fails; otherwise, nil.
+func (ss *sessionService) DeleteSession(c context.Context, userID, sessionID int) error {
+	ctx, cancel := context.WithTimeout(c, ss.contextTimeout) // Set up context with timeout
+	defer cancel()                                           // Ensure cancellation of context when done
+
+	err := ss.sessionRepository.DeleteSession(ctx, userID, sessionID) // Call repository method to delete session
+
+	return err // Return any errors from the repository
+}
+
+// DeleteUserSessions revokes all sessions of the user, logging the user out of every device.
+//
+// Parameters:
+//   - c: The context for managing request lifetime.
+//   - userID: The ID of the user whose sessions are revoked.
+//
+// Returns:
+//   - An error if the operation fails; otherwise, nil.
+func (ss *sessionService) DeleteUserSessions(c context.Context, userID int) error {
+	ctx, cancel := context.WithTimeout(c, ss.contextTimeout) // Set up context with timeout
+	defer cancel()                                           // Ensure cancellation of context when done
+
+	err := ss.sessionRepository.DeleteUserSessions(ctx, userID) // Call repository method to delete sessions
+
+	return err // Return any errors from the repository
+}
+
+// StartExpirySweeper starts removing the sessions whose refresh tokens have expired.
+//
+// Every login stores a new session, so the sessions which were never logged out of would stay in the database
+// and be listed to the user forever. A session is seen on every refresh, which issues a new refresh token,
+// and on the requests authenticated by the access tokens, which expire before the refresh token issued along.
+// So a session which wasn't seen for longer than the refresh token lifetime can't be used anymore.
+//
+// The sweeper runs in its own goroutine until the context is cancelled.
+//
+// Parameters:
+//   - ctx: The context which stops the sweeper when it is cancelled.
+//   - ttl: The lifetime of the refresh tokens. A non-positive value uses the default refresh token lifetime.
+//   - logger: The logger of the failed sweeps and the number of removed sessions.
+func (ss *sessionService) StartExpirySweeper(ctx context.Context, ttl time.Duration, logger logger.Logger) {
+	if ttl <= 0 {
+		ttl = defaultRefreshTokenLifetimeHours * time.Hour
+	}
+
+	go func() {
+		ticker := time.NewTicker(sessionSweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := ss.deleteExpiredSessions(ctx, ttl, logger); err != nil {
+					logger.Error(err) // Keep sweeping, the sessions are removed by the next sweep
+				}
+			}
+		}
+	}()
+}
+
+// deleteExpiredSessions removes the sessions which weren't seen for longer than the TTL in a single sweep.
+func (ss *sessionService) deleteExpiredSessions(c context.Context, ttl time.Duration, logger logger.Logger) error {
+	ctx, cancel := context.WithTimeout(c, ss.contextTimeout) // Set up context with timeout
+	defer cancel()                                           // Ensure cancellation of context when done
+
+	deleted, err := ss.sessionRepository.DeleteExpiredSessions(ctx, ttl)
+	if err != nil {
+		return err
+	}
+
+	if deleted > 0 {
+		logger.Debugf("Removed %d expired sessions", deleted)
+	}
+
+	return nil
+}
@@ -4,50 +4,74 @@ import (
 	"context"
 	"cvs/internal/models"
 	"cvs/internal/repository"
+	"fmt"
 	"time"
 )
 
+const (
+	defaultMaxPairsPerUser = 100 // Maximum number of pairs of a user used when none is configured
+	defaultPairsPageLimit  = 50  // Number of pairs of a page used when the limit isn't set
+	maxPairsPageLimit      = 500 // Maximum number of pairs of a page, so a page can't load all pairs of a user at once
+)
+
 // UserPairsService defines the interface for working with user pair settings.
 // This interface includes methods for adding, updating, retrieving, and deleting user pairs.
 type UserPairsService interface {
 	Add(ctx context.Context, pairData models.UserPairs) error
+	BulkAdd(ctx context.Context, pairs []models.UserPairs) ([]error, error)
 	UpdateExactValue(ctx context.Context, pairData models.UserPairs) error
 	GetAllUserPairs(ctx context.Context, userID int) ([]models.UserPairs, error)
+	GetUserPairsPage(ctx context.Context, userID int, pagination models.UserPairsPagination) (models.UserPairsPage, error)
+	GetUserPair(ctx context.Context, userID int, exchange, pair string) (models.UserPairs, error)
+	GetUserPairsByExchange(ctx context.Context, userID int, exchange string) ([]models.UserPairs, error)
 	GetPairsByExchange(ctx context.Context, exchange string) ([]string, error)
+	CountPairSubscribers(ctx context.Context, exchange, pair string) (int, error)
 	DeletePair(ctx context.Context, pairData models.UserPairs) error
+	DeleteAllUserPairs(ctx context.Context, userID int) error
+	ReplaceUserPairs(ctx context.Context, userID int, pairs []models.UserPairs) (models.UserPairsReplaceResult, error)
 }
 
 // userPairsService is a concrete implementation of UserPairsService.
-// It holds a reference to the UserPairsRepository and a timeout duration.
+// It holds a reference to the UserPairsRepository, the limit of pairs of a user and a timeout duration.
 type userPairsService struct {
 	userPairsRepository repository.UserPairsRepository // Repository for accessing user pairs data
+	maxPairsPerUser     int                            // Maximum number of pairs a user can have
 	contextTimeout      time.Duration                  // Timeout duration for context
 }
 
 // NewUserPairsService creates a new instance of userPairsService.
-// It takes a UserPairsRepository and a timeout duration as parameters.
+// It takes a UserPairsRepository, the limit of pairs of a user and a timeout duration as parameters.
 //
 // Parameters:
 //   - userPairsRepository: Repository for managing user pairs data.
+//   - maxPairsPerUser: Maximum number of pairs a user can have, every pair multiplies the scanning load.
+//     If it isn't positive, 100 is used.
 //   - timeout: Duration to set context timeout for operations.
 //
 // Returns:
 //   - An instance of UserPairsService.
-func NewUserPairsService(userPairsRepository repository.UserPairsRepository, timeout time.Duration) UserPairsService {
+func NewUserPairsService(userPairsRepository repository.UserPairsRepository, maxPairsPerUser int, timeout time.Duration) UserPairsService {
+	if maxPairsPerUser <= 0 {
+		maxPairsPerUser = defaultMaxPairsPerUser
+	}
+
 	return &userPairsService{
 		userPairsRepository: userPairsRepository,
+		maxPairsPerUser:     maxPairsPerUser,
 		contextTimeout:      timeout,
 	}
 }
 
 // Add inserts user pair data into the database.
-// It validates the pair data before attempting to add it to the repository.
+// It validates the pair data and checks that the user hasn't reached the limit of pairs
+// before attempting to add it to the repository.
 //
 // Parameters:
 //   - ctx: The context for managing request lifetime.
 //   - pairData: The user pair data to be added.
 //
 // Returns:
+//   - An error wrapping ErrMaxPairsPerUserReached if the user already has the maximum number of pairs.
 //   - An error if the operation fails; otherwise, nil.
 func (ups *userPairsService) Add(ctx context.Context, pairData models.UserPairs) error {
 	// Validate the pair data using a separate validation function.
@@ -58,6 +82,15 @@ func (ups *userPairsService) Add(ctx context.Context, pairData models.UserPairs)
 	ctx, cancel := context.WithTimeout(ctx, ups.contextTimeout) // Set up context with timeout
 	defer cancel()                                              // Ensure cancellation of context when done
 
+	pairsCount, err := ups.userPairsRepository.CountUserPairs(ctx, pairData.UserID)
+	if err != nil {
+		return err
+	}
+
+	if pairsCount >= ups.maxPairsPerUser {
+		return ups.errMaxPairsPerUserReached() // The new pair would exceed the limit
+	}
+
 	// Attempt to add the pair data using the repository.
 	if err := ups.userPairsRepository.Add(ctx, pairData); err != nil {
 		return err // Return any errors from the repository
@@ -66,6 +99,89 @@ func (ups *userPairsService) Add(ctx context.Context, pairData models.UserPairs)
 	return nil // Return nil if successful
 }
 
+// BulkAdd inserts several user pairs into the database in a single transaction.
+// Every pair is validated before the transaction starts, the invalid pairs are skipped
+// and the valid ones are added regardless of them. The pairs which would exceed the limit
+// of pairs of the user are skipped as well, the earlier pairs of the request take the precedence.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - pairs: The user pairs to be added.
+//
+// Returns:
+//   - The errors of the pairs in the order of the pairs, nil for the added ones.
+//   - An error if the transaction fails, in which case none of the pairs is added
+//     and the error is also set for every valid pair.
+func (ups *userPairsService) BulkAdd(ctx context.Context, pairs []models.UserPairs) ([]error, error) {
+	pairErrors := make([]error, len(pairs))
+
+	validPairs := make([]models.UserPairs, 0, len(pairs)) // Pairs passed to the repository
+	validIndexes := make([]int, 0, len(pairs))            // Indexes of the valid pairs among all pairs
+	for i, pairData := range pairs {
+		if err := CheckPairData(pairData); err != nil {
+			pairErrors[i] = err // Report the validation error of the pair
+
+			continue
+		}
+
+		validPairs = append(validPairs, pairData)
+		validIndexes = append(validIndexes, i)
+	}
+
+	if len(validPairs) == 0 {
+		return pairErrors, nil // Nothing to add
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ups.contextTimeout) // Set up context with timeout
+	defer cancel()                                              // Ensure cancellation of context when done
+
+	pairsToAdd := make([]models.UserPairs, 0, len(validPairs)) // Valid pairs within the limit of their users
+	indexesToAdd := make([]int, 0, len(validPairs))            // Indexes of the pairs to add among all pairs
+	pairsLeft := make(map[int]int)                             // Number of pairs every user can still add
+	for i, pairData := range validPairs {
+		left, ok := pairsLeft[pairData.UserID]
+		if !ok {
+			pairsCount, err := ups.userPairsRepository.CountUserPairs(ctx, pairData.UserID)
+			if err != nil {
+				for _, index := range validIndexes {
+					pairErrors[index] = err // None of the pairs is added
+				}
+
+				return pairErrors, err
+			}
+
+			left = ups.maxPairsPerUser - pairsCount
+		}
+
+		if left <= 0 {
+			pairErrors[validIndexes[i]] = ups.errMaxPairsPerUserReached()
+			pairsLeft[pairData.UserID] = 0
+
+			continue
+		}
+
+		pairsLeft[pairData.UserID] = left - 1
+		pairsToAdd = append(pairsToAdd, pairData)
+		indexesToAdd = append(indexesToAdd, validIndexes[i])
+	}
+
+	if len(pairsToAdd) == 0 {
+		return pairErrors, nil // Every valid pair exceeds the limit
+	}
+
+	repoErrors, err := ups.userPairsRepository.BulkAdd(ctx, pairsToAdd)
+	for i, index := range indexesToAdd {
+		switch {
+		case err != nil:
+			pairErrors[index] = err // None of the pairs was added
+		case i < len(repoErrors):
+			pairErrors[index] = repoErrors[i]
+		}
+	}
+
+	return pairErrors, err
+}
+
 // UpdateExactValue updates existing pair settings in the database.
 // It validates the pair data before attempting to update it in the repository.
 //
@@ -125,6 +241,93 @@ func (ups *userPairsService) DeletePair(ctx context.Context, pairData models.Use
 	return nil // Return nil if successful
 }
 
+// DeleteAllUserPairs removes all pairs of the user from the database at once.
+// It validates the user ID before attempting to delete.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - userID: The ID of the user whose pairs are to be deleted.
+//
+// Returns:
+//   - An error if validation fails or if the operation fails; otherwise, nil.
+func (ups *userPairsService) DeleteAllUserPairs(ctx context.Context, userID int) error {
+	// Validate that user ID is greater than zero.
+	if userID < 1 {
+		return errIdBelowOne // Return validation error
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ups.contextTimeout) // Set up context with timeout
+	defer cancel()                                              // Ensure cancellation of context when done
+
+	return ups.userPairsRepository.DeleteAllUserPairs(ctx, userID)
+}
+
+// ReplaceUserPairs replaces all pairs of the user with the given pairs in a single transaction.
+// Every pair is validated and the pairs must not exceed the limit of pairs of the user, otherwise nothing is changed.
+// An empty list removes all pairs of the user.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - userID: The ID of the user whose pairs are replaced.
+//   - pairs: The pairs the user should have, each pair of an exchange at most once.
+//
+// Returns:
+//   - The added, updated and removed pairs.
+//   - An error wrapping ErrMaxPairsPerUserReached if there are more pairs than the limit,
+//     a validation error if a pair is invalid or listed twice, or the error of the repository.
+func (ups *userPairsService) ReplaceUserPairs(ctx context.Context, userID int, pairs []models.UserPairs) (models.UserPairsReplaceResult, error) {
+	if userID < 1 {
+		return models.UserPairsReplaceResult{}, errIdBelowOne
+	}
+
+	if len(pairs) > ups.maxPairsPerUser {
+		return models.UserPairsReplaceResult{}, ups.errMaxPairsPerUserReached()
+	}
+
+	seen := make(map[[2]string]bool, len(pairs)) // Exchanges and pairs of the validated pairs
+	for i := range pairs {
+		pairs[i].UserID = userID
+
+		if err := CheckPairData(pairs[i]); err != nil {
+			return models.UserPairsReplaceResult{}, err
+		}
+
+		key := [2]string{pairs[i].Exchange, pairs[i].Pair}
+		if seen[key] {
+			return models.UserPairsReplaceResult{}, errPairDuplicated
+		}
+		seen[key] = true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ups.contextTimeout) // Set up context with timeout
+	defer cancel()                                              // Ensure cancellation of context when done
+
+	return ups.userPairsRepository.ReplaceUserPairs(ctx, userID, pairs)
+}
+
+// errMaxPairsPerUserReached returns the error of a pair which would exceed the limit of pairs of the user.
+// It names the limit, so the user knows how many pairs they can have.
+func (ups *userPairsService) errMaxPairsPerUserReached() error {
+	return fmt.Errorf("%w: %d", ErrMaxPairsPerUserReached, ups.maxPairsPerUser)
+}
+
+// CountPairSubscribers counts the users who watch the pair on the exchange.
+// An exchange should stop fetching a pair only when nobody watches it anymore.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - exchange: The name of the exchange.
+//   - pair: The pair watched on the exchange.
+//
+// Returns:
+//   - The number of users who have the pair on the exchange and an error if any occurs during retrieval.
+func (ups *userPairsService) CountPairSubscribers(ctx context.Context, exchange, pair string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, ups.contextTimeout) // Set up context with timeout
+	defer cancel()                                              // Ensure cancellation of context when done
+
+	return ups.userPairsRepository.CountPairSubscribers(ctx, exchange, pair)
+}
+
 // GetAllUserPairs retrieves all user pairs from the database for a given user ID.
 //
 // Parameters:
@@ -142,6 +345,93 @@ func (ups *userPairsService) GetAllUserPairs(ctx context.Context, userID int) ([
 	return userPairs, nil // Return retrieved pairs if successful
 }
 
+// GetUserPairsPage retrieves a page of the user pairs sorted by exchange and pair together with the number of all of them,
+// so the clients of the users with many pairs don't load all of them at once.
+//
+// Parameters:
+//   - c: The context for managing request lifetime.
+//   - userID: The ID of the user whose pairs are to be retrieved.
+//   - pagination: The limit and the offset of the page, a zero limit uses the default page size of 50.
+//
+// Returns:
+//   - The page of the pairs with the total number of pairs of the user.
+//   - A validation error if the limit isn't between 0 and 500 or the offset is negative, an error if any occurs during retrieval.
+func (ups *userPairsService) GetUserPairsPage(c context.Context, userID int, pagination models.UserPairsPagination) (models.UserPairsPage, error) {
+	if pagination.Limit < 0 || pagination.Limit > maxPairsPageLimit {
+		return models.UserPairsPage{}, errPairsLimitOutOfRange
+	}
+	if pagination.Offset < 0 {
+		return models.UserPairsPage{}, errOffsetBelowZero
+	}
+	if pagination.Limit == 0 {
+		pagination.Limit = defaultPairsPageLimit
+	}
+
+	ctx, cancel := context.WithTimeout(c, ups.contextTimeout) // Set up context with timeout
+	defer cancel()                                            // Ensure cancellation of context when done
+
+	total, err := ups.userPairsRepository.CountUserPairs(ctx, userID)
+	if err != nil {
+		return models.UserPairsPage{}, err
+	}
+
+	userPairs, err := ups.userPairsRepository.GetUserPairsPaged(ctx, userID, pagination.Limit, pagination.Offset)
+	if err != nil {
+		return models.UserPairsPage{}, err
+	}
+
+	return models.UserPairsPage{
+		Pairs:  userPairs,
+		Total:  total,
+		Limit:  pagination.Limit,
+		Offset: pagination.Offset,
+	}, nil
+}
+
+// GetUserPair retrieves a single pair of the user on the exchange from the database along with its search settings.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - userID: The ID of the user whose pair is to be retrieved.
+//   - exchange: The name of the exchange of the pair.
+//   - pair: The name of the pair in the BASE/QUOTE form it is stored in.
+//
+// Returns:
+//   - The pair and an error if the exchange name is invalid or any occurs during retrieval,
+//     repository.ErrUserPairNotFound if the user has no such pair.
+func (ups *userPairsService) GetUserPair(ctx context.Context, userID int, exchange, pair string) (models.UserPairs, error) {
+	if err := CheckExchangeName(exchange); err != nil {
+		return models.UserPairs{}, err // Return error if the exchange name is invalid
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ups.contextTimeout) // Set up context with timeout
+	defer cancel()                                              // Ensure cancellation of context when done
+
+	return ups.userPairsRepository.GetUserPair(ctx, userID, exchange, pair)
+}
+
+// GetUserPairsByExchange retrieves the user pairs of a given exchange from the database for a given user ID.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - userID: The ID of the user whose pairs are to be retrieved.
+//   - exchange: The name of the exchange whose pairs are to be retrieved.
+//
+// Returns:
+//   - A slice of UserPairs and an error if the exchange name is invalid or any occurs during retrieval.
+func (ups *userPairsService) GetUserPairsByExchange(ctx context.Context, userID int, exchange string) ([]models.UserPairs, error) {
+	if err := CheckExchangeName(exchange); err != nil {
+		return nil, err // Return error if the exchange name is invalid
+	}
+
+	userPairs, err := ups.userPairsRepository.GetUserPairsByExchange(ctx, userID, exchange)
+	if err != nil {
+		return userPairs, err // Return empty slice and error if retrieval fails
+	}
+
+	return userPairs, nil // Return retrieved pairs if successful
+}
+
 // GetPairsByExchange retrieves all user pairs associated with a given exchange name from the database.
 //
 // Parameters:
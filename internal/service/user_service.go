@@ -14,16 +14,19 @@ import (
 // UserService defines the interface for user-related operations.
 // This interface includes methods for inserting, updating, retrieving, and deleting users.
 type UserService interface {
-	InsertUser(ctx context.Context, user models.User) (int, error)         // Insert a new user
-	UpdatePassword(ctx context.Context, user models.User) error            // Update an existing user's password
-	UpdateRefreshToken(c context.Context, user models.User) error          // Update an existing user's refresh token
-	GetUsersIdFromDB(ctx context.Context) error                            // Get all user IDs from the database
-	GetUserById(ctx context.Context, userID int) (models.User, error)      // Get a user by ID
-	GetUserByEmail(ctx context.Context, email string) (models.User, error) // Get a user by email
-	GetUsersIdFromMemory() cmap.ConcurrentMap[string, string]              // Get all user IDs from memory
-	SetUserIdIntoMemory(userID int)                                        // Set a user ID into memory
-	DeleteUserIdFromMemory(userID int)                                     // Delete a user ID from memory
-	DeleteUser(ctx context.Context, userID int) error                      // Delete a user by ID
+	InsertUser(ctx context.Context, user models.User) (int, error)                                                  // Insert a new user
+	InsertUserWithSession(ctx context.Context, user models.User, newSession repository.NewSessionFunc) (int, error) // Insert a new user along with the first session of it
+	UpdatePassword(ctx context.Context, user models.User) error                                                     // Update an existing user's password
+	VerifyUser(ctx context.Context, userID int) error                                                               // Mark a user's email as verified
+	SetPendingEmail(ctx context.Context, userID int, email string) error                                            // Store the new email of a user until it is confirmed
+	ConfirmEmail(ctx context.Context, userID int, email string) error                                               // Replace a user's email with the confirmed pending one
+	GetUsersIdFromDB(ctx context.Context) error                                                                     // Get all user IDs from the database
+	GetUserById(ctx context.Context, userID int) (models.User, error)                                               // Get a user by ID
+	GetUserByEmail(ctx context.Context, email string) (models.User, error)                                          // Get a user by email
+	GetUsersIdFromMemory() cmap.ConcurrentMap[string, string]                                                       // Get all user IDs from memory
+	SetUserIdIntoMemory(userID int)                                                                                 // Set a user ID into memory
+	DeleteUserIdFromMemory(userID int)                                                                              // Delete a user ID from memory
+	DeleteUser(ctx context.Context, userID int) error                                                               // Delete a user by ID
 }
 
 // userService is a concrete implementation of UserService.
@@ -71,6 +74,26 @@ func (us *userService) InsertUser(c context.Context, user models.User) (int, err
 	return userID, err // Return the newly created user's ID and any errors
 }
 
+// InsertUserWithSession adds a new user to the database along with the first session of it.
+// The user and the session are stored in a single transaction, so the user isn't
+// stored if the session can't be started.
+//
+// Parameters:
+//   - c: The context for managing request lifetime.
+//   - user: The user data to be inserted.
+//   - newSession: The function starting the first session of the inserted user.
+//
+// Returns:
+//   - The ID of the newly created user and an error if the operation fails.
+func (us *userService) InsertUserWithSession(c context.Context, user models.User, newSession repository.NewSessionFunc) (int, error) {
+	ctx, cancel := context.WithTimeout(c, us.contextTimeout) // Set up context with timeout
+	defer cancel()                                           // Ensure cancellation of context when done
+
+	userID, err := us.userRepository.InsertUserWithSession(ctx, user, newSession) // Call repository method to insert user with session
+
+	return userID, err // Return the newly created user's ID and any errors
+}
+
 // UpdatePassword updates an existing user's password in the database.
 //
 // Parameters:
@@ -88,19 +111,58 @@ func (us *userService) UpdatePassword(c context.Context, user models.User) error
 	return err // Return any errors from the repository
 }
 
-// UpdateRefreshToken updates an existing user's refresh token in the database.
+// VerifyUser marks the email of the user as verified in the database.
+//
+// Parameters:
+//   - c: The context for managing request lifetime.
+//   - userID: The ID of the user whose email is verified.
+//
+// Returns:
+//   - An error if the operation fails; otherwise, nil.
+func (us *userService) VerifyUser(c context.Context, userID int) error {
+	ctx, cancel := context.WithTimeout(c, us.contextTimeout) // Set up context with timeout
+	defer cancel()                                           // Ensure cancellation of context when done
+
+	err := us.userRepository.VerifyUser(ctx, userID) // Call repository method to verify user
+
+	return err // Return any errors from the repository
+}
+
+// SetPendingEmail stores the new email of the user until it is confirmed by the link sent to it.
+//
+// Parameters:
+//   - c: The context for managing request lifetime.
+//   - userID: The ID of the user changing the email.
+//   - email: The new email of the user.
+//
+// Returns:
+//   - repository.ErrEmailAlreadyExists if another user is registered with the email.
+//   - An error if the operation fails; otherwise, nil.
+func (us *userService) SetPendingEmail(c context.Context, userID int, email string) error {
+	ctx, cancel := context.WithTimeout(c, us.contextTimeout) // Set up context with timeout
+	defer cancel()                                           // Ensure cancellation of context when done
+
+	err := us.userRepository.SetPendingEmail(ctx, userID, email) // Call repository method to store the pending email
+
+	return err // Return any errors from the repository
+}
+
+// ConfirmEmail replaces the email of the user with the pending one once it is confirmed.
 //
 // Parameters:
 //   - c: The context for managing request lifetime.
-//   - user: The user data containing the updated refresh token.
+//   - userID: The ID of the user changing the email.
+//   - email: The confirmed email.
 //
 // Returns:
+//   - repository.ErrEmailAlreadyExists if another user has registered with the email since it was requested.
+//   - repository.ErrPendingEmailNotFound if the email isn't the pending email of the user.
 //   - An error if the operation fails; otherwise, nil.
-func (us *userService) UpdateRefreshToken(c context.Context, user models.User) error {
+func (us *userService) ConfirmEmail(c context.Context, userID int, email string) error {
 	ctx, cancel := context.WithTimeout(c, us.contextTimeout) // Set up context with timeout
 	defer cancel()                                           // Ensure cancellation of context when done
 
-	err := us.userRepository.UpdateRefreshToken(ctx, user) // Call repository method to update refresh token
+	err := us.userRepository.ConfirmEmail(ctx, userID, email) // Call repository method to replace the email
 
 	return err // Return any errors from the repository
 }
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"cvs/internal/models"
+	"cvs/internal/repository"
+	"strconv"
+	"time"
+
+	cmap "github.com/orcaman/concurrent-map/v2"
+)
+
+// UserSettingsService defines the interface for working with the notification preferences of users.
+// This interface includes methods for retrieving and updating the settings and deciding whether a found volume is notified about.
+type UserSettingsService interface {
+	GetSettings(ctx context.Context, userID int) (models.UserSettings, error)          // Method to retrieve the settings of a user
+	UpdateSettings(ctx context.Context, settings models.UserSettings) error            // Method to validate and save the settings of a user
+	ShouldNotify(ctx context.Context, userID int, foundVolume models.FoundVolume) bool // Method to decide whether the user is notified about a found volume
+}
+
+// userSettingsService is a concrete implementation of UserSettingsService.
+// It holds a concurrent map which serves as a cache of the settings stored in the database,
+// because the settings are consulted for every found volume of the scan loops.
+type userSettingsService struct {
+	settingsData           cmap.ConcurrentMap[string, models.UserSettings] // Cached settings by user ID
+	userSettingsRepository repository.UserSettingsRepository               // Repository for persisting user settings
+	contextTimeout         time.Duration                                   // Timeout duration for context
+}
+
+// NewUserSettingsService creates a new instance of userSettingsService.
+//
+// Parameters:
+//   - userSettingsRepository: Repository for persisting user settings.
+//   - timeout: Duration to set context timeout for operations.
+//
+// Returns:
+//   - An instance of UserSettingsService.
+func NewUserSettingsService(userSettingsRepository repository.UserSettingsRepository, timeout time.Duration) UserSettingsService {
+	return &userSettingsService{
+		settingsData:           cmap.New[models.UserSettings](),
+		userSettingsRepository: userSettingsRepository,
+		contextTimeout:         timeout,
+	}
+}
+
+// GetSettings retrieves the settings of the user.
+// The settings are read from the database on the first request and cached afterwards.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - userID: The ID of the user whose settings are to be retrieved.
+//
+// Returns:
+//   - The settings of the user, the default ones if the user has never saved them.
+//   - An error if the settings could not be retrieved.
+func (uss *userSettingsService) GetSettings(ctx context.Context, userID int) (models.UserSettings, error) {
+	if settings, ok := uss.settingsData.Get(strconv.Itoa(userID)); ok {
+		return settings, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uss.contextTimeout) // Set up context with timeout
+	defer cancel()                                              // Ensure cancellation of context when done
+
+	settings, err := uss.userSettingsRepository.Get(ctx, userID)
+	if err != nil {
+		return settings, err
+	}
+
+	uss.settingsData.Set(strconv.Itoa(userID), settings) // Cache the settings for the scan loops
+
+	return settings, nil
+}
+
+// UpdateSettings validates the settings and saves them.
+// The cache is updated only after the settings are written to the database.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - settings: The new settings of the user.
+//
+// Returns:
+//   - An error if the settings are invalid or could not be saved.
+func (uss *userSettingsService) UpdateSettings(ctx context.Context, settings models.UserSettings) error {
+	if err := CheckUserSettings(settings); err != nil {
+		return err // Return validation error
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uss.contextTimeout) // Set up context with timeout
+	defer cancel()                                              // Ensure cancellation of context when done
+
+	if err := uss.userSettingsRepository.Upsert(ctx, settings); err != nil {
+		return err
+	}
+
+	uss.settingsData.Set(strconv.Itoa(settings.UserID), settings)
+
+	return nil
+}
+
+// ShouldNotify reports whether the user is notified about the found volume.
+// The found volumes below the minimum volume of the user settings are not notified about.
+//
+// If the settings could not be retrieved, the user is notified, so a database failure doesn't silence the scanner.
+//
+// Parameters:
+//   - ctx: The context for managing request lifetime.
+//   - userID: The ID of the user the volume was found for.
+//   - foundVolume: The found volume.
+//
+// Returns:
+//   - true if the user is notified about the found volume.
+func (uss *userSettingsService) ShouldNotify(ctx context.Context, userID int, foundVolume models.FoundVolume) bool {
+	settings, err := uss.GetSettings(ctx, userID)
+	if err != nil {
+		return true
+	}
+
+	return foundVolume.Volume >= settings.MinVolumeNotify
+}
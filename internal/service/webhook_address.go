@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// webhookResolveTimeout is the time the host of a webhook URL is resolved for when the settings are validated.
+const webhookResolveTimeout = 2 * time.Second
+
+var (
+	errWebhookUrlNotPublic     = NewValidationError("webhook url must point to a public address")
+	errWebhookAddressNotPublic = errors.New("webhook address is not public")
+
+	// nonPublicPrefixes are the ranges which aren't reported by netip, but aren't reachable on the internet either.
+	nonPublicPrefixes = []netip.Prefix{
+		netip.MustParsePrefix("0.0.0.0/8"),     // "This" network
+		netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT
+		netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+		netip.MustParsePrefix("198.18.0.0/15"), // Benchmarking
+	}
+)
+
+// isPublicAddress reports whether the found volumes may be posted to the IP address.
+//
+// The loopback, private, link-local (including the cloud metadata address 169.254.169.254), multicast
+// and unspecified addresses are rejected, so a user can't make the scanner request its own network.
+func isPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap() // Check the IPv4-mapped IPv6 addresses as IPv4 ones
+
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkWebhookHost validates the host of the webhook URL points to public addresses only.
+//
+// The host which can't be resolved is accepted, the addresses it resolves to later are checked
+// on every delivery by webhookDialControl, which also guards against the hosts changing their addresses.
+//
+// Parameters:
+//   - host: The host of the webhook URL without the port.
+//
+// Returns:
+//   - errWebhookUrlNotPublic if the host is or resolves to an address which isn't public.
+func checkWebhookHost(host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !isPublicAddress(addr) {
+			return errWebhookUrlNotPublic
+		}
+
+		return nil
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errWebhookUrlNotPublic // Resolved to the loopback addresses without asking the DNS
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookResolveTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil // The delivery is refused if the host resolves to a non-public address later
+	}
+
+	for _, addr := range addrs {
+		if !isPublicAddress(addr) {
+			return errWebhookUrlNotPublic
+		}
+	}
+
+	return nil
+}
+
+// webhookDialControl is the net.Dialer Control hook refusing the connections of the deliveries to non-public addresses.
+// It is called with the resolved address, so the hosts resolving to a private address after the validation are refused too.
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !isPublicAddress(addr) {
+		return errWebhookAddressNotPublic
+	}
+
+	return nil
+}
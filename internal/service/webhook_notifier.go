@@ -0,0 +1,159 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"cvs/internal/config"
+	"cvs/internal/models"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	webhookSignatureHeader = "X-Signature" // Header carrying the HMAC-SHA256 signature of the body
+
+	defaultWebhookAttempts = 3               // Maximum number of delivery attempts used when the config doesn't set it
+	defaultWebhookBackoff  = time.Second     // Delay before the first retry used when the config doesn't set it
+	defaultWebhookTimeout  = 5 * time.Second // Timeout of a delivery attempt used when the config doesn't set it
+)
+
+// webhookNotifier is a Notifier which posts the found volumes to the webhooks of users.
+type webhookNotifier struct {
+	client   http.Client   // HTTP client for posting the found volumes
+	attempts int           // Maximum number of delivery attempts of a found volume
+	backoff  time.Duration // Delay before the first retry, doubled for every next one
+}
+
+// NewWebhookNotifier creates a new instance of webhookNotifier.
+//
+// Parameters:
+//   - cfg: The delivery settings, non-positive values use the defaults.
+//
+// Returns:
+//   - An instance of Notifier.
+func NewWebhookNotifier(cfg config.WebhookConfig) Notifier {
+	attempts, backoff, timeout := cfg.Attempts, cfg.Backoff, cfg.Timeout
+	if attempts <= 0 {
+		attempts = defaultWebhookAttempts
+	}
+	if backoff <= 0 {
+		backoff = defaultWebhookBackoff
+	}
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	if !cfg.AllowPrivateAddresses {
+		dialer.Control = webhookDialControl // Refuse the addresses the hosts resolve to on the delivery, not only on the validation
+	}
+
+	return &webhookNotifier{
+		client: http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext, // No proxy, so the dialed address is the one of the receiver
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse // A redirect could point to the network of the scanner, so it fails the attempt
+			},
+		},
+		attempts: attempts,
+		backoff:  backoff,
+	}
+}
+
+// Notify posts the found volume as JSON to the webhook of the user.
+//
+// The body is signed with the webhook secret of the user, the hex encoded HMAC-SHA256 of the body
+// is sent in the X-Signature header, so the receiver can verify the found volume came from the scanner.
+// The delivery is retried on network errors and non-2xx responses until the attempts run out. The redirects
+// aren't followed and the connections to non-public addresses are refused, unless the config allows them.
+//
+// Parameters:
+//   - ctx: The context for managing the lifetime of the delivery.
+//   - settings: The settings of the user, nothing is posted if the webhook URL is empty.
+//   - foundVolume: The found volume to post.
+//
+// Returns:
+//   - An error if the found volume could not be delivered.
+func (wn *webhookNotifier) Notify(ctx context.Context, settings models.UserSettings, foundVolume models.FoundVolume) error {
+	if settings.WebhookUrl == "" {
+		return nil // The user hasn't configured the webhook
+	}
+
+	body, err := json.Marshal(foundVolume)
+	if err != nil {
+		return err
+	}
+
+	signature := signWebhookBody(settings.WebhookSecret, body)
+	delay := wn.backoff
+
+	for attempt := 1; ; attempt++ {
+		err = wn.post(ctx, settings.WebhookUrl, body, signature)
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= wn.attempts {
+			return fmt.Errorf("webhook delivery failed after %d attempts: %w", attempt, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+// post sends a single delivery attempt of the signed body.
+//
+// Returns:
+//   - An error if the request failed or the response status is not 2xx.
+func (wn *webhookNotifier) post(ctx context.Context, url string, body []byte, signature string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookSignatureHeader, signature)
+
+	resp, err := wn.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, resp.Body) // Drain the body so the connection can be reused
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// signWebhookBody returns the hex encoded HMAC-SHA256 of the body keyed by the secret.
+func signWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
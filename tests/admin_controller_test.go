@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cvs/api/server/controller"
+	"cvs/internal/mocks"
+	"cvs/internal/models"
+	"cvs/internal/service/exchange"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetExchangesStatsController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	lastSuccessfulFetch := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mockBinance := mocks.NewExchange(t)
+	mockBinance.On("ExchangeName").Return("binance_spot")
+	mockBinance.On("SubscribedPairsCount").Return(3)
+	mockBinance.On("AllPairsCount").Return(1500)
+	mockBinance.On("MinVolumeFloor").Return(0.5)
+	mockBinance.On("BlacklistedPairs").Return([]string{"LUNA/USDT"})
+	mockBinance.On("Status").Return(models.ExchangeStatus{
+		Exchange:            "binance_spot",
+		LastSuccessfulFetch: lastSuccessfulFetch,
+		SubscribedPairs:     3,
+		CircuitBreaker:      models.CircuitBreakerClosed,
+	})
+
+	mockBybit := mocks.NewExchange(t)
+	mockBybit.On("ExchangeName").Return("bybit_spot")
+	mockBybit.On("SubscribedPairsCount").Return(0)
+	mockBybit.On("AllPairsCount").Return(0) // The pairs aren't loaded yet
+	mockBybit.On("MinVolumeFloor").Return(0.0)
+	mockBybit.On("BlacklistedPairs").Return(nil) // No pair is blacklisted
+	mockBybit.On("Status").Return(models.ExchangeStatus{
+		Exchange:       "bybit_spot",
+		LastError:      "response has no body",
+		CircuitBreaker: models.CircuitBreakerOpen, // The exchange keeps failing
+	})
+
+	mockAllExchangesStorage := mocks.NewAllExchanges(t)
+	mockAllExchangesStorage.On("All").Return([]exchange.Exchange{mockBybit, mockBinance})
+
+	adminController := controller.NewAdminController(mockAllExchangesStorage, mocks.NewLogger(t))
+
+	app := fiber.New()
+	app.Get("/api/admin/exchanges/stats", adminController.GetExchangesStats)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/admin/exchanges/stats", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var stats []models.ExchangeStats
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&stats))
+	assert.Equal(t, []models.ExchangeStats{
+		{
+			Exchange:            "binance_spot",
+			SubscribedPairs:     3,
+			ListedPairs:         1500,
+			LastSuccessfulFetch: lastSuccessfulFetch,
+			MinVolumeFloor:      0.5,
+			CircuitBreaker:      models.CircuitBreakerClosed,
+			BlacklistedPairs:    []string{"LUNA/USDT"},
+		},
+		{
+			Exchange:       "bybit_spot",
+			LastError:      "response has no body",
+			CircuitBreaker: models.CircuitBreakerOpen,
+		},
+	}, stats) // Sorted by the exchange name
+}
+
+func TestRefreshExchangePairsController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name           string                                          // Name of the test case
+		exchangeName   string                                          // Exchange name in the path
+		mockBehavior   func(*mocks.AllExchanges, *mocks.Exchange)      // Mock behavior for the exchanges
+		expectedStatus int                                             // Expected HTTP status code
+		expectedBody   func(t *testing.T, body map[string]interface{}) // Assertions of the response body
+	}{
+		{
+			name:         "Success",
+			exchangeName: "binance_spot",
+			mockBehavior: func(mockAllExchanges *mocks.AllExchanges, mockExchange *mocks.Exchange) {
+				mockAllExchanges.On("Get", "binance_spot").Return(mockExchange, true)
+				mockExchange.On("GetAllPairsOfExchange", mock.Anything).Return(nil)
+				mockExchange.On("ExchangeName").Return("binance_spot")
+				mockExchange.On("AllPairsCount").Return(1501)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, body map[string]interface{}) {
+				assert.Equal(t, "binance_spot", body["exchange"])
+				assert.Equal(t, float64(1501), body["listed_pairs"])
+			},
+		},
+		{
+			name:         "Exchange Not Found",
+			exchangeName: "unknown",
+			mockBehavior: func(mockAllExchanges *mocks.AllExchanges, mockExchange *mocks.Exchange) {
+				mockAllExchanges.On("Get", "unknown").Return(nil, false)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody: func(t *testing.T, body map[string]interface{}) {
+				assert.Equal(t, "exchange not found", body["result"])
+				assert.Equal(t, models.CodeNotFound, body["code"])
+			},
+		},
+		{
+			name:         "Exchange Request Failed",
+			exchangeName: "binance_spot",
+			mockBehavior: func(mockAllExchanges *mocks.AllExchanges, mockExchange *mocks.Exchange) {
+				mockAllExchanges.On("Get", "binance_spot").Return(mockExchange, true)
+				mockExchange.On("GetAllPairsOfExchange", mock.Anything).Return(errors.New("response has no body"))
+			},
+			expectedStatus: http.StatusBadGateway,
+			expectedBody: func(t *testing.T, body map[string]interface{}) {
+				assert.Equal(t, "refreshing the pairs failed: response has no body", body["result"])
+				assert.Equal(t, models.CodeUnavailable, body["code"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture the range variable
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allows this subtest to run in parallel with other subtests
+
+			mockAllExchangesStorage := mocks.NewAllExchanges(t)
+			mockExchange := mocks.NewExchange(t)
+			tc.mockBehavior(mockAllExchangesStorage, mockExchange)
+
+			adminController := controller.NewAdminController(mockAllExchangesStorage, mocks.NewLogger(t))
+
+			app := fiber.New()
+			app.Post("/api/admin/exchanges/:name/refresh-pairs", adminController.RefreshExchangePairs)
+
+			resp, err := app.Test(httptest.NewRequest("POST", "/api/admin/exchanges/"+tc.exchangeName+"/refresh-pairs", nil))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+
+			var body map[string]interface{}
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			tc.expectedBody(t, body)
+		})
+	}
+}
@@ -21,15 +21,15 @@ func TestNewBinance(t *testing.T) {
 	mockLogger := mocks.NewLogger(t)
 
 	// Call NewBinance with mocked services
-	binances := exchange.NewBinance(
-		mockUserService,
-		mockUserPairsService,
-		mockHttpRequestService,
-		mockFoundVolumeService,
-		mockLogger,
-	)
+	binances := exchange.NewBinance(exchange.Dependencies{
+		UserService:         mockUserService,
+		UserPairsService:    mockUserPairsService,
+		HttpRequestService:  mockHttpRequestService,
+		FoundVolumesService: mockFoundVolumeService,
+		Logger:              mockLogger,
+	})
 
 	// Assert that the returned slice of exchanges is not nil and has expected length
 	assert.NotNil(t, binances)
-	assert.Equal(t, 3, len(binances)) // Assuming there are three initialization functions
+	assert.Equal(t, 4, len(binances)) // Assuming there are four initialization functions
 }
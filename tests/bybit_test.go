@@ -20,15 +20,15 @@ func TestNewBybit(t *testing.T) {
 	mockLogger := mocks.NewLogger(t)
 
 	// Call NewBybit with mocked services
-	bybits := exchange.NewBybit(
-		mockUserService,
-		mockUserPairsService,
-		mockHttpRequestService,
-		mockFoundVolumeService,
-		mockLogger,
-	)
+	bybits := exchange.NewBybit(exchange.Dependencies{
+		UserService:         mockUserService,
+		UserPairsService:    mockUserPairsService,
+		HttpRequestService:  mockHttpRequestService,
+		FoundVolumesService: mockFoundVolumeService,
+		Logger:              mockLogger,
+	})
 
 	// Assert that the returned slice of exchanges is not nil and has expected length
 	assert.NotNil(t, bybits)
-	assert.Equal(t, 2, len(bybits)) // Assuming there are three initialization functions
+	assert.Equal(t, 3, len(bybits)) // Assuming there are three initialization functions
 }
@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"cvs/internal/mocks"
+	"cvs/internal/service/exchange"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewCoinbase tests the NewCoinbase function
+func TestNewCoinbase(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	// Create mocks for services
+	mockUserService := mocks.NewUserService(t)
+	mockUserPairsService := mocks.NewUserPairsService(t)
+	mockHttpRequestService := mocks.NewHttpRequest(t)
+	mockFoundVolumeService := mocks.NewFoundVolumesService(t)
+	mockLogger := mocks.NewLogger(t)
+
+	// Call NewCoinbase with mocked services
+	coinbases := exchange.NewCoinbase(exchange.Dependencies{
+		UserService:         mockUserService,
+		UserPairsService:    mockUserPairsService,
+		HttpRequestService:  mockHttpRequestService,
+		FoundVolumesService: mockFoundVolumeService,
+		Logger:              mockLogger,
+	})
+
+	// Assert that the returned slice of exchanges is not nil and has expected length
+	assert.NotNil(t, coinbases)
+	assert.Equal(t, 1, len(coinbases)) // Spot exchange
+}
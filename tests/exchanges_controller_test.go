@@ -0,0 +1,788 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"cvs/api/server/controller"
+	"cvs/internal/mocks"
+	"cvs/internal/models"
+	"cvs/internal/service/exchange"
+	"cvs/internal/service/orderbook"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExchangesController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	app := fiber.New()
+
+	mockBinance := mocks.NewExchange(t)
+	mockBinance.On("ExchangeName").Return("binance_spot")
+	mockBybit := mocks.NewExchange(t)
+	mockBybit.On("ExchangeName").Return("bybit_spot")
+
+	mockAllExchangesStorage := mocks.NewAllExchanges(t)
+	mockAllExchangesStorage.On("All").Return([]exchange.Exchange{mockBybit, mockBinance})
+
+	exchangesController := controller.NewExchangesController(mockAllExchangesStorage, mocks.NewLogger(t))
+	app.Get("/api/exchanges", exchangesController.GetExchanges)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/exchanges", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var exchangeNames []string
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&exchangeNames))
+	assert.Equal(t, []string{"binance_spot", "bybit_spot"}, exchangeNames) // Sorted alphabetically
+}
+
+func TestGetExchangePairsController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	samplePairs := []models.ExchangePairs{
+		{Pair: "BTC/USDT", Exchange: "binance_spot"},
+		{Pair: "ETH/BTC", Exchange: "binance_spot"},
+		{Pair: "ETH/USDT", Exchange: "binance_spot"},
+	}
+
+	tests := []struct {
+		name          string                                                                   // Name of the test case
+		url           string                                                                   // Requested URL
+		mocksSetup    func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) // Function to set up mock behavior
+		expectedCode  int                                                                      // Expected HTTP status code after the request
+		expectedFound []models.ExchangePairs                                                   // Expected pairs in the response
+	}{
+		{
+			name: "All Pairs",
+			url:  "/api/exchanges/binance_spot/pairs",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+				exchangeMock.On("AllPairs").Return(samplePairs)
+			},
+			expectedCode:  http.StatusOK,
+			expectedFound: samplePairs,
+		},
+		{
+			name: "Search Filter",
+			url:  "/api/exchanges/binance_spot/pairs?search=btc", // Case-insensitive substring
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+				exchangeMock.On("AllPairs").Return(samplePairs)
+			},
+			expectedCode:  http.StatusOK,
+			expectedFound: samplePairs[:2],
+		},
+		{
+			name: "Search Without Matches",
+			url:  "/api/exchanges/binance_spot/pairs?search=SOL",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+				exchangeMock.On("AllPairs").Return(samplePairs)
+			},
+			expectedCode:  http.StatusOK,
+			expectedFound: []models.ExchangePairs{}, // An empty list rather than null
+		},
+		{
+			name: "Unknown Exchange",
+			url:  "/api/exchanges/unknown/pairs",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "unknown").Return(nil, false)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := fiber.New()
+
+			mockAllExchangesStorage := mocks.NewAllExchanges(t)
+			mockExchange := mocks.NewExchange(t)
+
+			if tc.mocksSetup != nil {
+				tc.mocksSetup(mockAllExchangesStorage, mockExchange) // Setup mocks for the current test case
+			}
+
+			exchangesController := controller.NewExchangesController(mockAllExchangesStorage, mocks.NewLogger(t))
+			app.Get("/api/exchanges/:name/pairs", exchangesController.GetExchangePairs)
+
+			resp, err := app.Test(httptest.NewRequest("GET", tc.url, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			if tc.expectedFound != nil {
+				var pairs []models.ExchangePairs
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&pairs))
+				assert.Equal(t, tc.expectedFound, pairs)
+			}
+		})
+	}
+}
+
+// TestSearchPairsController tests that the pairs found on every exchange are grouped by the exchange,
+// and the exchanges without matches are omitted.
+func TestSearchPairsController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	binancePairs := []models.ExchangePairs{
+		{Pair: "BTC/USDT", Exchange: "binance_spot"},
+		{Pair: "ETH/BTC", Exchange: "binance_spot"},
+	}
+	bybitPairs := []models.ExchangePairs{
+		{Pair: "BTC/USDT", Exchange: "bybit_spot"}, // The same pair is listed on both exchanges
+	}
+
+	tests := []struct {
+		name           string                                                     // Name of the test case
+		url            string                                                     // Requested URL
+		mocksSetup     func(binance, bybit, kraken *mocks.Exchange, query string) // Function to set up mock behavior
+		expectedCode   int                                                        // Expected HTTP status code after the request
+		expectedGroups []models.ExchangePairsGroup                                // Expected groups in the response
+	}{
+		{
+			name: "Overlapping Matches",
+			url:  "/api/pairs/search?q=btc",
+			mocksSetup: func(binance, bybit, kraken *mocks.Exchange, query string) {
+				binance.On("SearchPairs", query).Return(binancePairs)
+				binance.On("ExchangeName").Return("binance_spot")
+				bybit.On("SearchPairs", query).Return(bybitPairs)
+				bybit.On("ExchangeName").Return("bybit_spot")
+				kraken.On("SearchPairs", query).Return([]models.ExchangePairs{}) // Kraken doesn't list the asset
+			},
+			expectedCode: http.StatusOK,
+			expectedGroups: []models.ExchangePairsGroup{
+				{Exchange: "binance_spot", Pairs: binancePairs},
+				{Exchange: "bybit_spot", Pairs: bybitPairs},
+			}, // Sorted by the exchange name
+		},
+		{
+			name: "No Matches",
+			url:  "/api/pairs/search?q=SOL",
+			mocksSetup: func(binance, bybit, kraken *mocks.Exchange, query string) {
+				for _, exchange := range []*mocks.Exchange{binance, bybit, kraken} {
+					exchange.On("SearchPairs", query).Return([]models.ExchangePairs{})
+				}
+			},
+			expectedCode:   http.StatusOK,
+			expectedGroups: []models.ExchangePairsGroup{}, // An empty list rather than null
+		},
+		{
+			name:         "Missing Query",
+			url:          "/api/pairs/search?q=%20",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := fiber.New()
+
+			mockAllExchangesStorage := mocks.NewAllExchanges(t)
+			mockBinance, mockBybit, mockKraken := mocks.NewExchange(t), mocks.NewExchange(t), mocks.NewExchange(t)
+
+			if tc.mocksSetup != nil {
+				req := httptest.NewRequest("GET", tc.url, nil)
+				tc.mocksSetup(mockBinance, mockBybit, mockKraken, req.URL.Query().Get("q")) // Setup mocks for the current test case
+				mockAllExchangesStorage.On("All").Return([]exchange.Exchange{mockKraken, mockBybit, mockBinance})
+			}
+
+			exchangesController := controller.NewExchangesController(mockAllExchangesStorage, mocks.NewLogger(t))
+			app.Get("/api/pairs/search", exchangesController.SearchPairs)
+
+			resp, err := app.Test(httptest.NewRequest("GET", tc.url, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			if tc.expectedGroups != nil {
+				var groups []models.ExchangePairsGroup
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&groups))
+				assert.Equal(t, tc.expectedGroups, groups)
+			}
+		})
+	}
+}
+
+func TestGetExchangeSpreadController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	snapshot := models.PriceSnapshot{
+		Exchange:      "binance_spot",
+		Pair:          "BTC/USDT",
+		BestBid:       49990,
+		BestAsk:       50000,
+		Spread:        10,
+		SpreadPercent: 0.02,
+		Mid:           49995,
+	}
+
+	tests := []struct {
+		name             string                                                                   // Name of the test case
+		url              string                                                                   // Requested URL
+		mocksSetup       func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) // Function to set up mock behavior
+		expectedCode     int                                                                      // Expected HTTP status code after the request
+		expectedSnapshot *models.PriceSnapshot                                                    // Expected price snapshot in the response
+	}{
+		{
+			name: "Snapshot Found",
+			url:  "/api/exchanges/binance_spot/spread?pair=btc/usdt", // The pair is normalized to upper case
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+				exchangeMock.On("BestPrices", "BTC/USDT").Return(snapshot, true)
+			},
+			expectedCode:     http.StatusOK,
+			expectedSnapshot: &snapshot,
+		},
+		{
+			name: "No Orderbook Data",
+			url:  "/api/exchanges/binance_spot/spread?pair=ETH/USDT",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+				exchangeMock.On("BestPrices", "ETH/USDT").Return(models.PriceSnapshot{}, false)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "Missing Pair",
+			url:  "/api/exchanges/binance_spot/spread",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Unknown Exchange",
+			url:  "/api/exchanges/unknown/spread?pair=BTC/USDT",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "unknown").Return(nil, false)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := fiber.New()
+
+			mockAllExchangesStorage := mocks.NewAllExchanges(t)
+			mockExchange := mocks.NewExchange(t)
+
+			if tc.mocksSetup != nil {
+				tc.mocksSetup(mockAllExchangesStorage, mockExchange) // Setup mocks for the current test case
+			}
+
+			exchangesController := controller.NewExchangesController(mockAllExchangesStorage, mocks.NewLogger(t))
+			app.Get("/api/exchanges/:name/spread", exchangesController.GetExchangeSpread)
+
+			resp, err := app.Test(httptest.NewRequest("GET", tc.url, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			if tc.expectedSnapshot != nil {
+				var result models.PriceSnapshot
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+				assert.Equal(t, *tc.expectedSnapshot, result)
+			}
+		})
+	}
+}
+
+// TestGetExchangeOrderbookController tests the GetExchangeOrderbook method of the exchanges controller.
+func TestGetExchangeOrderbookController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	book := orderbook.NewOrderbook()
+	book.Upsert("BTC/USDT",
+		[][]interface{}{{"50010", "1"}, {"50000", "2"}, {"50050", "3"}},
+		[][]interface{}{{"49950", "1"}, {"49990", "2"}, {"49900", "3"}},
+	)
+
+	// The mock exchange reads the populated book like the real one does
+	orderbookSnapshot := func(pair string, depth int) (models.OrderbookSnapshot, bool) {
+		snapshot, ok := book.Snapshot(pair, depth)
+		snapshot.Exchange = "binance_spot"
+
+		return snapshot, ok
+	}
+
+	tests := []struct {
+		name             string                                                                   // Name of the test case
+		url              string                                                                   // Requested URL
+		mocksSetup       func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) // Function to set up mock behavior
+		expectedCode     int                                                                      // Expected HTTP status code after the request
+		expectedSnapshot *models.OrderbookSnapshot                                                // Expected order book snapshot in the response
+	}{
+		{
+			name: "Full Book",
+			url:  "/api/exchanges/binance_spot/orderbook?pair=btc/usdt", // The pair is normalized to upper case
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+				exchangeMock.On("OrderbookSnapshot", "BTC/USDT", 0).Return(orderbookSnapshot)
+			},
+			expectedCode: http.StatusOK,
+			expectedSnapshot: &models.OrderbookSnapshot{
+				Exchange: "binance_spot",
+				Pair:     "BTC/USDT",
+				Asks:     []models.OrderbookLevel{{Price: 50000, Volume: 2}, {Price: 50010, Volume: 1}, {Price: 50050, Volume: 3}},
+				Bids:     []models.OrderbookLevel{{Price: 49990, Volume: 2}, {Price: 49950, Volume: 1}, {Price: 49900, Volume: 3}},
+			},
+		},
+		{
+			name: "Truncated To Depth",
+			url:  "/api/exchanges/binance_spot/orderbook?pair=BTC/USDT&depth=1",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+				exchangeMock.On("OrderbookSnapshot", "BTC/USDT", 1).Return(orderbookSnapshot)
+			},
+			expectedCode: http.StatusOK,
+			expectedSnapshot: &models.OrderbookSnapshot{
+				Exchange: "binance_spot",
+				Pair:     "BTC/USDT",
+				Asks:     []models.OrderbookLevel{{Price: 50000, Volume: 2}},
+				Bids:     []models.OrderbookLevel{{Price: 49990, Volume: 2}},
+			},
+		},
+		{
+			name: "Unknown Pair",
+			url:  "/api/exchanges/binance_spot/orderbook?pair=ETH/USDT",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+				exchangeMock.On("OrderbookSnapshot", "ETH/USDT", 0).Return(orderbookSnapshot)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "Invalid Depth",
+			url:  "/api/exchanges/binance_spot/orderbook?pair=BTC/USDT&depth=ten",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Negative Depth",
+			url:  "/api/exchanges/binance_spot/orderbook?pair=BTC/USDT&depth=-1",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Missing Pair",
+			url:  "/api/exchanges/binance_spot/orderbook",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Unknown Exchange",
+			url:  "/api/exchanges/unknown/orderbook?pair=BTC/USDT",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "unknown").Return(nil, false)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := fiber.New()
+
+			mockAllExchangesStorage := mocks.NewAllExchanges(t)
+			mockExchange := mocks.NewExchange(t)
+
+			if tc.mocksSetup != nil {
+				tc.mocksSetup(mockAllExchangesStorage, mockExchange) // Setup mocks for the current test case
+			}
+
+			exchangesController := controller.NewExchangesController(mockAllExchangesStorage, mocks.NewLogger(t))
+			app.Get("/api/exchanges/:name/orderbook", exchangesController.GetExchangeOrderbook)
+
+			resp, err := app.Test(httptest.NewRequest("GET", tc.url, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			if tc.expectedSnapshot != nil {
+				var result models.OrderbookSnapshot
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+				assert.Equal(t, *tc.expectedSnapshot, result)
+			}
+		})
+	}
+}
+
+// TestPreviewVolumesController tests the PreviewVolumes method of the exchanges controller.
+func TestPreviewVolumesController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	book := orderbook.NewOrderbook()
+	book.Upsert("BTC/USDT",
+		[][]interface{}{{"50000", "1"}, {"50010", "12"}, {"50050", "30"}},
+		[][]interface{}{{"49990", "2"}, {"49950", "15"}, {"49900", "3"}},
+	)
+
+	// The mock exchange searches the populated book like the real one does
+	previewVolumes := func(pair string, exactValue float64) ([]models.FoundVolume, bool) {
+		foundVolumes := book.SearchVolume(pair, "binance_spot", exactValue, 0, 0)
+		if len(foundVolumes) == 0 {
+			return nil, false
+		}
+
+		previewed := []models.FoundVolume{}
+		for _, volume := range foundVolumes {
+			if volume.Price != 0 {
+				volume.VolumeTimeFound = time.Time{} // The time isn't compared
+				previewed = append(previewed, volume)
+			}
+		}
+
+		sort.Slice(previewed, func(i, j int) bool {
+			return previewed[i].Side < previewed[j].Side
+		})
+
+		return previewed, true
+	}
+
+	tests := []struct {
+		name            string                                                                   // Name of the test case
+		url             string                                                                   // Requested URL
+		mocksSetup      func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) // Function to set up mock behavior
+		expectedCode    int                                                                      // Expected HTTP status code after the request
+		expectedErrCode string                                                                   // Expected code of the error response, empty if the request succeeds
+		expectedFound   []string                                                                 // Expected sides and prices of the found volumes in the "side price" format
+	}{
+		{
+			name: "Both Sides",
+			url:  "/api/exchanges/binance_spot/search-volume?pair=btc-usdt&value=10", // The pair is normalized
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+				exchangeMock.On("HasPair", "BTC/USDT").Return(true)
+				exchangeMock.On("PreviewVolumes", "BTC/USDT", 10.0).Return(previewVolumes)
+			},
+			expectedCode:  http.StatusOK,
+			expectedFound: []string{"asks 50010", "bids 49950"},
+		},
+		{
+			name: "Single Side",
+			url:  "/api/exchanges/binance_spot/search-volume?pair=BTC/USDT&value=20&side=asks",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+				exchangeMock.On("HasPair", "BTC/USDT").Return(true)
+				exchangeMock.On("PreviewVolumes", "BTC/USDT", 20.0).Return(previewVolumes)
+			},
+			expectedCode:  http.StatusOK,
+			expectedFound: []string{"asks 50050"},
+		},
+		{
+			name: "Nothing Found",
+			url:  "/api/exchanges/binance_spot/search-volume?pair=BTC/USDT&value=100",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+				exchangeMock.On("HasPair", "BTC/USDT").Return(true)
+				exchangeMock.On("PreviewVolumes", "BTC/USDT", 100.0).Return(previewVolumes)
+			},
+			expectedCode:  http.StatusOK,
+			expectedFound: []string{},
+		},
+		{
+			name: "No Orderbook Data",
+			url:  "/api/exchanges/binance_spot/search-volume?pair=ETH/USDT&value=10",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+				exchangeMock.On("HasPair", "ETH/USDT").Return(true)
+				exchangeMock.On("PreviewVolumes", "ETH/USDT", 10.0).Return(previewVolumes)
+			},
+			expectedCode:    http.StatusNotFound,
+			expectedErrCode: models.CodeNotFound,
+		},
+		{
+			name: "Unknown Pair",
+			url:  "/api/exchanges/binance_spot/search-volume?pair=DOGE/USDT&value=10",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+				exchangeMock.On("HasPair", "DOGE/USDT").Return(false)
+			},
+			expectedCode:    http.StatusNotFound,
+			expectedErrCode: models.CodeNotFound,
+		},
+		{
+			name: "Invalid Pair",
+			url:  "/api/exchanges/binance_spot/search-volume?pair=BTC/&value=10",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+			},
+			expectedCode:    http.StatusBadRequest,
+			expectedErrCode: models.CodeValidationFailed,
+		},
+		{
+			name: "Missing Pair",
+			url:  "/api/exchanges/binance_spot/search-volume?value=10",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+			},
+			expectedCode:    http.StatusBadRequest,
+			expectedErrCode: models.CodeValidationFailed,
+		},
+		{
+			name: "Invalid Value",
+			url:  "/api/exchanges/binance_spot/search-volume?pair=BTC/USDT&value=ten",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+			},
+			expectedCode:    http.StatusBadRequest,
+			expectedErrCode: models.CodeValidationFailed,
+		},
+		{
+			name: "Non-Positive Value",
+			url:  "/api/exchanges/binance_spot/search-volume?pair=BTC/USDT&value=0",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+			},
+			expectedCode:    http.StatusBadRequest,
+			expectedErrCode: models.CodeValidationFailed,
+		},
+		{
+			name: "Invalid Side",
+			url:  "/api/exchanges/binance_spot/search-volume?pair=BTC/USDT&value=10&side=buy",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "binance_spot").Return(exchangeMock, true)
+			},
+			expectedCode:    http.StatusBadRequest,
+			expectedErrCode: models.CodeValidationFailed,
+		},
+		{
+			name: "Unknown Exchange",
+			url:  "/api/exchanges/unknown/search-volume?pair=BTC/USDT&value=10",
+			mocksSetup: func(allExchangesMock *mocks.AllExchanges, exchangeMock *mocks.Exchange) {
+				allExchangesMock.On("Get", "unknown").Return(nil, false)
+			},
+			expectedCode:    http.StatusNotFound,
+			expectedErrCode: models.CodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := fiber.New()
+
+			mockAllExchangesStorage := mocks.NewAllExchanges(t)
+			mockExchange := mocks.NewExchange(t)
+
+			if tc.mocksSetup != nil {
+				tc.mocksSetup(mockAllExchangesStorage, mockExchange) // Setup mocks for the current test case
+			}
+
+			exchangesController := controller.NewExchangesController(mockAllExchangesStorage, mocks.NewLogger(t))
+			app.Get("/api/exchanges/:name/search-volume", exchangesController.PreviewVolumes)
+
+			resp, err := app.Test(httptest.NewRequest("GET", tc.url, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			if tc.expectedErrCode != "" {
+				var result models.Response
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+				assert.Equal(t, tc.expectedErrCode, result.Code)
+			}
+
+			if tc.expectedFound != nil {
+				var result []models.FoundVolume
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+				found := []string{}
+				for _, volume := range result {
+					assert.Equal(t, "BTC/USDT", volume.Pair)
+					assert.Equal(t, "binance_spot", volume.Exchange)
+
+					found = append(found, volume.Side+" "+strconv.FormatFloat(volume.Price, 'f', -1, 64))
+				}
+				assert.Equal(t, tc.expectedFound, found)
+			}
+		})
+	}
+}
+
+// TestAggregateVolumesController tests the AggregateVolumes method of the exchanges controller.
+func TestAggregateVolumesController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	binanceVolumes := []models.FoundVolume{
+		{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50010, Volume: 12},
+		{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "bids", Price: 49950, Volume: 15},
+	}
+	bybitVolumes := []models.FoundVolume{
+		{Exchange: "bybit_spot", Pair: "BTC/USDT", Side: "asks", Price: 50020, Volume: 40},
+		{Exchange: "bybit_spot", Pair: "BTC/USDT", Side: "bids", Price: 49900, Volume: 11},
+	}
+
+	tests := []struct {
+		name            string                                                    // Name of the test case
+		url             string                                                    // Requested URL
+		mocksSetup      func(binance, bybit, kraken *mocks.Exchange, pair string) // Function to set up mock behavior
+		expectedCode    int                                                       // Expected HTTP status code after the request
+		expectedErrCode string                                                    // Expected code of the error response, empty if the request succeeds
+		expectedVolumes *models.AggregatedVolumes                                 // Expected volumes in the response, nil if not checked
+	}{
+		{
+			name: "Grouped And Sorted",
+			url:  "/api/pairs/aggregate-volume?pair=btcusdt&value=10", // The pair is normalized
+			mocksSetup: func(binance, bybit, kraken *mocks.Exchange, pair string) {
+				binance.On("HasPair", pair).Return(true)
+				binance.On("PreviewVolumes", pair, 10.0).Return(binanceVolumes, true)
+				binance.On("ExchangeName").Return("binance_spot")
+				bybit.On("HasPair", pair).Return(true)
+				bybit.On("PreviewVolumes", pair, 10.0).Return(bybitVolumes, true)
+				bybit.On("ExchangeName").Return("bybit_spot")
+				kraken.On("HasPair", pair).Return(false) // Kraken doesn't list the pair
+			},
+			expectedCode: http.StatusOK,
+			expectedVolumes: &models.AggregatedVolumes{
+				Pair:        "BTC/USDT",
+				TotalVolume: 78,
+				Exchanges: []models.ExchangeVolumes{
+					{Exchange: "bybit_spot", TotalVolume: 51, Volumes: bybitVolumes}, // The biggest total volume first
+					{Exchange: "binance_spot", TotalVolume: 27, Volumes: []models.FoundVolume{binanceVolumes[1], binanceVolumes[0]}},
+				},
+			},
+		},
+		{
+			name: "Single Side",
+			url:  "/api/pairs/aggregate-volume?pair=BTC/USDT&value=10&side=bids",
+			mocksSetup: func(binance, bybit, kraken *mocks.Exchange, pair string) {
+				binance.On("HasPair", pair).Return(true)
+				binance.On("PreviewVolumes", pair, 10.0).Return(binanceVolumes, true)
+				binance.On("ExchangeName").Return("binance_spot")
+				bybit.On("HasPair", pair).Return(true)
+				bybit.On("PreviewVolumes", pair, 10.0).Return(bybitVolumes, true)
+				bybit.On("ExchangeName").Return("bybit_spot")
+				kraken.On("HasPair", pair).Return(false)
+			},
+			expectedCode: http.StatusOK,
+			expectedVolumes: &models.AggregatedVolumes{
+				Pair:        "BTC/USDT",
+				TotalVolume: 26,
+				Exchanges: []models.ExchangeVolumes{
+					{Exchange: "binance_spot", TotalVolume: 15, Volumes: binanceVolumes[1:]},
+					{Exchange: "bybit_spot", TotalVolume: 11, Volumes: bybitVolumes[1:]},
+				},
+			},
+		},
+		{
+			name: "No Orderbook Data",
+			url:  "/api/pairs/aggregate-volume?pair=BTC/USDT&value=10",
+			mocksSetup: func(binance, bybit, kraken *mocks.Exchange, pair string) {
+				binance.On("HasPair", pair).Return(true)
+				binance.On("PreviewVolumes", pair, 10.0).Return(binanceVolumes, true)
+				binance.On("ExchangeName").Return("binance_spot")
+				bybit.On("HasPair", pair).Return(true)
+				bybit.On("PreviewVolumes", pair, 10.0).Return(nil, false) // Nobody watches the pair on Bybit
+				kraken.On("HasPair", pair).Return(false)
+			},
+			expectedCode: http.StatusOK,
+			expectedVolumes: &models.AggregatedVolumes{
+				Pair:        "BTC/USDT",
+				TotalVolume: 27,
+				Exchanges: []models.ExchangeVolumes{
+					{Exchange: "binance_spot", TotalVolume: 27, Volumes: []models.FoundVolume{binanceVolumes[1], binanceVolumes[0]}},
+				},
+			},
+		},
+		{
+			name: "Unknown Pair",
+			url:  "/api/pairs/aggregate-volume?pair=DOGE/USDT&value=10",
+			mocksSetup: func(binance, bybit, kraken *mocks.Exchange, pair string) {
+				for _, exchange := range []*mocks.Exchange{binance, bybit, kraken} {
+					exchange.On("HasPair", pair).Return(false)
+				}
+			},
+			expectedCode:    http.StatusNotFound,
+			expectedErrCode: models.CodeNotFound,
+		},
+		{
+			name:            "Missing Pair",
+			url:             "/api/pairs/aggregate-volume?value=10",
+			expectedCode:    http.StatusBadRequest,
+			expectedErrCode: models.CodeValidationFailed,
+		},
+		{
+			name:            "Non-Positive Value",
+			url:             "/api/pairs/aggregate-volume?pair=BTC/USDT&value=-1",
+			expectedCode:    http.StatusBadRequest,
+			expectedErrCode: models.CodeValidationFailed,
+		},
+		{
+			name:            "Invalid Side",
+			url:             "/api/pairs/aggregate-volume?pair=BTC/USDT&value=10&side=buy",
+			expectedCode:    http.StatusBadRequest,
+			expectedErrCode: models.CodeValidationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := fiber.New()
+
+			mockAllExchangesStorage := mocks.NewAllExchanges(t)
+			mockBinance, mockBybit, mockKraken := mocks.NewExchange(t), mocks.NewExchange(t), mocks.NewExchange(t)
+
+			if tc.mocksSetup != nil {
+				req := httptest.NewRequest("GET", tc.url, nil)
+				pair, _ := models.NormalizePair(req.URL.Query().Get("pair"))
+
+				tc.mocksSetup(mockBinance, mockBybit, mockKraken, pair) // Setup mocks for the current test case
+				mockAllExchangesStorage.On("All").Return([]exchange.Exchange{mockKraken, mockBinance, mockBybit})
+			}
+
+			exchangesController := controller.NewExchangesController(mockAllExchangesStorage, mocks.NewLogger(t))
+			app.Get("/api/pairs/aggregate-volume", exchangesController.AggregateVolumes)
+
+			resp, err := app.Test(httptest.NewRequest("GET", tc.url, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			if tc.expectedErrCode != "" {
+				var result models.Response
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+				assert.Equal(t, tc.expectedErrCode, result.Code)
+			}
+
+			if tc.expectedVolumes != nil {
+				var result models.AggregatedVolumes
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+				assert.Equal(t, *tc.expectedVolumes, result)
+			}
+		})
+	}
+}
@@ -2,14 +2,27 @@ package tests
 
 import (
 	"bytes"
+	"context"
+	"cvs/internal/config"
 	"cvs/internal/mocks"
+	"cvs/internal/models"
 	"cvs/internal/service/exchange"
+	"cvs/internal/service/logger"
+	"errors"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	cmap "github.com/orcaman/concurrent-map/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 func TestInitAllExchanges(t *testing.T) {
@@ -24,17 +37,839 @@ func TestInitAllExchanges(t *testing.T) {
 	allExchangesStorage := exchange.NewAllExchangesService(mockLogger)
 
 	mockLogger.On("Error", mock.Anything, mock.Anything, mock.Anything).Return(nil)
-	mockHttpRequestService.On("Get", mock.Anything).Return(http.Response{Body: io.NopCloser(bytes.NewReader([]byte("test")))}, nil)
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(http.Response{Body: io.NopCloser(bytes.NewReader([]byte("test")))}, nil)
 	mockUserPairsService.On("GetPairsByExchange", mock.Anything, mock.Anything).Return(nil, nil)
 
-	allExchanges := exchange.InitAllExchanges(
-		mockUserService,
-		mockUserPairsService,
-		mockHttpRequestService,
-		mockFoundVolumeService,
-		allExchangesStorage,
-		mockLogger,
+	allExchanges := exchange.InitAllExchanges(ctx, allExchangesStorage, exchange.Dependencies{
+		UserService:         mockUserService,
+		UserPairsService:    mockUserPairsService,
+		HttpRequestService:  mockHttpRequestService,
+		FoundVolumesService: mockFoundVolumeService,
+		Logger:              mockLogger,
+	})
+
+	assert.EqualValues(t, 11, len(allExchanges.All()))
+}
+
+// TestInitEnabledExchanges tests that only the exchanges enabled by the configuration are started.
+func TestInitEnabledExchanges(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	mockUserPairsService := mocks.NewUserPairsService(t)
+	mockHttpRequestService := mocks.NewHttpRequest(t)
+	mockLogger := mocks.NewLogger(t)
+	allExchangesStorage := exchange.NewAllExchangesService(mockLogger)
+
+	mockLogger.On("Error", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(http.Response{Body: io.NopCloser(bytes.NewReader([]byte("test")))}, nil)
+	mockUserPairsService.On("GetPairsByExchange", mock.Anything, "bybit_spot").Return(nil, nil) // Only Bybit Spot loads its pairs
+
+	allExchanges := exchange.InitAllExchanges(ctx, allExchangesStorage, exchange.Dependencies{
+		UserService:         mocks.NewUserService(t),
+		UserPairsService:    mockUserPairsService,
+		HttpRequestService:  mockHttpRequestService,
+		FoundVolumesService: mocks.NewFoundVolumesService(t),
+		Logger:              mockLogger,
+		EnabledExchanges:    []string{"bybit_spot"},
+	})
+
+	assert.Len(t, allExchanges.All(), 1)
+
+	_, started := allExchanges.Get("bybit_spot")
+	assert.True(t, started)
+
+	for _, name := range []string{"binance_spot", "binance_futures", "binance_us", "bybit_futures"} {
+		_, started := allExchanges.Get(name)
+		assert.False(t, started, "%s must not be started", name)
+	}
+}
+
+// TestInitAllExchangesBackfillsSubscriptions tests that the pairs stored in the database at startup
+// are subscribed before the first poll of the exchange.
+func TestInitAllExchangesBackfillsSubscriptions(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	workCtx, stopWork := context.WithCancel(context.Background())
+	defer stopWork() // Stop the loops of the exchange once the test ends
+
+	testLogger := logger.NewApiLogger(&config.Config{Logger: config.Logger{Level: "fatal"}}) // Keep the responses failing to parse out of the test output
+	testLogger.InitLogger()
+
+	mockUserService := mocks.NewUserService(t)
+	mockUserPairsService := mocks.NewUserPairsService(t)
+	mockHttpRequestService := mocks.NewHttpRequest(t)
+	allExchangesStorage := exchange.NewAllExchangesService(testLogger)
+
+	firstPoll := make(chan models.ExchangeStatus, 1) // Status of the exchange when its first order book is requested
+
+	jsonResponse := func(context.Context, string, http.Header, int, time.Duration) http.Response {
+		return http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"error":[],"result":{}}`))}
+	}
+
+	mockUserService.On("GetUsersIdFromMemory").Return(cmap.New[string]()).Maybe()
+	mockUserPairsService.On("GetPairsByExchange", mock.Anything, "kraken_spot").Return([]string{"BTC/USDT"}, nil).Once()
+	mockHttpRequestService.On("Get", mock.Anything, mock.Anything).Return(http.Response{}, errors.New("ticker unavailable")).Maybe()
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.MatchedBy(func(url string) bool {
+		return !strings.Contains(url, "/Depth")
+	}), mock.Anything, mock.Anything, mock.Anything).Return(jsonResponse, nil).Maybe() // The pairs of the exchange
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.MatchedBy(func(url string) bool {
+		return strings.Contains(url, "/Depth")
+	}), mock.Anything, mock.Anything, mock.Anything).
+		Return(jsonResponse, nil).
+		Run(func(args mock.Arguments) {
+			kraken, _ := allExchangesStorage.Get("kraken_spot")
+
+			select {
+			case firstPoll <- kraken.Status():
+			default: // Only the first poll is checked
+			}
+		})
+
+	exchange.InitAllExchanges(workCtx, allExchangesStorage, exchange.Dependencies{
+		UserService:         mockUserService,
+		UserPairsService:    mockUserPairsService,
+		HttpRequestService:  mockHttpRequestService,
+		FoundVolumesService: mocks.NewFoundVolumesService(t),
+		Logger:              testLogger,
+		EnabledExchanges:    []string{"kraken_spot"},
+	})
+
+	select {
+	case status := <-firstPoll:
+		assert.True(t, status.SubscriptionsLoaded)
+		assert.Equal(t, 1, status.SubscribedPairs)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the pair stored at startup wasn't polled")
+	}
+}
+
+// TestExchangeRequestFailureDoesNotPanic tests that failed requests to the exchange API are logged
+// instead of reading the missing response body.
+func TestExchangeRequestFailureDoesNotPanic(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name string        // Name of the test case
+		resp http.Response // Response returned by the HTTP request service
+		err  error         // Error returned by the HTTP request service
+	}{
+		{
+			name: "Transport Error",
+			resp: http.Response{}, // The transport returned no response, so the body is nil
+			err:  errors.New("connection refused"),
+		},
+		{
+			name: "Response Without Body",
+			resp: http.Response{StatusCode: http.StatusOK},
+			err:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockHttpRequestService := mocks.NewHttpRequest(t)
+			mockLogger := mocks.NewLogger(t)
+
+			mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tc.resp, tc.err)
+			mockLogger.On("Warn", "Error while getting orderbook", mock.Anything, mock.Anything, mock.Anything, zap.String("pair", "BTC/USDT")).Return(nil).Once() // The order book request is logged with the pair
+			mockLogger.On("Warn", "Error while getting all pairs of exchange", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()                     // The pairs request is logged
+
+			binance := exchange.NewBinance(exchange.Dependencies{HttpRequestService: mockHttpRequestService, Logger: mockLogger})[0]
+
+			assert.NotPanics(t, func() {
+				binance.GetOrderbookDataFromExchange(context.Background(), "BTC/USDT")
+				binance.GetAllPairsOfExchange(context.Background())
+			})
+		})
+	}
+}
+
+// TestExchangeRequestHeaders tests that the requests of an exchange carry the headers configured for it
+// and the exchanges without configured headers send none.
+func TestExchangeRequestHeaders(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	apiKey := http.Header{}
+	apiKey.Set("X-MBX-APIKEY", "secret")
+
+	mockHttpRequestService := mocks.NewHttpRequest(t)
+	mockLogger := mocks.NewLogger(t)
+
+	mockLogger.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, apiKey, mock.Anything, mock.Anything).
+		Return(http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte("{}")))}, nil).
+		Twice() // The pairs and the order book of Binance Spot
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, http.Header(nil), mock.Anything, mock.Anything).
+		Return(http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte("{}")))}, nil).
+		Once() // The order book of Binance Futures
+
+	binances := exchange.NewBinance(exchange.Dependencies{
+		HttpRequestService: mockHttpRequestService,
+		Logger:             mockLogger,
+		RequestHeaders: map[string]http.Header{
+			"binance_spot": apiKey,
+		},
+	})
+
+	binances[0].GetAllPairsOfExchange(context.Background())
+	binances[0].GetOrderbookDataFromExchange(context.Background(), "BTC/USDT")
+	binances[1].GetOrderbookDataFromExchange(context.Background(), "BTC/USDT")
+}
+
+// TestGetAllPairsOfExchangeRefresh tests that a refresh of the pairs adds the new listings and removes the delisted pairs,
+// and that a failed request or an empty list keeps the stored pairs.
+func TestGetAllPairsOfExchangeRefresh(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	mockHttpRequestService := mocks.NewHttpRequest(t)
+	mockLogger := mocks.NewLogger(t)
+
+	pairsResponse := func(body string) http.Response {
+		return http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte(body)))}
+	}
+
+	mockLogger.On("Warn", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once() // The failed request
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(pairsResponse(`{"symbols": [{"baseAsset": "BTC", "quoteAsset": "USDT"}, {"baseAsset": "ETH", "quoteAsset": "USDT"}]}`), nil).
+		Once()
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(pairsResponse(`{"symbols": [{"baseAsset": "BTC", "quoteAsset": "USDT"}, {"baseAsset": "SOL", "quoteAsset": "USDT"}]}`), nil).
+		Once() // ETH/USDT is delisted and SOL/USDT is listed
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(http.Response{}, errors.New("connection refused")).
+		Once()
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(pairsResponse(`{"symbols": []}`), nil).
+		Once()
+
+	binance := exchange.NewBinance(exchange.Dependencies{HttpRequestService: mockHttpRequestService, Logger: mockLogger})[0]
+
+	assert.NoError(t, binance.GetAllPairsOfExchange(context.Background()))
+	assert.Equal(t, []models.ExchangePairs{
+		{Pair: "BTC/USDT", Exchange: "binance_spot"},
+		{Pair: "ETH/USDT", Exchange: "binance_spot"},
+	}, binance.AllPairs())
+
+	assert.NoError(t, binance.GetAllPairsOfExchange(context.Background()))
+	assert.Equal(t, []models.ExchangePairs{
+		{Pair: "BTC/USDT", Exchange: "binance_spot"},
+		{Pair: "SOL/USDT", Exchange: "binance_spot"},
+	}, binance.AllPairs())
+
+	assert.Error(t, binance.GetAllPairsOfExchange(context.Background())) // The stored pairs are kept
+	assert.Equal(t, 2, binance.AllPairsCount())
+
+	assert.NoError(t, binance.GetAllPairsOfExchange(context.Background())) // An exchange doesn't delist all of its pairs at once
+	assert.Equal(t, 2, binance.AllPairsCount())
+}
+
+// TestExchangeBaseUrls tests that the configured base URL replaces the production host of the requests of its exchange only,
+// and that an invalid base URL is logged and ignored.
+func TestExchangeBaseUrls(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	mockHttpRequestService := mocks.NewHttpRequest(t)
+	mockLogger := mocks.NewLogger(t)
+
+	okResponse := func() http.Response {
+		return http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte("{}")))}
+	}
+
+	mockLogger.On("Error", "Invalid exchange base url", mock.Anything, mock.Anything).Return(nil).Once() // Binance Futures
+	mockLogger.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, "http://localhost:8080/binance/api/v3/exchangeInfo", mock.Anything, mock.Anything, mock.Anything).
+		Return(okResponse(), nil).
+		Once()
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, "http://localhost:8080/binance/api/v1/depth?symbol=BTCUSDT&limit=500", mock.Anything, mock.Anything, mock.Anything).
+		Return(okResponse(), nil).
+		Once()
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, "https://fapi.binance.com/fapi/v1/depth?symbol=BTCUSDT&limit=500", mock.Anything, mock.Anything, mock.Anything).
+		Return(okResponse(), nil).
+		Once() // The invalid base URL keeps the production host
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, "https://api.binance.us/api/v3/depth?symbol=BTCUSDT&limit=500", mock.Anything, mock.Anything, mock.Anything).
+		Return(okResponse(), nil).
+		Once() // The exchanges without a base URL request the production host
+
+	binances := exchange.NewBinance(exchange.Dependencies{
+		HttpRequestService: mockHttpRequestService,
+		Logger:             mockLogger,
+		BaseUrls: map[string]string{
+			"binance_spot":    "http://localhost:8080/binance/",
+			"binance_futures": "fapi.binance.com",
+		},
+	})
+
+	binances[0].GetAllPairsOfExchange(context.Background())
+	binances[0].GetOrderbookDataFromExchange(context.Background(), "BTC/USDT")
+	binances[1].GetOrderbookDataFromExchange(context.Background(), "BTC/USDT")
+	binances[3].GetOrderbookDataFromExchange(context.Background(), "BTC/USDT")
+}
+
+// TestOrderbookParseErrorLogged tests that an order book which can't be parsed is logged with the exchange and the pair.
+func TestOrderbookParseErrorLogged(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	mockHttpRequestService := mocks.NewHttpRequest(t)
+	mockLogger := mocks.NewLogger(t)
+
+	var logged []interface{} // Arguments the error was logged with
+
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte("not a json")))}, nil)
+	mockLogger.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			logged = args
+		}).
+		Once()
+
+	binance := exchange.NewBinance(exchange.Dependencies{HttpRequestService: mockHttpRequestService, Logger: mockLogger})[0]
+
+	binance.GetOrderbookDataFromExchange(context.Background(), "BTC/USDT")
+
+	if assert.Len(t, logged, 6) {
+		assert.Equal(t, "Empty asks or bids or error while parsing JSON", logged[0])
+		assert.Equal(t, zap.String("exchange", "binance_spot"), logged[1])
+		assert.Equal(t, zap.String("pair", "BTC/USDT"), logged[3])
+		assert.Equal(t, zapcore.ErrorType, logged[4].(zap.Field).Type) // The parse error is logged as well
+		assert.Equal(t, zap.String("body", "not a json"), logged[5])   // So is the body which couldn't be parsed
+	}
+}
+
+// TestMalformedOrderbookKeepsPreviousBook tests that a JSON body which isn't a valid order book
+// doesn't wipe the last known order book of the pair.
+func TestMalformedOrderbookKeepsPreviousBook(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	const pair = "MALFORMED/USDT" // The order book service of the exchange is shared, so use a pair no other test uses
+
+	tests := []struct {
+		name         string // Name of the test case
+		body         string // Body returned by the exchange after the valid order book
+		expectedBody string // Sample of the body expected in the log
+	}{
+		{
+			name:         "Truncated JSON",
+			body:         `{"lastUpdateId":1,"bids":[["100","5"]],"asks":[["101"`,
+			expectedBody: `{"lastUpdateId":1,"bids":[["100","5"]],"asks":[["101"`,
+		},
+		{
+			name:         "Empty Side",
+			body:         `{"lastUpdateId":1,"bids":[["100","5"]],"asks":[]}`,
+			expectedBody: `{"lastUpdateId":1,"bids":[["100","5"]],"asks":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) { // The cases share the order book of the pair, so they don't run in parallel
+			mockHttpRequestService := mocks.NewHttpRequest(t)
+			mockLogger := mocks.NewLogger(t)
+
+			mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"lastUpdateId":1,"bids":[["99","5"]],"asks":[["101","3"]]}`))}, nil).
+				Once()
+			mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(tc.body))}, nil).
+				Once()
+			mockLogger.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, zap.String("body", tc.expectedBody)).
+				Return(nil).
+				Once()
+
+			binance := exchange.NewBinance(exchange.Dependencies{HttpRequestService: mockHttpRequestService, Logger: mockLogger})[0]
+
+			binance.GetOrderbookDataFromExchange(context.Background(), pair) // The valid order book is stored
+			binance.GetOrderbookDataFromExchange(context.Background(), pair) // The malformed one is logged and skipped
+
+			snapshot, ok := binance.BestPrices(pair)
+			if assert.True(t, ok) { // The previous order book is retained
+				assert.Equal(t, 99.0, snapshot.BestBid)
+				assert.Equal(t, 101.0, snapshot.BestAsk)
+			}
+		})
+	}
+}
+
+// TestNonJsonOrderbookResponse tests that a body which isn't JSON, e.g. the HTML page Binance returns
+// with a 418 to a banned IP, is reported as rate limited or banned instead of a parse failure,
+// backs off the next requests and doesn't wipe the last known order book of the pair.
+func TestNonJsonOrderbookResponse(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	maintenancePage := "<html><body>" + strings.Repeat("Service is under maintenance. ", 20) + "</body></html>"
+	banPage := "<html><head><title>418 I'm a teapot</title></head><body>Way too many requests, IP banned</body></html>"
+
+	tests := []struct {
+		name             string      // Name of the test case
+		pair             string      // Pair no other test uses, as the order book service of the exchange is shared
+		status           int         // Status of the response which isn't JSON
+		header           http.Header // Headers of the response which isn't JSON
+		body             string      // Body returned by the exchange after the valid order book
+		expectedBody     string      // Sample of the body expected in the log
+		expectedMinDelay float64     // Minimum seconds until the next request expected in the log
+	}{
+		{
+			name:             "Weight Ban",
+			pair:             "BANNED/USDT",
+			status:           http.StatusTeapot,
+			header:           http.Header{"Content-Type": []string{"text/html"}, "Retry-After": []string{"30"}},
+			body:             banPage,
+			expectedBody:     banPage,
+			expectedMinDelay: 29, // The ban lasts as long as the exchange tells
+		},
+		{
+			name:             "Maintenance Page",
+			pair:             "MAINTENANCE/USDT",
+			status:           http.StatusOK,
+			header:           http.Header{"Content-Type": []string{"text/html; charset=utf-8"}},
+			body:             maintenancePage,
+			expectedBody:     maintenancePage[:256] + "...", // Long bodies are truncated
+			expectedMinDelay: 0,
+		},
+		{
+			name:             "Plain Text Notice",
+			pair:             "NOTICE/USDT",
+			status:           http.StatusForbidden,
+			header:           http.Header{"Content-Type": []string{"text/plain"}},
+			body:             "Forbidden",
+			expectedBody:     "Forbidden",
+			expectedMinDelay: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allows subtests to run in parallel
+
+			var logged []interface{} // Arguments the response was logged with
+
+			mockHttpRequestService := mocks.NewHttpRequest(t)
+			mockLogger := mocks.NewLogger(t)
+
+			mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"lastUpdateId":1,"bids":[["99","5"]],"asks":[["101","3"]]}`))}, nil).
+				Once()
+			mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(http.Response{StatusCode: tc.status, Header: tc.header, Body: io.NopCloser(strings.NewReader(tc.body))}, nil).
+				Once()
+			mockLogger.On("Warn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(nil).
+				Run(func(args mock.Arguments) {
+					logged = args
+				}).
+				Once() // Neither the status nor a parse failure is logged besides
+
+			binance := exchange.NewBinance(exchange.Dependencies{HttpRequestService: mockHttpRequestService, Logger: mockLogger})[0]
+
+			binance.GetOrderbookDataFromExchange(context.Background(), tc.pair) // The valid order book is stored
+			binance.GetOrderbookDataFromExchange(context.Background(), tc.pair) // The page is reported and skipped
+
+			if assert.Len(t, logged, 9) {
+				assert.Equal(t, "Non-JSON response, requests are probably rate limited or banned", logged[0])
+				assert.Equal(t, zap.String("exchange", "binance_spot"), logged[1])
+				assert.Equal(t, zap.Int("status", tc.status), logged[4])
+				assert.Equal(t, zap.String("content_type", tc.header.Get("Content-Type")), logged[5])
+				if retryIn, ok := logged[6].(zap.Field); assert.True(t, ok) {
+					assert.GreaterOrEqual(t, time.Duration(retryIn.Integer).Seconds(), tc.expectedMinDelay)
+				}
+				assert.Equal(t, zap.String("body", tc.expectedBody), logged[7])
+				assert.Equal(t, zap.String("pair", tc.pair), logged[8])
+			}
+
+			assert.Contains(t, binance.Status().LastError, "rate limited or banned")
+
+			snapshot, found := binance.BestPrices(tc.pair)
+			if assert.True(t, found) { // The previous order book is retained
+				assert.Equal(t, 99.0, snapshot.BestBid)
+				assert.Equal(t, 101.0, snapshot.BestAsk)
+			}
+		})
+	}
+}
+
+// TestOrderbookResponseStatus tests that the body of a non-2xx response, e.g. the JSON error of a rate limit,
+// isn't parsed as an order book and keeps the previous one, while a 200 response is stored.
+func TestOrderbookResponseStatus(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	const pair = "STATUS/USDT" // The order book service of the exchange is shared, so use a pair no other test uses
+
+	rateLimited := http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"2"}},
+		Body:       io.NopCloser(strings.NewReader(`{"code":-1003,"msg":"Too many requests."}`)),
+	}
+	ok := http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`{"lastUpdateId":2,"bids":[["98","5"]],"asks":[["102","3"]]}`)),
+	}
+
+	var logged []interface{} // Arguments the unexpected status was logged with
+
+	mockHttpRequestService := mocks.NewHttpRequest(t)
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"lastUpdateId":1,"bids":[["99","5"]],"asks":[["101","3"]]}`))}, nil).
+		Once()
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(rateLimited, nil).Once()
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(ok, nil).Once()
+
+	mockLogger := mocks.NewLogger(t)
+	mockLogger.On("Warn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			logged = args
+		}).
+		Once() // The body isn't parsed, so no parse error is logged
+
+	binance := exchange.NewBinance(exchange.Dependencies{HttpRequestService: mockHttpRequestService, Logger: mockLogger})[0]
+
+	binance.GetOrderbookDataFromExchange(context.Background(), pair) // The valid order book is stored
+	binance.GetOrderbookDataFromExchange(context.Background(), pair) // The rate limited response is logged and skipped
+
+	if assert.Len(t, logged, 6) {
+		assert.Equal(t, "Unexpected orderbook response status", logged[0])
+		assert.Equal(t, zap.String("exchange", "binance_spot"), logged[1])
+		assert.Equal(t, zap.String("pair", pair), logged[4])
+		assert.Equal(t, zap.String("body", `{"code":-1003,"msg":"Too many requests."}`), logged[5])
+	}
+
+	assert.Contains(t, binance.Status().LastError, "429")
+
+	snapshot, found := binance.BestPrices(pair)
+	if assert.True(t, found) { // The previous order book is retained
+		assert.Equal(t, 99.0, snapshot.BestBid)
+		assert.Equal(t, 101.0, snapshot.BestAsk)
+	}
+
+	binance.GetOrderbookDataFromExchange(context.Background(), pair) // The exchange responds successfully again
+
+	snapshot, found = binance.BestPrices(pair)
+	if assert.True(t, found) {
+		assert.Equal(t, 98.0, snapshot.BestBid)
+		assert.Equal(t, 102.0, snapshot.BestAsk)
+	}
+
+	assert.Empty(t, binance.Status().LastError)
+}
+
+// TestFindVolumeInOrderbookWorkersLimit tests that the number of users whose volumes are searched
+// at the same time never exceeds the configured number of workers.
+func TestFindVolumeInOrderbookWorkersLimit(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	const (
+		workers    = 3  // Maximum number of concurrently searched users
+		usersCount = 20 // Number of users, well above the number of workers
+	)
+
+	usersId := cmap.New[string]()
+	for i := 1; i <= usersCount; i++ {
+		usersId.Set(strconv.Itoa(i), strconv.Itoa(i))
+	}
+
+	var (
+		active    atomic.Int64 // Number of users being searched right now
+		maxActive atomic.Int64 // The largest number of users searched at the same time
+		searched  sync.WaitGroup
+	)
+
+	searched.Add(usersCount) // Every user is searched once during the first pass
+
+	mockUserService := mocks.NewUserService(t)
+	mockUserPairsService := mocks.NewUserPairsService(t)
+
+	mockUserService.On("GetUsersIdFromMemory").Return(usersId)
+	mockUserPairsService.On("GetAllUserPairs", mock.Anything, mock.Anything).
+		Return([]models.UserPairs{}, nil).
+		Run(func(args mock.Arguments) {
+			current := active.Add(1)
+			for {
+				previous := maxActive.Load()
+				if current <= previous || maxActive.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+
+			time.Sleep(10 * time.Millisecond) // Keep the worker busy, so the others have to wait
+
+			active.Add(-1)
+			searched.Done()
+		}).
+		Times(usersCount) // The work is cancelled before the second pass
+
+	binance := exchange.NewBinance(exchange.Dependencies{
+		UserService:         mockUserService,
+		UserPairsService:    mockUserPairsService,
+		Logger:              mocks.NewLogger(t),
+		VolumeSearchWorkers: workers,
+	})[0]
+	binance.AddPairToSubscribedPairs("BTC/USDT")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	binance.FindVolumeInOrderbookPeriodically(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		searched.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("not all users were searched")
+	}
+
+	cancel()
+
+	assert.LessOrEqual(t, maxActive.Load(), int64(workers))
+	assert.Equal(t, int64(workers), maxActive.Load()) // The workers were used in parallel
+}
+
+// TestFindVolumeInOrderbookDryRun tests that in the dry run mode the found volumes are logged,
+// but never passed to the found volumes service storing them and notifying the users.
+func TestFindVolumeInOrderbookDryRun(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	const pair = "DRYRUN/USDT" // The order book service of the exchange is shared, so use a pair no other test uses
+
+	usersId := cmap.New[string]()
+	usersId.Set("1", "1")
+
+	mockHttpRequestService := mocks.NewHttpRequest(t)
+	mockUserService := mocks.NewUserService(t)
+	mockUserPairsService := mocks.NewUserPairsService(t)
+	mockFoundVolumesService := mocks.NewFoundVolumesService(t) // No expectations, so any upsert fails the test
+	mockLogger := mocks.NewLogger(t)
+
+	var (
+		logged     []interface{} // Arguments the found volume was logged with
+		loggedOnce sync.Once
+	)
+	loggedDone := make(chan struct{})
+
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"lastUpdateId":1,"bids":[["99","50"]],"asks":[["101","3"]]}`))}, nil).
+		Once()
+	mockUserService.On("GetUsersIdFromMemory").Return(usersId)
+	mockUserPairsService.On("GetAllUserPairs", mock.Anything, 1).
+		Return([]models.UserPairs{{UserID: 1, Pair: pair, Exchange: "binance_spot", ExactValue: 10}}, nil)
+	mockLogger.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			loggedOnce.Do(func() {
+				logged = args
+				close(loggedDone)
+			})
+		})
+
+	binance := exchange.NewBinance(exchange.Dependencies{
+		UserService:         mockUserService,
+		UserPairsService:    mockUserPairsService,
+		HttpRequestService:  mockHttpRequestService,
+		FoundVolumesService: mockFoundVolumesService,
+		Logger:              mockLogger,
+		DryRun:              true,
+	})[0]
+
+	binance.GetOrderbookDataFromExchange(context.Background(), pair) // Store the order book whose bid is found
+	binance.AddPairToSubscribedPairs(pair)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	binance.FindVolumeInOrderbookPeriodically(ctx)
+
+	select {
+	case <-loggedDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the found volume wasn't logged")
+	}
+
+	cancel()
+
+	if assert.Len(t, logged, 7) { // Only the bid is found, the ask is below the exact value
+		assert.Equal(t, "Dry run, found volume isn't stored nor notified", logged[0])
+		assert.Equal(t, zap.String("pair", pair), logged[2])
+		assert.Equal(t, zap.String("side", "bids"), logged[3])
+		assert.Equal(t, zap.Float64("price", 99), logged[4])
+		assert.Equal(t, zap.Float64("volume", 50), logged[5])
+		assert.Equal(t, zap.Int("user_id", 1), logged[6])
+	}
+
+	mockFoundVolumesService.AssertNotCalled(t, "UpsertFoundVolume", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestFindVolumeInOrderbookPairSettings tests that the order book of a pair is searched with the settings
+// of that pair only, so the settings of the other pairs of the user don't drop the sides it watches.
+func TestFindVolumeInOrderbookPairSettings(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	const pair = "PAIRSET/USDT" // The order book service of the exchange is shared, so use a pair no other test uses
+
+	usersId := cmap.New[string]()
+	usersId.Set("1", "1")
+
+	mockHttpRequestService := mocks.NewHttpRequest(t)
+	mockUserService := mocks.NewUserService(t)
+	mockUserPairsService := mocks.NewUserPairsService(t)
+	mockFoundVolumesService := mocks.NewFoundVolumesService(t)
+
+	var (
+		mu       sync.Mutex
+		upserted []models.UserPairs   // Settings every found volume was upserted with
+		volumes  []models.FoundVolume // Upserted found volumes
 	)
 
-	assert.EqualValues(t, 5, len(allExchanges.All()))
+	mockHttpRequestService.On("GetWithRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"lastUpdateId":1,"bids":[["99","50"]],"asks":[["101","10"]]}`))}, nil).
+		Once()
+	mockUserService.On("GetUsersIdFromMemory").Return(usersId)
+	mockUserPairsService.On("GetAllUserPairs", mock.Anything, 1).
+		Return([]models.UserPairs{
+			{UserID: 1, Pair: pair, Exchange: "binance_spot", ExactValue: 5, Side: models.SideAsks},
+			{UserID: 1, Pair: "OTHER/USDT", Exchange: "binance_spot", ExactValue: 100, Side: models.SideBids}, // Another pair
+			{UserID: 1, Pair: pair, Exchange: "bybit_spot", ExactValue: 100, Side: models.SideBids},           // The pair on another exchange
+		}, nil)
+	mockFoundVolumesService.On("UpsertFoundVolume", mock.Anything, mock.Anything, mock.Anything).
+		Return(true, nil).
+		Run(func(args mock.Arguments) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			upserted = append(upserted, args.Get(1).(models.UserPairs))
+			volumes = append(volumes, args.Get(2).(models.FoundVolume))
+		})
+
+	binance := exchange.NewBinance(exchange.Dependencies{
+		UserService:         mockUserService,
+		UserPairsService:    mockUserPairsService,
+		HttpRequestService:  mockHttpRequestService,
+		FoundVolumesService: mockFoundVolumesService,
+		Logger:              mocks.NewLogger(t),
+	})[0]
+
+	binance.GetOrderbookDataFromExchange(context.Background(), pair) // Store the order book whose both sides reach the threshold
+	binance.AddPairToSubscribedPairs(pair)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	binance.FindVolumeInOrderbookPeriodically(ctx)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(volumes) >= 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for i, settings := range upserted { // Both sides are upserted with the settings of the searched pair only
+		assert.Equal(t, pair, settings.Pair)
+		assert.Equal(t, "binance_spot", settings.Exchange)
+		assert.Equal(t, models.SideAsks, settings.Side)
+		assert.Equal(t, pair, volumes[i].Pair)
+	}
+
+	asksFound := false // The asks volume passes the threshold of the pair, not the higher one of the other settings
+	for _, volume := range volumes {
+		if volume.Side == models.SideAsks && volume.Price == 101 && volume.Volume == 10 {
+			asksFound = true
+		}
+	}
+	assert.True(t, asksFound)
+}
+
+// TestQuoteFilter tests that only the pairs of the quote assets allowed by the configured filter are stored.
+func TestQuoteFilter(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	loadedPairs := []models.ExchangePairs{
+		{Pair: "BTC/USDT", Exchange: "binance_spot"},
+		{Pair: "ETH/BUSD", Exchange: "binance_spot"},
+		{Pair: "USDC/USDT", Exchange: "binance_spot"},
+		{Pair: "ETH/BTC", Exchange: "binance_spot"},
+	}
+
+	tests := []struct {
+		name          string                 // Name of the test case
+		quoteFilter   *models.QuoteFilter    // Filter configured for the exchange, nil if none is configured
+		expectedPairs []models.ExchangePairs // Pairs expected to be stored, sorted by the pair name
+	}{
+		{
+			name:        "Whitelist Only",
+			quoteFilter: &models.QuoteFilter{Whitelist: []string{"usdt"}}, // Compared case-insensitively
+			expectedPairs: []models.ExchangePairs{
+				{Pair: "BTC/USDT", Exchange: "binance_spot"},
+				{Pair: "USDC/USDT", Exchange: "binance_spot"},
+			},
+		},
+		{
+			name:        "Blacklist",
+			quoteFilter: &models.QuoteFilter{Blacklist: []string{"BUSD", "BTC"}},
+			expectedPairs: []models.ExchangePairs{
+				{Pair: "BTC/USDT", Exchange: "binance_spot"},
+				{Pair: "USDC/USDT", Exchange: "binance_spot"},
+			},
+		},
+		{
+			name:        "Whitelist And Blacklist",
+			quoteFilter: &models.QuoteFilter{Whitelist: []string{"USDT", "BTC"}, Blacklist: []string{"BTC"}}, // The blacklist wins
+			expectedPairs: []models.ExchangePairs{
+				{Pair: "BTC/USDT", Exchange: "binance_spot"},
+				{Pair: "USDC/USDT", Exchange: "binance_spot"},
+			},
+		},
+		{
+			name:        "Empty Filter",
+			quoteFilter: &models.QuoteFilter{},
+			expectedPairs: []models.ExchangePairs{
+				{Pair: "BTC/USDT", Exchange: "binance_spot"},
+				{Pair: "ETH/BTC", Exchange: "binance_spot"},
+				{Pair: "ETH/BUSD", Exchange: "binance_spot"},
+				{Pair: "USDC/USDT", Exchange: "binance_spot"},
+			},
+		},
+		{
+			name: "No Filter Configured",
+			expectedPairs: []models.ExchangePairs{
+				{Pair: "BTC/USDT", Exchange: "binance_spot"},
+				{Pair: "ETH/BTC", Exchange: "binance_spot"},
+				{Pair: "ETH/BUSD", Exchange: "binance_spot"},
+				{Pair: "USDC/USDT", Exchange: "binance_spot"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allows this subtest to run in parallel with other subtests
+
+			quoteFilters := map[string]models.QuoteFilter{}
+			if tc.quoteFilter != nil {
+				quoteFilters["binance_spot"] = *tc.quoteFilter
+			}
+
+			binance := exchange.NewBinance(exchange.Dependencies{Logger: mocks.NewLogger(t), QuoteFilters: quoteFilters})[0]
+			binance.SetEchangePairsToStorage(loadedPairs)
+
+			assert.Equal(t, tc.expectedPairs, binance.AllPairs())
+		})
+	}
 }
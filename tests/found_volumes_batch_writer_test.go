@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cvs/internal/mocks"
+	"cvs/internal/models"
+	"cvs/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestBatchedFoundVolumesRepository_Coalesce tests that rapid writes of the same found volumes are coalesced
+// into one batch holding the last write of every found volume.
+func TestBatchedFoundVolumesRepository_Coalesce(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	mockRepository := mocks.NewFoundVolumesRepository(t)
+	batched := repository.NewBatchedFoundVolumesRepository(mockRepository, time.Hour, mocks.NewLogger(t))
+
+	var upserts, deletes []models.FoundVolume
+	mockRepository.On("WriteBatch", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		upserts = args.Get(1).([]models.FoundVolume)
+		deletes = args.Get(2).([]models.FoundVolume)
+	}).Return(nil).Once()
+
+	for i := range 100 { // Every scan upserts the same volumes again
+		assert.NoError(t, batched.Upsert(ctx, 1, models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: float64(i)}))
+		assert.NoError(t, batched.Upsert(ctx, 2, models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: float64(i)}))
+	}
+
+	assert.NoError(t, batched.Upsert(ctx, 1, models.FoundVolume{Exchange: "binance_spot", Pair: "ETH/USDT", Side: "bids", Price: 3000, Volume: 10}))
+	assert.NoError(t, batched.Delete(ctx, 1, models.FoundVolume{Exchange: "binance_spot", Pair: "ETH/USDT", Side: "bids"})) // The delete replaces the upsert
+
+	assert.NoError(t, batched.Flush(ctx))
+
+	assert.Len(t, upserts, 2)
+	for _, foundVolume := range upserts {
+		assert.Equal(t, 99.0, foundVolume.Volume) // Only the last write of every found volume is flushed
+		assert.Contains(t, []int{1, 2}, foundVolume.UserID)
+	}
+	assert.Equal(t, []models.FoundVolume{{UserID: 1, Exchange: "binance_spot", Pair: "ETH/USDT", Side: "bids"}}, deletes)
+
+	assert.NoError(t, batched.Flush(ctx)) // Nothing is left to flush, so no batch is written
+	mockRepository.AssertNumberOfCalls(t, "WriteBatch", 1)
+}
+
+// TestBatchedFoundVolumesRepository_Start tests that the buffered writes are flushed periodically by one batch.
+func TestBatchedFoundVolumesRepository_Start(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	mockRepository := mocks.NewFoundVolumesRepository(t)
+	batched := repository.NewBatchedFoundVolumesRepository(mockRepository, 50*time.Millisecond, mocks.NewLogger(t))
+
+	flushed := make(chan int, 10) // Numbers of the upserts of the written batches
+	mockRepository.On("WriteBatch", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		flushed <- len(args.Get(1).([]models.FoundVolume))
+	}).Return(nil)
+
+	for i := range 20 {
+		assert.NoError(t, batched.Upsert(ctx, i+1, models.FoundVolume{Exchange: "bybit_spot", Pair: "BTC/USDT", Side: "bids", Price: 49000, Volume: 5}))
+	}
+
+	batchedCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batched.Start(batchedCtx)
+
+	select {
+	case upserts := <-flushed:
+		assert.Equal(t, 20, upserts) // All rapid upserts are written by one batch
+	case <-time.After(time.Second):
+		t.Fatal("the buffered writes weren't flushed")
+	}
+
+	time.Sleep(120 * time.Millisecond) // Let the next ticks pass without new writes
+	mockRepository.AssertNumberOfCalls(t, "WriteBatch", 1)
+}
+
+// TestBatchedFoundVolumesRepository_RetryFailedFlush tests that the writes of a failed flush are written again
+// by the next one, unless they were replaced meanwhile.
+func TestBatchedFoundVolumesRepository_RetryFailedFlush(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	mockRepository := mocks.NewFoundVolumesRepository(t)
+	batched := repository.NewBatchedFoundVolumesRepository(mockRepository, time.Hour, mocks.NewLogger(t))
+
+	mockRepository.On("WriteBatch", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("connection refused")).Once()
+
+	assert.NoError(t, batched.Upsert(ctx, 1, models.FoundVolume{Exchange: "kraken_spot", Pair: "BTC/USD", Side: "asks", Price: 50000, Volume: 1}))
+	assert.NoError(t, batched.Upsert(ctx, 1, models.FoundVolume{Exchange: "kraken_spot", Pair: "BTC/USD", Side: "bids", Price: 49000, Volume: 1}))
+	assert.Error(t, batched.Flush(ctx))
+
+	assert.NoError(t, batched.Upsert(ctx, 1, models.FoundVolume{Exchange: "kraken_spot", Pair: "BTC/USD", Side: "asks", Price: 50000, Volume: 2})) // Replaces the failed write
+
+	var upserts []models.FoundVolume
+	mockRepository.On("WriteBatch", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		upserts = args.Get(1).([]models.FoundVolume)
+	}).Return(nil).Once()
+
+	assert.NoError(t, batched.Flush(ctx))
+	assert.Len(t, upserts, 2)
+
+	for _, foundVolume := range upserts {
+		if foundVolume.Side == "asks" {
+			assert.Equal(t, 2.0, foundVolume.Volume) // The newer write is kept
+		} else {
+			assert.Equal(t, 1.0, foundVolume.Volume) // The failed write is retried
+		}
+	}
+}
+
+// TestBatchedFoundVolumesRepository_Disabled tests that without a flush interval every write goes to the repository directly
+// and that the reads see the buffered writes.
+func TestBatchedFoundVolumesRepository_Disabled(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	foundVolume := models.FoundVolume{Exchange: "okx_spot", Pair: "ETH/USDT", Side: "bids", Price: 2900, Volume: 100}
+
+	mockRepository := mocks.NewFoundVolumesRepository(t)
+	mockRepository.On("Upsert", mock.Anything, 1, foundVolume).Return(nil).Once()
+	mockRepository.On("Delete", mock.Anything, 1, foundVolume).Return(nil).Once()
+
+	batched := repository.NewBatchedFoundVolumesRepository(mockRepository, 0, mocks.NewLogger(t))
+	batched.Start(ctx) // Nothing is started without buffering
+
+	assert.NoError(t, batched.Upsert(ctx, 1, foundVolume))
+	assert.NoError(t, batched.Delete(ctx, 1, foundVolume))
+
+	// The reads flush the buffered writes first, so they see them
+	buffered := repository.NewBatchedFoundVolumesRepository(mockRepository, time.Hour, mocks.NewLogger(t))
+	assert.NoError(t, buffered.Upsert(ctx, 2, foundVolume))
+
+	mockRepository.On("WriteBatch", mock.Anything, mock.Anything, []models.FoundVolume(nil)).Return(nil).Once()
+	mockRepository.On("GetByUser", mock.Anything, 2).Return([]models.FoundVolume{foundVolume}, nil).Once()
+
+	foundVolumes, err := buffered.GetByUser(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.FoundVolume{foundVolume}, foundVolumes)
+}
@@ -0,0 +1,258 @@
+package tests
+
+import (
+	"cvs/internal/models"
+	"cvs/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFoundVolumesUpsert(t *testing.T) {
+	// Run tests in parallel to speed up execution
+	t.Parallel()
+
+	// Define test cases for upserting found volumes
+	tests := []struct {
+		name        string             // Name of the test case
+		validUser   bool               // Whether the volume belongs to an existing user
+		foundVolume models.FoundVolume // Found volume being upserted
+		updated     models.FoundVolume // Found volume upserted a second time with the same key, if any
+		wantErr     bool               // Expectation of whether an error should occur
+	}{
+		{
+			name:      "Insert",
+			validUser: true,
+			foundVolume: models.FoundVolume{
+				Exchange: "binance_spot",
+				Pair:     "BTC/USDT",
+				Side:     "asks",
+				Price:    50000,
+				Volume:   12,
+			},
+			wantErr: false, // No error expected for valid input
+		},
+		{
+			name:      "Update of the same key",
+			validUser: true,
+			foundVolume: models.FoundVolume{
+				Exchange: "binance_spot",
+				Pair:     "BTC/USDT",
+				Side:     "bids",
+				Price:    49000,
+				Volume:   5,
+			},
+			updated: models.FoundVolume{
+				Exchange: "binance_spot",
+				Pair:     "BTC/USDT",
+				Side:     "bids",
+				Price:    48500,
+				Volume:   7,
+			},
+			wantErr: false, // No error expected, the row is updated in place
+		},
+		{
+			name:      "Non-existent user",
+			validUser: false,
+			foundVolume: models.FoundVolume{
+				Exchange: "binance_spot",
+				Pair:     "BTC/USDT",
+				Side:     "asks",
+				Price:    50000,
+				Volume:   12,
+			},
+			wantErr: true, // Error expected due to the foreign key
+		},
+		{
+			name:      "Invalid side",
+			validUser: true,
+			foundVolume: models.FoundVolume{
+				Exchange: "binance_spot",
+				Pair:     "BTC/USDT",
+				Side:     "middle",
+				Price:    50000,
+				Volume:   12,
+			},
+			wantErr: true, // Error expected due to the side check
+		},
+	}
+
+	// Iterate through each test case
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allow this test case to run in parallel
+
+			db := setupDB()  // Setup a new database connection for each test case
+			defer db.Close() // Ensure the database connection is closed after the test
+
+			userID := 99999 // Assuming this user ID does not exist in the users table
+			if tc.validUser {
+				email := "foundvolumesupsert" + time.Now().Format("150405.000000") + "@example.com" // Create a unique email for each test case
+				id, err := insertUser(db, email, []byte("validpassword123"))                        // Insert a valid user into the database
+				defer db.ExecContext(ctx, deleteUserQueryRow, id)                                   // Clean up by deleting the user after the test
+
+				assert.NoError(t, err) // Assert that there was no error inserting the user
+
+				userID = id
+			}
+
+			repo := repository.NewFoundVolumesRepository(db) // Create a new repository instance for found volumes
+			err := repo.Upsert(ctx, userID, tc.foundVolume)  // Attempt to upsert the found volume
+
+			expected := tc.foundVolume
+			if tc.updated.Pair != "" {
+				assert.NoError(t, err)
+
+				err = repo.Upsert(ctx, userID, tc.updated) // Upsert the volume with the same key again
+				expected = tc.updated
+			}
+
+			if tc.wantErr {
+				assert.Error(t, err) // Assert that an error occurred if one was expected
+
+				return
+			}
+
+			assert.NoError(t, err) // Assert that no error occurred for valid input
+
+			foundVolumes, err := repo.GetByUser(ctx, userID) // Retrieve the stored found volumes
+
+			assert.NoError(t, err)
+			assert.Len(t, foundVolumes, 1) // The key is unique, so only one row is stored
+			assert.Equal(t, expected.Price, foundVolumes[0].Price)
+			assert.Equal(t, expected.Volume, foundVolumes[0].Volume)
+		})
+	}
+}
+
+func TestFoundVolumesGetByUser(t *testing.T) {
+	// Run tests in parallel to speed up execution
+	t.Parallel()
+
+	db := setupDB()  // Setup a new database connection
+	defer db.Close() // Ensure the database connection is closed after the test
+
+	userID, err := insertUser(db, "foundvolumesgetbyuser@example.com", []byte("validpassword123")) // Insert a valid user into the database
+	defer db.ExecContext(ctx, deleteUserQueryRow, userID)                                          // Clean up by deleting the user after the test
+
+	assert.NoError(t, err)
+
+	repo := repository.NewFoundVolumesRepository(db) // Create a new repository instance for found volumes
+
+	for _, side := range []string{"asks", "bids"} {
+		err = repo.Upsert(ctx, userID, models.FoundVolume{
+			Exchange: "bybit_spot",
+			Pair:     "ETH/USDT",
+			Side:     side,
+			Price:    3000,
+			Volume:   100,
+		})
+		assert.NoError(t, err)
+	}
+
+	// Define test cases for retrieving found volumes
+	tests := []struct {
+		name          string // Name of the test case
+		userID        int    // User ID for which found volumes are to be fetched
+		expectedCount int    // Expected number of found volumes to be returned
+	}{
+		{
+			name:          "User with found volumes",
+			userID:        userID,
+			expectedCount: 2,
+		},
+		{
+			name:          "User without found volumes",
+			userID:        99999, // Assuming this user ID does not exist in the users table
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			foundVolumes, err := repo.GetByUser(ctx, tc.userID) // Retrieve the stored found volumes
+
+			assert.NoError(t, err)
+			assert.Len(t, foundVolumes, tc.expectedCount)
+		})
+	}
+}
+
+func TestFoundVolumesDelete(t *testing.T) {
+	// Run tests in parallel to speed up execution
+	t.Parallel()
+
+	db := setupDB()  // Setup a new database connection
+	defer db.Close() // Ensure the database connection is closed after the test
+
+	userID, err := insertUser(db, "foundvolumesdelete@example.com", []byte("validpassword123")) // Insert a valid user into the database
+	defer db.ExecContext(ctx, deleteUserQueryRow, userID)                                       // Clean up by deleting the user after the test
+
+	assert.NoError(t, err)
+
+	asks := models.FoundVolume{Exchange: "bybit_spot", Pair: "ETH/USDT", Side: "asks", Price: 3000, Volume: 100}
+	bids := models.FoundVolume{Exchange: "bybit_spot", Pair: "ETH/USDT", Side: "bids", Price: 2900, Volume: 100}
+
+	repo := repository.NewFoundVolumesRepository(db) // Create a new repository instance for found volumes
+
+	assert.NoError(t, repo.Upsert(ctx, userID, asks))
+	assert.NoError(t, repo.Upsert(ctx, userID, bids))
+
+	assert.NoError(t, repo.Delete(ctx, userID, asks)) // Delete only the asks side
+
+	foundVolumes, err := repo.GetByUser(ctx, userID)
+
+	assert.NoError(t, err)
+	assert.Len(t, foundVolumes, 1)
+	assert.Equal(t, "bids", foundVolumes[0].Side) // The other side is kept
+
+	assert.NoError(t, repo.Delete(ctx, userID, asks)) // Deleting a missing volume is not an error
+}
+
+func TestFoundVolumesWriteBatch(t *testing.T) {
+	// Run tests in parallel to speed up execution
+	t.Parallel()
+
+	db := setupDB()  // Setup a new database connection
+	defer db.Close() // Ensure the database connection is closed after the test
+
+	userID, err := insertUser(db, "foundvolumeswritebatch@example.com", []byte("validpassword123")) // Insert a valid user into the database
+	defer db.ExecContext(ctx, deleteUserQueryRow, userID)                                           // Clean up by deleting the user after the test
+
+	assert.NoError(t, err)
+
+	repo := repository.NewFoundVolumesRepository(db) // Create a new repository instance for found volumes
+
+	stale := models.FoundVolume{Exchange: "okx_spot", Pair: "SOL/USDT", Side: "bids", Price: 90, Volume: 100}
+	assert.NoError(t, repo.Upsert(ctx, userID, stale))
+
+	upserts := []models.FoundVolume{
+		{UserID: userID, Exchange: "okx_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 12, LastPrice: 49000, DistanceFromLast: 2.04},
+		{UserID: userID, Exchange: "okx_spot", Pair: "ETH/USDT", Side: "bids", Price: 2900, Volume: 100},
+		{UserID: 99999, Exchange: "okx_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 12}, // The user doesn't exist, so it is skipped
+	}
+	deletes := []models.FoundVolume{
+		{UserID: userID, Exchange: "okx_spot", Pair: "SOL/USDT", Side: "bids"},
+	}
+
+	assert.NoError(t, repo.WriteBatch(ctx, upserts, deletes))
+
+	foundVolumes, err := repo.GetByUser(ctx, userID)
+
+	assert.NoError(t, err)
+	assert.Len(t, foundVolumes, 2) // The deleted volume is gone and both upserts are stored
+
+	for _, foundVolume := range foundVolumes {
+		if foundVolume.Pair == "BTC/USDT" {
+			assert.Equal(t, 49000.0, foundVolume.LastPrice)
+			assert.Equal(t, 2.04, foundVolume.DistanceFromLast)
+		} else {
+			assert.Equal(t, "ETH/USDT", foundVolume.Pair)
+		}
+	}
+}
@@ -0,0 +1,718 @@
+package tests
+
+import (
+	"context"
+	"cvs/internal/mocks"
+	"cvs/internal/models"
+	"cvs/internal/service"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestFoundVolumesService_UpsertFoundVolume tests that found volumes are cached and written through to the repository.
+func TestFoundVolumesService_UpsertFoundVolume(t *testing.T) {
+	t.Parallel()
+
+	userPairData := models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10}
+
+	tests := []struct {
+		name          string                              // Name of the test case
+		foundVolume   models.FoundVolume                  // Found volume being upserted
+		mockRepo      func(*mocks.FoundVolumesRepository) // Mocking the repository behavior
+		expectedCount int                                 // Expected number of cached found volumes
+		expectErr     bool                                // Expectation of whether an error should occur
+	}{
+		{
+			name:        "Found volume is stored",
+			foundVolume: models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 12},
+			mockRepo: func(m *mocks.FoundVolumesRepository) {
+				m.On("Upsert", mock.Anything, 1, mock.Anything).Return(nil)
+			},
+			expectedCount: 1,
+		},
+		{
+			name:        "Repository error is returned",
+			foundVolume: models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 12},
+			mockRepo: func(m *mocks.FoundVolumesRepository) {
+				m.On("Upsert", mock.Anything, 1, mock.Anything).Return(errors.New("db error"))
+			},
+			expectedCount: 1, // The cache is updated regardless
+			expectErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := mocks.NewFoundVolumesRepository(t)
+			tc.mockRepo(mockRepo)
+
+			foundVolumesService := service.NewFoundVolumesService(mockRepo, notifyAllSettingsService(t), mocks.NewLogger(t), contextTimeout)
+
+			isNew, err := foundVolumesService.UpsertFoundVolume(ctx, userPairData, tc.foundVolume)
+			assert.True(t, isNew) // The first found volume is always new
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			foundVolumes, err := foundVolumesService.GetAllFoundVolume(userPairData.UserID, models.FoundVolumesFilter{})
+
+			assert.NoError(t, err)
+			assert.Len(t, foundVolumes, tc.expectedCount)
+		})
+	}
+}
+
+// TestFoundVolumesService_UpsertEmptyFoundVolume tests that a volume which is no longer found is deleted from the repository.
+func TestFoundVolumesService_UpsertEmptyFoundVolume(t *testing.T) {
+	t.Parallel()
+
+	userPairData := models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10}
+	foundVolume := models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 12}
+
+	mockRepo := mocks.NewFoundVolumesRepository(t)
+	mockRepo.On("Upsert", mock.Anything, 1, foundVolume).Return(nil)
+	mockRepo.On("Delete", mock.Anything, 1, mock.Anything).Return(nil)
+
+	foundVolumesService := service.NewFoundVolumesService(mockRepo, notifyAllSettingsService(t), mocks.NewLogger(t), contextTimeout)
+
+	_, err := foundVolumesService.UpsertFoundVolume(ctx, userPairData, foundVolume)
+	assert.NoError(t, err)
+
+	isNew, err := foundVolumesService.UpsertFoundVolume(ctx, userPairData, models.FoundVolume{
+		Exchange: "binance_spot",
+		Pair:     "BTC/USDT",
+		Side:     "asks", // Zero price means nothing was found on this side
+	})
+	assert.NoError(t, err)
+	assert.False(t, isNew) // A removed volume isn't new
+
+	foundVolumes, err := foundVolumesService.GetAllFoundVolume(userPairData.UserID, models.FoundVolumesFilter{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, foundVolumes)
+}
+
+// TestFoundVolumesService_UpsertFoundVolumeSide tests that only the found volumes of the sides watched by the user pair
+// are recorded, and that a volume of a side which isn't watched anymore is removed.
+func TestFoundVolumesService_UpsertFoundVolumeSide(t *testing.T) {
+	t.Parallel()
+
+	asksVolume := models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50100, Volume: 12}
+	bidsVolume := models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "bids", Price: 49900, Volume: 15}
+
+	tests := []struct {
+		name          string   // Name of the test case
+		side          string   // Side setting of the user pair
+		expectedSides []string // Sides of the recorded found volumes
+	}{
+		{name: "Default Side", side: "", expectedSides: []string{"asks", "bids"}},
+		{name: "Both Sides", side: models.SideBoth, expectedSides: []string{"asks", "bids"}},
+		{name: "Asks Only", side: models.SideAsks, expectedSides: []string{"asks"}},
+		{name: "Bids Only", side: models.SideBids, expectedSides: []string{"bids"}},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			userPairData := models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10, Side: tc.side}
+
+			mockRepo := mocks.NewFoundVolumesRepository(t)
+			for _, volume := range []models.FoundVolume{asksVolume, bidsVolume} {
+				if userPairData.WatchesSide(volume.Side) {
+					mockRepo.On("Upsert", mock.Anything, 1, volume).Return(nil).Once()
+				} else { // The volume of the side which isn't watched is deleted rather than stored
+					mockRepo.On("Delete", mock.Anything, 1, models.FoundVolume{
+						Exchange: volume.Exchange,
+						Pair:     volume.Pair,
+						Side:     volume.Side,
+					}).Return(nil).Once()
+				}
+			}
+
+			foundVolumesService := service.NewFoundVolumesService(mockRepo, notifyAllSettingsService(t), mocks.NewLogger(t), contextTimeout)
+			subscriber, unsubscribe := foundVolumesService.Subscribe(userPairData.UserID)
+			defer unsubscribe()
+
+			for _, volume := range []models.FoundVolume{asksVolume, bidsVolume} {
+				isNew, err := foundVolumesService.UpsertFoundVolume(ctx, userPairData, volume)
+				assert.NoError(t, err)
+				assert.Equal(t, userPairData.WatchesSide(volume.Side), isNew) // The volume of the side which isn't watched isn't published
+			}
+
+			foundVolumes, err := foundVolumesService.GetAllFoundVolume(userPairData.UserID, models.FoundVolumesFilter{})
+			assert.NoError(t, err)
+
+			sides := []string{}
+			for _, volume := range foundVolumes {
+				sides = append(sides, volume.Side)
+			}
+			assert.ElementsMatch(t, tc.expectedSides, sides)
+			assert.Len(t, subscriber, len(tc.expectedSides))
+		})
+	}
+}
+
+// TestFoundVolumesService_UpsertFoundVolumeDeduplication tests that only the found volumes which first appear
+// or materially change are reported as new and pushed to the subscribers.
+func TestFoundVolumesService_UpsertFoundVolumeDeduplication(t *testing.T) {
+	t.Parallel()
+
+	userPairData := models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10}
+	foundVolume := models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 100}
+
+	mockRepo := mocks.NewFoundVolumesRepository(t)
+	mockRepo.On("Upsert", mock.Anything, 1, mock.Anything).Return(nil)
+	mockRepo.On("Delete", mock.Anything, 1, mock.Anything).Return(nil)
+
+	foundVolumesService := service.NewFoundVolumesService(mockRepo, notifyAllSettingsService(t), mocks.NewLogger(t), contextTimeout)
+	subscriber, unsubscribe := foundVolumesService.Subscribe(userPairData.UserID)
+	defer unsubscribe()
+
+	upsert := func(price, volume float64) bool {
+		changed := foundVolume
+		changed.Price, changed.Volume = price, volume
+
+		isNew, err := foundVolumesService.UpsertFoundVolume(ctx, userPairData, changed)
+		assert.NoError(t, err)
+
+		return isNew
+	}
+
+	assert.True(t, upsert(50000, 100))  // The level first appears
+	assert.False(t, upsert(50000, 100)) // The same level is found again on the next scan
+	assert.False(t, upsert(50000, 100))
+	assert.False(t, upsert(50000, 103)) // A small volume change is noise
+	assert.True(t, upsert(50000, 150))  // The volume changed materially
+	assert.True(t, upsert(50100, 150))  // The level moved to another price
+	assert.False(t, upsert(0, 0))       // Nothing is found anymore
+	assert.True(t, upsert(50100, 150))  // The level appears again
+
+	assert.Len(t, subscriber, 4) // Only the new found volumes are pushed
+}
+
+// TestFoundVolumesService_UpsertFoundVolumeBelowMinVolumeNotify tests that the found volumes below the minimum volume
+// of the user settings are stored but not published to the subscribers, e.g. the notification channels.
+func TestFoundVolumesService_UpsertFoundVolumeBelowMinVolumeNotify(t *testing.T) {
+	t.Parallel()
+
+	userPairData := models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10}
+
+	mockRepo := mocks.NewFoundVolumesRepository(t)
+	mockRepo.On("Upsert", mock.Anything, 1, mock.Anything).Return(nil)
+
+	mockSettingsRepo := mocks.NewUserSettingsRepository(t)
+	mockSettingsRepo.On("Get", mock.Anything, 1).Return(models.UserSettings{UserID: 1, MinVolumeNotify: 100}, nil).Once()
+
+	small := models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 12}
+	large := models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "bids", Price: 49000, Volume: 120}
+
+	userSettingsService := service.NewUserSettingsService(mockSettingsRepo, contextTimeout)
+	foundVolumesService := service.NewFoundVolumesService(mockRepo, userSettingsService, mocks.NewLogger(t), contextTimeout)
+	subscriber, unsubscribe := foundVolumesService.Subscribe(userPairData.UserID)
+	defer unsubscribe()
+
+	for _, foundVolume := range []models.FoundVolume{small, large} {
+		isNew, err := foundVolumesService.UpsertFoundVolume(ctx, userPairData, foundVolume)
+
+		assert.NoError(t, err)
+		assert.True(t, isNew) // Both volumes are new, the settings only decide about the notification
+	}
+
+	if assert.Len(t, subscriber, 1) { // Only the volume above the minimum is published
+		expected := large
+		expected.UserID = userPairData.UserID
+
+		assert.Equal(t, expected, <-subscriber)
+	}
+
+	foundVolumes, err := foundVolumesService.GetAllFoundVolume(userPairData.UserID, models.FoundVolumesFilter{})
+
+	assert.NoError(t, err)
+	assert.Len(t, foundVolumes, 2) // Both volumes are stored regardless of the settings
+}
+
+// TestFoundVolumesService_Subscribe tests that every subscriber of the user and of all users receives the same
+// found volume independently, the subscribers of other users receive nothing and unsubscribing closes the channel.
+func TestFoundVolumesService_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	userPairData := models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10}
+	foundVolume := models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 100}
+
+	mockRepo := mocks.NewFoundVolumesRepository(t)
+	mockRepo.On("Upsert", mock.Anything, 1, mock.Anything).Return(nil)
+
+	foundVolumesService := service.NewFoundVolumesService(mockRepo, notifyAllSettingsService(t), mocks.NewLogger(t), contextTimeout)
+
+	websocket, unsubscribeWebsocket := foundVolumesService.Subscribe(userPairData.UserID)
+	secondWebsocket, unsubscribeSecondWebsocket := foundVolumesService.Subscribe(userPairData.UserID)
+	notifications, unsubscribeNotifications := foundVolumesService.Subscribe(service.AllUsers)
+	otherUser, unsubscribeOtherUser := foundVolumesService.Subscribe(2)
+
+	defer unsubscribeSecondWebsocket()
+	defer unsubscribeNotifications()
+	defer unsubscribeOtherUser()
+
+	isNew, err := foundVolumesService.UpsertFoundVolume(ctx, userPairData, foundVolume)
+	assert.NoError(t, err)
+	assert.True(t, isNew)
+
+	expected := foundVolume
+	expected.UserID = userPairData.UserID
+
+	for _, subscriber := range []<-chan models.FoundVolume{websocket, secondWebsocket, notifications} {
+		if assert.Len(t, subscriber, 1) {
+			assert.Equal(t, expected, <-subscriber)
+		}
+	}
+
+	assert.Empty(t, otherUser) // The found volumes of other users aren't received
+
+	unsubscribeWebsocket()
+	unsubscribeWebsocket() // Unsubscribing again is a no-op
+
+	_, ok := <-websocket
+	assert.False(t, ok) // The channel is closed once unsubscribed
+
+	changed := foundVolume
+	changed.Price = 50100
+
+	_, err = foundVolumesService.UpsertFoundVolume(ctx, userPairData, changed)
+	assert.NoError(t, err)
+
+	assert.Len(t, secondWebsocket, 1) // The remaining subscribers still receive the found volumes
+	assert.Len(t, notifications, 1)
+}
+
+// TestFoundVolumesService_SubscribeOverflow tests that a slow subscriber misses the found volumes
+// which don't fit into its buffer instead of blocking the scanner, and the dropped ones are logged.
+func TestFoundVolumesService_SubscribeOverflow(t *testing.T) {
+	t.Parallel()
+
+	userPairData := models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10}
+
+	mockRepo := mocks.NewFoundVolumesRepository(t)
+	mockRepo.On("Upsert", mock.Anything, 1, mock.Anything).Return(nil)
+
+	mockLogger := mocks.NewLogger(t)
+	mockLogger.On("Warnf", mock.Anything, "BTC/USDT", "binance_spot", 1).Once() // Only the found volume beyond the buffer is dropped
+
+	foundVolumesService := service.NewFoundVolumesService(mockRepo, notifyAllSettingsService(t), mockLogger, contextTimeout)
+	subscriber, unsubscribe := foundVolumesService.Subscribe(userPairData.UserID)
+	defer unsubscribe()
+
+	buffer := 64 // The buffer of the subscriber of a user
+	for i := 0; i <= buffer; i++ {
+		foundVolume := models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: float64(50000 + i), Volume: 100}
+
+		isNew, err := foundVolumesService.UpsertFoundVolume(ctx, userPairData, foundVolume)
+		assert.NoError(t, err)
+		assert.True(t, isNew)
+	}
+
+	assert.Len(t, subscriber, buffer)
+}
+
+// TestFoundVolumesService_DeleteFoundVolume tests that both sides of the found volume are deleted.
+func TestFoundVolumesService_DeleteFoundVolume(t *testing.T) {
+	t.Parallel()
+
+	userPairData := models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT"}
+
+	mockRepo := mocks.NewFoundVolumesRepository(t)
+	mockRepo.On("Upsert", mock.Anything, 1, mock.Anything).Return(nil)
+	mockRepo.On("Delete", mock.Anything, 1, models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks"}).Return(nil).Once()
+	mockRepo.On("Delete", mock.Anything, 1, models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "bids"}).Return(nil).Once()
+
+	foundVolumesService := service.NewFoundVolumesService(mockRepo, notifyAllSettingsService(t), mocks.NewLogger(t), contextTimeout)
+
+	for _, side := range []string{"asks", "bids"} {
+		_, err := foundVolumesService.UpsertFoundVolume(ctx, userPairData, models.FoundVolume{
+			Exchange: "binance_spot",
+			Pair:     "BTC/USDT",
+			Side:     side,
+			Price:    50000,
+			Volume:   12,
+		})
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, foundVolumesService.DeleteFoundVolume(ctx, userPairData))
+
+	foundVolumes, err := foundVolumesService.GetAllFoundVolume(userPairData.UserID, models.FoundVolumesFilter{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, foundVolumes)
+}
+
+// TestFoundVolumesService_DeleteFoundVolumeWithoutStoredVolumes tests that deleting the found volume
+// of a user who has none stored does nothing.
+func TestFoundVolumesService_DeleteFoundVolumeWithoutStoredVolumes(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := mocks.NewFoundVolumesRepository(t) // No repository calls are expected
+
+	foundVolumesService := service.NewFoundVolumesService(mockRepo, notifyAllSettingsService(t), mocks.NewLogger(t), contextTimeout)
+
+	assert.NotPanics(t, func() {
+		err := foundVolumesService.DeleteFoundVolume(ctx, models.UserPairs{UserID: 42, Exchange: "binance_spot", Pair: "BTC/USDT"})
+		assert.NoError(t, err)
+	})
+}
+
+// TestFoundVolumesService_DeleteCollidingFoundVolume tests that found volumes of pairs and exchanges
+// whose concatenations overlap are stored separately and deleting one of them keeps the other.
+func TestFoundVolumesService_DeleteCollidingFoundVolume(t *testing.T) {
+	t.Parallel()
+
+	deleted := models.UserPairs{UserID: 1, Exchange: "USDTbinance", Pair: "BTC"}
+	kept := models.UserPairs{UserID: 1, Exchange: "binance", Pair: "BTCUSDT"} // "BTC" + "USDTbinance" == "BTCUSDT" + "binance"
+
+	mockRepo := mocks.NewFoundVolumesRepository(t)
+	mockRepo.On("Upsert", mock.Anything, 1, mock.Anything).Return(nil)
+	mockRepo.On("Delete", mock.Anything, 1, models.FoundVolume{Exchange: "USDTbinance", Pair: "BTC", Side: "asks"}).Return(nil).Once()
+	mockRepo.On("Delete", mock.Anything, 1, models.FoundVolume{Exchange: "USDTbinance", Pair: "BTC", Side: "bids"}).Return(nil).Once()
+
+	foundVolumesService := service.NewFoundVolumesService(mockRepo, notifyAllSettingsService(t), mocks.NewLogger(t), contextTimeout)
+
+	for _, userPairData := range []models.UserPairs{deleted, kept} {
+		_, err := foundVolumesService.UpsertFoundVolume(ctx, userPairData, models.FoundVolume{
+			Exchange: userPairData.Exchange,
+			Pair:     userPairData.Pair,
+			Side:     "asks",
+			Price:    50000,
+			Volume:   12,
+		})
+		assert.NoError(t, err)
+	}
+
+	foundVolumes, err := foundVolumesService.GetAllFoundVolume(1, models.FoundVolumesFilter{})
+
+	assert.NoError(t, err)
+	assert.Len(t, foundVolumes, 2) // Both found volumes are stored under distinct keys
+
+	assert.NoError(t, foundVolumesService.DeleteFoundVolume(ctx, deleted))
+
+	foundVolumes, err = foundVolumesService.GetAllFoundVolume(1, models.FoundVolumesFilter{})
+
+	assert.NoError(t, err)
+	if assert.Len(t, foundVolumes, 1) {
+		assert.Equal(t, kept.Pair, foundVolumes[0].Pair)
+		assert.Equal(t, kept.Exchange, foundVolumes[0].Exchange)
+	}
+}
+
+// TestFoundVolumesService_GetFoundVolumesFromDB tests that the cache is rehydrated from the repository.
+func TestFoundVolumesService_GetFoundVolumesFromDB(t *testing.T) {
+	t.Parallel()
+
+	storedVolumes := []models.FoundVolume{
+		{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 12},
+		{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "bids", Price: 49000, Volume: 8},
+	}
+
+	mockRepo := mocks.NewFoundVolumesRepository(t)
+	mockRepo.On("GetByUser", mock.Anything, 1).Return(storedVolumes, nil)
+	mockRepo.On("GetByUser", mock.Anything, 2).Return(nil, nil)
+
+	foundVolumesService := service.NewFoundVolumesService(mockRepo, notifyAllSettingsService(t), mocks.NewLogger(t), contextTimeout)
+
+	assert.NoError(t, foundVolumesService.GetFoundVolumesFromDB(ctx, []int{1, 2}))
+
+	foundVolumes, err := foundVolumesService.GetAllFoundVolume(1, models.FoundVolumesFilter{})
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, storedVolumes, foundVolumes)
+
+	_, err = foundVolumesService.GetAllFoundVolume(2, models.FoundVolumesFilter{}) // Users without stored volumes have no cached data
+
+	assert.Error(t, err)
+}
+
+// TestFoundVolumesService_GetAllFoundVolumeFilter tests filtering, sorting and pagination of the found volumes.
+func TestFoundVolumesService_GetAllFoundVolumeFilter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	// Found volumes of the user, the index in the slice is the number of minutes since they were found
+	storedVolumes := []models.FoundVolume{
+		{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 12, VolumeTimeFound: now},
+		{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "bids", Price: 49000, Volume: 8, VolumeTimeFound: now.Add(-1 * time.Minute)},
+		{Exchange: "bybit_spot", Pair: "BTC/USDT", Side: "asks", Price: 50010, Volume: 30, VolumeTimeFound: now.Add(-2 * time.Minute)},
+		{Exchange: "bybit_spot", Pair: "ETH/USDT", Side: "bids", Price: 2900, Volume: 100, VolumeTimeFound: now.Add(-3 * time.Minute)},
+		{Exchange: "binance_spot", Pair: "ETH/USDT", Side: "asks", Price: 3000, Volume: 5, VolumeTimeFound: now.Add(-4 * time.Minute)},
+	}
+
+	mockRepo := mocks.NewFoundVolumesRepository(t)
+	mockRepo.On("GetByUser", mock.Anything, 1).Return(storedVolumes, nil)
+
+	foundVolumesService := service.NewFoundVolumesService(mockRepo, notifyAllSettingsService(t), mocks.NewLogger(t), contextTimeout)
+	assert.NoError(t, foundVolumesService.GetFoundVolumesFromDB(ctx, []int{1}))
+
+	tests := []struct {
+		name     string                    // Name of the test case
+		filter   models.FoundVolumesFilter // Filter passed to the service
+		expected []models.FoundVolume      // Expected found volumes in the expected order
+		wantErr  bool                      // Expectation of whether an error should occur
+	}{
+		{
+			name:     "No filter returns all, equal differences sorted by time",
+			filter:   models.FoundVolumesFilter{},
+			expected: storedVolumes,
+		},
+		{
+			name:     "Limit",
+			filter:   models.FoundVolumesFilter{Limit: 2},
+			expected: storedVolumes[:2],
+		},
+		{
+			name:     "Limit larger than the number of volumes",
+			filter:   models.FoundVolumesFilter{Limit: 10},
+			expected: storedVolumes,
+		},
+		{
+			name:     "Offset",
+			filter:   models.FoundVolumesFilter{Offset: 3},
+			expected: storedVolumes[3:],
+		},
+		{
+			name:     "Limit and offset",
+			filter:   models.FoundVolumesFilter{Limit: 2, Offset: 1},
+			expected: storedVolumes[1:3],
+		},
+		{
+			name:     "Offset of the last volume",
+			filter:   models.FoundVolumesFilter{Limit: 2, Offset: 4},
+			expected: storedVolumes[4:],
+		},
+		{
+			name:     "Offset equal to the number of volumes",
+			filter:   models.FoundVolumesFilter{Offset: 5},
+			expected: []models.FoundVolume{},
+		},
+		{
+			name:     "Offset past the number of volumes",
+			filter:   models.FoundVolumesFilter{Offset: 100},
+			expected: []models.FoundVolume{},
+		},
+		{
+			name:     "Exchange and side",
+			filter:   models.FoundVolumesFilter{Exchange: "binance_spot", Side: "asks"},
+			expected: []models.FoundVolume{storedVolumes[0], storedVolumes[4]},
+		},
+		{
+			name:     "Exchange and side with pagination",
+			filter:   models.FoundVolumesFilter{Exchange: "binance_spot", Side: "asks", Limit: 1, Offset: 1},
+			expected: []models.FoundVolume{storedVolumes[4]},
+		},
+		{
+			name:     "Pair and minimum volume",
+			filter:   models.FoundVolumesFilter{Pair: "BTC/USDT", MinVolume: 10},
+			expected: []models.FoundVolume{storedVolumes[0], storedVolumes[2]},
+		},
+		{
+			name:     "Nothing matches",
+			filter:   models.FoundVolumesFilter{Exchange: "kraken_spot"},
+			expected: []models.FoundVolume{},
+		},
+		{
+			name:    "Negative limit",
+			filter:  models.FoundVolumesFilter{Limit: -1},
+			wantErr: true,
+		},
+		{
+			name:    "Negative offset",
+			filter:  models.FoundVolumesFilter{Offset: -1},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid side",
+			filter:  models.FoundVolumesFilter{Side: "middle"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid sort key",
+			filter:  models.FoundVolumesFilter{Sort: "time"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid order",
+			filter:  models.FoundVolumesFilter{Order: "up"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			foundVolumes, err := foundVolumesService.GetAllFoundVolume(1, tc.filter)
+
+			if tc.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, foundVolumes)
+		})
+	}
+}
+
+// TestFoundVolumesService_GetAllFoundVolumeSort tests sorting of the found volumes by each sort key.
+func TestFoundVolumesService_GetAllFoundVolumeSort(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	// The order of the values differs for every sort key
+	near := models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Difference: 0.5, Volume: 30, VolumeTimeFound: now}
+	far := models.FoundVolume{Exchange: "binance_spot", Pair: "ETH/USDT", Side: "bids", Price: 2900, Difference: 4, Volume: 10, VolumeTimeFound: now}
+	middle := models.FoundVolume{Exchange: "bybit_spot", Pair: "SOL/USDT", Side: "asks", Price: 150, Difference: 2, Volume: 500, VolumeTimeFound: now}
+	middleOlder := models.FoundVolume{Exchange: "bybit_spot", Pair: "XRP/USDT", Side: "asks", Price: 0.5, Difference: 2, Volume: 20, VolumeTimeFound: now.Add(-time.Minute)}
+
+	mockRepo := mocks.NewFoundVolumesRepository(t)
+	mockRepo.On("GetByUser", mock.Anything, 1).Return([]models.FoundVolume{near, far, middle, middleOlder}, nil)
+
+	foundVolumesService := service.NewFoundVolumesService(mockRepo, notifyAllSettingsService(t), mocks.NewLogger(t), contextTimeout)
+	assert.NoError(t, foundVolumesService.GetFoundVolumesFromDB(ctx, []int{1}))
+
+	tests := []struct {
+		name     string                    // Name of the test case
+		filter   models.FoundVolumesFilter // Filter passed to the service
+		expected []models.FoundVolume      // Expected found volumes in the expected order
+	}{
+		{
+			name:     "Difference descending by default",
+			filter:   models.FoundVolumesFilter{},
+			expected: []models.FoundVolume{far, middle, middleOlder, near}, // Equal differences are sorted by time
+		},
+		{
+			name:     "Difference ascending",
+			filter:   models.FoundVolumesFilter{Sort: "difference", Order: "asc"},
+			expected: []models.FoundVolume{near, middle, middleOlder, far},
+		},
+		{
+			name:     "Volume descending",
+			filter:   models.FoundVolumesFilter{Sort: "volume", Order: "desc"},
+			expected: []models.FoundVolume{middle, near, middleOlder, far},
+		},
+		{
+			name:     "Volume ascending",
+			filter:   models.FoundVolumesFilter{Sort: "volume", Order: "asc"},
+			expected: []models.FoundVolume{far, middleOlder, near, middle},
+		},
+		{
+			name:     "Price descending by default order",
+			filter:   models.FoundVolumesFilter{Sort: "price"},
+			expected: []models.FoundVolume{near, far, middle, middleOlder},
+		},
+		{
+			name:     "Price ascending",
+			filter:   models.FoundVolumesFilter{Sort: "price", Order: "asc"},
+			expected: []models.FoundVolume{middleOlder, middle, far, near},
+		},
+		{
+			name:     "Sorted before pagination",
+			filter:   models.FoundVolumesFilter{Sort: "volume", Limit: 2, Offset: 1},
+			expected: []models.FoundVolume{near, middleOlder},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			foundVolumes, err := foundVolumesService.GetAllFoundVolume(1, tc.filter)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, foundVolumes)
+		})
+	}
+}
+
+// TestFoundVolumesService_ExpirySweeper tests that the sweeper removes the found volumes older than the TTL
+// and notifies the subscribers of the user that they expired, while the recently found volumes are kept.
+func TestFoundVolumesService_ExpirySweeper(t *testing.T) {
+	t.Parallel()
+
+	userPairData := models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT"}
+	expiredVolume := models.FoundVolume{
+		Exchange:        "binance_spot",
+		Pair:            "BTC/USDT",
+		Side:            "asks",
+		Price:           50000,
+		Volume:          12,
+		VolumeTimeFound: time.Now().Add(-time.Hour), // The wall wasn't found again for an hour
+	}
+	recentVolume := models.FoundVolume{
+		Exchange:        "binance_spot",
+		Pair:            "BTC/USDT",
+		Side:            "bids",
+		Price:           49000,
+		Volume:          15,
+		VolumeTimeFound: time.Now(),
+	}
+
+	deleted := make(chan struct{}) // Closed once the expired found volume is deleted from the database
+
+	mockRepo := mocks.NewFoundVolumesRepository(t)
+	mockRepo.On("Upsert", mock.Anything, 1, mock.Anything).Return(nil)
+	mockRepo.On("Delete", mock.Anything, 1, mock.MatchedBy(func(foundVolume models.FoundVolume) bool {
+		return foundVolume.Side == "asks"
+	})).Return(nil).Once().Run(func(mock.Arguments) { close(deleted) })
+
+	foundVolumesService := service.NewFoundVolumesService(mockRepo, notifyAllSettingsService(t), mocks.NewLogger(t), contextTimeout)
+
+	for _, foundVolume := range []models.FoundVolume{expiredVolume, recentVolume} {
+		_, err := foundVolumesService.UpsertFoundVolume(ctx, userPairData, foundVolume)
+		assert.NoError(t, err)
+	}
+
+	subscriber, unsubscribe := foundVolumesService.Subscribe(userPairData.UserID)
+	defer unsubscribe()
+
+	sweeperCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	foundVolumesService.StartExpirySweeper(sweeperCtx, time.Minute, 10*time.Millisecond, mocks.NewLogger(t))
+
+	select {
+	case foundVolume := <-subscriber:
+		assert.True(t, foundVolume.Expired)
+		assert.Equal(t, "asks", foundVolume.Side)
+	case <-time.After(time.Second):
+		t.Fatal("the subscriber wasn't notified about the expired found volume")
+	}
+
+	select {
+	case <-deleted:
+	case <-time.After(time.Second):
+		t.Fatal("the expired found volume wasn't deleted from the database")
+	}
+
+	foundVolumes, err := foundVolumesService.GetAllFoundVolume(userPairData.UserID, models.FoundVolumesFilter{})
+
+	assert.NoError(t, err)
+	assert.Len(t, foundVolumes, 1)
+	assert.Equal(t, "bids", foundVolumes[0].Side) // The recently found volume is kept
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cvs/api/server/controller"
+	"cvs/internal/mocks"
+	"cvs/internal/models"
+	"cvs/internal/service/exchange"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	app := fiber.New()
+
+	healthController := controller.NewHealthController(mocks.NewAllExchanges(t), time.Minute)
+	app.Get("/health", healthController.Health)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/health", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestReadyController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	now := time.Now()
+
+	tests := []struct {
+		name          string                  // Name of the test case
+		statuses      []models.ExchangeStatus // Statuses returned by the exchanges
+		expectedCode  int                     // Expected HTTP status code after the request
+		expectedReady []bool                  // Expected readiness of the exchanges sorted by name
+	}{
+		{
+			name: "Healthy",
+			statuses: []models.ExchangeStatus{
+				{Exchange: "bybit_spot", SubscriptionsLoaded: true, LastSuccessfulFetch: now.Add(-10 * time.Second), SubscribedPairs: 1},
+				{Exchange: "binance_spot", SubscriptionsLoaded: true, LastSuccessfulFetch: now.Add(-time.Second), SubscribedPairs: 2},
+			},
+			expectedCode:  http.StatusOK,
+			expectedReady: []bool{true, true},
+		},
+		{
+			name: "Idle Exchange Isn't Stale",
+			statuses: []models.ExchangeStatus{
+				{Exchange: "binance_spot", SubscriptionsLoaded: true, LastSuccessfulFetch: now.Add(-time.Hour)}, // Only the pairs were fetched on start
+			},
+			expectedCode:  http.StatusOK,
+			expectedReady: []bool{true},
+		},
+		{
+			name: "Stale",
+			statuses: []models.ExchangeStatus{
+				{Exchange: "binance_spot", SubscriptionsLoaded: true, LastSuccessfulFetch: now.Add(-time.Second), SubscribedPairs: 1},
+				{Exchange: "bybit_spot", SubscriptionsLoaded: true, LastSuccessfulFetch: now.Add(-2 * time.Minute), LastError: "response has no body", SubscribedPairs: 1},
+			},
+			expectedCode:  http.StatusServiceUnavailable,
+			expectedReady: []bool{true, false},
+		},
+		{
+			name: "Subscriptions Not Loaded",
+			statuses: []models.ExchangeStatus{
+				{Exchange: "binance_spot", LastSuccessfulFetch: now.Add(-time.Second)}, // The subscribed pairs failed to load at startup
+			},
+			expectedCode:  http.StatusServiceUnavailable,
+			expectedReady: []bool{false},
+		},
+		{
+			name: "Never Fetched",
+			statuses: []models.ExchangeStatus{
+				{Exchange: "binance_spot", SubscriptionsLoaded: true, LastError: "response has no body"},
+			},
+			expectedCode:  http.StatusServiceUnavailable,
+			expectedReady: []bool{false},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allows subtests to run in parallel
+
+			app := fiber.New()
+
+			exchanges := []exchange.Exchange{}
+			for _, status := range tc.statuses {
+				mockExchange := mocks.NewExchange(t)
+				mockExchange.On("Status").Return(status)
+
+				exchanges = append(exchanges, mockExchange)
+			}
+
+			mockAllExchangesStorage := mocks.NewAllExchanges(t)
+			mockAllExchangesStorage.On("All").Return(exchanges)
+
+			healthController := controller.NewHealthController(mockAllExchangesStorage, time.Minute)
+			app.Get("/ready", healthController.Ready)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/ready", nil))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			var readiness models.Readiness
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&readiness))
+			assert.Equal(t, tc.expectedCode == http.StatusOK, readiness.Ready)
+
+			ready := []bool{}
+			for _, exchangeReadiness := range readiness.Exchanges {
+				ready = append(ready, exchangeReadiness.Ready)
+			}
+			assert.Equal(t, tc.expectedReady, ready)
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cvs/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHttpRequestService_GetWithRetry tests the GetWithRetry function of the HttpRequest service.
+func TestHttpRequestService_GetWithRetry(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	tests := []struct {
+		name             string        // Name of the test case
+		failures         int32         // Number of failed responses before the successful one
+		failureStatus    int           // Status code of the failed responses
+		retryAfter       string        // Value of the Retry-After header of the failed responses
+		attempts         int           // Maximum number of attempts
+		expectedStatus   int           // Expected status code of the returned response
+		expectedRequests int32         // Expected number of requests received by the server
+		minDuration      time.Duration // Minimum expected duration of the call
+	}{
+		{
+			name:             "Service Unavailable Twice Then OK",
+			failures:         2,
+			failureStatus:    http.StatusServiceUnavailable,
+			attempts:         3,
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 3,
+			minDuration:      30 * time.Millisecond, // 10ms and 20ms of exponential backoff
+		},
+		{
+			name:             "Attempts Exhausted",
+			failures:         5,
+			failureStatus:    http.StatusServiceUnavailable,
+			attempts:         2,
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedRequests: 2,
+		},
+		{
+			name:             "Too Many Requests With Retry-After",
+			failures:         1,
+			failureStatus:    http.StatusTooManyRequests,
+			retryAfter:       "1",
+			attempts:         3,
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 2,
+			minDuration:      time.Second, // The Retry-After delay is used instead of the backoff
+		},
+		{
+			name:             "Client Error Is Not Retried",
+			failures:         1,
+			failureStatus:    http.StatusNotFound,
+			attempts:         3,
+			expectedStatus:   http.StatusNotFound,
+			expectedRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) <= tc.failures {
+					if tc.retryAfter != "" {
+						w.Header().Set("Retry-After", tc.retryAfter)
+					}
+					w.WriteHeader(tc.failureStatus)
+
+					return
+				}
+
+				w.Write([]byte("ok"))
+			}))
+			defer server.Close()
+
+			httpRequestService := service.NewHttpRequestService(time.Second, "")
+
+			start := time.Now()
+			resp, err := httpRequestService.GetWithRetry(context.Background(), server.URL, nil, tc.attempts, 10*time.Millisecond)
+			elapsed := time.Since(start)
+
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tc.expectedRequests, requests.Load())
+			assert.GreaterOrEqual(t, elapsed, tc.minDuration)
+
+			if tc.expectedStatus == http.StatusOK {
+				body, err := io.ReadAll(resp.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, "ok", string(body))
+			}
+		})
+	}
+}
+
+// TestHttpRequestService_GetWithRetry_NetworkError tests that network errors are retried until the attempts are exhausted.
+func TestHttpRequestService_GetWithRetry_NetworkError(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close() // Close the server so every request fails to connect
+
+	httpRequestService := service.NewHttpRequestService(time.Second, "")
+
+	start := time.Now()
+	_, err := httpRequestService.GetWithRetry(context.Background(), url, nil, 3, 10*time.Millisecond)
+
+	assert.Error(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond) // Both retries waited for the backoff
+}
+
+// TestHttpRequestService_GetWithRetry_ContextCancel tests that cancelling the context stops the wait before a retry,
+// even if the server asked for a long delay by the Retry-After header.
+func TestHttpRequestService_GetWithRetry_ContextCancel(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	httpRequestService := service.NewHttpRequestService(time.Second, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := httpRequestService.GetWithRetry(ctx, server.URL, nil, 3, 10*time.Millisecond)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second) // The Retry-After delay wasn't waited out
+	assert.Equal(t, int32(1), requests.Load())       // No retry was sent after the cancellation
+}
+
+// TestHttpRequestService_GetTimeout tests that a request to a server hanging longer than the timeout
+// is cancelled and returns a timeout error promptly, so the caller moves on.
+func TestHttpRequestService_GetTimeout(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	const requestTimeout = 50 * time.Millisecond
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(5 * time.Second): // Hang much longer than the timeout
+		case <-r.Context().Done(): // The client cancelled the request
+		}
+	}))
+	defer server.Close()
+
+	httpRequestService := service.NewHttpRequestService(requestTimeout, "")
+
+	start := time.Now()
+	_, err := httpRequestService.Get(server.URL, nil)
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "the error must be a timeout, got %v", err)
+	assert.Less(t, time.Since(start), time.Second) // Returned right after the timeout instead of waiting for the server
+}
+
+// TestHttpRequestService_GetBodyAfterResponse tests that the body of a response can be read after Get returns,
+// that is the context of the request isn't cancelled before the body is closed.
+func TestHttpRequestService_GetBodyAfterResponse(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	httpRequestService := service.NewHttpRequestService(time.Second, "")
+
+	resp, err := httpRequestService.Get(server.URL, nil)
+	assert.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+}
+
+// TestHttpRequestService_GetHeaders tests that the outgoing requests carry the configured User-Agent header
+// and the headers passed by the caller, which override the User-Agent header if they set it.
+func TestHttpRequestService_GetHeaders(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	tests := []struct {
+		name              string      // Name of the test case
+		userAgent         string      // User-Agent header configured for the service
+		headers           http.Header // Headers passed to the request
+		expectedUserAgent string      // Expected User-Agent header of the outgoing request
+		expectedApiKey    string      // Expected API key header of the outgoing request
+	}{
+		{
+			name:              "Default User-Agent",
+			expectedUserAgent: "Crypto-Volume-Scanner",
+		},
+		{
+			name:              "Configured User-Agent",
+			userAgent:         "scanner/1.0",
+			expectedUserAgent: "scanner/1.0",
+		},
+		{
+			name:              "API Key Header",
+			userAgent:         "scanner/1.0",
+			headers:           http.Header{"X-Mbx-Apikey": {"secret"}},
+			expectedUserAgent: "scanner/1.0",
+			expectedApiKey:    "secret",
+		},
+		{
+			name:              "Headers Override User-Agent",
+			userAgent:         "scanner/1.0",
+			headers:           http.Header{"User-Agent": {"custom"}},
+			expectedUserAgent: "custom",
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			received := make(chan http.Header, 1) // Headers of the request received by the server
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received <- r.Header.Clone()
+			}))
+			defer server.Close()
+
+			httpRequestService := service.NewHttpRequestService(time.Second, tc.userAgent)
+
+			resp, err := httpRequestService.GetWithRetry(context.Background(), server.URL, tc.headers, 1, 10*time.Millisecond)
+			assert.NoError(t, err)
+
+			defer resp.Body.Close()
+
+			headers := <-received
+			assert.Equal(t, tc.expectedUserAgent, headers.Get("User-Agent"))
+			assert.Equal(t, tc.expectedApiKey, headers.Get("X-MBX-APIKEY"))
+		})
+	}
+}
+
+// TestParseRateLimitUsage tests that the rate limit headers of Binance and Bybit are parsed.
+func TestParseRateLimitUsage(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	tests := []struct {
+		name     string                 // Name of the test case
+		header   http.Header            // Headers of the response
+		expected service.RateLimitUsage // Expected usage
+		ok       bool                   // Whether the headers are expected to be found
+		ratio    float64                // Expected used part of the limit
+	}{
+		{
+			name:     "Binance Used Weight",
+			header:   http.Header{"X-Mbx-Used-Weight-1m": {"4800"}},
+			expected: service.RateLimitUsage{Used: 4800}, // Binance doesn't tell the limit
+			ok:       true,
+		},
+		{
+			name:     "Bybit Limit",
+			header:   http.Header{"X-Bapi-Limit": {"120"}, "X-Bapi-Limit-Status": {"30"}},
+			expected: service.RateLimitUsage{Used: 90, Limit: 120},
+			ok:       true,
+			ratio:    0.75,
+		},
+		{
+			name:   "Bybit Remaining Missing",
+			header: http.Header{"X-Bapi-Limit": {"120"}},
+		},
+		{
+			name:   "Invalid Value",
+			header: http.Header{"X-Mbx-Used-Weight-1m": {"a lot"}},
+		},
+		{
+			name:   "No Headers",
+			header: http.Header{},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			usage, ok := service.ParseRateLimitUsage(tc.header)
+
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, usage)
+			assert.Equal(t, tc.ratio, usage.Ratio())
+		})
+	}
+}
+
+// TestRateLimitObserver tests that the callback gets the rate limit usage of every response carrying the headers.
+func TestRateLimitObserver(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	var usedWeight atomic.Value
+	usedWeight.Store("100")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if weight := usedWeight.Load().(string); weight != "" {
+			w.Header().Set("X-MBX-USED-WEIGHT-1M", weight)
+		}
+	}))
+	defer server.Close()
+
+	var usages []service.RateLimitUsage
+	httpRequestService := service.NewRateLimitObserver(service.NewHttpRequestService(time.Second, ""), func(usage service.RateLimitUsage) {
+		usages = append(usages, usage)
+	})
+
+	resp, err := httpRequestService.Get(server.URL, nil)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	usedWeight.Store("5900")
+	resp, err = httpRequestService.GetWithRetry(context.Background(), server.URL, nil, 1, time.Millisecond)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	usedWeight.Store("") // The response without the headers isn't reported
+	resp, err = httpRequestService.Get(server.URL, nil)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []service.RateLimitUsage{{Used: 100}, {Used: 5900}}, usages)
+}
@@ -4,6 +4,10 @@ import (
 	"testing"
 	"time"
 
+	"cvs/internal/models"
+	"cvs/internal/service"
+
+	"github.com/golang-jwt/jwt"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -11,23 +15,29 @@ import (
 func TestJwtService_CreateAccessToken(t *testing.T) {
 	t.Parallel() // Run tests in parallel for efficiency
 
-	// Define test cases with userId and sessionId
+	// Define test cases with userId, sessionId and role
 	tests := []struct {
-		userId    int // User ID for token creation
-		sessionId int // Session ID for token creation
+		userId    int    // User ID for token creation
+		sessionId int    // Session ID for token creation
+		role      string // Role of the user for token creation
 	}{
-		{1, 123}, // Test case 1
-		{2, 456}, // Test case 2
+		{1, 123, models.RoleUser},  // Test case 1
+		{2, 456, models.RoleAdmin}, // Test case 2
 	}
 
 	for _, tt := range tests {
 		t.Run("CreateAccessToken", func(t *testing.T) {
-			// Create an access token using the userId and sessionId
-			token, expiresAt, err := jwtService.CreateAccessToken(tt.userId, tt.sessionId)
+			// Create an access token using the userId, sessionId and role
+			token, expiresAt, err := jwtService.CreateAccessToken(tt.userId, tt.sessionId, tt.role)
+
+			assert.NoError(t, err)                        // Ensure no error occurred during token creation
+			assert.NotEmpty(t, token)                     // Ensure the token is not empty
+			assert.True(t, time.Now().Unix() < expiresAt) // Ensure the token is not expired
 
-			assert.NoError(t, err)                             // Ensure no error occurred during token creation
-			assert.NotEmpty(t, token)                          // Ensure the token is not empty
-			assert.True(t, time.Now().UnixMilli() < expiresAt) // Ensure the token is not expired
+			claims := jwt.MapClaims{}
+			_, _, err = new(jwt.Parser).ParseUnverified(token, claims)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.role, claims["role"]) // The role of the user is included in the claims
 		})
 	}
 }
@@ -64,7 +74,7 @@ func TestJwtService_Parse_ValidToken(t *testing.T) {
 	sessionId := 9879 // Define session ID for testing
 
 	// Create a valid access token to be parsed later
-	tokenString, _, _ := jwtService.CreateAccessToken(userId, sessionId)
+	tokenString, _, _ := jwtService.CreateAccessToken(userId, sessionId, models.RoleUser)
 
 	t.Run("Parse_ValidToken", func(t *testing.T) {
 		// Parse the created token to retrieve user ID and session ID
@@ -91,3 +101,482 @@ func TestJwtService_Parse_InvalidToken(t *testing.T) {
 		assert.Equal(t, 0, sessionId) // Validate that session ID is zero when parsing fails
 	})
 }
+
+// TestJwtService_Parse_ClaimsValidation tests that Parse accepts only the tokens signed by HS256
+// and carrying the issuer and the audience of the service.
+func TestJwtService_Parse_ClaimsValidation(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	// Helper function to sign the claims of a valid access token by the signing method
+	signedToken := func(method jwt.SigningMethod, key interface{}, issuer, audience string) string {
+		claims := jwt.MapClaims{
+			"user_id":    1,
+			"session_id": 2,
+			"exp":        time.Now().Add(time.Hour).Unix(),
+		}
+		if issuer != "" {
+			claims["iss"] = issuer
+		}
+		if audience != "" {
+			claims["aud"] = audience
+		}
+
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			panic(err)
+		}
+
+		return token
+	}
+
+	refreshToken, err := jwtService.CreateRefreshToken(1, 2)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string // Name of the test case
+		token   string // Token to parse
+		wantErr bool   // Whether the token is expected to be rejected
+	}{
+		{
+			name:  "Valid Token",
+			token: signedToken(jwt.SigningMethodHS256, []byte("secret_key"), jwtIssuer, jwtAudience),
+		},
+		{
+			name:    "Refresh Token",
+			token:   refreshToken, // Refresh tokens don't authenticate requests
+			wantErr: true,
+		},
+		{
+			name:    "Wrong Algorithm",
+			token:   signedToken(jwt.SigningMethodHS384, []byte("secret_key"), jwtIssuer, jwtAudience),
+			wantErr: true,
+		},
+		{
+			name:    "Unsigned Token",
+			token:   signedToken(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, jwtIssuer, jwtAudience),
+			wantErr: true,
+		},
+		{
+			name:    "Wrong Issuer",
+			token:   signedToken(jwt.SigningMethodHS256, []byte("secret_key"), "other_service", jwtAudience),
+			wantErr: true,
+		},
+		{
+			name:    "Missing Issuer",
+			token:   signedToken(jwt.SigningMethodHS256, []byte("secret_key"), "", jwtAudience),
+			wantErr: true,
+		},
+		{
+			name:    "Wrong Audience",
+			token:   signedToken(jwt.SigningMethodHS256, []byte("secret_key"), jwtIssuer, "other_api"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			userId, sessionId, err := jwtService.Parse(tc.token)
+
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, 0, userId)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, userId)
+			assert.Equal(t, 2, sessionId)
+		})
+	}
+}
+
+// TestJwtService_IssuerAndAudienceClaims tests that the issued tokens carry the configured issuer and audience,
+// and that the claims aren't set nor validated when they aren't configured.
+func TestJwtService_IssuerAndAudienceClaims(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	token, _, err := jwtService.CreateAccessToken(1, 2, models.RoleUser)
+	assert.NoError(t, err)
+
+	claims := jwt.MapClaims{}
+	parsedToken, _, err := new(jwt.Parser).ParseUnverified(token, claims)
+	assert.NoError(t, err)
+	assert.Equal(t, "HS256", parsedToken.Header["alg"])
+	assert.Equal(t, jwtIssuer, claims["iss"])
+	assert.Equal(t, jwtAudience, claims["aud"])
+
+	unconfiguredService, err := service.NewJwtService("secret_key", "", nil, 20, 1200, "", "", 0)
+	assert.NoError(t, err)
+
+	_, _, err = unconfiguredService.Parse(token) // The claims of other services aren't validated
+	assert.NoError(t, err)
+
+	unconfiguredToken, _, err := unconfiguredService.CreateAccessToken(1, 2, models.RoleUser)
+	assert.NoError(t, err)
+
+	_, _, err = jwtService.Parse(unconfiguredToken)
+	assert.ErrorContains(t, err, "issuer") // The service with an issuer requires it
+}
+
+// TestJwtService_KeyRotation tests that the tokens signed with a previous key stay valid after the rotation,
+// while the tokens signed with an unknown key are rejected.
+func TestJwtService_KeyRotation(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	legacyService, err := service.NewJwtService("legacy_key", "", nil, 20, 1200, jwtIssuer, jwtAudience, 0)
+	assert.NoError(t, err)
+	oldService, err := service.NewJwtService("old_key", "v1", nil, 20, 1200, jwtIssuer, jwtAudience, 0)
+	assert.NoError(t, err)
+	rotatedService, err := service.NewJwtService(
+		"new_key",
+		"v2",
+		map[string]string{"": "legacy_key", "v1": "old_key"},
+		20,
+		1200,
+		jwtIssuer,
+		jwtAudience,
+		0,
+	)
+	assert.NoError(t, err)
+	unknownService, err := service.NewJwtService("unknown_key", "v3", nil, 20, 1200, jwtIssuer, jwtAudience, 0)
+	assert.NoError(t, err)
+	forgedService, err := service.NewJwtService("forged_key", "v1", nil, 20, 1200, jwtIssuer, jwtAudience, 0)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string             // Name of the test case
+		signer  service.JwtService // Service signing the token parsed by the rotated service
+		wantErr bool               // Whether the token is expected to be rejected
+	}{
+		{name: "Current key", signer: rotatedService},
+		{name: "Previous key", signer: oldService},
+		{name: "Key issued without a key id", signer: legacyService},
+		{name: "Unknown key id", signer: unknownService, wantErr: true},
+		{name: "Known key id signed with another key", signer: forgedService, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			token, _, err := tc.signer.CreateAccessToken(1, 2, models.RoleUser)
+			assert.NoError(t, err)
+
+			userId, sessionId, err := rotatedService.Parse(token)
+			if tc.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, userId)
+			assert.Equal(t, 2, sessionId)
+		})
+	}
+
+	token, _, err := rotatedService.CreateAccessToken(1, 2, models.RoleUser)
+	assert.NoError(t, err)
+
+	parsedToken, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", parsedToken.Header["kid"]) // The tokens are signed with the current key
+
+	_, _, err = oldService.Parse(token)
+	assert.Error(t, err) // The services which don't know the current key reject its tokens
+}
+
+// TestJwtService_ResetPasswordToken tests the creation and parsing of password reset tokens.
+func TestJwtService_ResetPasswordToken(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	userId := 1             // Define user ID for testing
+	passwordVersion := 4242 // Define password version for testing
+
+	resetToken, err := jwtService.CreateResetPasswordToken(userId, passwordVersion)
+	assert.NoError(t, err)         // Ensure no error occurred during token creation
+	assert.NotEmpty(t, resetToken) // Ensure the reset token is not empty
+
+	accessToken, _, err := jwtService.CreateAccessToken(userId, passwordVersion, models.RoleUser)
+	assert.NoError(t, err)
+
+	// Build a reset token which expired a minute ago
+	expiredToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":    userId,
+		"session_id": passwordVersion,
+		"type":       "reset_password",
+		"exp":        time.Now().Add(-time.Minute).Unix(),
+	}).SignedString([]byte("secret_key"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string // Name of the test case
+		token       string // Token to parse
+		expectedErr bool   // Whether an error is expected
+	}{
+		{"Valid Reset Token", resetToken, false},
+		{"Expired Reset Token", expiredToken, true},
+		{"Access Token", accessToken, true},
+		{"Malformed Token", "invalid.token.string", true},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parsedUserId, parsedPasswordVersion, err := jwtService.ParseResetPasswordToken(tc.token)
+			if tc.expectedErr {
+				assert.Error(t, err)                      // Ensure the token is rejected
+				assert.Equal(t, 0, parsedUserId)          // Validate that user ID is zero when parsing fails
+				assert.Equal(t, 0, parsedPasswordVersion) // Validate that password version is zero when parsing fails
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, userId, parsedUserId)                   // Validate that parsed user ID matches expected user ID
+			assert.Equal(t, passwordVersion, parsedPasswordVersion) // Validate that parsed password version matches the expected one
+		})
+	}
+
+	t.Run("Parse_RejectsResetToken", func(t *testing.T) {
+		t.Parallel()
+
+		// A reset token must not be usable as an access or refresh token
+		_, _, err := jwtService.Parse(resetToken)
+		assert.Error(t, err)
+	})
+}
+
+// TestJwtService_VerifyEmailToken tests the creation and parsing of email verification tokens.
+func TestJwtService_VerifyEmailToken(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	userId := 1 // Define user ID for testing
+
+	verifyToken, err := jwtService.CreateVerifyEmailToken(userId)
+	assert.NoError(t, err)          // Ensure no error occurred during token creation
+	assert.NotEmpty(t, verifyToken) // Ensure the verification token is not empty
+
+	accessToken, _, err := jwtService.CreateAccessToken(userId, 4242, models.RoleUser)
+	assert.NoError(t, err)
+
+	resetToken, err := jwtService.CreateResetPasswordToken(userId, 4242)
+	assert.NoError(t, err)
+
+	// Build a verification token which expired a minute ago
+	expiredToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userId,
+		"type":    "verify_email",
+		"exp":     time.Now().Add(-time.Minute).Unix(),
+	}).SignedString([]byte("secret_key"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string // Name of the test case
+		token       string // Token to parse
+		expectedErr bool   // Whether an error is expected
+	}{
+		{"Valid Verification Token", verifyToken, false},
+		{"Expired Verification Token", expiredToken, true},
+		{"Access Token", accessToken, true},
+		{"Reset Password Token", resetToken, true},
+		{"Malformed Token", "invalid.token.string", true},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parsedUserId, err := jwtService.ParseVerifyEmailToken(tc.token)
+			if tc.expectedErr {
+				assert.Error(t, err)             // Ensure the token is rejected
+				assert.Equal(t, 0, parsedUserId) // Validate that user ID is zero when parsing fails
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, userId, parsedUserId) // Validate that parsed user ID matches expected user ID
+		})
+	}
+
+	t.Run("Parse_RejectsVerifyEmailToken", func(t *testing.T) {
+		t.Parallel()
+
+		// A verification token must not be usable as an access or refresh token
+		_, _, err := jwtService.Parse(verifyToken)
+		assert.Error(t, err)
+	})
+}
+
+// TestNewJwtService tests that the token lifetimes are validated and the omitted ones are set to the defaults.
+func TestNewJwtService(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name            string            // Name of the test case
+		accessLifetime  time.Duration     // Access token lifetime in hours
+		refreshLifetime time.Duration     // Refresh token lifetime in hours
+		previousKeys    map[string]string // Previous secret keys keyed by key id
+		leeway          time.Duration     // Clock skew leeway of the time claims
+		wantErr         bool              // Whether the configuration is expected to be rejected
+	}{
+		{name: "Valid lifetimes", accessLifetime: 20, refreshLifetime: 1200},
+		{name: "Both lifetimes omitted", accessLifetime: 0, refreshLifetime: 0},
+		{name: "Access lifetime omitted", accessLifetime: 0, refreshLifetime: 48},
+		{name: "Refresh lifetime omitted", accessLifetime: 20, refreshLifetime: 0},
+		{name: "Negative access lifetime", accessLifetime: -1, refreshLifetime: 1200, wantErr: true},
+		{name: "Negative refresh lifetime", accessLifetime: 20, refreshLifetime: -1, wantErr: true},
+		{name: "Access lifetime equals refresh lifetime", accessLifetime: 24, refreshLifetime: 24, wantErr: true},
+		{name: "Access lifetime exceeds refresh lifetime", accessLifetime: 48, refreshLifetime: 24, wantErr: true},
+		{name: "Access lifetime exceeds default refresh lifetime", accessLifetime: 1000, refreshLifetime: 0, wantErr: true},
+		{name: "Previous keys", accessLifetime: 20, refreshLifetime: 1200, previousKeys: map[string]string{"": "a", "v0": "b"}},
+		{name: "Previous key with the current key id", accessLifetime: 20, refreshLifetime: 1200, previousKeys: map[string]string{"v1": "a"}, wantErr: true},
+		{name: "Empty previous key", accessLifetime: 20, refreshLifetime: 1200, previousKeys: map[string]string{"v0": ""}, wantErr: true},
+		{name: "Clock skew leeway", accessLifetime: 20, refreshLifetime: 1200, leeway: time.Minute},
+		{name: "Negative clock skew leeway", accessLifetime: 20, refreshLifetime: 1200, leeway: -time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			jwtService, err := service.NewJwtService("secret_key", "v1", tc.previousKeys, tc.accessLifetime, tc.refreshLifetime, jwtIssuer, jwtAudience, tc.leeway)
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, jwtService)
+
+				return
+			}
+
+			assert.NoError(t, err)
+
+			// The issued access token must not be expired
+			_, expiresAt, err := jwtService.CreateAccessToken(1, 1, models.RoleUser)
+			assert.NoError(t, err)
+			assert.Greater(t, expiresAt, time.Now().Unix())
+		})
+	}
+}
+
+// TestJwtService_ChangeEmailToken tests the creation and parsing of email change tokens.
+func TestJwtService_ChangeEmailToken(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	changeToken, err := jwtService.CreateChangeEmailToken(1, "new@example.com")
+	assert.NoError(t, err)
+
+	userId, email, err := jwtService.ParseChangeEmailToken(changeToken)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, userId)
+	assert.Equal(t, "new@example.com", email) // The token confirms only the email it was sent to
+
+	_, _, err = jwtService.Parse(changeToken) // The token doesn't authenticate requests
+	assert.Error(t, err)
+
+	verifyToken, err := jwtService.CreateVerifyEmailToken(1)
+	assert.NoError(t, err)
+
+	_, _, err = jwtService.ParseChangeEmailToken(verifyToken) // Tokens of other types are rejected
+	assert.Error(t, err)
+}
+
+// TestJwtService_RefreshToken tests that refresh tokens are parsed only as refresh tokens,
+// so an access token can't refresh the tokens nor a refresh token authenticate requests.
+func TestJwtService_RefreshToken(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	refreshToken, err := jwtService.CreateRefreshToken(1, 2)
+	assert.NoError(t, err)
+
+	userId, sessionId, err := jwtService.ParseRefreshToken(refreshToken)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, userId)
+	assert.Equal(t, 2, sessionId)
+
+	_, _, err = jwtService.Parse(refreshToken) // The token doesn't authenticate requests
+	assert.Error(t, err)
+
+	accessToken, _, err := jwtService.CreateAccessToken(1, 2, models.RoleUser)
+	assert.NoError(t, err)
+
+	_, _, err = jwtService.ParseRefreshToken(accessToken) // The access token doesn't refresh the tokens
+	assert.Error(t, err)
+
+	resetToken, err := jwtService.CreateResetPasswordToken(1, 2)
+	assert.NoError(t, err)
+
+	_, _, err = jwtService.ParseRefreshToken(resetToken) // Tokens of other types are rejected
+	assert.Error(t, err)
+}
+
+// TestJwtService_ClockSkewLeeway tests that the time claims of the parsed tokens are validated with the clock skew leeway,
+// so a token off by less than the leeway is accepted and a token off by more than the leeway is rejected.
+func TestJwtService_ClockSkewLeeway(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	leewayService, err := service.NewJwtService("leeway_key", "", nil, 20, 1200, jwtIssuer, jwtAudience, 30*time.Second)
+	assert.NoError(t, err)
+	defaultLeewayService, err := service.NewJwtService("leeway_key", "", nil, 20, 1200, jwtIssuer, jwtAudience, 0)
+	assert.NoError(t, err)
+
+	now := time.Now()
+
+	tests := []struct {
+		name        string             // Name of the test case
+		jwtService  service.JwtService // Service parsing the token
+		claims      jwt.MapClaims      // Time claims of the token
+		expectedErr bool               // Whether the token is expected to be rejected
+	}{
+		{"Expired Within Leeway", leewayService, jwt.MapClaims{"exp": now.Add(-10 * time.Second).Unix()}, false},
+		{"Expired Beyond Leeway", leewayService, jwt.MapClaims{"exp": now.Add(-time.Minute).Unix()}, true},
+		{"Not Valid Yet Within Leeway", leewayService, jwt.MapClaims{"nbf": now.Add(10 * time.Second).Unix()}, false},
+		{"Not Valid Yet Beyond Leeway", leewayService, jwt.MapClaims{"nbf": now.Add(time.Minute).Unix()}, true},
+		{"Issued In The Future Within Leeway", leewayService, jwt.MapClaims{"iat": now.Add(10 * time.Second).Unix()}, false},
+		{"Issued In The Future Beyond Leeway", leewayService, jwt.MapClaims{"iat": now.Add(time.Minute).Unix()}, true},
+		{"Expired Within Default Leeway", defaultLeewayService, jwt.MapClaims{"exp": now.Add(-10 * time.Second).Unix()}, false},
+		{"Expired Beyond Default Leeway", defaultLeewayService, jwt.MapClaims{"exp": now.Add(-time.Minute).Unix()}, true},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			claims := jwt.MapClaims{"user_id": 1, "session_id": 2, "iss": jwtIssuer, "aud": jwtAudience}
+			for name, value := range tc.claims {
+				claims[name] = value
+			}
+
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("leeway_key"))
+			assert.NoError(t, err)
+
+			userId, sessionId, err := tc.jwtService.Parse(token)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				assert.Equal(t, 0, userId)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, userId)
+			assert.Equal(t, 2, sessionId)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"cvs/internal/mocks"
+	"cvs/internal/service/exchange"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewKraken tests the NewKraken function
+func TestNewKraken(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	// Create mocks for services
+	mockUserService := mocks.NewUserService(t)
+	mockUserPairsService := mocks.NewUserPairsService(t)
+	mockHttpRequestService := mocks.NewHttpRequest(t)
+	mockFoundVolumeService := mocks.NewFoundVolumesService(t)
+	mockLogger := mocks.NewLogger(t)
+
+	// Call NewKraken with mocked services
+	krakens := exchange.NewKraken(exchange.Dependencies{
+		UserService:         mockUserService,
+		UserPairsService:    mockUserPairsService,
+		HttpRequestService:  mockHttpRequestService,
+		FoundVolumesService: mockFoundVolumeService,
+		Logger:              mockLogger,
+	})
+
+	// Assert that the returned slice of exchanges is not nil and has expected length
+	assert.NotNil(t, krakens)
+	assert.Equal(t, 1, len(krakens)) // Only the Spot exchange is supported
+}
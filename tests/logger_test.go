@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cvs/internal/config"
+	"cvs/internal/service/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoggerWritesToFile tests that the logs are appended to the configured log file.
+func TestLoggerWritesToFile(t *testing.T) {
+	t.Parallel()
+
+	logPath := filepath.Join(t.TempDir(), "logs", "cvs.log") // The missing directory is created as well
+
+	cfg := &config.Config{Logger: config.Logger{Level: "info", FilePath: logPath, MaxSize: 1}}
+
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+	appLogger.Info("first line")
+
+	content, err := os.ReadFile(logPath)
+	assert.NoError(t, err) // The log file is created by the first log line
+	assert.Contains(t, string(content), "first line")
+
+	restartedLogger := logger.NewApiLogger(cfg) // The logger of a restarted application keeps the previous logs
+	restartedLogger.InitLogger()
+	restartedLogger.Info("second line")
+
+	content, err = os.ReadFile(logPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "first line")
+	assert.Contains(t, string(content), "second line")
+	assert.Equal(t, 2, strings.Count(strings.TrimSpace(string(content)), "\n")+1) // Every log line is appended
+}
+
+// TestLoggerSampling tests that the repeated logs are sampled down while the logs of other messages and levels are written.
+func TestLoggerSampling(t *testing.T) {
+	t.Parallel()
+
+	logPath := filepath.Join(t.TempDir(), "cvs.log")
+
+	cfg := &config.Config{Logger: config.Logger{
+		Level:    "info",
+		FilePath: logPath,
+		Sampling: config.LogSampling{Tick: time.Minute, Initial: 2, Thereafter: 5}, // The test runs within a single tick
+	}}
+
+	appLogger := logger.NewApiLogger(cfg)
+	appLogger.InitLogger()
+
+	for range 12 {
+		appLogger.Warn("request failed")
+	}
+	appLogger.Error("request failed") // Another level is sampled separately
+	appLogger.Warn("another failure")
+
+	content, err := os.ReadFile(logPath)
+	assert.NoError(t, err)
+
+	// The first 2 warnings and then every 5th, the 7th and the 12th, are written along with the error
+	assert.Equal(t, 5, strings.Count(string(content), `"MESSAGE":"request failed"`))
+	assert.Equal(t, 1, strings.Count(string(content), `"LEVEL":"error"`))
+	assert.Contains(t, string(content), "another failure")
+
+	unsampledPath := filepath.Join(t.TempDir(), "cvs.log")
+
+	unsampledLogger := logger.NewApiLogger(&config.Config{Logger: config.Logger{Level: "info", FilePath: unsampledPath}})
+	unsampledLogger.InitLogger()
+
+	for range 12 {
+		unsampledLogger.Warn("request failed")
+	}
+
+	content, err = os.ReadFile(unsampledPath)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, strings.Count(string(content), "request failed")) // Every log is written without the sampling
+}
@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cvs/api/server/route"
+	"cvs/internal/service/metrics"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsRoute(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	app := fiber.New()
+	route.NewMetricsRouter(app)
+
+	// Labeled metrics are exposed only after they are observed for the first time
+	metrics.ObserveOrderbookFetch("binance_spot", time.Now(), true)
+	metrics.ObserveOrderbookFetch("binance_spot", time.Now(), false)
+	metrics.AddVolumesFound(1, 2)
+	metrics.SetSubscribedPairs("binance_spot", 3)
+	metrics.ObserveHttpRetry("https://api.binance.com/api/v3/depth?symbol=BTCUSDT")
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/metrics", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	for _, expected := range []string{
+		`cvs_orderbook_fetches_total{exchange="binance_spot",result="success"}`,
+		`cvs_orderbook_fetches_total{exchange="binance_spot",result="failure"}`,
+		`cvs_orderbook_fetch_duration_seconds_bucket{exchange="binance_spot"`,
+		`cvs_volumes_found_total{user_id="1"}`,
+		`cvs_subscribed_pairs{exchange="binance_spot"} 3`,
+		`cvs_http_request_retries_total{host="api.binance.com"}`,
+	} {
+		assert.Contains(t, string(body), expected)
+	}
+}
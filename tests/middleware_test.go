@@ -0,0 +1,440 @@
+package tests
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cvs/api/server/middleware"
+	"cvs/api/server/route"
+	"cvs/internal/config"
+	"cvs/internal/mocks"
+	"cvs/internal/models"
+	"cvs/internal/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestAuthLimiter tests that the auth routes share a tighter limit, while the other user routes aren't limited by it.
+func TestAuthLimiter(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	const authRateLimit = 3 // Requests allowed to the auth routes
+
+	app := fiber.New()
+
+	mockLogger := mocks.NewLogger(t)
+	mockLogger.On("Error", mock.Anything).Return(nil).Maybe()
+	mockLogger.On("Error", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	route.NewUserRouter(
+		app.Group("/api/user"),
+		mocks.NewUserService(t), // The invalid bodies are rejected before the services are used
+		mocks.NewSessionService(t),
+		jwtService,
+		mocks.NewEmailService(t),
+		mocks.NewAllExchanges(t),
+		middleware.AuthLimiter(authRateLimit, time.Minute),
+		config.PasswordPolicyConfig{},
+		mockLogger,
+	)
+
+	request := func(method, url string) *http.Response {
+		req := httptest.NewRequest(method, url, strings.NewReader("invalid body"))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+
+		return resp
+	}
+
+	// The budget is shared by the auth routes, so the requests to different routes add up
+	authUrls := []string{"/api/user/auth/signup", "/api/user/auth/login", "/api/user/auth/forgot-password"}
+	for i := 0; i < authRateLimit; i++ {
+		resp := request("POST", authUrls[i%len(authUrls)])
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "request %d must reach the controller", i+1)
+	}
+
+	for _, url := range authUrls {
+		resp := request("POST", url)
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode, "%s must be limited", url)
+	}
+
+	// The routes without the auth limiter are still served
+	resp := request("GET", "/api/user/auth/verify?token=invalid")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// TestGlobalLimiter tests that the global limiter rejects the requests of a client which exceeded the limit.
+func TestGlobalLimiter(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	const globalRateLimit = 5 // Requests allowed to the whole API
+
+	mockLogger := mocks.NewLogger(t)
+	mockLogger.On("Infof", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil) // Every request is logged
+
+	app := fiber.New()
+	middleware.Setup(app, globalRateLimit, time.Minute, mockLogger, middleware.CompressionDefault)
+	app.Get("/ping", func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusOK)
+	})
+
+	for i := 0; i < globalRateLimit; i++ {
+		resp, err := app.Test(httptest.NewRequest("GET", "/ping", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/ping", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+}
+
+// TestRequestLogger tests that every request gets a UUID, which is returned in the header, available to the handlers and logged.
+func TestRequestLogger(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	var loggedIDs []string // IDs of the logged requests in the order they were handled
+
+	mockLogger := mocks.NewLogger(t)
+	mockLogger.On("Infof", mock.Anything, mock.Anything, "GET", "/ping", http.StatusOK, mock.Anything).
+		Run(func(args mock.Arguments) { loggedIDs = append(loggedIDs, args.String(1)) }).
+		Return(nil).Twice()
+	mockLogger.On("Infof", mock.Anything, mock.Anything, "GET", "/missing", http.StatusNotFound, mock.Anything).
+		Return(nil).Once() // The status of the error returned by the handler is logged
+
+	var handlerID string // ID seen by the handler
+
+	app := fiber.New()
+	app.Use(middleware.RequestLogger(mockLogger))
+	app.Get("/ping", func(c *fiber.Ctx) error {
+		handlerID = middleware.RequestID(c)
+
+		return c.SendStatus(http.StatusOK)
+	})
+
+	var responseIDs []string
+	for i := 0; i < 2; i++ {
+		resp, err := app.Test(httptest.NewRequest("GET", "/ping", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		requestID := resp.Header.Get(middleware.RequestIDHeader)
+		_, err = uuid.Parse(requestID)
+		assert.NoError(t, err, "the request ID must be a valid UUID")
+		assert.Equal(t, requestID, handlerID) // The handler sees the ID returned to the client
+
+		responseIDs = append(responseIDs, requestID)
+	}
+
+	assert.NotEqual(t, responseIDs[0], responseIDs[1]) // Every request gets its own ID
+	assert.Equal(t, responseIDs, loggedIDs)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/missing", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get(middleware.RequestIDHeader))
+}
+
+// TestIsAuthorized tests that a user lacking the required role is rejected from an admin route, while an admin passes.
+func TestIsAuthorized(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name         string // Name of the test case
+		userID       int    // ID of the user the access token is issued for, 0 sends no token
+		role         string // Role of the user stored in the database
+		expectedCode int    // Expected HTTP status code after the request
+	}{
+		{
+			name:         "Admin",
+			userID:       1,
+			role:         models.RoleAdmin,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Regular User",
+			userID:       2,
+			role:         models.RoleUser,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Not Authenticated",
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allows this subtest to run in parallel with other subtests
+
+			const sessionID = 7
+
+			mockUserService := mocks.NewUserService(t)
+			mockSessionService := mocks.NewSessionService(t)
+
+			req := httptest.NewRequest("GET", "/api/admin/exchanges/stats", nil)
+			if tc.userID != 0 {
+				mockUserService.On("GetUserById", mock.Anything, tc.userID).Return(models.User{
+					ID:       tc.userID,
+					Verified: true,
+					Role:     tc.role,
+				}, nil)
+				session := models.Session{ID: sessionID, UserID: tc.userID}
+				mockSessionService.On("GetSession", mock.Anything, tc.userID, sessionID).Return(session, nil)
+				mockSessionService.On("TouchSession", mock.Anything, session).Return(nil)
+
+				accessToken, _, err := jwtService.CreateAccessToken(tc.userID, sessionID, tc.role)
+				assert.NoError(t, err)
+
+				req.Header.Set("Authorization", accessToken)
+			}
+
+			app := fiber.New()
+			app.Get(
+				"/api/admin/exchanges/stats",
+				middleware.IsAuthenticated(jwtService, mockUserService, mockSessionService),
+				middleware.IsAuthorized(models.RoleAdmin),
+				func(c *fiber.Ctx) error {
+					return c.SendStatus(http.StatusOK)
+				},
+			)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+		})
+	}
+}
+
+// TestIsAuthenticatedSession tests that only the access tokens of the existing sessions are accepted,
+// and the accepted session is touched and stored in context locals.
+func TestIsAuthenticatedSession(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name         string                                  // Name of the test case
+		mocksSetup   func(sessionMock *mocks.SessionService) // Function to set up mock behavior
+		expectedCode int                                     // Expected HTTP status code after the request
+	}{
+		{
+			name: "Active Session",
+			mocksSetup: func(sessionMock *mocks.SessionService) {
+				session := models.Session{ID: 3, UserID: 1}
+				sessionMock.On("GetSession", mock.Anything, 1, 3).Return(session, nil)
+				sessionMock.On("TouchSession", mock.Anything, session).Return(nil) // The session must be seen
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Touch Failure",
+			mocksSetup: func(sessionMock *mocks.SessionService) {
+				session := models.Session{ID: 3, UserID: 1}
+				sessionMock.On("GetSession", mock.Anything, 1, 3).Return(session, nil)
+				sessionMock.On("TouchSession", mock.Anything, session).Return(errors.New("db error")) // The last seen time isn't worth rejecting the request
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Revoked Session",
+			mocksSetup: func(sessionMock *mocks.SessionService) {
+				sessionMock.On("GetSession", mock.Anything, 1, 3).Return(models.Session{}, repository.ErrSessionNotFound)
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allows this subtest to run in parallel with other subtests
+
+			mockUserService := mocks.NewUserService(t)
+			mockUserService.On("GetUserById", mock.Anything, 1).Return(models.User{ID: 1, Verified: true}, nil)
+
+			mockSessionService := mocks.NewSessionService(t)
+			tc.mocksSetup(mockSessionService)
+
+			app := fiber.New()
+			app.Get(
+				"/api/user/settings",
+				middleware.IsAuthenticated(jwtService, mockUserService, mockSessionService),
+				func(c *fiber.Ctx) error {
+					assert.Equal(t, 3, c.Locals("session").(models.Session).ID) // The current session is available to the handlers
+					return c.SendStatus(http.StatusOK)
+				},
+			)
+
+			accessToken, _, err := jwtService.CreateAccessToken(1, 3, models.RoleUser)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest("GET", "/api/user/settings", nil)
+			req.Header.Set("Authorization", accessToken)
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+		})
+	}
+}
+
+// TestRestrictIPs tests that only the clients from the allowlisted IP addresses and CIDR ranges reach an admin route,
+// and the X-Forwarded-For header is respected only behind a trusted proxy.
+func TestRestrictIPs(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	const testRemoteIP = "0.0.0.0" // Address of the client of the test requests made by Fiber
+
+	tests := []struct {
+		name           string   // Name of the test case
+		allowed        []string // Allowed IP addresses and CIDR ranges
+		trustedProxies []string // Proxies whose X-Forwarded-For header is trusted
+		forwardedFor   string   // Value of the X-Forwarded-For header, empty sends no header
+		expectedCode   int      // Expected HTTP status code after the request
+	}{
+		{
+			name:         "Empty Allowlist",
+			forwardedFor: "203.0.113.7",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:           "Allowed IP",
+			allowed:        []string{"203.0.113.7"},
+			trustedProxies: []string{testRemoteIP},
+			forwardedFor:   "203.0.113.7",
+			expectedCode:   http.StatusOK,
+		},
+		{
+			name:           "Allowed CIDR Range",
+			allowed:        []string{"127.0.0.1", "10.0.0.0/8"},
+			trustedProxies: []string{testRemoteIP},
+			forwardedFor:   "10.20.30.40",
+			expectedCode:   http.StatusOK,
+		},
+		{
+			name:           "Blocked IP",
+			allowed:        []string{"127.0.0.1", "10.0.0.0/8"},
+			trustedProxies: []string{testRemoteIP},
+			forwardedFor:   "11.0.0.1",
+			expectedCode:   http.StatusForbidden,
+		},
+		{
+			name:         "Untrusted Proxy",
+			allowed:      []string{"10.0.0.0/8"},
+			forwardedFor: "10.20.30.40", // The spoofed header is ignored, so the remote address is checked
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Allowed Remote IP",
+			allowed:      []string{testRemoteIP},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allows this subtest to run in parallel with other subtests
+
+			app := fiber.New(fiber.Config{
+				ProxyHeader:             fiber.HeaderXForwardedFor,
+				EnableTrustedProxyCheck: true,
+				TrustedProxies:          tc.trustedProxies,
+			})
+			app.Get("/api/admin/exchanges/stats", middleware.RestrictIPs(tc.allowed), func(c *fiber.Ctx) error {
+				return c.SendStatus(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/api/admin/exchanges/stats", nil)
+			if tc.forwardedFor != "" {
+				req.Header.Set(fiber.HeaderXForwardedFor, tc.forwardedFor)
+			}
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+		})
+	}
+
+	assert.Panics(t, func() { middleware.RestrictIPs([]string{"10.0.0.0/33"}) }) // A misconfiguration fails at startup
+}
+
+// TestCompress tests that a large JSON response is gzip encoded for the clients accepting it,
+// while the disabled compression and the websocket upgrades leave the response intact.
+func TestCompress(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	pairs := make([]models.ExchangePairs, 1000) // Large JSON response
+	for i := range pairs {
+		pairs[i] = models.ExchangePairs{Pair: "BTC/USDT", Exchange: "binance_spot"}
+	}
+
+	tests := []struct {
+		name             string // Name of the test case
+		level            int    // Compression level of the middleware
+		acceptEncoding   string // Accept-Encoding header of the request
+		websocket        bool   // Whether the request is a websocket upgrade
+		expectedEncoding string // Expected Content-Encoding header of the response
+	}{
+		{name: "Gzip", level: middleware.CompressionDefault, acceptEncoding: "gzip", expectedEncoding: "gzip"},
+		{name: "Best Speed", level: middleware.CompressionBestSpeed, acceptEncoding: "gzip", expectedEncoding: "gzip"},
+		{name: "Not Accepted", level: middleware.CompressionDefault},
+		{name: "Disabled", level: middleware.CompressionDisabled, acceptEncoding: "gzip"},
+		{name: "Websocket Upgrade", level: middleware.CompressionDefault, acceptEncoding: "gzip", websocket: true},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := fiber.New()
+			app.Use(middleware.Compress(tc.level))
+			app.Get("/pairs", func(c *fiber.Ctx) error {
+				return c.JSON(pairs)
+			})
+
+			req := httptest.NewRequest("GET", "/pairs", nil)
+			if tc.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			}
+			if tc.websocket {
+				req.Header.Set("Connection", "Upgrade")
+				req.Header.Set("Upgrade", "websocket")
+			}
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.expectedEncoding, resp.Header.Get("Content-Encoding"))
+
+			var body io.Reader = resp.Body
+			if tc.expectedEncoding == "gzip" {
+				reader, err := gzip.NewReader(resp.Body)
+				assert.NoError(t, err)
+				body = reader
+			}
+
+			var decoded []models.ExchangePairs
+			assert.NoError(t, json.NewDecoder(body).Decode(&decoded))
+			assert.Equal(t, pairs, decoded) // The response is intact either way
+		})
+	}
+}
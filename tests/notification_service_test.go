@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cvs/internal/mocks"
+	"cvs/internal/models"
+	"cvs/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestNotificationService_Notify tests that the found volumes are delivered through every notifier with the settings of the user.
+func TestNotificationService_Notify(t *testing.T) {
+	t.Parallel()
+
+	settings := models.UserSettings{UserID: 1, WebhookUrl: "https://example.com/hooks/cvs", WebhookSecret: "secret"}
+	foundVolume := models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 12}
+
+	tests := []struct {
+		name      string                                                                          // Name of the test case
+		mockSetup func(*mocks.UserSettingsService, *mocks.Notifier, *mocks.Logger, chan struct{}) // Mocking the dependencies, the channel is signaled on delivery
+		delivered bool                                                                            // Whether the notifier is expected to be called
+	}{
+		{
+			name: "Delivered",
+			mockSetup: func(s *mocks.UserSettingsService, n *mocks.Notifier, l *mocks.Logger, done chan struct{}) {
+				s.On("GetSettings", mock.Anything, 1).Return(settings, nil)
+				n.On("Notify", mock.Anything, settings, foundVolume).Return(nil).Run(func(mock.Arguments) { done <- struct{}{} })
+			},
+			delivered: true,
+		},
+		{
+			name: "Failed delivery is logged",
+			mockSetup: func(s *mocks.UserSettingsService, n *mocks.Notifier, l *mocks.Logger, done chan struct{}) {
+				s.On("GetSettings", mock.Anything, 1).Return(settings, nil)
+				n.On("Notify", mock.Anything, settings, foundVolume).Return(errors.New("delivery error"))
+				l.On("Errorf", mock.Anything, 1, "BTC/USDT", "binance_spot", mock.Anything).Run(func(mock.Arguments) { done <- struct{}{} })
+			},
+			delivered: true,
+		},
+		{
+			name: "Settings can't be read",
+			mockSetup: func(s *mocks.UserSettingsService, n *mocks.Notifier, l *mocks.Logger, done chan struct{}) {
+				s.On("GetSettings", mock.Anything, 1).Return(models.UserSettings{}, errors.New("db error"))
+				l.On("Error", mock.Anything) // Nothing is delivered without the channels of the user
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockSettingsService := mocks.NewUserSettingsService(t)
+			mockNotifier := mocks.NewNotifier(t)
+			mockLogger := mocks.NewLogger(t)
+			done := make(chan struct{}, 1)
+
+			tc.mockSetup(mockSettingsService, mockNotifier, mockLogger, done)
+
+			notificationService := service.NewNotificationService(mockSettingsService, mockLogger, 0, mockNotifier)
+			notificationService.Notify(ctx, 1, foundVolume)
+
+			if tc.delivered {
+				select {
+				case <-done: // The delivery runs in the background
+				case <-time.After(time.Second):
+					t.Fatal("the found volume wasn't delivered")
+				}
+			}
+		})
+	}
+}
+
+// TestNotificationService_Consume tests that the new found volumes received from a subscription are delivered
+// to the users they were found for, while the expired ones are skipped.
+func TestNotificationService_Consume(t *testing.T) {
+	t.Parallel()
+
+	settings := models.UserSettings{UserID: 1, WebhookUrl: "https://example.com/hooks/cvs", WebhookSecret: "secret"}
+	foundVolume := models.FoundVolume{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 12}
+	expiredVolume := models.FoundVolume{UserID: 1, Exchange: "binance_spot", Pair: "ETH/USDT", Side: "bids", Price: 3000, Volume: 50, Expired: true}
+
+	delivered := make(chan struct{}, 1) // Signaled when the found volume is delivered
+
+	mockSettingsService := mocks.NewUserSettingsService(t)
+	mockSettingsService.On("GetSettings", mock.Anything, 1).Return(settings, nil).Once()
+
+	mockNotifier := mocks.NewNotifier(t)
+	mockNotifier.On("Notify", mock.Anything, settings, foundVolume).Return(nil).Once().Run(func(mock.Arguments) { delivered <- struct{}{} })
+
+	notificationService := service.NewNotificationService(mockSettingsService, mocks.NewLogger(t), 0, mockNotifier)
+
+	foundVolumes := make(chan models.FoundVolume, 2)
+	foundVolumes <- expiredVolume
+	foundVolumes <- foundVolume
+	close(foundVolumes)
+
+	notificationService.Consume(ctx, foundVolumes) // Returns once the subscription is closed
+
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Fatal("the found volume wasn't delivered")
+	}
+}
+
+// TestNotificationService_AlertCooldown tests that rapid repeated detections of a pair alert the user only once
+// per cooldown window, while the other pairs and the other users aren't held back by it.
+func TestNotificationService_AlertCooldown(t *testing.T) {
+	t.Parallel()
+
+	const cooldown = 300 * time.Millisecond
+
+	tests := []struct {
+		name     string              // Name of the test case
+		settings models.UserSettings // Settings of the user
+		cooldown time.Duration       // Cooldown of the service
+		wait     time.Duration       // Time waited before the detections are repeated
+		expected int                 // Expected number of alerts about the pair
+	}{
+		{
+			name:     "Repeated Within Cooldown",
+			settings: models.UserSettings{UserID: 1},
+			cooldown: cooldown,
+			expected: 1, // The flickering level alerts only once
+		},
+		{
+			name:     "Repeated After Cooldown",
+			settings: models.UserSettings{UserID: 1},
+			cooldown: cooldown,
+			wait:     cooldown + 100*time.Millisecond,
+			expected: 2, // Once per cooldown window
+		},
+		{
+			name:     "User Cooldown Overrides Service",
+			settings: models.UserSettings{UserID: 1, AlertCooldownSeconds: 60},
+			cooldown: cooldown,
+			wait:     cooldown + 100*time.Millisecond,
+			expected: 1, // The cooldown of the user hasn't elapsed yet
+		},
+		{
+			name:     "Cooldown Disabled",
+			settings: models.UserSettings{UserID: 1},
+			expected: 10, // Every detection alerts
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			foundVolume := models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 12}
+
+			var alerts atomic.Int32 // Number of alerts about the pair
+			mockSettingsService := mocks.NewUserSettingsService(t)
+			mockSettingsService.On("GetSettings", mock.Anything, 1).Return(tc.settings, nil)
+
+			mockNotifier := mocks.NewNotifier(t)
+			mockNotifier.On("Notify", mock.Anything, tc.settings, foundVolume).Return(nil).Run(func(mock.Arguments) { alerts.Add(1) })
+
+			notificationService := service.NewNotificationService(mockSettingsService, mocks.NewLogger(t), tc.cooldown, mockNotifier)
+
+			detect := func(times int) {
+				for range times {
+					notificationService.Notify(ctx, 1, foundVolume) // The level appears again
+				}
+			}
+
+			detect(5)
+			time.Sleep(tc.wait)
+			detect(5)
+
+			assert.Eventually(t, func() bool { return alerts.Load() == int32(tc.expected) }, time.Second, 10*time.Millisecond)
+			time.Sleep(50 * time.Millisecond) // Give the skipped alerts a chance to show up
+			assert.Equal(t, int32(tc.expected), alerts.Load())
+		})
+	}
+}
+
+// TestNotificationService_AlertCooldownPerPair tests that the cooldown of a pair doesn't hold back
+// the alerts about the other pairs of the user and the same pair of the other users.
+func TestNotificationService_AlertCooldownPerPair(t *testing.T) {
+	t.Parallel()
+
+	delivered := make(chan models.FoundVolume, 10) // Found volumes the users were alerted about
+
+	mockSettingsService := mocks.NewUserSettingsService(t)
+	mockSettingsService.On("GetSettings", mock.Anything, 1).Return(models.UserSettings{UserID: 1}, nil)
+	mockSettingsService.On("GetSettings", mock.Anything, 2).Return(models.UserSettings{UserID: 2}, nil)
+
+	mockNotifier := mocks.NewNotifier(t)
+	mockNotifier.On("Notify", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		delivered <- args.Get(2).(models.FoundVolume)
+	})
+
+	notificationService := service.NewNotificationService(mockSettingsService, mocks.NewLogger(t), time.Minute, mockNotifier)
+
+	btc := models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 12}
+	eth := models.FoundVolume{Exchange: "binance_spot", Pair: "ETH/USDT", Side: "bids", Price: 3000, Volume: 50}
+	btcBybit := models.FoundVolume{Exchange: "bybit_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 12}
+
+	notificationService.Notify(ctx, 1, btc)
+	notificationService.Notify(ctx, 1, btc) // Held back by the cooldown
+	notificationService.Notify(ctx, 1, eth)
+	notificationService.Notify(ctx, 1, btcBybit)
+	notificationService.Notify(ctx, 2, btc)
+
+	assert.Eventually(t, func() bool { return len(delivered) == 4 }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond) // Give the skipped alert a chance to show up
+	assert.Len(t, delivered, 4)
+}
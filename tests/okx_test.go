@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"cvs/internal/mocks"
+	"cvs/internal/service/exchange"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewOkx tests the NewOkx function
+func TestNewOkx(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	// Create mocks for services
+	mockUserService := mocks.NewUserService(t)
+	mockUserPairsService := mocks.NewUserPairsService(t)
+	mockHttpRequestService := mocks.NewHttpRequest(t)
+	mockFoundVolumeService := mocks.NewFoundVolumesService(t)
+	mockLogger := mocks.NewLogger(t)
+
+	// Call NewOkx with mocked services
+	okxs := exchange.NewOkx(exchange.Dependencies{
+		UserService:         mockUserService,
+		UserPairsService:    mockUserPairsService,
+		HttpRequestService:  mockHttpRequestService,
+		FoundVolumesService: mockFoundVolumeService,
+		Logger:              mockLogger,
+	})
+
+	// Assert that the returned slice of exchanges is not nil and has expected length
+	assert.NotNil(t, okxs)
+	assert.Equal(t, 2, len(okxs)) // Spot and Swap exchanges
+}
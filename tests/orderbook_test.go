@@ -1,9 +1,11 @@
 package tests
 
 import (
+	"cvs/internal/models"
 	"cvs/internal/service/orderbook"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert" // Import assert package for better assertions
 )
@@ -103,11 +105,538 @@ func TestOrderbook_SearchVolume(t *testing.T) {
 	ob := orderbook.NewOrderbook()                                                         // Create a new orderbook instance
 	ob.Upsert("BTC/USD", [][]interface{}{{"50000", "1"}}, [][]interface{}{{"49000", "1"}}) // Insert test data
 
-	volumes := ob.SearchVolume("BTC/USD", "binance", 1) // Search volumes based on criteria
+	volumes := ob.SearchVolume("BTC/USD", "binance", 1, 0, 0) // Search volumes based on criteria
 
 	assert.Equal(t, 2, len(volumes), "Expected 2 volumes, got %d", len(volumes)) // Validate total volumes retrieved
 }
 
+// TestOrderbook_SearchVolumeDifference tests that the difference is the non-negative distance from the best price
+// of the side, so the volumes of mirrored asks and bids are equally far from their best prices.
+func TestOrderbook_SearchVolumeDifference(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	tests := []struct {
+		name     string          // Name of the test case
+		asks     [][]interface{} // Ask levels of the book
+		bids     [][]interface{} // Bid levels of the book, mirroring the asks
+		expected float64         // Expected difference of both sides
+	}{
+		{
+			name:     "Five Percent Away",
+			asks:     [][]interface{}{{"100", "1"}, {"102", "2"}, {"105", "30"}},
+			bids:     [][]interface{}{{"100", "1"}, {"98", "2"}, {"95", "30"}},
+			expected: 5, // (105 - 100) / 100 * 100 and (100 - 95) / 100 * 100
+		},
+		{
+			name:     "At Best Price",
+			asks:     [][]interface{}{{"200", "30"}, {"210", "1"}},
+			bids:     [][]interface{}{{"200", "30"}, {"190", "1"}},
+			expected: 0, // The found volume is the best price itself
+		},
+		{
+			name:     "Unsorted Levels",
+			asks:     [][]interface{}{{"120", "30"}, {"100", "1"}, {"110", "2"}},
+			bids:     [][]interface{}{{"90", "2"}, {"80", "30"}, {"100", "1"}},
+			expected: 20, // The levels are sorted, so the best prices are found regardless of the input order
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture the current test case
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allow this test case to run in parallel
+
+			ob := orderbook.NewOrderbook()
+			ob.Upsert("BTC/USD", tc.asks, tc.bids)
+
+			volumes := ob.SearchVolume("BTC/USD", "binance_spot", 30, 0, 0) // Only the largest level of each side matches
+			assert.Equal(t, 2, len(volumes))                                // One volume per side
+
+			for _, volume := range volumes {
+				assert.Equal(t, 30.0, volume.Volume, volume.Side)
+				assert.GreaterOrEqual(t, volume.Difference, 0.0, volume.Side)         // The distance is never negative
+				assert.InDelta(t, tc.expected, volume.Difference, 0.001, volume.Side) // Both sides are equally far from the best price
+			}
+		})
+	}
+}
+
+// TestOrderbook_SearchVolumeTolerance tests that only the levels within the tolerance of the search value are found.
+func TestOrderbook_SearchVolumeTolerance(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	ob := orderbook.NewOrderbook()
+	ob.Upsert(
+		"BTC/USD",
+		[][]interface{}{{"101", "8.9"}, {"102", "11.1"}, {"103", "20"}}, // Just outside the 10% band around 10
+		[][]interface{}{{"97", "9.1"}, {"98", "3"}, {"99", "10.9"}},     // Just inside the 10% band around 10
+	)
+
+	tests := []struct {
+		name           string  // Name of the test case
+		search         float64 // Exact value of the search
+		tolerance      float64 // Tolerance in percent of the exact value
+		expectedAsk    float64 // Expected volume of the found ask level, zero if none
+		expectedBid    float64 // Expected volume of the found bid level, zero if none
+		expectedBidPrc float64 // Expected price of the found bid level, zero if none
+	}{
+		{
+			name:           "Just Inside And Outside The Band",
+			search:         10,
+			tolerance:      10,
+			expectedAsk:    0, // Both 8.9 and 11.1 deviate by more than 10%
+			expectedBid:    9.1,
+			expectedBidPrc: 97, // The smallest volume within the band
+		},
+		{
+			name:           "Narrow Band",
+			search:         10,
+			tolerance:      5,
+			expectedAsk:    0,
+			expectedBid:    0, // 9.1 and 10.9 deviate by more than 5%
+			expectedBidPrc: 0,
+		},
+		{
+			name:           "Band Edge Included",
+			search:         20,
+			tolerance:      44.5,
+			expectedAsk:    11.1, // 20 - 44.5% = 11.1
+			expectedBid:    0,    // 10.9 is below the band
+			expectedBidPrc: 0,
+		},
+		{
+			name:           "Zero Tolerance",
+			search:         10,
+			tolerance:      0,
+			expectedAsk:    11.1, // Every volume of at least the search value matches
+			expectedBid:    10.9,
+			expectedBidPrc: 99,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			volumes := ob.SearchVolume("BTC/USD", "binance", tc.search, tc.tolerance, 0)
+			assert.Len(t, volumes, 2)
+
+			for _, volume := range volumes {
+				if volume.Side == "asks" {
+					assert.InDelta(t, tc.expectedAsk, volume.Volume, 1e-9)
+				} else {
+					assert.InDelta(t, tc.expectedBid, volume.Volume, 1e-9)
+					assert.Equal(t, tc.expectedBidPrc, volume.Price)
+				}
+			}
+		})
+	}
+}
+
+// TestOrderbook_SearchVolumeFloor tests that the dust levels below the minimum volume floor are never found,
+// while the levels above it are found as without the floor.
+func TestOrderbook_SearchVolumeFloor(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	ob := orderbook.NewOrderbook()
+	ob.Upsert(
+		"BTC/USD",
+		[][]interface{}{{"101", "0.01"}, {"102", "0.05"}, {"103", "0.2"}, {"104", "12"}}, // Dust levels and a real one
+		[][]interface{}{{"97", "0.02"}, {"98", "0.3"}, {"99", "0.08"}},                   // Dust levels only
+	)
+
+	tests := []struct {
+		name        string  // Name of the test case
+		search      float64 // Exact value of the search
+		tolerance   float64 // Tolerance in percent of the exact value
+		floor       float64 // Volume below which the levels are ignored
+		expectedAsk float64 // Expected volume of the found ask level, zero if none
+		expectedBid float64 // Expected volume of the found bid level, zero if none
+	}{
+		{
+			name:        "Dust Matches Without Floor",
+			search:      0.01,
+			expectedAsk: 0.01,
+			expectedBid: 0.02,
+		},
+		{
+			name:        "Dust Ignored Below Floor",
+			search:      0.01,
+			floor:       1,
+			expectedAsk: 12, // The first level above the floor is found instead
+			expectedBid: 0,  // There are only dust bids
+		},
+		{
+			name:        "Exact Value Above Floor",
+			search:      10,
+			floor:       1,
+			expectedAsk: 12, // The floor doesn't change a search above it
+			expectedBid: 0,
+		},
+		{
+			name:        "Tolerance Band Below Floor",
+			search:      0.2,
+			tolerance:   50,
+			floor:       1,
+			expectedAsk: 0, // The whole band is below the floor
+			expectedBid: 0,
+		},
+		{
+			name:        "Tolerance Band Crossing Floor",
+			search:      0.2,
+			tolerance:   50,
+			floor:       0.25,
+			expectedAsk: 0,   // 0.2 is within the band, but below the floor
+			expectedBid: 0.3, // Only the part of the band above the floor is searched
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			volumes := ob.SearchVolume("BTC/USD", "binance", tc.search, tc.tolerance, tc.floor)
+			assert.Len(t, volumes, 2)
+
+			for _, volume := range volumes {
+				if volume.Side == "asks" {
+					assert.InDelta(t, tc.expectedAsk, volume.Volume, 1e-9)
+				} else {
+					assert.InDelta(t, tc.expectedBid, volume.Volume, 1e-9)
+				}
+			}
+		})
+	}
+}
+
+// TestOrderbook_SearchVolumeRelative tests the SearchVolumeRelative function of the Orderbook
+// with a synthetic book containing one outlier level on each side.
+func TestOrderbook_SearchVolumeRelative(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	asks := [][]interface{}{
+		{"100", "1"}, {"101", "2"}, {"102", "1.5"}, {"103", "2"}, {"104", "30"}, // Outlier ask level
+		{"105", "1"}, {"106", "2"}, {"107", "1.5"}, {"108", "1"},
+	}
+	bids := [][]interface{}{
+		{"91", "2"}, {"92", "1"}, {"93", "25"}, // Outlier bid level
+		{"94", "1.5"}, {"95", "2"}, {"96", "1"}, {"97", "2"}, {"98", "1"}, {"99", "1.5"},
+	}
+
+	ob := orderbook.NewOrderbook()
+	ob.Upsert("BTC/USD", asks, bids)
+
+	tests := []struct {
+		name          string  // Name of the test case
+		multiplier    float64 // Multiplier the outlier must exceed
+		window        int     // Neighbour levels on each side
+		expectedAsk   float64 // Expected price of the found ask level, zero if none
+		expectedBid   float64 // Expected price of the found bid level, zero if none
+		expectedAskVl float64 // Expected volume of the found ask level
+		expectedBidVl float64 // Expected volume of the found bid level
+	}{
+		{
+			name:          "Outliers Found",
+			multiplier:    5,
+			window:        3,
+			expectedAsk:   104,
+			expectedBid:   93,
+			expectedAskVl: 30,
+			expectedBidVl: 25,
+		},
+		{
+			name:       "Multiplier Above Outliers",
+			multiplier: 50,
+			window:     3,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			volumes := ob.SearchVolumeRelative("BTC/USD", "binance_spot", tc.multiplier, tc.window)
+			assert.Equal(t, 2, len(volumes)) // One volume per side
+
+			for _, volume := range volumes {
+				assert.Equal(t, "BTC/USD", volume.Pair)
+				assert.Equal(t, "binance_spot", volume.Exchange)
+
+				switch volume.Side {
+				case "asks":
+					assert.Equal(t, tc.expectedAsk, volume.Price)
+					assert.Equal(t, tc.expectedAskVl, volume.Volume)
+					if tc.expectedAsk != 0 {
+						assert.InDelta(t, 4, volume.Difference, 0.001) // (104 - 100) / 100 * 100
+					}
+				case "bids":
+					assert.Equal(t, tc.expectedBid, volume.Price)
+					assert.Equal(t, tc.expectedBidVl, volume.Volume)
+					if tc.expectedBid != 0 {
+						assert.InDelta(t, 6.061, volume.Difference, 0.001) // (99 - 93) / 99 * 100
+					}
+				default:
+					t.Fatalf("unexpected side %q", volume.Side)
+				}
+			}
+		})
+	}
+
+	assert.Empty(t, ob.SearchVolumeRelative("ETH/USD", "binance_spot", 5, 3)) // Unknown pair
+}
+
+// TestOrderbook_SearchCumulativeVolume tests the SearchCumulativeVolume function of the Orderbook
+// with a synthetic book whose walls are spread across adjacent levels, none of which reaches the threshold alone.
+func TestOrderbook_SearchCumulativeVolume(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	asks := [][]interface{}{
+		{"100", "1"}, {"100.1", "4"}, {"100.2", "4"}, {"100.3", "4"}, // Ask wall spread across adjacent levels
+		{"105", "2"}, {"110", "3"},
+	}
+	bids := [][]interface{}{
+		{"90", "5"},
+		{"98", "4"}, {"98.2", "4"}, {"98.4", "4"}, // Bid wall spread across adjacent levels
+		{"99", "1"},
+	}
+
+	ob := orderbook.NewOrderbook()
+	ob.Upsert("BTC/USD", asks, bids)
+
+	tests := []struct {
+		name         string             // Name of the test case
+		threshold    float64            // Total volume of a band at which it is found
+		priceRange   float64            // Width of a band in percent
+		expectedAsk  models.FoundVolume // Expected price, total volume and band of the asks, zero if nothing is found
+		expectedBid  models.FoundVolume // Expected price, total volume and band of the bids, zero if nothing is found
+		expectedDiff [2]float64         // Expected difference of the asks and the bids
+	}{
+		{
+			name:         "Walls Spread Across Levels",
+			threshold:    10,
+			priceRange:   0.5,
+			expectedAsk:  models.FoundVolume{Price: 100.1, Volume: 13, BandLow: 100, BandHigh: 100.3}, // The largest level of the band is reported
+			expectedBid:  models.FoundVolume{Price: 98, Volume: 12, BandLow: 98, BandHigh: 98.4},      // 99 is beyond 0.5% of 98.4
+			expectedDiff: [2]float64{0.1, 1.0101},                                                     // (100.1 - 100) / 100 * 100 and (99 - 98) / 99 * 100
+		},
+		{
+			name:       "Price Range Too Narrow",
+			threshold:  10,
+			priceRange: 0.05, // Every band holds a single level
+		},
+		{
+			name:       "Threshold Above Totals",
+			threshold:  100,
+			priceRange: 50,
+		},
+		{
+			name:         "Wide Range Sums Whole Side",
+			threshold:    15,
+			priceRange:   10,
+			expectedAsk:  models.FoundVolume{Price: 100.1, Volume: 18, BandLow: 100, BandHigh: 110},
+			expectedBid:  models.FoundVolume{Price: 90, Volume: 18, BandLow: 90, BandHigh: 99},
+			expectedDiff: [2]float64{0.1, 9.0909}, // (99 - 90) / 99 * 100
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			volumes := ob.SearchCumulativeVolume("BTC/USD", "binance_spot", tc.threshold, tc.priceRange)
+			assert.Equal(t, 2, len(volumes)) // One volume per side
+
+			for _, volume := range volumes {
+				assert.Equal(t, "BTC/USD", volume.Pair)
+				assert.Equal(t, "binance_spot", volume.Exchange)
+
+				expected, expectedDiff := tc.expectedAsk, tc.expectedDiff[0]
+				switch volume.Side {
+				case "asks":
+				case "bids":
+					expected, expectedDiff = tc.expectedBid, tc.expectedDiff[1]
+				default:
+					t.Fatalf("unexpected side %q", volume.Side)
+				}
+
+				assert.Equal(t, expected.Price, volume.Price)
+				assert.InDelta(t, expected.Volume, volume.Volume, 1e-9)
+				assert.Equal(t, expected.BandLow, volume.BandLow)
+				assert.Equal(t, expected.BandHigh, volume.BandHigh)
+				assert.InDelta(t, expectedDiff, volume.Difference, 0.001)
+				assert.Equal(t, expected.Price != 0, !volume.VolumeTimeFound.IsZero()) // Only the found bands are timestamped
+			}
+		})
+	}
+
+	assert.Empty(t, ob.SearchCumulativeVolume("ETH/USD", "binance_spot", 10, 0.5)) // Unknown pair
+}
+
+// TestOrderbook_BestPrices tests the BestPrices function of the Orderbook
+// with populated, one-sided and empty books.
+func TestOrderbook_BestPrices(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	ob := orderbook.NewOrderbook()
+	ob.Upsert("BTC/USD",
+		[][]interface{}{{"50010", "1"}, {"50000", "2"}, {"50050", "3"}}, // Best ask is 50000
+		[][]interface{}{{"49950", "1"}, {"49990", "2"}, {"49900", "3"}}, // Best bid is 49990
+	)
+	ob.Upsert("ETH/USD", [][]interface{}{{"3000", "1"}}, [][]interface{}{}) // Book without bids
+	ob.Upsert("SOL/USD", [][]interface{}{}, [][]interface{}{{"150", "1"}})  // Book without asks
+	ob.Upsert("XRP/USD", [][]interface{}{}, [][]interface{}{})              // Empty book
+
+	tests := []struct {
+		name       string               // Name of the test case
+		pair       string               // Trading pair to be tested
+		expected   models.PriceSnapshot // Expected price snapshot
+		expectedOk bool                 // Whether the snapshot is expected to be available
+	}{
+		{
+			name: "Two-Sided Book",
+			pair: "BTC/USD",
+			expected: models.PriceSnapshot{
+				Pair:          "BTC/USD",
+				BestBid:       49990,
+				BestAsk:       50000,
+				Spread:        10,
+				Mid:           49995,
+				SpreadPercent: 10.0 / 49995 * 100,
+			},
+			expectedOk: true,
+		},
+		{
+			name:       "Book Without Bids",
+			pair:       "ETH/USD",
+			expected:   models.PriceSnapshot{Pair: "ETH/USD", BestAsk: 3000}, // No spread for a one-sided book
+			expectedOk: true,
+		},
+		{
+			name:       "Book Without Asks",
+			pair:       "SOL/USD",
+			expected:   models.PriceSnapshot{Pair: "SOL/USD", BestBid: 150},
+			expectedOk: true,
+		},
+		{
+			name:     "Empty Book",
+			pair:     "XRP/USD",
+			expected: models.PriceSnapshot{Pair: "XRP/USD"},
+		},
+		{
+			name:     "Unknown Pair",
+			pair:     "DOGE/USD",
+			expected: models.PriceSnapshot{Pair: "DOGE/USD"},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			snapshot, ok := ob.BestPrices(tc.pair)
+
+			assert.Equal(t, tc.expectedOk, ok)
+			assert.Equal(t, tc.expected.Pair, snapshot.Pair)
+			assert.Equal(t, tc.expected.BestBid, snapshot.BestBid)
+			assert.Equal(t, tc.expected.BestAsk, snapshot.BestAsk)
+			assert.InDelta(t, tc.expected.Spread, snapshot.Spread, 1e-9)
+			assert.InDelta(t, tc.expected.Mid, snapshot.Mid, 1e-9)
+			assert.InDelta(t, tc.expected.SpreadPercent, snapshot.SpreadPercent, 1e-9) // 0.02% for the two-sided book
+		})
+	}
+}
+
+// TestOrderbook_Snapshot tests the Snapshot function of the Orderbook.
+func TestOrderbook_Snapshot(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	ob := orderbook.NewOrderbook()
+	ob.Upsert("BTC/USD",
+		[][]interface{}{{"50010", "1"}, {"50000", "2"}, {"50050", "3"}},
+		[][]interface{}{{"49950", "1"}, {"49990", "2"}, {"49900", "3"}},
+	)
+	ob.Upsert("ETH/USD", [][]interface{}{{"3000", "1"}}, [][]interface{}{}) // Book without bids
+	ob.Upsert("XRP/USD", [][]interface{}{}, [][]interface{}{})              // Empty book
+
+	tests := []struct {
+		name         string                  // Name of the test case
+		pair         string                  // Trading pair to be tested
+		depth        int                     // Maximum number of levels of each side
+		expectedAsks []models.OrderbookLevel // Expected asks, the best one first
+		expectedBids []models.OrderbookLevel // Expected bids, the best one first
+		expectedOk   bool                    // Whether the snapshot is expected to be available
+	}{
+		{
+			name:         "All Levels",
+			pair:         "BTC/USD",
+			expectedAsks: []models.OrderbookLevel{{Price: 50000, Volume: 2}, {Price: 50010, Volume: 1}, {Price: 50050, Volume: 3}},
+			expectedBids: []models.OrderbookLevel{{Price: 49990, Volume: 2}, {Price: 49950, Volume: 1}, {Price: 49900, Volume: 3}},
+			expectedOk:   true,
+		},
+		{
+			name:         "Truncated To Depth",
+			pair:         "BTC/USD",
+			depth:        2,
+			expectedAsks: []models.OrderbookLevel{{Price: 50000, Volume: 2}, {Price: 50010, Volume: 1}},
+			expectedBids: []models.OrderbookLevel{{Price: 49990, Volume: 2}, {Price: 49950, Volume: 1}},
+			expectedOk:   true,
+		},
+		{
+			name:         "Depth Above Book Size",
+			pair:         "BTC/USD",
+			depth:        10,
+			expectedAsks: []models.OrderbookLevel{{Price: 50000, Volume: 2}, {Price: 50010, Volume: 1}, {Price: 50050, Volume: 3}},
+			expectedBids: []models.OrderbookLevel{{Price: 49990, Volume: 2}, {Price: 49950, Volume: 1}, {Price: 49900, Volume: 3}},
+			expectedOk:   true,
+		},
+		{
+			name:         "Book Without Bids",
+			pair:         "ETH/USD",
+			expectedAsks: []models.OrderbookLevel{{Price: 3000, Volume: 1}},
+			expectedBids: []models.OrderbookLevel{},
+			expectedOk:   true,
+		},
+		{
+			name:         "Empty Book",
+			pair:         "XRP/USD",
+			expectedAsks: []models.OrderbookLevel{},
+			expectedBids: []models.OrderbookLevel{},
+		},
+		{
+			name:         "Unknown Pair",
+			pair:         "DOGE/USD",
+			expectedAsks: []models.OrderbookLevel{},
+			expectedBids: []models.OrderbookLevel{},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			snapshot, ok := ob.Snapshot(tc.pair, tc.depth)
+
+			assert.Equal(t, tc.expectedOk, ok)
+			assert.Equal(t, tc.pair, snapshot.Pair)
+			assert.Equal(t, tc.expectedAsks, snapshot.Asks)
+			assert.Equal(t, tc.expectedBids, snapshot.Bids)
+		})
+	}
+}
+
 // TestOrderbook_ConcurrentAccess tests concurrent access to the Orderbook.
 func TestOrderbook_ConcurrentAccess(t *testing.T) {
 	t.Parallel() // Run tests in parallel for efficiency
@@ -135,3 +664,40 @@ func TestOrderbook_ConcurrentAccess(t *testing.T) {
 	assert.Greater(t, len(asks), 0, "Expected at least 1 ask, got %d", len(asks))
 	assert.Greater(t, len(bids), 0, "Expected at least 1 bid, got %d", len(bids))
 }
+
+// TestOrderbook_Release tests that the order book of a pair is removed once its last holder releases it.
+func TestOrderbook_Release(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	ob := orderbook.NewOrderbook() // Create a new orderbook instance
+	ob.Retain("BTC/USDT", "binance_spot")
+	ob.Retain("BTC/USDT", "binance_futures")
+	ob.Upsert("BTC/USDT", [][]interface{}{{"50000", "1"}}, [][]interface{}{{"49000", "1"}})
+
+	ob.Release("BTC/USDT", "binance_spot")
+	assert.Len(t, ob.Asks("BTC/USDT"), 1, "Expected the book to be kept while another section holds the pair")
+
+	ob.Release("BTC/USDT", "binance_spot") // Releasing twice doesn't drop the other holder
+	assert.Len(t, ob.Asks("BTC/USDT"), 1, "Expected the book to be kept while another section holds the pair")
+
+	ob.Release("BTC/USDT", "binance_futures")
+	_, ok := ob.Snapshot("BTC/USDT", 0)
+	assert.False(t, ok, "Expected the book to be removed after the last holder released the pair")
+}
+
+// TestOrderbook_EvictStale tests that only the order books which weren't updated for longer than the age are removed.
+func TestOrderbook_EvictStale(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	ob := orderbook.NewOrderbook() // Create a new orderbook instance
+	ob.Upsert("BTC/USDT", [][]interface{}{{"50000", "1"}}, [][]interface{}{{"49000", "1"}})
+
+	assert.Equal(t, 0, ob.EvictStale(time.Minute), "Expected a fresh book to be kept")
+	assert.Len(t, ob.Asks("BTC/USDT"), 1)
+
+	time.Sleep(10 * time.Millisecond)
+
+	assert.Equal(t, 1, ob.EvictStale(5*time.Millisecond), "Expected the stale book to be removed")
+	_, ok := ob.Snapshot("BTC/USDT", 0)
+	assert.False(t, ok)
+}
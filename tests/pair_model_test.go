@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"testing"
+
+	"cvs/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNormalizePair tests that the pairs typed in the common formats are converted to the BASE/QUOTE form.
+func TestNormalizePair(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name     string // Name of the test case
+		raw      string // Pair as it is submitted by the user
+		expected string // Expected pair in the BASE/QUOTE form, empty if the pair is invalid
+	}{
+		{name: "Canonical", raw: "BTC/USDT", expected: "BTC/USDT"},
+		{name: "Lower Case With Spaces", raw: " btc/usdt ", expected: "BTC/USDT"},
+		{name: "Dash", raw: "BTC-USDT", expected: "BTC/USDT"},
+		{name: "Underscore", raw: "eth_btc", expected: "ETH/BTC"},
+		{name: "Colon", raw: "SOL:USDC", expected: "SOL/USDC"},
+		{name: "Concatenated", raw: "BTCUSDT", expected: "BTC/USDT"},
+		{name: "Concatenated Lower Case", raw: "ethbtc", expected: "ETH/BTC"},
+		{name: "Longest Quote Wins", raw: "ETHFDUSD", expected: "ETH/FDUSD"},     // FDUSD rather than USD
+		{name: "Stablecoin Base", raw: "USDCUSDT", expected: "USDC/USDT"},        // The quote is matched at the end only
+		{name: "Digits In Base", raw: "1000PEPEUSDT", expected: "1000PEPE/USDT"}, // Assets may contain digits
+		{name: "Unknown Quote", raw: "BTCXYZ"},                                   // The assets can't be told apart
+		{name: "Quote Only", raw: "USDT"},                                        // There is no base asset
+		{name: "Empty", raw: "  "},                                               // Nothing to normalize
+		{name: "Missing Base", raw: "/USDT"},                                     // The base asset is empty
+		{name: "Several Separators", raw: "BTC/USDT/ETH"},                        // The quote isn't an asset
+		{name: "Invalid Characters", raw: "BTC$/USDT"},                           // Assets are alphanumeric
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			pair, err := models.NormalizePair(tc.raw)
+
+			if tc.expected == "" {
+				assert.ErrorIs(t, err, models.ErrInvalidPair)
+				assert.Empty(t, pair)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, pair)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"cvs/internal/config"
+	"cvs/internal/database/postgres"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConfigurePool tests that the connection pool settings of the config are applied to the database,
+// and the defaults are used for the settings which aren't set.
+func TestConfigurePool(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name                 string                // Name of the test case
+		cfg                  config.PostgresConfig // Config with the connection pool settings
+		expectedMaxOpenConns int                   // Expected maximum number of open connections reported by the stats
+	}{
+		{
+			name:                 "Configured",
+			cfg:                  config.PostgresConfig{MaxOpenConns: 7, MaxIdleConns: 3, ConnMaxLifetime: time.Minute},
+			expectedMaxOpenConns: 7,
+		},
+		{
+			name:                 "Defaults",
+			cfg:                  config.PostgresConfig{},
+			expectedMaxOpenConns: 25,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allows this subtest to run in parallel with other subtests
+
+			db, err := sqlx.Open("postgres", "host=localhost sslmode=disable") // Opening doesn't connect to the database
+			assert.NoError(t, err)
+			defer db.Close()
+
+			postgres.ConfigurePool(db, tc.cfg)
+
+			assert.Equal(t, tc.expectedMaxOpenConns, db.Stats().MaxOpenConnections)
+		})
+	}
+}
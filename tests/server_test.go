@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"cvs/internal/app"
+	"cvs/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewFiberAppTimeouts tests that the configured server timeouts reach the Fiber config
+// and that the defaults are used when they aren't set.
+func TestNewFiberAppTimeouts(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	tests := []struct {
+		name          string                  // Name of the test case
+		httpServer    config.HttpServerConfig // Server timeouts of the config
+		expectedRead  time.Duration           // Expected read timeout of the Fiber config
+		expectedWrite time.Duration           // Expected write timeout of the Fiber config
+		expectedIdle  time.Duration           // Expected idle timeout of the Fiber config
+	}{
+		{
+			name: "Configured Timeouts",
+			httpServer: config.HttpServerConfig{
+				ReadTimeout:  5 * time.Second,
+				WriteTimeout: 15 * time.Second,
+				IdleTimeout:  2 * time.Minute,
+			},
+			expectedRead:  5 * time.Second,
+			expectedWrite: 15 * time.Second,
+			expectedIdle:  2 * time.Minute,
+		},
+		{
+			name:          "Default Timeouts",
+			httpServer:    config.HttpServerConfig{},
+			expectedRead:  10 * time.Second,
+			expectedWrite: 10 * time.Second,
+			expectedIdle:  time.Minute,
+		},
+		{
+			name:          "Negative Timeouts",
+			httpServer:    config.HttpServerConfig{ReadTimeout: -time.Second, WriteTimeout: 3 * time.Second},
+			expectedRead:  10 * time.Second, // A negative timeout isn't applied
+			expectedWrite: 3 * time.Second,
+			expectedIdle:  time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture the current test case
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allow this test case to run in parallel
+
+			fiberConfig := app.NewFiberApp(&config.Config{HttpServer: tc.httpServer}).Config()
+
+			assert.Equal(t, tc.expectedRead, fiberConfig.ReadTimeout)
+			assert.Equal(t, tc.expectedWrite, fiberConfig.WriteTimeout)
+			assert.Equal(t, tc.expectedIdle, fiberConfig.IdleTimeout)
+		})
+	}
+}
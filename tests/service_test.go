@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"cvs/internal/config"
 	"cvs/internal/models"
 	"cvs/internal/service"
 	"errors"
@@ -73,6 +74,140 @@ func TestCheckUserDataService(t *testing.T) {
 	}
 }
 
+// TestCheckPasswordStrength tests that every rule of the password policy is enforced only when it is configured.
+func TestCheckPasswordStrength(t *testing.T) {
+	t.Parallel()
+
+	strictPolicy := config.PasswordPolicyConfig{MinLength: 10, RequireDigit: true, RequireUpper: true, RequireSymbol: true}
+
+	tests := []struct {
+		name        string                      // Name of the test case
+		password    string                      // Password to be checked
+		policy      config.PasswordPolicyConfig // Policy the password is checked against
+		expectedErr string                      // Expected error message, empty if the password is accepted
+	}{
+		{
+			name:     "Ok. Default Policy",
+			password: "password",
+			policy:   config.PasswordPolicyConfig{},
+		},
+		{
+			name:     "Ok. Strict Policy",
+			password: "Password-123",
+			policy:   strictPolicy,
+		},
+		{
+			name:        "Error. Password Is Empty",
+			password:    "",
+			policy:      config.PasswordPolicyConfig{},
+			expectedErr: "user password value is empty",
+		},
+		{
+			name:        "Error. Shorter Than Default Length",
+			password:    "passwor",
+			policy:      config.PasswordPolicyConfig{},
+			expectedErr: "password must be at least 8 characters long",
+		},
+		{
+			name:        "Error. Shorter Than Configured Length",
+			password:    "Pass-123",
+			policy:      strictPolicy,
+			expectedErr: "password must be at least 10 characters long",
+		},
+		{
+			name:        "Error. Length Counted In Characters",
+			password:    "пароль1", // 7 characters taking 13 bytes
+			policy:      config.PasswordPolicyConfig{},
+			expectedErr: "password must be at least 8 characters long",
+		},
+		{
+			name:        "Error. Digit Required",
+			password:    "Password-abc",
+			policy:      strictPolicy,
+			expectedErr: "password must contain a digit",
+		},
+		{
+			name:        "Error. Upper Case Letter Required",
+			password:    "password-123",
+			policy:      strictPolicy,
+			expectedErr: "password must contain an upper case letter",
+		},
+		{
+			name:        "Error. Symbol Required",
+			password:    "Password1234",
+			policy:      strictPolicy,
+			expectedErr: "password must contain a punctuation or symbol character",
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Create a copy of the current test case
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			err := service.CheckPasswordStrength(tc.password, tc.policy)
+
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.EqualError(t, err, tc.expectedErr)
+
+			var validationErr *service.ValidationError
+			assert.ErrorAs(t, err, &validationErr) // The failed rule is shown to the client as it is
+		})
+	}
+}
+
+// TestCheckUserSettingsWebhookHost tests that the webhook URLs pointing to the network of the scanner are rejected.
+func TestCheckUserSettingsWebhookHost(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string // Name of the test case
+		url       string // Webhook URL of the user
+		expectErr bool   // Whether the URL is expected to be rejected
+	}{
+		{name: "Ok. Public Address", url: "https://93.184.216.34/hooks/cvs"},
+		{name: "Ok. Public IPv6 Address", url: "https://[2606:2800:220:1:248:1893:25c8:1946]/hooks/cvs"},
+		{name: "Error. Loopback Address", url: "http://127.0.0.1:8080/hooks/cvs", expectErr: true},
+		{name: "Error. Loopback IPv6 Address", url: "http://[::1]/hooks/cvs", expectErr: true},
+		{name: "Error. Localhost", url: "http://localhost/hooks/cvs", expectErr: true},
+		{name: "Error. Localhost Subdomain", url: "http://api.localhost./hooks/cvs", expectErr: true},
+		{name: "Error. Private Address", url: "http://10.0.0.5/hooks/cvs", expectErr: true},
+		{name: "Error. Private Address Of Home Network", url: "http://192.168.1.1/hooks/cvs", expectErr: true},
+		{name: "Error. Cloud Metadata Address", url: "http://169.254.169.254/latest/meta-data", expectErr: true},
+		{name: "Error. Unspecified Address", url: "http://0.0.0.0/hooks/cvs", expectErr: true},
+		{name: "Error. Carrier-Grade NAT Address", url: "http://100.64.0.1/hooks/cvs", expectErr: true},
+		{name: "Error. IPv4-Mapped Loopback Address", url: "http://[::ffff:127.0.0.1]/hooks/cvs", expectErr: true},
+		{name: "Error. Unique Local IPv6 Address", url: "http://[fd00::1]/hooks/cvs", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Create a copy of the current test case
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			err := service.CheckUserSettings(models.UserSettings{UserID: 1, WebhookUrl: tc.url, WebhookSecret: "secret"})
+
+			if !tc.expectErr {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.EqualError(t, err, "webhook url must point to a public address")
+
+			var validationErr *service.ValidationError
+			assert.ErrorAs(t, err, &validationErr) // The rejected URL is shown to the client as a validation error
+		})
+	}
+}
+
 // TestCheckPairDataService tests the CheckPairData function of the service package.
 func TestCheckPairDataService(t *testing.T) {
 	t.Parallel()
@@ -92,6 +227,71 @@ func TestCheckPairDataService(t *testing.T) {
 			},
 			expectedErr: nil, // No error expected for valid input
 		},
+		{
+			name: "Ok. Relative search mode", // Test case for valid relative mode settings
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "binance_spot",
+				Pair:       "BTC/USDT",
+				ExactValue: 1,
+				SearchMode: models.SearchModeRelative,
+				Multiplier: 5,
+				Window:     10,
+			},
+			expectedErr: nil, // No error expected for valid input
+		},
+		{
+			name: "Ok. Cumulative search mode", // Test case for valid cumulative mode settings
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "binance_spot",
+				Pair:       "BTC/USDT",
+				ExactValue: 1,
+				SearchMode: models.SearchModeCumulative,
+				PriceRange: 0.5,
+			},
+			expectedErr: nil, // No error expected for valid input
+		},
+		{
+			name: "Ok. OKX swap exchange", // Test case for an exchange added to the supported ones
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "okx_swap",
+				Pair:       "BTC/USDT",
+				ExactValue: 1,
+			},
+			expectedErr: nil, // No error expected for valid input
+		},
+		{
+			name: "Ok. Binance COIN-M exchange", // Test case for an exchange added to the supported ones
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "binance_coinm",
+				Pair:       "BTC/USD",
+				ExactValue: 1,
+			},
+			expectedErr: nil, // No error expected for valid input
+		},
+		{
+			name: "Ok. Bybit inverse exchange", // Test case for an exchange added to the supported ones
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "bybit_inverse",
+				Pair:       "BTC/USD",
+				ExactValue: 1,
+			},
+			expectedErr: nil, // No error expected for valid input
+		},
+		{
+			name: "Ok. Coinbase spot exchange", // Test case for an exchange added to the supported ones
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "coinbase_spot",
+				Pair:       "BTC/USDT",
+				ExactValue: 1,
+			},
+			expectedErr: nil, // No error expected for valid input
+		},
 		{
 			name: "Error. Pair name is empty", // Test case for empty pair name
 			inputPairData: models.UserPairs{
@@ -152,6 +352,143 @@ func TestCheckPairDataService(t *testing.T) {
 			},
 			expectedErr: errors.New("invalid exchange name format"), // Expected error for invalid exchange name format
 		},
+		{
+			name: "Error. Invalid search mode", // Test case for unknown search mode
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "binance_spot",
+				Pair:       "BTC/USDT",
+				ExactValue: 1,
+				SearchMode: "median", // Unknown search mode
+			},
+			expectedErr: errors.New("search mode must be exact, relative or cumulative"),
+		},
+		{
+			name: "Error. Relative mode multiplier must be above one", // Test case for the relative mode without a multiplier
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "binance_spot",
+				Pair:       "BTC/USDT",
+				ExactValue: 1,
+				SearchMode: models.SearchModeRelative,
+				Multiplier: 1, // A level equal to its neighbours doesn't stand out
+				Window:     10,
+			},
+			expectedErr: errors.New("multiplier must be above one in the relative search mode"),
+		},
+		{
+			name: "Error. Relative mode window out of range", // Test case for the relative mode without a window
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "binance_spot",
+				Pair:       "BTC/USDT",
+				ExactValue: 1,
+				SearchMode: models.SearchModeRelative,
+				Multiplier: 5,
+				Window:     0, // No neighbours to compare with
+			},
+			expectedErr: errors.New("window must be between 1 and 100 in the relative search mode"),
+		},
+		{
+			name: "Error. Cumulative mode without price range", // Test case for the cumulative mode summing nothing but single prices
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "binance_spot",
+				Pair:       "BTC/USDT",
+				ExactValue: 1,
+				SearchMode: models.SearchModeCumulative,
+			},
+			expectedErr: errors.New("price range must be above 0 and at most 100 in the cumulative search mode"),
+		},
+		{
+			name: "Error. Cumulative mode price range above 100", // Test case for a band wider than the price itself
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "binance_spot",
+				Pair:       "BTC/USDT",
+				ExactValue: 1,
+				SearchMode: models.SearchModeCumulative,
+				PriceRange: 150,
+			},
+			expectedErr: errors.New("price range must be above 0 and at most 100 in the cumulative search mode"),
+		},
+		{
+			name: "Error. Tolerance below zero", // Test case for a negative tolerance
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "binance_spot",
+				Pair:       "BTC/USDT",
+				ExactValue: 10,
+				Tolerance:  -1,
+			},
+			expectedErr: errors.New("tolerance must be between 0 and 100"),
+		},
+		{
+			name: "Error. Tolerance above one hundred", // Test case for a tolerance above the exact value
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "binance_spot",
+				Pair:       "BTC/USDT",
+				ExactValue: 10,
+				Tolerance:  100.5,
+			},
+			expectedErr: errors.New("tolerance must be between 0 and 100"),
+		},
+		{
+			name: "Valid tolerance", // Test case for the exact mode with a tolerance band
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "binance_spot",
+				Pair:       "BTC/USDT",
+				ExactValue: 10,
+				Tolerance:  100,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Error. Invalid side", // Test case for a side which isn't a side of the order book
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "binance_spot",
+				Pair:       "BTC/USDT",
+				ExactValue: 10,
+				Side:       "sell",
+			},
+			expectedErr: errors.New("side must be asks, bids or both"),
+		},
+		{
+			name: "Valid asks side", // Test case for a pair watching the resistance levels only
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "binance_spot",
+				Pair:       "BTC/USDT",
+				ExactValue: 10,
+				Side:       models.SideAsks,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Valid bids side", // Test case for a pair watching the support levels only
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "binance_spot",
+				Pair:       "BTC/USDT",
+				ExactValue: 10,
+				Side:       models.SideBids,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Valid both sides", // Test case for a pair watching both sides explicitly
+			inputPairData: models.UserPairs{
+				UserID:     1,
+				Exchange:   "binance_spot",
+				Pair:       "BTC/USDT",
+				ExactValue: 10,
+				Side:       models.SideBoth,
+			},
+			expectedErr: nil,
+		},
 	}
 
 	for _, test := range tests {
@@ -162,7 +499,7 @@ func TestCheckPairDataService(t *testing.T) {
 
 			err := service.CheckPairData(tc.inputPairData) // Call the function to validate pair data
 
-			if tc.name == "Ok" {
+			if tc.expectedErr == nil {
 				assert.NoError(t, err) // Check that no error occurred for valid input
 			} else {
 				assert.EqualError(t, tc.expectedErr, err.Error()) // Check that the expected error matches the actual error
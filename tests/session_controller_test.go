@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"cvs/api/server/controller"
+	"cvs/internal/mocks"
+	"cvs/internal/models"
+	"cvs/internal/repository"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestListSessionsController tests the listing of the sessions of the user and the marking of the current one.
+func TestListSessionsController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name         string                                                            // Name of the test case
+		mocksSetup   func(sessionMock *mocks.SessionService, mockLogger *mocks.Logger) // Function to set up mock behavior
+		expectedCode int                                                               // Expected HTTP status code after the request
+		expectedList []models.Session                                                  // Expected sessions in the response
+	}{
+		{
+			name: "Successful Retrieval",
+			mocksSetup: func(sessionMock *mocks.SessionService, mockLogger *mocks.Logger) {
+				sessionMock.On("GetUserSessions", mock.Anything, 1).Return([]models.Session{
+					{ID: 3, UserID: 1, UserAgent: "phone"},
+					{ID: 2, UserID: 1, UserAgent: "laptop"},
+				}, nil)
+			},
+			expectedCode: http.StatusOK, // Expecting 200 OK status
+			expectedList: []models.Session{
+				{ID: 3, UserAgent: "phone"},
+				{ID: 2, UserAgent: "laptop", Current: true}, // The session which made the request
+			},
+		},
+		{
+			name: "Error Retrieving Sessions",
+			mocksSetup: func(sessionMock *mocks.SessionService, mockLogger *mocks.Logger) {
+				sessionMock.On("GetUserSessions", mock.Anything, 1).Return(nil, errors.New("db error"))
+				mockLogger.On("Error", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status due to retrieval failure
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable for use in goroutine
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run each test case in parallel
+
+			app := fiber.New() // Create a new Fiber application instance
+
+			mockSessionService := mocks.NewSessionService(t) // Create a new mock Session service
+			mockLogger := mocks.NewLogger(t)
+
+			tc.mocksSetup(mockSessionService, mockLogger) // Setup mocks for the current test case
+
+			sessionController := controller.NewSessionController(mockSessionService, mockLogger)
+
+			app.Get("/api/user/sessions", func(c *fiber.Ctx) error {
+				c.Locals("user", models.User{ID: 1})                  // Add user to context locals
+				c.Locals("session", models.Session{ID: 2, UserID: 1}) // Add the current session to context locals
+				return sessionController.List(c)
+			})
+
+			req := httptest.NewRequest("GET", "/api/user/sessions", nil) // Create a new GET request
+
+			resp, err := app.Test(req, -1) // Execute the request against the Fiber app
+			assert.NoError(t, err)         // Assert that there was no error during request execution
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode) // Assert that the response status code matches expected
+
+			if tc.expectedList != nil {
+				var sessions []models.Session
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&sessions))
+				assert.Equal(t, tc.expectedList, sessions) // Assert the current session is marked
+			}
+		})
+	}
+}
+
+// TestRevokeSessionController tests the revocation of a session of the user.
+func TestRevokeSessionController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name         string                                                            // Name of the test case
+		sessionId    string                                                            // ID of the session in the path
+		mocksSetup   func(sessionMock *mocks.SessionService, mockLogger *mocks.Logger) // Function to set up mock behavior
+		expectedCode int                                                               // Expected HTTP status code after the request
+	}{
+		{
+			name:      "Successful Revocation",
+			sessionId: "3",
+			mocksSetup: func(sessionMock *mocks.SessionService, mockLogger *mocks.Logger) {
+				sessionMock.On("DeleteSession", mock.Anything, 1, 3).Return(nil)
+			},
+			expectedCode: http.StatusOK, // Expecting 200 OK status
+		},
+		{
+			name:         "Invalid Session ID",
+			sessionId:    "abc",
+			mocksSetup:   func(sessionMock *mocks.SessionService, mockLogger *mocks.Logger) {}, // The service must not be called
+			expectedCode: http.StatusBadRequest,                                                // Expecting 400 Bad Request status due to the invalid ID
+		},
+		{
+			name:         "Non Positive Session ID",
+			sessionId:    "0",
+			mocksSetup:   func(sessionMock *mocks.SessionService, mockLogger *mocks.Logger) {}, // The service must not be called
+			expectedCode: http.StatusBadRequest,                                                // Expecting 400 Bad Request status due to the invalid ID
+		},
+		{
+			name:      "Session Not Found",
+			sessionId: "4",
+			mocksSetup: func(sessionMock *mocks.SessionService, mockLogger *mocks.Logger) {
+				sessionMock.On("DeleteSession", mock.Anything, 1, 4).Return(repository.ErrSessionNotFound)
+			},
+			expectedCode: http.StatusNotFound, // Expecting 404 Not Found status, since the user has no such session
+		},
+		{
+			name:      "Error Revoking Session",
+			sessionId: "3",
+			mocksSetup: func(sessionMock *mocks.SessionService, mockLogger *mocks.Logger) {
+				sessionMock.On("DeleteSession", mock.Anything, 1, 3).Return(errors.New("db error"))
+				mockLogger.On("Error", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status due to deletion failure
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable for use in goroutine
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run each test case in parallel
+
+			app := fiber.New() // Create a new Fiber application instance
+
+			mockSessionService := mocks.NewSessionService(t) // Create a new mock Session service
+			mockLogger := mocks.NewLogger(t)
+
+			tc.mocksSetup(mockSessionService, mockLogger) // Setup mocks for the current test case
+
+			sessionController := controller.NewSessionController(mockSessionService, mockLogger)
+
+			app.Delete("/api/user/sessions/:id", func(c *fiber.Ctx) error {
+				c.Locals("user", models.User{ID: 1}) // Add user to context locals
+				return sessionController.Revoke(c)
+			})
+
+			req := httptest.NewRequest("DELETE", "/api/user/sessions/"+tc.sessionId, nil) // Create a new DELETE request
+
+			resp, err := app.Test(req, -1) // Execute the request against the Fiber app
+			assert.NoError(t, err)         // Assert that there was no error during request execution
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode) // Assert that the response status code matches expected
+		})
+	}
+}
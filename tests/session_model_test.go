@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"bytes"
+	"cvs/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSessionRefreshToken tests that the refresh token is stored hashed and compared against the hash.
+func TestSessionRefreshToken(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	const refreshToken = "valid_refresh_token"
+
+	tests := []struct {
+		name         string // Name of the test case
+		refreshToken string // Refresh token compared with the stored one
+		unset        bool   // Whether the refresh token of the session is left unset
+		expectErr    bool   // Whether the comparison is expected to fail
+	}{
+		{
+			name:         "Correct Token",
+			refreshToken: refreshToken,
+			expectErr:    false,
+		},
+		{
+			name:         "Wrong Token",
+			refreshToken: "wrong_refresh_token",
+			expectErr:    true,
+		},
+		{
+			name:         "Unset Token",
+			refreshToken: "",
+			unset:        true,
+			expectErr:    true, // No refresh token matches the session without one
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable for use in goroutine
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run each test case in parallel
+
+			var session models.Session
+			if !tc.unset {
+				assert.NoError(t, session.SetRefreshToken(refreshToken))
+
+				assert.NotEmpty(t, session.RefreshToken)
+				assert.False(t, bytes.Contains(session.RefreshToken, []byte(refreshToken))) // The plaintext token isn't stored
+			}
+
+			err := session.CompareRefreshToken(tc.refreshToken)
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestSessionRefreshTokenSalted tests that the same refresh token is hashed differently every time,
+// so equal tokens can't be told apart by their hashes.
+func TestSessionRefreshTokenSalted(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	var first, second models.Session
+	assert.NoError(t, first.SetRefreshToken("refresh_token"))
+	assert.NoError(t, second.SetRefreshToken("refresh_token"))
+
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	assert.NoError(t, second.CompareRefreshToken("refresh_token")) // Both hashes match the token
+	assert.NoError(t, first.CompareRefreshToken("refresh_token"))
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"cvs/internal/models"
+	"cvs/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSessionRepository tests starting, retrieving, refreshing and revoking the sessions of a user.
+func TestSessionRepository(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	db := setupDB()                                    // Set up the database connection for testing
+	defer db.Close()                                   // Ensure the database connection is closed after the test
+	sessionRepo := repository.NewSessionRepository(db) // Initialize the session repository
+
+	id, err := insertUser(db, "sessionuser@example.com", []byte("password123"))
+	defer db.ExecContext(ctx, deleteUserQueryRow, id) // Clean up by deleting the user after the test
+	assert.NoError(t, err)
+
+	first := models.Session{ID: 1, UserID: id, RefreshToken: []byte("first_refresh_token"), UserAgent: "laptop"}
+	second := models.Session{ID: 2, UserID: id, RefreshToken: []byte("second_refresh_token"), UserAgent: "phone"}
+	assert.NoError(t, sessionRepo.InsertSession(ctx, first))
+	assert.NoError(t, sessionRepo.InsertSession(ctx, second))
+	assert.Error(t, sessionRepo.InsertSession(ctx, first)) // The user already has a session with the ID
+
+	stored, err := sessionRepo.GetSession(ctx, id, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, first.RefreshToken, stored.RefreshToken)
+	assert.Equal(t, "laptop", stored.UserAgent)
+
+	_, err = sessionRepo.GetSession(ctx, id, 3)
+	assert.ErrorIs(t, err, repository.ErrSessionNotFound)
+
+	assert.NoError(t, sessionRepo.TouchSession(ctx, id, 1)) // The first session becomes the most recently seen
+	assert.ErrorIs(t, sessionRepo.TouchSession(ctx, id, 3), repository.ErrSessionNotFound)
+
+	sessions, err := sessionRepo.GetUserSessions(ctx, id)
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 2) {
+		assert.Equal(t, 1, sessions[0].ID) // The most recently seen first
+		assert.Equal(t, 2, sessions[1].ID)
+	}
+
+	rotated := models.Session{ID: 2, UserID: id, RefreshToken: []byte("rotated_refresh_token")}
+	assert.NoError(t, sessionRepo.RotateRefreshToken(ctx, rotated, second.RefreshToken)) // Rotate the currently stored token
+
+	replayed := models.Session{ID: 2, UserID: id, RefreshToken: []byte("replayed_refresh_token")}
+	assert.Error(t, sessionRepo.RotateRefreshToken(ctx, replayed, second.RefreshToken)) // Rotate the already rotated token again
+
+	stored, err = sessionRepo.GetSession(ctx, id, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, rotated.RefreshToken, stored.RefreshToken) // The replay must not overwrite the rotated token
+
+	assert.NoError(t, sessionRepo.DeleteSession(ctx, id, 2))
+	assert.ErrorIs(t, sessionRepo.DeleteSession(ctx, id, 2), repository.ErrSessionNotFound) // The session is already revoked
+
+	_, err = sessionRepo.GetSession(ctx, id, 1)
+	assert.NoError(t, err) // Revoking a session leaves the other sessions active
+
+	assert.NoError(t, sessionRepo.DeleteUserSessions(ctx, id))
+	assert.NoError(t, sessionRepo.DeleteUserSessions(ctx, id)) // A user without sessions is not an error
+
+	sessions, err = sessionRepo.GetUserSessions(ctx, id)
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+// TestDeleteExpiredSessions tests that only the sessions which weren't seen for longer than the TTL are removed.
+func TestDeleteExpiredSessions(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	db := setupDB()                                    // Set up the database connection for testing
+	defer db.Close()                                   // Ensure the database connection is closed after the test
+	sessionRepo := repository.NewSessionRepository(db) // Initialize the session repository
+
+	id, err := insertUser(db, "expiredsessionuser@example.com", []byte("password123"))
+	defer db.ExecContext(ctx, deleteUserQueryRow, id) // Clean up by deleting the user after the test
+	assert.NoError(t, err)
+
+	assert.NoError(t, sessionRepo.InsertSession(ctx, models.Session{ID: 1, UserID: id, RefreshToken: []byte("expired_refresh_token")}))
+	assert.NoError(t, sessionRepo.InsertSession(ctx, models.Session{ID: 2, UserID: id, RefreshToken: []byte("active_refresh_token")}))
+
+	// The first session was last seen before its refresh token expired
+	_, err = db.ExecContext(ctx, `UPDATE user_sessions SET last_seen_at = now() - interval '48 hours' WHERE user_id=$1 AND id=1`, id)
+	assert.NoError(t, err)
+
+	deleted, err := sessionRepo.DeleteExpiredSessions(ctx, 24*time.Hour)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, deleted, int64(1)) // The expired sessions of the other tests may be removed too
+
+	sessions, err := sessionRepo.GetUserSessions(ctx, id)
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, 2, sessions[0].ID) // The active session is kept
+	}
+}
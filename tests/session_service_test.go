@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"cvs/internal/mocks"
+	"cvs/internal/models"
+	"cvs/internal/repository"
+	"cvs/internal/service"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetUserSessionsService(t *testing.T) {
+	t.Parallel() // Enable parallel execution for this test
+
+	sessions := []models.Session{{ID: 2, UserID: 1}, {ID: 1, UserID: 1}}
+
+	// Define test cases for retrieving the sessions of a user
+	tests := []struct {
+		name     string           // Name of the test case
+		userID   int              // ID of the user whose sessions are retrieved
+		mockCall bool             // Whether the repository is expected to be called
+		sessions []models.Session // Sessions returned by the repository
+		err      error            // Error returned by the repository
+		wantErr  bool             // Expected outcome: true if an error is expected
+	}{
+		{
+			name:     "Successful Retrieval",
+			userID:   1,
+			mockCall: true,
+			sessions: sessions,
+		},
+		{
+			name:     "Repository Error",
+			userID:   1,
+			mockCall: true,
+			err:      errors.New("db error"),
+			wantErr:  true,
+		},
+		{
+			name:    "Invalid User ID",
+			userID:  0,
+			wantErr: true, // The repository isn't called for the invalid ID
+		},
+	}
+
+	// Iterate through each test case
+	for _, tt := range tests {
+		tc := tt // Capture the current test case
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allow this test case to run in parallel
+
+			mockSessionRepository := mocks.NewSessionRepository(t) // Create a new instance of the mocked repository
+			if tc.mockCall {
+				mockSessionRepository.On("GetUserSessions", mock.Anything, tc.userID).Return(tc.sessions, tc.err) // Set up expectation
+			}
+
+			sessionService := service.NewSessionService(mockSessionRepository, contextTimeout) // Create a new instance of the session service
+
+			result, err := sessionService.GetUserSessions(ctx, tc.userID) // Call the method under test
+
+			if tc.wantErr {
+				assert.Error(t, err) // Assert that an error occurred as expected
+			} else {
+				assert.NoError(t, err)               // Assert no error occurred for successful retrieval
+				assert.Equal(t, tc.sessions, result) // Assert the sessions are returned as stored
+			}
+		})
+	}
+}
+
+func TestRotateRefreshTokenService(t *testing.T) {
+	t.Parallel() // Enable parallel execution for this test
+
+	previousRefreshToken := []byte("previous_refresh_token")
+
+	// Define test cases for rotating refresh tokens
+	tests := []struct {
+		name    string         // Name of the test case
+		session models.Session // Session data for the test case
+		err     error          // Expected error (nil for success)
+	}{
+		{
+			name:    "Successful Rotation",
+			session: models.Session{ID: 2, UserID: 1, RefreshToken: []byte("new_refresh_token")},
+			err:     nil, // No error expected for successful rotation
+		},
+		{
+			name:    "Already Rotated",
+			session: models.Session{ID: 2, UserID: 1, RefreshToken: []byte("new_refresh_token")},
+			err:     errors.New(""), // Simulate the stored refresh token being replaced already
+		},
+	}
+
+	// Iterate through each test case
+	for _, tt := range tests {
+		tc := tt // Capture the current test case
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allow this test case to run in parallel
+
+			mockSessionRepository := mocks.NewSessionRepository(t)                                                         // Create a new instance of the mocked repository
+			mockSessionRepository.On("RotateRefreshToken", mock.Anything, tc.session, previousRefreshToken).Return(tc.err) // Set up expectation
+
+			sessionService := service.NewSessionService(mockSessionRepository, contextTimeout) // Create a new instance of the session service
+
+			err := sessionService.RotateRefreshToken(ctx, tc.session, previousRefreshToken) // Call the method under test
+
+			if tc.err == nil {
+				assert.NoError(t, err) // Assert no error occurred for successful rotation
+			} else {
+				assert.Error(t, err) // Assert that an error occurred as expected
+			}
+		})
+	}
+}
+
+func TestTouchSessionService(t *testing.T) {
+	t.Parallel() // Enable parallel execution for this test
+
+	// Define test cases for updating the time a session was last seen at
+	tests := []struct {
+		name       string    // Name of the test case
+		lastSeenAt time.Time // Time the session was last seen at
+		mockCall   bool      // Whether the repository is expected to be called
+		err        error     // Error returned by the repository
+	}{
+		{
+			name:       "Recently Seen",
+			lastSeenAt: time.Now(),
+			mockCall:   false, // The session isn't updated within the touch interval
+		},
+		{
+			name:       "Seen Long Ago",
+			lastSeenAt: time.Now().Add(-time.Hour),
+			mockCall:   true,
+		},
+		{
+			name:       "Revoked Session",
+			lastSeenAt: time.Now().Add(-time.Hour),
+			mockCall:   true,
+			err:        repository.ErrSessionNotFound,
+		},
+	}
+
+	// Iterate through each test case
+	for _, tt := range tests {
+		tc := tt // Capture the current test case
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allow this test case to run in parallel
+
+			session := models.Session{ID: 2, UserID: 1, LastSeenAt: tc.lastSeenAt}
+
+			mockSessionRepository := mocks.NewSessionRepository(t) // Create a new instance of the mocked repository
+			if tc.mockCall {
+				mockSessionRepository.On("TouchSession", mock.Anything, session.UserID, session.ID).Return(tc.err) // Set up expectation
+			}
+
+			sessionService := service.NewSessionService(mockSessionRepository, contextTimeout) // Create a new instance of the session service
+
+			err := sessionService.TouchSession(ctx, session) // Call the method under test
+
+			assert.ErrorIs(t, err, tc.err) // Assert the error of the repository is returned
+		})
+	}
+}
+
+func TestDeleteSessionService(t *testing.T) {
+	t.Parallel() // Enable parallel execution for this test
+
+	// Define test cases for revoking a session
+	tests := []struct {
+		name string // Name of the test case
+		err  error  // Error returned by the repository
+	}{
+		{
+			name: "Successful Deletion",
+			err:  nil,
+		},
+		{
+			name: "Session Not Found",
+			err:  repository.ErrSessionNotFound,
+		},
+	}
+
+	// Iterate through each test case
+	for _, tt := range tests {
+		tc := tt // Capture the current test case
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allow this test case to run in parallel
+
+			mockSessionRepository := mocks.NewSessionRepository(t)                        // Create a new instance of the mocked repository
+			mockSessionRepository.On("DeleteSession", mock.Anything, 1, 2).Return(tc.err) // Set up expectation
+
+			sessionService := service.NewSessionService(mockSessionRepository, contextTimeout) // Create a new instance of the session service
+
+			err := sessionService.DeleteSession(ctx, 1, 2) // Call the method under test
+
+			assert.ErrorIs(t, err, tc.err) // Assert the error of the repository is returned
+		})
+	}
+}
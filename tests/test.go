@@ -4,11 +4,14 @@ import (
 	"context"
 	"cvs/internal/config"
 	"cvs/internal/database/postgres"
+	"cvs/internal/mocks"
 	"cvs/internal/service"
 	"fmt"
+	"testing"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/mock"
 )
 
 const (
@@ -22,9 +25,32 @@ const (
 var (
 	ctx                = context.Background()
 	deleteUserQueryRow = fmt.Sprintf(`DELETE FROM %s WHERE id=$1`, usersTable)
-	jwtService         = service.NewJwtService("secret_key", 20, 1200)
+	jwtService         = newJwtService("secret_key", 20, 1200)
 )
 
+const (
+	jwtIssuer   = "cvs_tests"     // Issuer of the tokens created by the test JWT service
+	jwtAudience = "cvs_tests_api" // Audience of the tokens created by the test JWT service
+)
+
+// Helper function to create a JWT service with lifetimes known to be valid
+func newJwtService(secretKey string, accessTokenLifetimeHours, refreshTokenLifetimeHours time.Duration) service.JwtService {
+	jwtService, err := service.NewJwtService(secretKey, "", nil, accessTokenLifetimeHours, refreshTokenLifetimeHours, jwtIssuer, jwtAudience, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	return jwtService
+}
+
+// Helper function to create user settings which notify about every found volume
+func notifyAllSettingsService(t *testing.T) *mocks.UserSettingsService {
+	userSettingsService := mocks.NewUserSettingsService(t)
+	userSettingsService.On("ShouldNotify", mock.Anything, mock.Anything, mock.Anything).Return(true).Maybe()
+
+	return userSettingsService
+}
+
 func setupDB() *sqlx.DB {
 	cfg := config.NewConfig(confPath)
 
@@ -37,10 +63,9 @@ func setupDB() *sqlx.DB {
 // Helper function to create a user in the users table
 func insertUser(db *sqlx.DB, email string, password []byte) (int, error) {
 	var userID int
-	refreshToken := []byte{}
 
-	query := `INSERT INTO users (email, password, refresh_token, session_id) VALUES ($1, $2, $3, $4) RETURNING id`
-	err := db.GetContext(context.Background(), &userID, query, email, password, refreshToken, 1)
+	query := `INSERT INTO users (email, password, password_version) VALUES ($1, $2, $3) RETURNING id`
+	err := db.GetContext(context.Background(), &userID, query, email, password, 1)
 
 	return userID, err
 }
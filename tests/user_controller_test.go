@@ -2,24 +2,45 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"cvs/api/server/controller"
+	"cvs/api/server/middleware"
+	"cvs/internal/config"
 	"cvs/internal/mocks"
 	"cvs/internal/models"
+	"cvs/internal/repository"
 	"cvs/internal/service/exchange"
 
 	"github.com/goccy/go-json"
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+// insertUserWithSession returns the mocked InsertUserWithSession implementation which assigns userID
+// to the inserted user and, like the repository, fails if starting the session fails.
+func insertUserWithSession(userID int) func(context.Context, models.User, repository.NewSessionFunc) (int, error) {
+	return func(_ context.Context, user models.User, newSession repository.NewSessionFunc) (int, error) {
+		user.ID = userID
+		if _, err := newSession(&user); err != nil {
+			return 0, err
+		}
+
+		return userID, nil
+	}
+}
+
 // Test for Signup method
 func TestSignup(t *testing.T) {
 	t.Parallel() // Allows this test to run in parallel with other tests
@@ -33,7 +54,9 @@ func TestSignup(t *testing.T) {
 			jwtMock *mocks.JwtService,
 			mockLogger *mocks.Logger,
 		) // Function to set up mock behavior
-		expectedCode int // Expected HTTP status code after the request
+		expectEmail  bool   // Whether the verification email is expected to be sent
+		expectedCode int    // Expected HTTP status code after the request
+		expectedBody string // Expected response body in JSON format, not checked when empty
 	}{
 		{
 			name: "Successful Signup",
@@ -42,11 +65,14 @@ func TestSignup(t *testing.T) {
 				Password: "password123",
 			},
 			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
-				userMock.On("InsertUser", mock.Anything, mock.Anything).Return(1, nil)                    // Mock successful user insertion
-				userMock.On("UpdateRefreshToken", mock.Anything, mock.Anything).Return(nil)               // Mock successful refresh token update
-				jwtMock.On("CreateAccessToken", 1, mock.Anything).Return("accessToken", int64(3600), nil) // Mock access token creation
-				jwtMock.On("CreateRefreshToken", 1, mock.Anything).Return("refreshToken", nil)            // Mock refresh token creation
+				userMock.On("InsertUserWithSession", mock.Anything, mock.MatchedBy(func(user models.User) bool {
+					return !user.Verified // The user is created unverified
+				}), mock.Anything).Return(insertUserWithSession(1)) // Mock successful user insertion along with the first session
+				jwtMock.On("CreateAccessToken", 1, mock.Anything, mock.Anything).Return("accessToken", int64(3600), nil) // Mock access token creation
+				jwtMock.On("CreateRefreshToken", 1, mock.Anything).Return("refreshToken", nil)                           // Mock refresh token creation
+				jwtMock.On("CreateVerifyEmailToken", 1).Return("verifyToken", nil)                                       // Mock verification token creation
 			},
+			expectEmail:  true,
 			expectedCode: http.StatusOK, // Expecting 200 OK status
 		},
 		{
@@ -55,10 +81,19 @@ func TestSignup(t *testing.T) {
 				Email:    "",
 				Password: "",
 			},
-			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
-				mockLogger.On("Error", mock.Anything).Return(nil) // Mock refresh token creation
-			}, // No mocks needed for this case
+			mocksSetup:   nil,                   // Validation errors aren't logged, so no mocks are needed for this case
 			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status due to invalid input
+			expectedBody: `{"result":"email data is empty","code":"validation_failed"}`,
+		},
+		{
+			name: "Weak Password",
+			newUserData: models.UserAuth{
+				Email:    "test@example.com",
+				Password: "pass",
+			},
+			mocksSetup:   nil, // The user isn't inserted with a password breaking the policy
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"result":"password must be at least 8 characters long","code":"validation_failed"}`,
 		},
 		{
 			name: "Error Inserting User",
@@ -67,10 +102,38 @@ func TestSignup(t *testing.T) {
 				Password: "password123",
 			},
 			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
-				mockLogger.On("Error", mock.Anything).Return(nil)                                             // Mock refresh token creation
-				userMock.On("InsertUser", mock.Anything, mock.Anything).Return(0, errors.New("insert error")) // Mock error during user insertion
+				mockLogger.On("Error", mock.Anything).Return(nil) // Mock refresh token creation
+				userMock.On("InsertUserWithSession", mock.Anything, mock.Anything, mock.Anything).
+					Return(0, fmt.Errorf("internal.repository.InsertUserWithSession: %w", errors.New("pq: connection refused"))) // Mock error during user insertion
+			},
+			expectedCode: http.StatusInternalServerError,                               // Expecting 500 Internal Server Error status due to insertion failure
+			expectedBody: `{"result":"internal server error","code":"internal_error"}`, // The details of the failure are only logged
+		},
+		{
+			name: "Email Already Registered",
+			newUserData: models.UserAuth{
+				Email:    "test@example.com",
+				Password: "password123",
+			},
+			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+				userMock.On("InsertUserWithSession", mock.Anything, mock.Anything, mock.Anything).Return(0, repository.ErrEmailAlreadyExists) // A user with the email exists
+			},
+			expectedCode: http.StatusConflict, // Expecting 409 Conflict status for the duplicate email
+			expectedBody: `{"result":"a user with this email is already registered","code":"conflict"}`,
+		},
+		{
+			name: "Error Creating Refresh Token",
+			newUserData: models.UserAuth{
+				Email:    "test@example.com",
+				Password: "password123",
+			},
+			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+				mockLogger.On("Error", mock.Anything).Return(nil)
+				userMock.On("InsertUserWithSession", mock.Anything, mock.Anything, mock.Anything).Return(insertUserWithSession(1)) // The token error must roll back the insertion
+				jwtMock.On("CreateAccessToken", 1, mock.Anything, mock.Anything).Return("accessToken", int64(3600), nil)           // Mock access token creation
+				jwtMock.On("CreateRefreshToken", 1, mock.Anything).Return("", errors.New("token error"))                           // Mock error during refresh token creation
 			},
-			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status due to insertion failure
+			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status since the user isn't stored
 		},
 	}
 
@@ -91,8 +154,16 @@ func TestSignup(t *testing.T) {
 				tc.mocksSetup(mockUserService, mockJwtService, mockLogger) // Setup mocks for the current test case
 			}
 
-			uc := controller.NewUserController(mockUserService, mockJwtService, mockAllExchangesStorage, mockLogger) // Create a new UserController instance
-			app.Post("/api/user/auth/signup", uc.Signup)                                                             // Define POST route for signup
+			sent := make(chan struct{})
+			mockEmailService := mocks.NewEmailService(t) // Create a new mock email service
+			if tc.expectEmail {
+				mockEmailService.On("SendVerifyEmailToken", tc.newUserData.Email, "verifyToken").
+					Return(nil).
+					Run(func(args mock.Arguments) { close(sent) }) // Signal that the email was sent
+			}
+
+			uc := controller.NewUserController(mockUserService, nil, mockJwtService, mockEmailService, mockAllExchangesStorage, config.PasswordPolicyConfig{}, mockLogger) // Create a new UserController instance
+			app.Post("/api/user/auth/signup", uc.Signup)                                                                                                                   // Define POST route for signup
 
 			reqBody := `{"email":"` + tc.newUserData.Email + `","password":"` + tc.newUserData.Password + `"}`
 			req := httptest.NewRequest("POST", "/api/user/auth/signup", strings.NewReader(reqBody)) // Create a new POST request with JSON body
@@ -102,6 +173,119 @@ func TestSignup(t *testing.T) {
 
 			assert.NoError(t, err)                            // Assert that there was no error during request execution
 			assert.Equal(t, tc.expectedCode, resp.StatusCode) // Assert that the response status code matches expected
+
+			if tc.expectedBody != "" {
+				bodyBytes, _ := io.ReadAll(resp.Body)                // Read the response body into bytes
+				assert.JSONEq(t, tc.expectedBody, string(bodyBytes)) // Assert that the JSON response matches expected body
+			}
+
+			// The email is sent in the background, wait for it before the mocks are asserted
+			if tc.expectEmail {
+				select {
+				case <-sent:
+				case <-time.After(2 * time.Second):
+					t.Fatal("the verification email wasn't sent")
+				}
+			}
+		})
+	}
+}
+
+// Test for VerifyEmail method
+func TestVerifyEmailController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	validToken, err := jwtService.CreateVerifyEmailToken(1)
+	assert.NoError(t, err)
+
+	// Build a verification token which expired a minute ago
+	expiredToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": 1,
+		"type":    "verify_email",
+		"exp":     time.Now().Add(-time.Minute).Unix(),
+	}).SignedString([]byte("secret_key"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name         string                                                      // Name of the test case
+		token        string                                                      // Verification token passed in the query
+		mocksSetup   func(userMock *mocks.UserService, mockLogger *mocks.Logger) // Function to set up mock behavior
+		expectedCode int                                                         // Expected HTTP status code after the request
+	}{
+		{
+			name:  "Valid Token",
+			token: validToken,
+			mocksSetup: func(userMock *mocks.UserService, mockLogger *mocks.Logger) {
+				userMock.On("GetUserById", mock.Anything, 1).Return(models.User{ID: 1, Email: "test@example.com"}, nil)
+				userMock.On("VerifyUser", mock.Anything, 1).Return(nil) // The user must be marked as verified
+			},
+			expectedCode: http.StatusOK, // Expecting 200 OK status
+		},
+		{
+			name:  "Expired Token",
+			token: expiredToken,
+			mocksSetup: func(userMock *mocks.UserService, mockLogger *mocks.Logger) {
+				mockLogger.On("Error", mock.Anything).Return(nil).Maybe()
+				mockLogger.On("Error", mock.Anything, mock.Anything).Return(nil).Maybe()
+			},
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status due to the expired token
+		},
+		{
+			name:  "Already Verified",
+			token: validToken,
+			mocksSetup: func(userMock *mocks.UserService, mockLogger *mocks.Logger) {
+				userMock.On("GetUserById", mock.Anything, 1).Return(models.User{ID: 1, Email: "test@example.com", Verified: true}, nil)
+			},
+			expectedCode: http.StatusConflict, // Expecting 409 Conflict status as there is nothing to verify
+		},
+		{
+			name:  "User Not Found",
+			token: validToken,
+			mocksSetup: func(userMock *mocks.UserService, mockLogger *mocks.Logger) {
+				userMock.On("GetUserById", mock.Anything, 1).Return(models.User{}, errors.New("user not found"))
+				mockLogger.On("Error", mock.Anything).Return(nil).Maybe()
+				mockLogger.On("Error", mock.Anything, mock.Anything).Return(nil).Maybe()
+			},
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status as the user was deleted
+		},
+		{
+			name:  "Error Verifying User",
+			token: validToken,
+			mocksSetup: func(userMock *mocks.UserService, mockLogger *mocks.Logger) {
+				userMock.On("GetUserById", mock.Anything, 1).Return(models.User{ID: 1, Email: "test@example.com"}, nil)
+				userMock.On("VerifyUser", mock.Anything, 1).Return(errors.New("db error"))
+				mockLogger.On("Error", mock.Anything).Return(nil).Maybe()
+				mockLogger.On("Error", mock.Anything, mock.Anything).Return(nil).Maybe()
+			},
+			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status due to the database failure
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := fiber.New()
+
+			mockUserService := mocks.NewUserService(t)
+			mockAllExchangesStorage := mocks.NewAllExchanges(t)
+			mockLogger := mocks.NewLogger(t)
+
+			if tc.mocksSetup != nil {
+				tc.mocksSetup(mockUserService, mockLogger) // Setup mocks for the current test case
+			}
+
+			userController := controller.NewUserController(mockUserService, nil, jwtService, nil, mockAllExchangesStorage, config.PasswordPolicyConfig{}, mockLogger)
+			app.Get("/api/user/auth/verify", userController.VerifyEmail)
+
+			req := httptest.NewRequest("GET", "/api/user/auth/verify?token="+tc.token, nil)
+
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
 		})
 	}
 }
@@ -110,45 +294,117 @@ func TestSignup(t *testing.T) {
 func TestTokens(t *testing.T) {
 	t.Parallel() // Allows this test to run in parallel with other tests
 
+	storedUser := models.User{ID: 1, PasswordVersion: 7} // The user as stored in the database
+
+	// The session as stored in the database, holding the hash of the currently valid refresh token
+	storedSession := models.Session{ID: 10, UserID: 1}
+	storedSession.SetRefreshToken("valid_refresh_token")
+
 	// Define a slice of test cases for the Tokens functionality
 	tests := []struct {
-		name         string                                                                                 // Name of the test case
-		userID       int                                                                                    // User ID for token generation
-		refreshToken string                                                                                 // Refresh token for authentication
-		mocksSetup   func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) // Function to set up mock behavior
-		expectedCode int                                                                                    // Expected HTTP status code after the request
+		name         string // Name of the test case
+		refreshToken string // Refresh token for authentication
+		mocksSetup   func(
+			userMock *mocks.UserService,
+			sessionMock *mocks.SessionService,
+			jwtMock *mocks.JwtService,
+			mockLogger *mocks.Logger,
+		) // Function to set up mock behavior
+		expectedCode int // Expected HTTP status code after the request
 	}{
 		{
-			name:         "Successful Token Retrieval",
-			userID:       1,
+			name:         "Successful Rotation",
 			refreshToken: "valid_refresh_token", // Valid refresh token for authentication
-			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
-				// Mock successful access and refresh token creation
-				userMock.On("UpdateRefreshToken", mock.Anything, mock.Anything).Return(nil)
-				jwtMock.On("CreateAccessToken", 1, mock.Anything).Return("newAccessToken", int64(3600), nil)
-				jwtMock.On("CreateRefreshToken", 1, mock.Anything).Return("newRefreshToken", nil)
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+				jwtMock.On("ParseRefreshToken", "valid_refresh_token").Return(1, 10, nil)
+				userMock.On("GetUserById", mock.Anything, 1).Return(storedUser, nil)
+				sessionMock.On("GetSession", mock.Anything, 1, 10).Return(storedSession, nil)
+				jwtMock.On("CreateAccessToken", 1, 10, mock.Anything).Return("newAccessToken", int64(3600), nil) // The session keeps its ID
+				jwtMock.On("CreateRefreshToken", 1, 10).Return("newRefreshToken", nil)
+				// The stored refresh token must be replaced only if it is still the rotated one
+				sessionMock.On("RotateRefreshToken", mock.Anything, mock.MatchedBy(func(session models.Session) bool {
+					return session.ID == 10 && session.CompareRefreshToken("newRefreshToken") == nil
+				}), storedSession.RefreshToken).Return(nil)
 			},
 			expectedCode: http.StatusOK, // Expecting 200 OK status
 		},
 		{
-			name:   "Invalid Refresh Token",
-			userID: 1,
-			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
-				// Mock successful access and refresh token creation
+			name:         "Replayed Old Refresh Token",
+			refreshToken: "old_refresh_token", // Refresh token of the same session which was already rotated
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+				jwtMock.On("ParseRefreshToken", "old_refresh_token").Return(1, 10, nil)
+				userMock.On("GetUserById", mock.Anything, 1).Return(storedUser, nil)
+				sessionMock.On("GetSession", mock.Anything, 1, 10).Return(storedSession, nil)
+				sessionMock.On("DeleteSession", mock.Anything, 1, 10).Return(nil) // The session must be revoked
+			},
+			expectedCode: http.StatusUnauthorized, // Expecting 401 Unauthorized status due to the token reuse
+		},
+		{
+			name:         "Revoked Session",
+			refreshToken: "revoked_session_refresh_token", // Refresh token issued for a revoked session
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+				jwtMock.On("ParseRefreshToken", "revoked_session_refresh_token").Return(1, 9, nil)
+				userMock.On("GetUserById", mock.Anything, 1).Return(storedUser, nil)
+				sessionMock.On("GetSession", mock.Anything, 1, 9).Return(models.Session{}, repository.ErrSessionNotFound)
+			},
+			expectedCode: http.StatusUnauthorized, // Expecting 401 Unauthorized status since the session doesn't exist
+		},
+		{
+			name:         "Concurrent Rotation",
+			refreshToken: "valid_refresh_token",
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+				jwtMock.On("ParseRefreshToken", "valid_refresh_token").Return(1, 10, nil)
+				userMock.On("GetUserById", mock.Anything, 1).Return(storedUser, nil)
+				sessionMock.On("GetSession", mock.Anything, 1, 10).Return(storedSession, nil)
+				jwtMock.On("CreateAccessToken", 1, 10, mock.Anything).Return("newAccessToken", int64(3600), nil)
+				jwtMock.On("CreateRefreshToken", 1, 10).Return("newRefreshToken", nil)
+				sessionMock.On("RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("already rotated")) // Another request rotated the token first
+				sessionMock.On("DeleteSession", mock.Anything, 1, 10).Return(nil)                                                       // The session must be revoked
+				mockLogger.On("Error", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusUnauthorized, // Expecting 401 Unauthorized status due to the token reuse
+		},
+		{
+			name:         "Invalid Refresh Token",
+			refreshToken: "", // Invalid refresh token (empty)
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+				jwtMock.On("ParseRefreshToken", "").Return(0, 0, errors.New("invalid token"))
 				mockLogger.On("Error", mock.Anything).Return(nil)
 			},
-			refreshToken: "",                      // Invalid refresh token (empty)
 			expectedCode: http.StatusUnauthorized, // Expecting 401 Unauthorized status due to invalid refresh token
 		},
 		{
-			name:         "Error Retrieving User",
-			userID:       1,
+			name:         "Access Token Instead Of Refresh Token",
+			refreshToken: "access_token", // Access token of the session, which must neither rotate nor revoke it
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+				jwtMock.On("ParseRefreshToken", "access_token").Return(0, 0, errors.New("invalid token type"))
+				mockLogger.On("Error", mock.Anything).Return(nil)
+				// No DeleteSession expectation, the session stays active
+			},
+			expectedCode: http.StatusUnauthorized, // Expecting 401 Unauthorized status since an access token doesn't refresh the tokens
+		},
+		{
+			name:         "Error Retrieving Session",
 			refreshToken: "valid_refresh_token",
-			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
-				// mockLogger.On("Error", mock.Anything).Return(nil)
-				jwtMock.On("CreateAccessToken", mock.Anything, mock.Anything).Return("", int64(0), errors.New("token creation error"))
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+				jwtMock.On("ParseRefreshToken", "valid_refresh_token").Return(1, 10, nil)
+				userMock.On("GetUserById", mock.Anything, 1).Return(storedUser, nil)
+				sessionMock.On("GetSession", mock.Anything, 1, 10).Return(models.Session{}, errors.New("db error"))
+				mockLogger.On("Error", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status due to the database failure
+		},
+		{
+			name:         "Error Generating Tokens",
+			refreshToken: "valid_refresh_token",
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+				jwtMock.On("ParseRefreshToken", "valid_refresh_token").Return(1, 10, nil)
+				userMock.On("GetUserById", mock.Anything, 1).Return(storedUser, nil)
+				sessionMock.On("GetSession", mock.Anything, 1, 10).Return(storedSession, nil)
+				jwtMock.On("CreateAccessToken", mock.Anything, mock.Anything, mock.Anything).Return("", int64(0), errors.New("token creation error"))
+				mockLogger.On("Error", mock.Anything, mock.Anything).Return(nil)
 			},
-			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status due to retrieval failure
+			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status due to token generation failure
 		},
 	}
 
@@ -161,30 +417,20 @@ func TestTokens(t *testing.T) {
 			app := fiber.New() // Create a new Fiber application instance
 
 			mockUserService := mocks.NewUserService(t)          // Create a new mock user service
+			mockSessionService := mocks.NewSessionService(t)    // Create a new mock session service
 			mockJwtService := mocks.NewJwtService(t)            // Create a new mock JWT service
 			mockAllExchangesStorage := mocks.NewAllExchanges(t) // Create a new mock all exchanges storage
 			mockLogger := mocks.NewLogger(t)
 
 			if tc.mocksSetup != nil {
-				tc.mocksSetup(mockUserService, mockJwtService, mockLogger) // Setup mocks for the current test case
+				tc.mocksSetup(mockUserService, mockSessionService, mockJwtService, mockLogger) // Setup mocks for the current test case
 			}
 
-			userController := controller.NewUserController(mockUserService, mockJwtService, mockAllExchangesStorage, mockLogger) // Create a new UserController instance
-
-			app.Get("/api/user/auth/tokens", func(c *fiber.Ctx) error {
-				user := models.User{ID: tc.userID}    // Create a user model with the specified user ID
-				user.SetRefreshToken(tc.refreshToken) // Set the refresh token for the user
-
-				if tc.name == "Invalid Refresh Token" {
-					user.SetRefreshToken("1")
-				}
-
-				c.Locals("user", user)                                   // Store the user in context locals for retrieval in controller
-				c.Request().Header.Set("Authorization", tc.refreshToken) // Set the Authorization header with the refresh token
-				return userController.Tokens(c)                          // Call Tokens method on UserController
-			})
+			userController := controller.NewUserController(mockUserService, mockSessionService, mockJwtService, nil, mockAllExchangesStorage, config.PasswordPolicyConfig{}, mockLogger) // Create a new UserController instance
+			app.Get("/api/user/auth/tokens", userController.Tokens)
 
 			req := httptest.NewRequest("GET", "/api/user/auth/tokens", nil) // Create a new GET request
+			req.Header.Set("Authorization", tc.refreshToken)                // Set the Authorization header with the refresh token
 
 			resp, err := app.Test(req, -1) // Execute the request against the Fiber app
 			assert.NoError(t, err)         // Assert that there was no error during request execution
@@ -198,10 +444,15 @@ func TestLogin(t *testing.T) {
 	t.Parallel() // Allows this test to run in parallel with other tests
 
 	tests := []struct {
-		name         string                                                                                 // Name of the test case
-		userData     models.UserAuth                                                                        // User authentication data for login
-		mocksSetup   func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) // Function to set up mock behavior
-		expectedCode int                                                                                    // Expected HTTP status code after the request
+		name       string          // Name of the test case
+		userData   models.UserAuth // User authentication data for login
+		mocksSetup func(
+			userMock *mocks.UserService,
+			sessionMock *mocks.SessionService,
+			jwtMock *mocks.JwtService,
+			mockLogger *mocks.Logger,
+		) // Function to set up mock behavior
+		expectedCode int // Expected HTTP status code after the request
 	}{
 		{
 			name: "Successful Login",
@@ -209,23 +460,44 @@ func TestLogin(t *testing.T) {
 				Email:    "test@example.com",
 				Password: "password123",
 			},
-			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
 				user := models.User{ID: 1, Email: "test@example.com"}
-				user.SetPassword("password123")                                                                 // Assume this sets a hashed password correctly
-				userMock.On("UpdateRefreshToken", mock.Anything, mock.Anything).Return(nil)                     // Mock successful user retrieval
-				userMock.On("GetUserByEmail", mock.Anything, "test@example.com").Return(user, nil)              // Mock successful user retrieval
-				jwtMock.On("CreateAccessToken", user.ID, mock.Anything).Return("accessToken", int64(3600), nil) // Mock access token creation
-				jwtMock.On("CreateRefreshToken", user.ID, mock.Anything).Return("refreshToken", nil)            // Mock refresh token creation
+				user.SetPassword("password123")                                                                                // Assume this sets a hashed password correctly
+				userMock.On("GetUserByEmail", mock.Anything, "test@example.com").Return(user, nil)                             // Mock successful user retrieval
+				jwtMock.On("CreateAccessToken", user.ID, mock.Anything, mock.Anything).Return("accessToken", int64(3600), nil) // Mock access token creation
+				jwtMock.On("CreateRefreshToken", user.ID, mock.Anything).Return("refreshToken", nil)                           // Mock refresh token creation
+				// A new session must be started with the user agent of the client and the hash of the refresh token
+				sessionMock.On("InsertSession", mock.Anything, mock.MatchedBy(func(session models.Session) bool {
+					return session.ID > 0 && session.UserID == user.ID && session.UserAgent == "test-agent" &&
+						session.CompareRefreshToken("refreshToken") == nil
+				})).Return(nil)
 			},
 			expectedCode: http.StatusOK, // Expecting 200 OK status
 		},
+		{
+			name: "Error Storing Session",
+			userData: models.UserAuth{
+				Email:    "test@example.com",
+				Password: "password123",
+			},
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+				user := models.User{ID: 1, Email: "test@example.com"}
+				user.SetPassword("password123")
+				userMock.On("GetUserByEmail", mock.Anything, "test@example.com").Return(user, nil)
+				jwtMock.On("CreateAccessToken", user.ID, mock.Anything, mock.Anything).Return("accessToken", int64(3600), nil)
+				jwtMock.On("CreateRefreshToken", user.ID, mock.Anything).Return("refreshToken", nil)
+				sessionMock.On("InsertSession", mock.Anything, mock.Anything).Return(errors.New("db error")) // Mock error during session insertion
+				mockLogger.On("Error", mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status since the session isn't stored
+		},
 		{
 			name: "User Not Found",
 			userData: models.UserAuth{
 				Email:    "notfound@example.com",
 				Password: "password123",
 			},
-			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
 				mockLogger.On("Error", mock.Anything).Return(nil)
 				userMock.On("GetUserByEmail", mock.Anything, "notfound@example.com").Return(models.User{}, errors.New("user not found")) // Mock user not found error
 			},
@@ -237,7 +509,7 @@ func TestLogin(t *testing.T) {
 				Email:    "test@example.com",
 				Password: "wrongpassword",
 			},
-			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
 				user := models.User{ID: 1, Email: "test@example.com"}
 				user.SetPassword("password123")
 				userMock.On("GetUserByEmail", mock.Anything, "test@example.com").Return(user, nil) // Mock successful user retrieval
@@ -251,11 +523,11 @@ func TestLogin(t *testing.T) {
 				Email:    "test@example.com",
 				Password: "password123",
 			},
-			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
 				user := models.User{ID: -1, Email: "test@example.com"}
 				user.SetPassword("password123")
 				userMock.On("GetUserByEmail", mock.Anything, "test@example.com").Return(user, nil)
-				jwtMock.On("CreateAccessToken", user.ID, mock.Anything).Return("", int64(0), errors.New("token error")) // Mock token generation error
+				jwtMock.On("CreateAccessToken", user.ID, mock.Anything, mock.Anything).Return("", int64(0), errors.New("token error")) // Mock token generation error
 				mockLogger.On("Error", mock.Anything).Return(nil)
 			},
 			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status due to token generation failure
@@ -271,20 +543,22 @@ func TestLogin(t *testing.T) {
 			app := fiber.New()
 
 			mockUserService := mocks.NewUserService(t)
+			mockSessionService := mocks.NewSessionService(t)
 			mockJwtService := mocks.NewJwtService(t)
 			mockAllExchangesStorage := mocks.NewAllExchanges(t) // Create a new mock all exchanges storage
 			mockLogger := mocks.NewLogger(t)
 
 			if tc.mocksSetup != nil {
-				tc.mocksSetup(mockUserService, mockJwtService, mockLogger) // Setup mocks for the current test case
+				tc.mocksSetup(mockUserService, mockSessionService, mockJwtService, mockLogger) // Setup mocks for the current test case
 			}
 
-			userController := controller.NewUserController(mockUserService, mockJwtService, mockAllExchangesStorage, mockLogger) // Create a new UserController instance
+			userController := controller.NewUserController(mockUserService, mockSessionService, mockJwtService, nil, mockAllExchangesStorage, config.PasswordPolicyConfig{}, mockLogger) // Create a new UserController instance
 			app.Post("/api/user/auth/login", userController.Login)
 
 			reqBody := `{"email":"` + tc.userData.Email + `","password":"` + tc.userData.Password + `"}`
 			req := httptest.NewRequest("POST", "/api/user/auth/login", bytes.NewBufferString(reqBody))
 			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("User-Agent", "test-agent")
 
 			resp, err := app.Test(req)
 
@@ -301,48 +575,77 @@ func TestUpdatePasswordController(t *testing.T) {
 	t.Parallel() // Allows this test to run in parallel with other tests
 
 	tests := []struct {
-		name         string                                                                                    // Name of the test case
-		userID       int                                                                                       // User ID for updating password
-		oldPassword  []byte                                                                                    // Old password for validation
-		newPassword  []byte                                                                                    // New password to be set
-		mocksSetup   func(userMock *mocks.UserService, jwtService *mocks.JwtService, mockLogger *mocks.Logger) // Function to set up mock behavior
-		expectedCode int                                                                                       // Expected HTTP status code after the request
+		name        string // Name of the test case
+		userID      int    // User ID for updating password
+		oldPassword []byte // Old password for validation
+		newPassword []byte // New password to be set
+		mocksSetup  func(
+			userMock *mocks.UserService,
+			sessionMock *mocks.SessionService,
+			jwtMock *mocks.JwtService,
+			mockLogger *mocks.Logger,
+		) // Function to set up mock behavior
+		expectedCode int // Expected HTTP status code after the request
 	}{
 		{
 			name:        "Successful Password Update",
 			userID:      1,
 			oldPassword: []byte("oldpassword123"),
 			newPassword: []byte("newpassword123"),
-			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
-				jwtMock.On("CreateAccessToken", mock.Anything, mock.Anything).Return("", int64(3600), nil) // Mock access token creation
-				jwtMock.On("CreateRefreshToken", mock.Anything, mock.Anything).Return("", nil)             // Mock refresh token creation
-				userMock.On("UpdatePassword", mock.Anything, mock.Anything).Return(nil)                    // Mock successful password update
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+				jwtMock.On("CreateAccessToken", mock.Anything, mock.Anything, mock.Anything).Return("", int64(3600), nil) // Mock access token creation
+				jwtMock.On("CreateRefreshToken", mock.Anything, mock.Anything).Return("refreshToken", nil)                // Mock refresh token creation
+				userMock.On("UpdatePassword", mock.Anything, mock.MatchedBy(func(user models.User) bool {
+					return user.PasswordVersion != 5 && user.ComparePassword("newpassword123") == nil // The password reset tokens must be invalidated
+				})).Return(nil)
+				sessionMock.On("DeleteUserSessions", mock.Anything, 1).Return(nil) // Every device must be logged out
+				sessionMock.On("InsertSession", mock.Anything, mock.MatchedBy(func(session models.Session) bool {
+					return session.UserID == 1 && session.CompareRefreshToken("refreshToken") == nil
+				})).Return(nil) // The current device gets a new session
 			},
 			expectedCode: http.StatusOK, // Expecting 200 OK status
 		},
 		{
 			name:   "Invalid Old Password",
 			userID: 1,
-			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
 				mockLogger.On("Error", mock.Anything).Return(nil)
 			},
 			oldPassword:  []byte("wrongpassword"),
 			newPassword:  []byte("newpassword123"),
 			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status due to invalid old password
 		},
+		{
+			name:         "Weak New Password",
+			userID:       1,
+			oldPassword:  []byte("oldpassword123"),
+			newPassword:  []byte("new"),
+			mocksSetup:   nil,                   // The password isn't updated
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status due to the password policy
+		},
 		{
 			name:        "Error Updating Password",
 			userID:      1,
 			oldPassword: []byte("oldpassword123"),
 			newPassword: []byte("newpassword123"),
-			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
-				jwtMock.On("CreateAccessToken", mock.Anything, mock.Anything).Return("", int64(3600), nil)     // Mock access token creation
-				jwtMock.On("CreateRefreshToken", mock.Anything, mock.Anything).Return("", nil)                 // Mock refresh token creation
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
 				userMock.On("UpdatePassword", mock.Anything, mock.Anything).Return(errors.New("update error")) // Mock error during password update
 				mockLogger.On("Error", mock.Anything).Return(nil)
 			},
 			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status due to update failure
 		},
+		{
+			name:        "Error Revoking Sessions",
+			userID:      1,
+			oldPassword: []byte("oldpassword123"),
+			newPassword: []byte("newpassword123"),
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+				userMock.On("UpdatePassword", mock.Anything, mock.Anything).Return(nil)
+				sessionMock.On("DeleteUserSessions", mock.Anything, 1).Return(errors.New("db error")) // Mock error during session revocation
+				mockLogger.On("Error", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status since the other devices stay logged in
+		},
 	}
 
 	for _, tt := range tests {
@@ -354,18 +657,19 @@ func TestUpdatePasswordController(t *testing.T) {
 			app := fiber.New() // Create a new Fiber application instance
 
 			mockUserService := mocks.NewUserService(t) // Create a new mock User service
+			mockSessionService := mocks.NewSessionService(t)
 			mockJwtService := mocks.NewJwtService(t)
 			mockAllExchangesStorage := mocks.NewAllExchanges(t) // Create a new mock all exchanges storage
 			mockLogger := mocks.NewLogger(t)
 
 			if tc.mocksSetup != nil {
-				tc.mocksSetup(mockUserService, mockJwtService, mockLogger) // Setup mocks for the current test case
+				tc.mocksSetup(mockUserService, mockSessionService, mockJwtService, mockLogger) // Setup mocks for the current test case
 			}
 
-			userController := controller.NewUserController(mockUserService, mockJwtService, mockAllExchangesStorage, mockLogger) // Create a new UserController instance
+			userController := controller.NewUserController(mockUserService, mockSessionService, mockJwtService, nil, mockAllExchangesStorage, config.PasswordPolicyConfig{}, mockLogger) // Create a new UserController instance
 
 			app.Put("/api/user/auth/update-password", func(c *fiber.Ctx) error {
-				user := models.User{ID: tc.userID}
+				user := models.User{ID: tc.userID, PasswordVersion: 5}
 				user.SetPassword(string(tc.oldPassword))
 
 				if tc.name == "Invalid Old Password" {
@@ -422,8 +726,8 @@ func TestDeleteUserController(t *testing.T) {
 				mockLogger.On("Error", mock.Anything).Return(nil)
 				userMock.On("DeleteUser", mock.Anything, 1).Return(errors.New("deletion error"))
 			},
-			expectedCode: http.StatusInternalServerError,      // Expecting 500 Internal Server Error status
-			expectedBody: `{"result":"user deletion failed"}`, // Expected response body
+			expectedCode: http.StatusInternalServerError,                              // Expecting 500 Internal Server Error status
+			expectedBody: `{"result":"user deletion failed","code":"internal_error"}`, // Expected response body
 		},
 	}
 
@@ -445,7 +749,7 @@ func TestDeleteUserController(t *testing.T) {
 				tc.mocksSetup(mockUserService, mockAllExchangesStorage, mockExchange, mockLogger) // Setup mocks for the current test case
 			}
 
-			userController := controller.NewUserController(mockUserService, nil, mockAllExchangesStorage, mockLogger) // Create a new UserController instance
+			userController := controller.NewUserController(mockUserService, nil, nil, nil, mockAllExchangesStorage, config.PasswordPolicyConfig{}, mockLogger) // Create a new UserController instance
 			app.Delete("/api/user", func(c *fiber.Ctx) error {
 				user := models.User{ID: 1}         // Create a user model with ID 1
 				user.SetPassword("oldpassword123") // Set a dummy password (not used in this test)
@@ -468,3 +772,489 @@ func TestDeleteUserController(t *testing.T) {
 		})
 	}
 }
+
+// TestLogoutController tests that logout revokes the current session of the authenticated user.
+func TestLogoutController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name         string                                                            // Name of the test case
+		mocksSetup   func(sessionMock *mocks.SessionService, mockLogger *mocks.Logger) // Function to set up mock behavior
+		expectedCode int                                                               // Expected HTTP status code after the request
+		expectedBody string                                                            // Expected response body in JSON format
+	}{
+		{
+			name: "Successful Logout",
+			mocksSetup: func(sessionMock *mocks.SessionService, mockLogger *mocks.Logger) {
+				sessionMock.On("DeleteSession", mock.Anything, 1, 5).Return(nil) // The current session must be revoked
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"result":"logged out successfully"}`,
+		},
+		{
+			name: "Error Revoking Session",
+			mocksSetup: func(sessionMock *mocks.SessionService, mockLogger *mocks.Logger) {
+				mockLogger.On("Error", mock.Anything, mock.Anything).Return(nil)
+				sessionMock.On("DeleteSession", mock.Anything, 1, 5).Return(errors.New("delete error"))
+			},
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"result":"logout failed","code":"internal_error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run each test case in parallel
+
+			app := fiber.New() // Create a new Fiber application instance
+
+			mockSessionService := mocks.NewSessionService(t)
+			mockLogger := mocks.NewLogger(t)
+			tc.mocksSetup(mockSessionService, mockLogger)
+
+			userController := controller.NewUserController(nil, mockSessionService, nil, nil, nil, config.PasswordPolicyConfig{}, mockLogger)
+			app.Post("/api/user/auth/logout", func(c *fiber.Ctx) error {
+				c.Locals("user", models.User{ID: 1})                  // Store the authenticated user
+				c.Locals("session", models.Session{ID: 5, UserID: 1}) // Store the current session
+				return userController.Logout(c)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("POST", "/api/user/auth/logout", nil), -1)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			bodyBytes, _ := io.ReadAll(resp.Body)
+			assert.JSONEq(t, tc.expectedBody, string(bodyBytes))
+		})
+	}
+}
+
+// TestLogoutRejectsAccessToken tests that the access token used before logout is rejected with 401 after it,
+// while the access token of another session of the same user is still accepted.
+func TestLogoutRejectsAccessToken(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	var (
+		mu       sync.Mutex
+		sessions = map[int]models.Session{ // The sessions as stored in the database
+			5: {ID: 5, UserID: 1, LastSeenAt: time.Now()},
+			6: {ID: 6, UserID: 1, LastSeenAt: time.Now()},
+		}
+	)
+
+	mockUserService := mocks.NewUserService(t)
+	mockUserService.On("GetUserById", mock.Anything, 1).Return(models.User{ID: 1, PasswordVersion: 7, Verified: true}, nil)
+
+	mockSessionService := mocks.NewSessionService(t)
+	mockSessionService.On("GetSession", mock.Anything, 1, mock.Anything).Return(func(_ context.Context, _, sessionId int) (models.Session, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		session, ok := sessions[sessionId]
+		if !ok {
+			return models.Session{}, repository.ErrSessionNotFound
+		}
+
+		return session, nil
+	})
+	mockSessionService.On("TouchSession", mock.Anything, mock.Anything).Return(nil)
+	mockSessionService.On("DeleteSession", mock.Anything, 1, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		delete(sessions, args.Int(2)) // Revoke the session
+	})
+
+	userController := controller.NewUserController(mockUserService, mockSessionService, jwtService, nil, nil, config.PasswordPolicyConfig{}, mocks.NewLogger(t))
+
+	app := fiber.New()
+	app.Post("/api/user/auth/logout", middleware.IsAuthenticated(jwtService, mockUserService, mockSessionService), userController.Logout)
+
+	logout := func(sessionId int) *http.Response {
+		accessToken, _, err := jwtService.CreateAccessToken(1, sessionId, models.RoleUser)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest("POST", "/api/user/auth/logout", nil)
+		req.Header.Set("Authorization", accessToken)
+
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err)
+
+		return resp
+	}
+
+	assert.Equal(t, http.StatusOK, logout(5).StatusCode)           // The first logout is accepted
+	assert.Equal(t, http.StatusUnauthorized, logout(5).StatusCode) // The token of the revoked session is rejected
+	assert.Equal(t, http.StatusOK, logout(6).StatusCode)           // The other session of the user wasn't affected
+}
+
+func TestForgotPasswordController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name         string                                                                                                          // Name of the test case
+		reqBody      string                                                                                                          // Request body
+		mocksSetup   func(userMock *mocks.UserService, jwtMock *mocks.JwtService, emailMock *mocks.EmailService, sent chan struct{}) // Function to set up mock behavior
+		expectEmail  bool                                                                                                            // Whether an email is expected to be sent
+		expectedCode int                                                                                                             // Expected HTTP status code after the request
+	}{
+		{
+			name:    "Registered Email",
+			reqBody: `{"email":"test@example.com"}`,
+			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, emailMock *mocks.EmailService, sent chan struct{}) {
+				user := models.User{ID: 1, Email: "test@example.com", PasswordVersion: 77}
+				userMock.On("GetUserByEmail", mock.Anything, "test@example.com").Return(user, nil)
+				jwtMock.On("CreateResetPasswordToken", 1, 77).Return("resetToken", nil)
+				emailMock.On("SendResetPasswordToken", "test@example.com", "resetToken").
+					Return(nil).
+					Run(func(args mock.Arguments) { close(sent) }) // Signal that the email was sent
+			},
+			expectEmail:  true,
+			expectedCode: http.StatusOK, // Expecting 200 OK status
+		},
+		{
+			name:    "Unknown Email",
+			reqBody: `{"email":"notfound@example.com"}`,
+			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, emailMock *mocks.EmailService, sent chan struct{}) {
+				userMock.On("GetUserByEmail", mock.Anything, "notfound@example.com").Return(models.User{}, errors.New("user not found"))
+			},
+			expectedCode: http.StatusOK, // Expecting 200 OK status to not reveal registered emails
+		},
+		{
+			name:         "Invalid Body",
+			reqBody:      `{"email":`,
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := fiber.New()
+
+			mockUserService := mocks.NewUserService(t)
+			mockJwtService := mocks.NewJwtService(t)
+			mockEmailService := mocks.NewEmailService(t)
+			mockAllExchangesStorage := mocks.NewAllExchanges(t)
+			mockLogger := mocks.NewLogger(t)
+			mockLogger.On("Error", mock.Anything).Return(nil).Maybe()
+
+			sent := make(chan struct{})
+			if tc.mocksSetup != nil {
+				tc.mocksSetup(mockUserService, mockJwtService, mockEmailService, sent) // Setup mocks for the current test case
+			}
+
+			userController := controller.NewUserController(mockUserService, nil, mockJwtService, mockEmailService, mockAllExchangesStorage, config.PasswordPolicyConfig{}, mockLogger)
+			app.Post("/api/user/auth/forgot-password", userController.ForgotPassword)
+
+			req := httptest.NewRequest("POST", "/api/user/auth/forgot-password", bytes.NewBufferString(tc.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			// The email is sent in the background, wait for it before the mocks are asserted
+			if tc.expectEmail {
+				select {
+				case <-sent:
+				case <-time.After(2 * time.Second):
+					t.Fatal("the password reset email wasn't sent")
+				}
+			}
+		})
+	}
+}
+
+// TestChangeEmailController tests that the new email is stored as pending and the confirmation link is sent to it.
+func TestChangeEmailController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name         string                                                                                                          // Name of the test case
+		reqBody      string                                                                                                          // Request body
+		mocksSetup   func(userMock *mocks.UserService, jwtMock *mocks.JwtService, emailMock *mocks.EmailService, sent chan struct{}) // Function to set up mock behavior
+		expectEmail  bool                                                                                                            // Whether an email is expected to be sent
+		expectedCode int                                                                                                             // Expected HTTP status code after the request
+	}{
+		{
+			name:    "Valid Email",
+			reqBody: `{"email":"new@example.com"}`,
+			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, emailMock *mocks.EmailService, sent chan struct{}) {
+				userMock.On("SetPendingEmail", mock.Anything, 1, "new@example.com").Return(nil)
+				jwtMock.On("CreateChangeEmailToken", 1, "new@example.com").Return("changeToken", nil)
+				emailMock.On("SendChangeEmailToken", "new@example.com", "changeToken").
+					Return(nil).
+					Run(func(args mock.Arguments) { close(sent) }) // The link is sent to the new email
+			},
+			expectEmail:  true,
+			expectedCode: http.StatusOK, // Expecting 200 OK status
+		},
+		{
+			name:    "Duplicate Email",
+			reqBody: `{"email":"taken@example.com"}`,
+			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, emailMock *mocks.EmailService, sent chan struct{}) {
+				userMock.On("SetPendingEmail", mock.Anything, 1, "taken@example.com").Return(repository.ErrEmailAlreadyExists) // Nothing is sent
+			},
+			expectedCode: http.StatusConflict, // Expecting 409 Conflict status
+		},
+		{
+			name:         "Invalid Email Format",
+			reqBody:      `{"email":"not-an-email"}`,
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status
+		},
+		{
+			name:         "Current Email",
+			reqBody:      `{"email":"test@example.com"}`,
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status as there is nothing to change
+		},
+		{
+			name:    "Error Storing Email",
+			reqBody: `{"email":"new@example.com"}`,
+			mocksSetup: func(userMock *mocks.UserService, jwtMock *mocks.JwtService, emailMock *mocks.EmailService, sent chan struct{}) {
+				userMock.On("SetPendingEmail", mock.Anything, 1, "new@example.com").Return(errors.New("db error"))
+			},
+			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status due to the database failure
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := fiber.New()
+
+			mockUserService := mocks.NewUserService(t)
+			mockJwtService := mocks.NewJwtService(t)
+			mockEmailService := mocks.NewEmailService(t)
+			mockAllExchangesStorage := mocks.NewAllExchanges(t)
+			mockLogger := mocks.NewLogger(t)
+			mockLogger.On("Error", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			sent := make(chan struct{})
+			if tc.mocksSetup != nil {
+				tc.mocksSetup(mockUserService, mockJwtService, mockEmailService, sent) // Setup mocks for the current test case
+			}
+
+			userController := controller.NewUserController(mockUserService, nil, mockJwtService, mockEmailService, mockAllExchangesStorage, config.PasswordPolicyConfig{}, mockLogger)
+			app.Put("/api/user/auth/email", func(c *fiber.Ctx) error {
+				c.Locals("user", models.User{ID: 1, Email: "test@example.com"}) // Store the authenticated user
+				return userController.ChangeEmail(c)
+			})
+
+			req := httptest.NewRequest("PUT", "/api/user/auth/email", bytes.NewBufferString(tc.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			// The email is sent in the background, wait for it before the mocks are asserted
+			if tc.expectEmail {
+				select {
+				case <-sent:
+				case <-time.After(2 * time.Second):
+					t.Fatal("the email change link wasn't sent")
+				}
+			}
+		})
+	}
+}
+
+// TestConfirmEmailController tests that the email of the user is swapped for the pending one by the email change token.
+func TestConfirmEmailController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	validToken, err := jwtService.CreateChangeEmailToken(1, "new@example.com")
+	assert.NoError(t, err)
+
+	verifyToken, err := jwtService.CreateVerifyEmailToken(1) // A token of another type
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name         string                            // Name of the test case
+		token        string                            // Email change token passed in the query
+		mocksSetup   func(userMock *mocks.UserService) // Function to set up mock behavior
+		expectedCode int                               // Expected HTTP status code after the request
+	}{
+		{
+			name:  "Successful Swap",
+			token: validToken,
+			mocksSetup: func(userMock *mocks.UserService) {
+				userMock.On("ConfirmEmail", mock.Anything, 1, "new@example.com").Return(nil) // The email from the token is confirmed
+			},
+			expectedCode: http.StatusOK, // Expecting 200 OK status
+		},
+		{
+			name:  "Superseded Or Already Confirmed",
+			token: validToken,
+			mocksSetup: func(userMock *mocks.UserService) {
+				userMock.On("ConfirmEmail", mock.Anything, 1, "new@example.com").Return(repository.ErrPendingEmailNotFound)
+			},
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status
+		},
+		{
+			name:  "Email Registered Meanwhile",
+			token: validToken,
+			mocksSetup: func(userMock *mocks.UserService) {
+				userMock.On("ConfirmEmail", mock.Anything, 1, "new@example.com").Return(repository.ErrEmailAlreadyExists)
+			},
+			expectedCode: http.StatusConflict, // Expecting 409 Conflict status
+		},
+		{
+			name:         "Token Of Another Type",
+			token:        verifyToken,
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status, nothing is confirmed
+		},
+		{
+			name:  "Error Confirming Email",
+			token: validToken,
+			mocksSetup: func(userMock *mocks.UserService) {
+				userMock.On("ConfirmEmail", mock.Anything, 1, "new@example.com").Return(errors.New("db error"))
+			},
+			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status due to the database failure
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := fiber.New()
+
+			mockUserService := mocks.NewUserService(t)
+			mockAllExchangesStorage := mocks.NewAllExchanges(t)
+			mockLogger := mocks.NewLogger(t)
+			mockLogger.On("Error", mock.Anything).Return(nil).Maybe()
+			mockLogger.On("Error", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			if tc.mocksSetup != nil {
+				tc.mocksSetup(mockUserService) // Setup mocks for the current test case
+			}
+
+			userController := controller.NewUserController(mockUserService, nil, jwtService, nil, mockAllExchangesStorage, config.PasswordPolicyConfig{}, mockLogger)
+			app.Get("/api/user/auth/email/confirm", userController.ConfirmEmail)
+
+			req := httptest.NewRequest("GET", "/api/user/auth/email/confirm?token="+tc.token, nil)
+
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestResetPasswordController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name       string               // Name of the test case
+		resetData  models.PasswordReset // Password reset request data
+		mocksSetup func(
+			userMock *mocks.UserService,
+			sessionMock *mocks.SessionService,
+			jwtMock *mocks.JwtService,
+			mockLogger *mocks.Logger,
+		) // Function to set up mock behavior
+		expectedCode int // Expected HTTP status code after the request
+	}{
+		{
+			name:      "Successful Password Reset",
+			resetData: models.PasswordReset{Token: "resetToken", NewPassword: "newpassword123", NewPasswordRepeat: "newpassword123"},
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+				jwtMock.On("ParseResetPasswordToken", "resetToken").Return(1, 77, nil)
+				userMock.On("GetUserById", mock.Anything, 1).Return(models.User{ID: 1, PasswordVersion: 77}, nil)
+				jwtMock.On("CreateAccessToken", 1, mock.Anything, mock.Anything).Return("accessToken", int64(3600), nil)
+				jwtMock.On("CreateRefreshToken", 1, mock.Anything).Return("refreshToken", nil)
+				userMock.On("UpdatePassword", mock.Anything, mock.MatchedBy(func(user models.User) bool {
+					return user.PasswordVersion != 77 && user.ComparePassword("newpassword123") == nil // The new password must be stored and the token invalidated
+				})).Return(nil)
+				sessionMock.On("DeleteUserSessions", mock.Anything, 1).Return(nil) // Every device must be logged out
+				sessionMock.On("InsertSession", mock.Anything, mock.MatchedBy(func(session models.Session) bool {
+					return session.UserID == 1 && session.CompareRefreshToken("refreshToken") == nil
+				})).Return(nil)
+			},
+			expectedCode: http.StatusOK, // Expecting 200 OK status
+		},
+		{
+			name:      "Passwords Do Not Match",
+			resetData: models.PasswordReset{Token: "resetToken", NewPassword: "newpassword123", NewPasswordRepeat: "other"},
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+			},
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status
+		},
+		{
+			name:      "Weak New Password",
+			resetData: models.PasswordReset{Token: "resetToken", NewPassword: "new", NewPasswordRepeat: "new"},
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+			},
+			expectedCode: http.StatusBadRequest, // The token isn't used up by a password breaking the policy
+		},
+		{
+			name:      "Invalid Token",
+			resetData: models.PasswordReset{Token: "invalid", NewPassword: "newpassword123", NewPasswordRepeat: "newpassword123"},
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+				jwtMock.On("ParseResetPasswordToken", "invalid").Return(0, 0, errors.New("invalid token"))
+				mockLogger.On("Error", mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status
+		},
+		{
+			name:      "Outdated Token",
+			resetData: models.PasswordReset{Token: "resetToken", NewPassword: "newpassword123", NewPasswordRepeat: "newpassword123"},
+			mocksSetup: func(userMock *mocks.UserService, sessionMock *mocks.SessionService, jwtMock *mocks.JwtService, mockLogger *mocks.Logger) {
+				jwtMock.On("ParseResetPasswordToken", "resetToken").Return(1, 77, nil)
+				userMock.On("GetUserById", mock.Anything, 1).Return(models.User{ID: 1, PasswordVersion: 78}, nil) // The password was changed after the token was issued
+				mockLogger.On("Error", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := fiber.New()
+
+			mockUserService := mocks.NewUserService(t)
+			mockSessionService := mocks.NewSessionService(t)
+			mockJwtService := mocks.NewJwtService(t)
+			mockAllExchangesStorage := mocks.NewAllExchanges(t)
+			mockLogger := mocks.NewLogger(t)
+
+			if tc.mocksSetup != nil {
+				tc.mocksSetup(mockUserService, mockSessionService, mockJwtService, mockLogger) // Setup mocks for the current test case
+			}
+
+			userController := controller.NewUserController(mockUserService, mockSessionService, mockJwtService, nil, mockAllExchangesStorage, config.PasswordPolicyConfig{}, mockLogger)
+			app.Post("/api/user/auth/reset-password", userController.ResetPassword)
+
+			reqBody, err := json.Marshal(tc.resetData)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest("POST", "/api/user/auth/reset-password", bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req, -1) // Disable the timeout, hashing the new password may be slow
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+		})
+	}
+}
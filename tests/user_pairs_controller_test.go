@@ -2,18 +2,28 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/goccy/go-json"
 
 	"cvs/api/server/controller"
 	"cvs/internal/mocks"
 	"cvs/internal/models"
+	"cvs/internal/repository"
+	"cvs/internal/service"
 	"cvs/internal/service/exchange"
 
+	fasthttpws "github.com/fasthttp/websocket"
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -33,7 +43,9 @@ func TestAddPairController(t *testing.T) {
 			mockExchange *mocks.Exchange,
 			mockLogger *mocks.Logger,
 		) // Function to set up mock behavior
-		expectedCode int // Expected HTTP status code after the request
+		expectedCode       int    // Expected HTTP status code after the request
+		expectedRetryAfter string // Expected value of the Retry-After header
+		expectedBody       string // Expected response body in JSON format, not checked when empty
 	}{
 		{
 			name:   "Successful Addition",
@@ -41,7 +53,31 @@ func TestAddPairController(t *testing.T) {
 			pairData: models.UserPairs{
 				UserID:   1,
 				Pair:     "BTC-ETH",
-				Exchange: "Binance", // Assuming Exchange field is part of UserPairs
+				Exchange: "binance_spot", // Assuming Exchange field is part of UserPairs
+			},
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+				userPairsMock.On("Add", mock.Anything, mock.Anything).Return(nil)     // Mock successful addition
+				userMock.On("SetUserIdIntoMemory", mock.Anything).Return(nil)         // Mock successful addition
+				allExchangesMock.On("Get", "binance_spot").Return(mockExchange, true) // Mock getting the exchange
+				mockExchange.On("PairsLoaded").Return(true)                           // The pairs of the exchange are loaded
+				mockExchange.On("HasPair", "BTC/ETH").Return(true)                    // The exchange lists the pair
+				mockExchange.On("AddPairToSubscribedPairs", "BTC/ETH").Return()       // Mock adding pair to subscribed pairs
+			},
+			expectedCode: http.StatusOK, // Expecting 200 OK status
+		},
+		{
+			name:   "Normalized Pair And Exchange",
+			userID: 1,
+			pairData: models.UserPairs{
+				UserID:   1,
+				Pair:     " btc/usdt ",
+				Exchange: "Binance_Spot",
 			},
 			mocksSetup: func(
 				userPairsMock *mocks.UserPairsService,
@@ -50,20 +86,152 @@ func TestAddPairController(t *testing.T) {
 				mockExchange *mocks.Exchange,
 				mockLogger *mocks.Logger,
 			) {
-				userPairsMock.On("Add", mock.Anything, mock.Anything).Return(nil) // Mock successful addition
-				userMock.On("SetUserIdIntoMemory", mock.Anything).Return(nil)     // Mock successful addition
-				allExchangesMock.On("Get", "Binance").Return(mockExchange)        // Mock getting the exchange
-				mockExchange.On("AddPairToSubscribedPairs", "BTC-ETH").Return()   // Mock adding pair to subscribed pairs
+				userPairsMock.On("Add", mock.Anything, mock.MatchedBy(func(pairData models.UserPairs) bool {
+					return pairData.Pair == "BTC/USDT" && pairData.Exchange == "binance_spot"
+				})).Return(nil) // The normalized pair is stored
+				userMock.On("SetUserIdIntoMemory", mock.Anything).Return(nil)
+				allExchangesMock.On("Get", "binance_spot").Return(mockExchange, true)
+				mockExchange.On("PairsLoaded").Return(true)
+				mockExchange.On("HasPair", "BTC/USDT").Return(true) // The normalized pair is looked up
+				mockExchange.On("AddPairToSubscribedPairs", "BTC/USDT").Return()
 			},
 			expectedCode: http.StatusOK, // Expecting 200 OK status
 		},
+		{
+			name:   "Invalid Pair Format",
+			userID: 1,
+			pairData: models.UserPairs{
+				UserID:   1,
+				Pair:     "BTC",
+				Exchange: "binance_spot",
+			},
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+				// The assets can't be told apart, so the exchange isn't even looked up
+			},
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status
+		},
+		{
+			name:   "Nonexistent Exchange",
+			userID: 1,
+			pairData: models.UserPairs{
+				UserID:   1,
+				Pair:     "BTC-ETH",
+				Exchange: "unknown_spot",
+			},
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+				allExchangesMock.On("Get", "unknown_spot").Return(nil, false) // The exchange isn't registered, so nothing is stored or subscribed
+			},
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status
+		},
+		{
+			name:   "Pair Not Listed",
+			userID: 1,
+			pairData: models.UserPairs{
+				UserID:   1,
+				Pair:     "FOO/BAR",
+				Exchange: "binance_spot",
+			},
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+				allExchangesMock.On("Get", "binance_spot").Return(mockExchange, true)
+				mockExchange.On("PairsLoaded").Return(true)
+				mockExchange.On("HasPair", "FOO/BAR").Return(false) // The exchange doesn't offer the pair, so nothing is stored or subscribed
+			},
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status
+		},
+		{
+			name:   "Pairs Not Loaded Yet",
+			userID: 1,
+			pairData: models.UserPairs{
+				UserID:   1,
+				Pair:     "BTC/USDT",
+				Exchange: "binance_spot",
+			},
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+				allExchangesMock.On("Get", "binance_spot").Return(mockExchange, true)
+				mockExchange.On("PairsLoaded").Return(false) // The exchange has just started, so the pair can't be checked yet
+			},
+			expectedCode:       http.StatusServiceUnavailable, // Expecting 503 Service Unavailable status, the request can be retried
+			expectedRetryAfter: "5",
+		},
+		{
+			name:   "Maximum Pairs Reached",
+			userID: 1,
+			pairData: models.UserPairs{
+				UserID:   1,
+				Pair:     "BTC-ETH",
+				Exchange: "binance_spot",
+			},
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+				allExchangesMock.On("Get", "binance_spot").Return(mockExchange, true)
+				mockExchange.On("PairsLoaded").Return(true)
+				mockExchange.On("HasPair", "BTC/ETH").Return(true)
+				userPairsMock.On("Add", mock.Anything, mock.Anything).
+					Return(fmt.Errorf("%w: %d", service.ErrMaxPairsPerUserReached, 100)) // The pair isn't stored or subscribed
+			},
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status, the limit isn't a failure of the service
+			expectedBody: `{"result":"maximum number of pairs per user reached: 100","code":"pairs_limit_reached"}`,
+		},
+		{
+			name:   "Validation Error",
+			userID: 1,
+			pairData: models.UserPairs{
+				UserID:   1,
+				Pair:     "BTC-ETH",
+				Exchange: "binance_spot",
+			},
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+				allExchangesMock.On("Get", "binance_spot").Return(mockExchange, true)
+				mockExchange.On("PairsLoaded").Return(true)
+				mockExchange.On("HasPair", "BTC/ETH").Return(true)
+				userPairsMock.On("Add", mock.Anything, mock.Anything).
+					Return(service.NewValidationError("exact value must be above zero")) // The validation error isn't logged
+			},
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status, the data is invalid
+			expectedBody: `{"result":"exact value must be above zero","code":"validation_failed"}`,
+		},
 		{
 			name:   "Error Adding Pair - Service Error",
 			userID: 1,
 			pairData: models.UserPairs{
 				UserID:   1,
 				Pair:     "BTC-ETH",
-				Exchange: "Binance", // Assuming Exchange field is part of UserPairs
+				Exchange: "binance_spot", // Assuming Exchange field is part of UserPairs
 			},
 			mocksSetup: func(
 				userPairsMock *mocks.UserPairsService,
@@ -72,10 +240,15 @@ func TestAddPairController(t *testing.T) {
 				mockExchange *mocks.Exchange,
 				mockLogger *mocks.Logger,
 			) {
-				userPairsMock.On("Add", mock.Anything, mock.Anything).Return(errors.New("service error")) // Mock error during addition
-				mockLogger.On("Error", mock.Anything).Return(nil)
+				allExchangesMock.On("Get", "binance_spot").Return(mockExchange, true) // Mock getting the exchange
+				mockExchange.On("PairsLoaded").Return(true)                           // The pairs of the exchange are loaded
+				mockExchange.On("HasPair", "BTC/ETH").Return(true)                    // The exchange lists the pair
+				userPairsMock.On("Add", mock.Anything, mock.Anything).
+					Return(fmt.Errorf("internal.repository.Add: %w", errors.New(`pq: relation "user_pairs" does not exist`))) // Mock error during addition
+				mockLogger.On("Error", mock.Anything).Return(nil) // The details are only logged
 			},
-			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status due to service error
+			expectedCode: http.StatusInternalServerError,                               // Expecting 500 Internal Server Error status due to service error
+			expectedBody: `{"result":"internal server error","code":"internal_error"}`, // Neither the operation path nor the database details are exposed
 		},
 	}
 
@@ -121,7 +294,197 @@ func TestAddPairController(t *testing.T) {
 			resp, err := app.Test(req, -1) // Execute the request against the Fiber app
 			assert.NoError(t, err)         // Assert that there was no error during request execution
 
-			assert.Equal(t, tc.expectedCode, resp.StatusCode) // Assert that the response status code matches expected
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)                               // Assert that the response status code matches expected
+			assert.Equal(t, tc.expectedRetryAfter, resp.Header.Get(fiber.HeaderRetryAfter)) // Only the pairs which can't be checked yet are retried
+
+			if tc.expectedBody != "" {
+				bodyBytes, _ := io.ReadAll(resp.Body)
+				assert.JSONEq(t, tc.expectedBody, string(bodyBytes)) // Assert that the JSON response matches expected body
+			}
+		})
+	}
+}
+
+func TestBulkAddPairController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	pairs := []models.UserPairs{
+		{Pair: "btc/usdt", Exchange: "binance_spot", ExactValue: 10}, // Valid pair
+		{Pair: "ETH/USDT", Exchange: "binance_spot", ExactValue: 0},  // Rejected by the validation of the service
+		{Pair: "SOL/USDT", Exchange: "unknown_spot", ExactValue: 10}, // Pair of an unsupported exchange
+		{Pair: "XRP/USDT", Exchange: " Bybit_Spot ", ExactValue: 10}, // Valid pair of another exchange
+		{Pair: "FOO/BAR", Exchange: "binance_spot", ExactValue: 10},  // Pair the exchange doesn't list
+	}
+
+	// Matches the pairs passed to the service, which are the normalized pairs of the supported exchanges
+	supportedPairs := mock.MatchedBy(func(toAdd []models.UserPairs) bool {
+		return len(toAdd) == 3 &&
+			toAdd[0].Pair == "BTC/USDT" && toAdd[0].UserID == 1 &&
+			toAdd[1].Pair == "ETH/USDT" &&
+			toAdd[2].Pair == "XRP/USDT" && toAdd[2].Exchange == "bybit_spot"
+	})
+
+	tests := []struct {
+		name       string             // Name of the test case
+		pairs      []models.UserPairs // Input data for adding the user pairs
+		mocksSetup func(
+			userPairsMock *mocks.UserPairsService,
+			userMock *mocks.UserService,
+			allExchangesMock *mocks.AllExchanges,
+			mockExchange *mocks.Exchange,
+			mockLogger *mocks.Logger,
+		) // Function to set up mock behavior
+		expectedCode    int                          // Expected HTTP status code after the request
+		expectedResults []models.UserPairsBulkResult // Expected outcome of every pair
+	}{
+		{
+			name:  "Partial Failure",
+			pairs: pairs,
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+				allExchangesMock.On("Get", "binance_spot").Return(mockExchange, true)
+				allExchangesMock.On("Get", "bybit_spot").Return(mockExchange, true)
+				allExchangesMock.On("Get", "unknown_spot").Return(nil, false)
+				mockExchange.On("PairsLoaded").Return(true)
+				mockExchange.On("HasPair", "FOO/BAR").Return(false) // The unlisted pair isn't passed to the service
+				mockExchange.On("HasPair", mock.Anything).Return(true)
+				userPairsMock.On("BulkAdd", mock.Anything, supportedPairs).
+					Return([]error{nil, service.NewValidationError("exact value must be above zero"), nil}, nil)
+				mockExchange.On("AddPairToSubscribedPairs", "BTC/USDT").Return().Once() // Only the added pairs are subscribed
+				mockExchange.On("AddPairToSubscribedPairs", "XRP/USDT").Return().Once()
+				userMock.On("SetUserIdIntoMemory", 1).Return(nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedResults: []models.UserPairsBulkResult{
+				{Exchange: "binance_spot", Pair: "BTC/USDT", Added: true},
+				{Exchange: "binance_spot", Pair: "ETH/USDT", Error: "exact value must be above zero", Code: models.CodeValidationFailed},
+				{Exchange: "unknown_spot", Pair: "SOL/USDT", Error: "exchange not found", Code: models.CodeNotFound},
+				{Exchange: "bybit_spot", Pair: "XRP/USDT", Added: true},
+				{Exchange: "binance_spot", Pair: "FOO/BAR", Error: "pair is not listed on the exchange", Code: models.CodePairNotListed},
+			},
+		},
+		{
+			name:  "Pairs Not Loaded Yet",
+			pairs: pairs[:1],
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+				allExchangesMock.On("Get", "binance_spot").Return(mockExchange, true)
+				mockExchange.On("PairsLoaded").Return(false) // Nothing is passed to the service
+			},
+			expectedCode: http.StatusOK,
+			expectedResults: []models.UserPairsBulkResult{
+				{Exchange: "binance_spot", Pair: "BTC/USDT", Error: "pairs of the exchange are not loaded yet, please retry later", Code: models.CodeUnavailable},
+			},
+		},
+		{
+			name:  "Only Unsupported Exchanges",
+			pairs: pairs[2:3],
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+				allExchangesMock.On("Get", "unknown_spot").Return(nil, false) // Nothing is passed to the service
+			},
+			expectedCode: http.StatusOK,
+			expectedResults: []models.UserPairsBulkResult{
+				{Exchange: "unknown_spot", Pair: "SOL/USDT", Error: "exchange not found", Code: models.CodeNotFound},
+			},
+		},
+		{
+			name:  "Transaction Error",
+			pairs: pairs,
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+				allExchangesMock.On("Get", mock.Anything).Return(mockExchange, true)
+				mockExchange.On("PairsLoaded").Return(true)
+				mockExchange.On("HasPair", mock.Anything).Return(true)
+				userPairsMock.On("BulkAdd", mock.Anything, mock.Anything).Return(nil, errors.New("db error")) // Nothing is subscribed
+				mockLogger.On("Error", mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:  "Empty Request",
+			pairs: []models.UserPairs{},
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable for use in goroutine
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run each test case in parallel
+
+			app := fiber.New() // Create a new Fiber application instance
+
+			mockUserPairsService := mocks.NewUserPairsService(t)
+			mockUserService := mocks.NewUserService(t)
+			mockAllExchangesStorage := mocks.NewAllExchanges(t)
+			mockExchange := mocks.NewExchange(t)
+			mockLogger := mocks.NewLogger(t)
+
+			tc.mocksSetup(
+				mockUserPairsService,
+				mockUserService,
+				mockAllExchangesStorage,
+				mockExchange,
+				mockLogger,
+			) // Setup mocks for the current test case
+
+			userPairsController := controller.NewUserPairsController(
+				mockUserPairsService,
+				mockUserService,
+				nil,
+				mockAllExchangesStorage,
+				mockLogger,
+			)
+
+			app.Post("/api/user/pair/bulk", func(c *fiber.Ctx) error {
+				c.Locals("user", models.User{ID: 1}) // Add user to context locals
+				return userPairsController.BulkAdd(c)
+			})
+
+			reqBody, _ := json.Marshal(tc.pairs)
+			req := httptest.NewRequest("POST", "/api/user/pair/bulk", bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req, -1) // Execute the request against the Fiber app
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			if tc.expectedResults != nil {
+				var results []models.UserPairsBulkResult
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&results))
+				assert.Equal(t, tc.expectedResults, results)
+			}
 		})
 	}
 }
@@ -211,6 +574,7 @@ func TestGetAllUserPairsController(t *testing.T) {
 	tests := []struct {
 		name         string                                                           // Name of the test case
 		userID       int                                                              // User ID for which to retrieve pairs
+		query        string                                                           // Query string of the request
 		mocksSetup   func(userMock *mocks.UserPairsService, mockLogger *mocks.Logger) // Function to set up mock behavior
 		expectedCode int                                                              // Expected HTTP status code after the request
 	}{
@@ -234,6 +598,82 @@ func TestGetAllUserPairsController(t *testing.T) {
 			},
 			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status due to retrieval failure
 		},
+		{
+			name:   "Successful Retrieval Of Page",
+			userID: 1,
+			query:  "?limit=2&offset=4",
+			mocksSetup: func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {
+				userPairsMock.On("GetUserPairsPage", mock.Anything, 1, models.UserPairsPagination{Limit: 2, Offset: 4}).Return(models.UserPairsPage{
+					Pairs: []models.UserPairs{{UserID: 1, Exchange: "okx_spot", Pair: "ETH/USDT"}},
+					Total: 5, Limit: 2, Offset: 4,
+				}, nil) // The page is retrieved instead of all pairs
+			},
+			expectedCode: http.StatusOK, // Expecting 200 OK status
+		},
+		{
+			name:   "Successful Retrieval Of Page By Offset",
+			userID: 1,
+			query:  "?offset=50",
+			mocksSetup: func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {
+				userPairsMock.On("GetUserPairsPage", mock.Anything, 1, models.UserPairsPagination{Offset: 50}).Return(models.UserPairsPage{
+					Pairs: []models.UserPairs{}, Total: 5, Limit: 50, Offset: 50,
+				}, nil) // The page past the last pair is empty
+			},
+			expectedCode: http.StatusOK, // Expecting 200 OK status
+		},
+		{
+			name:         "Invalid Limit",
+			userID:       1,
+			query:        "?limit=abc",
+			mocksSetup:   func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {}, // The service must not be called
+			expectedCode: http.StatusBadRequest,                                                    // Expecting 400 Bad Request status due to the invalid limit
+		},
+		{
+			name:         "Page Of Exchange",
+			userID:       1,
+			query:        "?exchange=binance_spot&limit=10",
+			mocksSetup:   func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {}, // The service must not be called
+			expectedCode: http.StatusBadRequest,                                                    // Expecting 400 Bad Request status, the pages are taken from all pairs
+		},
+		{
+			name:   "Page Out Of Range",
+			userID: 1,
+			query:  "?limit=1000",
+			mocksSetup: func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {
+				userPairsMock.On("GetUserPairsPage", mock.Anything, 1, models.UserPairsPagination{Limit: 1000}).Return(
+					models.UserPairsPage{}, service.NewValidationError("limit must be between 0 and 500"),
+				) // The service rejects the limit
+			},
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status due to the validation failure
+		},
+		{
+			name:   "Successful Retrieval By Exchange",
+			userID: 1,
+			query:  "?exchange=binance_spot",
+			mocksSetup: func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {
+				userPairsMock.On("GetUserPairsByExchange", mock.Anything, 1, "binance_spot").Return([]models.UserPairs{
+					{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT"},
+				}, nil) // Only the pairs of the requested exchange are retrieved
+			},
+			expectedCode: http.StatusOK, // Expecting 200 OK status
+		},
+		{
+			name:         "Invalid Exchange",
+			userID:       1,
+			query:        "?exchange=unknown_spot",
+			mocksSetup:   func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {}, // The service must not be called
+			expectedCode: http.StatusBadRequest,                                                    // Expecting 400 Bad Request status due to the invalid exchange name
+		},
+		{
+			name:   "Error Retrieving User Pairs By Exchange",
+			userID: 1,
+			query:  "?exchange=okx_spot",
+			mocksSetup: func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {
+				userPairsMock.On("GetUserPairsByExchange", mock.Anything, 1, "okx_spot").Return(nil, errors.New("retrieve error")) // Mock error during retrieval
+				mockLogger.On("Error", mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status due to retrieval failure
+		},
 	}
 
 	for _, tt := range tests {
@@ -264,7 +704,7 @@ func TestGetAllUserPairsController(t *testing.T) {
 				return userPairsController.GetAllUserPairs(c) // Call GetAllUserPairs method on UserPairsController
 			})
 
-			req := httptest.NewRequest("GET", "/api/user/pairs", nil) // Create a new GET request
+			req := httptest.NewRequest("GET", "/api/user/pairs"+tc.query, nil) // Create a new GET request
 
 			resp, err := app.Test(req, -1) // Execute the request against the Fiber app
 			assert.NoError(t, err)         // Assert that there was no error during request execution
@@ -274,16 +714,412 @@ func TestGetAllUserPairsController(t *testing.T) {
 	}
 }
 
-func TestDeletePairController(t *testing.T) {
+// TestGetUserPairController tests the retrieval of a single pair of the user by the exchange and the pair.
+func TestGetUserPairController(t *testing.T) {
 	t.Parallel() // Allows this test to run in parallel with other tests
 
+	userPair := models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10, Tolerance: 5, Side: models.SideBids}
+
 	tests := []struct {
-		name       string // Name of the test case
-		userID     int    // User ID for deleting the pair
-		pairQuery  string // Query parameter for deleting the user pair
-		mocksSetup func(
-			userPairsMock *mocks.UserPairsService,
-			userMock *mocks.UserService,
+		name         string                                                                // Name of the test case
+		query        string                                                                // Query string of the request
+		mocksSetup   func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) // Function to set up mock behavior
+		expectedCode int                                                                   // Expected HTTP status code after the request
+		expectedBody string                                                                // Expected body of the response
+	}{
+		{
+			name:  "Pair Found",
+			query: "?exchange=binance_spot&pair=BTCUSDT", // The pair is normalized to the form it is stored in
+			mocksSetup: func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {
+				userPairsMock.On("GetUserPair", mock.Anything, 1, "binance_spot", "BTC/USDT").Return(userPair, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"exchange":"binance_spot","pair":"BTC/USDT","exact_value":10,"tolerance":5,"side":"bids"}`,
+		},
+		{
+			name:  "Pair Not Found",
+			query: "?exchange=okx_spot&pair=BTC/USDT",
+			mocksSetup: func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {
+				userPairsMock.On("GetUserPair", mock.Anything, 1, "okx_spot", "BTC/USDT").Return(models.UserPairs{}, repository.ErrUserPairNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"result":"pair not found","code":"not_found"}`,
+		},
+		{
+			name:  "Invalid Exchange",
+			query: "?exchange=unknown_spot&pair=BTC/USDT",
+			mocksSetup: func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {
+				userPairsMock.On("GetUserPair", mock.Anything, 1, "unknown_spot", "BTC/USDT").Return(models.UserPairs{}, service.NewValidationError("invalid exchange name format"))
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"result":"invalid exchange name format","code":"validation_failed"}`,
+		},
+		{
+			name:         "Missing Pair",
+			query:        "?exchange=binance_spot",
+			mocksSetup:   func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {}, // The service must not be called
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:  "Error Retrieving User Pair",
+			query: "?exchange=binance_spot&pair=BTC/USDT",
+			mocksSetup: func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {
+				userPairsMock.On("GetUserPair", mock.Anything, 1, "binance_spot", "BTC/USDT").Return(models.UserPairs{}, errors.New("retrieve error"))
+				mockLogger.On("Error", mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run each test case in parallel
+
+			app := fiber.New() // Create a new Fiber application instance
+
+			mockUserPairsService := mocks.NewUserPairsService(t) // Create a new mock UserPairs service
+			mockLogger := mocks.NewLogger(t)
+
+			tc.mocksSetup(mockUserPairsService, mockLogger) // Setup mocks for the current test case
+
+			userPairsController := controller.NewUserPairsController(
+				mockUserPairsService,
+				mocks.NewUserService(t),
+				nil,
+				mocks.NewAllExchanges(t),
+				mockLogger,
+			)
+
+			app.Get("/api/user/pair", func(c *fiber.Ctx) error {
+				c.Locals("user", models.User{ID: 1})      // Add user to context locals
+				return userPairsController.GetUserPair(c) // Call GetUserPair method on UserPairsController
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/user/pair"+tc.query, nil), -1)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			if tc.expectedBody != "" {
+				body, err := io.ReadAll(resp.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, tc.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+// TestImportUserPairsController tests the import of the user pairs from CSV and JSON files with valid and invalid rows.
+func TestImportUserPairsController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	mixedCsv := "exchange,pair,exact_value,search_mode,multiplier,window,tolerance,side\n" +
+		"binance_spot,BTC/USDT,2.5,exact,0,0,5,both\n" + // Valid row as exported
+		"binance_spot,ETH/USDT,abc,exact,0,0,0,both\n" + // The exact value isn't a number
+		"okx_spot,SOL/USDT\n" + // The row lacks values
+		"binance_spot,XRP/USDT,0,,,,,\n" + // Rejected by the validation of the service
+		"okx_spot,ETH/USDT,10,relative,4,8,,bids\n" // Valid row in the relative mode watching the bids only
+
+	// Matches the parsed pairs passed to the service, which are the rows whose values are parsed
+	parsedPairs := mock.MatchedBy(func(toAdd []models.UserPairs) bool {
+		return len(toAdd) == 3 &&
+			toAdd[0] == models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 2.5, SearchMode: models.SearchModeExact, Tolerance: 5, Side: models.SideBoth} &&
+			toAdd[1].Pair == "XRP/USDT" &&
+			toAdd[2] == models.UserPairs{UserID: 1, Exchange: "okx_spot", Pair: "ETH/USDT", ExactValue: 10, SearchMode: models.SearchModeRelative, Multiplier: 4, Window: 8, Side: models.SideBids}
+	})
+
+	tests := []struct {
+		name        string // Name of the test case
+		contentType string // Content type of the request
+		body        string // Body of the request
+		mocksSetup  func(
+			userPairsMock *mocks.UserPairsService,
+			userMock *mocks.UserService,
+			allExchangesMock *mocks.AllExchanges,
+			mockExchange *mocks.Exchange,
+			mockLogger *mocks.Logger,
+		) // Function to set up mock behavior
+		expectedCode int    // Expected HTTP status code after the request
+		expectedBody string // Expected body of the response
+	}{
+		{
+			name:        "Mixed CSV",
+			contentType: "text/csv",
+			body:        mixedCsv,
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+				allExchangesMock.On("Get", mock.Anything).Return(mockExchange, true)
+				mockExchange.On("PairsLoaded").Return(true)
+				mockExchange.On("HasPair", mock.Anything).Return(true)
+				userPairsMock.On("BulkAdd", mock.Anything, parsedPairs).
+					Return([]error{nil, service.NewValidationError("exact value must be above zero"), nil}, nil)
+				mockExchange.On("AddPairToSubscribedPairs", "BTC/USDT").Return().Once() // Only the imported pairs are subscribed
+				mockExchange.On("AddPairToSubscribedPairs", "ETH/USDT").Return().Once()
+				userMock.On("SetUserIdIntoMemory", 1).Return(nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"imported":2,"skipped":3,"pairs":[` +
+				`{"exchange":"binance_spot","pair":"BTC/USDT","added":true},` +
+				`{"exchange":"binance_spot","pair":"ETH/USDT","added":false,"error":"exact_value must be a number","code":"invalid_input"},` +
+				`{"exchange":"okx_spot","pair":"SOL/USDT","added":false,"error":"row must have as many values as the header","code":"invalid_input"},` +
+				`{"exchange":"binance_spot","pair":"XRP/USDT","added":false,"error":"exact value must be above zero","code":"validation_failed"},` +
+				`{"exchange":"okx_spot","pair":"ETH/USDT","added":true}]}`,
+		},
+		{
+			name:        "JSON",
+			contentType: "application/json",
+			body:        `[{"exchange":"binance_spot","pair":"BTC/USDT","exact_value":2.5}]`,
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+				allExchangesMock.On("Get", "binance_spot").Return(mockExchange, true)
+				mockExchange.On("PairsLoaded").Return(true)
+				mockExchange.On("HasPair", "BTC/USDT").Return(true)
+				userPairsMock.On("BulkAdd", mock.Anything, mock.Anything).Return([]error{nil}, nil)
+				mockExchange.On("AddPairToSubscribedPairs", "BTC/USDT").Return().Once()
+				userMock.On("SetUserIdIntoMemory", 1).Return(nil).Once()
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"imported":1,"skipped":0,"pairs":[{"exchange":"binance_spot","pair":"BTC/USDT","added":true}]}`,
+		},
+		{
+			name:        "CSV Without Required Column",
+			contentType: "text/csv",
+			body:        "exchange,pair\nbinance_spot,BTC/USDT\n",
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"result":"invalid input data","code":"invalid_input"}`,
+		},
+		{
+			name:        "CSV Without Rows",
+			contentType: "text/csv",
+			body:        "exchange,pair,exact_value\n",
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"result":"from 1 to 100 pairs are expected","code":"invalid_input"}`,
+		},
+		{
+			name:        "Transaction Error",
+			contentType: "text/csv",
+			body:        mixedCsv,
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+			) {
+				allExchangesMock.On("Get", mock.Anything).Return(mockExchange, true)
+				mockExchange.On("PairsLoaded").Return(true)
+				mockExchange.On("HasPair", mock.Anything).Return(true)
+				userPairsMock.On("BulkAdd", mock.Anything, mock.Anything).Return(nil, errors.New("db error")) // Nothing is subscribed
+				mockLogger.On("Error", mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"result":"internal server error","code":"internal_error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable for use in goroutine
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run each test case in parallel
+
+			app := fiber.New() // Create a new Fiber application instance
+
+			mockUserPairsService := mocks.NewUserPairsService(t)
+			mockUserService := mocks.NewUserService(t)
+			mockAllExchangesStorage := mocks.NewAllExchanges(t)
+			mockExchange := mocks.NewExchange(t)
+			mockLogger := mocks.NewLogger(t)
+
+			tc.mocksSetup(
+				mockUserPairsService,
+				mockUserService,
+				mockAllExchangesStorage,
+				mockExchange,
+				mockLogger,
+			) // Setup mocks for the current test case
+
+			userPairsController := controller.NewUserPairsController(
+				mockUserPairsService,
+				mockUserService,
+				nil,
+				mockAllExchangesStorage,
+				mockLogger,
+			)
+
+			app.Post("/api/user/pair/import", func(c *fiber.Ctx) error {
+				c.Locals("user", models.User{ID: 1}) // Add user to context locals
+				return userPairsController.Import(c)
+			})
+
+			req := httptest.NewRequest("POST", "/api/user/pair/import", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+
+			resp, err := app.Test(req, -1) // Execute the request against the Fiber app
+			assert.NoError(t, err)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
+
+// TestExportUserPairsController tests the export of the user pairs as CSV and JSON files.
+func TestExportUserPairsController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	userPairs := []models.UserPairs{
+		{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 2.5, Tolerance: 5},
+		{UserID: 1, Exchange: "okx_spot", Pair: "ETH/USDT", ExactValue: 10, SearchMode: models.SearchModeRelative, Multiplier: 4, Window: 8, Side: models.SideBids},
+		{UserID: 1, Exchange: "bybit_spot", Pair: "SOL/USDT", ExactValue: 500, SearchMode: models.SearchModeCumulative, PriceRange: 0.5},
+	}
+
+	tests := []struct {
+		name                string                                                           // Name of the test case
+		query               string                                                           // Query string of the request
+		mocksSetup          func(userMock *mocks.UserPairsService, mockLogger *mocks.Logger) // Function to set up mock behavior
+		expectedCode        int                                                              // Expected HTTP status code after the request
+		expectedContentType string                                                           // Expected content type of the response
+		expectedDisposition string                                                           // Expected content disposition of the response
+		expectedBody        string                                                           // Expected body of the response
+	}{
+		{
+			name: "CSV Export",
+			mocksSetup: func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {
+				userPairsMock.On("GetAllUserPairs", mock.Anything, 1).Return(userPairs, nil)
+			},
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedDisposition: `attachment; filename="pairs.csv"`,
+			expectedBody: "exchange,pair,exact_value,search_mode,multiplier,window,tolerance,side,price_range\n" +
+				"binance_spot,BTC/USDT,2.5,exact,0,0,5,both,0\n" + // The empty search mode and side are exported as the exact mode and both sides
+				"okx_spot,ETH/USDT,10,relative,4,8,0,bids,0\n" +
+				"bybit_spot,SOL/USDT,500,cumulative,0,0,0,both,0.5\n",
+		},
+		{
+			name:  "JSON Export",
+			query: "?format=json",
+			mocksSetup: func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {
+				userPairsMock.On("GetAllUserPairs", mock.Anything, 1).Return(userPairs[:1], nil)
+			},
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/json",
+			expectedDisposition: `attachment; filename="pairs.json"`,
+			expectedBody:        `[{"exchange":"binance_spot","pair":"BTC/USDT","exact_value":2.5,"tolerance":5}]`,
+		},
+		{
+			name:  "Empty CSV Export",
+			query: "?format=csv",
+			mocksSetup: func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {
+				userPairsMock.On("GetAllUserPairs", mock.Anything, 1).Return(nil, nil)
+			},
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedDisposition: `attachment; filename="pairs.csv"`,
+			expectedBody:        "exchange,pair,exact_value,search_mode,multiplier,window,tolerance,side,price_range\n", // Only the header row
+		},
+		{
+			name:                "Invalid Format",
+			query:               "?format=xml",
+			mocksSetup:          func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {}, // The service must not be called
+			expectedCode:        http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        `{"result":"format must be csv or json","code":"invalid_input"}`,
+		},
+		{
+			name: "Error Retrieving User Pairs",
+			mocksSetup: func(userPairsMock *mocks.UserPairsService, mockLogger *mocks.Logger) {
+				userPairsMock.On("GetAllUserPairs", mock.Anything, 1).Return(nil, errors.New("retrieve error"))
+				mockLogger.On("Error", mock.Anything).Return(nil)
+			},
+			expectedCode:        http.StatusInternalServerError,
+			expectedContentType: "application/json",
+			expectedBody:        `{"result":"internal server error","code":"internal_error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable for use in goroutine
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run each test case in parallel
+
+			app := fiber.New() // Create a new Fiber application instance
+
+			mockUserPairsService := mocks.NewUserPairsService(t) // Create a new mock UserPairs service
+			mockLogger := mocks.NewLogger(t)
+
+			tc.mocksSetup(mockUserPairsService, mockLogger) // Setup mocks for the current test case
+
+			userPairsController := controller.NewUserPairsController(
+				mockUserPairsService,
+				mocks.NewUserService(t),
+				nil,
+				mocks.NewAllExchanges(t),
+				mockLogger,
+			)
+
+			app.Get("/api/user/pair/export", func(c *fiber.Ctx) error {
+				c.Locals("user", models.User{ID: 1}) // Add user to context locals
+				return userPairsController.Export(c)
+			})
+
+			req := httptest.NewRequest("GET", "/api/user/pair/export"+tc.query, nil)
+
+			resp, err := app.Test(req, -1) // Execute the request against the Fiber app
+			assert.NoError(t, err)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedContentType, resp.Header.Get("Content-Type"))
+			assert.Equal(t, tc.expectedDisposition, resp.Header.Get("Content-Disposition"))
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
+
+func TestDeletePairController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name       string // Name of the test case
+		userID     int    // User ID for deleting the pair
+		pairQuery  string // Query parameter for deleting the user pair
+		mocksSetup func(
+			userPairsMock *mocks.UserPairsService,
+			userMock *mocks.UserService,
 			allExchangesMock *mocks.AllExchanges,
 			mockExchange *mocks.Exchange,
 			mockLogger *mocks.Logger,
@@ -303,15 +1139,75 @@ func TestDeletePairController(t *testing.T) {
 				mockLogger *mocks.Logger,
 				mockFoundVolumes *mocks.FoundVolumesService,
 			) {
-				mockExchange.On("DeletePairFromSubscribedPairs", "BTC-ETH").Return()
+				mockExchange.On("DeletePairFromSubscribedPairs", "BTC/ETH").Return()
 				mockExchange.On("ExchangeName").Return("test-exchange")
-				userPairsMock.On("DeletePair", mock.Anything, mock.Anything).Return(nil) // Mock successful deletion
-				userMock.On("DeleteUserIdFromMemory", mock.Anything).Return(nil)         // Mock successful deletion
+				userPairsMock.On("DeletePair", mock.Anything, mock.Anything).Return(nil)                           // Mock successful deletion
+				userPairsMock.On("CountPairSubscribers", mock.Anything, "test-exchange", "BTC/ETH").Return(0, nil) // Nobody else watches the pair
+				userMock.On("DeleteUserIdFromMemory", mock.Anything).Return(nil)                                   // Mock successful deletion
 				allExchangesMock.On("All").Return([]exchange.Exchange{mockExchange})
-				mockFoundVolumes.On("DeleteFoundVolume", mock.Anything).Return()
+				mockFoundVolumes.On("DeleteFoundVolume", mock.Anything, mock.Anything).Return(nil)
 			},
 			expectedCode: http.StatusOK, // Expecting 200 OK status
 		},
+		{
+			name:      "Error Counting Subscribers Keeps Subscription",
+			userID:    1,
+			pairQuery: "BTC-ETH", // Pair to be deleted
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+				mockFoundVolumes *mocks.FoundVolumesService,
+			) {
+				mockExchange.On("ExchangeName").Return("test-exchange") // The pair isn't removed from the subscribed pairs
+				userPairsMock.On("DeletePair", mock.Anything, mock.Anything).Return(nil)
+				userPairsMock.On("CountPairSubscribers", mock.Anything, "test-exchange", "BTC/ETH").Return(0, errors.New("count error"))
+				userMock.On("DeleteUserIdFromMemory", mock.Anything).Return(nil)
+				allExchangesMock.On("All").Return([]exchange.Exchange{mockExchange})
+				mockFoundVolumes.On("DeleteFoundVolume", mock.Anything, mock.Anything).Return(nil)
+				mockLogger.On("Error", mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusOK, // Expecting 200 OK status, the pair of the user is deleted
+		},
+		{
+			name:      "Concatenated Pair",
+			userID:    1,
+			pairQuery: "ethbtc", // Pair to be deleted, stored as ETH/BTC
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+				mockFoundVolumes *mocks.FoundVolumesService,
+			) {
+				mockExchange.On("DeletePairFromSubscribedPairs", "ETH/BTC").Return()
+				mockExchange.On("ExchangeName").Return("test-exchange")
+				userPairsMock.On("DeletePair", mock.Anything, models.UserPairs{UserID: 1, Pair: "ETH/BTC"}).Return(nil) // The normalized pair is deleted
+				userPairsMock.On("CountPairSubscribers", mock.Anything, "test-exchange", "ETH/BTC").Return(0, nil)
+				userMock.On("DeleteUserIdFromMemory", mock.Anything).Return(nil)
+				allExchangesMock.On("All").Return([]exchange.Exchange{mockExchange})
+				mockFoundVolumes.On("DeleteFoundVolume", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusOK, // Expecting 200 OK status
+		},
+		{
+			name:      "Invalid Pair Format",
+			userID:    1,
+			pairQuery: "BTC", // The assets can't be told apart, so nothing is deleted
+			mocksSetup: func(
+				userPairsMock *mocks.UserPairsService,
+				userMock *mocks.UserService,
+				allExchangesMock *mocks.AllExchanges,
+				mockExchange *mocks.Exchange,
+				mockLogger *mocks.Logger,
+				mockFoundVolumes *mocks.FoundVolumesService,
+			) {
+			},
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status
+		},
 		{
 			name:      "Error Deleting Pair",
 			userID:    1,
@@ -378,3 +1274,503 @@ func TestDeletePairController(t *testing.T) {
 		})
 	}
 }
+
+// TestDeletePairKeepsSubscriptionOfOtherUsers tests that deleting the pair of one user keeps the exchange
+// subscribed to the pair while another user still watches it there.
+func TestDeletePairKeepsSubscriptionOfOtherUsers(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	const pair = "BTC/USDT"
+
+	mockUserPairsService := mocks.NewUserPairsService(t)
+	mockUserService := mocks.NewUserService(t)
+	mockAllExchangesStorage := mocks.NewAllExchanges(t)
+	mockFoundVolumesService := mocks.NewFoundVolumesService(t)
+	mockLogger := mocks.NewLogger(t)
+
+	sharedExchange := mocks.NewExchange(t) // Both users watch the pair on this exchange
+	sharedExchange.On("ExchangeName").Return("binance_spot")
+
+	ownExchange := mocks.NewExchange(t) // Only the deleting user watches the pair on this exchange
+	ownExchange.On("ExchangeName").Return("bybit_spot")
+	ownExchange.On("DeletePairFromSubscribedPairs", pair).Return().Once()
+
+	mockAllExchangesStorage.On("All").Return([]exchange.Exchange{sharedExchange, ownExchange})
+	mockUserPairsService.On("DeletePair", mock.Anything, models.UserPairs{UserID: 1, Pair: pair}).Return(nil)
+	mockUserPairsService.On("CountPairSubscribers", mock.Anything, "binance_spot", pair).Return(1, nil) // The second user keeps the pair
+	mockUserPairsService.On("CountPairSubscribers", mock.Anything, "bybit_spot", pair).Return(0, nil)
+	mockUserService.On("DeleteUserIdFromMemory", 1).Return(nil)
+	mockFoundVolumesService.On("DeleteFoundVolume", mock.Anything, mock.MatchedBy(func(userPairData models.UserPairs) bool {
+		return userPairData.UserID == 1 // Only the found volumes of the deleting user are removed
+	})).Return(nil).Twice()
+
+	userPairsController := controller.NewUserPairsController(
+		mockUserPairsService,
+		mockUserService,
+		mockFoundVolumesService,
+		mockAllExchangesStorage,
+		mockLogger,
+	)
+
+	app := fiber.New()
+	app.Delete("/api/user/pairs", func(c *fiber.Ctx) error {
+		c.Locals("user", models.User{ID: 1}) // The first user deletes the pair
+		return userPairsController.DeletePair(c)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/api/user/pairs?pair="+pair, nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	sharedExchange.AssertNotCalled(t, "DeletePairFromSubscribedPairs", pair) // The book is still fetched for the second user
+}
+
+// TestDeleteAllPairsController tests that all pairs of the user are deleted, the pairs nobody else watches
+// are unsubscribed and the found volumes are cleared, so the subsequent GetAllUserPairs returns no pairs.
+func TestDeleteAllPairsController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	userPairs := []models.UserPairs{
+		{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10},
+		{UserID: 1, Exchange: "bybit_spot", Pair: "ETH/USDT", ExactValue: 20},
+	}
+
+	mockUserPairsRepository := mocks.NewUserPairsRepository(t)
+	mockUserPairsRepository.On("GetAllUserPairs", mock.Anything, 1).Return(userPairs, nil).Once() // The pairs before the deletion
+	mockUserPairsRepository.On("DeleteAllUserPairs", mock.Anything, 1).Return(nil).Once()
+	mockUserPairsRepository.On("GetAllUserPairs", mock.Anything, 1).Return([]models.UserPairs{}, nil).Once()     // The pairs after the deletion
+	mockUserPairsRepository.On("CountPairSubscribers", mock.Anything, "binance_spot", "BTC/USDT").Return(1, nil) // Another user keeps the pair
+	mockUserPairsRepository.On("CountPairSubscribers", mock.Anything, "bybit_spot", "ETH/USDT").Return(0, nil)
+
+	userPairsService := service.NewUserPairsService(mockUserPairsRepository, maxPairsPerUser, contextTimeout)
+
+	mockUserService := mocks.NewUserService(t)
+	mockUserService.On("DeleteUserIdFromMemory", 1).Return(nil)
+
+	mockFoundVolumesService := mocks.NewFoundVolumesService(t)
+	mockFoundVolumesService.On("DeleteFoundVolume", mock.Anything, userPairs[0]).Return(nil).Once()
+	mockFoundVolumesService.On("DeleteFoundVolume", mock.Anything, userPairs[1]).Return(nil).Once()
+
+	sharedExchange := mocks.NewExchange(t) // The pair is still watched on this exchange
+
+	ownExchange := mocks.NewExchange(t) // Only the deleting user watches the pair on this exchange
+	ownExchange.On("DeletePairFromSubscribedPairs", "ETH/USDT").Return().Once()
+
+	mockAllExchangesStorage := mocks.NewAllExchanges(t)
+	mockAllExchangesStorage.On("Get", "binance_spot").Return(sharedExchange, true)
+	mockAllExchangesStorage.On("Get", "bybit_spot").Return(ownExchange, true)
+
+	userPairsController := controller.NewUserPairsController(
+		userPairsService,
+		mockUserService,
+		mockFoundVolumesService,
+		mockAllExchangesStorage,
+		mocks.NewLogger(t),
+	)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user", models.User{ID: 1}) // Add the authenticated user to context locals
+		return c.Next()
+	})
+	app.Delete("/api/user/pair/all", userPairsController.DeleteAllPairs)
+	app.Get("/api/user/pair/all-pairs", userPairsController.GetAllUserPairs)
+
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/api/user/pair/all", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	sharedExchange.AssertNotCalled(t, "DeletePairFromSubscribedPairs", "BTC/USDT") // The book is still fetched for the other user
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/user/pair/all-pairs", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var remainingPairs []models.UserPairs
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&remainingPairs))
+	assert.Empty(t, remainingPairs)
+}
+
+// TestDeleteAllPairsControllerErrors tests that nothing is unsubscribed or cleared when the pairs can't be deleted.
+func TestDeleteAllPairsControllerErrors(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name       string                        // Name of the test case
+		mocksSetup func(*mocks.UserPairsService) // Function to set up mock behavior
+	}{
+		{
+			name: "Pairs Can't Be Read",
+			mocksSetup: func(m *mocks.UserPairsService) {
+				m.On("GetAllUserPairs", mock.Anything, 1).Return(nil, errors.New("db error"))
+			},
+		},
+		{
+			name: "Pairs Can't Be Deleted",
+			mocksSetup: func(m *mocks.UserPairsService) {
+				m.On("GetAllUserPairs", mock.Anything, 1).Return([]models.UserPairs{{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT"}}, nil)
+				m.On("DeleteAllUserPairs", mock.Anything, 1).Return(errors.New("db error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockUserPairsService := mocks.NewUserPairsService(t)
+			tc.mocksSetup(mockUserPairsService)
+
+			mockLogger := mocks.NewLogger(t)
+			mockLogger.On("Error", mock.Anything).Return(nil)
+
+			// The user, exchanges and found volumes services aren't expected to be called
+			userPairsController := controller.NewUserPairsController(
+				mockUserPairsService,
+				mocks.NewUserService(t),
+				mocks.NewFoundVolumesService(t),
+				mocks.NewAllExchanges(t),
+				mockLogger,
+			)
+
+			app := fiber.New()
+			app.Delete("/api/user/pair/all", func(c *fiber.Ctx) error {
+				c.Locals("user", models.User{ID: 1}) // Add the authenticated user to context locals
+				return userPairsController.DeleteAllPairs(c)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("DELETE", "/api/user/pair/all", nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		})
+	}
+}
+
+func TestStreamFoundVolumesController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	const userID = 1
+
+	mockFoundVolumesRepository := mocks.NewFoundVolumesRepository(t)
+	mockFoundVolumesRepository.On("Upsert", mock.Anything, userID, mock.Anything).Return(nil)
+
+	foundVolumesService := service.NewFoundVolumesService(mockFoundVolumesRepository, notifyAllSettingsService(t), mocks.NewLogger(t), contextTimeout)
+
+	userPairsController := controller.NewUserPairsController(
+		nil,
+		nil,
+		foundVolumesService,
+		nil,
+		nil,
+	)
+
+	app := fiber.New()
+	app.Get(
+		"/api/user/pair/found-volumes/ws",
+		func(c *fiber.Ctx) error {
+			c.Locals("user", models.User{ID: userID}) // Add user to context locals
+			return c.Next()
+		},
+		userPairsController.FoundVolumesWebsocketUpgrade,
+		websocket.New(userPairsController.StreamFoundVolumes),
+	)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0") // Listen on a random free port
+	assert.NoError(t, err)
+
+	go app.Listener(listener)
+	defer app.Shutdown()
+
+	// A plain request must be rejected
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/user/pair/found-volumes/ws", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUpgradeRequired, resp.StatusCode)
+
+	conn, _, err := fasthttpws.DefaultDialer.Dial("ws://"+listener.Addr().String()+"/api/user/pair/found-volumes/ws", nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	userPairData := models.UserPairs{UserID: userID, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10}
+	foundVolume := models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 12}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	// The subscriber is registered right after the handshake, so keep recording the volume until it is received.
+	// Only new found volumes are pushed, so the volume is moved to another price every time.
+	go func() {
+		for price := foundVolume.Price; ; price++ {
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+				movedVolume := foundVolume
+				movedVolume.Price = price
+
+				foundVolumesService.UpsertFoundVolume(ctx, userPairData, movedVolume)
+			}
+		}
+	}()
+
+	var received models.FoundVolume
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	assert.NoError(t, conn.ReadJSON(&received))
+	assert.Equal(t, foundVolume.Pair, received.Pair)
+	assert.Equal(t, foundVolume.Side, received.Side)
+	assert.Equal(t, foundVolume.Volume, received.Volume)
+}
+
+func TestGetAllUserFoundVolumesController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name         string                           // Name of the test case
+		query        string                           // Query string of the request
+		mocksSetup   func(*mocks.FoundVolumesService) // Function to set up mock behavior
+		expectedCode int                              // Expected HTTP status code after the request
+	}{
+		{
+			name:  "Filter is parsed from the query",
+			query: "?exchange=binance_spot&pair=BTC/USDT&side=asks&min_volume=2.5&limit=10&offset=20&sort=volume&order=asc",
+			mocksSetup: func(m *mocks.FoundVolumesService) {
+				m.On("GetAllFoundVolume", 1, models.FoundVolumesFilter{
+					Exchange:  "binance_spot",
+					Pair:      "BTC/USDT",
+					Side:      "asks",
+					MinVolume: 2.5,
+					Limit:     10,
+					Offset:    20,
+					Sort:      "volume",
+					Order:     "asc",
+				}).Return([]models.FoundVolume{}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Invalid limit",
+			query:        "?limit=ten",
+			mocksSetup:   func(m *mocks.FoundVolumesService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Negative offset",
+			query:        "?offset=-1",
+			mocksSetup:   func(m *mocks.FoundVolumesService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Invalid sort key",
+			query:        "?sort=time",
+			mocksSetup:   func(m *mocks.FoundVolumesService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Invalid order",
+			query:        "?order=up",
+			mocksSetup:   func(m *mocks.FoundVolumesService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable for use in goroutine
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run each test case in parallel
+
+			app := fiber.New() // Create a new Fiber application instance
+
+			mockFoundVolumesService := mocks.NewFoundVolumesService(t)
+			tc.mocksSetup(mockFoundVolumesService)
+
+			userPairsController := controller.NewUserPairsController(
+				nil,
+				nil,
+				mockFoundVolumesService,
+				nil,
+				nil,
+			)
+
+			app.Get("/api/user/pair/found-volumes", func(c *fiber.Ctx) error {
+				c.Locals("user", models.User{ID: 1})                 // Add user to context locals
+				return userPairsController.GetAllUserFoundVolumes(c) // Call GetAllUserFoundVolumes method on UserPairsController
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/user/pair/found-volumes"+tc.query, nil), -1)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode) // Assert that the response status code matches expected
+		})
+	}
+}
+
+// TestReplaceUserPairsController tests that the pairs of the user are reconciled with the pairs of the request,
+// the added pairs are subscribed and the removed ones nobody else watches are unsubscribed.
+func TestReplaceUserPairsController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	btc := models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10}
+	eth := models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "ETH/USDT", ExactValue: 20}
+	sol := models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "SOL/USDT", ExactValue: 30}
+
+	tests := []struct {
+		name           string             // Name of the test case
+		stored         []models.UserPairs // Pairs the user has before the request
+		body           string             // Request body
+		sharedPairs    []string           // Removed pairs other users still watch
+		expectedStatus int                // Expected HTTP status code
+		expectedAdded  []string           // Pairs expected to be subscribed
+		expectedGone   []string           // Pairs expected to be unsubscribed
+		expectedResult int                // Expected number of the added, updated and removed pairs
+		expectMemory   bool               // Whether the user is expected to be kept in memory
+	}{
+		{
+			name:           "Adds Only",
+			stored:         []models.UserPairs{btc},
+			body:           `[{"exchange":"binance_spot","pair":"BTC/USDT","exact_value":10},{"exchange":"Binance_Spot","pair":"ethusdt","exact_value":20}]`,
+			expectedStatus: http.StatusOK,
+			expectedAdded:  []string{"ETH/USDT"},
+			expectedResult: 1,
+			expectMemory:   true,
+		},
+		{
+			name:           "Removes Only",
+			stored:         []models.UserPairs{btc, eth, sol},
+			body:           `[{"exchange":"binance_spot","pair":"ETH/USDT","exact_value":20}]`,
+			sharedPairs:    []string{"SOL/USDT"},
+			expectedStatus: http.StatusOK,
+			expectedGone:   []string{"BTC/USDT"}, // SOL/USDT is still fetched for another user
+			expectedResult: 2,
+			expectMemory:   true,
+		},
+		{
+			name:           "Mixed",
+			stored:         []models.UserPairs{btc, eth},
+			body:           `[{"exchange":"binance_spot","pair":"BTC/USDT","exact_value":15},{"exchange":"binance_spot","pair":"SOL/USDT","exact_value":30}]`,
+			expectedStatus: http.StatusOK,
+			expectedAdded:  []string{"SOL/USDT"},
+			expectedGone:   []string{"ETH/USDT"},
+			expectedResult: 3, // BTC/USDT is updated
+			expectMemory:   true,
+		},
+		{
+			name:           "All Removed",
+			stored:         []models.UserPairs{btc},
+			body:           `[]`,
+			expectedStatus: http.StatusOK,
+			expectedGone:   []string{"BTC/USDT"},
+			expectedResult: 1,
+		},
+		{
+			name:           "Pair Not Listed",
+			stored:         []models.UserPairs{btc},
+			body:           `[{"exchange":"binance_spot","pair":"DOGE/USDT","exact_value":10}]`,
+			expectedStatus: http.StatusBadRequest, // Nothing is changed
+		},
+		{
+			name:           "Unsupported Exchange",
+			stored:         []models.UserPairs{btc},
+			body:           `[{"exchange":"unknown","pair":"BTC/USDT","exact_value":10}]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Duplicated Pair",
+			stored:         []models.UserPairs{btc},
+			body:           `[{"exchange":"binance_spot","pair":"BTC/USDT","exact_value":10},{"exchange":"binance_spot","pair":"btc-usdt","exact_value":20}]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid Body",
+			body:           `{"exchange":"binance_spot"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockUserPairsRepository := mocks.NewUserPairsRepository(t)
+			mockUserPairsRepository.On("ReplaceUserPairs", mock.Anything, 1, mock.Anything).Return(
+				func(_ context.Context, _ int, pairs []models.UserPairs) (models.UserPairsReplaceResult, error) {
+					return models.DiffUserPairs(tc.stored, pairs), nil // The repository applies the difference
+				},
+			).Maybe()
+			for _, pairData := range tc.stored {
+				subscribers := 0
+				if slices.Contains(tc.sharedPairs, pairData.Pair) {
+					subscribers = 1
+				}
+				mockUserPairsRepository.On("CountPairSubscribers", mock.Anything, "binance_spot", pairData.Pair).Return(subscribers, nil).Maybe()
+			}
+
+			userPairsService := service.NewUserPairsService(mockUserPairsRepository, maxPairsPerUser, contextTimeout)
+
+			mockExchange := mocks.NewExchange(t)
+			mockExchange.On("PairsLoaded").Return(true).Maybe()
+			mockExchange.On("HasPair", mock.Anything).Return(func(pair string) bool {
+				return pair != "DOGE/USDT"
+			}).Maybe()
+			for _, pair := range tc.expectedAdded {
+				mockExchange.On("AddPairToSubscribedPairs", pair).Return().Once()
+			}
+			for _, pair := range tc.expectedGone {
+				mockExchange.On("DeletePairFromSubscribedPairs", pair).Return().Once()
+			}
+
+			mockAllExchangesStorage := mocks.NewAllExchanges(t)
+			mockAllExchangesStorage.On("Get", "binance_spot").Return(mockExchange, true).Maybe()
+			mockAllExchangesStorage.On("Get", "unknown").Return(nil, false).Maybe()
+
+			mockUserService := mocks.NewUserService(t)
+			mockFoundVolumesService := mocks.NewFoundVolumesService(t)
+			if tc.expectedStatus == http.StatusOK {
+				if tc.expectMemory {
+					mockUserService.On("SetUserIdIntoMemory", 1).Return()
+				} else {
+					mockUserService.On("DeleteUserIdFromMemory", 1).Return(nil)
+				}
+				mockFoundVolumesService.On("DeleteFoundVolume", mock.Anything, mock.Anything).Return(nil).Maybe()
+			}
+
+			mockLogger := mocks.NewLogger(t)
+			mockLogger.On("Error", mock.Anything).Return(nil).Maybe()
+
+			userPairsController := controller.NewUserPairsController(
+				userPairsService,
+				mockUserService,
+				mockFoundVolumesService,
+				mockAllExchangesStorage,
+				mockLogger,
+			)
+
+			app := fiber.New()
+			app.Put("/api/user/pair/replace", func(c *fiber.Ctx) error {
+				c.Locals("user", models.User{ID: 1}) // Add the authenticated user to context locals
+				return userPairsController.Replace(c)
+			})
+
+			req := httptest.NewRequest("PUT", "/api/user/pair/replace", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+
+			if tc.expectedStatus != http.StatusOK {
+				mockUserPairsRepository.AssertNotCalled(t, "ReplaceUserPairs", mock.Anything, mock.Anything, mock.Anything)
+
+				return
+			}
+
+			var result models.UserPairsReplaceResult
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			assert.Equal(t, tc.expectedResult, len(result.Added)+len(result.Updated)+len(result.Removed))
+		})
+	}
+}
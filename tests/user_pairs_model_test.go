@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"testing"
+
+	"cvs/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDiffUserPairs tests that the stored pairs are reconciled with the desired ones by the exchange and the pair.
+func TestDiffUserPairs(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	btc := models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10}
+	eth := models.UserPairs{UserID: 1, Exchange: "bybit_spot", Pair: "ETH/USDT", ExactValue: 20, SearchMode: models.SearchModeExact}
+	sol := models.UserPairs{UserID: 1, Exchange: "okx_spot", Pair: "SOL/USDT", ExactValue: 30}
+	btcOtherExchange := models.UserPairs{UserID: 1, Exchange: "bybit_spot", Pair: "BTC/USDT", ExactValue: 10}
+
+	btcUpdated := btc
+	btcUpdated.ExactValue = 15
+
+	ethDefaultMode := eth
+	ethDefaultMode.SearchMode = "" // The default search mode is the exact one
+
+	solBothSides := sol
+	solBothSides.Side = models.SideBoth // The default side is both sides
+
+	solBids := sol
+	solBids.Side = models.SideBids
+
+	tests := []struct {
+		name     string                        // Name of the test case
+		stored   []models.UserPairs            // Pairs the user has
+		desired  []models.UserPairs            // Pairs the user should have
+		expected models.UserPairsReplaceResult // Expected changes
+	}{
+		{
+			name:    "Adds Only",
+			stored:  []models.UserPairs{btc},
+			desired: []models.UserPairs{btc, eth, sol},
+			expected: models.UserPairsReplaceResult{
+				Added:   []models.UserPairs{eth, sol},
+				Updated: []models.UserPairs{},
+				Removed: []models.UserPairs{},
+			},
+		},
+		{
+			name:    "Removes Only",
+			stored:  []models.UserPairs{btc, eth, sol},
+			desired: []models.UserPairs{eth},
+			expected: models.UserPairsReplaceResult{
+				Added:   []models.UserPairs{},
+				Updated: []models.UserPairs{},
+				Removed: []models.UserPairs{btc, sol},
+			},
+		},
+		{
+			name:    "Mixed",
+			stored:  []models.UserPairs{btc, eth},
+			desired: []models.UserPairs{sol, btcUpdated, btcOtherExchange},
+			expected: models.UserPairsReplaceResult{
+				Added:   []models.UserPairs{sol, btcOtherExchange}, // The pair of another exchange is another pair
+				Updated: []models.UserPairs{btcUpdated},
+				Removed: []models.UserPairs{eth},
+			},
+		},
+		{
+			name:    "No Changes",
+			stored:  []models.UserPairs{btc, eth},
+			desired: []models.UserPairs{ethDefaultMode, btc},
+			expected: models.UserPairsReplaceResult{
+				Added:   []models.UserPairs{},
+				Updated: []models.UserPairs{},
+				Removed: []models.UserPairs{},
+			},
+		},
+		{
+			name:    "Side Changed",
+			stored:  []models.UserPairs{solBothSides},
+			desired: []models.UserPairs{solBids}, // Only the bids are watched now
+			expected: models.UserPairsReplaceResult{
+				Added:   []models.UserPairs{},
+				Updated: []models.UserPairs{solBids},
+				Removed: []models.UserPairs{},
+			},
+		},
+		{
+			name:    "Default Side",
+			stored:  []models.UserPairs{solBothSides},
+			desired: []models.UserPairs{sol},
+			expected: models.UserPairsReplaceResult{
+				Added:   []models.UserPairs{},
+				Updated: []models.UserPairs{},
+				Removed: []models.UserPairs{},
+			},
+		},
+		{
+			name:    "All Removed",
+			stored:  []models.UserPairs{btc},
+			desired: nil,
+			expected: models.UserPairsReplaceResult{
+				Added:   []models.UserPairs{},
+				Updated: []models.UserPairs{},
+				Removed: []models.UserPairs{btc},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, models.DiffUserPairs(tc.stored, tc.desired))
+		})
+	}
+}
+
+// TestUserPairsWatchesSide tests which sides of the order book are recorded for each side setting of a user pair.
+func TestUserPairsWatchesSide(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		side      string // Side setting of the user pair
+		watchAsks bool   // Whether the asks are recorded
+		watchBids bool   // Whether the bids are recorded
+	}{
+		{side: "", watchAsks: true, watchBids: true}, // Empty value means both sides
+		{side: models.SideBoth, watchAsks: true, watchBids: true},
+		{side: models.SideAsks, watchAsks: true, watchBids: false},
+		{side: models.SideBids, watchAsks: false, watchBids: true},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run("Side "+tc.side, func(t *testing.T) {
+			t.Parallel()
+
+			pairData := models.UserPairs{Side: tc.side}
+
+			assert.Equal(t, tc.watchAsks, pairData.WatchesSide("asks"))
+			assert.Equal(t, tc.watchBids, pairData.WatchesSide("bids"))
+		})
+	}
+}
@@ -124,6 +124,39 @@ func TestAdd(t *testing.T) {
 	}
 }
 
+func TestBulkAdd(t *testing.T) {
+	t.Parallel()
+
+	db := setupDB()  // Setup a new database connection
+	defer db.Close() // Ensure the database connection is closed after the test
+
+	userID, err := insertUser(db, "bulkadduser@example.com", []byte("validpassword123")) // Insert a valid user into the database
+	defer db.ExecContext(ctx, deleteUserQueryRow, userID)                                // Clean up by deleting the user after the test
+	assert.NoError(t, err)
+
+	repo := repository.NewUserPairsRepository(db)
+
+	assert.NoError(t, repo.Add(ctx, models.UserPairs{UserID: userID, Exchange: "binance_spot", Pair: "ETH/USDT", ExactValue: 10}))
+
+	pairErrors, err := repo.BulkAdd(ctx, []models.UserPairs{
+		{UserID: userID, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10},
+		{UserID: userID, Exchange: "binance_spot", Pair: "ETH/USDT", ExactValue: 10}, // The user already has the pair
+		{UserID: userID, Exchange: "bybit_spot", Pair: "BTC/USDT", ExactValue: 10},
+	})
+
+	assert.NoError(t, err) // The failed pair doesn't abort the transaction
+	if assert.Len(t, pairErrors, 3) {
+		assert.NoError(t, pairErrors[0])
+		assert.Error(t, pairErrors[1])
+		assert.NoError(t, pairErrors[2])
+	}
+
+	userPairs, err := repo.GetAllUserPairs(ctx, userID)
+
+	assert.NoError(t, err)
+	assert.Len(t, userPairs, 3) // The pair added before and the two new ones
+}
+
 func TestUpdateExactValue(t *testing.T) {
 	// Run tests in parallel to improve execution speed
 	t.Parallel()
@@ -282,6 +315,204 @@ func TestGetAllUserPairs(t *testing.T) {
 		})
 	}
 }
+
+// TestGetUserPairsByExchange tests that only the user pairs of the requested exchange are retrieved.
+func TestGetUserPairsByExchange(t *testing.T) {
+	t.Parallel() // Run tests in parallel to improve execution speed
+
+	db := setupDB()  // Setup a new database connection
+	defer db.Close() // Ensure the database connection is closed after the test
+
+	userID, err := insertUser(db, "exchangepairsuser@example.com", []byte("validpassword123")) // Insert a valid user into the database
+	defer db.ExecContext(ctx, deleteUserQueryRow, userID)                                      // Clean up by deleting the user after the test
+	assert.NoError(t, err)
+
+	// Insert pairs across two exchanges
+	assert.NoError(t, insertUserPair(db, userID, "binance_spot", "BTC/USDT", 45000))
+	assert.NoError(t, insertUserPair(db, userID, "binance_spot", "ETH/USDT", 3000))
+	assert.NoError(t, insertUserPair(db, userID, "okx_spot", "BTC/USDT", 45000))
+
+	repo := repository.NewUserPairsRepository(db) // Create a new repository instance for user pairs
+
+	pairs, err := repo.GetUserPairsByExchange(ctx, userID, "binance_spot")
+	assert.NoError(t, err)
+	assert.Len(t, pairs, 2) // Only the pairs of the requested exchange are retrieved
+
+	for _, p := range pairs {
+		assert.Equal(t, userID, p.UserID)
+		assert.Equal(t, "binance_spot", p.Exchange)
+	}
+
+	pairs, err = repo.GetUserPairsByExchange(ctx, userID, "bybit_spot")
+	assert.NoError(t, err)
+	assert.Empty(t, pairs) // The user has no pairs on the exchange
+}
+
+func TestGetUserPairsPaged(t *testing.T) {
+	t.Parallel() // Run tests in parallel to improve execution speed
+
+	db := setupDB()  // Setup a new database connection
+	defer db.Close() // Ensure the database connection is closed after the test
+
+	userID, err := insertUser(db, "pageduserpairs@example.com", []byte("validpassword123")) // Insert a valid user into the database
+	defer db.ExecContext(ctx, deleteUserQueryRow, userID)                                   // Clean up by deleting the user after the test
+	assert.NoError(t, err)
+
+	// Insert five pairs out of order, they are paged sorted by exchange and pair
+	assert.NoError(t, insertUserPair(db, userID, "okx_spot", "BTC/USDT", 1))
+	assert.NoError(t, insertUserPair(db, userID, "binance_spot", "SOL/USDT", 1))
+	assert.NoError(t, insertUserPair(db, userID, "binance_spot", "BTC/USDT", 1))
+	assert.NoError(t, insertUserPair(db, userID, "okx_spot", "ETH/USDT", 1))
+	assert.NoError(t, insertUserPair(db, userID, "binance_spot", "ETH/USDT", 1))
+
+	repo := repository.NewUserPairsRepository(db) // Create a new repository instance for user pairs
+
+	tests := []struct {
+		name          string   // Name of the test case
+		limit         int      // Maximum number of pairs of the page
+		offset        int      // Number of pairs skipped before the page
+		expectedPairs []string // Expected exchange and pair of every pair of the page
+	}{
+		{
+			name:          "First Page",
+			limit:         2,
+			offset:        0,
+			expectedPairs: []string{"binance_spot BTC/USDT", "binance_spot ETH/USDT"},
+		},
+		{
+			name:          "Page Crossing Exchanges",
+			limit:         2,
+			offset:        2,
+			expectedPairs: []string{"binance_spot SOL/USDT", "okx_spot BTC/USDT"},
+		},
+		{
+			name:          "Partial Last Page",
+			limit:         2,
+			offset:        4,
+			expectedPairs: []string{"okx_spot ETH/USDT"},
+		},
+		{
+			name:          "Empty Page Past The Last Pair",
+			limit:         2,
+			offset:        6,
+			expectedPairs: []string{},
+		},
+		{
+			name:          "Page Larger Than All Pairs",
+			limit:         10,
+			offset:        0,
+			expectedPairs: []string{"binance_spot BTC/USDT", "binance_spot ETH/USDT", "binance_spot SOL/USDT", "okx_spot BTC/USDT", "okx_spot ETH/USDT"},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			pairs, err := repo.GetUserPairsPaged(ctx, userID, tc.limit, tc.offset)
+			assert.NoError(t, err)
+			assert.NotNil(t, pairs) // An empty page is an empty list, not null
+
+			gotPairs := make([]string, 0, len(pairs))
+			for _, p := range pairs {
+				assert.Equal(t, userID, p.UserID)
+				gotPairs = append(gotPairs, p.Exchange+" "+p.Pair)
+			}
+
+			assert.Equal(t, tc.expectedPairs, gotPairs)
+		})
+	}
+}
+
+func TestGetUserPair(t *testing.T) {
+	t.Parallel() // Run tests in parallel to improve execution speed
+
+	db := setupDB()  // Setup a new database connection
+	defer db.Close() // Ensure the database connection is closed after the test
+
+	userID, err := insertUser(db, "getuserpair@example.com", []byte("validpassword123")) // Insert a valid user into the database
+	defer db.ExecContext(ctx, deleteUserQueryRow, userID)                                // Clean up by deleting the user after the test
+	assert.NoError(t, err)
+
+	repo := repository.NewUserPairsRepository(db) // Create a new repository instance for user pairs
+
+	pairData := models.UserPairs{UserID: userID, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10, Tolerance: 5, Side: models.SideBids}
+	assert.NoError(t, repo.Add(ctx, pairData))
+
+	pairData.SearchMode = models.SearchModeExact // The empty search mode is stored as the exact one
+
+	storedPair, err := repo.GetUserPair(ctx, userID, "binance_spot", "BTC/USDT")
+	assert.NoError(t, err)
+	assert.Equal(t, pairData, storedPair) // The pair is returned with its search settings
+
+	_, err = repo.GetUserPair(ctx, userID, "okx_spot", "BTC/USDT") // The user has the pair on another exchange only
+	assert.ErrorIs(t, err, repository.ErrUserPairNotFound)
+}
+
+func TestCountPairSubscribers(t *testing.T) {
+	t.Parallel() // Run tests in parallel to improve execution speed
+
+	db := setupDB()  // Setup a new database connection
+	defer db.Close() // Ensure the database connection is closed after the test
+
+	// Two users watch the same pair on the same exchange
+	firstUserID, err := insertUser(db, "countsubscribersfirst@example.com", []byte("validpassword123"))
+	defer db.ExecContext(ctx, deleteUserQueryRow, firstUserID)
+	assert.NoError(t, err)
+
+	secondUserID, err := insertUser(db, "countsubscriberssecond@example.com", []byte("validpassword123"))
+	defer db.ExecContext(ctx, deleteUserQueryRow, secondUserID)
+	assert.NoError(t, err)
+
+	const pair = "CNTSUB/USDT" // Pair no other test uses
+
+	assert.NoError(t, insertUserPair(db, firstUserID, "binance_spot", pair, 100))
+	assert.NoError(t, insertUserPair(db, secondUserID, "binance_spot", pair, 200))
+	assert.NoError(t, insertUserPair(db, firstUserID, "okx_spot", pair, 100))
+
+	repo := repository.NewUserPairsRepository(db) // Create a new repository instance for user pairs
+
+	subscribers, err := repo.CountPairSubscribers(ctx, "binance_spot", pair)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, subscribers)
+
+	// The first user deletes the pair, the second one keeps it
+	assert.NoError(t, repo.DeletePair(ctx, models.UserPairs{UserID: firstUserID, Pair: pair}))
+
+	subscribers, err = repo.CountPairSubscribers(ctx, "binance_spot", pair)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, subscribers) // The exchange must keep fetching the pair
+
+	subscribers, err = repo.CountPairSubscribers(ctx, "okx_spot", pair)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, subscribers) // Nobody watches the pair on the exchange anymore
+}
+
+func TestCountUserPairs(t *testing.T) {
+	t.Parallel() // Run tests in parallel to improve execution speed
+
+	db := setupDB()  // Setup a new database connection
+	defer db.Close() // Ensure the database connection is closed after the test
+
+	userID, err := insertUser(db, "countuserpairs@example.com", []byte("validpassword123")) // Insert a valid user into the database
+	defer db.ExecContext(ctx, deleteUserQueryRow, userID)                                   // Clean up by deleting the user after the test
+	assert.NoError(t, err)
+
+	repo := repository.NewUserPairsRepository(db) // Create a new repository instance for user pairs
+
+	pairsCount, err := repo.CountUserPairs(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, pairsCount) // The user has no pairs yet
+
+	// Insert pairs across two exchanges
+	assert.NoError(t, insertUserPair(db, userID, "binance_spot", "BTC/USDT", 45000))
+	assert.NoError(t, insertUserPair(db, userID, "okx_spot", "BTC/USDT", 45000))
+
+	pairsCount, err = repo.CountUserPairs(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, pairsCount) // The pairs of every exchange are counted
+}
+
 func TestGetPairsByExchange(t *testing.T) {
 	// Run tests in parallel to improve execution speed
 	t.Parallel()
@@ -436,3 +667,83 @@ func TestDeletePair(t *testing.T) {
 		})
 	}
 }
+
+// TestDeleteAllUserPairs tests that all pairs of the user are deleted while the pairs of other users are kept.
+func TestDeleteAllUserPairs(t *testing.T) {
+	t.Parallel()
+
+	db := setupDB()  // Setup a new database connection
+	defer db.Close() // Ensure the database connection is closed after the test
+
+	repo := repository.NewUserPairsRepository(db) // Create a new repository instance for user pairs
+
+	userID, err := insertUser(db, "deleteallpairs@example.com", []byte("validpassword123"))
+	defer db.ExecContext(ctx, deleteUserQueryRow, userID) // Clean up by deleting the user after the test
+	assert.NoError(t, err)
+
+	otherUserID, err := insertUser(db, "deleteallpairs_other@example.com", []byte("validpassword123"))
+	defer db.ExecContext(ctx, deleteUserQueryRow, otherUserID)
+	assert.NoError(t, err)
+
+	assert.NoError(t, insertUserPair(db, userID, "binance_spot", "BTC/USDT", 10))
+	assert.NoError(t, insertUserPair(db, userID, "bybit_spot", "ETH/USDT", 10))
+	assert.NoError(t, insertUserPair(db, otherUserID, "binance_spot", "BTC/USDT", 10))
+
+	assert.NoError(t, repo.DeleteAllUserPairs(ctx, userID))
+
+	userPairs, err := repo.GetAllUserPairs(ctx, userID)
+	assert.NoError(t, err)
+	assert.Empty(t, userPairs) // Every pair of the user is deleted
+
+	otherUserPairs, err := repo.GetAllUserPairs(ctx, otherUserID)
+	assert.NoError(t, err)
+	assert.Len(t, otherUserPairs, 1) // The pairs of other users are kept
+
+	assert.NoError(t, repo.DeleteAllUserPairs(ctx, userID)) // A user without pairs is not an error
+}
+
+func TestReplaceUserPairs(t *testing.T) {
+	t.Parallel()
+
+	db := setupDB()  // Setup a new database connection
+	defer db.Close() // Ensure the database connection is closed after the test
+
+	repo := repository.NewUserPairsRepository(db) // Create a new repository instance for user pairs
+
+	userID, err := insertUser(db, "replacepairs@example.com", []byte("validpassword123"))
+	defer db.ExecContext(ctx, deleteUserQueryRow, userID) // Clean up by deleting the user after the test
+	assert.NoError(t, err)
+
+	assert.NoError(t, insertUserPair(db, userID, "binance_spot", "BTC/USDT", 10))
+	assert.NoError(t, insertUserPair(db, userID, "bybit_spot", "ETH/USDT", 10))
+
+	desired := []models.UserPairs{
+		{UserID: userID, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 15}, // Updated
+		{UserID: userID, Exchange: "okx_spot", Pair: "SOL/USDT", ExactValue: 30},     // Added
+	} // The pair of bybit_spot is removed
+
+	result, err := repo.ReplaceUserPairs(ctx, userID, desired)
+	assert.NoError(t, err)
+	assert.Len(t, result.Added, 1)
+	assert.Len(t, result.Updated, 1)
+	assert.Len(t, result.Removed, 1)
+	assert.Equal(t, "bybit_spot", result.Removed[0].Exchange)
+
+	userPairs, err := repo.GetAllUserPairs(ctx, userID)
+	assert.NoError(t, err)
+	assert.Len(t, userPairs, 2)
+	for _, pairData := range userPairs {
+		assert.NotEqual(t, "bybit_spot", pairData.Exchange)
+		if pairData.Exchange == "binance_spot" {
+			assert.Equal(t, float64(15), pairData.ExactValue)
+		}
+	}
+
+	result, err = repo.ReplaceUserPairs(ctx, userID, nil) // An empty list removes every pair of the user
+	assert.NoError(t, err)
+	assert.Len(t, result.Removed, 2)
+
+	userPairs, err = repo.GetAllUserPairs(ctx, userID)
+	assert.NoError(t, err)
+	assert.Empty(t, userPairs)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"cvs/internal/mocks"
 	"cvs/internal/models"
+	"cvs/internal/repository"
 	"cvs/internal/service"
 	"errors"
 	"testing"
@@ -12,6 +13,8 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+const maxPairsPerUser = 3 // Limit of pairs of a user in the service tests
+
 func TestUserPairsService_Add(t *testing.T) {
 	// Run tests in parallel to improve execution speed
 	t.Parallel()
@@ -32,7 +35,8 @@ func TestUserPairsService_Add(t *testing.T) {
 				ExactValue: 100,
 			},
 			mockRepo: func(m *mocks.UserPairsRepository) {
-				m.On("Add", mock.Anything, mock.Anything).Return(nil) // Expect Add to be called with any arguments and return no error
+				m.On("CountUserPairs", mock.Anything, 1).Return(0, nil) // The user has no pairs yet
+				m.On("Add", mock.Anything, mock.Anything).Return(nil)   // Expect Add to be called with any arguments and return no error
 			},
 			expectErr: false, // No error expected for valid input
 		},
@@ -123,8 +127,8 @@ func TestUserPairsService_Add(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel() // Allow this test case to run in parallel
 
-			mockRepo := mocks.NewUserPairsRepository(t)                               // Create a new instance of the mocked repository
-			userPairsService := service.NewUserPairsService(mockRepo, contextTimeout) // Create a new instance of the service with the mocked repository
+			mockRepo := mocks.NewUserPairsRepository(t)                                                // Create a new instance of the mocked repository
+			userPairsService := service.NewUserPairsService(mockRepo, maxPairsPerUser, contextTimeout) // Create a new instance of the service with the mocked repository
 
 			// Set up the mock expectations based on the test case
 			tc.mockRepo(mockRepo)
@@ -143,6 +147,90 @@ func TestUserPairsService_Add(t *testing.T) {
 	}
 }
 
+// TestUserPairsService_AddMaxPairsPerUser tests that a pair is added only while the user is below the limit of pairs.
+func TestUserPairsService_AddMaxPairsPerUser(t *testing.T) {
+	t.Parallel()
+
+	pairData := models.UserPairs{UserID: 1, Pair: "BTC/USDT", Exchange: "binance_spot", ExactValue: 100}
+
+	tests := []struct {
+		name       string // Name of the test case
+		pairsCount int    // Number of pairs the user already has
+		countErr   error  // Error returned when counting the pairs
+		expectAdd  bool   // Whether the pair is expected to reach the repository
+		limitErr   bool   // Whether the limit error is expected
+		expectErr  bool   // Expectation of whether an error should occur
+	}{
+		{
+			name:       "Below the limit",
+			pairsCount: maxPairsPerUser - 1,
+			expectAdd:  true,
+		},
+		{
+			name:       "At the limit",
+			pairsCount: maxPairsPerUser,
+			limitErr:   true,
+			expectErr:  true,
+		},
+		{
+			name:       "Above the limit",
+			pairsCount: maxPairsPerUser + 2, // E.g. the limit was lowered since the pairs were added
+			limitErr:   true,
+			expectErr:  true,
+		},
+		{
+			name:      "Counting error",
+			countErr:  errors.New("db error"),
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := mocks.NewUserPairsRepository(t)
+			mockRepo.On("CountUserPairs", mock.Anything, 1).Return(tc.pairsCount, tc.countErr)
+			if tc.expectAdd {
+				mockRepo.On("Add", mock.Anything, pairData).Return(nil)
+			}
+
+			userPairsService := service.NewUserPairsService(mockRepo, maxPairsPerUser, contextTimeout)
+
+			err := userPairsService.Add(context.Background(), pairData)
+			if !tc.expectErr {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.Error(t, err)
+			assert.Equal(t, tc.limitErr, errors.Is(err, service.ErrMaxPairsPerUserReached))
+			if tc.limitErr {
+				assert.Contains(t, err.Error(), "3") // The error names the limit
+			}
+		})
+	}
+}
+
+// TestNewUserPairsServiceDefaultMaxPairsPerUser tests that 100 pairs are allowed when the limit isn't configured.
+func TestNewUserPairsServiceDefaultMaxPairsPerUser(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := mocks.NewUserPairsRepository(t)
+	mockRepo.On("CountUserPairs", mock.Anything, 1).Return(99, nil).Once()
+	mockRepo.On("CountUserPairs", mock.Anything, 1).Return(100, nil).Once()
+	mockRepo.On("Add", mock.Anything, mock.Anything).Return(nil).Once()
+
+	userPairsService := service.NewUserPairsService(mockRepo, 0, contextTimeout)
+	pairData := models.UserPairs{UserID: 1, Pair: "BTC/USDT", Exchange: "binance_spot", ExactValue: 100}
+
+	assert.NoError(t, userPairsService.Add(context.Background(), pairData))                                    // The 100th pair is added
+	assert.ErrorIs(t, userPairsService.Add(context.Background(), pairData), service.ErrMaxPairsPerUserReached) // The 101st isn't
+}
+
 func TestUserPairsService_UpdateExactValue(t *testing.T) {
 	// Run tests in parallel to improve execution speed
 	t.Parallel()
@@ -254,8 +342,8 @@ func TestUserPairsService_UpdateExactValue(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel() // Allow this test case to run in parallel
 
-			mockRepo := mocks.NewUserPairsRepository(t)                               // Create a new instance of the mocked repository
-			userPairsService := service.NewUserPairsService(mockRepo, contextTimeout) // Create a new instance of the service with the mocked repository
+			mockRepo := mocks.NewUserPairsRepository(t)                                                // Create a new instance of the mocked repository
+			userPairsService := service.NewUserPairsService(mockRepo, maxPairsPerUser, contextTimeout) // Create a new instance of the service with the mocked repository
 
 			// Set up the mock expectations based on the test case
 			tc.mockRepo(mockRepo)
@@ -317,8 +405,8 @@ func TestUserPairsService_DeletePair(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel() // Allow this test case to run in parallel
 
-			mockRepo := mocks.NewUserPairsRepository(t)                               // Create a new instance of the mocked repository
-			userPairsService := service.NewUserPairsService(mockRepo, contextTimeout) // Create a new instance of the service with the mocked repository
+			mockRepo := mocks.NewUserPairsRepository(t)                                                // Create a new instance of the mocked repository
+			userPairsService := service.NewUserPairsService(mockRepo, maxPairsPerUser, contextTimeout) // Create a new instance of the service with the mocked repository
 
 			// Set up the mock expectations based on the test case
 			tc.mockRepo(mockRepo)
@@ -337,6 +425,263 @@ func TestUserPairsService_DeletePair(t *testing.T) {
 	}
 }
 
+// TestUserPairsService_GetUserPairsByExchange tests that the user pairs are retrieved only for a valid exchange name.
+func TestUserPairsService_GetUserPairsByExchange(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name        string             // Name of the test case
+		exchange    string             // Exchange the pairs are filtered by
+		repoPairs   []models.UserPairs // Pairs returned by the repository
+		repoErr     error              // Error returned by the repository
+		expectCall  bool               // Whether the repository is expected to be called
+		expectedErr bool               // Whether an error is expected
+	}{
+		{
+			name:       "Valid Exchange",
+			exchange:   "binance_spot",
+			repoPairs:  []models.UserPairs{{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT"}},
+			expectCall: true,
+		},
+		{
+			name:        "Repository Error",
+			exchange:    "okx_spot",
+			repoErr:     errors.New("repository error"),
+			expectCall:  true,
+			expectedErr: true,
+		},
+		{
+			name:        "Invalid Exchange",
+			exchange:    "unknown_spot",
+			expectedErr: true,
+		},
+		{
+			name:        "Empty Exchange",
+			exchange:    "",
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := mocks.NewUserPairsRepository(t)
+			if tc.expectCall {
+				mockRepo.On("GetUserPairsByExchange", mock.Anything, 1, tc.exchange).Return(tc.repoPairs, tc.repoErr)
+			}
+
+			userPairsService := service.NewUserPairsService(mockRepo, maxPairsPerUser, contextTimeout)
+
+			pairs, err := userPairsService.GetUserPairsByExchange(ctx, 1, tc.exchange)
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.repoPairs, pairs)
+			}
+		})
+	}
+}
+
+func TestUserPairsService_GetUserPair(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name        string           // Name of the test case
+		exchange    string           // Exchange of the pair
+		repoPair    models.UserPairs // Pair returned by the repository
+		repoErr     error            // Error returned by the repository
+		expectCall  bool             // Whether the repository is expected to be called
+		expectedErr error            // Expected error, nil if the pair is returned
+	}{
+		{
+			name:       "Pair Found",
+			exchange:   "binance_spot",
+			repoPair:   models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10, Side: models.SideAsks},
+			expectCall: true,
+		},
+		{
+			name:        "Pair Not Found",
+			exchange:    "okx_spot",
+			repoErr:     repository.ErrUserPairNotFound,
+			expectCall:  true,
+			expectedErr: repository.ErrUserPairNotFound,
+		},
+		{
+			name:        "Invalid Exchange",
+			exchange:    "unknown_spot",
+			expectedErr: errors.New("invalid exchange name format"),
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := mocks.NewUserPairsRepository(t)
+			if tc.expectCall {
+				mockRepo.On("GetUserPair", mock.Anything, 1, tc.exchange, "BTC/USDT").Return(tc.repoPair, tc.repoErr)
+			}
+
+			userPairsService := service.NewUserPairsService(mockRepo, maxPairsPerUser, contextTimeout)
+
+			pair, err := userPairsService.GetUserPair(ctx, 1, tc.exchange, "BTC/USDT")
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.repoPair, pair)
+			}
+		})
+	}
+}
+
+func TestUserPairsService_GetUserPairsPage(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	pairs := []models.UserPairs{{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT"}}
+
+	tests := []struct {
+		name          string                     // Name of the test case
+		pagination    models.UserPairsPagination // Requested page
+		expectCall    bool                       // Whether the repository is expected to be called
+		expectedLimit int                        // Limit passed to the repository
+		countErr      error                      // Error returned by the count
+		pageErr       error                      // Error returned by the page retrieval
+		expectedErr   bool                       // Whether an error is expected
+	}{
+		{
+			name:          "Requested Page",
+			pagination:    models.UserPairsPagination{Limit: 20, Offset: 40},
+			expectCall:    true,
+			expectedLimit: 20,
+		},
+		{
+			name:          "Default Limit",
+			pagination:    models.UserPairsPagination{Offset: 10},
+			expectCall:    true,
+			expectedLimit: 50, // The default page size is used when the limit isn't set
+		},
+		{
+			name:          "Maximum Limit",
+			pagination:    models.UserPairsPagination{Limit: 500},
+			expectCall:    true,
+			expectedLimit: 500,
+		},
+		{
+			name:        "Limit Above Maximum",
+			pagination:  models.UserPairsPagination{Limit: 501},
+			expectedErr: true,
+		},
+		{
+			name:        "Negative Limit",
+			pagination:  models.UserPairsPagination{Limit: -1},
+			expectedErr: true,
+		},
+		{
+			name:        "Negative Offset",
+			pagination:  models.UserPairsPagination{Limit: 10, Offset: -1},
+			expectedErr: true,
+		},
+		{
+			name:        "Count Error",
+			pagination:  models.UserPairsPagination{Limit: 10},
+			countErr:    errors.New("repository error"),
+			expectedErr: true,
+		},
+		{
+			name:          "Page Error",
+			pagination:    models.UserPairsPagination{Limit: 10},
+			expectCall:    true,
+			expectedLimit: 10,
+			pageErr:       errors.New("repository error"),
+			expectedErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := mocks.NewUserPairsRepository(t)
+			if tc.expectCall || tc.countErr != nil {
+				mockRepo.On("CountUserPairs", mock.Anything, 1).Return(120, tc.countErr)
+			}
+			if tc.expectCall {
+				mockRepo.On("GetUserPairsPaged", mock.Anything, 1, tc.expectedLimit, tc.pagination.Offset).Return(pairs, tc.pageErr)
+			}
+
+			userPairsService := service.NewUserPairsService(mockRepo, maxPairsPerUser, contextTimeout)
+
+			page, err := userPairsService.GetUserPairsPage(ctx, 1, tc.pagination)
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, models.UserPairsPage{
+					Pairs:  pairs,
+					Total:  120,
+					Limit:  tc.expectedLimit,
+					Offset: tc.pagination.Offset,
+				}, page)
+			}
+		})
+	}
+}
+
+func TestUserPairsService_CountPairSubscribers(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name        string // Name of the test case
+		repoCount   int    // Number of subscribers returned by the repository
+		repoErr     error  // Error returned by the repository
+		expectedErr bool   // Whether an error is expected
+	}{
+		{
+			name:      "Pair Watched By Other Users",
+			repoCount: 2,
+		},
+		{
+			name:      "Pair Watched By Nobody",
+			repoCount: 0,
+		},
+		{
+			name:        "Repository Error",
+			repoErr:     errors.New("repository error"),
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := mocks.NewUserPairsRepository(t)
+			mockRepo.On("CountPairSubscribers", mock.Anything, "binance_spot", "BTC/USDT").Return(tc.repoCount, tc.repoErr)
+
+			userPairsService := service.NewUserPairsService(mockRepo, maxPairsPerUser, contextTimeout)
+
+			subscribers, err := userPairsService.CountPairSubscribers(ctx, "binance_spot", "BTC/USDT")
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.repoCount, subscribers)
+			}
+		})
+	}
+}
+
 func TestUserPairsService_GetAllUserPairs(t *testing.T) {
 	t.Parallel() // Enable parallel execution for this test
 
@@ -371,8 +716,8 @@ func TestUserPairsService_GetAllUserPairs(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel() // Allow this test case to run in parallel
 
-			mockRepo := mocks.NewUserPairsRepository(t)                               // Create a new instance of the mocked repository
-			userPairsService := service.NewUserPairsService(mockRepo, contextTimeout) // Create a new instance of the service with the mocked repository
+			mockRepo := mocks.NewUserPairsRepository(t)                                                // Create a new instance of the mocked repository
+			userPairsService := service.NewUserPairsService(mockRepo, maxPairsPerUser, contextTimeout) // Create a new instance of the service with the mocked repository
 
 			// Set up the mock expectations based on the test case
 			mockRepo.On("GetAllUserPairs", mock.Anything, mock.Anything).Return(tc.mockReturn, tc.mockErr)
@@ -434,8 +779,8 @@ func TestUserPairsService_GetPairsByExchange(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel() // Allow this test case to run in parallel
 
-			mockRepo := mocks.NewUserPairsRepository(t)                               // Create a new instance of the mocked repository
-			userPairsService := service.NewUserPairsService(mockRepo, contextTimeout) // Create a new instance of the service with the mocked repository
+			mockRepo := mocks.NewUserPairsRepository(t)                                                // Create a new instance of the mocked repository
+			userPairsService := service.NewUserPairsService(mockRepo, maxPairsPerUser, contextTimeout) // Create a new instance of the service with the mocked repository
 
 			// Set up the mock expectations based on the test case
 			mockRepo.On("GetPairsByExchange", mock.Anything, tc.exchange).Return(tc.mockReturn, tc.mockErr)
@@ -458,3 +803,265 @@ func TestUserPairsService_GetPairsByExchange(t *testing.T) {
 		})
 	}
 }
+
+func TestUserPairsService_BulkAdd(t *testing.T) {
+	t.Parallel()
+
+	pairs := []models.UserPairs{
+		{UserID: 1, Pair: "BTC/USDT", Exchange: "binance_spot", ExactValue: 100},
+		{UserID: 1, Pair: "", Exchange: "binance_spot", ExactValue: 100}, // Invalid data
+		{UserID: 1, Pair: "ETH/USDT", Exchange: "binance_spot", ExactValue: 100},
+	}
+	validPairs := []models.UserPairs{pairs[0], pairs[2]} // Only the valid pairs reach the repository
+
+	tests := []struct {
+		name           string                           // Name of the test case
+		pairs          []models.UserPairs               // Data for the user pairs being tested
+		mockRepo       func(*mocks.UserPairsRepository) // Mocking the repository behavior
+		expectedErrors []bool                           // Whether an error is expected for every pair
+		expectErr      bool                             // Expectation of whether the whole operation fails
+	}{
+		{
+			name:  "One invalid pair among valid ones",
+			pairs: pairs,
+			mockRepo: func(m *mocks.UserPairsRepository) {
+				m.On("CountUserPairs", mock.Anything, 1).Return(0, nil).Once() // The pairs of a user are counted once
+				m.On("BulkAdd", mock.Anything, validPairs).Return([]error{nil, nil}, nil)
+			},
+			expectedErrors: []bool{false, true, false},
+		},
+		{
+			name:  "Pair rejected by the repository",
+			pairs: pairs,
+			mockRepo: func(m *mocks.UserPairsRepository) {
+				m.On("CountUserPairs", mock.Anything, 1).Return(0, nil).Once()
+				m.On("BulkAdd", mock.Anything, validPairs).Return([]error{nil, errors.New("pair already exists")}, nil)
+			},
+			expectedErrors: []bool{false, true, true},
+		},
+		{
+			name:  "Transaction error",
+			pairs: pairs,
+			mockRepo: func(m *mocks.UserPairsRepository) {
+				m.On("CountUserPairs", mock.Anything, 1).Return(0, nil).Once()
+				m.On("BulkAdd", mock.Anything, validPairs).Return(nil, errors.New("db error"))
+			},
+			expectedErrors: []bool{true, true, true}, // None of the pairs was added
+			expectErr:      true,
+		},
+		{
+			name:           "Only invalid pairs",
+			pairs:          pairs[1:2],
+			mockRepo:       func(m *mocks.UserPairsRepository) {}, // The repository isn't called
+			expectedErrors: []bool{true},
+		},
+		{
+			name:  "Pairs above the limit",
+			pairs: pairs,
+			mockRepo: func(m *mocks.UserPairsRepository) {
+				m.On("CountUserPairs", mock.Anything, 1).Return(maxPairsPerUser-1, nil).Once() // Only one more pair fits
+				m.On("BulkAdd", mock.Anything, validPairs[:1]).Return([]error{nil}, nil)       // The earlier pair takes the precedence
+			},
+			expectedErrors: []bool{false, true, true},
+		},
+		{
+			name:  "Limit already reached",
+			pairs: pairs,
+			mockRepo: func(m *mocks.UserPairsRepository) {
+				m.On("CountUserPairs", mock.Anything, 1).Return(maxPairsPerUser, nil).Once() // Nothing is passed to BulkAdd
+			},
+			expectedErrors: []bool{true, true, true},
+		},
+		{
+			name:  "Counting error",
+			pairs: pairs,
+			mockRepo: func(m *mocks.UserPairsRepository) {
+				m.On("CountUserPairs", mock.Anything, 1).Return(0, errors.New("db error")).Once()
+			},
+			expectedErrors: []bool{true, true, true}, // None of the pairs was added
+			expectErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := mocks.NewUserPairsRepository(t)
+			tc.mockRepo(mockRepo)
+
+			userPairsService := service.NewUserPairsService(mockRepo, maxPairsPerUser, contextTimeout)
+
+			pairErrors, err := userPairsService.BulkAdd(context.Background(), tc.pairs)
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Len(t, pairErrors, len(tc.pairs)) // An outcome for every pair
+			for i, expectErr := range tc.expectedErrors {
+				if expectErr {
+					assert.Error(t, pairErrors[i], "pair %d", i)
+				} else {
+					assert.NoError(t, pairErrors[i], "pair %d", i)
+				}
+			}
+		})
+	}
+}
+
+// TestUserPairsService_DeleteAllUserPairs tests that all pairs of a valid user are deleted at once.
+func TestUserPairsService_DeleteAllUserPairs(t *testing.T) {
+	t.Parallel() // Enable parallel execution for this test
+
+	tests := []struct {
+		name      string                           // Name of the test case
+		userID    int                              // ID of the user whose pairs are deleted
+		mockRepo  func(*mocks.UserPairsRepository) // Mocking the repository behavior
+		expectErr bool                             // Expectation of whether an error should occur
+	}{
+		{
+			name:   "Valid user",
+			userID: 1,
+			mockRepo: func(m *mocks.UserPairsRepository) {
+				m.On("DeleteAllUserPairs", mock.Anything, 1).Return(nil)
+			},
+		},
+		{
+			name:      "Invalid user ID",
+			userID:    0,
+			mockRepo:  func(m *mocks.UserPairsRepository) {}, // The repository isn't called for an invalid user
+			expectErr: true,
+		},
+		{
+			name:   "Repository error",
+			userID: 1,
+			mockRepo: func(m *mocks.UserPairsRepository) {
+				m.On("DeleteAllUserPairs", mock.Anything, 1).Return(errors.New("db error"))
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := mocks.NewUserPairsRepository(t)
+			tc.mockRepo(mockRepo)
+
+			userPairsService := service.NewUserPairsService(mockRepo, maxPairsPerUser, contextTimeout)
+
+			err := userPairsService.DeleteAllUserPairs(ctx, tc.userID)
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUserPairsService_ReplaceUserPairs(t *testing.T) {
+	t.Parallel() // Enable parallel execution for this test
+
+	btc := models.UserPairs{UserID: 1, Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10}
+	eth := models.UserPairs{UserID: 1, Exchange: "bybit_spot", Pair: "ETH/USDT", ExactValue: 20}
+
+	tests := []struct {
+		name      string                           // Name of the test case
+		userID    int                              // ID of the user whose pairs are replaced
+		pairs     []models.UserPairs               // Pairs replacing the pairs of the user
+		mockRepo  func(*mocks.UserPairsRepository) // Mocking the repository behavior
+		limitErr  bool                             // Whether the limit error is expected
+		expectErr bool                             // Expectation of whether an error should occur
+	}{
+		{
+			name:   "Valid pairs",
+			userID: 1,
+			pairs:  []models.UserPairs{{Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 10}, eth}, // The user ID is set by the service
+			mockRepo: func(m *mocks.UserPairsRepository) {
+				m.On("ReplaceUserPairs", mock.Anything, 1, []models.UserPairs{btc, eth}).Return(models.UserPairsReplaceResult{Added: []models.UserPairs{eth}}, nil)
+			},
+		},
+		{
+			name:   "No pairs",
+			userID: 1,
+			pairs:  []models.UserPairs{}, // All pairs of the user are removed
+			mockRepo: func(m *mocks.UserPairsRepository) {
+				m.On("ReplaceUserPairs", mock.Anything, 1, []models.UserPairs{}).Return(models.UserPairsReplaceResult{Removed: []models.UserPairs{btc}}, nil)
+			},
+		},
+		{
+			name:      "Invalid user ID",
+			userID:    0,
+			pairs:     []models.UserPairs{btc},
+			mockRepo:  func(m *mocks.UserPairsRepository) {}, // The repository isn't called for an invalid user
+			expectErr: true,
+		},
+		{
+			name:   "Above the limit",
+			userID: 1,
+			pairs: []models.UserPairs{
+				btc,
+				eth,
+				{Exchange: "okx_spot", Pair: "SOL/USDT", ExactValue: 30},
+				{Exchange: "kraken_spot", Pair: "XRP/USDT", ExactValue: 40},
+			},
+			mockRepo:  func(m *mocks.UserPairsRepository) {},
+			limitErr:  true,
+			expectErr: true,
+		},
+		{
+			name:      "Invalid pair",
+			userID:    1,
+			pairs:     []models.UserPairs{btc, {Exchange: "bybit_spot", Pair: "ETH/USDT"}}, // The exact value is missing
+			mockRepo:  func(m *mocks.UserPairsRepository) {},
+			expectErr: true,
+		},
+		{
+			name:      "Duplicated pair",
+			userID:    1,
+			pairs:     []models.UserPairs{btc, {Exchange: "binance_spot", Pair: "BTC/USDT", ExactValue: 20}},
+			mockRepo:  func(m *mocks.UserPairsRepository) {},
+			expectErr: true,
+		},
+		{
+			name:   "Repository error",
+			userID: 1,
+			pairs:  []models.UserPairs{btc},
+			mockRepo: func(m *mocks.UserPairsRepository) {
+				m.On("ReplaceUserPairs", mock.Anything, 1, []models.UserPairs{btc}).Return(models.UserPairsReplaceResult{}, errors.New("db error"))
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := mocks.NewUserPairsRepository(t)
+			tc.mockRepo(mockRepo)
+
+			userPairsService := service.NewUserPairsService(mockRepo, maxPairsPerUser, contextTimeout)
+
+			_, err := userPairsService.ReplaceUserPairs(ctx, tc.userID, tc.pairs)
+			if !tc.expectErr {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.Error(t, err)
+			assert.Equal(t, tc.limitErr, errors.Is(err, service.ErrMaxPairsPerUserReached))
+		})
+	}
+}
@@ -2,6 +2,8 @@ package tests
 
 import (
 	"bytes"
+	"database/sql"
+	"errors"
 	"testing"
 
 	"cvs/internal/models"
@@ -22,18 +24,18 @@ func TestInsertUser(t *testing.T) {
 	}{
 		{
 			name:    "Valid User",
-			user:    models.User{Email: "newuser1@example.com", Password: []byte("newpassword123"), SessionID: 1}, // Include SessionID
-			wantErr: false,                                                                                        // No error expected for valid user
+			user:    models.User{Email: "newuser1@example.com", Password: []byte("newpassword123"), PasswordVersion: 1}, // Include PasswordVersion
+			wantErr: false,                                                                                              // No error expected for valid user
 		},
 		{
 			name:    "Invalid User - Empty Email",
-			user:    models.User{Email: "", Password: []byte("password123"), SessionID: 1}, // Include SessionID
-			wantErr: true,                                                                  // Error expected due to empty email
+			user:    models.User{Email: "", Password: []byte("password123"), PasswordVersion: 1}, // Include PasswordVersion
+			wantErr: true,                                                                        // Error expected due to empty email
 		},
 		{
 			name:    "Invalid User - Empty Password",
-			user:    models.User{Email: "user2@example.com", Password: []byte{}, SessionID: 1}, // Include SessionID
-			wantErr: true,                                                                      // Error expected due to empty password
+			user:    models.User{Email: "user2@example.com", Password: []byte{}, PasswordVersion: 1}, // Include PasswordVersion
+			wantErr: true,                                                                            // Error expected due to empty password
 		},
 	}
 
@@ -60,11 +62,105 @@ func TestInsertUser(t *testing.T) {
 				query := `SELECT id, email, password, session_id FROM users WHERE id = $1` // Query to retrieve the inserted user
 				db.GetContext(ctx, &retrievedUser, query, id)                              // Execute the query
 
-				assert.NoError(t, err)                                                // Ensure no error occurred while retrieving the user
-				assert.Equal(t, id, retrievedUser.ID)                                 // Check that the retrieved ID matches the inserted ID
-				assert.Equal(t, tc.user.Email, retrievedUser.Email)                   // Verify that the email matches
-				assert.True(t, bytes.Equal(tc.user.Password, retrievedUser.Password)) // Check that passwords match
-				assert.Equal(t, tc.user.SessionID, retrievedUser.SessionID)           // Verify that SessionID matches
+				assert.NoError(t, err)                                                  // Ensure no error occurred while retrieving the user
+				assert.Equal(t, id, retrievedUser.ID)                                   // Check that the retrieved ID matches the inserted ID
+				assert.Equal(t, tc.user.Email, retrievedUser.Email)                     // Verify that the email matches
+				assert.True(t, bytes.Equal(tc.user.Password, retrievedUser.Password))   // Check that passwords match
+				assert.Equal(t, tc.user.PasswordVersion, retrievedUser.PasswordVersion) // Verify that PasswordVersion matches
+			}
+		})
+	}
+}
+
+// TestInsertUserDuplicateEmail tests that inserting a user with the email of another user returns ErrEmailAlreadyExists.
+func TestInsertUserDuplicateEmail(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	db := setupDB()  // Set up the database connection for testing
+	defer db.Close() // Ensure the database connection is closed after the test
+
+	userRepo := repository.NewUserRepository(db) // Initialize the user repository
+	user := models.User{Email: "duplicate@example.com", Password: []byte("password123"), PasswordVersion: 1}
+
+	id, err := userRepo.InsertUser(ctx, user)         // Insert the user for the first time
+	defer db.ExecContext(ctx, deleteUserQueryRow, id) // Clean up by deleting the user after the test
+	assert.NoError(t, err)
+
+	duplicateID, err := userRepo.InsertUser(ctx, user) // Insert a user with the same email
+	assert.ErrorIs(t, err, repository.ErrEmailAlreadyExists)
+	assert.Zero(t, duplicateID)
+
+	duplicateID, err = userRepo.InsertUserWithSession(ctx, user, func(user *models.User) (models.Session, error) {
+		return models.Session{}, nil // The session isn't started, since the insertion fails
+	}) // Sign up with the same email
+	assert.ErrorIs(t, err, repository.ErrEmailAlreadyExists)
+	assert.Zero(t, duplicateID)
+}
+
+// TestInsertUserWithSession tests the InsertUserWithSession function of the UserRepository.
+func TestInsertUserWithSession(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	tests := []struct {
+		name       string                    // Name of the test case
+		user       models.User               // User data to be inserted
+		newSession repository.NewSessionFunc // Function starting the first session of the inserted user
+		wantErr    bool                      // Expected outcome: true if an error is expected
+	}{
+		{
+			name: "Valid User",
+			user: models.User{Email: "tokenuser1@example.com", Password: []byte("password123"), PasswordVersion: 1},
+			newSession: func(user *models.User) (models.Session, error) {
+				return models.Session{
+					ID:           2,
+					UserID:       user.ID,
+					RefreshToken: []byte("refresh_token"),
+					UserAgent:    "test-agent",
+				}, nil // Start the session of the inserted user
+			},
+			wantErr: false, // No error expected for valid user
+		},
+		{
+			name: "Failed Token",
+			user: models.User{Email: "tokenuser2@example.com", Password: []byte("password123"), PasswordVersion: 1},
+			newSession: func(user *models.User) (models.Session, error) {
+				return models.Session{}, errors.New("token error") // Fail to issue the tokens of the session
+			},
+			wantErr: true, // Error expected and the user must not be stored
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Create a copy of the current test case
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run this test case in parallel
+
+			db := setupDB()  // Set up the database connection for testing
+			defer db.Close() // Ensure the database connection is closed after the test
+
+			userRepo := repository.NewUserRepository(db) // Initialize the user repository
+
+			id, err := userRepo.InsertUserWithSession(ctx, tc.user, tc.newSession)         // Attempt to insert the user along with the session
+			defer db.ExecContext(ctx, "DELETE FROM users WHERE email = $1", tc.user.Email) // Clean up by deleting the user after the test
+
+			var retrievedUser models.User
+			query := `SELECT id, session_id FROM users WHERE email = $1` // Query to retrieve the inserted user
+			retrieveErr := db.GetContext(ctx, &retrievedUser, query, tc.user.Email)
+
+			if tc.wantErr {
+				assert.Error(t, err)                          // Check that an error occurred if one was expected
+				assert.Zero(t, id)                            // No user ID is returned
+				assert.ErrorIs(t, retrieveErr, sql.ErrNoRows) // Check that the insertion was rolled back
+			} else {
+				assert.NoError(t, err)                // Check that no error occurred
+				assert.NoError(t, retrieveErr)        // Ensure no error occurred while retrieving the user
+				assert.Equal(t, id, retrievedUser.ID) // Check that the retrieved ID matches the inserted ID
+
+				session, err := repository.NewSessionRepository(db).GetSession(ctx, id, 2)
+				assert.NoError(t, err)                                         // Verify that the session was stored
+				assert.Equal(t, []byte("refresh_token"), session.RefreshToken) // Verify that the refresh token was stored with the session
+				assert.Equal(t, "test-agent", session.UserAgent)
 			}
 		})
 	}
@@ -81,13 +177,13 @@ func TestUpdatePassword(t *testing.T) {
 	}{
 		{
 			name:    "Valid User",
-			user:    models.User{Email: "newuser3@example.com", Password: []byte("newpassword123"), SessionID: 1}, // Include SessionID
-			wantErr: false,                                                                                        // No error expected for valid update
+			user:    models.User{Email: "newuser3@example.com", Password: []byte("newpassword123"), PasswordVersion: 1}, // Include PasswordVersion
+			wantErr: false,                                                                                              // No error expected for valid update
 		},
 		{
 			name:    "Invalid User",
-			user:    models.User{Email: "", Password: []byte(""), SessionID: 1}, // Include SessionID but invalid data
-			wantErr: true,                                                       // Error expected due to invalid user data (empty fields)
+			user:    models.User{Email: "", Password: []byte(""), PasswordVersion: 1}, // Include PasswordVersion but invalid data
+			wantErr: true,                                                             // Error expected due to invalid user data (empty fields)
 		},
 	}
 
@@ -128,52 +224,44 @@ func TestUpdatePassword(t *testing.T) {
 	}
 }
 
-// TestUpdateRefreshToken tests the UpdateRefreshToken function of the UserRepository.
-func TestUpdateRefreshToken(t *testing.T) {
+// TestChangeEmail tests the SetPendingEmail and ConfirmEmail functions of the UserRepository.
+func TestChangeEmail(t *testing.T) {
 	t.Parallel() // Run tests in parallel for efficiency
 
-	tests := []struct {
-		name    string      // Name of the test case
-		user    models.User // User data including RefreshToken
-		wantErr bool        // Expected outcome: true if an error is expected
-	}{
-		{
-			name:    "Update Refresh Token - Success",
-			user:    models.User{ID: 1, Email: "newuser4453@example.com", Password: []byte("newpassword123"), RefreshToken: []byte("new_refresh_token"), SessionID: 1}, // Include SessionID
-			wantErr: false,                                                                                                                                             // No error expected for successful update
-		},
-		{
-			name:    "Update Refresh Token - Error",
-			user:    models.User{ID: 1, Email: "newuser4453@example.com", Password: []byte("newpassword123"), RefreshToken: []byte("new_refresh_token"), SessionID: 1}, // Include SessionID
-			wantErr: true,                                                                                                                                              // Error expected (e.g., user not found)
-		},
-	}
+	db := setupDB()                              // Set up the database connection for testing
+	defer db.Close()                             // Ensure the database connection is closed after the test
+	userRepo := repository.NewUserRepository(db) // Initialize the user repository
 
-	for _, tt := range tests {
-		tc := tt // Create a copy of the current test case
+	id, err := insertUser(db, "changeemail@example.com", []byte("password123"))
+	defer db.ExecContext(ctx, deleteUserQueryRow, id) // Clean up by deleting the user after the test
+	assert.NoError(t, err)
 
-		t.Run(tc.name, func(t *testing.T) {
-			t.Parallel() // Run this test case in parallel
+	otherId, err := insertUser(db, "changeemailother@example.com", []byte("password123"))
+	defer db.ExecContext(ctx, deleteUserQueryRow, otherId)
+	assert.NoError(t, err)
 
-			db := setupDB()                              // Set up the database connection for testing
-			defer db.Close()                             // Ensure the database connection is closed after the test
-			userRepo := repository.NewUserRepository(db) // Initialize the user repository
+	err = userRepo.SetPendingEmail(ctx, id, "changeemailother@example.com") // The email of another user
+	assert.ErrorIs(t, err, repository.ErrEmailAlreadyExists)
 
-			if !tc.wantErr {
-				id, err := insertUser(db, tc.user.Email, tc.user.Password) // Insert user if no error is expected
-				defer db.ExecContext(ctx, deleteUserQueryRow, id)          // Clean up by deleting the user after the test
-				assert.NoError(t, err)
+	assert.NoError(t, userRepo.SetPendingEmail(ctx, id, "changeemailnew@example.com"))
 
-				tc.user.ID = id
-				err = userRepo.UpdateRefreshToken(ctx, tc.user) // Attempt to update user's refresh token in database
-				assert.NoError(t, err)                          // Check that no error occurred
-			} else {
-				tc.user.ID = 99999
-				err := userRepo.UpdateRefreshToken(ctx, tc.user) // Attempt to update user's refresh token in database
-				assert.Error(t, err)
-			}
-		})
-	}
+	storedUser, err := userRepo.GetUserById(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "changeemail@example.com", storedUser.Email) // The email isn't changed until it is confirmed
+	assert.Equal(t, "changeemailnew@example.com", storedUser.PendingEmail)
+
+	err = userRepo.ConfirmEmail(ctx, id, "changeemailsuperseded@example.com") // A link of an earlier requested email
+	assert.ErrorIs(t, err, repository.ErrPendingEmailNotFound)
+
+	assert.NoError(t, userRepo.ConfirmEmail(ctx, id, "changeemailnew@example.com"))
+
+	storedUser, err = userRepo.GetUserById(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "changeemailnew@example.com", storedUser.Email) // The email is swapped after the confirmation
+	assert.Empty(t, storedUser.PendingEmail)
+
+	err = userRepo.ConfirmEmail(ctx, id, "changeemailnew@example.com") // The link can't be used twice
+	assert.ErrorIs(t, err, repository.ErrPendingEmailNotFound)
 }
 
 // TestGetUserByID tests the GetUserById function of the UserRepository.
@@ -400,3 +488,42 @@ func TestDeleteUser(t *testing.T) {
 		})
 	}
 }
+
+// TestDeleteUserCascade tests that deleting a user removes the pairs, found volumes, settings and sessions of the user,
+// so no orphaned row is left behind.
+func TestDeleteUserCascade(t *testing.T) {
+	t.Parallel() // Run tests in parallel for efficiency
+
+	db := setupDB()  // Set up the database connection for testing
+	defer db.Close() // Ensure the database connection is closed after the test
+
+	userID, err := insertUser(db, "cascade@example.com", []byte("password123"))
+	defer db.ExecContext(ctx, deleteUserQueryRow, userID) // Clean up if the deletion fails
+	assert.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, `INSERT INTO user_pairs (user_id, exchange, pair, exact_value) VALUES ($1, 'binance_spot', 'BTC/USDT', 10), ($1, 'bybit_spot', 'ETH/USDT', 20)`, userID)
+	assert.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, `INSERT INTO found_volumes (user_id, exchange, pair, side, price, volume) VALUES ($1, 'binance_spot', 'BTC/USDT', 'asks', 50000, 12)`, userID)
+	assert.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, `INSERT INTO user_settings (user_id, notify_email) VALUES ($1, true)`, userID)
+	assert.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, `INSERT INTO user_sessions (id, user_id, refresh_token) VALUES (1, $1, 'hash'), (2, $1, 'hash')`, userID)
+	assert.NoError(t, err)
+
+	userRepo := repository.NewUserRepository(db) // Initialize the user repository
+
+	assert.NoError(t, userRepo.DeleteUser(ctx, userID))
+
+	for _, table := range []string{"user_pairs", "found_volumes", "user_settings", "user_sessions"} {
+		var count int
+		err := db.GetContext(ctx, &count, `SELECT count(*) FROM `+table+` WHERE user_id=$1`, userID)
+
+		assert.NoError(t, err)
+		assert.Zero(t, count, "rows of the deleted user are left in %s", table)
+	}
+
+	assert.Error(t, userRepo.DeleteUser(ctx, userID)) // The user is gone as well
+}
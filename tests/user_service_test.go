@@ -39,6 +39,31 @@ func TestUserService_InsertUser(t *testing.T) {
 
 }
 
+func TestUserService_InsertUserWithSession(t *testing.T) {
+	t.Parallel() // Enable parallel execution for this test
+
+	// Create a mock user repository for testing
+	mockUserRepository := mocks.NewUserRepository(t)
+	userService := service.NewUserService(mockUserRepository, contextTimeout) // Create a new instance of the user service
+
+	user := models.User{
+		Email:    "test@example.com",
+		Password: []byte("password123"),
+	}
+
+	// Set up the expectation for the InsertUserWithSession method
+	mockUserRepository.On("InsertUserWithSession", mock.Anything, user, mock.Anything).Return(1, nil)
+
+	// Call the InsertUserWithSession method with a sample user
+	userID, err := userService.InsertUserWithSession(context.Background(), user, func(user *models.User) (models.Session, error) {
+		return models.Session{}, nil
+	})
+
+	// Assert that no error occurred and the returned user ID is as expected
+	assert.NoError(t, err)
+	assert.Equal(t, 1, userID)
+}
+
 func TestUserService_UpdatePassword(t *testing.T) {
 	t.Parallel() // Enable parallel execution for this test
 
@@ -91,55 +116,6 @@ func TestUserService_GetUsersIDFromMemory(t *testing.T) {
 	// Assert that the returned value is of type ConcurrentMap[string, string]
 	assert.IsType(t, cmap.ConcurrentMap[string, string]{}, usersIDs)
 }
-func TestUpdateRefreshTokenService(t *testing.T) {
-	t.Parallel() // Enable parallel execution for this test
-
-	// Define test cases for updating refresh tokens
-	tests := []struct {
-		name string      // Name of the test case
-		user models.User // User data for the test case
-		err  error       // Expected error (nil for success)
-	}{
-		{
-			name: "Successful Update",
-			user: models.User{ID: 1, RefreshToken: []byte("new_refresh_token")},
-			err:  nil, // No error expected for successful update
-		},
-		{
-			name: "Error from UserRepository",
-			user: models.User{ID: 1, RefreshToken: []byte("new_refresh_token")},
-			err:  errors.New(""), // Simulate an error from the repository
-		},
-		{
-			name: "Context Timeout",
-			user: models.User{ID: 1, RefreshToken: []byte("new_refresh_token")},
-			err:  errors.New(""), // Simulate a context timeout error
-		},
-	}
-
-	// Iterate through each test case
-	for _, tt := range tests {
-		tc := tt // Capture the current test case
-
-		t.Run(tc.name, func(t *testing.T) {
-			t.Parallel() // Allow this test case to run in parallel
-
-			mockUserRepository := mocks.NewUserRepository(t)                                   // Create a new instance of the mocked repository
-			mockUserRepository.On("UpdateRefreshToken", mock.Anything, tt.user).Return(tc.err) // Set up expectation
-
-			userService := service.NewUserService(mockUserRepository, contextTimeout) // Create a new instance of the user service
-
-			err := userService.UpdateRefreshToken(ctx, tt.user) // Call the method under test
-
-			if tc.err == nil {
-				assert.NoError(t, err) // Assert no error occurred for successful updates
-			} else {
-				assert.Error(t, err) // Assert that an error occurred as expected
-			}
-		})
-	}
-}
-
 func TestGetUserById(t *testing.T) {
 	t.Parallel() // Enable parallel execution for this test
 
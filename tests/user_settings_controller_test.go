@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goccy/go-json"
+
+	"cvs/api/server/controller"
+	"cvs/internal/mocks"
+	"cvs/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetUserSettingsController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name       string // Name of the test case
+		mocksSetup func(
+			userSettingsMock *mocks.UserSettingsService,
+			mockLogger *mocks.Logger,
+		) // Function to set up mock behavior
+		expectedCode     int                 // Expected HTTP status code after the request
+		expectedSettings models.UserSettings // Expected settings in the response body
+	}{
+		{
+			name: "Successful Retrieval",
+			mocksSetup: func(userSettingsMock *mocks.UserSettingsService, mockLogger *mocks.Logger) {
+				userSettingsMock.On("GetSettings", mock.Anything, 1).
+					Return(models.UserSettings{UserID: 1, NotifyEmail: true, MinVolumeNotify: 100}, nil)
+			},
+			expectedCode:     http.StatusOK,
+			expectedSettings: models.UserSettings{NotifyEmail: true, MinVolumeNotify: 100}, // The user ID isn't exposed
+		},
+		{
+			name: "Service Error",
+			mocksSetup: func(userSettingsMock *mocks.UserSettingsService, mockLogger *mocks.Logger) {
+				userSettingsMock.On("GetSettings", mock.Anything, 1).Return(models.UserSettings{}, errors.New("service error"))
+				mockLogger.On("Error", mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status due to service error
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable for use in goroutine
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run each test case in parallel
+
+			app := fiber.New() // Create a new Fiber application instance
+
+			mockUserSettingsService := mocks.NewUserSettingsService(t) // Create a new mock UserSettings service
+			mockLogger := mocks.NewLogger(t)
+
+			tc.mocksSetup(mockUserSettingsService, mockLogger) // Setup mocks for the current test case
+
+			userSettingsController := controller.NewUserSettingsController(mockUserSettingsService, mockLogger)
+
+			app.Get("/api/user/settings", func(c *fiber.Ctx) error {
+				c.Locals("user", models.User{ID: 1})         // Add user to context locals
+				return userSettingsController.GetSettings(c) // Call GetSettings method on UserSettingsController
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/user/settings", nil), -1) // Execute the request against the Fiber app
+			assert.NoError(t, err)                                                           // Assert that there was no error during request execution
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode) // Assert that the response status code matches expected
+
+			if tc.expectedCode == http.StatusOK {
+				var settings models.UserSettings
+
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&settings))
+				assert.Equal(t, tc.expectedSettings, settings)
+			}
+		})
+	}
+}
+
+func TestUpdateUserSettingsController(t *testing.T) {
+	t.Parallel() // Allows this test to run in parallel with other tests
+
+	tests := []struct {
+		name       string // Name of the test case
+		body       string // Body of the request
+		mocksSetup func(
+			userSettingsMock *mocks.UserSettingsService,
+			mockLogger *mocks.Logger,
+		) // Function to set up mock behavior
+		expectedCode int // Expected HTTP status code after the request
+	}{
+		{
+			name: "Successful Update",
+			body: `{"notify_telegram":true,"notify_email":false,"min_volume_notify":250}`,
+			mocksSetup: func(userSettingsMock *mocks.UserSettingsService, mockLogger *mocks.Logger) {
+				userSettingsMock.On("UpdateSettings", mock.Anything, models.UserSettings{
+					UserID:          1, // The settings belong to the authenticated user
+					NotifyTelegram:  true,
+					MinVolumeNotify: 250,
+				}).Return(nil)
+			},
+			expectedCode: http.StatusOK, // Expecting 200 OK status
+		},
+		{
+			name: "Zero Min Volume",
+			body: `{"notify_email":true,"min_volume_notify":0}`,
+			mocksSetup: func(userSettingsMock *mocks.UserSettingsService, mockLogger *mocks.Logger) {
+				userSettingsMock.On("UpdateSettings", mock.Anything, models.UserSettings{UserID: 1, NotifyEmail: true}).Return(nil)
+			},
+			expectedCode: http.StatusOK, // Expecting 200 OK status
+		},
+		{
+			name: "Webhook",
+			body: `{"webhook_url":"https://example.com/hooks/cvs","webhook_secret":"secret"}`,
+			mocksSetup: func(userSettingsMock *mocks.UserSettingsService, mockLogger *mocks.Logger) {
+				userSettingsMock.On("UpdateSettings", mock.Anything, models.UserSettings{
+					UserID:        1,
+					WebhookUrl:    "https://example.com/hooks/cvs",
+					WebhookSecret: "secret",
+				}).Return(nil)
+			},
+			expectedCode: http.StatusOK, // Expecting 200 OK status
+		},
+		{
+			name:         "Webhook Without Secret",
+			body:         `{"webhook_url":"https://example.com/hooks/cvs"}`,
+			mocksSetup:   func(userSettingsMock *mocks.UserSettingsService, mockLogger *mocks.Logger) {},
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status
+		},
+		{
+			name:         "Negative Min Volume",
+			body:         `{"min_volume_notify":-1}`,
+			mocksSetup:   func(userSettingsMock *mocks.UserSettingsService, mockLogger *mocks.Logger) {}, // Invalid settings are not saved
+			expectedCode: http.StatusBadRequest,                                                          // Expecting 400 Bad Request status
+		},
+		{
+			name:         "Invalid Body",
+			body:         `{"min_volume_notify":"a lot"}`,
+			mocksSetup:   func(userSettingsMock *mocks.UserSettingsService, mockLogger *mocks.Logger) {},
+			expectedCode: http.StatusBadRequest, // Expecting 400 Bad Request status
+		},
+		{
+			name: "Service Error",
+			body: `{"min_volume_notify":10}`,
+			mocksSetup: func(userSettingsMock *mocks.UserSettingsService, mockLogger *mocks.Logger) {
+				userSettingsMock.On("UpdateSettings", mock.Anything, mock.Anything).Return(errors.New("service error"))
+				mockLogger.On("Error", mock.Anything).Return(nil)
+			},
+			expectedCode: http.StatusInternalServerError, // Expecting 500 Internal Server Error status due to service error
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt // Capture range variable for use in goroutine
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Run each test case in parallel
+
+			app := fiber.New() // Create a new Fiber application instance
+
+			mockUserSettingsService := mocks.NewUserSettingsService(t) // Create a new mock UserSettings service
+			mockLogger := mocks.NewLogger(t)
+
+			tc.mocksSetup(mockUserSettingsService, mockLogger) // Setup mocks for the current test case
+
+			userSettingsController := controller.NewUserSettingsController(mockUserSettingsService, mockLogger)
+
+			app.Put("/api/user/settings", func(c *fiber.Ctx) error {
+				c.Locals("user", models.User{ID: 1})            // Add user to context locals
+				return userSettingsController.UpdateSettings(c) // Call UpdateSettings method on UserSettingsController
+			})
+
+			req := httptest.NewRequest("PUT", "/api/user/settings", bytes.NewBufferString(tc.body)) // Create a new PUT request with JSON body
+			req.Header.Set("Content-Type", "application/json")                                      // Set Content-Type header to application/json
+
+			resp, err := app.Test(req, -1) // Execute the request against the Fiber app
+			assert.NoError(t, err)         // Assert that there was no error during request execution
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode) // Assert that the response status code matches expected
+		})
+	}
+}
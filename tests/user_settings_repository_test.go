@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"cvs/internal/models"
+	"cvs/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserSettingsUpsert(t *testing.T) {
+	// Run tests in parallel to speed up execution
+	t.Parallel()
+
+	// Define test cases for upserting user settings
+	tests := []struct {
+		name      string               // Name of the test case
+		validUser bool                 // Whether the settings belong to an existing user
+		settings  models.UserSettings  // Settings being upserted
+		updated   *models.UserSettings // Settings upserted a second time for the same user, if any
+		wantErr   bool                 // Expectation of whether an error should occur
+	}{
+		{
+			name:      "Insert",
+			validUser: true,
+			settings:  models.UserSettings{NotifyEmail: true, MinVolumeNotify: 100},
+			wantErr:   false, // No error expected for valid input
+		},
+		{
+			name:      "Update of the same user",
+			validUser: true,
+			settings:  models.UserSettings{NotifyEmail: true, MinVolumeNotify: 100},
+			updated:   &models.UserSettings{NotifyTelegram: true, MinVolumeNotify: 0, WebhookUrl: "https://example.com/hooks/cvs", WebhookSecret: "secret", AlertCooldownSeconds: 600},
+			wantErr:   false, // No error expected, the row is updated in place
+		},
+		{
+			name:      "Non-existent user",
+			validUser: false,
+			settings:  models.UserSettings{MinVolumeNotify: 100},
+			wantErr:   true, // Error expected due to the foreign key
+		},
+		{
+			name:      "Negative min volume",
+			validUser: true,
+			settings:  models.UserSettings{MinVolumeNotify: -1},
+			wantErr:   true, // Error expected due to the min volume check
+		},
+	}
+
+	// Iterate through each test case
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel() // Allow this test case to run in parallel
+
+			db := setupDB()  // Setup a new database connection for each test case
+			defer db.Close() // Ensure the database connection is closed after the test
+
+			userID := 99999 // Assuming this user ID does not exist in the users table
+			if tc.validUser {
+				email := "usersettingsupsert" + time.Now().Format("150405.000000") + "@example.com" // Create a unique email for each test case
+				id, err := insertUser(db, email, []byte("validpassword123"))                        // Insert a valid user into the database
+				defer db.ExecContext(ctx, deleteUserQueryRow, id)                                   // Clean up by deleting the user after the test
+
+				assert.NoError(t, err) // Assert that there was no error inserting the user
+
+				userID = id
+			}
+
+			repo := repository.NewUserSettingsRepository(db) // Create a new repository instance for user settings
+
+			settings := tc.settings
+			settings.UserID = userID
+
+			err := repo.Upsert(ctx, settings) // Attempt to upsert the settings
+
+			expected := settings
+			if tc.updated != nil {
+				assert.NoError(t, err)
+
+				expected = *tc.updated
+				expected.UserID = userID
+
+				err = repo.Upsert(ctx, expected) // Upsert the settings of the same user again
+			}
+
+			if tc.wantErr {
+				assert.Error(t, err) // Assert that an error occurred if one was expected
+
+				return
+			}
+
+			assert.NoError(t, err) // Assert that no error occurred for valid input
+
+			stored, err := repo.Get(ctx, userID) // Retrieve the stored settings
+
+			assert.NoError(t, err)
+			assert.Equal(t, expected, stored)
+		})
+	}
+}
+
+func TestUserSettingsGetDefault(t *testing.T) {
+	// Run tests in parallel to speed up execution
+	t.Parallel()
+
+	db := setupDB()  // Setup a new database connection
+	defer db.Close() // Ensure the database connection is closed after the test
+
+	userID, err := insertUser(db, "usersettingsgetdefault@example.com", []byte("validpassword123")) // Insert a valid user into the database
+	defer db.ExecContext(ctx, deleteUserQueryRow, userID)                                           // Clean up by deleting the user after the test
+
+	assert.NoError(t, err)
+
+	repo := repository.NewUserSettingsRepository(db) // Create a new repository instance for user settings
+
+	settings, err := repo.Get(ctx, userID) // The user has never saved the settings
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.UserSettings{UserID: userID}, settings)
+}
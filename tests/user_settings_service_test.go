@@ -0,0 +1,241 @@
+package tests
+
+import (
+	"cvs/internal/mocks"
+	"cvs/internal/models"
+	"cvs/internal/service"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestUserSettingsService_GetSettings tests that the settings are read from the repository once and cached.
+func TestUserSettingsService_GetSettings(t *testing.T) {
+	t.Parallel()
+
+	stored := models.UserSettings{UserID: 1, NotifyEmail: true, MinVolumeNotify: 100}
+
+	mockRepo := mocks.NewUserSettingsRepository(t)
+	mockRepo.On("Get", mock.Anything, 1).Return(stored, nil).Once() // Later requests are served from the cache
+
+	userSettingsService := service.NewUserSettingsService(mockRepo, contextTimeout)
+
+	for i := 0; i < 2; i++ {
+		settings, err := userSettingsService.GetSettings(ctx, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, stored, settings)
+	}
+}
+
+// TestUserSettingsService_GetSettingsError tests that a failed read is returned and not cached.
+func TestUserSettingsService_GetSettingsError(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := mocks.NewUserSettingsRepository(t)
+	mockRepo.On("Get", mock.Anything, 1).Return(models.UserSettings{UserID: 1}, errors.New("db error")).Twice()
+
+	userSettingsService := service.NewUserSettingsService(mockRepo, contextTimeout)
+
+	for i := 0; i < 2; i++ {
+		_, err := userSettingsService.GetSettings(ctx, 1)
+
+		assert.Error(t, err)
+	}
+}
+
+// TestUserSettingsService_UpdateSettings tests validation and saving of the settings.
+func TestUserSettingsService_UpdateSettings(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string                              // Name of the test case
+		settings  models.UserSettings                 // Settings being saved
+		mockRepo  func(*mocks.UserSettingsRepository) // Mocking the repository behavior
+		expectErr bool                                // Expectation of whether an error should occur
+	}{
+		{
+			name:     "Valid settings",
+			settings: models.UserSettings{UserID: 1, NotifyTelegram: true, MinVolumeNotify: 50},
+			mockRepo: func(m *mocks.UserSettingsRepository) {
+				m.On("Upsert", mock.Anything, models.UserSettings{UserID: 1, NotifyTelegram: true, MinVolumeNotify: 50}).Return(nil)
+			},
+		},
+		{
+			name:     "Zero min volume",
+			settings: models.UserSettings{UserID: 1},
+			mockRepo: func(m *mocks.UserSettingsRepository) {
+				m.On("Upsert", mock.Anything, models.UserSettings{UserID: 1}).Return(nil)
+			},
+		},
+		{
+			name:     "Webhook",
+			settings: models.UserSettings{UserID: 1, WebhookUrl: "https://example.com/hooks/cvs", WebhookSecret: "secret"},
+			mockRepo: func(m *mocks.UserSettingsRepository) {
+				m.On("Upsert", mock.Anything, models.UserSettings{UserID: 1, WebhookUrl: "https://example.com/hooks/cvs", WebhookSecret: "secret"}).Return(nil)
+			},
+		},
+		{
+			name:      "Webhook without secret",
+			settings:  models.UserSettings{UserID: 1, WebhookUrl: "https://example.com/hooks/cvs"},
+			mockRepo:  func(m *mocks.UserSettingsRepository) {}, // The receiver couldn't verify the found volumes
+			expectErr: true,
+		},
+		{
+			name:      "Relative webhook url",
+			settings:  models.UserSettings{UserID: 1, WebhookUrl: "/hooks/cvs", WebhookSecret: "secret"},
+			mockRepo:  func(m *mocks.UserSettingsRepository) {},
+			expectErr: true,
+		},
+		{
+			name:      "Webhook url with loopback address",
+			settings:  models.UserSettings{UserID: 1, WebhookUrl: "http://127.0.0.1:8080/hooks/cvs", WebhookSecret: "secret"},
+			mockRepo:  func(m *mocks.UserSettingsRepository) {}, // The scanner must not request its own network
+			expectErr: true,
+		},
+		{
+			name:      "Webhook url with unsupported scheme",
+			settings:  models.UserSettings{UserID: 1, WebhookUrl: "ftp://example.com/hooks/cvs", WebhookSecret: "secret"},
+			mockRepo:  func(m *mocks.UserSettingsRepository) {},
+			expectErr: true,
+		},
+		{
+			name:      "Negative min volume",
+			settings:  models.UserSettings{UserID: 1, MinVolumeNotify: -0.5},
+			mockRepo:  func(m *mocks.UserSettingsRepository) {}, // Invalid settings are not saved
+			expectErr: true,
+		},
+		{
+			name:     "Alert cooldown",
+			settings: models.UserSettings{UserID: 1, AlertCooldownSeconds: 600},
+			mockRepo: func(m *mocks.UserSettingsRepository) {
+				m.On("Upsert", mock.Anything, models.UserSettings{UserID: 1, AlertCooldownSeconds: 600}).Return(nil)
+			},
+		},
+		{
+			name:      "Negative alert cooldown",
+			settings:  models.UserSettings{UserID: 1, AlertCooldownSeconds: -1},
+			mockRepo:  func(m *mocks.UserSettingsRepository) {},
+			expectErr: true,
+		},
+		{
+			name:      "Alert cooldown longer than a day",
+			settings:  models.UserSettings{UserID: 1, AlertCooldownSeconds: 24*60*60 + 1},
+			mockRepo:  func(m *mocks.UserSettingsRepository) {}, // The pair would practically be muted
+			expectErr: true,
+		},
+		{
+			name:      "Invalid user ID",
+			settings:  models.UserSettings{UserID: 0, MinVolumeNotify: 10},
+			mockRepo:  func(m *mocks.UserSettingsRepository) {},
+			expectErr: true,
+		},
+		{
+			name:     "Repository error",
+			settings: models.UserSettings{UserID: 1, MinVolumeNotify: 10},
+			mockRepo: func(m *mocks.UserSettingsRepository) {
+				m.On("Upsert", mock.Anything, mock.Anything).Return(errors.New("db error"))
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := mocks.NewUserSettingsRepository(t)
+			tc.mockRepo(mockRepo)
+
+			userSettingsService := service.NewUserSettingsService(mockRepo, contextTimeout)
+
+			err := userSettingsService.UpdateSettings(ctx, tc.settings)
+			if tc.expectErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+
+			settings, err := userSettingsService.GetSettings(ctx, tc.settings.UserID) // The saved settings are cached, so the repository isn't read
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.settings, settings)
+		})
+	}
+}
+
+// TestUserSettingsService_ShouldNotify tests that the found volumes below the minimum volume of the user are not notified about.
+func TestUserSettingsService_ShouldNotify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string                              // Name of the test case
+		volume   float64                             // Volume of the found volume
+		mockRepo func(*mocks.UserSettingsRepository) // Mocking the repository behavior
+		expected bool                                // Whether the user is expected to be notified
+	}{
+		{
+			name:   "Default settings",
+			volume: 1,
+			mockRepo: func(m *mocks.UserSettingsRepository) {
+				m.On("Get", mock.Anything, 1).Return(models.UserSettings{UserID: 1}, nil)
+			},
+			expected: true,
+		},
+		{
+			name:   "Volume above the minimum",
+			volume: 150,
+			mockRepo: func(m *mocks.UserSettingsRepository) {
+				m.On("Get", mock.Anything, 1).Return(models.UserSettings{UserID: 1, MinVolumeNotify: 100}, nil)
+			},
+			expected: true,
+		},
+		{
+			name:   "Volume equal to the minimum",
+			volume: 100,
+			mockRepo: func(m *mocks.UserSettingsRepository) {
+				m.On("Get", mock.Anything, 1).Return(models.UserSettings{UserID: 1, MinVolumeNotify: 100}, nil)
+			},
+			expected: true,
+		},
+		{
+			name:   "Volume below the minimum",
+			volume: 99,
+			mockRepo: func(m *mocks.UserSettingsRepository) {
+				m.On("Get", mock.Anything, 1).Return(models.UserSettings{UserID: 1, MinVolumeNotify: 100}, nil)
+			},
+			expected: false,
+		},
+		{
+			name:   "Settings can't be read",
+			volume: 1,
+			mockRepo: func(m *mocks.UserSettingsRepository) {
+				m.On("Get", mock.Anything, 1).Return(models.UserSettings{UserID: 1}, errors.New("db error"))
+			},
+			expected: true, // A database failure doesn't silence the scanner
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := mocks.NewUserSettingsRepository(t)
+			tc.mockRepo(mockRepo)
+
+			userSettingsService := service.NewUserSettingsService(mockRepo, contextTimeout)
+
+			foundVolume := models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: tc.volume}
+
+			assert.Equal(t, tc.expected, userSettingsService.ShouldNotify(ctx, 1, foundVolume))
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cvs/internal/config"
+	"cvs/internal/models"
+	"cvs/internal/service"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWebhookNotifier_Notify tests that the found volumes are posted signed to the webhook and retried on non-2xx responses.
+func TestWebhookNotifier_Notify(t *testing.T) {
+	t.Parallel()
+
+	const secret = "webhook-secret"
+
+	foundVolume := models.FoundVolume{Exchange: "binance_spot", Pair: "BTC/USDT", Side: "asks", Price: 50000, Volume: 12}
+
+	tests := []struct {
+		name             string // Name of the test case
+		responses        []int  // Status codes of the receiver by attempt, the last one repeats
+		secret           string // Secret the found volume is signed with
+		disabled         bool   // Whether the user has no webhook configured
+		denyPrivate      bool   // Whether the posts to the private addresses of the receiver are refused
+		expectedAttempts int32  // Expected number of requests received
+		expectErr        bool   // Expectation of whether an error should occur
+	}{
+		{
+			name:             "Delivered",
+			responses:        []int{http.StatusOK},
+			secret:           secret,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "Retried after server error",
+			responses:        []int{http.StatusInternalServerError, http.StatusNoContent},
+			secret:           secret,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "Attempts are capped",
+			responses:        []int{http.StatusBadRequest},
+			secret:           secret,
+			expectedAttempts: 3, // Every non-2xx response is retried until the attempts run out
+			expectErr:        true,
+		},
+		{
+			name:             "Signed with another secret",
+			responses:        []int{http.StatusOK},
+			secret:           "another-secret",
+			expectedAttempts: 3, // The receiver rejects every attempt
+			expectErr:        true,
+		},
+		{
+			name:             "Webhook disabled",
+			disabled:         true,
+			expectedAttempts: 0,
+		},
+		{
+			name:             "Redirect isn't followed",
+			responses:        []int{http.StatusFound},
+			secret:           secret,
+			expectedAttempts: 3, // Every attempt fails on the redirect instead of following it
+			expectErr:        true,
+		},
+		{
+			name:             "Loopback address refused",
+			responses:        []int{http.StatusOK},
+			secret:           secret,
+			denyPrivate:      true,
+			expectedAttempts: 0, // The connection to the receiver on the loopback address is never made
+			expectErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var attempts atomic.Int32
+
+			// The receiver verifies the signature like the systems of users do
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempt := int(attempts.Add(1))
+
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+				mac := hmac.New(sha256.New, []byte(secret))
+				mac.Write(body)
+				if !hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(r.Header.Get("X-Signature"))) {
+					w.WriteHeader(http.StatusUnauthorized)
+
+					return
+				}
+
+				var received models.FoundVolume
+				assert.NoError(t, json.Unmarshal(body, &received))
+				assert.Equal(t, foundVolume, received)
+
+				status := tc.responses[min(attempt, len(tc.responses))-1]
+				if status >= http.StatusMultipleChoices && status < http.StatusBadRequest {
+					w.Header().Set("Location", "/redirected") // Would be received as another attempt if followed
+				}
+
+				w.WriteHeader(status)
+			}))
+			defer receiver.Close()
+
+			settings := models.UserSettings{UserID: 1, WebhookUrl: receiver.URL, WebhookSecret: tc.secret}
+			if tc.disabled {
+				settings.WebhookUrl = ""
+			}
+
+			notifier := service.NewWebhookNotifier(config.WebhookConfig{
+				Attempts:              3,
+				Backoff:               time.Millisecond,
+				Timeout:               time.Second,
+				AllowPrivateAddresses: !tc.denyPrivate, // The test receiver listens on the loopback address
+			})
+
+			err := notifier.Notify(ctx, settings, foundVolume)
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expectedAttempts, attempts.Load())
+		})
+	}
+}